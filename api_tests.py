@@ -1,7 +1,14 @@
+import base64
+import hashlib
+import hmac
+import json
+import os
+import time
 import requests
 import pytest
 import random
 import string
+import uuid
 
 BASE_URL = "http://localhost:80"
 '''
@@ -202,6 +209,468 @@ def test_login_correct(primary_user):
     assert response.status_code == 200
     token = response.json().get("token")
     assert token is not None
+    assert response.json().get("refresh_token", "").startswith("rt_")
+    assert response.json().get("expires_in") > 0
+
+
+# ---------------------------
+# Session Tests
+# ---------------------------
+def login_new_user():
+    """Registers a throwaway user and logs in, so revoking its sessions leaves the fixtures alone."""
+    username = "session_" + random_string()
+    email = f"{username}@example.com"
+    r = requests.post(f"{BASE_URL}/users", json={"user_name": username, "email": email, "password": "password1"})
+    assert r.status_code == 201
+    r = requests.post(f"{BASE_URL}/login", json={"email": email, "password": "password1"})
+    assert r.status_code == 200
+    return r.json()
+
+
+def test_refresh_token_rotation():
+    """A refresh token gives new working tokens once, reusing it is rejected."""
+    session = login_new_user()
+    response = requests.post(f"{BASE_URL}/token/refresh", json={"refresh_token": session["refresh_token"]})
+    assert response.status_code == 200
+    refreshed = response.json()
+    assert refreshed["refresh_token"] != session["refresh_token"]
+
+    response = requests.get(f"{BASE_URL}/contacts", headers={"Authorization": f"Bearer {refreshed['token']}"})
+    assert response.status_code == 200
+
+    response = requests.post(f"{BASE_URL}/token/refresh", json={"refresh_token": session["refresh_token"]})
+    assert response.status_code == 401
+
+
+def test_logout_revokes_tokens():
+    """After logout neither the access token nor the refresh token of the session work."""
+    session = login_new_user()
+    headers = {"Authorization": f"Bearer {session['token']}"}
+    response = requests.post(f"{BASE_URL}/logout", headers=headers, json={"refresh_token": session["refresh_token"]})
+    assert response.status_code == 200
+
+    response = requests.get(f"{BASE_URL}/contacts", headers=headers)
+    assert response.status_code == 401
+    response = requests.post(f"{BASE_URL}/token/refresh", json={"refresh_token": session["refresh_token"]})
+    assert response.status_code == 401
+
+
+def test_change_password_revokes_sessions():
+    """Changing the password revokes the other sessions, the returned tokens and the new password work."""
+    session = login_new_user()
+    headers = {"Authorization": f"Bearer {session['token']}"}
+    response = requests.put(f"{BASE_URL}/users/me/password", headers=headers,
+                            json={"current_password": "wrongpassword", "new_password": "password2"})
+    assert response.status_code == 403
+
+    response = requests.put(f"{BASE_URL}/users/me/password", headers=headers,
+                            json={"current_password": "password1", "new_password": "password2"})
+    assert response.status_code == 200
+    renewed = response.json()
+
+    assert requests.get(f"{BASE_URL}/contacts", headers=headers).status_code == 401
+    response = requests.post(f"{BASE_URL}/token/refresh", json={"refresh_token": session["refresh_token"]})
+    assert response.status_code == 401
+    response = requests.get(f"{BASE_URL}/contacts", headers={"Authorization": f"Bearer {renewed['token']}"})
+    assert response.status_code == 200
+
+
+def test_profile_and_email_change(primary_user, secondary_user):
+    """The profile shows the account, an email change needs the password, a free email and both confirmations."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    response = requests.get(f"{BASE_URL}/users/me", headers=headers)
+    assert response.status_code == 200
+    assert response.json()["email"] == primary_user["email"]
+    assert "pending_email_change" not in response.json()
+
+    response = requests.post(f"{BASE_URL}/users/me/email", headers=headers,
+                             json={"new_email": f"{random_string()}@example.com", "password": "wrongpassword"})
+    assert response.status_code in (403, 503)
+    response = requests.post(f"{BASE_URL}/users/me/email", headers=headers,
+                             json={"new_email": secondary_user["email"], "password": "password1"})
+    assert response.status_code in (409, 503)
+
+    response = requests.get(f"{BASE_URL}/users/email/confirm", params={"token": "invalid"})
+    assert response.status_code == 404
+    response = requests.delete(f"{BASE_URL}/users/me/email", headers=headers)
+    assert response.status_code == 404
+
+
+def test_update_profile_and_delete_account():
+    """The profile can be renamed in a PATCH, and deleting the account needs the password and removes its contacts."""
+    session = login_new_user()
+    headers = {"Authorization": f"Bearer {session['token']}"}
+    response = requests.patch(f"{BASE_URL}/users/me", json={}, headers=headers)
+    assert response.status_code == 400
+    new_name = "patched_" + random_string()
+    response = requests.patch(f"{BASE_URL}/users/me", json={"user_name": new_name}, headers=headers)
+    assert response.status_code == 200
+    assert response.json()["user_name"] == new_name
+
+    assert create_contact(session["token"], "gone_" + random_string(), "bd", "0501234567", "somewhere").status_code == 201
+    response = requests.delete(f"{BASE_URL}/users/me", json={"password": "wrongpassword"}, headers=headers)
+    assert response.status_code == 403
+    response = requests.delete(f"{BASE_URL}/users/me", json={"password": "password1"}, headers=headers)
+    assert response.status_code == 200
+
+    response = requests.get(f"{BASE_URL}/contacts", headers=headers)
+    assert response.status_code == 401
+
+
+def test_deactivate_account():
+    """A deactivated account keeps its data but cannot log in or use its tokens, reactivation links are checked."""
+    session = login_new_user()
+    headers = {"Authorization": f"Bearer {session['token']}"}
+    email = requests.get(f"{BASE_URL}/users/me", headers=headers).json()["email"]
+
+    response = requests.post(f"{BASE_URL}/users/me/deactivate", json={"password": "wrongpassword"}, headers=headers)
+    assert response.status_code == 403
+    response = requests.post(f"{BASE_URL}/users/me/deactivate", json={"password": "password1"}, headers=headers)
+    assert response.status_code == 200
+
+    response = requests.post(f"{BASE_URL}/login", json={"email": email, "password": "password1"})
+    assert response.status_code == 403
+    response = requests.post(f"{BASE_URL}/token/refresh", json={"refresh_token": session["refresh_token"]})
+    assert response.status_code == 401
+
+    response = requests.post(f"{BASE_URL}/users/reactivate", json={"email": email})
+    assert response.status_code in (202, 503)
+    response = requests.get(f"{BASE_URL}/users/reactivate/confirm", params={"token": "invalid"})
+    assert response.status_code == 404
+
+
+def test_contact_template_requires_admin(primary_user):
+    """Users read the contact template but only admins change it, unknown custom fields are refused."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    response = requests.get(f"{BASE_URL}/contact-template", headers=headers)
+    assert response.status_code == 200
+    assert isinstance(response.json()["fields"], list)
+
+    response = requests.put(f"{BASE_URL}/admin/contact-template/fields/tier", json={"label": "Tier", "type": "text"}, headers=headers)
+    assert response.status_code == 403
+    response = requests.put(f"{BASE_URL}/admin/contact-template/tags", json={"tags": ["crm"]}, headers=headers)
+    assert response.status_code == 403
+
+    payload = {"first_name": "custom_" + random_string(), "last_name": "fields", "phone_number": "0501234567",
+               "address": "somewhere", "custom_fields": {"no_such_field_" + random_string(): "x"}}
+    response = requests.post(f"{BASE_URL}/contacts", json=payload, headers=headers)
+    assert response.status_code == 400
+
+
+
+def test_shared_address_book(primary_user):
+    """Users share their own contacts and withdraw them, members cannot review submissions."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    payload = {"first_name": "shared_" + random_string(), "last_name": "book", "phone_number": "0501234567", "address": "somewhere"}
+    contact_id = requests.post(f"{BASE_URL}/contacts", json=payload, headers=headers).json()["contact_id"]
+
+    response = requests.post(f"{BASE_URL}/contacts/{contact_id}/share", headers=headers)
+    assert response.status_code == 200
+    assert response.json()["status"] in ("pending", "shared")
+    submissions = requests.get(f"{BASE_URL}/shared-contacts/submissions", headers=headers).json()["items"]
+    assert any(item["contact"]["id"] == contact_id for item in submissions)
+
+    response = requests.post(f"{BASE_URL}/shared-contacts/{contact_id}/approve", json={"note": "ok"}, headers=headers)
+    assert response.status_code in (403, 409)
+
+    response = requests.delete(f"{BASE_URL}/contacts/{contact_id}/share", headers=headers)
+    assert response.status_code == 200
+    response = requests.delete(f"{BASE_URL}/contacts/{contact_id}/share", headers=headers)
+    assert response.status_code == 404
+
+
+def test_sync_merges_fields(primary_user):
+    """Two offline edits of different fields of a contact are both kept, the same field edited twice is a conflict."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    payload = {"first_name": "sync_" + random_string(), "last_name": "merge", "phone_number": "0501234567", "address": "somewhere"}
+    contact_id = requests.post(f"{BASE_URL}/contacts", json=payload, headers=headers).json()["contact_id"]
+    base = requests.get(f"{BASE_URL}/sync/contacts/{contact_id}", headers=headers).json()["version"]
+
+    response = requests.patch(f"{BASE_URL}/sync/contacts/{contact_id}", json={"base_version": base, "changes": {"company": "Acme"}}, headers=headers)
+    assert response.status_code == 200
+    assert response.json()["applied"] == ["company"]
+
+    response = requests.patch(f"{BASE_URL}/sync/contacts/{contact_id}",
+                              json={"base_version": base, "changes": {"job_title": "CTO", "company": "Globex"}}, headers=headers)
+    assert response.status_code == 200
+    body = response.json()
+    assert body["applied"] == ["job_title"]
+    assert body["conflicts"] == [{"field": "company", "server_value": "Acme", "client_value": "Globex"}]
+    assert body["contact"]["company"] == "Acme"
+    assert body["contact"]["job_title"] == "CTO"
+    assert body["version"] > base
+
+def test_sync_batch(primary_user):
+    """A batch creates a contact by temp ID, updates it in the same batch, and is not applied twice when replayed."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    first_name = "batch_" + random_string()
+    batch = {
+        "batch_id": "batch-" + random_string(),
+        "mutations": [
+            {"op": "create", "temp_id": "tmp-1", "create": {"first_name": first_name, "last_name": "sync", "phone_number": "0501234567", "address": "somewhere"}},
+            {"op": "update", "temp_id": "tmp-1", "update": {"company": "Acme"}},
+        ],
+    }
+    response = requests.post(f"{BASE_URL}/sync/batch", json=batch, headers=headers)
+    assert response.status_code == 200
+    body = response.json()
+    assert body["committed"] is True
+    contact_id = body["id_map"]["tmp-1"]
+    assert [result["status"] for result in body["results"]] == ["applied", "applied"]
+    assert requests.get(f"{BASE_URL}/sync/contacts/{contact_id}", headers=headers).json()["contact"]["company"] == "Acme"
+
+    response = requests.post(f"{BASE_URL}/sync/batch", json=batch, headers=headers)
+    assert response.status_code == 200
+    assert response.json()["replayed"] is True
+    assert response.json()["id_map"] == {"tmp-1": contact_id}
+
+    failing = {"mutations": [
+        {"op": "create", "temp_id": "tmp-2", "create": {"first_name": "batch_" + random_string(), "last_name": "sync", "phone_number": "0501234567", "address": "somewhere"}},
+        {"op": "update", "temp_id": "tmp-3", "update": {"company": "Acme"}},
+    ]}
+    body = requests.post(f"{BASE_URL}/sync/batch", json=failing, headers=headers).json()
+    assert body["committed"] is False
+    assert [result["status"] for result in body["results"]] == ["skipped", "failed"]
+
+def test_contact_client_id(primary_user):
+    """A contact created with a client UUID can be read, updated and deleted by it, and the UUID cannot be reused."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    client_id = str(uuid.uuid4())
+    payload = {"first_name": "uuid_" + random_string(), "last_name": "client", "phone_number": "0501234567", "address": "somewhere", "client_id": client_id}
+    response = requests.post(f"{BASE_URL}/contacts", json=payload, headers=headers)
+    assert response.status_code == 201
+    contact_id = response.json()["contact_id"]
+
+    response = requests.get(f"{BASE_URL}/contacts/{client_id}", headers=headers)
+    assert response.status_code == 200
+    assert response.json()["id"] == contact_id
+    assert response.json()["client_id"] == client_id
+    assert requests.patch(f"{BASE_URL}/contacts/{client_id}", json={"company": "Acme"}, headers=headers).status_code == 200
+    assert requests.get(f"{BASE_URL}/contacts/{contact_id}", headers=headers).json()["company"] == "Acme"
+
+    payload["first_name"] = "uuid_" + random_string()
+    assert requests.post(f"{BASE_URL}/contacts", json=payload, headers=headers).status_code == 409
+    assert requests.get(f"{BASE_URL}/contacts/{uuid.uuid4()}", headers=headers).status_code == 404
+    assert requests.delete(f"{BASE_URL}/contacts/{client_id}", headers=headers).status_code == 200
+
+def test_embed_token(primary_user):
+    """An embed token lists the contacts of its saved search without logging in, until revoked."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    tag = "embed" + random_string().lower()
+    contact_id = create_contact(primary_user["token"], "embed_" + random_string(), "directory", "0501234567", "somewhere").json()["contact_id"]
+    requests.post(f"{BASE_URL}/contacts/{contact_id}/tags", json={"name": tag}, headers=headers)
+    requests.put(f"{BASE_URL}/contacts/{contact_id}/social/github", json={"value": "octo_embed"}, headers=headers)
+
+    response = requests.post(f"{BASE_URL}/embed-tokens", json={"name": "directory", "tag": tag, "group_id": 1}, headers=headers)
+    assert response.status_code == 400
+    response = requests.post(f"{BASE_URL}/embed-tokens", json={"name": "directory", "tag": tag}, headers=headers)
+    assert response.status_code == 201
+    token = response.json()
+
+    response = requests.get(f"{BASE_URL}/embed/contacts", params={"token": token["token"]})
+    assert response.status_code == 200
+    body = response.json()
+    assert body["name"] == "directory"
+    assert body["total_count"] == 1
+    assert body["items"][0]["last_name"] == "directory"
+    assert "id" not in body["items"][0] and "address" not in body["items"][0]
+    assert body["items"][0]["social_profiles"][0]["url"] == "https://github.com/octo_embed"
+
+    assert requests.delete(f"{BASE_URL}/embed-tokens/{token['id']}", headers=headers).status_code == 200
+    assert requests.get(f"{BASE_URL}/embed/contacts", params={"token": token["token"]}).status_code == 401
+    listed = requests.get(f"{BASE_URL}/embed-tokens", headers=headers).json()["items"]
+    assert [t["active"] for t in listed if t["id"] == token["id"]] == [False]
+
+def test_team_directory(primary_user):
+    """Members pick their own directory card, only admins configure the directory, unknown slugs are not found."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    contact_id = create_contact(primary_user["token"], "card_" + random_string(), "directory", "0501234567", "somewhere").json()["contact_id"]
+
+    assert requests.put(f"{BASE_URL}/users/me/directory-card", json={"contact_id": contact_id}, headers=headers).status_code == 200
+    assert requests.put(f"{BASE_URL}/users/me/directory-card", json={"contact_id": 999999999}, headers=headers).status_code == 404
+    assert requests.put(f"{BASE_URL}/users/me/directory-card", json={"contact_id": None}, headers=headers).status_code == 200
+
+    settings = {"enabled": True, "slug": "team", "source": "members", "fields": ["email"]}
+    assert requests.put(f"{BASE_URL}/admin/directory", json=settings, headers=headers).status_code == 403
+    assert requests.get(f"{BASE_URL}/directory/no-such-" + random_string().lower()).status_code == 404
+
+def test_duplicate_contacts():
+    """Contacts with the same phone and alike names are flagged with the score of each signal, per the sensitivity."""
+    token = login_new_user()["token"]
+    headers = {"Authorization": f"Bearer {token}"}
+    first = create_contact(token, "Jonathan", "Smith", "+12025550143", "somewhere").json()["contact_id"]
+    second = create_contact(token, "Jonathon", "Smith", "+1 202 555 0143", "elsewhere").json()["contact_id"]
+    create_contact(token, "Jane", "Smithers", "+12025550199", "somewhere")
+
+    response = requests.get(f"{BASE_URL}/contacts/duplicates", headers=headers)
+    assert response.status_code == 200
+    body = response.json()
+    assert body["sensitivity"] == "medium"
+    assert len(body["pairs"]) == 1
+    pair = body["pairs"][0]
+    assert [c["id"] for c in pair["contacts"]] == [first, second]
+    signals = {s["signal"]: s for s in pair["signals"]}
+    assert signals["phone_match"]["matched"] and not signals["email_match"]["matched"]
+    assert signals["name_similarity"]["score"] >= body["thresholds"]["name_similarity"]
+    assert pair["score"] == round(sum(s["contribution"] for s in pair["signals"]), 2)
+
+    response = requests.patch(f"{BASE_URL}/users/me/preferences", json={"duplicate_sensitivity": "low"}, headers=headers)
+    assert response.status_code == 200
+    assert response.json()["duplicate_sensitivity"] == "low"
+    body = requests.get(f"{BASE_URL}/contacts/duplicates", headers=headers).json()
+    assert body["thresholds"]["score"] > pair["score"] and body["pairs"] == []
+    assert requests.get(f"{BASE_URL}/contacts/duplicates?sensitivity=high", headers=headers).json()["pairs"] != []
+    assert requests.get(f"{BASE_URL}/contacts/duplicates?sensitivity=extreme", headers=headers).status_code == 400
+
+def test_phone_number_region_default():
+    """National numbers are validated for the region of the user, the error naming the region."""
+    session = login_new_user()
+    headers = {"Authorization": f"Bearer {session['token']}"}
+    payload = {"first_name": "region_" + random_string(), "last_name": "phone", "phone_number": "123"}
+    assert requests.post(f"{BASE_URL}/contacts", json=payload, headers=headers).status_code == 201
+
+    assert requests.patch(f"{BASE_URL}/users/me/preferences", json={"region": "IL"}, headers=headers).status_code == 200
+    payload["first_name"] = "region_" + random_string()
+    response = requests.post(f"{BASE_URL}/contacts", json=payload, headers=headers)
+    assert response.status_code == 400
+    assert "region IL" in response.json()["error"]
+
+    payload["phone_number"] = "050-123-4567"
+    response = requests.post(f"{BASE_URL}/contacts", json=payload, headers=headers)
+    assert response.status_code == 201
+    contact_id = response.json()["contact_id"]
+    response = requests.patch(f"{BASE_URL}/contacts/{contact_id}", json={"phone_number": "99"}, headers=headers)
+    assert response.status_code == 400
+    assert "region IL" in response.json()["error"]
+
+def test_service_errors_map_to_statuses():
+    """Errors of the same kind get the same status on every endpoint, with the message of the service."""
+    session = login_new_user()
+    headers = {"Authorization": f"Bearer {session['token']}"}
+    payload = {"first_name": "kind_" + random_string(), "last_name": "errors", "phone_number": "+12025550123"}
+    assert requests.post(f"{BASE_URL}/contacts", json=payload, headers=headers).status_code == 201
+    response = requests.post(f"{BASE_URL}/contacts", json=payload, headers=headers)
+    assert response.status_code == 409
+    assert "already exists" in response.json()["error"]
+
+    for method, path in [("get", "/contacts/999999999"), ("delete", "/contacts/999999999"), ("get", "/contacts/999999999/history")]:
+        response = getattr(requests, method)(f"{BASE_URL}{path}", headers=headers)
+        assert response.status_code == 404, path
+        assert "not found" in response.json()["error"]
+
+    response = requests.post(f"{BASE_URL}/login", json={"email": "nobody_" + random_string() + "@example.com", "password": "password1"})
+    assert response.status_code == 401
+
+def test_aborted_requests_release_connections():
+    """Requests whose client goes away release their connections, later requests are still served promptly."""
+    session = login_new_user()
+    headers = {"Authorization": f"Bearer {session['token']}"}
+    for i in range(50):
+        try:
+            requests.get(f"{BASE_URL}/contacts", params={"search": "aborted" + str(i)}, headers=headers, timeout=0.001)
+        except requests.exceptions.RequestException:
+            pass
+
+    for _ in range(5):
+        start = time.time()
+        response = requests.get(f"{BASE_URL}/contacts", headers=headers, timeout=5)
+        assert response.status_code == 200
+        assert time.time() - start < 2
+
+def test_maintenance_jobs_require_admin():
+    """Maintenance jobs rebuilding derived data are admin only, users can neither start nor list them."""
+    session = login_new_user()
+    headers = {"Authorization": f"Bearer {session['token']}"}
+    response = requests.post(f"{BASE_URL}/admin/maintenance/jobs", json={"operation": "reindex-search"}, headers=headers)
+    assert response.status_code == 403
+    assert requests.get(f"{BASE_URL}/admin/maintenance/jobs", headers=headers).status_code == 403
+    assert requests.get(f"{BASE_URL}/admin/maintenance/jobs/0123456789abcdef", headers=headers).status_code == 403
+
+def test_shared_contact_updates_marked_for_viewers():
+    """Shared contacts changed by their owner since another user viewed them are marked updated for that user."""
+    owner = {"Authorization": f"Bearer {login_new_user()['token']}"}
+    viewer = {"Authorization": f"Bearer {login_new_user()['token']}"}
+    response = requests.get(f"{BASE_URL}/users/me/notifications", headers=viewer)
+    assert response.status_code == 200
+    assert response.json()["events"]["shared_contact_updated"] == {"email": False, "in_app": True}
+    response = requests.patch(f"{BASE_URL}/users/me/notifications", json={"events": {"shared_contact_updated": {"sms": True}}}, headers=viewer)
+    assert response.status_code == 400
+
+    payload = {"first_name": "notified_" + random_string(), "last_name": "share", "phone_number": "+12025550123"}
+    contact_id = requests.post(f"{BASE_URL}/contacts", json=payload, headers=owner).json()["contact_id"]
+    if requests.post(f"{BASE_URL}/contacts/{contact_id}/share", headers=owner).json()["status"] != "shared":
+        return  # held for review on this deployment
+    assert requests.get(f"{BASE_URL}/shared-contacts/{contact_id}", headers=viewer).status_code == 200
+    assert requests.patch(f"{BASE_URL}/contacts/{contact_id}", json={"company": "Acme"}, headers=owner).status_code == 200
+
+    items = requests.get(f"{BASE_URL}/shared-contacts", headers=viewer).json()["items"]
+    assert next(item for item in items if item["contact"]["id"] == contact_id).get("updated_since_viewed") is True
+    requests.get(f"{BASE_URL}/shared-contacts/{contact_id}", headers=viewer)
+    items = requests.get(f"{BASE_URL}/shared-contacts", headers=viewer).json()["items"]
+    assert not next(item for item in items if item["contact"]["id"] == contact_id).get("updated_since_viewed")
+
+    response = requests.patch(f"{BASE_URL}/users/me/notifications", json={"events": {"shared_contact_updated": {"in_app": False}}}, headers=viewer)
+    assert response.status_code == 200
+    assert response.json()["events"]["shared_contact_updated"]["in_app"] is False
+
+def test_branding_is_public():
+    """The branding of the deployment is served without login, always with the name of the instance."""
+    response = requests.get(f"{BASE_URL}/branding")
+    assert response.status_code == 200
+    assert response.json()["instance_name"]
+    assert "email_footer" not in response.json()
+
+def test_cached_listing_filter_values_do_not_collide():
+    """A filter value holding the separators of the cache keys does not reuse the cached page of other filters."""
+    token = login_new_user()["token"]
+    headers = {"Authorization": f"Bearer {token}"}
+    first_name = "cache" + random_string()
+    create_contact(token, first_name, "collide", "0501234567", "somewhere")
+
+    response = requests.get(f"{BASE_URL}/contacts", headers=headers,
+                            params={"first_name": f"{first_name}:last_name=collide"})
+    assert response.status_code == 200
+    assert not response.json()["items"]
+
+    response = requests.get(f"{BASE_URL}/contacts", headers=headers,
+                            params={"first_name": first_name, "last_name": "collide"})
+    assert response.status_code == 200
+    assert len(response.json()["items"]) == 1
+
+
+def test_signup_refuses_disposable_email():
+    """Disposable email addresses cannot register."""
+    username = "disposable_" + random_string()
+    response = requests.post(f"{BASE_URL}/users", json={"user_name": username, "email": f"{username}@mailinator.com", "password": "password1"})
+    assert response.status_code == 403
+
+
+def test_username_change(primary_user):
+    """A renamed user keeps its former username reserved, lookups of it lead to the account, and has to wait to rename again."""
+    session = login_new_user()
+    headers = {"Authorization": f"Bearer {session['token']}"}
+    old_name = requests.get(f"{BASE_URL}/users/me", headers=headers).json()["user_name"]
+    new_name = "renamed_" + random_string()
+
+    response = requests.put(f"{BASE_URL}/users/me/username", json={"user_name": old_name}, headers=headers)
+    assert response.status_code == 400
+    response = requests.put(f"{BASE_URL}/users/me/username", json={"user_name": new_name}, headers=headers)
+    assert response.status_code == 200
+    profile = response.json()
+    assert profile["user_name"] == new_name
+    assert profile["username_history"][0]["old_user_name"] == old_name
+    assert profile["username_change_available_at"]
+
+    response = requests.put(f"{BASE_URL}/users/me/username", json={"user_name": "again_" + random_string()}, headers=headers)
+    assert response.status_code == 409
+
+    other_headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    response = requests.get(f"{BASE_URL}/users/by-username/{old_name}", headers=other_headers)
+    assert response.status_code == 200
+    assert response.json() == {"id": profile["id"], "user_name": new_name, "redirected_from": old_name}
+
+    response = requests.post(f"{BASE_URL}/users", json={"user_name": old_name, "email": f"{random_string()}@example.com", "password": "password1"})
+    assert response.status_code == 409
 
 
 # ---------------------------
@@ -427,6 +896,86 @@ def test_pagination_contacts(primary_user):
     assert isinstance(contacts_page2, list)
 
 
+def test_cursor_pagination_contacts():
+    """Walking the pages by cursor with a sort returns every contact once, in order."""
+    session = login_new_user()
+    headers = {"Authorization": f"Bearer {session['token']}"}
+    for last_name in ["delta", "alpha", "echo", "charlie", "bravo"]:
+        assert create_contact(session["token"], "cursor", last_name, "0001112222", "new address").status_code == 201
+
+    params = {"page_size": 2, "sort_by": "name", "sort_dir": "desc"}
+    names = []
+    while True:
+        response = requests.get(f"{BASE_URL}/contacts", headers=headers, params=params)
+        assert response.status_code == 200
+        body = response.json()
+        # Only the first page is counted, the pages reached by cursor skip the count
+        assert body["total_count"] == (0 if "cursor" in params else 5)
+        names += [item["last_name"] for item in body["items"]]
+        if "next_cursor" not in body:
+            break
+        params["cursor"] = body["next_cursor"]
+    assert names == ["echo", "delta", "charlie", "bravo", "alpha"]
+
+    # A cursor is only valid for the sort it was issued for
+    response = requests.get(f"{BASE_URL}/contacts", headers=headers, params={"sort_by": "created_at", "cursor": params["cursor"]})
+    assert response.status_code == 400
+    response = requests.get(f"{BASE_URL}/contacts", headers=headers, params={"sort_by": "phone_number"})
+    assert response.status_code == 400
+
+
+def test_pagination_ties_ordered_by_id():
+    """Contacts with the same sort value (imported in one transaction) are ordered by ID on every page."""
+    session = login_new_user()
+    headers = {"Authorization": f"Bearer {session['token']}"}
+    archive = {"format": "contact-app-account", "version": 1,
+               "contacts": [{"first_name": "Tie", "last_name": f"n{i}", "phone_number": f"050000000{i}", "address": "1 Tie St"}
+                            for i in range(5)]}
+    response = requests.post(f"{BASE_URL}/users/me/import", json=archive, headers=headers)
+    assert response.status_code == 200
+
+    for sort_dir in ["asc", "desc"]:
+        ids = []
+        for page in range(1, 4):
+            response = requests.get(f"{BASE_URL}/contacts", headers=headers,
+                                    params={"sort_by": "created_at", "sort_dir": sort_dir, "page": page, "page_size": 2})
+            ids += [item["id"] for item in response.json()["items"]]
+        assert len(ids) == 5
+        assert ids == sorted(ids, reverse=sort_dir == "desc")
+
+
+def test_search_contacts():
+    """The q parameter searches names, phone numbers and addresses together, best matches first and despite typos."""
+    session = login_new_user()
+    headers = {"Authorization": f"Bearer {session['token']}"}
+    assert create_contact(session["token"], "Jonathan", "Whitaker", "0501234567", "12 Baker Street").status_code == 201
+    assert create_contact(session["token"], "Maria", "Jonas", "0509876543", "7 Elm Road").status_code == 201
+    assert create_contact(session["token"], "Peter", "Parker", "0505550000", "20 Ingram Street").status_code == 201
+
+    response = requests.get(f"{BASE_URL}/contacts", headers=headers, params={"q": "Whitakr"})
+    assert response.status_code == 200
+    assert [item["last_name"] for item in response.json()["items"]] == ["Whitaker"]
+
+    response = requests.get(f"{BASE_URL}/contacts", headers=headers, params={"q": "Baker Street"})
+    assert response.status_code == 200
+    assert response.json()["items"][0]["first_name"] == "Jonathan"
+
+    response = requests.get(f"{BASE_URL}/contacts", headers=headers, params={"q": "0509876"})
+    assert [item["first_name"] for item in response.json()["items"]] == ["Maria"]
+
+    # Pages ranked by relevance are walked by cursor like any other listing
+    params = {"q": "Street", "page_size": 1}
+    names = []
+    while True:
+        response = requests.get(f"{BASE_URL}/contacts", headers=headers, params=params)
+        assert response.status_code == 200
+        names += [item["first_name"] for item in response.json()["items"]]
+        if "next_cursor" not in response.json():
+            break
+        params["cursor"] = response.json()["next_cursor"]
+    assert sorted(names) == ["Jonathan", "Peter"]
+
+
 # ---------------------------
 # Delete Contact Tests
 # ---------------------------
@@ -468,3 +1017,1180 @@ def test_delete_same_contact_again(primary_user):
     # Attempt to delete the same contact a second time
     del_response2 = delete_contact(primary_user["token"], contact_id)
     assert del_response2.status_code == 404
+
+
+def test_contact_completeness(primary_user):
+    """A contact is scored on its optional fields and listed as incomplete while it misses one."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    response = create_contact(primary_user["token"], "partial_" + random_string(), "bd", "0501234567", "somewhere")
+    contact_id = response.json()["contact_id"]
+
+    response = requests.get(f"{BASE_URL}/contacts/{contact_id}/completeness", headers=headers)
+    assert response.status_code == 200
+    completeness = response.json()
+    assert completeness["filled"] == ["phone_number", "address"]
+    assert "email" in completeness["missing"]
+    assert completeness["score"] == 33
+
+    response = requests.get(f"{BASE_URL}/contacts/incomplete", params={"missing": "email", "page_size": 100}, headers=headers)
+    assert response.status_code == 200
+    assert all("email" in item["missing"] for item in response.json()["items"])
+    response = requests.get(f"{BASE_URL}/contacts/incomplete", params={"missing": "first_name"}, headers=headers)
+    assert response.status_code == 400
+
+
+def test_trash_restore_and_history(primary_user):
+    """Deleted contacts go to the trash, can be restored and keep their history until deleted permanently."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    response = create_contact(primary_user["token"], "trash_" + random_string(), "bd", "0501234567", "somewhere")
+    contact_id = response.json()["contact_id"]
+    requests.patch(f"{BASE_URL}/contacts/{contact_id}", json={"address": "elsewhere"}, headers=headers)
+
+    assert delete_contact(primary_user["token"], contact_id).status_code == 200
+    assert requests.get(f"{BASE_URL}/contacts/{contact_id}", headers=headers).status_code == 404
+    response = requests.get(f"{BASE_URL}/contacts/trash", headers=headers)
+    assert response.status_code == 200
+    trashed = next(c for c in response.json()["items"] if c["id"] == contact_id)
+    assert trashed["deleted_at"]
+    response = requests.get(f"{BASE_URL}/contacts/trash/{contact_id}", headers=headers)
+    assert response.status_code == 200
+    detail = response.json()
+    assert detail["address"] == "elsewhere"
+    assert detail["deleted_at"] and detail["purge_at"] > detail["deleted_at"]
+    assert detail["deleted_by"] == detail["user_id"]
+    assert detail["groups"] == [] and detail["tags"] == []
+
+    response = requests.post(f"{BASE_URL}/contacts/{contact_id}/restore", headers=headers)
+    assert response.status_code == 200
+    assert response.json()["address"] == "elsewhere"
+    response = requests.post(f"{BASE_URL}/contacts/{contact_id}/restore", headers=headers)
+    assert response.status_code == 404
+    assert requests.get(f"{BASE_URL}/contacts/trash/{contact_id}", headers=headers).status_code == 404
+
+    response = requests.get(f"{BASE_URL}/contacts/{contact_id}/history", headers=headers)
+    assert response.status_code == 200
+    items = response.json()["items"]
+    assert [item["action"] for item in items] == ["created", "updated", "deleted", "restored"]
+    assert items[1]["changes"] == {"address": {"from": "somewhere", "to": "elsewhere"}}
+
+    response = requests.delete(f"{BASE_URL}/contacts/{contact_id}", params={"permanent": "true"}, headers=headers)
+    assert response.status_code == 200
+    assert requests.get(f"{BASE_URL}/contacts/{contact_id}/history", headers=headers).status_code == 404
+    response = requests.get(f"{BASE_URL}/contacts/trash", headers=headers)
+    assert contact_id not in [c["id"] for c in response.json()["items"]]
+
+
+# ---------------------------
+# Picklist (source / stage) Tests
+# ---------------------------
+def test_get_stage_picklist(primary_user):
+    """The stage picklist is seeded with default values."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    response = requests.get(f"{BASE_URL}/picklists/stage", headers=headers)
+    assert response.status_code == 200
+    assert "lead" in response.json().get("values", [])
+
+
+def test_create_contact_invalid_stage(primary_user):
+    """Creating a contact with a stage outside the picklist should fail with 400."""
+    url = f"{BASE_URL}/contacts"
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    payload = {
+        "first_name": "stage_" + random_string(),
+        "last_name": "bd",
+        "phone_number": "0501234567",
+        "address": "tel aviv",
+        "stage": "not_a_stage"
+    }
+    response = requests.post(url, json=payload, headers=headers)
+    assert response.status_code == 400
+
+
+def test_contact_stats_by_stage(primary_user):
+    """Contacts created with a stage are aggregated in the stats endpoint."""
+    url = f"{BASE_URL}/contacts"
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    payload = {
+        "first_name": "stage_" + random_string(),
+        "last_name": "bd",
+        "phone_number": "0501234567",
+        "address": "tel aviv",
+        "stage": "lead"
+    }
+    response = requests.post(url, json=payload, headers=headers)
+    assert response.status_code == 201
+
+    response = requests.get(f"{BASE_URL}/contacts/stats", headers=headers)
+    assert response.status_code == 200
+    assert response.json()["by_stage"].get("lead", 0) >= 1
+
+
+def test_add_picklist_value_not_admin(primary_user):
+    """Regular users cannot manage picklist values."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    response = requests.post(f"{BASE_URL}/admin/picklists/stage", json={"value": "won"}, headers=headers)
+    assert response.status_code == 403
+
+
+# ---------------------------
+# Stage Board Tests
+# ---------------------------
+def test_board_move_contact(primary_user):
+    """Moving a contact to a stage places it in that board column."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    response = create_contact(primary_user["token"], "board_" + random_string(), "bd", "0509999999", "haifa")
+    assert response.status_code == 201
+    contact_id = response.json().get("contact_id")
+
+    response = requests.post(f"{BASE_URL}/contacts/{contact_id}/move",
+                             json={"stage": "prospect", "position": 1}, headers=headers)
+    assert response.status_code == 200
+
+    response = requests.get(f"{BASE_URL}/contacts/board", headers=headers,
+                            params={"field": "stage", "column": "prospect"})
+    assert response.status_code == 200
+    columns = response.json()["columns"]
+    assert len(columns) == 1
+    assert columns[0]["items"][0]["id"] == contact_id
+
+
+def test_stage_update_appends_to_board_column(primary_user):
+    """Changing the stage of a contact with an update appends it to the end of its new board column."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    contact_ids = []
+    for _ in range(2):
+        response = create_contact(primary_user["token"], "board_" + random_string(), "bd", "0509999999", "haifa")
+        assert response.status_code == 201
+        contact_ids.append(response.json().get("contact_id"))
+
+    response = requests.post(f"{BASE_URL}/contacts/{contact_ids[0]}/move",
+                             json={"stage": "customer", "position": 1}, headers=headers)
+    assert response.status_code == 200
+    response = requests.patch(f"{BASE_URL}/contacts/{contact_ids[1]}", json={"stage": "customer"}, headers=headers)
+    assert response.status_code == 200
+
+    params = {"field": "stage", "column": "customer", "page_size": 100}
+    response = requests.get(f"{BASE_URL}/contacts/board", headers=headers, params=params)
+    assert response.status_code == 200
+    last_page = response.json()["columns"][0]["total_pages"]
+    response = requests.get(f"{BASE_URL}/contacts/board", headers=headers, params={**params, "page": last_page})
+    assert response.status_code == 200
+    assert response.json()["columns"][0]["items"][-1]["id"] == contact_ids[1]
+
+
+def test_board_move_invalid_stage(primary_user, contact1):
+    """Moving a contact to a stage outside the picklist should fail with 400."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    response = requests.post(f"{BASE_URL}/contacts/{contact1}/move", json={"stage": "nowhere"}, headers=headers)
+    assert response.status_code == 400
+
+
+# ---------------------------
+# Attachment Tests
+# ---------------------------
+def test_attachment_upload_and_download(primary_user, contact1):
+    """Upload a text attachment, then download it through a signed link."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    files = {"file": ("notes.txt", b"met at the conference", "text/plain")}
+    response = requests.post(f"{BASE_URL}/contacts/{contact1}/attachments", files=files, headers=headers)
+    assert response.status_code == 201
+    attachment_id = response.json()["id"]
+
+    response = requests.get(f"{BASE_URL}/contacts/{contact1}/attachments/{attachment_id}/url", headers=headers)
+    assert response.status_code == 200
+    download = requests.get(BASE_URL + response.json()["url"])
+    assert download.status_code == 200
+    assert download.content == b"met at the conference"
+
+
+def test_storage_usage_follows_attachments():
+    """The storage used counts the attachments of the user and drops with the contacts deleted permanently."""
+    session = login_new_user()
+    headers = {"Authorization": f"Bearer {session['token']}"}
+    first = create_contact(session["token"], "Storage", "one", "0501110001", "1 Disk St").json()["contact_id"]
+    second = create_contact(session["token"], "Storage", "two", "0501110002", "2 Disk St").json()["contact_id"]
+    for contact_id, content in ((first, b"12345"), (second, b"1234567890")):
+        files = {"file": ("notes.txt", content, "text/plain")}
+        assert requests.post(f"{BASE_URL}/contacts/{contact_id}/attachments", files=files, headers=headers).status_code == 201
+
+    response = requests.get(f"{BASE_URL}/contacts/{first}/attachments", headers=headers)
+    assert response.json()["storage"]["used_bytes"] == 15
+
+    response = requests.delete(f"{BASE_URL}/contacts/{second}", params={"permanent": "true"}, headers=headers)
+    assert response.status_code == 200
+    response = requests.get(f"{BASE_URL}/contacts/{first}/attachments", headers=headers)
+    assert response.json()["storage"]["used_bytes"] == 5
+
+
+def blob_files_containing(content):
+    """Lists the files of the blob store of the server holding content, BLOB_DIR points the tests at it."""
+    blob_dir = os.environ.get("BLOB_DIR", os.path.join("data", "blobs"))
+    if not os.path.isdir(blob_dir):
+        pytest.skip("the blob store of the server is not reachable, set BLOB_DIR")
+    found = []
+    for root, _, names in os.walk(blob_dir):
+        for name in names:
+            path = os.path.join(root, name)
+            with open(path, "rb") as f:
+                if f.read() == content:
+                    found.append(path)
+    return found
+
+
+def test_permanent_delete_removes_attachment_files():
+    """Deleting a contact permanently deletes the files of its attachments from the blob store."""
+    session = login_new_user()
+    headers = {"Authorization": f"Bearer {session['token']}"}
+    contact_id = create_contact(session["token"], "Blob", "purge", "0501110003", "3 Disk St").json()["contact_id"]
+    content = ("purged " + random_string()).encode()
+    files = {"file": ("notes.txt", content, "text/plain")}
+    assert requests.post(f"{BASE_URL}/contacts/{contact_id}/attachments", files=files, headers=headers).status_code == 201
+    assert len(blob_files_containing(content)) == 1
+
+    response = requests.delete(f"{BASE_URL}/contacts/{contact_id}", params={"permanent": "true"}, headers=headers)
+    assert response.status_code == 200
+    assert blob_files_containing(content) == []
+
+
+def test_attachment_download_bad_signature(primary_user):
+    """A download link with a forged signature is rejected."""
+    response = requests.get(f"{BASE_URL}/attachments/1/download", params={"expires": 9999999999, "signature": "bad"})
+    assert response.status_code == 403
+
+
+def test_attachment_disallowed_type(primary_user, contact1):
+    """Executable content is rejected with 415."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    files = {"file": ("tool.exe", b"MZ\x90\x00\x03\x00\x00\x00\x04\x00\x00\x00\xff\xff", "application/octet-stream")}
+    response = requests.post(f"{BASE_URL}/contacts/{contact1}/attachments", files=files, headers=headers)
+    assert response.status_code == 415
+
+
+# ---------------------------
+# Social Profile Tests
+# ---------------------------
+def test_set_social_profile_normalizes_url(primary_user, contact1):
+    """A profile URL is normalized to the handle and canonical URL."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    response = requests.put(f"{BASE_URL}/contacts/{contact1}/social/x",
+                            json={"value": "https://twitter.com/jane_doe"}, headers=headers)
+    assert response.status_code == 200
+    assert response.json()["handle"] == "jane_doe"
+    assert response.json()["url"] == "https://x.com/jane_doe"
+
+    response = requests.get(f"{BASE_URL}/contacts", headers=headers, params={"social": "jane_doe"})
+    assert response.status_code == 200
+    assert any(item["id"] == contact1 for item in response.json()["items"])
+
+
+def test_export_social_profiles(primary_user, contact1):
+    """Social profiles are exported as CSV columns and vCard X-SOCIALPROFILE properties."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    response = requests.put(f"{BASE_URL}/contacts/{contact1}/social/github",
+                            json={"value": "octo_export"}, headers=headers)
+    assert response.status_code == 200
+
+    response = requests.get(f"{BASE_URL}/contacts/export", params={"format": "csv"}, headers=headers)
+    assert response.status_code == 200
+    assert "social_github" in response.text.splitlines()[0]
+    assert "https://github.com/octo_export" in response.text
+
+    response = requests.get(f"{BASE_URL}/contacts/export", params={"format": "vcf"}, headers=headers)
+    assert response.status_code == 200
+    assert "X-SOCIALPROFILE;TYPE=github:https://github.com/octo_export" in response.text
+
+
+def test_set_social_profile_wrong_host(primary_user, contact1):
+    """A URL on another host is rejected."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    response = requests.put(f"{BASE_URL}/contacts/{contact1}/social/github",
+                            json={"value": "https://gitlab.com/jane"}, headers=headers)
+    assert response.status_code == 400
+
+
+# ---------------------------
+# Timezone Tests
+# ---------------------------
+def test_contact_local_time(primary_user):
+    """A contact with a timezone is returned with its local time and working hours flag."""
+    url = f"{BASE_URL}/contacts"
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    first_name = "tz_" + random_string()
+    payload = {
+        "first_name": first_name,
+        "last_name": "bd",
+        "phone_number": "0501234567",
+        "address": "new york",
+        "timezone": "America/New_York"
+    }
+    response = requests.post(url, json=payload, headers=headers)
+    assert response.status_code == 201
+
+    response = requests.get(url, headers=headers, params={"first_name": first_name})
+    item = response.json()["items"][0]
+    assert item["local_time"]
+    assert isinstance(item["within_working_hours"], bool)
+
+
+def test_contact_local_time_estimated_from_location(primary_user):
+    """A contact without a timezone gets a local time estimated from its longitude."""
+    url = f"{BASE_URL}/contacts"
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    first_name = "tz_" + random_string()
+    payload = {
+        "first_name": first_name,
+        "last_name": "bd",
+        "phone_number": "0501234567",
+        "address": "tokyo",
+        "latitude": 35.68,
+        "longitude": 139.69
+    }
+    response = requests.post(url, json=payload, headers=headers)
+    assert response.status_code == 201
+
+    response = requests.get(url, headers=headers, params={"first_name": first_name})
+    item = response.json()["items"][0]
+    assert item["local_time"].endswith("+09:00")
+    assert item["local_time_estimated"] is True
+
+
+def test_contact_invalid_timezone(primary_user):
+    """Unknown timezones are rejected."""
+    payload = {
+        "first_name": "tz_" + random_string(),
+        "last_name": "bd",
+        "phone_number": "0501234567",
+        "address": "nowhere",
+        "timezone": "Mars/Olympus"
+    }
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    response = requests.post(f"{BASE_URL}/contacts", json=payload, headers=headers)
+    assert response.status_code == 400
+
+
+# ---------------------------
+# International Address Tests
+# ---------------------------
+def test_contact_structured_address_formatted(primary_user):
+    """A structured address is formatted following the conventions of its country."""
+    url = f"{BASE_URL}/contacts"
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    first_name = "addr_" + random_string()
+    payload = {
+        "first_name": first_name,
+        "last_name": "bd",
+        "phone_number": "0501234567",
+        "street": "Unter den Linden 77",
+        "city": "Berlin",
+        "postal_code": "10117",
+        "country_code": "de"
+    }
+    response = requests.post(url, json=payload, headers=headers)
+    assert response.status_code == 201
+
+    response = requests.get(url, headers=headers, params={"first_name": first_name})
+    item = response.json()["items"][0]
+    assert item["formatted_address"] == ["Unter den Linden 77", "10117 Berlin", "DE"]
+    assert item["address"] == "Unter den Linden 77, 10117 Berlin, DE"
+
+
+def test_contact_invalid_postal_code(primary_user):
+    """A postal code not matching the country format is rejected."""
+    payload = {
+        "first_name": "addr_" + random_string(),
+        "last_name": "bd",
+        "phone_number": "0501234567",
+        "street": "1600 Amphitheatre Pkwy",
+        "city": "Mountain View",
+        "region": "CA",
+        "postal_code": "ABC",
+        "country_code": "US"
+    }
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    response = requests.post(f"{BASE_URL}/contacts", json=payload, headers=headers)
+    assert response.status_code == 400
+
+
+# ---------------------------
+# Map View Tests
+# ---------------------------
+def test_contacts_geojson(primary_user):
+    """Contacts with coordinates are returned as GeoJSON points."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    first_name = "geo_" + random_string()
+    payload = {
+        "first_name": first_name,
+        "last_name": "bd",
+        "phone_number": "0501234567",
+        "address": "Berlin",
+        "latitude": 52.5163,
+        "longitude": 13.3777
+    }
+    response = requests.post(f"{BASE_URL}/contacts", json=payload, headers=headers)
+    assert response.status_code == 201
+
+    response = requests.get(f"{BASE_URL}/contacts/geojson", headers=headers, params={"first_name": first_name})
+    assert response.status_code == 200
+    body = response.json()
+    assert body["type"] == "FeatureCollection"
+    assert len(body["features"]) == 1
+    assert body["features"][0]["geometry"]["coordinates"] == [13.3777, 52.5163]
+
+
+def test_contact_partial_location(primary_user):
+    """Latitude without longitude is rejected."""
+    payload = {
+        "first_name": "geo_" + random_string(),
+        "last_name": "bd",
+        "phone_number": "0501234567",
+        "address": "Berlin",
+        "latitude": 52.5163
+    }
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    response = requests.post(f"{BASE_URL}/contacts", json=payload, headers=headers)
+    assert response.status_code == 400
+
+
+# ---------------------------
+# Audit Log Tests
+# ---------------------------
+def test_audit_export_csv(primary_user, contact1):
+    """The audit export streams the user's history as CSV."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    response = requests.get(f"{BASE_URL}/audit/export", headers=headers,
+                            params={"action": "contact.created", "format": "csv"})
+    assert response.status_code == 200
+    assert response.headers["Content-Type"].startswith("text/csv")
+    lines = response.text.strip().splitlines()
+    assert lines[0] == "id,created_at,user_id,actor_id,action,entity_type,entity_id,details"
+    assert len(lines) > 1
+    assert all(line.split(",")[4] == "contact.created" for line in lines[1:])
+
+
+def test_audit_login_records_client_ip(primary_user):
+    """Logins are audited with the client IP, a spoofed X-Forwarded-For from an untrusted peer is ignored."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    response = requests.post(f"{BASE_URL}/login", json={"email": primary_user["email"], "password": "password1"},
+                             headers={"X-Forwarded-For": "203.0.113.250"})
+    assert response.status_code == 200
+
+    response = requests.get(f"{BASE_URL}/audit/export", headers=headers, params={"action": "user.login"})
+    assert response.status_code == 200
+    last_login = response.text.strip().splitlines()[-1]
+    assert '""ip""' in last_login
+    assert "203.0.113.250" not in last_login
+
+
+def test_audit_export_invalid_range(primary_user):
+    """A range ending before it starts is rejected."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    response = requests.get(f"{BASE_URL}/audit/export", headers=headers,
+                            params={"from": "2025-02-01", "to": "2025-01-01"})
+    assert response.status_code == 400
+
+
+# ---------------------------
+# Group and Tag Tests
+# ---------------------------
+def test_bulk_group_assignment(primary_user):
+    """Many contacts are attached to a group at once and listed through the group filter."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    contact_ids = []
+    for _ in range(3):
+        response = create_contact(primary_user["token"], "grp_" + random_string(), "bd", "0501234567", "somewhere")
+        assert response.status_code == 201
+        contact_ids.append(response.json()["contact_id"])
+
+    response = requests.post(f"{BASE_URL}/groups", json={"name": "grp_" + random_string()}, headers=headers)
+    assert response.status_code == 201
+    group_id = response.json()["id"]
+
+    response = requests.post(f"{BASE_URL}/groups/{group_id}/contacts", json={"contact_ids": contact_ids}, headers=headers)
+    assert response.status_code == 200
+    assert response.json() == {"requested": 3, "changed": 3}
+
+    response = requests.get(f"{BASE_URL}/contacts", headers=headers, params={"group": group_id})
+    assert response.json()["total_count"] == 3
+
+    response = requests.delete(f"{BASE_URL}/groups/{group_id}/contacts", json={"contact_ids": contact_ids[:1]}, headers=headers)
+    assert response.json()["changed"] == 1
+
+    response = requests.get(f"{BASE_URL}/contacts", headers=headers, params={"group": group_id})
+    assert response.json()["total_count"] == 2
+
+
+def test_bulk_tag_foreign_contact(primary_user, secondary_user):
+    """Tagging contacts of another user is rejected."""
+    response = create_contact(secondary_user["token"], "tag_" + random_string(), "bd", "0501234567", "somewhere")
+    foreign_id = response.json()["contact_id"]
+
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    response = requests.post(f"{BASE_URL}/tags/work/contacts", json={"contact_ids": [foreign_id]}, headers=headers)
+    assert response.status_code == 404
+
+
+def test_tag_contact_and_filter(primary_user):
+    """A tag attached to a contact filters the contacts listing, even once the unfiltered listing is cached."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    tag = "tag" + random_string().lower()
+    response = requests.post(f"{BASE_URL}/tags", json={"name": tag}, headers=headers)
+    assert response.status_code == 201
+    response = requests.post(f"{BASE_URL}/tags", json={"name": tag.upper()}, headers=headers)
+    assert response.status_code == 409
+
+    response = create_contact(primary_user["token"], "tagged_" + random_string(), "bd", "0501234567", "somewhere")
+    contact_id = response.json()["contact_id"]
+    response = requests.get(f"{BASE_URL}/contacts", params={"tag": tag}, headers=headers)
+    assert response.json()["total_count"] == 0
+
+    response = requests.post(f"{BASE_URL}/contacts/{contact_id}/tags", json={"name": tag}, headers=headers)
+    assert response.status_code == 200
+    assert [t["name"] for t in response.json()["tags"]] == [tag]
+
+    response = requests.get(f"{BASE_URL}/contacts", params={"tag": tag.upper()}, headers=headers)
+    assert response.json()["total_count"] == 1
+    response = requests.get(f"{BASE_URL}/tags", headers=headers)
+    assert any(t["name"] == tag and t["contact_count"] == 1 for t in response.json()["items"])
+
+    response = requests.delete(f"{BASE_URL}/contacts/{contact_id}/tags/{tag}", headers=headers)
+    assert response.status_code == 200
+    assert response.json()["tags"] == []
+    response = requests.get(f"{BASE_URL}/contacts", params={"tag": tag}, headers=headers)
+    assert response.json()["total_count"] == 0
+
+    response = requests.delete(f"{BASE_URL}/tags/{tag}", headers=headers)
+    assert response.status_code == 200
+    response = requests.delete(f"{BASE_URL}/tags/{tag}", headers=headers)
+    assert response.status_code == 404
+
+
+# ---------------------------
+# Snapshot Tests
+# ---------------------------
+def test_snapshot_diff_and_restore(primary_user):
+    """A snapshot diff reports changes made since and restoring it reverts them."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    response = create_contact(primary_user["token"], "snap_" + random_string(), "bd", "0501234567", "somewhere")
+    contact_id = response.json()["contact_id"]
+
+    response = requests.post(f"{BASE_URL}/snapshots", json={"name": "before edit"}, headers=headers)
+    assert response.status_code == 201
+    snapshot_id = response.json()["id"]
+
+    requests.patch(f"{BASE_URL}/contacts/{contact_id}", json={"address": "elsewhere"}, headers=headers)
+    response = create_contact(primary_user["token"], "snap_" + random_string(), "bd", "0501234567", "somewhere")
+    added_id = response.json()["contact_id"]
+
+    response = requests.get(f"{BASE_URL}/snapshots/{snapshot_id}/diff", headers=headers)
+    assert response.status_code == 200
+    diff = response.json()
+    assert [c["id"] for c in diff["added"]] == [added_id]
+    changed = next(c for c in diff["changed"] if c["id"] == contact_id)
+    assert {"field": "address", "snapshot": "somewhere", "current": "elsewhere"} in changed["changes"]
+
+    response = requests.post(f"{BASE_URL}/snapshots/{snapshot_id}/restore", headers=headers)
+    assert response.status_code == 200
+    assert response.json()["deleted"] == 1
+
+    response = requests.get(f"{BASE_URL}/snapshots/{snapshot_id}/diff", headers=headers)
+    assert response.json() == {"snapshot_id": snapshot_id, "added": [], "removed": [], "changed": []}
+
+
+# ---------------------------
+# Preferences Tests
+# ---------------------------
+def test_weekly_digest_opt_in(primary_user):
+    """The weekly digest is opt-in and can be toggled."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    response = requests.get(f"{BASE_URL}/users/me/preferences", headers=headers)
+    assert response.status_code == 200
+    assert response.json()["weekly_digest"] is False
+    assert response.json()["region"] == ""
+
+    response = requests.patch(f"{BASE_URL}/users/me/preferences", json={"weekly_digest": True}, headers=headers)
+    assert response.status_code == 200
+    assert response.json()["weekly_digest"] is True
+
+    response = requests.patch(f"{BASE_URL}/users/me/preferences", json={}, headers=headers)
+    assert response.json()["weekly_digest"] is True
+
+
+def test_phone_number_formatted_for_region():
+    """Phone numbers are displayed in national format for readers of their country, international otherwise."""
+    session = login_new_user()
+    headers = {"Authorization": f"Bearer {session['token']}"}
+    response = requests.post(f"{BASE_URL}/contacts", json={"first_name": "Noa", "last_name": "Levi", "phone_number": "050-123-4567",
+                                                          "street": "Rothschild 1", "city": "Tel Aviv", "country_code": "IL"}, headers=headers)
+    assert response.status_code == 201
+    contact_id = response.json()["contact_id"]
+
+    response = requests.get(f"{BASE_URL}/contacts/{contact_id}", headers={**headers, "Accept-Language": "en-US,en;q=0.9"})
+    assert response.json()["phone_number_e164"] == "+972501234567"
+    assert response.json()["phone_number_formatted"] == "+972 50-123-4567"
+
+    response = requests.patch(f"{BASE_URL}/users/me/preferences", json={"region": "il"}, headers=headers)
+    assert response.status_code == 200
+    assert response.json()["region"] == "IL"
+    response = requests.get(f"{BASE_URL}/contacts", headers={**headers, "Accept-Language": "en-US"})
+    assert response.json()["items"][0]["phone_number_formatted"] == "050-123-4567"
+
+    response = requests.patch(f"{BASE_URL}/users/me/preferences", json={"region": "XX"}, headers=headers)
+    assert response.status_code == 400
+
+
+
+def test_search_language_stems_words():
+    """Contacts are searched by word in the search language of their owner."""
+    session = login_new_user()
+    headers = {"Authorization": f"Bearer {session['token']}"}
+    response = requests.patch(f"{BASE_URL}/users/me/preferences", json={"search_language": "English"}, headers=headers)
+    assert response.status_code == 200
+    assert response.json()["search_language"] == "english"
+
+    response = requests.post(f"{BASE_URL}/contacts", json={"first_name": "jane", "last_name": "doe", "phone_number": "1234567890",
+                                                          "address": "tel aviv", "company": "Consulting Partners"}, headers=headers)
+    assert response.status_code == 201
+    contact_id = response.json()["contact_id"]
+
+    response = requests.get(f"{BASE_URL}/contacts", params={"q": "consult"}, headers=headers)
+    assert response.status_code == 200
+    assert [item["contact_id"] for item in response.json()["items"]] == [contact_id]
+
+    response = requests.patch(f"{BASE_URL}/users/me/preferences", json={"search_language": "klingon"}, headers=headers)
+    assert response.status_code == 400
+
+# ---------------------------
+# Webhook Tests
+# ---------------------------
+def test_webhook_register_and_test(primary_user):
+    """A webhook secret is only shown once and a test delivery reports the endpoint outcome."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    response = requests.post(f"{BASE_URL}/webhooks", json={"url": "ftp://example.com/hook"}, headers=headers)
+    assert response.status_code == 400
+
+    # Addresses of the server and its network are refused, they would let users probe it
+    for url in ("http://127.0.0.1:9/hook", "http://169.254.169.254/latest", "http://10.0.0.1/hook", "http://localhost/hook"):
+        response = requests.post(f"{BASE_URL}/webhooks", json={"url": url}, headers=headers)
+        assert response.status_code == 400, url
+
+    # The .invalid domain never resolves so the delivery fails without leaving the host
+    response = requests.post(f"{BASE_URL}/webhooks", json={"url": "http://hook.invalid/hook"}, headers=headers)
+    assert response.status_code == 201
+    webhook = response.json()
+    assert webhook["secret"].startswith("whsec_")
+
+    response = requests.get(f"{BASE_URL}/webhooks", headers=headers)
+    listed = [item for item in response.json()["items"] if item["id"] == webhook["id"]]
+    assert len(listed) == 1 and "secret" not in listed[0]
+
+    response = requests.post(f"{BASE_URL}/webhooks/test", json={"webhook_id": webhook["id"]}, headers=headers)
+    assert response.status_code == 200
+    result = response.json()
+    assert result["delivered"] is False
+    assert result["event_id"].startswith("evt_")
+    assert result["error"] == "endpoint could not be reached"
+
+    response = requests.delete(f"{BASE_URL}/webhooks/{webhook['id']}", headers=headers)
+    assert response.status_code == 200
+    response = requests.post(f"{BASE_URL}/webhooks/test", json={"webhook_id": webhook["id"]}, headers=headers)
+    assert response.status_code == 404
+
+
+def test_webhook_deliveries_are_logged(secondary_user):
+    """Contact events are stored as deliveries, a failed one stays pending with its error until retried."""
+    session = login_new_user()
+    headers = {"Authorization": f"Bearer {session['token']}"}
+    response = requests.post(f"{BASE_URL}/webhooks", json={"url": "http://hook.invalid/hook"}, headers=headers)
+    assert response.status_code == 201
+    webhook_id = response.json()["id"]
+
+    assert create_contact(session["token"], "Hook", random_string(), "0501234567", "1 Hook St").status_code == 201
+    deliveries = []
+    for _ in range(20):
+        deliveries = requests.get(f"{BASE_URL}/webhooks/{webhook_id}/deliveries", headers=headers).json()["items"]
+        if deliveries and deliveries[0]["attempts"] > 0:
+            break
+        time.sleep(0.25)
+    assert len(deliveries) == 1
+    delivery = deliveries[0]
+    assert delivery["event_type"] == "contact.created"
+    assert delivery["event_id"].startswith("evt_")
+    assert delivery["status"] == "pending" and delivery["error"] and delivery["next_attempt_at"]
+
+    response = requests.get(f"{BASE_URL}/webhooks/{webhook_id}/deliveries",
+                            headers={"Authorization": f"Bearer {secondary_user['token']}"})
+    assert response.status_code == 404
+
+
+# ---------------------------
+# API Key and Signed Request Tests
+# ---------------------------
+def sign_request(secret, method, path, body):
+    """Builds the signature headers of a request signed with an API key secret."""
+    timestamp = str(int(time.time()))
+    nonce = random_string(24)
+    body_hash = hashlib.sha256(body).hexdigest()
+    content = "\n".join([method, path, timestamp, nonce, body_hash])
+    signature = "v1=" + hmac.new(secret.encode(), content.encode(), hashlib.sha256).hexdigest()
+    return {"X-Signature-Timestamp": timestamp, "X-Signature-Nonce": nonce, "X-Signature": signature}
+
+
+def test_api_key_signed_requests(primary_user):
+    """API keys authenticate with their secret or a signature, and a signed request cannot be replayed."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    response = requests.post(f"{BASE_URL}/api-keys", json={"name": "crm sync"}, headers=headers)
+    assert response.status_code == 201
+    key = response.json()
+
+    response = requests.get(f"{BASE_URL}/contacts", headers={"Authorization": f"ApiKey {key['key_id']}:{key['secret']}"})
+    assert response.status_code == 200
+    response = requests.get(f"{BASE_URL}/contacts", headers={"Authorization": f"ApiKey {key['key_id']}:wrong"})
+    assert response.status_code == 401
+
+    body = json.dumps({"first_name": "Signed", "last_name": random_string(), "phone_number": "0501234567",
+                       "address": "1 Signed St"}).encode()
+    signed_headers = {"Authorization": f"ApiKey {key['key_id']}", "Content-Type": "application/json",
+                      **sign_request(key["secret"], "POST", "/contacts", body)}
+    response = requests.post(f"{BASE_URL}/contacts", data=body, headers=signed_headers)
+    assert response.status_code == 201
+
+    # Replaying the exact same signed request is rejected
+    response = requests.post(f"{BASE_URL}/contacts", data=body, headers=signed_headers)
+    assert response.status_code == 401
+
+    response = requests.delete(f"{BASE_URL}/api-keys/{key['id']}", headers=headers)
+    assert response.status_code == 200
+    response = requests.get(f"{BASE_URL}/contacts", headers={"Authorization": f"ApiKey {key['key_id']}:{key['secret']}"})
+    assert response.status_code == 401
+
+
+# ---------------------------
+# JWT Validation Tests
+# ---------------------------
+def b64url(data):
+    return base64.urlsafe_b64encode(json.dumps(data).encode()).rstrip(b"=").decode()
+
+
+def test_jwt_alg_none_rejected(primary_user):
+    """A token with alg none is rejected even when its claims are valid."""
+    payload = json.loads(base64.urlsafe_b64decode(primary_user["token"].split(".")[1] + "=="))
+    token = b64url({"alg": "none", "typ": "JWT"}) + "." + b64url(payload) + "."
+    response = requests.get(f"{BASE_URL}/contacts", headers={"Authorization": f"Bearer {token}"})
+    assert response.status_code == 401
+
+
+def test_jwt_claims_issued(primary_user):
+    """Issued tokens carry the expiry, issue time, issuer and audience claims."""
+    payload = json.loads(base64.urlsafe_b64decode(primary_user["token"].split(".")[1] + "=="))
+    for claim in ("exp", "iat", "iss", "aud"):
+        assert claim in payload
+
+
+def test_metrics_requires_admin(primary_user):
+    """The metrics endpoint is only available to admins."""
+    response = requests.get(f"{BASE_URL}/admin/metrics", headers={"Authorization": f"Bearer {primary_user['token']}"})
+    assert response.status_code == 403
+
+
+# ---------------------------
+# Rate Limit Header Tests
+# ---------------------------
+def test_rate_limit_headers(primary_user):
+    """Every response reports the rate limit of the caller, the remaining count decreasing with each request."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    first = requests.get(f"{BASE_URL}/contacts", headers=headers)
+    second = requests.get(f"{BASE_URL}/contacts", headers=headers)
+    for response in (first, second):
+        assert response.status_code == 200
+        for header in ("X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset"):
+            assert header in response.headers
+    assert int(second.headers["X-RateLimit-Remaining"]) < int(first.headers["X-RateLimit-Remaining"])
+    assert int(first.headers["X-RateLimit-Reset"]) > time.time() - 1
+
+
+def test_login_route_rate_limit():
+    """Login has a stricter limit of its own, reported by its rate limit headers."""
+    response = requests.post(f"{BASE_URL}/login", json={"email": "nobody@example.com", "password": "wrong"})
+    assert response.status_code == 401
+    login_limit = int(response.headers["X-RateLimit-Limit"])
+    response = requests.post(f"{BASE_URL}/token/refresh", json={"refresh_token": "invalid"})
+    assert login_limit < int(response.headers["X-RateLimit-Limit"])
+
+
+# ---------------------------
+# Content Negotiation Tests
+# ---------------------------
+def test_get_contact(primary_user, secondary_user):
+    """A single contact can be retrieved by ID, contacts of other users are not found."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    contact_id = create_contact(primary_user["token"], "Single", random_string(), "0501112233", "1 Single St").json()["contact_id"]
+    response = requests.get(f"{BASE_URL}/contacts/{contact_id}", headers=headers)
+    assert response.status_code == 200
+    assert response.json()["id"] == contact_id
+
+    response = requests.get(f"{BASE_URL}/contacts/{contact_id}", headers={"Authorization": f"Bearer {secondary_user['token']}"})
+    assert response.status_code == 404
+
+
+
+def test_contacts_xml_and_csv(primary_user):
+    """GET /contacts and GET /contacts/:id answer in XML or CSV when asked through the Accept header."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    contact_id = create_contact(primary_user["token"], "Xml", random_string(), "0504445566", "1 Xml St").json()["contact_id"]
+
+    response = requests.get(f"{BASE_URL}/contacts", headers={**headers, "Accept": "application/xml"})
+    assert response.status_code == 200
+    assert response.headers["Content-Type"].startswith("application/xml")
+    assert "<contacts>" in response.text and "<total_count>" in response.text
+
+    response = requests.get(f"{BASE_URL}/contacts", headers={**headers, "Accept": "text/csv"})
+    assert response.status_code == 200
+    assert response.headers["Content-Type"].startswith("text/csv")
+    assert response.text.splitlines()[0].startswith("id,first_name,last_name,phone_number")
+    assert "X-Total-Count" in response.headers
+
+    response = requests.get(f"{BASE_URL}/contacts/{contact_id}", headers={**headers, "Accept": "application/xml"})
+    assert response.status_code == 200
+    assert f"<id>{contact_id}</id>" in response.text
+
+    response = requests.get(f"{BASE_URL}/contacts", headers={**headers, "Accept": "application/pdf"})
+    assert response.status_code == 406
+
+
+# ---------------------------
+# JSON:API Output Tests
+# ---------------------------
+def test_contacts_jsonapi(primary_user):
+    """format=jsonapi returns JSON:API documents with tag relationships and pagination links."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    contact_id = create_contact(primary_user["token"], "JsonApi", random_string(), "0507778899", "1 Spec St").json()["contact_id"]
+    tag = "spec" + random_string().lower()
+    response = requests.post(f"{BASE_URL}/tags/{tag}/contacts", json={"contact_ids": [contact_id]}, headers=headers)
+    assert response.status_code == 200
+
+    response = requests.get(f"{BASE_URL}/contacts/{contact_id}?format=jsonapi", headers=headers)
+    assert response.status_code == 200
+    assert response.headers["Content-Type"] == "application/vnd.api+json"
+    document = response.json()
+    assert document["data"]["type"] == "contacts" and document["data"]["id"] == str(contact_id)
+    assert "id" not in document["data"]["attributes"]
+    assert {"type": "tags", "id": tag} in document["data"]["relationships"]["tags"]["data"]
+    assert {"type": "tags", "id": tag, "attributes": {"name": tag}} in document["included"]
+
+    response = requests.get(f"{BASE_URL}/contacts", headers={**headers, "Accept": "application/vnd.api+json"})
+    assert response.status_code == 200
+    document = response.json()
+    assert document["meta"]["page"] == 1
+    assert "first" in document["links"] and "last" in document["links"]
+
+
+# ---------------------------
+# Contact Changes Long Polling Tests
+# ---------------------------
+def test_contact_changes_feed(primary_user):
+    """The changes feed returns changes after a cursor and times out with an empty list when nothing changed."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    response = requests.get(f"{BASE_URL}/contacts/changes", params={"since": 0, "wait": "0s"}, headers=headers)
+    assert response.status_code == 200
+    cursor = response.json()["cursor"]
+    while response.json()["has_more"]:
+        response = requests.get(f"{BASE_URL}/contacts/changes", params={"since": cursor, "wait": "0s"}, headers=headers)
+        cursor = response.json()["cursor"]
+
+    started = time.time()
+    response = requests.get(f"{BASE_URL}/contacts/changes", params={"since": cursor, "wait": "1s"}, headers=headers)
+    assert response.status_code == 200
+    assert response.json() == {"changes": [], "cursor": cursor, "has_more": False}
+    assert time.time() - started >= 0.9
+
+    contact_id = create_contact(primary_user["token"], "Changed", random_string(), "0501231231", "1 Feed St").json()["contact_id"]
+    response = requests.get(f"{BASE_URL}/contacts/changes", params={"since": cursor, "wait": "5s"}, headers=headers)
+    assert response.status_code == 200
+    changes = response.json()["changes"]
+    assert {"action": "contact.created", "entity_id": contact_id}.items() <= changes[-1].items()
+    assert response.json()["cursor"] == changes[-1]["id"]
+
+
+def test_contact_count_badge():
+    """The count badge follows the contacts created and deleted and counts the changes after a cursor."""
+    session = login_new_user()
+    headers = {"Authorization": f"Bearer {session['token']}"}
+    response = requests.get(f"{BASE_URL}/contacts/count", headers=headers)
+    assert response.status_code == 200
+    assert response.json()["total"] == 0
+    cursor = response.json()["cursor"]
+
+    first = create_contact(session["token"], "Badge", "one", "0501230001", "1 Badge St").json()["contact_id"]
+    create_contact(session["token"], "Badge", "two", "0501230002", "2 Badge St")
+    requests.patch(f"{BASE_URL}/contacts/{first}", json={"company": "Acme"}, headers=headers)
+    assert delete_contact(session["token"], first).status_code == 200
+
+    response = requests.get(f"{BASE_URL}/contacts/count", params={"since": cursor}, headers=headers)
+    assert response.json()["total"] == 1
+    assert response.json()["unread_changes"] == 4
+    response = requests.get(f"{BASE_URL}/contacts/count", params={"since": response.json()["cursor"]}, headers=headers)
+    assert response.json()["unread_changes"] == 0
+
+    assert requests.get(f"{BASE_URL}/contacts/count", params={"since": "-1"}, headers=headers).status_code == 400
+
+
+def test_contact_changes_invalid_wait(primary_user):
+    """A wait above the maximum is rejected."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    response = requests.get(f"{BASE_URL}/contacts/changes", params={"wait": "10m"}, headers=headers)
+    assert response.status_code == 400
+
+
+# ---------------------------
+# CSV and vCard Import and Export Tests
+# ---------------------------
+def test_contact_file_csv_import_reports_rows(primary_user):
+    """Valid CSV rows are imported, invalid and duplicate ones are reported with their line."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    last_name = random_string()
+    csv_file = (
+        "First Name,Last Name,Phone 1 - Value,E-mail 1 - Value\n"
+        f"Csv,{last_name},0501234567,csv@example.com\n"
+        f"Missing,{last_name},,\n"
+        f"Csv,{last_name},0501234567,\n"
+    )
+    files = {"file": ("google.csv", csv_file, "text/csv")}
+    response = requests.post(f"{BASE_URL}/contacts/import", files=files, headers=headers)
+    assert response.status_code == 200
+    result = response.json()
+    assert result["rows"] == 3 and result["imported"] == 1 and result["failed"] == 2
+    assert [error["line"] for error in result["errors"]] == [3, 4]
+    assert "phone_number" in result["errors"][0]["error"]
+    assert "already exists" in result["errors"][1]["error"]
+
+    response = requests.get(f"{BASE_URL}/contacts/export", params={"format": "csv"}, headers=headers)
+    assert response.status_code == 200
+    assert response.headers["Content-Type"].startswith("text/csv")
+    assert f"Csv,{last_name},0501234567,csv@example.com" in response.text
+
+
+def test_contact_file_vcard_round_trip(primary_user, secondary_user):
+    """Contacts exported as vCard import into another account."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    last_name = random_string()
+    create_contact(primary_user["token"], "Vcard", last_name, "0507654321", "1 Card St")
+
+    response = requests.get(f"{BASE_URL}/contacts/export", params={"format": "vcf"}, headers=headers)
+    assert response.status_code == 200
+    assert response.headers["Content-Type"].startswith("text/vcard")
+    assert f"N:{last_name};Vcard;;;" in response.text
+
+    other = {"Authorization": f"Bearer {secondary_user['token']}"}
+    files = {"file": ("contacts.vcf", response.content, "text/vcard")}
+    response = requests.post(f"{BASE_URL}/contacts/import", files=files, headers=other)
+    assert response.status_code == 200
+    assert response.json()["imported"] >= 1
+
+
+def test_contact_file_unsupported_format(primary_user):
+    """Only CSV and vCard files are accepted."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    files = {"file": ("contacts.xlsx", b"binary", "application/octet-stream")}
+    response = requests.post(f"{BASE_URL}/contacts/import", files=files, headers=headers)
+    assert response.status_code == 400
+
+    response = requests.get(f"{BASE_URL}/contacts/export", params={"format": "pdf"}, headers=headers)
+    assert response.status_code == 400
+
+
+# ---------------------------
+# Account Export and Import Tests
+# ---------------------------
+def test_account_export_import_round_trip(primary_user, secondary_user):
+    """An exported account imports into another user with its groups, tags and preferences, twice imports nothing new."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    last_name = random_string()
+    contact_id = create_contact(primary_user["token"], "Archived", last_name, "0506665544", "1 Archive St").json()["contact_id"]
+    tag = "takeout" + random_string().lower()
+    response = requests.post(f"{BASE_URL}/tags/{tag}/contacts", json={"contact_ids": [contact_id]}, headers=headers)
+    assert response.status_code == 200
+
+    response = requests.get(f"{BASE_URL}/users/me/export", headers=headers)
+    assert response.status_code == 200
+    assert response.headers["Content-Disposition"].startswith("attachment")
+    archive = response.json()
+    assert archive["format"] == "contact-app-account" and archive["version"] == 1
+    exported = next(c for c in archive["contacts"] if c["last_name"] == last_name)
+    assert exported["tags"] == [tag]
+    assert tag in archive["tags"]
+
+    other = {"Authorization": f"Bearer {secondary_user['token']}"}
+    response = requests.post(f"{BASE_URL}/users/me/import", json=archive, headers=other)
+    assert response.status_code == 200
+    result = response.json()
+    assert result["contacts_imported"] >= 1 and result["preferences_imported"]
+
+    response = requests.get(f"{BASE_URL}/users/me/export", headers=other)
+    imported = next(c for c in response.json()["contacts"] if c["last_name"] == last_name)
+    assert imported["tags"] == [tag]
+
+    response = requests.post(f"{BASE_URL}/users/me/import", json=archive, headers=other)
+    assert response.status_code == 200
+    assert response.json()["contacts_imported"] == 0
+    assert response.json()["contacts_skipped"] == len(archive["contacts"])
+
+
+def test_encrypted_account_export_round_trip():
+    """An export encrypted with a password imports back with the same password only."""
+    source = login_new_user()
+    headers = {"Authorization": f"Bearer {source['token']}"}
+    last_name = random_string()
+    create_contact(source["token"], "Sealed", last_name, "0506665544", "1 Archive St")
+
+    response = requests.get(f"{BASE_URL}/users/me/export", headers={**headers, "X-Export-Password": "short"})
+    assert response.status_code == 400
+    response = requests.get(f"{BASE_URL}/users/me/export", headers={**headers, "X-Export-Password": "correct horse"})
+    assert response.status_code == 200
+    assert response.headers["Content-Disposition"].endswith('.json.gpg"')
+    assert last_name.encode() not in response.content
+    encrypted = response.content
+
+    target = {"Authorization": f"Bearer {login_new_user()['token']}"}
+    response = requests.post(f"{BASE_URL}/users/me/import", data=encrypted, headers={**target, "X-Export-Password": "wrong horse"})
+    assert response.status_code == 400
+    assert response.json()["error"] == "wrong export password"
+    response = requests.post(f"{BASE_URL}/users/me/import", data=encrypted, headers={**target, "X-Export-Password": "correct horse"})
+    assert response.status_code == 200
+    assert response.json()["contacts_imported"] == 1
+
+    response = requests.get(f"{BASE_URL}/contacts/export", headers={**headers, "X-Export-Password": "correct horse"})
+    assert response.status_code == 200
+    assert response.headers["Content-Disposition"].endswith('contacts.csv.gpg"')
+    assert last_name.encode() not in response.content
+
+
+def test_account_import_forward_compatible(primary_user):
+    """Unknown sections of a newer archive are skipped with a warning, invalid values reject the whole archive."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    archive = {"format": "contact-app-account", "version": 2, "contacts": [], "reminders": [{"at": "2030-01-01"}]}
+    response = requests.post(f"{BASE_URL}/users/me/import", json=archive, headers=headers)
+    assert response.status_code == 200
+    assert any("reminders" in warning for warning in response.json()["warnings"])
+
+    archive = {"format": "contact-app-account", "version": 1,
+               "contacts": [{"first_name": "Valid", "last_name": random_string(), "phone_number": "0501112233"},
+                            {"first_name": "", "last_name": "Missing", "phone_number": "0501112233"}]}
+    response = requests.post(f"{BASE_URL}/users/me/import", json=archive, headers=headers)
+    assert response.status_code == 400
+    assert response.json()["error"] == "invalid account archive"
+    assert "contacts[1].first_name failed the required rule" in response.json()["problems"]
+
+
+# ---------------------------
+# Analytics Settings Tests
+# ---------------------------
+def test_analytics_settings_requires_admin(primary_user):
+    """Only admins can read or change the instance wide analytics opt-out."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    response = requests.get(f"{BASE_URL}/admin/analytics", headers=headers)
+    assert response.status_code == 403
+    response = requests.put(f"{BASE_URL}/admin/analytics", json={"enabled": False}, headers=headers)
+    assert response.status_code == 403
+
+
+# ---------------------------
+# Admin Stats Tests
+# ---------------------------
+def test_admin_stats_requires_admin(primary_user):
+    """Instance wide stats are reserved to admins."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    response = requests.get(f"{BASE_URL}/admin/stats", headers=headers)
+    assert response.status_code == 403
+
+
+def test_api_usage_counts_requests_by_endpoint():
+    """The usage of a user counts its requests by endpoint, today included."""
+    session = login_new_user()
+    headers = {"Authorization": f"Bearer {session['token']}"}
+    for _ in range(3):
+        assert requests.get(f"{BASE_URL}/contacts", headers=headers).status_code == 200
+
+    response = requests.get(f"{BASE_URL}/users/me/usage", params={"days": 7}, headers=headers)
+    assert response.status_code == 200
+    usage = response.json()
+    assert len(usage["days"]) == 7
+    assert usage["days"][-1]["date"] == usage["to"]
+    endpoints = {endpoint["endpoint"]: endpoint for endpoint in usage["endpoints"]}
+    assert endpoints["GetContacts"]["requests"] == 3
+    assert endpoints["GetContacts"]["path"] == "/contacts"
+    assert endpoints["GetContacts"]["bytes_out"] > 0
+    assert usage["total"]["requests"] >= 3
+
+    assert requests.get(f"{BASE_URL}/users/me/usage", params={"days": 91}, headers=headers).status_code == 400
+    assert requests.get(f"{BASE_URL}/admin/usage", headers=headers).status_code == 403
+
+
+def test_interactions_find_cold_contacts():
+    """Logged interactions set last_interacted_at, which sorts and filters the cold contacts."""
+    session = login_new_user()
+    headers = {"Authorization": f"Bearer {session['token']}"}
+    cold_id = create_contact(session["token"], "cold", "contact", "1234567890", "tel aviv").json()["contact_id"]
+    warm_id = create_contact(session["token"], "warm", "contact", "1234567890", "tel aviv").json()["contact_id"]
+
+    response = requests.post(f"{BASE_URL}/contacts/{cold_id}/interactions", headers=headers,
+                             json={"kind": "call", "note": "catch up", "occurred_at": "2020-01-01T10:00:00Z"})
+    assert response.status_code == 201
+    assert response.json()["kind"] == "call"
+    response = requests.post(f"{BASE_URL}/contacts/{cold_id}/interactions", headers=headers, json={"kind": "fax"})
+    assert response.status_code == 400
+
+    response = requests.get(f"{BASE_URL}/contacts/{cold_id}/interactions", headers=headers)
+    assert response.status_code == 200
+    assert [item["note"] for item in response.json()["items"]] == ["catch up"]
+
+    response = requests.get(f"{BASE_URL}/contacts", params={"inactive_for": "90d"}, headers=headers)
+    assert response.status_code == 200
+    assert [item["contact_id"] for item in response.json()["items"]] == [cold_id]
+    assert response.json()["items"][0]["last_interacted_at"].startswith("2020-01-01")
+
+    response = requests.get(f"{BASE_URL}/contacts", params={"sort_by": "last_interacted_at"}, headers=headers)
+    assert response.status_code == 200
+    assert [item["contact_id"] for item in response.json()["items"]] == [cold_id, warm_id]
+
+    assert requests.get(f"{BASE_URL}/contacts", params={"inactive_for": "soon"}, headers=headers).status_code == 400
+
+
+def test_account_merge_requires_admin(primary_user, secondary_user):
+    """Listing duplicate accounts and merging them are reserved to admins."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    response = requests.get(f"{BASE_URL}/admin/users/duplicates", headers=headers)
+    assert response.status_code == 403
+    response = requests.post(f"{BASE_URL}/admin/users/merge", headers=headers, json={"source_user_id": 1, "target_user_id": 2})
+    assert response.status_code == 403
+
+
+def test_announcements_require_admin_to_post(primary_user):
+    """Only admins post announcements, users list theirs and cannot dismiss unknown ones."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    response = requests.post(f"{BASE_URL}/admin/announcements", json={"message": "Maintenance tonight"}, headers=headers)
+    assert response.status_code == 403
+    assert requests.get(f"{BASE_URL}/admin/announcements", headers=headers).status_code == 403
+
+    response = requests.get(f"{BASE_URL}/announcements", headers=headers)
+    assert response.status_code == 200
+    assert isinstance(response.json()["items"], list)
+
+    response = requests.post(f"{BASE_URL}/announcements/999999999/dismiss", headers=headers)
+    assert response.status_code == 404
+
+
+# ---------------------------
+# Alerting Tests
+# ---------------------------
+def test_alert_settings_require_admin(primary_user):
+    """Error budget alerting settings are reserved to admins."""
+    headers = {"Authorization": f"Bearer {primary_user['token']}"}
+    response = requests.get(f"{BASE_URL}/admin/alerts", headers=headers)
+    assert response.status_code == 403
+
+    response = requests.put(
+        f"{BASE_URL}/admin/alerts/suppressions",
+        json={"route": "GET /contacts", "suppression": "1h"},
+        headers=headers,
+    )
+    assert response.status_code == 403
@@ -0,0 +1,5 @@
+// Package clients holds the API clients generated from api.Routes and the DTOs,
+// run go generate ./clients after changing either and commit the result
+package clients
+
+//go:generate go run ../cmd/clientgen -out .
@@ -0,0 +1,2449 @@
+// Code generated by cmd/clientgen. DO NOT EDIT.
+
+// Package contactclient is the Go client of the contact API
+package contactclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// APIError is returned for every response outside the 2xx range, Message is the error reported by the API.
+// RetryAfter is set when the API asks to wait before retrying (rate limited requests)
+type APIError struct {
+	StatusCode int
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("contact api: %d %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound reports whether err is an APIError with status 404
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsUnauthorized reports whether err is an APIError with status 401, the token is missing or expired
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+// IsForbidden reports whether err is an APIError with status 403
+func IsForbidden(err error) bool {
+	return hasStatus(err, http.StatusForbidden)
+}
+
+// IsConflict reports whether err is an APIError with status 409
+func IsConflict(err error) bool {
+	return hasStatus(err, http.StatusConflict)
+}
+
+// IsRateLimited reports whether err is an APIError with status 429, wait for its RetryAfter before retrying
+func IsRateLimited(err error) bool {
+	return hasStatus(err, http.StatusTooManyRequests)
+}
+
+func hasStatus(err error, status int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status
+}
+
+// Client calls the contact API, set Token (from Login) or APIKey ("<key_id>:<secret>") before calling
+// authenticated endpoints
+type Client struct {
+	BaseURL    string
+	Token      string
+	APIKey     string
+	HTTPClient *http.Client
+}
+
+// New creates a client for the API served at baseURL
+func New(baseURL string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, body io.Reader, contentType string) (*http.Response, error) {
+	target := c.BaseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	switch {
+	case c.Token != "":
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	case c.APIKey != "":
+		req.Header.Set("Authorization", "ApiKey "+c.APIKey)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		apiErr := &APIError{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			apiErr.RetryAfter = time.Duration(seconds) * time.Second
+		}
+		var errBody ErrorResponse
+		if json.NewDecoder(resp.Body).Decode(&errBody) == nil && errBody.Error != "" {
+			apiErr.Message = errBody.Error
+		}
+		return nil, apiErr
+	}
+	return resp, nil
+}
+
+func (c *Client) doJSON(ctx context.Context, method, path string, query url.Values, in, out interface{}) error {
+	var body io.Reader
+	contentType := ""
+	if in != nil {
+		encoded, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(encoded)
+		contentType = "application/json"
+	}
+
+	resp, err := c.send(ctx, method, path, query, body, contentType)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (c *Client) doMultipart(ctx context.Context, method, path, field, fileName string, file io.Reader, out interface{}) error {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile(field, fileName)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	resp, err := c.send(ctx, method, path, nil, &body, writer.FormDataContentType())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+type RateLimitErrorResponse struct {
+	Error      string `json:"error"`
+	Limit      int    `json:"limit"`
+	Remaining  int    `json:"remaining"`
+	Reset      int64  `json:"reset"`
+	RetryAfter int    `json:"retry_after"`
+}
+
+type CreateUserRequest struct {
+	Username string `json:"user_name"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type CreateUserResponse struct {
+	Message string `json:"message"`
+	UserID  int    `json:"userID"`
+}
+
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type LoginResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+	UserID       int    `json:"user_id"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
+type ProfileResponse struct {
+	ID                        int              `json:"id"`
+	UserName                  string           `json:"user_name"`
+	Email                     string           `json:"email"`
+	IsAdmin                   bool             `json:"is_admin"`
+	CreatedAt                 time.Time        `json:"created_at"`
+	PendingEmailChange        *EmailChange     `json:"pending_email_change,omitempty"`
+	UsernameChangeAvailableAt *time.Time       `json:"username_change_available_at,omitempty"`
+	UsernameHistory           []UsernameChange `json:"username_history,omitempty"`
+}
+
+type EmailChange struct {
+	NewEmail         string    `json:"new_email"`
+	CurrentConfirmed bool      `json:"current_confirmed"`
+	NewConfirmed     bool      `json:"new_confirmed"`
+	RequestedAt      time.Time `json:"requested_at"`
+	ExpiresAt        time.Time `json:"expires_at"`
+}
+
+type UsernameChange struct {
+	OldUserName string    `json:"old_user_name"`
+	NewUserName string    `json:"new_user_name"`
+	ChangedAt   time.Time `json:"changed_at"`
+}
+
+type UpdateProfileRequest struct {
+	UserName string `json:"user_name,omitempty"`
+	Email    string `json:"email,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+type DeactivateAccountRequest struct {
+	Password string `json:"password"`
+}
+
+type RequestReactivation struct {
+	Email string `json:"email"`
+}
+
+type RequestEmailChange struct {
+	NewEmail string `json:"new_email"`
+	Password string `json:"password"`
+}
+
+type ConfirmEmailChangeResponse struct {
+	Completed bool         `json:"completed"`
+	Email     string       `json:"email,omitempty"`
+	Pending   *EmailChange `json:"pending,omitempty"`
+}
+
+type ChangeUsernameRequest struct {
+	UserName string `json:"user_name"`
+}
+
+type APIUsageResponse struct {
+	From      string             `json:"from"`
+	To        string             `json:"to"`
+	Total     APIUsageCounts     `json:"total"`
+	Days      []APIUsageDay      `json:"days"`
+	Endpoints []APIUsageEndpoint `json:"endpoints"`
+}
+
+type APIUsageCounts struct {
+	Requests    int64 `json:"requests"`
+	RateLimited int64 `json:"rate_limited"`
+	BytesIn     int64 `json:"bytes_in"`
+	BytesOut    int64 `json:"bytes_out"`
+}
+
+type APIUsageDay struct {
+	Date        string `json:"date"`
+	Requests    int64  `json:"requests"`
+	RateLimited int64  `json:"rate_limited"`
+	BytesIn     int64  `json:"bytes_in"`
+	BytesOut    int64  `json:"bytes_out"`
+}
+
+type APIUsageEndpoint struct {
+	Endpoint           string `json:"endpoint"`
+	Method             string `json:"method"`
+	Path               string `json:"path"`
+	RateLimitPerMinute int    `json:"rate_limit_per_minute,omitempty"`
+	Requests           int64  `json:"requests"`
+	RateLimited        int64  `json:"rate_limited"`
+	BytesIn            int64  `json:"bytes_in"`
+	BytesOut           int64  `json:"bytes_out"`
+}
+
+type UserLookupResponse struct {
+	ID             int    `json:"id"`
+	UserName       string `json:"user_name"`
+	RedirectedFrom string `json:"redirected_from,omitempty"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+type AnnouncementListResponse struct {
+	Items []Announcement `json:"items"`
+}
+
+type Announcement struct {
+	ID        int        `json:"id"`
+	Message   string     `json:"message"`
+	Level     string     `json:"level"`
+	StartsAt  time.Time  `json:"starts_at"`
+	EndsAt    *time.Time `json:"ends_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type PreferencesResponse struct {
+	WeeklyDigest         bool   `json:"weekly_digest"`
+	Region               string `json:"region"`
+	SearchLanguage       string `json:"search_language"`
+	DuplicateSensitivity string `json:"duplicate_sensitivity"`
+}
+
+type UpdatePreferencesRequest struct {
+	WeeklyDigest         *bool   `json:"weekly_digest,omitempty"`
+	Region               *string `json:"region,omitempty"`
+	SearchLanguage       *string `json:"search_language,omitempty"`
+	DuplicateSensitivity *string `json:"duplicate_sensitivity,omitempty"`
+}
+
+type NotificationPreferences struct {
+	Events map[string]map[string]bool `json:"events"`
+}
+
+type UpdateNotificationPreferencesRequest struct {
+	Events map[string]map[string]bool `json:"events"`
+}
+
+type AccountArchive struct {
+	Format      string               `json:"format"`
+	Version     int                  `json:"version"`
+	ExportedAt  time.Time            `json:"exported_at"`
+	Contacts    []ArchiveContact     `json:"contacts"`
+	Groups      []string             `json:"groups"`
+	Tags        []string             `json:"tags"`
+	Preferences *PreferencesResponse `json:"preferences,omitempty"`
+}
+
+type ArchiveContact struct {
+	FirstName      string          `json:"first_name"`
+	LastName       string          `json:"last_name"`
+	PhoneNumber    string          `json:"phone_number"`
+	Address        string          `json:"address,omitempty"`
+	Email          string          `json:"email,omitempty"`
+	Company        string          `json:"company,omitempty"`
+	JobTitle       string          `json:"job_title,omitempty"`
+	Timezone       string          `json:"timezone,omitempty"`
+	Street         string          `json:"street,omitempty"`
+	City           string          `json:"city,omitempty"`
+	Region         string          `json:"region,omitempty"`
+	PostalCode     string          `json:"postal_code,omitempty"`
+	CountryCode    string          `json:"country_code,omitempty"`
+	Latitude       *float64        `json:"latitude,omitempty"`
+	Longitude      *float64        `json:"longitude,omitempty"`
+	Source         string          `json:"source,omitempty"`
+	Stage          string          `json:"stage,omitempty"`
+	SocialProfiles []SocialProfile `json:"social_profiles,omitempty"`
+	Groups         []string        `json:"groups,omitempty"`
+	Tags           []string        `json:"tags,omitempty"`
+}
+
+type SocialProfile struct {
+	Network string `json:"network"`
+	Handle  string `json:"handle"`
+	URL     string `json:"url"`
+}
+
+type ImportAccountResponse struct {
+	ContactsImported    int      `json:"contacts_imported"`
+	ContactsSkipped     int      `json:"contacts_skipped"`
+	GroupsCreated       int      `json:"groups_created"`
+	TagsCreated         int      `json:"tags_created"`
+	PreferencesImported bool     `json:"preferences_imported"`
+	Warnings            []string `json:"warnings,omitempty"`
+}
+
+type APIKeyListResponse struct {
+	Items []APIKeyResponse `json:"items"`
+}
+
+type APIKeyResponse struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	KeyID     string    `json:"key_id"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name string `json:"name"`
+}
+
+type EmbedTokenListResponse struct {
+	Items []EmbedToken `json:"items"`
+}
+
+type EmbedToken struct {
+	ID          int        `json:"id"`
+	Name        string     `json:"name"`
+	Token       string     `json:"token,omitempty"`
+	URL         string     `json:"url,omitempty"`
+	TokenPrefix string     `json:"token_prefix"`
+	GroupID     *int       `json:"group_id,omitempty"`
+	Query       string     `json:"q,omitempty"`
+	Tag         string     `json:"tag,omitempty"`
+	Active      bool       `json:"active"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type CreateEmbedTokenRequest struct {
+	Name      string     `json:"name"`
+	GroupID   *int       `json:"group_id,omitempty"`
+	Query     string     `json:"q,omitempty"`
+	Tag       string     `json:"tag,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type EmbedContactListResponse struct {
+	Name       string         `json:"name"`
+	Branding   Branding       `json:"branding"`
+	Items      []EmbedContact `json:"items"`
+	TotalCount int            `json:"total_count"`
+	Page       int            `json:"page"`
+	PageSize   int            `json:"page_size"`
+	TotalPages int            `json:"total_pages"`
+}
+
+type Branding struct {
+	InstanceName string `json:"instance_name"`
+	SupportEmail string `json:"support_email,omitempty"`
+	LogoURL      string `json:"logo_url,omitempty"`
+}
+
+type EmbedContact struct {
+	FirstName      string          `json:"first_name"`
+	LastName       string          `json:"last_name"`
+	Email          string          `json:"email,omitempty"`
+	PhoneNumber    string          `json:"phone_number,omitempty"`
+	Company        string          `json:"company,omitempty"`
+	JobTitle       string          `json:"job_title,omitempty"`
+	SocialProfiles []SocialProfile `json:"social_profiles,omitempty"`
+}
+
+type PaginationResult struct {
+	Items      []GetContactsResponse `json:"items"`
+	TotalCount int                   `json:"total_count"`
+	Page       int                   `json:"page"`
+	PageSize   int                   `json:"page_size"`
+	TotalPages int                   `json:"total_pages"`
+	NextCursor string                `json:"next_cursor,omitempty"`
+}
+
+type GetContactsResponse struct {
+	ID                   int               `json:"id"`
+	UserID               int               `json:"user_id"`
+	FirstName            string            `json:"first_name"`
+	LastName             string            `json:"last_name"`
+	PhoneNumber          string            `json:"phone_number"`
+	Address              string            `json:"address,omitempty"`
+	Email                string            `json:"email,omitempty"`
+	Company              string            `json:"company,omitempty"`
+	JobTitle             string            `json:"job_title,omitempty"`
+	Source               string            `json:"source,omitempty"`
+	Stage                string            `json:"stage,omitempty"`
+	SocialProfiles       []SocialProfile   `json:"social_profiles,omitempty"`
+	Timezone             string            `json:"timezone,omitempty"`
+	Street               string            `json:"street,omitempty"`
+	City                 string            `json:"city,omitempty"`
+	Region               string            `json:"region,omitempty"`
+	PostalCode           string            `json:"postal_code,omitempty"`
+	CountryCode          string            `json:"country_code,omitempty"`
+	FormattedAddress     []string          `json:"formatted_address,omitempty"`
+	Latitude             *float64          `json:"latitude,omitempty"`
+	Longitude            *float64          `json:"longitude,omitempty"`
+	LocalTime            string            `json:"local_time,omitempty"`
+	WithinWorkingHours   *bool             `json:"within_working_hours,omitempty"`
+	LocalTimeEstimated   bool              `json:"local_time_estimated,omitempty"`
+	DeletedAt            *time.Time        `json:"deleted_at,omitempty"`
+	PhoneNumberE164      string            `json:"phone_number_e164,omitempty"`
+	PhoneNumberFormatted string            `json:"phone_number_formatted,omitempty"`
+	LastInteractedAt     *time.Time        `json:"last_interacted_at,omitempty"`
+	CustomFields         map[string]string `json:"custom_fields,omitempty"`
+	ClientID             string            `json:"client_id,omitempty"`
+}
+
+type CreateContactRequest struct {
+	FirstName    string            `json:"first_name"`
+	LastName     string            `json:"last_name"`
+	PhoneNumber  string            `json:"phone_number"`
+	Address      string            `json:"address"`
+	Email        string            `json:"email,omitempty"`
+	Company      string            `json:"company,omitempty"`
+	JobTitle     string            `json:"job_title,omitempty"`
+	Timezone     string            `json:"timezone,omitempty"`
+	Street       string            `json:"street,omitempty"`
+	City         string            `json:"city,omitempty"`
+	Region       string            `json:"region,omitempty"`
+	PostalCode   string            `json:"postal_code,omitempty"`
+	CountryCode  string            `json:"country_code,omitempty"`
+	Latitude     *float64          `json:"latitude,omitempty"`
+	Longitude    *float64          `json:"longitude,omitempty"`
+	Source       string            `json:"source,omitempty"`
+	Stage        string            `json:"stage,omitempty"`
+	CustomFields map[string]string `json:"custom_fields,omitempty"`
+	ClientID     string            `json:"client_id,omitempty"`
+}
+
+type CreateContactResponse struct {
+	Message   string `json:"message"`
+	ContactID int    `json:"contact_id"`
+}
+
+type UpdateContactRequest struct {
+	FirstName    string            `json:"first_name,omitempty"`
+	LastName     string            `json:"last_name,omitempty"`
+	PhoneNumber  string            `json:"phone_number,omitempty"`
+	Address      string            `json:"address,omitempty"`
+	Email        string            `json:"email,omitempty"`
+	Company      string            `json:"company,omitempty"`
+	JobTitle     string            `json:"job_title,omitempty"`
+	Timezone     string            `json:"timezone,omitempty"`
+	Street       string            `json:"street,omitempty"`
+	City         string            `json:"city,omitempty"`
+	Region       string            `json:"region,omitempty"`
+	PostalCode   string            `json:"postal_code,omitempty"`
+	CountryCode  string            `json:"country_code,omitempty"`
+	Latitude     *float64          `json:"latitude,omitempty"`
+	Longitude    *float64          `json:"longitude,omitempty"`
+	Source       string            `json:"source,omitempty"`
+	Stage        string            `json:"stage,omitempty"`
+	CustomFields map[string]string `json:"custom_fields,omitempty"`
+}
+
+type IncompleteContactsResponse struct {
+	Items      []IncompleteContact `json:"items"`
+	TotalCount int                 `json:"total_count"`
+	Page       int                 `json:"page"`
+	PageSize   int                 `json:"page_size"`
+	TotalPages int                 `json:"total_pages"`
+}
+
+type IncompleteContact struct {
+	ID                   int               `json:"id"`
+	UserID               int               `json:"user_id"`
+	FirstName            string            `json:"first_name"`
+	LastName             string            `json:"last_name"`
+	PhoneNumber          string            `json:"phone_number"`
+	Address              string            `json:"address,omitempty"`
+	Email                string            `json:"email,omitempty"`
+	Company              string            `json:"company,omitempty"`
+	JobTitle             string            `json:"job_title,omitempty"`
+	Source               string            `json:"source,omitempty"`
+	Stage                string            `json:"stage,omitempty"`
+	SocialProfiles       []SocialProfile   `json:"social_profiles,omitempty"`
+	Timezone             string            `json:"timezone,omitempty"`
+	Street               string            `json:"street,omitempty"`
+	City                 string            `json:"city,omitempty"`
+	Region               string            `json:"region,omitempty"`
+	PostalCode           string            `json:"postal_code,omitempty"`
+	CountryCode          string            `json:"country_code,omitempty"`
+	FormattedAddress     []string          `json:"formatted_address,omitempty"`
+	Latitude             *float64          `json:"latitude,omitempty"`
+	Longitude            *float64          `json:"longitude,omitempty"`
+	LocalTime            string            `json:"local_time,omitempty"`
+	WithinWorkingHours   *bool             `json:"within_working_hours,omitempty"`
+	LocalTimeEstimated   bool              `json:"local_time_estimated,omitempty"`
+	DeletedAt            *time.Time        `json:"deleted_at,omitempty"`
+	PhoneNumberE164      string            `json:"phone_number_e164,omitempty"`
+	PhoneNumberFormatted string            `json:"phone_number_formatted,omitempty"`
+	LastInteractedAt     *time.Time        `json:"last_interacted_at,omitempty"`
+	CustomFields         map[string]string `json:"custom_fields,omitempty"`
+	ClientID             string            `json:"client_id,omitempty"`
+	Completeness         int               `json:"completeness"`
+	Missing              []string          `json:"missing"`
+}
+
+type DuplicateContactsResponse struct {
+	Sensitivity string              `json:"sensitivity"`
+	Thresholds  DuplicateThresholds `json:"thresholds"`
+	Pairs       []DuplicatePair     `json:"pairs"`
+	Truncated   bool                `json:"truncated"`
+}
+
+type DuplicateThresholds struct {
+	Score          float64 `json:"score"`
+	NameSimilarity float64 `json:"name_similarity"`
+}
+
+type DuplicatePair struct {
+	Contacts []GetContactsResponse `json:"contacts"`
+	Score    float64               `json:"score"`
+	Signals  []DuplicateSignal     `json:"signals"`
+}
+
+type DuplicateSignal struct {
+	Signal       string  `json:"signal"`
+	Score        float64 `json:"score"`
+	Weight       float64 `json:"weight"`
+	Contribution float64 `json:"contribution"`
+	Threshold    float64 `json:"threshold"`
+	Matched      bool    `json:"matched"`
+}
+
+type ContactCompleteness struct {
+	ContactID int      `json:"contact_id"`
+	Score     int      `json:"score"`
+	Filled    []string `json:"filled"`
+	Missing   []string `json:"missing"`
+}
+
+type TrashListResponse struct {
+	Items []GetContactsResponse `json:"items"`
+}
+
+type TrashedContact struct {
+	ID                   int               `json:"id"`
+	UserID               int               `json:"user_id"`
+	FirstName            string            `json:"first_name"`
+	LastName             string            `json:"last_name"`
+	PhoneNumber          string            `json:"phone_number"`
+	Address              string            `json:"address,omitempty"`
+	Email                string            `json:"email,omitempty"`
+	Company              string            `json:"company,omitempty"`
+	JobTitle             string            `json:"job_title,omitempty"`
+	Source               string            `json:"source,omitempty"`
+	Stage                string            `json:"stage,omitempty"`
+	SocialProfiles       []SocialProfile   `json:"social_profiles,omitempty"`
+	Timezone             string            `json:"timezone,omitempty"`
+	Street               string            `json:"street,omitempty"`
+	City                 string            `json:"city,omitempty"`
+	Region               string            `json:"region,omitempty"`
+	PostalCode           string            `json:"postal_code,omitempty"`
+	CountryCode          string            `json:"country_code,omitempty"`
+	FormattedAddress     []string          `json:"formatted_address,omitempty"`
+	Latitude             *float64          `json:"latitude,omitempty"`
+	Longitude            *float64          `json:"longitude,omitempty"`
+	LocalTime            string            `json:"local_time,omitempty"`
+	WithinWorkingHours   *bool             `json:"within_working_hours,omitempty"`
+	LocalTimeEstimated   bool              `json:"local_time_estimated,omitempty"`
+	DeletedAt            *time.Time        `json:"deleted_at,omitempty"`
+	PhoneNumberE164      string            `json:"phone_number_e164,omitempty"`
+	PhoneNumberFormatted string            `json:"phone_number_formatted,omitempty"`
+	LastInteractedAt     *time.Time        `json:"last_interacted_at,omitempty"`
+	CustomFields         map[string]string `json:"custom_fields,omitempty"`
+	ClientID             string            `json:"client_id,omitempty"`
+	Groups               []Membership      `json:"groups"`
+	Tags                 []Membership      `json:"tags"`
+	DeletedBy            *int              `json:"deleted_by,omitempty"`
+	PurgeAt              time.Time         `json:"purge_at"`
+}
+
+type Membership struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type ContactHistoryResponse struct {
+	ContactID int                   `json:"contact_id"`
+	Items     []ContactHistoryEntry `json:"items"`
+}
+
+type ContactHistoryEntry struct {
+	ID        int64                         `json:"id"`
+	Action    string                        `json:"action"`
+	Changes   map[string]ContactFieldChange `json:"changes"`
+	CreatedAt time.Time                     `json:"created_at"`
+}
+
+type ContactFieldChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+type ContactChangesResponse struct {
+	Changes []ContactChange `json:"changes"`
+	Cursor  int64           `json:"cursor"`
+	HasMore bool            `json:"has_more"`
+}
+
+type ContactChange struct {
+	ID         int64     `json:"id"`
+	Action     string    `json:"action"`
+	EntityType string    `json:"entity_type"`
+	EntityID   int       `json:"entity_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type SyncContact struct {
+	Contact GetContactsResponse `json:"contact"`
+	Version int64               `json:"version"`
+}
+
+type SyncUpdateContactRequest struct {
+	BaseVersion int64                `json:"base_version"`
+	Changes     UpdateContactRequest `json:"changes"`
+}
+
+type SyncUpdateContactResponse struct {
+	Contact   GetContactsResponse `json:"contact"`
+	Version   int64               `json:"version"`
+	Applied   []string            `json:"applied"`
+	Conflicts []SyncFieldConflict `json:"conflicts"`
+}
+
+type SyncFieldConflict struct {
+	Field       string      `json:"field"`
+	ServerValue interface{} `json:"server_value"`
+	ClientValue interface{} `json:"client_value"`
+}
+
+type SyncBatchRequest struct {
+	BatchID   string         `json:"batch_id,omitempty"`
+	Mutations []SyncMutation `json:"mutations"`
+}
+
+type SyncMutation struct {
+	Op          string                `json:"op"`
+	TempID      string                `json:"temp_id,omitempty"`
+	ContactID   int                   `json:"contact_id,omitempty"`
+	BaseVersion int64                 `json:"base_version,omitempty"`
+	Create      *CreateContactRequest `json:"create,omitempty"`
+	Update      *UpdateContactRequest `json:"update,omitempty"`
+}
+
+type SyncBatchResponse struct {
+	Committed bool                 `json:"committed"`
+	Replayed  bool                 `json:"replayed,omitempty"`
+	IDMap     map[string]int       `json:"id_map"`
+	Results   []SyncMutationResult `json:"results"`
+}
+
+type SyncMutationResult struct {
+	Index     int                 `json:"index"`
+	Op        string              `json:"op"`
+	Status    string              `json:"status"`
+	TempID    string              `json:"temp_id,omitempty"`
+	ContactID int                 `json:"contact_id,omitempty"`
+	Version   int64               `json:"version,omitempty"`
+	Applied   []string            `json:"applied,omitempty"`
+	Conflicts []SyncFieldConflict `json:"conflicts,omitempty"`
+	Error     string              `json:"error,omitempty"`
+}
+
+type ContactCountResponse struct {
+	Total         int64 `json:"total"`
+	UnreadChanges int64 `json:"unread_changes"`
+	Cursor        int64 `json:"cursor"`
+}
+
+type ContactStatsResponse struct {
+	TotalCount int            `json:"total_count"`
+	ByStage    map[string]int `json:"by_stage"`
+	BySource   map[string]int `json:"by_source"`
+}
+
+type GeoJSONFeatureCollection struct {
+	Type      string           `json:"type"`
+	Features  []GeoJSONFeature `json:"features"`
+	Clustered bool             `json:"clustered"`
+}
+
+type GeoJSONFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   GeoJSONPoint           `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type GeoJSONPoint struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+type CardImportResponse struct {
+	Draft   CreateContactRequest `json:"draft"`
+	RawText string               `json:"raw_text"`
+}
+
+type ContactFileImportResponse struct {
+	Format   string                `json:"format"`
+	Rows     int                   `json:"rows"`
+	Imported int                   `json:"imported"`
+	Failed   int                   `json:"failed"`
+	Errors   []ContactFileRowError `json:"errors"`
+	Warnings []string              `json:"warnings,omitempty"`
+}
+
+type ContactFileRowError struct {
+	Line  int    `json:"line"`
+	Name  string `json:"name,omitempty"`
+	Error string `json:"error"`
+}
+
+type BoardResponse struct {
+	Field   string        `json:"field"`
+	Columns []BoardColumn `json:"columns"`
+}
+
+type BoardColumn struct {
+	Value      string                `json:"value"`
+	Items      []GetContactsResponse `json:"items"`
+	TotalCount int                   `json:"total_count"`
+	Page       int                   `json:"page"`
+	PageSize   int                   `json:"page_size"`
+	TotalPages int                   `json:"total_pages"`
+}
+
+type MoveContactRequest struct {
+	Stage    string `json:"stage"`
+	Position int    `json:"position,omitempty"`
+}
+
+type EnrichmentResponse struct {
+	ID             int               `json:"id"`
+	ContactID      int               `json:"contact_id"`
+	Provider       string            `json:"provider"`
+	MatchedOn      string            `json:"matched_on"`
+	Status         string            `json:"status"`
+	Company        string            `json:"company,omitempty"`
+	JobTitle       string            `json:"job_title,omitempty"`
+	SocialProfiles map[string]string `json:"social_profiles,omitempty"`
+	FetchedAt      time.Time         `json:"fetched_at"`
+	ResolvedAt     *time.Time        `json:"resolved_at,omitempty"`
+}
+
+type EnrichmentListResponse struct {
+	Items []EnrichmentResponse `json:"items"`
+}
+
+type SocialProfileListResponse struct {
+	Items []SocialProfile `json:"items"`
+}
+
+type SetSocialProfileRequest struct {
+	Value string `json:"value"`
+}
+
+type InteractionListResponse struct {
+	Items []Interaction `json:"items"`
+}
+
+type Interaction struct {
+	ID         int       `json:"id"`
+	ContactID  int       `json:"contact_id"`
+	Kind       string    `json:"kind"`
+	Note       string    `json:"note,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type LogInteractionRequest struct {
+	Kind       string     `json:"kind"`
+	Note       string     `json:"note,omitempty"`
+	OccurredAt *time.Time `json:"occurred_at,omitempty"`
+}
+
+type TagContactRequest struct {
+	Name string `json:"name"`
+}
+
+type ContactTagsResponse struct {
+	ContactID int          `json:"contact_id"`
+	Tags      []Membership `json:"tags"`
+}
+
+type AttachmentListResponse struct {
+	Items   []AttachmentResponse `json:"items"`
+	Storage StorageUsage         `json:"storage"`
+}
+
+type AttachmentResponse struct {
+	ID          int       `json:"id"`
+	ContactID   int       `json:"contact_id"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	SizeBytes   int64     `json:"size_bytes"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type StorageUsage struct {
+	UsedBytes  int64 `json:"used_bytes"`
+	QuotaBytes int64 `json:"quota_bytes"`
+}
+
+type AttachmentURLResponse struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type SharedContact struct {
+	Contact            GetContactsResponse `json:"contact"`
+	Status             string              `json:"status"`
+	SubmittedBy        int                 `json:"submitted_by"`
+	SubmittedAt        time.Time           `json:"submitted_at"`
+	ReviewedAt         *time.Time          `json:"reviewed_at,omitempty"`
+	ReviewNote         string              `json:"review_note,omitempty"`
+	ChangedAt          *time.Time          `json:"changed_at,omitempty"`
+	UpdatedSinceViewed bool                `json:"updated_since_viewed,omitempty"`
+}
+
+type SharedContactListResponse struct {
+	Items []SharedContact `json:"items"`
+}
+
+type ReviewSharedContactRequest struct {
+	Note string `json:"note,omitempty"`
+}
+
+type DirectoryResponse struct {
+	Title      string           `json:"title,omitempty"`
+	Branding   Branding         `json:"branding"`
+	Fields     []string         `json:"fields"`
+	Items      []DirectoryEntry `json:"items"`
+	TotalCount int              `json:"total_count"`
+	Page       int              `json:"page"`
+	PageSize   int              `json:"page_size"`
+	TotalPages int              `json:"total_pages"`
+}
+
+type DirectoryEntry struct {
+	FirstName      string          `json:"first_name"`
+	LastName       string          `json:"last_name"`
+	Email          string          `json:"email,omitempty"`
+	PhoneNumber    string          `json:"phone_number,omitempty"`
+	Company        string          `json:"company,omitempty"`
+	JobTitle       string          `json:"job_title,omitempty"`
+	City           string          `json:"city,omitempty"`
+	Region         string          `json:"region,omitempty"`
+	CountryCode    string          `json:"country_code,omitempty"`
+	SocialProfiles []SocialProfile `json:"social_profiles,omitempty"`
+}
+
+type SetDirectoryCardRequest struct {
+	ContactID *int `json:"contact_id,omitempty"`
+}
+
+type DirectorySettings struct {
+	Enabled bool     `json:"enabled"`
+	Slug    string   `json:"slug"`
+	Title   string   `json:"title,omitempty"`
+	Source  string   `json:"source"`
+	Fields  []string `json:"fields"`
+}
+
+type PicklistResponse struct {
+	Field  string   `json:"field"`
+	Values []string `json:"values"`
+}
+
+type CreatePicklistValueRequest struct {
+	Value    string `json:"value"`
+	Position int    `json:"position,omitempty"`
+}
+
+type ContactTemplate struct {
+	Version     int           `json:"version"`
+	Fields      []CustomField `json:"fields"`
+	DefaultTags []string      `json:"default_tags"`
+}
+
+type CustomField struct {
+	Key      string   `json:"key"`
+	Label    string   `json:"label"`
+	Type     string   `json:"type"`
+	Options  []string `json:"options,omitempty"`
+	Required bool     `json:"required"`
+	Default  string   `json:"default,omitempty"`
+	Position int      `json:"position"`
+}
+
+type SaveCustomFieldRequest struct {
+	Label    string   `json:"label"`
+	Type     string   `json:"type"`
+	Options  []string `json:"options,omitempty"`
+	Required bool     `json:"required,omitempty"`
+	Default  string   `json:"default,omitempty"`
+	Position int      `json:"position,omitempty"`
+}
+
+type SetTemplateTagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+type ContactTemplateMigration struct {
+	Version          int `json:"version"`
+	OutdatedContacts int `json:"outdated_contacts"`
+	MissingRequired  int `json:"missing_required"`
+}
+
+type AdminStatsResponse struct {
+	Users            int        `json:"users"`
+	Contacts         int        `json:"contacts"`
+	DailyActiveUsers int        `json:"daily_active_users"`
+	ImportsLast24h   int        `json:"imports_last_24h"`
+	ExportsLast24h   int        `json:"exports_last_24h"`
+	Cache            CacheStats `json:"cache"`
+	Jobs             JobStats   `json:"jobs"`
+	GeneratedAt      time.Time  `json:"generated_at"`
+}
+
+type CacheStats struct {
+	Hits    int64    `json:"hits"`
+	Misses  int64    `json:"misses"`
+	HitRate *float64 `json:"hit_rate,omitempty"`
+}
+
+type JobStats struct {
+	DigestsDue        int `json:"digests_due"`
+	AnalyticsBuffered int `json:"analytics_buffered"`
+}
+
+type AdminAPIUsageResponse struct {
+	From      string             `json:"from"`
+	To        string             `json:"to"`
+	Total     APIUsageCounts     `json:"total"`
+	Days      []APIUsageDay      `json:"days"`
+	Endpoints []APIUsageEndpoint `json:"endpoints"`
+	TopUsers  []APIUserUsage     `json:"top_users"`
+}
+
+type APIUserUsage struct {
+	UserID      int    `json:"user_id"`
+	Username    string `json:"username"`
+	Requests    int64  `json:"requests"`
+	RateLimited int64  `json:"rate_limited"`
+	BytesIn     int64  `json:"bytes_in"`
+	BytesOut    int64  `json:"bytes_out"`
+}
+
+type DuplicateUsersResponse struct {
+	Duplicates []DuplicateUsers `json:"duplicates"`
+}
+
+type DuplicateUsers struct {
+	Email string        `json:"email"`
+	Users []UserSummary `json:"users"`
+}
+
+type UserSummary struct {
+	ID        int       `json:"id"`
+	UserName  string    `json:"user_name"`
+	Email     string    `json:"email"`
+	IsAdmin   bool      `json:"is_admin"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type MergeUsersRequest struct {
+	SourceUserID int `json:"source_user_id"`
+	TargetUserID int `json:"target_user_id"`
+}
+
+type MergeUsersResponse struct {
+	SourceUserID int `json:"source_user_id"`
+	TargetUserID int `json:"target_user_id"`
+	Contacts     int `json:"contacts"`
+	Groups       int `json:"groups"`
+	Tags         int `json:"tags"`
+	Attachments  int `json:"attachments"`
+	Snapshots    int `json:"snapshots"`
+	Webhooks     int `json:"webhooks"`
+	APIKeys      int `json:"api_keys"`
+}
+
+type StartMaintenanceJobRequest struct {
+	Operation string `json:"operation"`
+	Top       int    `json:"top,omitempty"`
+}
+
+type MaintenanceJob struct {
+	ID         string             `json:"id"`
+	Operation  string             `json:"operation"`
+	Status     string             `json:"status"`
+	StartedAt  time.Time          `json:"started_at"`
+	FinishedAt *time.Time         `json:"finished_at,omitempty"`
+	Result     *MaintenanceResult `json:"result,omitempty"`
+	Error      string             `json:"error,omitempty"`
+}
+
+type MaintenanceResult struct {
+	Operation string `json:"operation"`
+	Scanned   int    `json:"scanned"`
+	Updated   int    `json:"updated"`
+	Users     int    `json:"users"`
+}
+
+type MaintenanceJobListResponse struct {
+	Items []MaintenanceJob `json:"items"`
+}
+
+type CreateAnnouncementRequest struct {
+	Message  string     `json:"message"`
+	Level    string     `json:"level"`
+	StartsAt *time.Time `json:"starts_at,omitempty"`
+	EndsAt   *time.Time `json:"ends_at,omitempty"`
+}
+
+type AnalyticsSettings struct {
+	Enabled    bool `json:"enabled"`
+	Configured bool `json:"configured"`
+}
+
+type UpdateAnalyticsSettingsRequest struct {
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+type AlertSettings struct {
+	Enabled            bool         `json:"enabled"`
+	Threshold          float64      `json:"threshold"`
+	MinRequests        int          `json:"min_requests"`
+	Window             string       `json:"window"`
+	DefaultSuppression string       `json:"default_suppression"`
+	Routes             []RouteAlert `json:"routes"`
+}
+
+type RouteAlert struct {
+	Route           string     `json:"route"`
+	Suppression     string     `json:"suppression,omitempty"`
+	Requests        int        `json:"requests"`
+	Errors          int        `json:"errors"`
+	SuppressedUntil *time.Time `json:"suppressed_until,omitempty"`
+}
+
+type SetRouteSuppressionRequest struct {
+	Route       string `json:"route"`
+	Suppression string `json:"suppression"`
+}
+
+type GroupListResponse struct {
+	Items []GroupResponse `json:"items"`
+}
+
+type GroupResponse struct {
+	ID           int       `json:"id"`
+	Name         string    `json:"name"`
+	ContactCount int       `json:"contact_count"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type CreateGroupRequest struct {
+	Name string `json:"name"`
+}
+
+type BulkContactsRequest struct {
+	ContactIDs []int `json:"contact_ids"`
+}
+
+type BulkContactsResponse struct {
+	Requested int `json:"requested"`
+	Changed   int `json:"changed"`
+}
+
+type TagListResponse struct {
+	Items []TagResponse `json:"items"`
+}
+
+type TagResponse struct {
+	ID           int       `json:"id"`
+	Name         string    `json:"name"`
+	ContactCount int       `json:"contact_count"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type CreateTagRequest struct {
+	Name string `json:"name"`
+}
+
+type SnapshotListResponse struct {
+	Items []SnapshotResponse `json:"items"`
+}
+
+type SnapshotResponse struct {
+	ID           int       `json:"id"`
+	Name         string    `json:"name"`
+	ContactCount int       `json:"contact_count"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type CreateSnapshotRequest struct {
+	Name string `json:"name"`
+}
+
+type SnapshotDiffResponse struct {
+	SnapshotID int                     `json:"snapshot_id"`
+	Added      []SnapshotContact       `json:"added"`
+	Removed    []SnapshotContact       `json:"removed"`
+	Changed    []SnapshotContactChange `json:"changed"`
+}
+
+type SnapshotContact struct {
+	ID        int    `json:"id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+type SnapshotContactChange struct {
+	ID        int                   `json:"id"`
+	FirstName string                `json:"first_name"`
+	LastName  string                `json:"last_name"`
+	Changes   []SnapshotFieldChange `json:"changes"`
+}
+
+type SnapshotFieldChange struct {
+	Field    string `json:"field"`
+	Snapshot string `json:"snapshot"`
+	Current  string `json:"current"`
+}
+
+type RestoreSnapshotResponse struct {
+	SnapshotID int `json:"snapshot_id"`
+	Recreated  int `json:"recreated"`
+	Updated    int `json:"updated"`
+	Deleted    int `json:"deleted"`
+}
+
+type WebhookListResponse struct {
+	Items []WebhookResponse `json:"items"`
+}
+
+type WebhookResponse struct {
+	ID        int       `json:"id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CreateWebhookRequest struct {
+	URL string `json:"url"`
+}
+
+type WebhookDeliveryListResponse struct {
+	Items []WebhookDelivery `json:"items"`
+}
+
+type WebhookDelivery struct {
+	ID            int64      `json:"id"`
+	EventID       string     `json:"event_id"`
+	EventType     string     `json:"event_type"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	StatusCode    int        `json:"status_code,omitempty"`
+	Error         string     `json:"error,omitempty"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+}
+
+type TestWebhookRequest struct {
+	WebhookID int `json:"webhook_id"`
+}
+
+type TestWebhookResponse struct {
+	WebhookID  int    `json:"webhook_id"`
+	EventID    string `json:"event_id"`
+	Delivered  bool   `json:"delivered"`
+	StatusCode int    `json:"status_code,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// CreateUser calls POST /users: register a user
+func (c *Client) CreateUser(ctx context.Context, body CreateUserRequest) (*CreateUserResponse, error) {
+	var result CreateUserResponse
+	if err := c.doJSON(ctx, "POST", "/users", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Login calls POST /login: log in and get an access token and a refresh token
+func (c *Client) Login(ctx context.Context, body LoginRequest) (*LoginResponse, error) {
+	var result LoginResponse
+	if err := c.doJSON(ctx, "POST", "/login", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// RefreshToken calls POST /token/refresh: exchange a refresh token for new tokens
+func (c *Client) RefreshToken(ctx context.Context, body RefreshTokenRequest) (*LoginResponse, error) {
+	var result LoginResponse
+	if err := c.doJSON(ctx, "POST", "/token/refresh", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Logout calls POST /logout: revoke the access token and the refresh token of the session
+func (c *Client) Logout(ctx context.Context, body LogoutRequest) (*MessageResponse, error) {
+	var result MessageResponse
+	if err := c.doJSON(ctx, "POST", "/logout", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetProfile calls GET /users/me: get the account of the current user
+func (c *Client) GetProfile(ctx context.Context) (*ProfileResponse, error) {
+	var result ProfileResponse
+	if err := c.doJSON(ctx, "GET", "/users/me", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpdateProfile calls PATCH /users/me: change the username and the email of the current user
+func (c *Client) UpdateProfile(ctx context.Context, body UpdateProfileRequest) (*ProfileResponse, error) {
+	var result ProfileResponse
+	if err := c.doJSON(ctx, "PATCH", "/users/me", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteAccount calls DELETE /users/me: delete the current user with its contacts and everything else it owns
+func (c *Client) DeleteAccount(ctx context.Context, body DeleteAccountRequest) (*MessageResponse, error) {
+	var result MessageResponse
+	if err := c.doJSON(ctx, "DELETE", "/users/me", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeactivateAccount calls POST /users/me/deactivate: freeze the current user, keeping its data, until reactivated from a link sent by email
+func (c *Client) DeactivateAccount(ctx context.Context, body DeactivateAccountRequest) (*MessageResponse, error) {
+	var result MessageResponse
+	if err := c.doJSON(ctx, "POST", "/users/me/deactivate", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// RequestReactivation calls POST /users/reactivate: email a reactivation link to a deactivated account
+func (c *Client) RequestReactivation(ctx context.Context, body RequestReactivation) (*MessageResponse, error) {
+	var result MessageResponse
+	if err := c.doJSON(ctx, "POST", "/users/reactivate", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ConfirmReactivation calls GET /users/reactivate/confirm: reactivate an account from a link sent by email
+func (c *Client) ConfirmReactivation(ctx context.Context, query url.Values) (*MessageResponse, error) {
+	var result MessageResponse
+	if err := c.doJSON(ctx, "GET", "/users/reactivate/confirm", query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// RequestEmailChange calls POST /users/me/email: change the email once confirmed from the current and the new address
+func (c *Client) RequestEmailChange(ctx context.Context, body RequestEmailChange) (*EmailChange, error) {
+	var result EmailChange
+	if err := c.doJSON(ctx, "POST", "/users/me/email", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CancelEmailChange calls DELETE /users/me/email: cancel the pending email change
+func (c *Client) CancelEmailChange(ctx context.Context) (*MessageResponse, error) {
+	var result MessageResponse
+	if err := c.doJSON(ctx, "DELETE", "/users/me/email", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ConfirmEmailChange calls GET /users/email/confirm: confirm an email change from a link sent by email
+func (c *Client) ConfirmEmailChange(ctx context.Context, query url.Values) (*ConfirmEmailChangeResponse, error) {
+	var result ConfirmEmailChangeResponse
+	if err := c.doJSON(ctx, "GET", "/users/email/confirm", query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ChangeUsername calls PUT /users/me/username: change the username, at most once every 30 days
+func (c *Client) ChangeUsername(ctx context.Context, body ChangeUsernameRequest) (*ProfileResponse, error) {
+	var result ProfileResponse
+	if err := c.doJSON(ctx, "PUT", "/users/me/username", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetAPIUsage calls GET /users/me/usage: get the API requests of the current user by day and endpoint
+func (c *Client) GetAPIUsage(ctx context.Context, query url.Values) (*APIUsageResponse, error) {
+	var result APIUsageResponse
+	if err := c.doJSON(ctx, "GET", "/users/me/usage", query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// LookupUsername calls GET /users/by-username/:username: find the account of a username, following former usernames
+func (c *Client) LookupUsername(ctx context.Context, username string) (*UserLookupResponse, error) {
+	var result UserLookupResponse
+	if err := c.doJSON(ctx, "GET", "/users/by-username/"+url.PathEscape(username), nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ChangePassword calls PUT /users/me/password: change the password and revoke every other session
+func (c *Client) ChangePassword(ctx context.Context, body ChangePasswordRequest) (*LoginResponse, error) {
+	var result LoginResponse
+	if err := c.doJSON(ctx, "PUT", "/users/me/password", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetAnnouncements calls GET /announcements: list the announcements the current user did not dismiss
+func (c *Client) GetAnnouncements(ctx context.Context) (*AnnouncementListResponse, error) {
+	var result AnnouncementListResponse
+	if err := c.doJSON(ctx, "GET", "/announcements", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DismissAnnouncement calls POST /announcements/:id/dismiss: hide an announcement from the current user
+func (c *Client) DismissAnnouncement(ctx context.Context, id int) (*MessageResponse, error) {
+	var result MessageResponse
+	if err := c.doJSON(ctx, "POST", "/announcements/"+strconv.Itoa(id)+"/dismiss", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetPreferences calls GET /users/me/preferences: get the preferences of the current user
+func (c *Client) GetPreferences(ctx context.Context) (*PreferencesResponse, error) {
+	var result PreferencesResponse
+	if err := c.doJSON(ctx, "GET", "/users/me/preferences", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpdatePreferences calls PATCH /users/me/preferences: update the preferences of the current user
+func (c *Client) UpdatePreferences(ctx context.Context, body UpdatePreferencesRequest) (*PreferencesResponse, error) {
+	var result PreferencesResponse
+	if err := c.doJSON(ctx, "PATCH", "/users/me/preferences", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetNotificationPreferences calls GET /users/me/notifications: get the notification preferences matrix of the current user
+func (c *Client) GetNotificationPreferences(ctx context.Context) (*NotificationPreferences, error) {
+	var result NotificationPreferences
+	if err := c.doJSON(ctx, "GET", "/users/me/notifications", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpdateNotificationPreferences calls PATCH /users/me/notifications: turn notifications of the current user on or off by event and channel
+func (c *Client) UpdateNotificationPreferences(ctx context.Context, body UpdateNotificationPreferencesRequest) (*NotificationPreferences, error) {
+	var result NotificationPreferences
+	if err := c.doJSON(ctx, "PATCH", "/users/me/notifications", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ExportAccount calls GET /users/me/export: download the whole account as a versioned archive
+func (c *Client) ExportAccount(ctx context.Context) (*AccountArchive, error) {
+	var result AccountArchive
+	if err := c.doJSON(ctx, "GET", "/users/me/export", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ImportAccount calls POST /users/me/import: add the contacts, groups, tags and preferences of an account archive
+func (c *Client) ImportAccount(ctx context.Context, body AccountArchive) (*ImportAccountResponse, error) {
+	var result ImportAccountResponse
+	if err := c.doJSON(ctx, "POST", "/users/me/import", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListAPIKeys calls GET /api-keys: list API keys
+func (c *Client) ListAPIKeys(ctx context.Context) (*APIKeyListResponse, error) {
+	var result APIKeyListResponse
+	if err := c.doJSON(ctx, "GET", "/api-keys", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CreateAPIKey calls POST /api-keys: create an API key
+func (c *Client) CreateAPIKey(ctx context.Context, body CreateAPIKeyRequest) (*APIKeyResponse, error) {
+	var result APIKeyResponse
+	if err := c.doJSON(ctx, "POST", "/api-keys", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteAPIKey calls DELETE /api-keys/:id: revoke an API key
+func (c *Client) DeleteAPIKey(ctx context.Context, id int) (*MessageResponse, error) {
+	var result MessageResponse
+	if err := c.doJSON(ctx, "DELETE", "/api-keys/"+strconv.Itoa(id), nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListEmbedTokens calls GET /embed-tokens: list the read-only tokens embedding contact lists
+func (c *Client) ListEmbedTokens(ctx context.Context) (*EmbedTokenListResponse, error) {
+	var result EmbedTokenListResponse
+	if err := c.doJSON(ctx, "GET", "/embed-tokens", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CreateEmbedToken calls POST /embed-tokens: create a read-only token embedding a group or a saved search in another page
+func (c *Client) CreateEmbedToken(ctx context.Context, body CreateEmbedTokenRequest) (*EmbedToken, error) {
+	var result EmbedToken
+	if err := c.doJSON(ctx, "POST", "/embed-tokens", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// RevokeEmbedToken calls DELETE /embed-tokens/:id: revoke an embed token
+func (c *Client) RevokeEmbedToken(ctx context.Context, id int) (*MessageResponse, error) {
+	var result MessageResponse
+	if err := c.doJSON(ctx, "DELETE", "/embed-tokens/"+strconv.Itoa(id), nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetEmbeddedContacts calls GET /embed/contacts: list the contacts of an embed token, without logging in
+func (c *Client) GetEmbeddedContacts(ctx context.Context, query url.Values) (*EmbedContactListResponse, error) {
+	var result EmbedContactListResponse
+	if err := c.doJSON(ctx, "GET", "/embed/contacts", query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetContacts calls GET /contacts: list contacts, filtered and paginated
+func (c *Client) GetContacts(ctx context.Context, query url.Values) (*PaginationResult, error) {
+	var result PaginationResult
+	if err := c.doJSON(ctx, "GET", "/contacts", query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetContactsAll calls fn for every item of every page of GetContacts, stopping at the first error
+func (c *Client) GetContactsAll(ctx context.Context, query url.Values, fn func(GetContactsResponse) error) error {
+	pageQuery := url.Values{}
+	for key, values := range query {
+		pageQuery[key] = values
+	}
+	pageQuery.Del("page")
+	for {
+		result, err := c.GetContacts(ctx, pageQuery)
+		if err != nil {
+			return err
+		}
+		for _, item := range result.Items {
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+		if result.NextCursor == "" {
+			return nil
+		}
+		pageQuery.Set("cursor", result.NextCursor)
+	}
+}
+
+// GetContact calls GET /contacts/:id: get a contact
+func (c *Client) GetContact(ctx context.Context, id int) (*GetContactsResponse, error) {
+	var result GetContactsResponse
+	if err := c.doJSON(ctx, "GET", "/contacts/"+strconv.Itoa(id), nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CreateContact calls POST /contacts: create a contact
+func (c *Client) CreateContact(ctx context.Context, body CreateContactRequest) (*CreateContactResponse, error) {
+	var result CreateContactResponse
+	if err := c.doJSON(ctx, "POST", "/contacts", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpdateContact calls PATCH /contacts/:id: update a contact
+func (c *Client) UpdateContact(ctx context.Context, id int, body UpdateContactRequest) (*MessageResponse, error) {
+	var result MessageResponse
+	if err := c.doJSON(ctx, "PATCH", "/contacts/"+strconv.Itoa(id), nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteContact calls DELETE /contacts/:id: delete a contact
+func (c *Client) DeleteContact(ctx context.Context, id int, query url.Values) (*MessageResponse, error) {
+	var result MessageResponse
+	if err := c.doJSON(ctx, "DELETE", "/contacts/"+strconv.Itoa(id), query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetIncompleteContacts calls GET /contacts/incomplete: list the contacts missing data, least complete first
+func (c *Client) GetIncompleteContacts(ctx context.Context, query url.Values) (*IncompleteContactsResponse, error) {
+	var result IncompleteContactsResponse
+	if err := c.doJSON(ctx, "GET", "/contacts/incomplete", query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetDuplicateContacts calls GET /contacts/duplicates: list the pairs of contacts likely to be the same person, with the score of each signal
+func (c *Client) GetDuplicateContacts(ctx context.Context, query url.Values) (*DuplicateContactsResponse, error) {
+	var result DuplicateContactsResponse
+	if err := c.doJSON(ctx, "GET", "/contacts/duplicates", query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetContactCompleteness calls GET /contacts/:id/completeness: score how filled in a contact is
+func (c *Client) GetContactCompleteness(ctx context.Context, id int) (*ContactCompleteness, error) {
+	var result ContactCompleteness
+	if err := c.doJSON(ctx, "GET", "/contacts/"+strconv.Itoa(id)+"/completeness", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListTrash calls GET /contacts/trash: list the deleted contacts of the trash
+func (c *Client) ListTrash(ctx context.Context) (*TrashListResponse, error) {
+	var result TrashListResponse
+	if err := c.doJSON(ctx, "GET", "/contacts/trash", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetTrashedContact calls GET /contacts/trash/:id: get a deleted contact of the trash
+func (c *Client) GetTrashedContact(ctx context.Context, id int) (*TrashedContact, error) {
+	var result TrashedContact
+	if err := c.doJSON(ctx, "GET", "/contacts/trash/"+strconv.Itoa(id), nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// RestoreContact calls POST /contacts/:id/restore: restore a contact from the trash
+func (c *Client) RestoreContact(ctx context.Context, id int) (*GetContactsResponse, error) {
+	var result GetContactsResponse
+	if err := c.doJSON(ctx, "POST", "/contacts/"+strconv.Itoa(id)+"/restore", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetContactHistory calls GET /contacts/:id/history: list the changes of a contact
+func (c *Client) GetContactHistory(ctx context.Context, id int) (*ContactHistoryResponse, error) {
+	var result ContactHistoryResponse
+	if err := c.doJSON(ctx, "GET", "/contacts/"+strconv.Itoa(id)+"/history", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetContactChanges calls GET /contacts/changes: wait for changes to the contacts after a cursor (long polling)
+func (c *Client) GetContactChanges(ctx context.Context, query url.Values) (*ContactChangesResponse, error) {
+	var result ContactChangesResponse
+	if err := c.doJSON(ctx, "GET", "/contacts/changes", query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetSyncContact calls GET /sync/contacts/:id: get a contact with its version for a sync client
+func (c *Client) GetSyncContact(ctx context.Context, id int) (*SyncContact, error) {
+	var result SyncContact
+	if err := c.doJSON(ctx, "GET", "/sync/contacts/"+strconv.Itoa(id), nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SyncUpdateContact calls PATCH /sync/contacts/:id: merge the changes a sync client made to a contact at a base version, field by field
+func (c *Client) SyncUpdateContact(ctx context.Context, id int, body SyncUpdateContactRequest) (*SyncUpdateContactResponse, error) {
+	var result SyncUpdateContactResponse
+	if err := c.doJSON(ctx, "PATCH", "/sync/contacts/"+strconv.Itoa(id), nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ApplySyncBatch calls POST /sync/batch: apply the queued mutations of an offline client in order, all or none
+func (c *Client) ApplySyncBatch(ctx context.Context, body SyncBatchRequest) (*SyncBatchResponse, error) {
+	var result SyncBatchResponse
+	if err := c.doJSON(ctx, "POST", "/sync/batch", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetContactCount calls GET /contacts/count: count contacts and unread changes, for polling
+func (c *Client) GetContactCount(ctx context.Context, query url.Values) (*ContactCountResponse, error) {
+	var result ContactCountResponse
+	if err := c.doJSON(ctx, "GET", "/contacts/count", query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetContactStats calls GET /contacts/stats: count contacts by stage and source
+func (c *Client) GetContactStats(ctx context.Context) (*ContactStatsResponse, error) {
+	var result ContactStatsResponse
+	if err := c.doJSON(ctx, "GET", "/contacts/stats", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetContactsGeoJSON calls GET /contacts/geojson: get contacts as GeoJSON for the map view
+func (c *Client) GetContactsGeoJSON(ctx context.Context, query url.Values) (*GeoJSONFeatureCollection, error) {
+	var result GeoJSONFeatureCollection
+	if err := c.doJSON(ctx, "GET", "/contacts/geojson", query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ImportCardImage calls POST /contacts/import/card-image: extract a draft contact from a business card photo
+func (c *Client) ImportCardImage(ctx context.Context, fileName string, file io.Reader) (*CardImportResponse, error) {
+	var result CardImportResponse
+	if err := c.doMultipart(ctx, "POST", "/contacts/import/card-image", "image", fileName, file, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ImportContactFile calls POST /contacts/import: add the contacts of a CSV or vCard file, reporting the rows not imported
+func (c *Client) ImportContactFile(ctx context.Context, fileName string, file io.Reader) (*ContactFileImportResponse, error) {
+	var result ContactFileImportResponse
+	if err := c.doMultipart(ctx, "POST", "/contacts/import", "file", fileName, file, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ExportContactFile calls GET /contacts/export: download every contact as a CSV or vCard file
+// the caller must close the body of the returned response
+func (c *Client) ExportContactFile(ctx context.Context, query url.Values) (*http.Response, error) {
+	return c.send(ctx, "GET", "/contacts/export", query, nil, "")
+}
+
+// GetBoard calls GET /contacts/board: get the kanban board
+func (c *Client) GetBoard(ctx context.Context, query url.Values) (*BoardResponse, error) {
+	var result BoardResponse
+	if err := c.doJSON(ctx, "GET", "/contacts/board", query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// MoveContact calls POST /contacts/:id/move: move a contact to a board column
+func (c *Client) MoveContact(ctx context.Context, id int, body MoveContactRequest) (*MessageResponse, error) {
+	var result MessageResponse
+	if err := c.doJSON(ctx, "POST", "/contacts/"+strconv.Itoa(id)+"/move", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// EnrichContact calls POST /contacts/:id/enrich: fetch enrichment suggestions for a contact
+func (c *Client) EnrichContact(ctx context.Context, id int) (*EnrichmentResponse, error) {
+	var result EnrichmentResponse
+	if err := c.doJSON(ctx, "POST", "/contacts/"+strconv.Itoa(id)+"/enrich", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListEnrichments calls GET /contacts/:id/enrichments: list the enrichment suggestions of a contact
+func (c *Client) ListEnrichments(ctx context.Context, id int) (*EnrichmentListResponse, error) {
+	var result EnrichmentListResponse
+	if err := c.doJSON(ctx, "GET", "/contacts/"+strconv.Itoa(id)+"/enrichments", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// AcceptEnrichment calls POST /contacts/:id/enrichments/:enrichmentId/accept: apply an enrichment suggestion
+func (c *Client) AcceptEnrichment(ctx context.Context, id int, enrichmentID int) (*MessageResponse, error) {
+	var result MessageResponse
+	if err := c.doJSON(ctx, "POST", "/contacts/"+strconv.Itoa(id)+"/enrichments/"+strconv.Itoa(enrichmentID)+"/accept", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// RejectEnrichment calls POST /contacts/:id/enrichments/:enrichmentId/reject: discard an enrichment suggestion
+func (c *Client) RejectEnrichment(ctx context.Context, id int, enrichmentID int) (*MessageResponse, error) {
+	var result MessageResponse
+	if err := c.doJSON(ctx, "POST", "/contacts/"+strconv.Itoa(id)+"/enrichments/"+strconv.Itoa(enrichmentID)+"/reject", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetSocialProfiles calls GET /contacts/:id/social: list the social profiles of a contact
+func (c *Client) GetSocialProfiles(ctx context.Context, id int) (*SocialProfileListResponse, error) {
+	var result SocialProfileListResponse
+	if err := c.doJSON(ctx, "GET", "/contacts/"+strconv.Itoa(id)+"/social", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SetSocialProfile calls PUT /contacts/:id/social/:network: set the profile of a contact on a social network
+func (c *Client) SetSocialProfile(ctx context.Context, id int, network string, body SetSocialProfileRequest) (*SocialProfile, error) {
+	var result SocialProfile
+	if err := c.doJSON(ctx, "PUT", "/contacts/"+strconv.Itoa(id)+"/social/"+url.PathEscape(network), nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteSocialProfile calls DELETE /contacts/:id/social/:network: remove the profile of a contact on a social network
+func (c *Client) DeleteSocialProfile(ctx context.Context, id int, network string) (*MessageResponse, error) {
+	var result MessageResponse
+	if err := c.doJSON(ctx, "DELETE", "/contacts/"+strconv.Itoa(id)+"/social/"+url.PathEscape(network), nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetInteractions calls GET /contacts/:id/interactions: list the interactions logged with a contact
+func (c *Client) GetInteractions(ctx context.Context, id int) (*InteractionListResponse, error) {
+	var result InteractionListResponse
+	if err := c.doJSON(ctx, "GET", "/contacts/"+strconv.Itoa(id)+"/interactions", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// LogInteraction calls POST /contacts/:id/interactions: log a call, meeting or message with a contact
+func (c *Client) LogInteraction(ctx context.Context, id int, body LogInteractionRequest) (*Interaction, error) {
+	var result Interaction
+	if err := c.doJSON(ctx, "POST", "/contacts/"+strconv.Itoa(id)+"/interactions", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// TagContact calls POST /contacts/:id/tags: attach a tag to a contact
+func (c *Client) TagContact(ctx context.Context, id int, body TagContactRequest) (*ContactTagsResponse, error) {
+	var result ContactTagsResponse
+	if err := c.doJSON(ctx, "POST", "/contacts/"+strconv.Itoa(id)+"/tags", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UntagContact calls DELETE /contacts/:id/tags/:name: detach a tag from a contact
+func (c *Client) UntagContact(ctx context.Context, id int, name string) (*ContactTagsResponse, error) {
+	var result ContactTagsResponse
+	if err := c.doJSON(ctx, "DELETE", "/contacts/"+strconv.Itoa(id)+"/tags/"+url.PathEscape(name), nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListAttachments calls GET /contacts/:id/attachments: list the attachments of a contact
+func (c *Client) ListAttachments(ctx context.Context, id int) (*AttachmentListResponse, error) {
+	var result AttachmentListResponse
+	if err := c.doJSON(ctx, "GET", "/contacts/"+strconv.Itoa(id)+"/attachments", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UploadAttachment calls POST /contacts/:id/attachments: attach a file to a contact
+func (c *Client) UploadAttachment(ctx context.Context, id int, fileName string, file io.Reader) (*AttachmentResponse, error) {
+	var result AttachmentResponse
+	if err := c.doMultipart(ctx, "POST", "/contacts/"+strconv.Itoa(id)+"/attachments", "file", fileName, file, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetAttachmentURL calls GET /contacts/:id/attachments/:attachmentId/url: get a signed download link for an attachment
+func (c *Client) GetAttachmentURL(ctx context.Context, id int, attachmentID int) (*AttachmentURLResponse, error) {
+	var result AttachmentURLResponse
+	if err := c.doJSON(ctx, "GET", "/contacts/"+strconv.Itoa(id)+"/attachments/"+strconv.Itoa(attachmentID)+"/url", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteAttachment calls DELETE /contacts/:id/attachments/:attachmentId: delete an attachment
+func (c *Client) DeleteAttachment(ctx context.Context, id int, attachmentID int) (*MessageResponse, error) {
+	var result MessageResponse
+	if err := c.doJSON(ctx, "DELETE", "/contacts/"+strconv.Itoa(id)+"/attachments/"+strconv.Itoa(attachmentID), nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DownloadAttachment calls GET /attachments/:attachmentId/download: download an attachment from a signed link
+// the caller must close the body of the returned response
+func (c *Client) DownloadAttachment(ctx context.Context, attachmentID int, query url.Values) (*http.Response, error) {
+	return c.send(ctx, "GET", "/attachments/"+strconv.Itoa(attachmentID)+"/download", query, nil, "")
+}
+
+// ShareContact calls POST /contacts/:id/share: submit a contact to the address book shared with every user
+func (c *Client) ShareContact(ctx context.Context, id int) (*SharedContact, error) {
+	var result SharedContact
+	if err := c.doJSON(ctx, "POST", "/contacts/"+strconv.Itoa(id)+"/share", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UnshareContact calls DELETE /contacts/:id/share: take a contact out of the shared address book
+func (c *Client) UnshareContact(ctx context.Context, id int) (*MessageResponse, error) {
+	var result MessageResponse
+	if err := c.doJSON(ctx, "DELETE", "/contacts/"+strconv.Itoa(id)+"/share", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListSharedContacts calls GET /shared-contacts: list the contacts shared with every user
+func (c *Client) ListSharedContacts(ctx context.Context) (*SharedContactListResponse, error) {
+	var result SharedContactListResponse
+	if err := c.doJSON(ctx, "GET", "/shared-contacts", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListSharedSubmissions calls GET /shared-contacts/submissions: list the contacts the current user submitted with their review
+func (c *Client) ListSharedSubmissions(ctx context.Context) (*SharedContactListResponse, error) {
+	var result SharedContactListResponse
+	if err := c.doJSON(ctx, "GET", "/shared-contacts/submissions", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListPendingSharedContacts calls GET /shared-contacts/pending: list the submissions waiting for review, admins and editors only
+func (c *Client) ListPendingSharedContacts(ctx context.Context) (*SharedContactListResponse, error) {
+	var result SharedContactListResponse
+	if err := c.doJSON(ctx, "GET", "/shared-contacts/pending", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetSharedContact calls GET /shared-contacts/:id: get a contact of the shared address book and mark it viewed
+func (c *Client) GetSharedContact(ctx context.Context, id int) (*SharedContact, error) {
+	var result SharedContact
+	if err := c.doJSON(ctx, "GET", "/shared-contacts/"+strconv.Itoa(id), nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ApproveSharedContact calls POST /shared-contacts/:id/approve: approve a submission to the shared address book, admins and editors only
+func (c *Client) ApproveSharedContact(ctx context.Context, id int, body ReviewSharedContactRequest) (*SharedContact, error) {
+	var result SharedContact
+	if err := c.doJSON(ctx, "POST", "/shared-contacts/"+strconv.Itoa(id)+"/approve", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// RejectSharedContact calls POST /shared-contacts/:id/reject: reject a submission to the shared address book, admins and editors only
+func (c *Client) RejectSharedContact(ctx context.Context, id int, body ReviewSharedContactRequest) (*SharedContact, error) {
+	var result SharedContact
+	if err := c.doJSON(ctx, "POST", "/shared-contacts/"+strconv.Itoa(id)+"/reject", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetDirectory calls GET /directory/:slug: search the public team directory
+func (c *Client) GetDirectory(ctx context.Context, slug string, query url.Values) (*DirectoryResponse, error) {
+	var result DirectoryResponse
+	if err := c.doJSON(ctx, "GET", "/directory/"+url.PathEscape(slug), query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetBranding calls GET /branding: get the name, support email and logo of the deployment for its public pages
+func (c *Client) GetBranding(ctx context.Context) (*Branding, error) {
+	var result Branding
+	if err := c.doJSON(ctx, "GET", "/branding", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SetDirectoryCard calls PUT /users/me/directory-card: pick the contact standing for the current user in the team directory
+func (c *Client) SetDirectoryCard(ctx context.Context, body SetDirectoryCardRequest) (*MessageResponse, error) {
+	var result MessageResponse
+	if err := c.doJSON(ctx, "PUT", "/users/me/directory-card", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetDirectorySettings calls GET /admin/directory: get the settings of the team directory
+func (c *Client) GetDirectorySettings(ctx context.Context) (*DirectorySettings, error) {
+	var result DirectorySettings
+	if err := c.doJSON(ctx, "GET", "/admin/directory", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SaveDirectorySettings calls PUT /admin/directory: enable the team directory and choose its path, source and visible fields
+func (c *Client) SaveDirectorySettings(ctx context.Context, body DirectorySettings) (*DirectorySettings, error) {
+	var result DirectorySettings
+	if err := c.doJSON(ctx, "PUT", "/admin/directory", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetPicklist calls GET /picklists/:field: list the allowed values of a picklist field
+func (c *Client) GetPicklist(ctx context.Context, field string) (*PicklistResponse, error) {
+	var result PicklistResponse
+	if err := c.doJSON(ctx, "GET", "/picklists/"+url.PathEscape(field), nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// AddPicklistValue calls POST /admin/picklists/:field: add an allowed value to a picklist field
+func (c *Client) AddPicklistValue(ctx context.Context, field string, body CreatePicklistValueRequest) (*MessageResponse, error) {
+	var result MessageResponse
+	if err := c.doJSON(ctx, "POST", "/admin/picklists/"+url.PathEscape(field), nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeletePicklistValue calls DELETE /admin/picklists/:field/:value: remove an allowed value from a picklist field
+func (c *Client) DeletePicklistValue(ctx context.Context, field string, value string) (*MessageResponse, error) {
+	var result MessageResponse
+	if err := c.doJSON(ctx, "DELETE", "/admin/picklists/"+url.PathEscape(field)+"/"+url.PathEscape(value), nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetContactTemplate calls GET /contact-template: get the custom fields and default tags of contacts
+func (c *Client) GetContactTemplate(ctx context.Context) (*ContactTemplate, error) {
+	var result ContactTemplate
+	if err := c.doJSON(ctx, "GET", "/contact-template", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SaveCustomField calls PUT /admin/contact-template/fields/:key: create or replace a custom field of the contact template
+func (c *Client) SaveCustomField(ctx context.Context, key string, body SaveCustomFieldRequest) (*ContactTemplate, error) {
+	var result ContactTemplate
+	if err := c.doJSON(ctx, "PUT", "/admin/contact-template/fields/"+url.PathEscape(key), nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteCustomField calls DELETE /admin/contact-template/fields/:key: remove a custom field from the contact template
+func (c *Client) DeleteCustomField(ctx context.Context, key string) (*ContactTemplate, error) {
+	var result ContactTemplate
+	if err := c.doJSON(ctx, "DELETE", "/admin/contact-template/fields/"+url.PathEscape(key), nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SetTemplateTags calls PUT /admin/contact-template/tags: set the tags attached to every new contact
+func (c *Client) SetTemplateTags(ctx context.Context, body SetTemplateTagsRequest) (*ContactTemplate, error) {
+	var result ContactTemplate
+	if err := c.doJSON(ctx, "PUT", "/admin/contact-template/tags", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetTemplateMigration calls GET /admin/contact-template/migration: report the migration of the contacts to the current contact template
+func (c *Client) GetTemplateMigration(ctx context.Context) (*ContactTemplateMigration, error) {
+	var result ContactTemplateMigration
+	if err := c.doJSON(ctx, "GET", "/admin/contact-template/migration", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetMetrics calls GET /admin/metrics: get the application counters
+// the caller must close the body of the returned response
+func (c *Client) GetMetrics(ctx context.Context) (*http.Response, error) {
+	return c.send(ctx, "GET", "/admin/metrics", nil, nil, "")
+}
+
+// GetAdminStats calls GET /admin/stats: get instance wide numbers for the admin dashboard
+func (c *Client) GetAdminStats(ctx context.Context) (*AdminStatsResponse, error) {
+	var result AdminStatsResponse
+	if err := c.doJSON(ctx, "GET", "/admin/stats", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetAdminAPIUsage calls GET /admin/usage: get the API requests of every user by day and endpoint, with the top users
+func (c *Client) GetAdminAPIUsage(ctx context.Context, query url.Values) (*AdminAPIUsageResponse, error) {
+	var result AdminAPIUsageResponse
+	if err := c.doJSON(ctx, "GET", "/admin/usage", query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListDuplicateUsers calls GET /admin/users/duplicates: list the accounts sharing an email, candidates for a merge
+func (c *Client) ListDuplicateUsers(ctx context.Context) (*DuplicateUsersResponse, error) {
+	var result DuplicateUsersResponse
+	if err := c.doJSON(ctx, "GET", "/admin/users/duplicates", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// MergeUsers calls POST /admin/users/merge: merge an account into another and delete it
+func (c *Client) MergeUsers(ctx context.Context, body MergeUsersRequest) (*MergeUsersResponse, error) {
+	var result MergeUsersResponse
+	if err := c.doJSON(ctx, "POST", "/admin/users/merge", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// StartMaintenanceJob calls POST /admin/maintenance/jobs: rebuild search indexes, normalized phone numbers, per-user totals or the caches of the most active users in the background
+func (c *Client) StartMaintenanceJob(ctx context.Context, body StartMaintenanceJobRequest) (*MaintenanceJob, error) {
+	var result MaintenanceJob
+	if err := c.doJSON(ctx, "POST", "/admin/maintenance/jobs", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListMaintenanceJobs calls GET /admin/maintenance/jobs: list the maintenance jobs of the replica answering
+func (c *Client) ListMaintenanceJobs(ctx context.Context) (*MaintenanceJobListResponse, error) {
+	var result MaintenanceJobListResponse
+	if err := c.doJSON(ctx, "GET", "/admin/maintenance/jobs", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetMaintenanceJob calls GET /admin/maintenance/jobs/:id: get the status and result of a maintenance job
+func (c *Client) GetMaintenanceJob(ctx context.Context, id int) (*MaintenanceJob, error) {
+	var result MaintenanceJob
+	if err := c.doJSON(ctx, "GET", "/admin/maintenance/jobs/"+strconv.Itoa(id), nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListAnnouncements calls GET /admin/announcements: list every announcement, ended and scheduled ones included
+func (c *Client) ListAnnouncements(ctx context.Context) (*AnnouncementListResponse, error) {
+	var result AnnouncementListResponse
+	if err := c.doJSON(ctx, "GET", "/admin/announcements", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CreateAnnouncement calls POST /admin/announcements: post an announcement to every user
+func (c *Client) CreateAnnouncement(ctx context.Context, body CreateAnnouncementRequest) (*Announcement, error) {
+	var result Announcement
+	if err := c.doJSON(ctx, "POST", "/admin/announcements", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteAnnouncement calls DELETE /admin/announcements/:id: remove an announcement
+func (c *Client) DeleteAnnouncement(ctx context.Context, id int) (*MessageResponse, error) {
+	var result MessageResponse
+	if err := c.doJSON(ctx, "DELETE", "/admin/announcements/"+strconv.Itoa(id), nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetAnalyticsSettings calls GET /admin/analytics: get the instance wide analytics settings
+func (c *Client) GetAnalyticsSettings(ctx context.Context) (*AnalyticsSettings, error) {
+	var result AnalyticsSettings
+	if err := c.doJSON(ctx, "GET", "/admin/analytics", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpdateAnalyticsSettings calls PUT /admin/analytics: opt the instance in or out of analytics
+func (c *Client) UpdateAnalyticsSettings(ctx context.Context, body UpdateAnalyticsSettingsRequest) (*AnalyticsSettings, error) {
+	var result AnalyticsSettings
+	if err := c.doJSON(ctx, "PUT", "/admin/analytics", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetAlertSettings calls GET /admin/alerts: get the 5xx error budget alerting settings and route states
+func (c *Client) GetAlertSettings(ctx context.Context) (*AlertSettings, error) {
+	var result AlertSettings
+	if err := c.doJSON(ctx, "GET", "/admin/alerts", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SetRouteSuppression calls PUT /admin/alerts/suppressions: set how long alerts of a route are suppressed after one fires
+func (c *Client) SetRouteSuppression(ctx context.Context, body SetRouteSuppressionRequest) (*AlertSettings, error) {
+	var result AlertSettings
+	if err := c.doJSON(ctx, "PUT", "/admin/alerts/suppressions", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ExportAuditLog calls GET /audit/export: export the audit log as CSV
+// the caller must close the body of the returned response
+func (c *Client) ExportAuditLog(ctx context.Context, query url.Values) (*http.Response, error) {
+	return c.send(ctx, "GET", "/audit/export", query, nil, "")
+}
+
+// ListGroups calls GET /groups: list contact groups
+func (c *Client) ListGroups(ctx context.Context) (*GroupListResponse, error) {
+	var result GroupListResponse
+	if err := c.doJSON(ctx, "GET", "/groups", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CreateGroup calls POST /groups: create a contact group
+func (c *Client) CreateGroup(ctx context.Context, body CreateGroupRequest) (*GroupResponse, error) {
+	var result GroupResponse
+	if err := c.doJSON(ctx, "POST", "/groups", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteGroup calls DELETE /groups/:id: delete a contact group
+func (c *Client) DeleteGroup(ctx context.Context, id int) (*MessageResponse, error) {
+	var result MessageResponse
+	if err := c.doJSON(ctx, "DELETE", "/groups/"+strconv.Itoa(id), nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// AddContactsToGroup calls POST /groups/:id/contacts: add contacts to a group
+func (c *Client) AddContactsToGroup(ctx context.Context, id int, body BulkContactsRequest) (*BulkContactsResponse, error) {
+	var result BulkContactsResponse
+	if err := c.doJSON(ctx, "POST", "/groups/"+strconv.Itoa(id)+"/contacts", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// RemoveContactsFromGroup calls DELETE /groups/:id/contacts: remove contacts from a group
+func (c *Client) RemoveContactsFromGroup(ctx context.Context, id int, body BulkContactsRequest) (*BulkContactsResponse, error) {
+	var result BulkContactsResponse
+	if err := c.doJSON(ctx, "DELETE", "/groups/"+strconv.Itoa(id)+"/contacts", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListTags calls GET /tags: list tags
+func (c *Client) ListTags(ctx context.Context) (*TagListResponse, error) {
+	var result TagListResponse
+	if err := c.doJSON(ctx, "GET", "/tags", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CreateTag calls POST /tags: create a tag
+func (c *Client) CreateTag(ctx context.Context, body CreateTagRequest) (*TagResponse, error) {
+	var result TagResponse
+	if err := c.doJSON(ctx, "POST", "/tags", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteTag calls DELETE /tags/:name: delete a tag, its contacts are kept
+func (c *Client) DeleteTag(ctx context.Context, name string) (*MessageResponse, error) {
+	var result MessageResponse
+	if err := c.doJSON(ctx, "DELETE", "/tags/"+url.PathEscape(name), nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// AddContactsToTag calls POST /tags/:name/contacts: tag contacts
+func (c *Client) AddContactsToTag(ctx context.Context, name string, body BulkContactsRequest) (*BulkContactsResponse, error) {
+	var result BulkContactsResponse
+	if err := c.doJSON(ctx, "POST", "/tags/"+url.PathEscape(name)+"/contacts", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// RemoveContactsFromTag calls DELETE /tags/:name/contacts: untag contacts
+func (c *Client) RemoveContactsFromTag(ctx context.Context, name string, body BulkContactsRequest) (*BulkContactsResponse, error) {
+	var result BulkContactsResponse
+	if err := c.doJSON(ctx, "DELETE", "/tags/"+url.PathEscape(name)+"/contacts", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListSnapshots calls GET /snapshots: list address book snapshots
+func (c *Client) ListSnapshots(ctx context.Context) (*SnapshotListResponse, error) {
+	var result SnapshotListResponse
+	if err := c.doJSON(ctx, "GET", "/snapshots", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CreateSnapshot calls POST /snapshots: take a snapshot of the address book
+func (c *Client) CreateSnapshot(ctx context.Context, body CreateSnapshotRequest) (*SnapshotResponse, error) {
+	var result SnapshotResponse
+	if err := c.doJSON(ctx, "POST", "/snapshots", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteSnapshot calls DELETE /snapshots/:id: delete a snapshot
+func (c *Client) DeleteSnapshot(ctx context.Context, id int) (*MessageResponse, error) {
+	var result MessageResponse
+	if err := c.doJSON(ctx, "DELETE", "/snapshots/"+strconv.Itoa(id), nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DiffSnapshot calls GET /snapshots/:id/diff: compare a snapshot with the current address book
+func (c *Client) DiffSnapshot(ctx context.Context, id int) (*SnapshotDiffResponse, error) {
+	var result SnapshotDiffResponse
+	if err := c.doJSON(ctx, "GET", "/snapshots/"+strconv.Itoa(id)+"/diff", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// RestoreSnapshot calls POST /snapshots/:id/restore: restore the address book from a snapshot
+func (c *Client) RestoreSnapshot(ctx context.Context, id int) (*RestoreSnapshotResponse, error) {
+	var result RestoreSnapshotResponse
+	if err := c.doJSON(ctx, "POST", "/snapshots/"+strconv.Itoa(id)+"/restore", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListWebhooks calls GET /webhooks: list webhooks
+func (c *Client) ListWebhooks(ctx context.Context) (*WebhookListResponse, error) {
+	var result WebhookListResponse
+	if err := c.doJSON(ctx, "GET", "/webhooks", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CreateWebhook calls POST /webhooks: register a webhook URL
+func (c *Client) CreateWebhook(ctx context.Context, body CreateWebhookRequest) (*WebhookResponse, error) {
+	var result WebhookResponse
+	if err := c.doJSON(ctx, "POST", "/webhooks", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteWebhook calls DELETE /webhooks/:id: delete a webhook
+func (c *Client) DeleteWebhook(ctx context.Context, id int) (*MessageResponse, error) {
+	var result MessageResponse
+	if err := c.doJSON(ctx, "DELETE", "/webhooks/"+strconv.Itoa(id), nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListWebhookDeliveries calls GET /webhooks/:id/deliveries: list the latest deliveries of a webhook with their status
+func (c *Client) ListWebhookDeliveries(ctx context.Context, id int) (*WebhookDeliveryListResponse, error) {
+	var result WebhookDeliveryListResponse
+	if err := c.doJSON(ctx, "GET", "/webhooks/"+strconv.Itoa(id)+"/deliveries", nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// TestWebhook calls POST /webhooks/test: send a sample signed event to a webhook
+func (c *Client) TestWebhook(ctx context.Context, body TestWebhookRequest) (*TestWebhookResponse, error) {
+	var result TestWebhookResponse
+	if err := c.doJSON(ctx, "POST", "/webhooks/test", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,1879 @@
+// Code generated by cmd/clientgen. DO NOT EDIT.
+
+export interface ErrorResponse {
+  error: string;
+}
+
+export interface RateLimitErrorResponse {
+  error: string;
+  limit: number;
+  remaining: number;
+  reset: number;
+  retry_after: number;
+}
+
+export interface CreateUserRequest {
+  user_name: string;
+  email: string;
+  password: string;
+}
+
+export interface CreateUserResponse {
+  message: string;
+  userID: number;
+}
+
+export interface LoginRequest {
+  email: string;
+  password: string;
+}
+
+export interface LoginResponse {
+  token: string;
+  refresh_token: string;
+  expires_in: number;
+  user_id: number;
+}
+
+export interface RefreshTokenRequest {
+  refresh_token: string;
+}
+
+export interface LogoutRequest {
+  refresh_token: string;
+}
+
+export interface MessageResponse {
+  message: string;
+}
+
+export interface ProfileResponse {
+  id: number;
+  user_name: string;
+  email: string;
+  is_admin: boolean;
+  created_at: string;
+  pending_email_change?: EmailChange;
+  username_change_available_at?: string;
+  username_history?: UsernameChange[];
+}
+
+export interface EmailChange {
+  new_email: string;
+  current_confirmed: boolean;
+  new_confirmed: boolean;
+  requested_at: string;
+  expires_at: string;
+}
+
+export interface UsernameChange {
+  old_user_name: string;
+  new_user_name: string;
+  changed_at: string;
+}
+
+export interface UpdateProfileRequest {
+  user_name?: string;
+  email?: string;
+  password?: string;
+}
+
+export interface DeleteAccountRequest {
+  password: string;
+}
+
+export interface DeactivateAccountRequest {
+  password: string;
+}
+
+export interface RequestReactivation {
+  email: string;
+}
+
+export interface RequestEmailChange {
+  new_email: string;
+  password: string;
+}
+
+export interface ConfirmEmailChangeResponse {
+  completed: boolean;
+  email?: string;
+  pending?: EmailChange;
+}
+
+export interface ChangeUsernameRequest {
+  user_name: string;
+}
+
+export interface APIUsageResponse {
+  from: string;
+  to: string;
+  total: APIUsageCounts;
+  days: APIUsageDay[];
+  endpoints: APIUsageEndpoint[];
+}
+
+export interface APIUsageCounts {
+  requests: number;
+  rate_limited: number;
+  bytes_in: number;
+  bytes_out: number;
+}
+
+export interface APIUsageDay {
+  date: string;
+  requests: number;
+  rate_limited: number;
+  bytes_in: number;
+  bytes_out: number;
+}
+
+export interface APIUsageEndpoint {
+  endpoint: string;
+  method: string;
+  path: string;
+  rate_limit_per_minute?: number;
+  requests: number;
+  rate_limited: number;
+  bytes_in: number;
+  bytes_out: number;
+}
+
+export interface UserLookupResponse {
+  id: number;
+  user_name: string;
+  redirected_from?: string;
+}
+
+export interface ChangePasswordRequest {
+  current_password: string;
+  new_password: string;
+}
+
+export interface AnnouncementListResponse {
+  items: Announcement[];
+}
+
+export interface Announcement {
+  id: number;
+  message: string;
+  level: string;
+  starts_at: string;
+  ends_at?: string;
+  created_at: string;
+}
+
+export interface PreferencesResponse {
+  weekly_digest: boolean;
+  region: string;
+  search_language: string;
+  duplicate_sensitivity: string;
+}
+
+export interface UpdatePreferencesRequest {
+  weekly_digest?: boolean;
+  region?: string;
+  search_language?: string;
+  duplicate_sensitivity?: string;
+}
+
+export interface NotificationPreferences {
+  events: Record<string, Record<string, boolean>>;
+}
+
+export interface UpdateNotificationPreferencesRequest {
+  events: Record<string, Record<string, boolean>>;
+}
+
+export interface AccountArchive {
+  format: string;
+  version: number;
+  exported_at: string;
+  contacts: ArchiveContact[];
+  groups: string[];
+  tags: string[];
+  preferences?: PreferencesResponse;
+}
+
+export interface ArchiveContact {
+  first_name: string;
+  last_name: string;
+  phone_number: string;
+  address?: string;
+  email?: string;
+  company?: string;
+  job_title?: string;
+  timezone?: string;
+  street?: string;
+  city?: string;
+  region?: string;
+  postal_code?: string;
+  country_code?: string;
+  latitude?: number;
+  longitude?: number;
+  source?: string;
+  stage?: string;
+  social_profiles?: SocialProfile[];
+  groups?: string[];
+  tags?: string[];
+}
+
+export interface SocialProfile {
+  network: string;
+  handle: string;
+  url: string;
+}
+
+export interface ImportAccountResponse {
+  contacts_imported: number;
+  contacts_skipped: number;
+  groups_created: number;
+  tags_created: number;
+  preferences_imported: boolean;
+  warnings?: string[];
+}
+
+export interface APIKeyListResponse {
+  items: APIKeyResponse[];
+}
+
+export interface APIKeyResponse {
+  id: number;
+  name: string;
+  key_id: string;
+  secret?: string;
+  created_at: string;
+}
+
+export interface CreateAPIKeyRequest {
+  name: string;
+}
+
+export interface EmbedTokenListResponse {
+  items: EmbedToken[];
+}
+
+export interface EmbedToken {
+  id: number;
+  name: string;
+  token?: string;
+  url?: string;
+  token_prefix: string;
+  group_id?: number;
+  q?: string;
+  tag?: string;
+  active: boolean;
+  expires_at?: string;
+  revoked_at?: string;
+  last_used_at?: string;
+  created_at: string;
+}
+
+export interface CreateEmbedTokenRequest {
+  name: string;
+  group_id?: number;
+  q?: string;
+  tag?: string;
+  expires_at?: string;
+}
+
+export interface EmbedContactListResponse {
+  name: string;
+  branding: Branding;
+  items: EmbedContact[];
+  total_count: number;
+  page: number;
+  page_size: number;
+  total_pages: number;
+}
+
+export interface Branding {
+  instance_name: string;
+  support_email?: string;
+  logo_url?: string;
+}
+
+export interface EmbedContact {
+  first_name: string;
+  last_name: string;
+  email?: string;
+  phone_number?: string;
+  company?: string;
+  job_title?: string;
+  social_profiles?: SocialProfile[];
+}
+
+export interface PaginationResult {
+  items: GetContactsResponse[];
+  total_count: number;
+  page: number;
+  page_size: number;
+  total_pages: number;
+  next_cursor?: string;
+}
+
+export interface GetContactsResponse {
+  id: number;
+  user_id: number;
+  first_name: string;
+  last_name: string;
+  phone_number: string;
+  address?: string;
+  email?: string;
+  company?: string;
+  job_title?: string;
+  source?: string;
+  stage?: string;
+  social_profiles?: SocialProfile[];
+  timezone?: string;
+  street?: string;
+  city?: string;
+  region?: string;
+  postal_code?: string;
+  country_code?: string;
+  formatted_address?: string[];
+  latitude?: number;
+  longitude?: number;
+  local_time?: string;
+  within_working_hours?: boolean;
+  local_time_estimated?: boolean;
+  deleted_at?: string;
+  phone_number_e164?: string;
+  phone_number_formatted?: string;
+  last_interacted_at?: string;
+  custom_fields?: Record<string, string>;
+  client_id?: string;
+}
+
+export interface CreateContactRequest {
+  first_name: string;
+  last_name: string;
+  phone_number: string;
+  address: string;
+  email?: string;
+  company?: string;
+  job_title?: string;
+  timezone?: string;
+  street?: string;
+  city?: string;
+  region?: string;
+  postal_code?: string;
+  country_code?: string;
+  latitude?: number;
+  longitude?: number;
+  source?: string;
+  stage?: string;
+  custom_fields?: Record<string, string>;
+  client_id?: string;
+}
+
+export interface CreateContactResponse {
+  message: string;
+  contact_id: number;
+}
+
+export interface UpdateContactRequest {
+  first_name?: string;
+  last_name?: string;
+  phone_number?: string;
+  address?: string;
+  email?: string;
+  company?: string;
+  job_title?: string;
+  timezone?: string;
+  street?: string;
+  city?: string;
+  region?: string;
+  postal_code?: string;
+  country_code?: string;
+  latitude?: number;
+  longitude?: number;
+  source?: string;
+  stage?: string;
+  custom_fields?: Record<string, string>;
+}
+
+export interface IncompleteContactsResponse {
+  items: IncompleteContact[];
+  total_count: number;
+  page: number;
+  page_size: number;
+  total_pages: number;
+}
+
+export interface IncompleteContact {
+  id: number;
+  user_id: number;
+  first_name: string;
+  last_name: string;
+  phone_number: string;
+  address?: string;
+  email?: string;
+  company?: string;
+  job_title?: string;
+  source?: string;
+  stage?: string;
+  social_profiles?: SocialProfile[];
+  timezone?: string;
+  street?: string;
+  city?: string;
+  region?: string;
+  postal_code?: string;
+  country_code?: string;
+  formatted_address?: string[];
+  latitude?: number;
+  longitude?: number;
+  local_time?: string;
+  within_working_hours?: boolean;
+  local_time_estimated?: boolean;
+  deleted_at?: string;
+  phone_number_e164?: string;
+  phone_number_formatted?: string;
+  last_interacted_at?: string;
+  custom_fields?: Record<string, string>;
+  client_id?: string;
+  completeness: number;
+  missing: string[];
+}
+
+export interface DuplicateContactsResponse {
+  sensitivity: string;
+  thresholds: DuplicateThresholds;
+  pairs: DuplicatePair[];
+  truncated: boolean;
+}
+
+export interface DuplicateThresholds {
+  score: number;
+  name_similarity: number;
+}
+
+export interface DuplicatePair {
+  contacts: GetContactsResponse[];
+  score: number;
+  signals: DuplicateSignal[];
+}
+
+export interface DuplicateSignal {
+  signal: string;
+  score: number;
+  weight: number;
+  contribution: number;
+  threshold: number;
+  matched: boolean;
+}
+
+export interface ContactCompleteness {
+  contact_id: number;
+  score: number;
+  filled: string[];
+  missing: string[];
+}
+
+export interface TrashListResponse {
+  items: GetContactsResponse[];
+}
+
+export interface TrashedContact {
+  id: number;
+  user_id: number;
+  first_name: string;
+  last_name: string;
+  phone_number: string;
+  address?: string;
+  email?: string;
+  company?: string;
+  job_title?: string;
+  source?: string;
+  stage?: string;
+  social_profiles?: SocialProfile[];
+  timezone?: string;
+  street?: string;
+  city?: string;
+  region?: string;
+  postal_code?: string;
+  country_code?: string;
+  formatted_address?: string[];
+  latitude?: number;
+  longitude?: number;
+  local_time?: string;
+  within_working_hours?: boolean;
+  local_time_estimated?: boolean;
+  deleted_at?: string;
+  phone_number_e164?: string;
+  phone_number_formatted?: string;
+  last_interacted_at?: string;
+  custom_fields?: Record<string, string>;
+  client_id?: string;
+  groups: Membership[];
+  tags: Membership[];
+  deleted_by?: number;
+  purge_at: string;
+}
+
+export interface Membership {
+  id: number;
+  name: string;
+}
+
+export interface ContactHistoryResponse {
+  contact_id: number;
+  items: ContactHistoryEntry[];
+}
+
+export interface ContactHistoryEntry {
+  id: number;
+  action: string;
+  changes: Record<string, ContactFieldChange>;
+  created_at: string;
+}
+
+export interface ContactFieldChange {
+  from: unknown;
+  to: unknown;
+}
+
+export interface ContactChangesResponse {
+  changes: ContactChange[];
+  cursor: number;
+  has_more: boolean;
+}
+
+export interface ContactChange {
+  id: number;
+  action: string;
+  entity_type: string;
+  entity_id: number;
+  created_at: string;
+}
+
+export interface SyncContact {
+  contact: GetContactsResponse;
+  version: number;
+}
+
+export interface SyncUpdateContactRequest {
+  base_version: number;
+  changes: UpdateContactRequest;
+}
+
+export interface SyncUpdateContactResponse {
+  contact: GetContactsResponse;
+  version: number;
+  applied: string[];
+  conflicts: SyncFieldConflict[];
+}
+
+export interface SyncFieldConflict {
+  field: string;
+  server_value: unknown;
+  client_value: unknown;
+}
+
+export interface SyncBatchRequest {
+  batch_id?: string;
+  mutations: SyncMutation[];
+}
+
+export interface SyncMutation {
+  op: string;
+  temp_id?: string;
+  contact_id?: number;
+  base_version?: number;
+  create?: CreateContactRequest;
+  update?: UpdateContactRequest;
+}
+
+export interface SyncBatchResponse {
+  committed: boolean;
+  replayed?: boolean;
+  id_map: Record<string, number>;
+  results: SyncMutationResult[];
+}
+
+export interface SyncMutationResult {
+  index: number;
+  op: string;
+  status: string;
+  temp_id?: string;
+  contact_id?: number;
+  version?: number;
+  applied?: string[];
+  conflicts?: SyncFieldConflict[];
+  error?: string;
+}
+
+export interface ContactCountResponse {
+  total: number;
+  unread_changes: number;
+  cursor: number;
+}
+
+export interface ContactStatsResponse {
+  total_count: number;
+  by_stage: Record<string, number>;
+  by_source: Record<string, number>;
+}
+
+export interface GeoJSONFeatureCollection {
+  type: string;
+  features: GeoJSONFeature[];
+  clustered: boolean;
+}
+
+export interface GeoJSONFeature {
+  type: string;
+  geometry: GeoJSONPoint;
+  properties: Record<string, unknown>;
+}
+
+export interface GeoJSONPoint {
+  type: string;
+  coordinates: [number, number];
+}
+
+export interface CardImportResponse {
+  draft: CreateContactRequest;
+  raw_text: string;
+}
+
+export interface ContactFileImportResponse {
+  format: string;
+  rows: number;
+  imported: number;
+  failed: number;
+  errors: ContactFileRowError[];
+  warnings?: string[];
+}
+
+export interface ContactFileRowError {
+  line: number;
+  name?: string;
+  error: string;
+}
+
+export interface BoardResponse {
+  field: string;
+  columns: BoardColumn[];
+}
+
+export interface BoardColumn {
+  value: string;
+  items: GetContactsResponse[];
+  total_count: number;
+  page: number;
+  page_size: number;
+  total_pages: number;
+}
+
+export interface MoveContactRequest {
+  stage: string;
+  position?: number;
+}
+
+export interface EnrichmentResponse {
+  id: number;
+  contact_id: number;
+  provider: string;
+  matched_on: string;
+  status: string;
+  company?: string;
+  job_title?: string;
+  social_profiles?: Record<string, string>;
+  fetched_at: string;
+  resolved_at?: string;
+}
+
+export interface EnrichmentListResponse {
+  items: EnrichmentResponse[];
+}
+
+export interface SocialProfileListResponse {
+  items: SocialProfile[];
+}
+
+export interface SetSocialProfileRequest {
+  value: string;
+}
+
+export interface InteractionListResponse {
+  items: Interaction[];
+}
+
+export interface Interaction {
+  id: number;
+  contact_id: number;
+  kind: string;
+  note?: string;
+  occurred_at: string;
+  created_at: string;
+}
+
+export interface LogInteractionRequest {
+  kind: string;
+  note?: string;
+  occurred_at?: string;
+}
+
+export interface TagContactRequest {
+  name: string;
+}
+
+export interface ContactTagsResponse {
+  contact_id: number;
+  tags: Membership[];
+}
+
+export interface AttachmentListResponse {
+  items: AttachmentResponse[];
+  storage: StorageUsage;
+}
+
+export interface AttachmentResponse {
+  id: number;
+  contact_id: number;
+  file_name: string;
+  content_type: string;
+  size_bytes: number;
+  created_at: string;
+}
+
+export interface StorageUsage {
+  used_bytes: number;
+  quota_bytes: number;
+}
+
+export interface AttachmentURLResponse {
+  url: string;
+  expires_at: string;
+}
+
+export interface SharedContact {
+  contact: GetContactsResponse;
+  status: string;
+  submitted_by: number;
+  submitted_at: string;
+  reviewed_at?: string;
+  review_note?: string;
+  changed_at?: string;
+  updated_since_viewed?: boolean;
+}
+
+export interface SharedContactListResponse {
+  items: SharedContact[];
+}
+
+export interface ReviewSharedContactRequest {
+  note?: string;
+}
+
+export interface DirectoryResponse {
+  title?: string;
+  branding: Branding;
+  fields: string[];
+  items: DirectoryEntry[];
+  total_count: number;
+  page: number;
+  page_size: number;
+  total_pages: number;
+}
+
+export interface DirectoryEntry {
+  first_name: string;
+  last_name: string;
+  email?: string;
+  phone_number?: string;
+  company?: string;
+  job_title?: string;
+  city?: string;
+  region?: string;
+  country_code?: string;
+  social_profiles?: SocialProfile[];
+}
+
+export interface SetDirectoryCardRequest {
+  contact_id?: number;
+}
+
+export interface DirectorySettings {
+  enabled: boolean;
+  slug: string;
+  title?: string;
+  source: string;
+  fields: string[];
+}
+
+export interface PicklistResponse {
+  field: string;
+  values: string[];
+}
+
+export interface CreatePicklistValueRequest {
+  value: string;
+  position?: number;
+}
+
+export interface ContactTemplate {
+  version: number;
+  fields: CustomField[];
+  default_tags: string[];
+}
+
+export interface CustomField {
+  key: string;
+  label: string;
+  type: string;
+  options?: string[];
+  required: boolean;
+  default?: string;
+  position: number;
+}
+
+export interface SaveCustomFieldRequest {
+  label: string;
+  type: string;
+  options?: string[];
+  required?: boolean;
+  default?: string;
+  position?: number;
+}
+
+export interface SetTemplateTagsRequest {
+  tags: string[];
+}
+
+export interface ContactTemplateMigration {
+  version: number;
+  outdated_contacts: number;
+  missing_required: number;
+}
+
+export interface AdminStatsResponse {
+  users: number;
+  contacts: number;
+  daily_active_users: number;
+  imports_last_24h: number;
+  exports_last_24h: number;
+  cache: CacheStats;
+  jobs: JobStats;
+  generated_at: string;
+}
+
+export interface CacheStats {
+  hits: number;
+  misses: number;
+  hit_rate?: number;
+}
+
+export interface JobStats {
+  digests_due: number;
+  analytics_buffered: number;
+}
+
+export interface AdminAPIUsageResponse {
+  from: string;
+  to: string;
+  total: APIUsageCounts;
+  days: APIUsageDay[];
+  endpoints: APIUsageEndpoint[];
+  top_users: APIUserUsage[];
+}
+
+export interface APIUserUsage {
+  user_id: number;
+  username: string;
+  requests: number;
+  rate_limited: number;
+  bytes_in: number;
+  bytes_out: number;
+}
+
+export interface DuplicateUsersResponse {
+  duplicates: DuplicateUsers[];
+}
+
+export interface DuplicateUsers {
+  email: string;
+  users: UserSummary[];
+}
+
+export interface UserSummary {
+  id: number;
+  user_name: string;
+  email: string;
+  is_admin: boolean;
+  created_at: string;
+}
+
+export interface MergeUsersRequest {
+  source_user_id: number;
+  target_user_id: number;
+}
+
+export interface MergeUsersResponse {
+  source_user_id: number;
+  target_user_id: number;
+  contacts: number;
+  groups: number;
+  tags: number;
+  attachments: number;
+  snapshots: number;
+  webhooks: number;
+  api_keys: number;
+}
+
+export interface StartMaintenanceJobRequest {
+  operation: string;
+  top?: number;
+}
+
+export interface MaintenanceJob {
+  id: string;
+  operation: string;
+  status: string;
+  started_at: string;
+  finished_at?: string;
+  result?: MaintenanceResult;
+  error?: string;
+}
+
+export interface MaintenanceResult {
+  operation: string;
+  scanned: number;
+  updated: number;
+  users: number;
+}
+
+export interface MaintenanceJobListResponse {
+  items: MaintenanceJob[];
+}
+
+export interface CreateAnnouncementRequest {
+  message: string;
+  level: string;
+  starts_at?: string;
+  ends_at?: string;
+}
+
+export interface AnalyticsSettings {
+  enabled: boolean;
+  configured: boolean;
+}
+
+export interface UpdateAnalyticsSettingsRequest {
+  enabled?: boolean;
+}
+
+export interface AlertSettings {
+  enabled: boolean;
+  threshold: number;
+  min_requests: number;
+  window: string;
+  default_suppression: string;
+  routes: RouteAlert[];
+}
+
+export interface RouteAlert {
+  route: string;
+  suppression?: string;
+  requests: number;
+  errors: number;
+  suppressed_until?: string;
+}
+
+export interface SetRouteSuppressionRequest {
+  route: string;
+  suppression: string;
+}
+
+export interface GroupListResponse {
+  items: GroupResponse[];
+}
+
+export interface GroupResponse {
+  id: number;
+  name: string;
+  contact_count: number;
+  created_at: string;
+}
+
+export interface CreateGroupRequest {
+  name: string;
+}
+
+export interface BulkContactsRequest {
+  contact_ids: number[];
+}
+
+export interface BulkContactsResponse {
+  requested: number;
+  changed: number;
+}
+
+export interface TagListResponse {
+  items: TagResponse[];
+}
+
+export interface TagResponse {
+  id: number;
+  name: string;
+  contact_count: number;
+  created_at: string;
+}
+
+export interface CreateTagRequest {
+  name: string;
+}
+
+export interface SnapshotListResponse {
+  items: SnapshotResponse[];
+}
+
+export interface SnapshotResponse {
+  id: number;
+  name: string;
+  contact_count: number;
+  created_at: string;
+}
+
+export interface CreateSnapshotRequest {
+  name: string;
+}
+
+export interface SnapshotDiffResponse {
+  snapshot_id: number;
+  added: SnapshotContact[];
+  removed: SnapshotContact[];
+  changed: SnapshotContactChange[];
+}
+
+export interface SnapshotContact {
+  id: number;
+  first_name: string;
+  last_name: string;
+}
+
+export interface SnapshotContactChange {
+  id: number;
+  first_name: string;
+  last_name: string;
+  changes: SnapshotFieldChange[];
+}
+
+export interface SnapshotFieldChange {
+  field: string;
+  snapshot: string;
+  current: string;
+}
+
+export interface RestoreSnapshotResponse {
+  snapshot_id: number;
+  recreated: number;
+  updated: number;
+  deleted: number;
+}
+
+export interface WebhookListResponse {
+  items: WebhookResponse[];
+}
+
+export interface WebhookResponse {
+  id: number;
+  url: string;
+  secret?: string;
+  created_at: string;
+}
+
+export interface CreateWebhookRequest {
+  url: string;
+}
+
+export interface WebhookDeliveryListResponse {
+  items: WebhookDelivery[];
+}
+
+export interface WebhookDelivery {
+  id: number;
+  event_id: string;
+  event_type: string;
+  status: string;
+  attempts: number;
+  status_code?: number;
+  error?: string;
+  next_attempt_at?: string;
+  created_at: string;
+  delivered_at?: string;
+}
+
+export interface TestWebhookRequest {
+  webhook_id: number;
+}
+
+export interface TestWebhookResponse {
+  webhook_id: number;
+  event_id: string;
+  delivered: boolean;
+  status_code?: number;
+  duration_ms: number;
+  error?: string;
+}
+
+/** Thrown for every response outside the 2xx range, message is the error reported by the API */
+export class ApiError extends Error {
+  constructor(
+    readonly status: number,
+    message: string,
+    /** Seconds to wait before retrying, set when the API asks for it (rate limited requests) */
+    readonly retryAfter?: number,
+  ) {
+    super(message);
+    this.name = "ApiError";
+  }
+
+  get isNotFound(): boolean {
+    return this.status === 404;
+  }
+
+  get isUnauthorized(): boolean {
+    return this.status === 401;
+  }
+
+  get isForbidden(): boolean {
+    return this.status === 403;
+  }
+
+  get isConflict(): boolean {
+    return this.status === 409;
+  }
+
+  get isRateLimited(): boolean {
+    return this.status === 429;
+  }
+}
+
+export type Query = Record<string, string | number | boolean | undefined>;
+
+interface RequestOptions {
+  query?: Query;
+  body?: unknown;
+  form?: FormData;
+}
+
+/** Calls the contact API, set token (from login) or apiKey ("<key_id>:<secret>") before calling authenticated endpoints */
+export class ContactClient {
+  constructor(
+    private readonly baseUrl: string,
+    public token?: string,
+    public apiKey?: string,
+    private readonly fetchFn: typeof fetch = (input, init) => fetch(input, init),
+  ) {
+    this.baseUrl = baseUrl.replace(/\/$/, "");
+  }
+
+  private async send(method: string, path: string, options: RequestOptions = {}): Promise<Response> {
+    const params = new URLSearchParams();
+    for (const [key, value] of Object.entries(options.query ?? {})) {
+      if (value !== undefined) {
+        params.set(key, String(value));
+      }
+    }
+    const search = params.toString();
+
+    const headers: Record<string, string> = {};
+    if (this.token) {
+      headers["Authorization"] = "Bearer " + this.token;
+    } else if (this.apiKey) {
+      headers["Authorization"] = "ApiKey " + this.apiKey;
+    }
+    let body: BodyInit | undefined = options.form;
+    if (options.body !== undefined) {
+      headers["Content-Type"] = "application/json";
+      body = JSON.stringify(options.body);
+    }
+
+    const response = await this.fetchFn(this.baseUrl + path + (search ? "?" + search : ""), { method, headers, body });
+    if (!response.ok) {
+      let message = response.statusText;
+      try {
+        const error = (await response.json()) as ErrorResponse;
+        if (error.error) {
+          message = error.error;
+        }
+      } catch {
+        // the error body is not JSON, keep the status text
+      }
+      const retryAfter = Number(response.headers.get("Retry-After"));
+      throw new ApiError(response.status, message, retryAfter > 0 ? retryAfter : undefined);
+    }
+    return response;
+  }
+
+  private async request<T>(method: string, path: string, options?: RequestOptions): Promise<T> {
+    const response = await this.send(method, path, options);
+    return (await response.json()) as T;
+  }
+
+  /** Register a user (POST /users) */
+  async createUser(body: CreateUserRequest): Promise<CreateUserResponse> {
+    return this.request<CreateUserResponse>("POST", `/users`, { body });
+  }
+
+  /** Log in and get an access token and a refresh token (POST /login) */
+  async login(body: LoginRequest): Promise<LoginResponse> {
+    return this.request<LoginResponse>("POST", `/login`, { body });
+  }
+
+  /** Exchange a refresh token for new tokens (POST /token/refresh) */
+  async refreshToken(body: RefreshTokenRequest): Promise<LoginResponse> {
+    return this.request<LoginResponse>("POST", `/token/refresh`, { body });
+  }
+
+  /** Revoke the access token and the refresh token of the session (POST /logout) */
+  async logout(body: LogoutRequest): Promise<MessageResponse> {
+    return this.request<MessageResponse>("POST", `/logout`, { body });
+  }
+
+  /** Get the account of the current user (GET /users/me) */
+  async getProfile(): Promise<ProfileResponse> {
+    return this.request<ProfileResponse>("GET", `/users/me`);
+  }
+
+  /** Change the username and the email of the current user (PATCH /users/me) */
+  async updateProfile(body: UpdateProfileRequest): Promise<ProfileResponse> {
+    return this.request<ProfileResponse>("PATCH", `/users/me`, { body });
+  }
+
+  /** Delete the current user with its contacts and everything else it owns (DELETE /users/me) */
+  async deleteAccount(body: DeleteAccountRequest): Promise<MessageResponse> {
+    return this.request<MessageResponse>("DELETE", `/users/me`, { body });
+  }
+
+  /** Freeze the current user, keeping its data, until reactivated from a link sent by email (POST /users/me/deactivate) */
+  async deactivateAccount(body: DeactivateAccountRequest): Promise<MessageResponse> {
+    return this.request<MessageResponse>("POST", `/users/me/deactivate`, { body });
+  }
+
+  /** Email a reactivation link to a deactivated account (POST /users/reactivate) */
+  async requestReactivation(body: RequestReactivation): Promise<MessageResponse> {
+    return this.request<MessageResponse>("POST", `/users/reactivate`, { body });
+  }
+
+  /** Reactivate an account from a link sent by email (GET /users/reactivate/confirm) */
+  async confirmReactivation(query?: Query): Promise<MessageResponse> {
+    return this.request<MessageResponse>("GET", `/users/reactivate/confirm`, { query });
+  }
+
+  /** Change the email once confirmed from the current and the new address (POST /users/me/email) */
+  async requestEmailChange(body: RequestEmailChange): Promise<EmailChange> {
+    return this.request<EmailChange>("POST", `/users/me/email`, { body });
+  }
+
+  /** Cancel the pending email change (DELETE /users/me/email) */
+  async cancelEmailChange(): Promise<MessageResponse> {
+    return this.request<MessageResponse>("DELETE", `/users/me/email`);
+  }
+
+  /** Confirm an email change from a link sent by email (GET /users/email/confirm) */
+  async confirmEmailChange(query?: Query): Promise<ConfirmEmailChangeResponse> {
+    return this.request<ConfirmEmailChangeResponse>("GET", `/users/email/confirm`, { query });
+  }
+
+  /** Change the username, at most once every 30 days (PUT /users/me/username) */
+  async changeUsername(body: ChangeUsernameRequest): Promise<ProfileResponse> {
+    return this.request<ProfileResponse>("PUT", `/users/me/username`, { body });
+  }
+
+  /** Get the API requests of the current user by day and endpoint (GET /users/me/usage) */
+  async getAPIUsage(query?: Query): Promise<APIUsageResponse> {
+    return this.request<APIUsageResponse>("GET", `/users/me/usage`, { query });
+  }
+
+  /** Find the account of a username, following former usernames (GET /users/by-username/:username) */
+  async lookupUsername(username: string): Promise<UserLookupResponse> {
+    return this.request<UserLookupResponse>("GET", `/users/by-username/${encodeURIComponent(username)}`);
+  }
+
+  /** Change the password and revoke every other session (PUT /users/me/password) */
+  async changePassword(body: ChangePasswordRequest): Promise<LoginResponse> {
+    return this.request<LoginResponse>("PUT", `/users/me/password`, { body });
+  }
+
+  /** List the announcements the current user did not dismiss (GET /announcements) */
+  async getAnnouncements(): Promise<AnnouncementListResponse> {
+    return this.request<AnnouncementListResponse>("GET", `/announcements`);
+  }
+
+  /** Hide an announcement from the current user (POST /announcements/:id/dismiss) */
+  async dismissAnnouncement(id: number): Promise<MessageResponse> {
+    return this.request<MessageResponse>("POST", `/announcements/${encodeURIComponent(id)}/dismiss`);
+  }
+
+  /** Get the preferences of the current user (GET /users/me/preferences) */
+  async getPreferences(): Promise<PreferencesResponse> {
+    return this.request<PreferencesResponse>("GET", `/users/me/preferences`);
+  }
+
+  /** Update the preferences of the current user (PATCH /users/me/preferences) */
+  async updatePreferences(body: UpdatePreferencesRequest): Promise<PreferencesResponse> {
+    return this.request<PreferencesResponse>("PATCH", `/users/me/preferences`, { body });
+  }
+
+  /** Get the notification preferences matrix of the current user (GET /users/me/notifications) */
+  async getNotificationPreferences(): Promise<NotificationPreferences> {
+    return this.request<NotificationPreferences>("GET", `/users/me/notifications`);
+  }
+
+  /** Turn notifications of the current user on or off by event and channel (PATCH /users/me/notifications) */
+  async updateNotificationPreferences(body: UpdateNotificationPreferencesRequest): Promise<NotificationPreferences> {
+    return this.request<NotificationPreferences>("PATCH", `/users/me/notifications`, { body });
+  }
+
+  /** Download the whole account as a versioned archive (GET /users/me/export) */
+  async exportAccount(): Promise<AccountArchive> {
+    return this.request<AccountArchive>("GET", `/users/me/export`);
+  }
+
+  /** Add the contacts, groups, tags and preferences of an account archive (POST /users/me/import) */
+  async importAccount(body: AccountArchive): Promise<ImportAccountResponse> {
+    return this.request<ImportAccountResponse>("POST", `/users/me/import`, { body });
+  }
+
+  /** List API keys (GET /api-keys) */
+  async listAPIKeys(): Promise<APIKeyListResponse> {
+    return this.request<APIKeyListResponse>("GET", `/api-keys`);
+  }
+
+  /** Create an API key (POST /api-keys) */
+  async createAPIKey(body: CreateAPIKeyRequest): Promise<APIKeyResponse> {
+    return this.request<APIKeyResponse>("POST", `/api-keys`, { body });
+  }
+
+  /** Revoke an API key (DELETE /api-keys/:id) */
+  async deleteAPIKey(id: number): Promise<MessageResponse> {
+    return this.request<MessageResponse>("DELETE", `/api-keys/${encodeURIComponent(id)}`);
+  }
+
+  /** List the read-only tokens embedding contact lists (GET /embed-tokens) */
+  async listEmbedTokens(): Promise<EmbedTokenListResponse> {
+    return this.request<EmbedTokenListResponse>("GET", `/embed-tokens`);
+  }
+
+  /** Create a read-only token embedding a group or a saved search in another page (POST /embed-tokens) */
+  async createEmbedToken(body: CreateEmbedTokenRequest): Promise<EmbedToken> {
+    return this.request<EmbedToken>("POST", `/embed-tokens`, { body });
+  }
+
+  /** Revoke an embed token (DELETE /embed-tokens/:id) */
+  async revokeEmbedToken(id: number): Promise<MessageResponse> {
+    return this.request<MessageResponse>("DELETE", `/embed-tokens/${encodeURIComponent(id)}`);
+  }
+
+  /** List the contacts of an embed token, without logging in (GET /embed/contacts) */
+  async getEmbeddedContacts(query?: Query): Promise<EmbedContactListResponse> {
+    return this.request<EmbedContactListResponse>("GET", `/embed/contacts`, { query });
+  }
+
+  /** List contacts, filtered and paginated (GET /contacts) */
+  async getContacts(query?: Query): Promise<PaginationResult> {
+    return this.request<PaginationResult>("GET", `/contacts`, { query });
+  }
+
+  /** Iterates over every item of every page of getContacts */
+  async *getContactsAll(query?: Query): AsyncGenerator<GetContactsResponse> {
+    let cursor: string | undefined;
+    for (;;) {
+      const result = await this.getContacts({ ...query, page: undefined, cursor });
+      yield* result.items ?? [];
+      if (!result.next_cursor) {
+        return;
+      }
+      cursor = result.next_cursor;
+    }
+  }
+
+  /** Get a contact (GET /contacts/:id) */
+  async getContact(id: number): Promise<GetContactsResponse> {
+    return this.request<GetContactsResponse>("GET", `/contacts/${encodeURIComponent(id)}`);
+  }
+
+  /** Create a contact (POST /contacts) */
+  async createContact(body: CreateContactRequest): Promise<CreateContactResponse> {
+    return this.request<CreateContactResponse>("POST", `/contacts`, { body });
+  }
+
+  /** Update a contact (PATCH /contacts/:id) */
+  async updateContact(id: number, body: UpdateContactRequest): Promise<MessageResponse> {
+    return this.request<MessageResponse>("PATCH", `/contacts/${encodeURIComponent(id)}`, { body });
+  }
+
+  /** Delete a contact (DELETE /contacts/:id) */
+  async deleteContact(id: number, query?: Query): Promise<MessageResponse> {
+    return this.request<MessageResponse>("DELETE", `/contacts/${encodeURIComponent(id)}`, { query });
+  }
+
+  /** List the contacts missing data, least complete first (GET /contacts/incomplete) */
+  async getIncompleteContacts(query?: Query): Promise<IncompleteContactsResponse> {
+    return this.request<IncompleteContactsResponse>("GET", `/contacts/incomplete`, { query });
+  }
+
+  /** List the pairs of contacts likely to be the same person, with the score of each signal (GET /contacts/duplicates) */
+  async getDuplicateContacts(query?: Query): Promise<DuplicateContactsResponse> {
+    return this.request<DuplicateContactsResponse>("GET", `/contacts/duplicates`, { query });
+  }
+
+  /** Score how filled in a contact is (GET /contacts/:id/completeness) */
+  async getContactCompleteness(id: number): Promise<ContactCompleteness> {
+    return this.request<ContactCompleteness>("GET", `/contacts/${encodeURIComponent(id)}/completeness`);
+  }
+
+  /** List the deleted contacts of the trash (GET /contacts/trash) */
+  async listTrash(): Promise<TrashListResponse> {
+    return this.request<TrashListResponse>("GET", `/contacts/trash`);
+  }
+
+  /** Get a deleted contact of the trash (GET /contacts/trash/:id) */
+  async getTrashedContact(id: number): Promise<TrashedContact> {
+    return this.request<TrashedContact>("GET", `/contacts/trash/${encodeURIComponent(id)}`);
+  }
+
+  /** Restore a contact from the trash (POST /contacts/:id/restore) */
+  async restoreContact(id: number): Promise<GetContactsResponse> {
+    return this.request<GetContactsResponse>("POST", `/contacts/${encodeURIComponent(id)}/restore`);
+  }
+
+  /** List the changes of a contact (GET /contacts/:id/history) */
+  async getContactHistory(id: number): Promise<ContactHistoryResponse> {
+    return this.request<ContactHistoryResponse>("GET", `/contacts/${encodeURIComponent(id)}/history`);
+  }
+
+  /** Wait for changes to the contacts after a cursor (long polling) (GET /contacts/changes) */
+  async getContactChanges(query?: Query): Promise<ContactChangesResponse> {
+    return this.request<ContactChangesResponse>("GET", `/contacts/changes`, { query });
+  }
+
+  /** Get a contact with its version for a sync client (GET /sync/contacts/:id) */
+  async getSyncContact(id: number): Promise<SyncContact> {
+    return this.request<SyncContact>("GET", `/sync/contacts/${encodeURIComponent(id)}`);
+  }
+
+  /** Merge the changes a sync client made to a contact at a base version, field by field (PATCH /sync/contacts/:id) */
+  async syncUpdateContact(id: number, body: SyncUpdateContactRequest): Promise<SyncUpdateContactResponse> {
+    return this.request<SyncUpdateContactResponse>("PATCH", `/sync/contacts/${encodeURIComponent(id)}`, { body });
+  }
+
+  /** Apply the queued mutations of an offline client in order, all or none (POST /sync/batch) */
+  async applySyncBatch(body: SyncBatchRequest): Promise<SyncBatchResponse> {
+    return this.request<SyncBatchResponse>("POST", `/sync/batch`, { body });
+  }
+
+  /** Count contacts and unread changes, for polling (GET /contacts/count) */
+  async getContactCount(query?: Query): Promise<ContactCountResponse> {
+    return this.request<ContactCountResponse>("GET", `/contacts/count`, { query });
+  }
+
+  /** Count contacts by stage and source (GET /contacts/stats) */
+  async getContactStats(): Promise<ContactStatsResponse> {
+    return this.request<ContactStatsResponse>("GET", `/contacts/stats`);
+  }
+
+  /** Get contacts as GeoJSON for the map view (GET /contacts/geojson) */
+  async getContactsGeoJSON(query?: Query): Promise<GeoJSONFeatureCollection> {
+    return this.request<GeoJSONFeatureCollection>("GET", `/contacts/geojson`, { query });
+  }
+
+  /** Extract a draft contact from a business card photo (POST /contacts/import/card-image) */
+  async importCardImage(file: Blob, fileName?: string): Promise<CardImportResponse> {
+    const form = new FormData();
+    form.append("image", file, fileName);
+    return this.request<CardImportResponse>("POST", `/contacts/import/card-image`, { form });
+  }
+
+  /** Add the contacts of a CSV or vCard file, reporting the rows not imported (POST /contacts/import) */
+  async importContactFile(file: Blob, fileName?: string): Promise<ContactFileImportResponse> {
+    const form = new FormData();
+    form.append("file", file, fileName);
+    return this.request<ContactFileImportResponse>("POST", `/contacts/import`, { form });
+  }
+
+  /** Download every contact as a CSV or vCard file (GET /contacts/export) */
+  async exportContactFile(query?: Query): Promise<Response> {
+    return this.send("GET", `/contacts/export`, { query });
+  }
+
+  /** Get the kanban board (GET /contacts/board) */
+  async getBoard(query?: Query): Promise<BoardResponse> {
+    return this.request<BoardResponse>("GET", `/contacts/board`, { query });
+  }
+
+  /** Move a contact to a board column (POST /contacts/:id/move) */
+  async moveContact(id: number, body: MoveContactRequest): Promise<MessageResponse> {
+    return this.request<MessageResponse>("POST", `/contacts/${encodeURIComponent(id)}/move`, { body });
+  }
+
+  /** Fetch enrichment suggestions for a contact (POST /contacts/:id/enrich) */
+  async enrichContact(id: number): Promise<EnrichmentResponse> {
+    return this.request<EnrichmentResponse>("POST", `/contacts/${encodeURIComponent(id)}/enrich`);
+  }
+
+  /** List the enrichment suggestions of a contact (GET /contacts/:id/enrichments) */
+  async listEnrichments(id: number): Promise<EnrichmentListResponse> {
+    return this.request<EnrichmentListResponse>("GET", `/contacts/${encodeURIComponent(id)}/enrichments`);
+  }
+
+  /** Apply an enrichment suggestion (POST /contacts/:id/enrichments/:enrichmentId/accept) */
+  async acceptEnrichment(id: number, enrichmentId: number): Promise<MessageResponse> {
+    return this.request<MessageResponse>("POST", `/contacts/${encodeURIComponent(id)}/enrichments/${encodeURIComponent(enrichmentId)}/accept`);
+  }
+
+  /** Discard an enrichment suggestion (POST /contacts/:id/enrichments/:enrichmentId/reject) */
+  async rejectEnrichment(id: number, enrichmentId: number): Promise<MessageResponse> {
+    return this.request<MessageResponse>("POST", `/contacts/${encodeURIComponent(id)}/enrichments/${encodeURIComponent(enrichmentId)}/reject`);
+  }
+
+  /** List the social profiles of a contact (GET /contacts/:id/social) */
+  async getSocialProfiles(id: number): Promise<SocialProfileListResponse> {
+    return this.request<SocialProfileListResponse>("GET", `/contacts/${encodeURIComponent(id)}/social`);
+  }
+
+  /** Set the profile of a contact on a social network (PUT /contacts/:id/social/:network) */
+  async setSocialProfile(id: number, network: string, body: SetSocialProfileRequest): Promise<SocialProfile> {
+    return this.request<SocialProfile>("PUT", `/contacts/${encodeURIComponent(id)}/social/${encodeURIComponent(network)}`, { body });
+  }
+
+  /** Remove the profile of a contact on a social network (DELETE /contacts/:id/social/:network) */
+  async deleteSocialProfile(id: number, network: string): Promise<MessageResponse> {
+    return this.request<MessageResponse>("DELETE", `/contacts/${encodeURIComponent(id)}/social/${encodeURIComponent(network)}`);
+  }
+
+  /** List the interactions logged with a contact (GET /contacts/:id/interactions) */
+  async getInteractions(id: number): Promise<InteractionListResponse> {
+    return this.request<InteractionListResponse>("GET", `/contacts/${encodeURIComponent(id)}/interactions`);
+  }
+
+  /** Log a call, meeting or message with a contact (POST /contacts/:id/interactions) */
+  async logInteraction(id: number, body: LogInteractionRequest): Promise<Interaction> {
+    return this.request<Interaction>("POST", `/contacts/${encodeURIComponent(id)}/interactions`, { body });
+  }
+
+  /** Attach a tag to a contact (POST /contacts/:id/tags) */
+  async tagContact(id: number, body: TagContactRequest): Promise<ContactTagsResponse> {
+    return this.request<ContactTagsResponse>("POST", `/contacts/${encodeURIComponent(id)}/tags`, { body });
+  }
+
+  /** Detach a tag from a contact (DELETE /contacts/:id/tags/:name) */
+  async untagContact(id: number, name: string): Promise<ContactTagsResponse> {
+    return this.request<ContactTagsResponse>("DELETE", `/contacts/${encodeURIComponent(id)}/tags/${encodeURIComponent(name)}`);
+  }
+
+  /** List the attachments of a contact (GET /contacts/:id/attachments) */
+  async listAttachments(id: number): Promise<AttachmentListResponse> {
+    return this.request<AttachmentListResponse>("GET", `/contacts/${encodeURIComponent(id)}/attachments`);
+  }
+
+  /** Attach a file to a contact (POST /contacts/:id/attachments) */
+  async uploadAttachment(id: number, file: Blob, fileName?: string): Promise<AttachmentResponse> {
+    const form = new FormData();
+    form.append("file", file, fileName);
+    return this.request<AttachmentResponse>("POST", `/contacts/${encodeURIComponent(id)}/attachments`, { form });
+  }
+
+  /** Get a signed download link for an attachment (GET /contacts/:id/attachments/:attachmentId/url) */
+  async getAttachmentURL(id: number, attachmentId: number): Promise<AttachmentURLResponse> {
+    return this.request<AttachmentURLResponse>("GET", `/contacts/${encodeURIComponent(id)}/attachments/${encodeURIComponent(attachmentId)}/url`);
+  }
+
+  /** Delete an attachment (DELETE /contacts/:id/attachments/:attachmentId) */
+  async deleteAttachment(id: number, attachmentId: number): Promise<MessageResponse> {
+    return this.request<MessageResponse>("DELETE", `/contacts/${encodeURIComponent(id)}/attachments/${encodeURIComponent(attachmentId)}`);
+  }
+
+  /** Download an attachment from a signed link (GET /attachments/:attachmentId/download) */
+  async downloadAttachment(attachmentId: number, query?: Query): Promise<Response> {
+    return this.send("GET", `/attachments/${encodeURIComponent(attachmentId)}/download`, { query });
+  }
+
+  /** Submit a contact to the address book shared with every user (POST /contacts/:id/share) */
+  async shareContact(id: number): Promise<SharedContact> {
+    return this.request<SharedContact>("POST", `/contacts/${encodeURIComponent(id)}/share`);
+  }
+
+  /** Take a contact out of the shared address book (DELETE /contacts/:id/share) */
+  async unshareContact(id: number): Promise<MessageResponse> {
+    return this.request<MessageResponse>("DELETE", `/contacts/${encodeURIComponent(id)}/share`);
+  }
+
+  /** List the contacts shared with every user (GET /shared-contacts) */
+  async listSharedContacts(): Promise<SharedContactListResponse> {
+    return this.request<SharedContactListResponse>("GET", `/shared-contacts`);
+  }
+
+  /** List the contacts the current user submitted with their review (GET /shared-contacts/submissions) */
+  async listSharedSubmissions(): Promise<SharedContactListResponse> {
+    return this.request<SharedContactListResponse>("GET", `/shared-contacts/submissions`);
+  }
+
+  /** List the submissions waiting for review, admins and editors only (GET /shared-contacts/pending) */
+  async listPendingSharedContacts(): Promise<SharedContactListResponse> {
+    return this.request<SharedContactListResponse>("GET", `/shared-contacts/pending`);
+  }
+
+  /** Get a contact of the shared address book and mark it viewed (GET /shared-contacts/:id) */
+  async getSharedContact(id: number): Promise<SharedContact> {
+    return this.request<SharedContact>("GET", `/shared-contacts/${encodeURIComponent(id)}`);
+  }
+
+  /** Approve a submission to the shared address book, admins and editors only (POST /shared-contacts/:id/approve) */
+  async approveSharedContact(id: number, body: ReviewSharedContactRequest): Promise<SharedContact> {
+    return this.request<SharedContact>("POST", `/shared-contacts/${encodeURIComponent(id)}/approve`, { body });
+  }
+
+  /** Reject a submission to the shared address book, admins and editors only (POST /shared-contacts/:id/reject) */
+  async rejectSharedContact(id: number, body: ReviewSharedContactRequest): Promise<SharedContact> {
+    return this.request<SharedContact>("POST", `/shared-contacts/${encodeURIComponent(id)}/reject`, { body });
+  }
+
+  /** Search the public team directory (GET /directory/:slug) */
+  async getDirectory(slug: string, query?: Query): Promise<DirectoryResponse> {
+    return this.request<DirectoryResponse>("GET", `/directory/${encodeURIComponent(slug)}`, { query });
+  }
+
+  /** Get the name, support email and logo of the deployment for its public pages (GET /branding) */
+  async getBranding(): Promise<Branding> {
+    return this.request<Branding>("GET", `/branding`);
+  }
+
+  /** Pick the contact standing for the current user in the team directory (PUT /users/me/directory-card) */
+  async setDirectoryCard(body: SetDirectoryCardRequest): Promise<MessageResponse> {
+    return this.request<MessageResponse>("PUT", `/users/me/directory-card`, { body });
+  }
+
+  /** Get the settings of the team directory (GET /admin/directory) */
+  async getDirectorySettings(): Promise<DirectorySettings> {
+    return this.request<DirectorySettings>("GET", `/admin/directory`);
+  }
+
+  /** Enable the team directory and choose its path, source and visible fields (PUT /admin/directory) */
+  async saveDirectorySettings(body: DirectorySettings): Promise<DirectorySettings> {
+    return this.request<DirectorySettings>("PUT", `/admin/directory`, { body });
+  }
+
+  /** List the allowed values of a picklist field (GET /picklists/:field) */
+  async getPicklist(field: string): Promise<PicklistResponse> {
+    return this.request<PicklistResponse>("GET", `/picklists/${encodeURIComponent(field)}`);
+  }
+
+  /** Add an allowed value to a picklist field (POST /admin/picklists/:field) */
+  async addPicklistValue(field: string, body: CreatePicklistValueRequest): Promise<MessageResponse> {
+    return this.request<MessageResponse>("POST", `/admin/picklists/${encodeURIComponent(field)}`, { body });
+  }
+
+  /** Remove an allowed value from a picklist field (DELETE /admin/picklists/:field/:value) */
+  async deletePicklistValue(field: string, value: string): Promise<MessageResponse> {
+    return this.request<MessageResponse>("DELETE", `/admin/picklists/${encodeURIComponent(field)}/${encodeURIComponent(value)}`);
+  }
+
+  /** Get the custom fields and default tags of contacts (GET /contact-template) */
+  async getContactTemplate(): Promise<ContactTemplate> {
+    return this.request<ContactTemplate>("GET", `/contact-template`);
+  }
+
+  /** Create or replace a custom field of the contact template (PUT /admin/contact-template/fields/:key) */
+  async saveCustomField(key: string, body: SaveCustomFieldRequest): Promise<ContactTemplate> {
+    return this.request<ContactTemplate>("PUT", `/admin/contact-template/fields/${encodeURIComponent(key)}`, { body });
+  }
+
+  /** Remove a custom field from the contact template (DELETE /admin/contact-template/fields/:key) */
+  async deleteCustomField(key: string): Promise<ContactTemplate> {
+    return this.request<ContactTemplate>("DELETE", `/admin/contact-template/fields/${encodeURIComponent(key)}`);
+  }
+
+  /** Set the tags attached to every new contact (PUT /admin/contact-template/tags) */
+  async setTemplateTags(body: SetTemplateTagsRequest): Promise<ContactTemplate> {
+    return this.request<ContactTemplate>("PUT", `/admin/contact-template/tags`, { body });
+  }
+
+  /** Report the migration of the contacts to the current contact template (GET /admin/contact-template/migration) */
+  async getTemplateMigration(): Promise<ContactTemplateMigration> {
+    return this.request<ContactTemplateMigration>("GET", `/admin/contact-template/migration`);
+  }
+
+  /** Get the application counters (GET /admin/metrics) */
+  async getMetrics(): Promise<Response> {
+    return this.send("GET", `/admin/metrics`);
+  }
+
+  /** Get instance wide numbers for the admin dashboard (GET /admin/stats) */
+  async getAdminStats(): Promise<AdminStatsResponse> {
+    return this.request<AdminStatsResponse>("GET", `/admin/stats`);
+  }
+
+  /** Get the API requests of every user by day and endpoint, with the top users (GET /admin/usage) */
+  async getAdminAPIUsage(query?: Query): Promise<AdminAPIUsageResponse> {
+    return this.request<AdminAPIUsageResponse>("GET", `/admin/usage`, { query });
+  }
+
+  /** List the accounts sharing an email, candidates for a merge (GET /admin/users/duplicates) */
+  async listDuplicateUsers(): Promise<DuplicateUsersResponse> {
+    return this.request<DuplicateUsersResponse>("GET", `/admin/users/duplicates`);
+  }
+
+  /** Merge an account into another and delete it (POST /admin/users/merge) */
+  async mergeUsers(body: MergeUsersRequest): Promise<MergeUsersResponse> {
+    return this.request<MergeUsersResponse>("POST", `/admin/users/merge`, { body });
+  }
+
+  /** Rebuild search indexes, normalized phone numbers, per-user totals or the caches of the most active users in the background (POST /admin/maintenance/jobs) */
+  async startMaintenanceJob(body: StartMaintenanceJobRequest): Promise<MaintenanceJob> {
+    return this.request<MaintenanceJob>("POST", `/admin/maintenance/jobs`, { body });
+  }
+
+  /** List the maintenance jobs of the replica answering (GET /admin/maintenance/jobs) */
+  async listMaintenanceJobs(): Promise<MaintenanceJobListResponse> {
+    return this.request<MaintenanceJobListResponse>("GET", `/admin/maintenance/jobs`);
+  }
+
+  /** Get the status and result of a maintenance job (GET /admin/maintenance/jobs/:id) */
+  async getMaintenanceJob(id: number): Promise<MaintenanceJob> {
+    return this.request<MaintenanceJob>("GET", `/admin/maintenance/jobs/${encodeURIComponent(id)}`);
+  }
+
+  /** List every announcement, ended and scheduled ones included (GET /admin/announcements) */
+  async listAnnouncements(): Promise<AnnouncementListResponse> {
+    return this.request<AnnouncementListResponse>("GET", `/admin/announcements`);
+  }
+
+  /** Post an announcement to every user (POST /admin/announcements) */
+  async createAnnouncement(body: CreateAnnouncementRequest): Promise<Announcement> {
+    return this.request<Announcement>("POST", `/admin/announcements`, { body });
+  }
+
+  /** Remove an announcement (DELETE /admin/announcements/:id) */
+  async deleteAnnouncement(id: number): Promise<MessageResponse> {
+    return this.request<MessageResponse>("DELETE", `/admin/announcements/${encodeURIComponent(id)}`);
+  }
+
+  /** Get the instance wide analytics settings (GET /admin/analytics) */
+  async getAnalyticsSettings(): Promise<AnalyticsSettings> {
+    return this.request<AnalyticsSettings>("GET", `/admin/analytics`);
+  }
+
+  /** Opt the instance in or out of analytics (PUT /admin/analytics) */
+  async updateAnalyticsSettings(body: UpdateAnalyticsSettingsRequest): Promise<AnalyticsSettings> {
+    return this.request<AnalyticsSettings>("PUT", `/admin/analytics`, { body });
+  }
+
+  /** Get the 5xx error budget alerting settings and route states (GET /admin/alerts) */
+  async getAlertSettings(): Promise<AlertSettings> {
+    return this.request<AlertSettings>("GET", `/admin/alerts`);
+  }
+
+  /** Set how long alerts of a route are suppressed after one fires (PUT /admin/alerts/suppressions) */
+  async setRouteSuppression(body: SetRouteSuppressionRequest): Promise<AlertSettings> {
+    return this.request<AlertSettings>("PUT", `/admin/alerts/suppressions`, { body });
+  }
+
+  /** Export the audit log as CSV (GET /audit/export) */
+  async exportAuditLog(query?: Query): Promise<Response> {
+    return this.send("GET", `/audit/export`, { query });
+  }
+
+  /** List contact groups (GET /groups) */
+  async listGroups(): Promise<GroupListResponse> {
+    return this.request<GroupListResponse>("GET", `/groups`);
+  }
+
+  /** Create a contact group (POST /groups) */
+  async createGroup(body: CreateGroupRequest): Promise<GroupResponse> {
+    return this.request<GroupResponse>("POST", `/groups`, { body });
+  }
+
+  /** Delete a contact group (DELETE /groups/:id) */
+  async deleteGroup(id: number): Promise<MessageResponse> {
+    return this.request<MessageResponse>("DELETE", `/groups/${encodeURIComponent(id)}`);
+  }
+
+  /** Add contacts to a group (POST /groups/:id/contacts) */
+  async addContactsToGroup(id: number, body: BulkContactsRequest): Promise<BulkContactsResponse> {
+    return this.request<BulkContactsResponse>("POST", `/groups/${encodeURIComponent(id)}/contacts`, { body });
+  }
+
+  /** Remove contacts from a group (DELETE /groups/:id/contacts) */
+  async removeContactsFromGroup(id: number, body: BulkContactsRequest): Promise<BulkContactsResponse> {
+    return this.request<BulkContactsResponse>("DELETE", `/groups/${encodeURIComponent(id)}/contacts`, { body });
+  }
+
+  /** List tags (GET /tags) */
+  async listTags(): Promise<TagListResponse> {
+    return this.request<TagListResponse>("GET", `/tags`);
+  }
+
+  /** Create a tag (POST /tags) */
+  async createTag(body: CreateTagRequest): Promise<TagResponse> {
+    return this.request<TagResponse>("POST", `/tags`, { body });
+  }
+
+  /** Delete a tag, its contacts are kept (DELETE /tags/:name) */
+  async deleteTag(name: string): Promise<MessageResponse> {
+    return this.request<MessageResponse>("DELETE", `/tags/${encodeURIComponent(name)}`);
+  }
+
+  /** Tag contacts (POST /tags/:name/contacts) */
+  async addContactsToTag(name: string, body: BulkContactsRequest): Promise<BulkContactsResponse> {
+    return this.request<BulkContactsResponse>("POST", `/tags/${encodeURIComponent(name)}/contacts`, { body });
+  }
+
+  /** Untag contacts (DELETE /tags/:name/contacts) */
+  async removeContactsFromTag(name: string, body: BulkContactsRequest): Promise<BulkContactsResponse> {
+    return this.request<BulkContactsResponse>("DELETE", `/tags/${encodeURIComponent(name)}/contacts`, { body });
+  }
+
+  /** List address book snapshots (GET /snapshots) */
+  async listSnapshots(): Promise<SnapshotListResponse> {
+    return this.request<SnapshotListResponse>("GET", `/snapshots`);
+  }
+
+  /** Take a snapshot of the address book (POST /snapshots) */
+  async createSnapshot(body: CreateSnapshotRequest): Promise<SnapshotResponse> {
+    return this.request<SnapshotResponse>("POST", `/snapshots`, { body });
+  }
+
+  /** Delete a snapshot (DELETE /snapshots/:id) */
+  async deleteSnapshot(id: number): Promise<MessageResponse> {
+    return this.request<MessageResponse>("DELETE", `/snapshots/${encodeURIComponent(id)}`);
+  }
+
+  /** Compare a snapshot with the current address book (GET /snapshots/:id/diff) */
+  async diffSnapshot(id: number): Promise<SnapshotDiffResponse> {
+    return this.request<SnapshotDiffResponse>("GET", `/snapshots/${encodeURIComponent(id)}/diff`);
+  }
+
+  /** Restore the address book from a snapshot (POST /snapshots/:id/restore) */
+  async restoreSnapshot(id: number): Promise<RestoreSnapshotResponse> {
+    return this.request<RestoreSnapshotResponse>("POST", `/snapshots/${encodeURIComponent(id)}/restore`);
+  }
+
+  /** List webhooks (GET /webhooks) */
+  async listWebhooks(): Promise<WebhookListResponse> {
+    return this.request<WebhookListResponse>("GET", `/webhooks`);
+  }
+
+  /** Register a webhook URL (POST /webhooks) */
+  async createWebhook(body: CreateWebhookRequest): Promise<WebhookResponse> {
+    return this.request<WebhookResponse>("POST", `/webhooks`, { body });
+  }
+
+  /** Delete a webhook (DELETE /webhooks/:id) */
+  async deleteWebhook(id: number): Promise<MessageResponse> {
+    return this.request<MessageResponse>("DELETE", `/webhooks/${encodeURIComponent(id)}`);
+  }
+
+  /** List the latest deliveries of a webhook with their status (GET /webhooks/:id/deliveries) */
+  async listWebhookDeliveries(id: number): Promise<WebhookDeliveryListResponse> {
+    return this.request<WebhookDeliveryListResponse>("GET", `/webhooks/${encodeURIComponent(id)}/deliveries`);
+  }
+
+  /** Send a sample signed event to a webhook (POST /webhooks/test) */
+  async testWebhook(body: TestWebhookRequest): Promise<TestWebhookResponse> {
+    return this.request<TestWebhookResponse>("POST", `/webhooks/test`, { body });
+  }
+}
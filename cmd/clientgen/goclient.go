@@ -0,0 +1,298 @@
+package main
+
+import (
+	"fmt"
+	"go/format"
+	"reflect"
+	"strings"
+
+	"github.com/danizion/contact-app/internal/api"
+)
+
+const goClientRuntime = `
+// APIError is returned for every response outside the 2xx range, Message is the error reported by the API.
+// RetryAfter is set when the API asks to wait before retrying (rate limited requests)
+type APIError struct {
+	StatusCode int
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("contact api: %d %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound reports whether err is an APIError with status 404
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsUnauthorized reports whether err is an APIError with status 401, the token is missing or expired
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+// IsForbidden reports whether err is an APIError with status 403
+func IsForbidden(err error) bool {
+	return hasStatus(err, http.StatusForbidden)
+}
+
+// IsConflict reports whether err is an APIError with status 409
+func IsConflict(err error) bool {
+	return hasStatus(err, http.StatusConflict)
+}
+
+// IsRateLimited reports whether err is an APIError with status 429, wait for its RetryAfter before retrying
+func IsRateLimited(err error) bool {
+	return hasStatus(err, http.StatusTooManyRequests)
+}
+
+func hasStatus(err error, status int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status
+}
+
+// Client calls the contact API, set Token (from Login) or APIKey ("<key_id>:<secret>") before calling
+// authenticated endpoints
+type Client struct {
+	BaseURL    string
+	Token      string
+	APIKey     string
+	HTTPClient *http.Client
+}
+
+// New creates a client for the API served at baseURL
+func New(baseURL string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, body io.Reader, contentType string) (*http.Response, error) {
+	target := c.BaseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	switch {
+	case c.Token != "":
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	case c.APIKey != "":
+		req.Header.Set("Authorization", "ApiKey "+c.APIKey)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		apiErr := &APIError{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			apiErr.RetryAfter = time.Duration(seconds) * time.Second
+		}
+		var errBody ErrorResponse
+		if json.NewDecoder(resp.Body).Decode(&errBody) == nil && errBody.Error != "" {
+			apiErr.Message = errBody.Error
+		}
+		return nil, apiErr
+	}
+	return resp, nil
+}
+
+func (c *Client) doJSON(ctx context.Context, method, path string, query url.Values, in, out interface{}) error {
+	var body io.Reader
+	contentType := ""
+	if in != nil {
+		encoded, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(encoded)
+		contentType = "application/json"
+	}
+
+	resp, err := c.send(ctx, method, path, query, body, contentType)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (c *Client) doMultipart(ctx context.Context, method, path, field, fileName string, file io.Reader, out interface{}) error {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile(field, fileName)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	resp, err := c.send(ctx, method, path, nil, &body, writer.FormDataContentType())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+`
+
+func generateGoClient(routes []api.Route, types *typeSet) ([]byte, error) {
+	var b strings.Builder
+	b.WriteString(goClientRuntime)
+
+	for _, t := range types.order {
+		fmt.Fprintf(&b, "\ntype %s struct {\n", types.name(t))
+		for _, f := range fields(t) {
+			tag := f.JSONName
+			if f.Optional {
+				tag += ",omitempty"
+			}
+			fmt.Fprintf(&b, "\t%s %s `json:%q`\n", f.GoName, goType(f.Type, types), tag)
+		}
+		b.WriteString("}\n")
+	}
+
+	for _, route := range routes {
+		writeGoMethod(&b, route, types)
+	}
+
+	imports := []string{"bytes", "context", "encoding/json", "errors", "fmt", "io", "mime/multipart", "net/http", "net/url"}
+	code := b.String()
+	if strings.Contains(code, "strconv.") {
+		imports = append(imports, "strconv")
+	}
+	imports = append(imports, "strings")
+	if strings.Contains(code, "time.") {
+		imports = append(imports, "time")
+	}
+
+	var header strings.Builder
+	header.WriteString("// Code generated by cmd/clientgen. DO NOT EDIT.\n\n")
+	header.WriteString("// Package contactclient is the Go client of the contact API\n")
+	header.WriteString("package contactclient\n\nimport (\n")
+	for _, path := range imports {
+		fmt.Fprintf(&header, "\t%q\n", path)
+	}
+	header.WriteString(")\n")
+
+	return format.Source([]byte(header.String() + code))
+}
+
+func writeGoMethod(b *strings.Builder, route api.Route, types *typeSet) {
+	params := []string{"ctx context.Context"}
+	path := fmt.Sprintf("%q", route.Path)
+	for _, param := range pathParams(route.Path) {
+		if param.IsID {
+			params = append(params, param.GoName+" int")
+			path = strings.Replace(path, param.Pattern, `"+strconv.Itoa(`+param.GoName+`)+"`, 1)
+		} else {
+			params = append(params, param.GoName+" string")
+			path = strings.Replace(path, param.Pattern, `"+url.PathEscape(`+param.GoName+`)+"`, 1)
+		}
+	}
+	path = strings.TrimSuffix(path, `+""`)
+
+	body := "nil"
+	switch {
+	case route.FileField != "":
+		params = append(params, "fileName string", "file io.Reader")
+	case route.Body != nil:
+		params = append(params, "body "+types.name(reflect.TypeOf(route.Body)))
+		body = "body"
+	}
+	query := "nil"
+	if len(route.Query) > 0 {
+		params = append(params, "query url.Values")
+		query = "query"
+	}
+
+	fmt.Fprintf(b, "\n// %s calls %s %s: %s\n", route.Name, route.Method, route.Path, lowerFirst(route.Summary))
+	signature := strings.Join(params, ", ")
+
+	if route.Raw != "" {
+		fmt.Fprintf(b, "// the caller must close the body of the returned response\n")
+		fmt.Fprintf(b, "func (c *Client) %s(%s) (*http.Response, error) {\n", route.Name, signature)
+		fmt.Fprintf(b, "\treturn c.send(ctx, %q, %s, %s, nil, \"\")\n}\n", route.Method, path, query)
+		return
+	}
+
+	result := "MessageResponse"
+	if route.Response != nil {
+		result = types.name(reflect.TypeOf(route.Response))
+	}
+	fmt.Fprintf(b, "func (c *Client) %s(%s) (*%s, error) {\n", route.Name, signature, result)
+	fmt.Fprintf(b, "\tvar result %s\n", result)
+	if route.FileField != "" {
+		fmt.Fprintf(b, "\tif err := c.doMultipart(ctx, %q, %s, %q, fileName, file, &result); err != nil {\n", route.Method, path, route.FileField)
+	} else {
+		fmt.Fprintf(b, "\tif err := c.doJSON(ctx, %q, %s, %s, %s, &result); err != nil {\n", route.Method, path, query, body)
+	}
+	b.WriteString("\t\treturn nil, err\n\t}\n\treturn &result, nil\n}\n")
+
+	if route.Paginated {
+		item := types.name(itemsType(reflect.TypeOf(route.Response)))
+		args := strings.Join(params[1:len(params)-1], ", ")
+		if args != "" {
+			args += ", "
+		}
+		fmt.Fprintf(b, "\n// %sAll calls fn for every item of every page of %s, stopping at the first error\n", route.Name, route.Name)
+		fmt.Fprintf(b, "func (c *Client) %sAll(%s, fn func(%s) error) error {\n", route.Name, signature, item)
+		b.WriteString("\tpageQuery := url.Values{}\n\tfor key, values := range query {\n\t\tpageQuery[key] = values\n\t}\n")
+		// Pages are walked by cursor, which stays fast on deep pages and skips no item when contacts are added meanwhile
+		b.WriteString("\tpageQuery.Del(\"page\")\n\tfor {\n")
+		fmt.Fprintf(b, "\t\tresult, err := c.%s(ctx, %spageQuery)\n", route.Name, goArgNames(args))
+		b.WriteString("\t\tif err != nil {\n\t\t\treturn err\n\t\t}\n")
+		b.WriteString("\t\tfor _, item := range result.Items {\n\t\t\tif err := fn(item); err != nil {\n\t\t\t\treturn err\n\t\t\t}\n\t\t}\n")
+		b.WriteString("\t\tif result.NextCursor == \"\" {\n\t\t\treturn nil\n\t\t}\n")
+		b.WriteString("\t\tpageQuery.Set(\"cursor\", result.NextCursor)\n\t}\n}\n")
+	}
+}
+
+// goArgNames turns "id int, body X, " into "id, body, "
+func goArgNames(params string) string {
+	if params == "" {
+		return ""
+	}
+	var names []string
+	for _, param := range strings.Split(strings.TrimSuffix(params, ", "), ", ") {
+		name, _, _ := strings.Cut(param, " ")
+		names = append(names, name)
+	}
+	return strings.Join(names, ", ") + ", "
+}
+
+func goType(t reflect.Type, types *typeSet) string {
+	if t == timeType {
+		return "time.Time"
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return "*" + goType(t.Elem(), types)
+	case reflect.Struct:
+		return types.name(t)
+	case reflect.Slice:
+		return "[]" + goType(t.Elem(), types)
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%s", t.Len(), goType(t.Elem(), types))
+	case reflect.Map:
+		return "map[" + goType(t.Key(), types) + "]" + goType(t.Elem(), types)
+	case reflect.Interface:
+		return "interface{}"
+	default:
+		return t.Kind().String()
+	}
+}
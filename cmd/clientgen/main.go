@@ -0,0 +1,62 @@
+// Command clientgen generates the OpenAPI document and the Go and TypeScript clients from api.Routes and the DTOs,
+// run it with go generate ./clients after changing either
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/danizion/contact-app/internal/api"
+)
+
+func main() {
+	out := flag.String("out", "clients", "directory the clients are written to")
+	check := flag.Bool("check", false, "fail instead of writing when the generated files are out of date")
+	flag.Parse()
+
+	routes := api.Routes()
+	types := collectTypes(routes)
+
+	openAPI, err := generateOpenAPI(routes, types)
+	if err != nil {
+		log.Fatalf("Failed to generate OpenAPI document: %v", err)
+	}
+	goClient, err := generateGoClient(routes, types)
+	if err != nil {
+		log.Fatalf("Failed to generate Go client: %v", err)
+	}
+	tsClient := generateTSClient(routes, types)
+
+	files := map[string][]byte{
+		"openapi.json":               openAPI,
+		"go/contactclient/client.go": goClient,
+		"typescript/src/client.ts":   tsClient,
+	}
+
+	stale := false
+	for name, content := range files {
+		path := filepath.Join(*out, name)
+		current, err := os.ReadFile(path)
+		if err == nil && bytes.Equal(current, content) {
+			continue
+		}
+		if *check {
+			fmt.Fprintf(os.Stderr, "%s is out of date, run go generate ./clients\n", path)
+			stale = true
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			log.Fatalf("Failed to create %s: %v", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, content, 0o644); err != nil {
+			log.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+	if stale {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/danizion/contact-app/internal/api"
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/render"
+)
+
+type object = map[string]interface{}
+
+func generateOpenAPI(routes []api.Route, types *typeSet) ([]byte, error) {
+	paths := object{}
+	for _, route := range routes {
+		path := openAPIPath(route.Path)
+		operations, ok := paths[path].(object)
+		if !ok {
+			operations = object{}
+			paths[path] = operations
+		}
+		operations[lowerMethod(route.Method)] = operation(route, types)
+	}
+
+	schemas := object{}
+	for _, t := range types.order {
+		schemas[types.name(t)] = structSchema(t, types)
+	}
+
+	document := object{
+		"openapi": "3.0.3",
+		"info": object{
+			"title":   "Contact App API",
+			"version": "1.0.0",
+		},
+		"paths": paths,
+		"components": object{
+			"schemas": schemas,
+			"headers": object{
+				constants.HeaderRateLimitLimit:     rateLimitHeader("Requests allowed per window"),
+				constants.HeaderRateLimitRemaining: rateLimitHeader("Requests left in the current window"),
+				constants.HeaderRateLimitReset:     rateLimitHeader("Unix time the current window ends"),
+				constants.HeaderRetryAfter:         rateLimitHeader("Seconds to wait before retrying"),
+			},
+			"securitySchemes": object{
+				"bearerAuth": object{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+			},
+		},
+	}
+
+	content, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(content, '\n'), nil
+}
+
+func operation(route api.Route, types *typeSet) object {
+	op := object{
+		"operationId": route.Name,
+		"summary":     route.Summary,
+	}
+	if route.Access != api.AccessPublic {
+		op["security"] = []object{{"bearerAuth": []string{}}}
+	}
+
+	var parameters []object
+	for _, param := range pathParams(route.Path) {
+		schema := object{"type": "string"}
+		if param.IsID {
+			schema = object{"type": "integer"}
+		}
+		parameters = append(parameters, object{"name": param.Name, "in": "path", "required": true, "schema": schema})
+	}
+	for _, name := range route.Query {
+		parameters = append(parameters, object{"name": name, "in": "query", "schema": object{"type": "string"}})
+	}
+	if parameters != nil {
+		op["parameters"] = parameters
+	}
+
+	switch {
+	case route.FileField != "":
+		op["requestBody"] = object{
+			"required": true,
+			"content": object{"multipart/form-data": object{"schema": object{
+				"type":       "object",
+				"required":   []string{route.FileField},
+				"properties": object{route.FileField: object{"type": "string", "format": "binary"}},
+			}}},
+		}
+	case route.Body != nil:
+		op["requestBody"] = object{
+			"required": true,
+			"content":  object{"application/json": object{"schema": schemaRef(reflect.TypeOf(route.Body), types)}},
+		}
+	}
+
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := object{"description": http.StatusText(status)}
+	switch {
+	case route.Raw != "":
+		success["content"] = object{route.Raw: object{"schema": object{"type": "string", "format": "binary"}}}
+	case route.Response != nil:
+		success["content"] = object{"application/json": object{"schema": schemaRef(reflect.TypeOf(route.Response), types)}}
+	}
+	if route.Negotiated {
+		content := success["content"].(object)
+		content[render.MIMEXML] = object{"schema": object{"type": "string", "description": "XML document with the elements of the JSON response"}}
+		content[render.MIMECSV] = object{"schema": object{"type": "string"}}
+		content[render.MIMEJSONAPI] = object{"schema": object{"type": "object", "description": "JSON:API document, also returned for format=jsonapi"}}
+	}
+	success["headers"] = rateLimitHeaders(constants.HeaderRateLimitLimit, constants.HeaderRateLimitRemaining, constants.HeaderRateLimitReset)
+	op["responses"] = object{
+		strconv.Itoa(status): success,
+		"429": object{
+			"description": "Rate limit exceeded",
+			"headers":     rateLimitHeaders(constants.HeaderRateLimitLimit, constants.HeaderRateLimitRemaining, constants.HeaderRateLimitReset, constants.HeaderRetryAfter),
+			"content":     object{"application/json": object{"schema": object{"$ref": "#/components/schemas/RateLimitErrorResponse"}}},
+		},
+		"default": object{
+			"description": "Error",
+			"content":     object{"application/json": object{"schema": object{"$ref": "#/components/schemas/ErrorResponse"}}},
+		},
+	}
+	return op
+}
+
+func rateLimitHeader(description string) object {
+	return object{"description": description, "schema": object{"type": "integer"}}
+}
+
+// rateLimitHeaders references the rate limit headers declared in the components
+func rateLimitHeaders(names ...string) object {
+	headers := object{}
+	for _, name := range names {
+		headers[name] = object{"$ref": "#/components/headers/" + name}
+	}
+	return headers
+}
+
+func structSchema(t reflect.Type, types *typeSet) object {
+	properties := object{}
+	var required []string
+	for _, f := range fields(t) {
+		properties[f.JSONName] = schemaRef(f.Type, types)
+		if !f.Optional {
+			required = append(required, f.JSONName)
+		}
+	}
+
+	schema := object{"type": "object", "properties": properties}
+	if required != nil {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func schemaRef(t reflect.Type, types *typeSet) object {
+	if t == timeType {
+		return object{"type": "string", "format": "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		schema := schemaRef(t.Elem(), types)
+		if _, isRef := schema["$ref"]; !isRef {
+			schema["nullable"] = true
+		}
+		return schema
+	case reflect.Struct:
+		return object{"$ref": "#/components/schemas/" + types.name(t)}
+	case reflect.Slice:
+		return object{"type": "array", "items": schemaRef(t.Elem(), types)}
+	case reflect.Array:
+		return object{"type": "array", "items": schemaRef(t.Elem(), types), "minItems": t.Len(), "maxItems": t.Len()}
+	case reflect.Map:
+		return object{"type": "object", "additionalProperties": schemaRef(t.Elem(), types)}
+	case reflect.String:
+		return object{"type": "string"}
+	case reflect.Bool:
+		return object{"type": "boolean"}
+	case reflect.Int, reflect.Int32:
+		return object{"type": "integer", "format": "int32"}
+	case reflect.Int64:
+		return object{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return object{"type": "number"}
+	default:
+		return object{}
+	}
+}
+
+func lowerMethod(method string) string {
+	switch method {
+	case http.MethodGet:
+		return "get"
+	case http.MethodPost:
+		return "post"
+	case http.MethodPut:
+		return "put"
+	case http.MethodPatch:
+		return "patch"
+	case http.MethodDelete:
+		return "delete"
+	}
+	panic("unsupported method " + method)
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/danizion/contact-app/internal/api"
+)
+
+const tsClientRuntime = `
+/** Thrown for every response outside the 2xx range, message is the error reported by the API */
+export class ApiError extends Error {
+  constructor(
+    readonly status: number,
+    message: string,
+    /** Seconds to wait before retrying, set when the API asks for it (rate limited requests) */
+    readonly retryAfter?: number,
+  ) {
+    super(message);
+    this.name = "ApiError";
+  }
+
+  get isNotFound(): boolean {
+    return this.status === 404;
+  }
+
+  get isUnauthorized(): boolean {
+    return this.status === 401;
+  }
+
+  get isForbidden(): boolean {
+    return this.status === 403;
+  }
+
+  get isConflict(): boolean {
+    return this.status === 409;
+  }
+
+  get isRateLimited(): boolean {
+    return this.status === 429;
+  }
+}
+
+export type Query = Record<string, string | number | boolean | undefined>;
+
+interface RequestOptions {
+  query?: Query;
+  body?: unknown;
+  form?: FormData;
+}
+
+/** Calls the contact API, set token (from login) or apiKey ("<key_id>:<secret>") before calling authenticated endpoints */
+export class ContactClient {
+  constructor(
+    private readonly baseUrl: string,
+    public token?: string,
+    public apiKey?: string,
+    private readonly fetchFn: typeof fetch = (input, init) => fetch(input, init),
+  ) {
+    this.baseUrl = baseUrl.replace(/\/$/, "");
+  }
+
+  private async send(method: string, path: string, options: RequestOptions = {}): Promise<Response> {
+    const params = new URLSearchParams();
+    for (const [key, value] of Object.entries(options.query ?? {})) {
+      if (value !== undefined) {
+        params.set(key, String(value));
+      }
+    }
+    const search = params.toString();
+
+    const headers: Record<string, string> = {};
+    if (this.token) {
+      headers["Authorization"] = "Bearer " + this.token;
+    } else if (this.apiKey) {
+      headers["Authorization"] = "ApiKey " + this.apiKey;
+    }
+    let body: BodyInit | undefined = options.form;
+    if (options.body !== undefined) {
+      headers["Content-Type"] = "application/json";
+      body = JSON.stringify(options.body);
+    }
+
+    const response = await this.fetchFn(this.baseUrl + path + (search ? "?" + search : ""), { method, headers, body });
+    if (!response.ok) {
+      let message = response.statusText;
+      try {
+        const error = (await response.json()) as ErrorResponse;
+        if (error.error) {
+          message = error.error;
+        }
+      } catch {
+        // the error body is not JSON, keep the status text
+      }
+      const retryAfter = Number(response.headers.get("Retry-After"));
+      throw new ApiError(response.status, message, retryAfter > 0 ? retryAfter : undefined);
+    }
+    return response;
+  }
+
+  private async request<T>(method: string, path: string, options?: RequestOptions): Promise<T> {
+    const response = await this.send(method, path, options);
+    return (await response.json()) as T;
+  }
+`
+
+func generateTSClient(routes []api.Route, types *typeSet) []byte {
+	var b strings.Builder
+	b.WriteString("// Code generated by cmd/clientgen. DO NOT EDIT.\n\n")
+
+	for _, t := range types.order {
+		fmt.Fprintf(&b, "export interface %s {\n", types.name(t))
+		for _, f := range fields(t) {
+			optional := ""
+			if f.Optional {
+				optional = "?"
+			}
+			fmt.Fprintf(&b, "  %s%s: %s;\n", f.JSONName, optional, tsType(f.Type, types))
+		}
+		b.WriteString("}\n\n")
+	}
+
+	b.WriteString(strings.TrimPrefix(tsClientRuntime, "\n"))
+	for _, route := range routes {
+		writeTSMethod(&b, route, types)
+	}
+	b.WriteString("}\n")
+	return []byte(b.String())
+}
+
+func writeTSMethod(b *strings.Builder, route api.Route, types *typeSet) {
+	var params []string
+	path := "`" + route.Path + "`"
+	for _, param := range pathParams(route.Path) {
+		if param.IsID {
+			params = append(params, param.TSName+": number")
+		} else {
+			params = append(params, param.TSName+": string")
+		}
+		path = strings.Replace(path, param.Pattern, "${encodeURIComponent("+param.TSName+")}", 1)
+	}
+
+	var options []string
+	switch {
+	case route.FileField != "":
+		params = append(params, "file: Blob", "fileName?: string")
+		options = append(options, "form")
+	case route.Body != nil:
+		params = append(params, "body: "+types.name(reflect.TypeOf(route.Body)))
+		options = append(options, "body")
+	}
+	if len(route.Query) > 0 {
+		params = append(params, "query?: Query")
+		options = append(options, "query")
+	}
+	optionsArg := ""
+	if len(options) > 0 {
+		optionsArg = ", { " + strings.Join(options, ", ") + " }"
+	}
+
+	name := lowerFirst(route.Name)
+	signature := strings.Join(params, ", ")
+	fmt.Fprintf(b, "\n  /** %s (%s %s) */\n", route.Summary, route.Method, route.Path)
+
+	if route.Raw != "" {
+		fmt.Fprintf(b, "  async %s(%s): Promise<Response> {\n", name, signature)
+		fmt.Fprintf(b, "    return this.send(%q, %s%s);\n  }\n", route.Method, path, optionsArg)
+		return
+	}
+
+	result := "MessageResponse"
+	if route.Response != nil {
+		result = types.name(reflect.TypeOf(route.Response))
+	}
+	fmt.Fprintf(b, "  async %s(%s): Promise<%s> {\n", name, signature, result)
+	if route.FileField != "" {
+		b.WriteString("    const form = new FormData();\n")
+		fmt.Fprintf(b, "    form.append(%q, file, fileName);\n", route.FileField)
+	}
+	fmt.Fprintf(b, "    return this.request<%s>(%q, %s%s);\n  }\n", result, route.Method, path, optionsArg)
+
+	if route.Paginated {
+		item := types.name(itemsType(reflect.TypeOf(route.Response)))
+		var args []string
+		for _, param := range params[:len(params)-1] {
+			argName, _, _ := strings.Cut(param, ":")
+			args = append(args, strings.TrimSuffix(argName, "?"))
+		}
+		args = append(args, "{ ...query, page: undefined, cursor }")
+
+		// Pages are walked by cursor, see the Go client
+		fmt.Fprintf(b, "\n  /** Iterates over every item of every page of %s */\n", name)
+		fmt.Fprintf(b, "  async *%sAll(%s): AsyncGenerator<%s> {\n", name, signature, item)
+		b.WriteString("    let cursor: string | undefined;\n    for (;;) {\n")
+		fmt.Fprintf(b, "      const result = await this.%s(%s);\n", name, strings.Join(args, ", "))
+		b.WriteString("      yield* result.items ?? [];\n")
+		b.WriteString("      if (!result.next_cursor) {\n        return;\n      }\n      cursor = result.next_cursor;\n    }\n  }\n")
+	}
+}
+
+func tsType(t reflect.Type, types *typeSet) string {
+	if t == timeType {
+		return "string"
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return tsType(t.Elem(), types)
+	case reflect.Struct:
+		return types.name(t)
+	case reflect.Slice:
+		return tsType(t.Elem(), types) + "[]"
+	case reflect.Array:
+		element := tsType(t.Elem(), types)
+		elements := make([]string, t.Len())
+		for i := range elements {
+			elements[i] = element
+		}
+		return "[" + strings.Join(elements, ", ") + "]"
+	case reflect.Map:
+		return "Record<string, " + tsType(t.Elem(), types) + ">"
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int32, reflect.Int64, reflect.Float32, reflect.Float64:
+		return "number"
+	default:
+		return "unknown"
+	}
+}
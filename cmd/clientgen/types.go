@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/danizion/contact-app/internal/api"
+	"github.com/danizion/contact-app/internal/dtos"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// field is a JSON field of a DTO as seen by clients
+type field struct {
+	GoName   string
+	JSONName string
+	Type     reflect.Type
+	Optional bool
+}
+
+// typeSet holds the DTO structs reachable from the routes in a stable order
+type typeSet struct {
+	order  []reflect.Type
+	names  map[reflect.Type]string
+	byName map[string]reflect.Type
+}
+
+func collectTypes(routes []api.Route) *typeSet {
+	types := &typeSet{names: map[reflect.Type]string{}, byName: map[string]reflect.Type{}}
+	types.add(reflect.TypeOf(dtos.ErrorResponseDto{}))
+	types.add(reflect.TypeOf(dtos.RateLimitErrorResponseDto{}))
+	for _, route := range routes {
+		if route.Body != nil {
+			types.add(reflect.TypeOf(route.Body))
+		}
+		if route.Response != nil {
+			types.add(reflect.TypeOf(route.Response))
+		}
+	}
+	return types
+}
+
+func (s *typeSet) add(t reflect.Type) {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		s.add(t.Elem())
+		return
+	case reflect.Struct:
+	default:
+		return
+	}
+	if t == timeType {
+		return
+	}
+	if _, ok := s.names[t]; ok {
+		return
+	}
+
+	name := strings.TrimSuffix(t.Name(), "Dto")
+	if other, ok := s.byName[name]; ok {
+		panic(fmt.Sprintf("types %s and %s both generate %s", t, other, name))
+	}
+	s.names[t] = name
+	s.byName[name] = t
+	s.order = append(s.order, t)
+
+	for _, f := range fields(t) {
+		s.add(f.Type)
+	}
+}
+
+func (s *typeSet) name(t reflect.Type) string {
+	return s.names[t]
+}
+
+// fields lists the JSON fields of a struct, flattening embedded structs and skipping the fields filled in by the server
+func fields(t reflect.Type) []field {
+	var result []field
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() || f.Tag.Get("client") == "-" {
+			continue
+		}
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			result = append(result, fields(f.Type)...)
+			continue
+		}
+
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+		result = append(result, field{
+			GoName:   f.Name,
+			JSONName: name,
+			Type:     f.Type,
+			Optional: strings.Contains(options, "omitempty") || f.Type.Kind() == reflect.Ptr,
+		})
+	}
+	return result
+}
+
+// itemsType returns the element type of the Items field of a paginated response
+func itemsType(t reflect.Type) reflect.Type {
+	f, ok := t.FieldByName("Items")
+	if !ok || f.Type.Kind() != reflect.Slice {
+		panic(fmt.Sprintf("paginated response %s has no Items slice", t))
+	}
+	return f.Type.Elem()
+}
+
+// pathParam is a parameter of a route path, ids are integers and everything else strings
+type pathParam struct {
+	Name    string
+	IsID    bool
+	GoName  string
+	TSName  string
+	Pattern string
+}
+
+var pathParamPattern = regexp.MustCompile(`:([A-Za-z]+)`)
+
+func pathParams(path string) []pathParam {
+	var params []pathParam
+	for _, match := range pathParamPattern.FindAllStringSubmatch(path, -1) {
+		name := match[1]
+		goName := name
+		if strings.HasSuffix(name, "Id") {
+			goName = strings.TrimSuffix(name, "Id") + "ID"
+		}
+		params = append(params, pathParam{
+			Name:    name,
+			IsID:    name == "id" || strings.HasSuffix(name, "Id"),
+			GoName:  goName,
+			TSName:  name,
+			Pattern: match[0],
+		})
+	}
+	return params
+}
+
+// openAPIPath converts a gin path (/contacts/:id) to an OpenAPI path (/contacts/{id})
+func openAPIPath(path string) string {
+	return pathParamPattern.ReplaceAllString(path, "{$1}")
+}
+
+func lowerFirst(s string) string {
+	return strings.ToLower(s[:1]) + s[1:]
+}
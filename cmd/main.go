@@ -1,18 +1,60 @@
 package main
 
 import (
-	"github.com/danizion/contact-app/internal/utils"
+	"encoding/json"
+	"flag"
+	"fmt"
 	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+	// embed the IANA timezone database, the runtime image has none
+	_ "time/tzdata"
 
+	"github.com/danizion/contact-app/internal/alerting"
+	"github.com/danizion/contact-app/internal/analytics"
 	"github.com/danizion/contact-app/internal/api"
+	"github.com/danizion/contact-app/internal/config"
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/demo"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/enrichment"
+	"github.com/danizion/contact-app/internal/events"
+	"github.com/danizion/contact-app/internal/geocode"
+	"github.com/danizion/contact-app/internal/jobs"
 	"github.com/danizion/contact-app/internal/logger"
+	"github.com/danizion/contact-app/internal/mail"
 	"github.com/danizion/contact-app/internal/middlewares"
+	"github.com/danizion/contact-app/internal/notify"
+	"github.com/danizion/contact-app/internal/ocr"
+	"github.com/danizion/contact-app/internal/server"
+	"github.com/danizion/contact-app/internal/service"
+	"github.com/danizion/contact-app/internal/storage/blob"
 	"github.com/danizion/contact-app/internal/storage/db"
 	"github.com/danizion/contact-app/internal/storage/redis"
+	"github.com/danizion/contact-app/internal/utils"
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
+	// admin commands run against the database and exit instead of serving the API
+	if len(os.Args) > 1 && os.Args[1] == "admin" {
+		os.Exit(runAdmin(os.Args[2:]))
+	}
+	// config check validates the configuration without starting the server
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		os.Exit(runConfig(os.Args[2:]))
+	}
+
+	// local state (blobs) is kept under the data directory, the binary embeds everything else it needs
+	dataDir := flag.String("data-dir", utils.GetEnvOrDefault("DATA_DIR", "./data"), "directory for local storage such as attachment blobs")
+	flag.Parse()
+
 	// Initialize the logger
 	logger.Setup()
 	slog.Info("Contact application starting up")
@@ -26,32 +68,399 @@ func main() {
 	redisCache := redis.InitRedis()
 	slog.Info("Redis cache connection initialized")
 
+	// init event bus, the contacts cache, the count badge and webhooks follow the changes to contacts
+	events.Init(redisCache)
+	if redisCache != nil {
+		events.Subscribe(service.InvalidateContactsCache(redisCache), events.ContactEvents...)
+		events.Subscribe(service.CountContactChanges(redisCache), events.ContactEvents...)
+	}
+	webhookService := service.NewWebhookService(postgresDb)
+	events.Subscribe(webhookService.DeliverEvent, events.ContactEvents...)
+	jobs.Every("webhook-retries", constants.WebhookRetryInterval, webhookService.RetryDueDeliveries)
+	jobs.Every("webhook-deliveries-cleanup", constants.WebhookDeliveryCleanupInterval, webhookService.CleanupDeliveries)
+	jobs.Every("api-usage-rollup", constants.APIUsageRollupInterval, service.NewUsageService(postgresDb, redisCache).RollUp)
+	jobs.Every("sync-batch-cleanup", constants.SyncBatchCleanupInterval, service.NewSyncService(postgresDb, redisCache).CleanupBatches)
+	slog.Info("Event bus initialized")
+
+	// contacts are searched in the language of their owner, the deployment one for users without their own; the
+	// reindex job catches up the contacts indexed in a previous language
+	searchService := service.NewSearchService(postgresDb, redisCache)
+	if err := searchService.ApplyDefaultLanguage(utils.GetEnvOrDefault("SEARCH_LANGUAGE", constants.DefaultSearchLanguage)); err != nil {
+		slog.Error("Failed to apply search language", "error", err)
+	}
+	jobs.Every("search-reindex", constants.SearchReindexInterval, searchService.Reindex)
+
+	// contacts following a previous version of the contact template are migrated to the current one
+	templateService := service.NewContactTemplateService(postgresDb, redisCache)
+	jobs.Every("contact-template-migration", constants.ContactTemplateMigrationInterval, templateService.MigrateContacts)
+
+	// init blob store
+	blobStore := blob.Init(*dataDir)
+	slog.Info("Blob store initialized", "dataDir", *dataDir)
+	jobs.Every("trash-purge", constants.TrashPurgeInterval, service.NewTrashService(postgresDb, blobStore).PurgeExpiredTrash)
+
+	// init OCR provider, business card import is disabled when none is configured
+	ocrProvider := ocr.Init()
+	if ocrProvider == nil {
+		slog.Info("No OCR provider configured, business card import disabled")
+	}
+
+	// init enrichment provider, contact enrichment is disabled when none is configured
+	enrichmentProvider := enrichment.Init()
+	if enrichmentProvider == nil {
+		slog.Info("No enrichment provider configured, contact enrichment disabled")
+	}
+
+	// init geocoding provider, contacts are only placed on the map from explicit coordinates when none is configured
+	geocodeProvider := geocode.Init()
+	if geocodeProvider == nil {
+		slog.Info("No geocoding provider configured, address geocoding disabled")
+	}
+
+	// init mail sender, emails (weekly digest, email change confirmations) are disabled when none is configured
+	mailSender := mail.Init()
+	if mailSender == nil {
+		slog.Info("No SMTP server configured, emails disabled")
+	} else {
+		digestService := service.NewDigestService(postgresDb, mailSender)
+		jobs.Every("weekly-digest", constants.DigestCheckInterval, digestService.SendDueDigests)
+	}
+
+	// shared contacts changed by their owner are marked updated for the other users, and emailed to those who opted in
+	events.Subscribe(service.NewSharedBookService(postgresDb, mailSender).NotifyContactChange, events.ContactUpdated)
+
+	// demo mode provisions a public demo account and resets its data from the seed fixtures on a schedule
+	if demoConfig := demo.Load(); demoConfig.Enabled {
+		demoService := service.NewDemoService(postgresDb, redisCache, demoConfig)
+		if err := demoService.Reset(); err != nil {
+			slog.Error("Failed to reset demo account", "error", err)
+		}
+		jobs.Every("demo-reset", demoConfig.ResetInterval, demoService.Reset)
+		slog.Info("Demo mode enabled", "email", demoConfig.Email, "resetInterval", demoConfig.ResetInterval)
+	}
+
+	// init analytics, anonymized product events are only recorded when a sink is configured and admins did not opt out
+	if analytics.Init(postgresDb) == nil {
+		slog.Info("No analytics sink configured, analytics disabled")
+	} else {
+		analyticsService := service.NewAnalyticsService(postgresDb)
+		if err := analyticsService.ApplySettings(); err != nil {
+			slog.Error("Failed to load analytics settings", "error", err)
+		}
+		jobs.Every("analytics-settings", constants.AnalyticsSettingsRefreshInterval, analyticsService.ApplySettings)
+	}
+
+	// init error budget alerting, routes exceeding their 5xx budget alert through Slack, a webhook or email
+	var alertMonitor *alerting.Monitor
+	if notifier := notify.Init(mailSender); notifier == nil {
+		slog.Info("No alert channel configured, 5xx alerting disabled")
+	} else {
+		alertMonitor = alerting.NewMonitor(alerting.LoadConfig(), notifier)
+		alertService := service.NewAlertService(postgresDb, alertMonitor)
+		if err := alertService.ApplySettings(); err != nil {
+			slog.Error("Failed to load alert settings", "error", err)
+		}
+		jobs.Every("alert-settings", constants.AlertSettingsRefreshInterval, alertService.ApplySettings)
+	}
+
 	// create handlers
-	handler := api.NewHandler(postgresDb, redisCache)
+	handler := api.NewHandler(postgresDb, redisCache, blobStore, ocrProvider, enrichmentProvider, geocodeProvider, mailSender, alertMonitor)
 	slog.Info("API handlers initialized")
 
-	// routing
+	// listeners, each serves a part of the routes on a TCP address or a Unix domain socket with its own middlewares
+	listeners, err := server.Load()
+	if err != nil {
+		slog.Error("Invalid listener configuration", "error", err)
+		os.Exit(1)
+	}
+	routers := make([]http.Handler, len(listeners))
+	for i, listener := range listeners {
+		routers[i] = newRouter(handler, listener)
+	}
+
+	// start server
+	if err := server.Serve(listeners, routers); err != nil {
+		slog.Error("Failed to start server", "error", err)
+		os.Exit(1)
+	}
+}
+
+// newRouter builds the router of a listener with the routes it serves
+func newRouter(handler *api.Handler, listener server.Listener) *gin.Engine {
 	router := gin.Default()
 
-	// public endpoints
-	router.POST("/users", handler.CreateUser)
-	router.POST("/login", handler.Login)
+	// X-Forwarded-For and X-Real-IP are only believed when sent by a trusted proxy, otherwise any client could pick
+	// the IP that rate limits and audit logs see. Without TRUSTED_PROXIES the client IP is the peer address
+	if err := router.SetTrustedProxies(listener.TrustedProxies); err != nil {
+		slog.Error("Invalid trusted proxies, trusting no proxy", "listener", listener.Name, "error", err)
+		router.SetTrustedProxies(nil)
+	}
+	if len(listener.Allow) > 0 {
+		router.Use(middlewares.AllowNetworks(listener.Allow))
+	}
+
+	// every endpoint is declared in api.Routes, which also generates the OpenAPI document and clients
+	api.RegisterRoutes(router, handler, api.RouteOptions{
+		Access:             listener.Access,
+		RateLimitPerMinute: listener.RateLimitPerMinute,
+		RouteRateLimits:    listener.RouteRateLimits,
+		RequestTimeout:     utils.GetEnvDurationOrDefault("REQUEST_TIMEOUT", constants.DefaultRequestTimeout),
+	})
+	return router
+}
+
+// adminUsage lists the admin commands
+const adminUsage = `usage: main admin validate-data [-user ID] [-fix] [-json]
+       main admin reindex-search|normalize-phones|recount [-json]
+       main admin warm-cache [-top N] [-json]
+       main admin grant-admin [-revoke] [EMAIL...]`
 
-	// protected endpoints (contacts)
-	protectedRoutes := router.Group("/")
-	protectedRoutes.Use(middlewares.AuthenticateJWT())
-	{
-		protectedRoutes.GET("/contacts", handler.GetContacts)
-		protectedRoutes.POST("/contacts", handler.CreateContact)
-		protectedRoutes.PATCH("/contacts/:id", handler.UpdateContact)
-		protectedRoutes.DELETE("/contacts/:id", handler.DeleteContact)
+// runAdmin runs an admin command and returns the exit code of the process
+func runAdmin(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, adminUsage)
+		return 2
 	}
+	switch args[0] {
+	case "validate-data":
+		return runValidateData(args[1:])
+	case "grant-admin":
+		return runGrantAdmin(args[1:])
+	case constants.MaintenanceReindexSearch, constants.MaintenanceNormalizePhones, constants.MaintenanceRecount,
+		constants.MaintenanceWarmCache:
+		return runMaintenance(args[0], args[1:])
+	}
+	fmt.Fprintln(os.Stderr, adminUsage)
+	return 2
+}
 
-	port := utils.GetEnvOrDefault("PORT", "8080")
-	router.Run(port)
-	slog.Info("Server started on port", "port", port)
-	// start server
-	if err := router.Run(); err != nil {
-		slog.Error("Failed to start server", "error", err)
+// runGrantAdmin grants the admin role to the accounts of the emails given, or of ADMIN_EMAILS when none is given,
+// and revokes it with -revoke. Accounts must exist, signing up never grants the role
+func runGrantAdmin(args []string) int {
+	flags := flag.NewFlagSet("grant-admin", flag.ContinueOnError)
+	revoke := flags.Bool("revoke", false, "revoke the admin role instead of granting it")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	emails := flags.Args()
+	if len(emails) == 0 {
+		for _, email := range strings.Split(utils.GetEnvOrDefault("ADMIN_EMAILS", ""), ",") {
+			if email = strings.TrimSpace(email); email != "" {
+				emails = append(emails, email)
+			}
+		}
+	}
+	if len(emails) == 0 {
+		fmt.Fprintln(os.Stderr, "grant-admin: no email given and ADMIN_EMAILS is empty")
+		return 2
+	}
+
+	postgresDb := db.Init()
+	defer postgresDb.Close()
+	userService := service.NewUserService(postgresDb)
+	status := 0
+	for _, email := range emails {
+		user, err := userService.SetAdmin(email, !*revoke)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "grant-admin: %s: %v\n", email, err)
+			status = 1
+			continue
+		}
+		if *revoke {
+			fmt.Printf("%s (user %d) is no longer an admin\n", user.Email, user.ID)
+		} else {
+			fmt.Printf("%s (user %d) is an admin\n", user.Email, user.ID)
+		}
+	}
+	return status
+}
+
+// runValidateData scans the contacts for malformed data and repairs it with -fix
+func runValidateData(args []string) int {
+	flags := flag.NewFlagSet("validate-data", flag.ContinueOnError)
+	userID := flags.Int("user", 0, "only scan the contacts of this user")
+	fix := flags.Bool("fix", false, "repair the fields that can be repaired automatically")
+	asJSON := flags.Bool("json", false, "print the report as JSON")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	postgresDb := db.Init()
+	defer postgresDb.Close()
+	if *fix {
+		// fixed contacts leave the cached contact pages stale, the API drops them through the event bus
+		redisCache := redis.InitRedis()
+		events.Init(redisCache)
+		events.Subscribe(service.InvalidateContactsCache(redisCache), events.ContactEvents...)
+		events.Subscribe(service.CountContactChanges(redisCache), events.ContactEvents...)
+	}
+
+	report, err := service.NewDataValidationService(postgresDb).ValidateData(*userID, *fix)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "validate-data: %v\n", err)
+		if report == nil {
+			return 1
+		}
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(report)
+	} else {
+		printDataValidationReport(report)
+	}
+	if err != nil {
+		return 1
+	}
+	return 0
+}
+
+// runMaintenance runs a maintenance operation rebuilding derived data until it is done, the admin API runs the same
+// operations as background jobs
+func runMaintenance(operation string, args []string) int {
+	flags := flag.NewFlagSet(operation, flag.ContinueOnError)
+	var top int
+	if operation == constants.MaintenanceWarmCache {
+		flags.IntVar(&top, "top", constants.DefaultWarmCacheUsers, "number of most active users to warm the caches of")
+	}
+	asJSON := flags.Bool("json", false, "print the result as JSON")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	postgresDb := db.Init()
+	defer postgresDb.Close()
+	// rebuilt data leaves cached listings and totals stale, they are dropped from the cache the API reads
+	redisCache := redis.InitRedis()
+
+	start := time.Now()
+	result, err := service.NewMaintenanceService(postgresDb, redisCache).Run(operation, top)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", operation, err)
+		if result == nil {
+			return 1
+		}
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(result)
+	} else {
+		fmt.Printf("%s: %d scanned, %d updated, %d users' caches refreshed in %s\n", result.Operation, result.Scanned,
+			result.Updated, result.Users, time.Since(start).Round(time.Millisecond))
+	}
+	if err != nil {
+		return 1
+	}
+	return 0
+}
+
+// runConfig runs a config command and returns the exit code of the process, 1 when the configuration is broken
+func runConfig(args []string) int {
+	if len(args) == 0 || args[0] != "check" {
+		fmt.Fprintln(os.Stderr, "usage: main config check [-probe] [-json] [-data-dir DIR]")
+		return 2
+	}
+
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	probe := flags.Bool("probe", false, "also test the connections to Postgres, Redis, SMTP and the blob store")
+	asJSON := flags.Bool("json", false, "print the report as JSON")
+	dataDir := flags.String("data-dir", utils.GetEnvOrDefault("DATA_DIR", "./data"), "data directory the server would be started with")
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	report := config.Check(*dataDir)
+	if *probe {
+		report.RunProbes(utils.GetEnvOrDefault("BLOB_DIR", filepath.Join(*dataDir, "blobs")))
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(report)
+	} else {
+		printConfigReport(report)
+	}
+	if report.Failed() {
+		return 1
+	}
+	return 0
+}
+
+// printConfigReport prints the effective configuration by group, then the problems and the probes
+func printConfigReport(report *config.Report) {
+	out := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	group := ""
+	for _, value := range report.Values {
+		if value.Group != group {
+			group = value.Group
+			fmt.Fprintf(out, "\n# %s\n", group)
+		}
+		shown := value.Value
+		if shown == "" {
+			shown = "-"
+		}
+		annotation := value.Description
+		if value.Error != "" {
+			annotation = "ERROR: " + value.Error
+		}
+		fmt.Fprintf(out, "%s\t%s\t(%s)\t# %s\n", value.Name, shown, value.Source, annotation)
+	}
+	out.Flush()
+
+	errorCount := 0
+	fmt.Println()
+	for _, problem := range report.Problems {
+		if problem.Severity == "error" {
+			errorCount++
+		}
+		fmt.Printf("%s: %s: %s\n", problem.Severity, problem.Setting, problem.Message)
+	}
+	fmt.Printf("%d settings, %d problems (%d errors)\n", len(report.Values), len(report.Problems), errorCount)
+
+	if len(report.Probes) > 0 {
+		fmt.Println()
+		out = tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(out, "PROBE\tRESULT\tDURATION")
+		for _, probe := range report.Probes {
+			result := "ok"
+			switch {
+			case probe.Skipped:
+				result, probe.Duration = "skipped (not configured)", "-"
+			case !probe.OK:
+				result = "FAILED: " + probe.Error
+			}
+			fmt.Fprintf(out, "%s\t%s\t%s\n", probe.Name, result, probe.Duration)
+		}
+		out.Flush()
+	}
+}
+
+// printDataValidationReport prints an issue per line then the counts by kind
+func printDataValidationReport(report *dtos.DataValidationReportDto) {
+	out := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(out, "CONTACT\tUSER\tFIELD\tKIND\tVALUE\tFIX")
+	for _, issue := range report.Issues {
+		fixed := issue.Fix
+		switch {
+		case fixed == "":
+			fixed = "-"
+		case issue.Fixed:
+			fixed = strconv.Quote(fixed) + " (fixed)"
+		default:
+			fixed = strconv.Quote(fixed)
+		}
+		fmt.Fprintf(out, "%d\t%d\t%s\t%s\t%q\t%s\n", issue.ContactID, issue.UserID, issue.Field, issue.Kind, issue.Value, fixed)
+	}
+	out.Flush()
+
+	kinds := make([]string, 0, len(report.ByKind))
+	for kind, count := range report.ByKind {
+		kinds = append(kinds, fmt.Sprintf("%s: %d", kind, count))
 	}
+	sort.Strings(kinds)
+	fmt.Printf("\n%d contacts scanned, %d issues (%s), %d fixed\n", report.Scanned, len(report.Issues), strings.Join(kinds, ", "), report.Fixed)
 }
@@ -0,0 +1,40 @@
+// Package accountstate is the state machine of user accounts. Accounts are created active; deactivating one freezes
+// it, its data is kept but nobody can use it, until it is reactivated from a link sent to its email.
+package accountstate
+
+import (
+	"fmt"
+
+	"github.com/danizion/contact-app/internal/constants"
+)
+
+// States of an account
+const (
+	Active      = "active"
+	Deactivated = "deactivated"
+)
+
+// Events moving an account from a state to another
+const (
+	Deactivate = "deactivate"
+	Reactivate = "reactivate"
+)
+
+// transitions maps each state to the events allowed in it and the state they lead to
+var transitions = map[string]map[string]string{
+	Active:      {Deactivate: Deactivated},
+	Deactivated: {Reactivate: Active},
+}
+
+// Next returns the state an account in state moves to on event, an error when event is not allowed in state
+func Next(state, event string) (string, error) {
+	if next, ok := transitions[state][event]; ok {
+		return next, nil
+	}
+	return "", fmt.Errorf("%s: cannot %s an account that is %s", constants.ErrInvalidAccountTransition, event, state)
+}
+
+// CanUseAPI tells whether the users of accounts in state can log in and use the API
+func CanUseAPI(state string) bool {
+	return state == Active
+}
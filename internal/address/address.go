@@ -0,0 +1,156 @@
+package address
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Address is a structured postal address
+type Address struct {
+	Street      string
+	City        string
+	Region      string
+	PostalCode  string
+	CountryCode string
+}
+
+// IsEmpty reports whether no structured field is set
+func (a Address) IsEmpty() bool {
+	return a.Street == "" && a.City == "" && a.Region == "" && a.PostalCode == "" && a.CountryCode == ""
+}
+
+// Per-country layouts, one entry per line, placeholders are replaced by the address fields and empty parts dropped.
+// Countries without an entry use the default layout.
+var layouts = map[string][]string{
+	"US": {"{street}", "{city}, {region} {postal}"},
+	"CA": {"{street}", "{city} {region} {postal}"},
+	"AU": {"{street}", "{city} {region} {postal}"},
+	"GB": {"{street}", "{city}", "{region}", "{postal}"},
+	"IE": {"{street}", "{city}", "{region}", "{postal}"},
+	"DE": {"{street}", "{postal} {city}"},
+	"AT": {"{street}", "{postal} {city}"},
+	"CH": {"{street}", "{postal} {city}"},
+	"FR": {"{street}", "{postal} {city}"},
+	"ES": {"{street}", "{postal} {city} {region}"},
+	"IT": {"{street}", "{postal} {city} {region}"},
+	"NL": {"{street}", "{postal} {city}"},
+	"IL": {"{street}", "{city} {postal}"},
+	"JP": {"〒{postal}", "{region}{city}", "{street}"},
+	"CN": {"{postal}", "{region}{city}", "{street}"},
+	"BR": {"{street}", "{city} - {region}", "{postal}"},
+	"IN": {"{street}", "{city} {postal}", "{region}"},
+}
+
+var defaultLayout = []string{"{street}", "{postal} {city}", "{region}"}
+
+// Postal code formats of the countries validated strictly, other countries accept any short alphanumeric code
+var postalCodePatterns = map[string]*regexp.Regexp{
+	"US": regexp.MustCompile(`^\d{5}(-\d{4})?$`),
+	"CA": regexp.MustCompile(`^[A-Za-z]\d[A-Za-z] ?\d[A-Za-z]\d$`),
+	"GB": regexp.MustCompile(`^(?i)[A-Z]{1,2}\d[A-Z\d]? ?\d[A-Z]{2}$`),
+	"DE": regexp.MustCompile(`^\d{5}$`),
+	"FR": regexp.MustCompile(`^\d{5}$`),
+	"ES": regexp.MustCompile(`^\d{5}$`),
+	"IT": regexp.MustCompile(`^\d{5}$`),
+	"AT": regexp.MustCompile(`^\d{4}$`),
+	"CH": regexp.MustCompile(`^\d{4}$`),
+	"AU": regexp.MustCompile(`^\d{4}$`),
+	"NL": regexp.MustCompile(`^\d{4} ?[A-Za-z]{2}$`),
+	"IL": regexp.MustCompile(`^\d{7}$`),
+	"JP": regexp.MustCompile(`^\d{3}-?\d{4}$`),
+	"BR": regexp.MustCompile(`^\d{5}-?\d{3}$`),
+	"IN": regexp.MustCompile(`^\d{6}$`),
+	"CN": regexp.MustCompile(`^\d{6}$`),
+}
+
+var genericPostalCode = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9 \-]{1,9}$`)
+
+// ISO 3166-1 alpha-2 country codes
+var countryCodes = make(map[string]bool)
+
+func init() {
+	for _, code := range strings.Fields(isoCountryCodes) {
+		countryCodes[code] = true
+	}
+}
+
+// NormalizeCountryCode upper-cases a country code and checks it is a valid ISO 3166-1 alpha-2 code
+func NormalizeCountryCode(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if !countryCodes[code] {
+		return "", fmt.Errorf("unknown country code %q", code)
+	}
+	return code, nil
+}
+
+// ValidatePostalCode checks a postal code against the format of its country
+func ValidatePostalCode(countryCode, postalCode string) error {
+	if postalCode == "" {
+		return nil
+	}
+	pattern, ok := postalCodePatterns[countryCode]
+	if !ok {
+		pattern = genericPostalCode
+	}
+	if !pattern.MatchString(postalCode) {
+		return fmt.Errorf("postal code %q is not valid for %s", postalCode, countryCode)
+	}
+	return nil
+}
+
+// Validate checks the country code and the postal code of an address
+func Validate(a Address) error {
+	if a.CountryCode == "" {
+		if a.PostalCode != "" {
+			return fmt.Errorf("country_code is required to validate the postal code")
+		}
+		return nil
+	}
+	if _, err := NormalizeCountryCode(a.CountryCode); err != nil {
+		return err
+	}
+	return ValidatePostalCode(strings.ToUpper(a.CountryCode), a.PostalCode)
+}
+
+// Format renders an address following the conventions of its country, one line per element of the result
+func Format(a Address) []string {
+	layout, ok := layouts[strings.ToUpper(a.CountryCode)]
+	if !ok {
+		layout = defaultLayout
+	}
+
+	replacer := strings.NewReplacer(
+		"{street}", a.Street,
+		"{city}", a.City,
+		"{region}", a.Region,
+		"{postal}", a.PostalCode,
+	)
+
+	var lines []string
+	for _, line := range layout {
+		formatted := strings.Join(strings.Fields(replacer.Replace(line)), " ")
+		formatted = strings.Trim(formatted, " ,-")
+		if formatted != "" && formatted != "〒" {
+			lines = append(lines, formatted)
+		}
+	}
+	if a.CountryCode != "" {
+		lines = append(lines, strings.ToUpper(a.CountryCode))
+	}
+	return lines
+}
+
+// FormatSingleLine renders an address on a single line, used as the free-text address of a contact
+func FormatSingleLine(a Address) string {
+	return strings.Join(Format(a), ", ")
+}
+
+const isoCountryCodes = `
+AD AE AF AG AI AL AM AO AQ AR AS AT AU AW AX AZ BA BB BD BE BF BG BH BI BJ BL BM BN BO BQ BR BS BT BV BW BY BZ
+CA CC CD CF CG CH CI CK CL CM CN CO CR CU CV CW CX CY CZ DE DJ DK DM DO DZ EC EE EG EH ER ES ET FI FJ FK FM FO FR
+GA GB GD GE GF GG GH GI GL GM GN GP GQ GR GS GT GU GW GY HK HM HN HR HT HU ID IE IL IM IN IO IQ IR IS IT JE JM JO
+JP KE KG KH KI KM KN KP KR KW KY KZ LA LB LC LI LK LR LS LT LU LV LY MA MC MD ME MF MG MH MK ML MM MN MO MP MQ MR
+MS MT MU MV MW MX MY MZ NA NC NE NF NG NI NL NO NP NR NU NZ OM PA PE PF PG PH PK PL PM PN PR PS PT PW PY QA RE RO
+RS RU RW SA SB SC SD SE SG SH SI SJ SK SL SM SN SO SR SS ST SV SX SY SZ TC TD TF TG TH TJ TK TL TM TN TO TR TT TV
+TW TZ UA UG UM US UY UZ VA VC VE VG VI VN VU WF WS YE YT ZA ZM ZW`
@@ -0,0 +1,184 @@
+// Package alerting enforces an error budget per route: when the share of 5xx responses of a route exceeds a
+// threshold within a window, an alert is fired through the notifier, then suppressed for that route for a while
+package alerting
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/metrics"
+	"github.com/danizion/contact-app/internal/notify"
+	"github.com/danizion/contact-app/internal/utils"
+)
+
+// AlertsFired counts the alerts fired by route
+var AlertsFired = metrics.NewCounter("alerts_fired")
+
+// Config is the error budget applied to every route
+type Config struct {
+	// Threshold is the share of 5xx responses (0.05 is 5%) above which a route alerts
+	Threshold float64
+	// MinRequests is the number of requests a window needs before its error rate is considered
+	MinRequests int
+	Window      time.Duration
+	// Suppression is how long a route stays silent after alerting, unless overridden for the route
+	Suppression time.Duration
+}
+
+// LoadConfig reads ALERT_5XX_THRESHOLD, ALERT_MIN_REQUESTS, ALERT_WINDOW and ALERT_SUPPRESSION, invalid values
+// fall back to the defaults
+func LoadConfig() Config {
+	config := Config{
+		Threshold:   constants.DefaultAlertThreshold,
+		MinRequests: utils.GetEnvIntOrDefault("ALERT_MIN_REQUESTS", constants.DefaultAlertMinRequests),
+		Window:      constants.DefaultAlertWindow,
+		Suppression: constants.DefaultAlertSuppression,
+	}
+	if threshold, err := strconv.ParseFloat(utils.GetEnvOrDefault("ALERT_5XX_THRESHOLD", ""), 64); err == nil && threshold > 0 && threshold <= 1 {
+		config.Threshold = threshold
+	}
+	if window, err := time.ParseDuration(utils.GetEnvOrDefault("ALERT_WINDOW", "")); err == nil && window > 0 {
+		config.Window = window
+	}
+	if suppression, err := time.ParseDuration(utils.GetEnvOrDefault("ALERT_SUPPRESSION", "")); err == nil && suppression > 0 {
+		config.Suppression = suppression
+	}
+	return config
+}
+
+// RouteStatus is the current window of a route
+type RouteStatus struct {
+	Route           string
+	Requests        int
+	Errors          int
+	SuppressedUntil time.Time
+}
+
+type routeState struct {
+	windowStart     time.Time
+	requests        int
+	errors          int
+	suppressedUntil time.Time
+}
+
+// Monitor counts the responses of every route over fixed windows and alerts when a route exceeds the error budget
+type Monitor struct {
+	config   Config
+	notifier notify.Notifier
+
+	mu           sync.Mutex
+	routes       map[string]*routeState
+	suppressions map[string]time.Duration
+}
+
+// NewMonitor creates a monitor alerting through notifier
+func NewMonitor(config Config, notifier notify.Notifier) *Monitor {
+	return &Monitor{
+		config:       config,
+		notifier:     notifier,
+		routes:       make(map[string]*routeState),
+		suppressions: make(map[string]time.Duration),
+	}
+}
+
+// Config returns the error budget of the monitor
+func (m *Monitor) Config() Config {
+	return m.config
+}
+
+// Record counts a response of route ("GET /contacts"), alerting when it makes the route exceed the error budget
+func (m *Monitor) Record(route string, status int) {
+	now := time.Now()
+
+	m.mu.Lock()
+	state, ok := m.routes[route]
+	if !ok {
+		state = &routeState{windowStart: now}
+		m.routes[route] = state
+	}
+	if now.Sub(state.windowStart) >= m.config.Window {
+		state.windowStart, state.requests, state.errors = now, 0, 0
+	}
+	state.requests++
+	if status >= 500 {
+		state.errors++
+	}
+
+	fire := status >= 500 && state.requests >= m.config.MinRequests &&
+		float64(state.errors)/float64(state.requests) > m.config.Threshold && !now.Before(state.suppressedUntil)
+	var alert notify.Alert
+	if fire {
+		state.suppressedUntil = now.Add(m.suppression(route))
+		alert = notify.Alert{
+			Key:   "5xx:" + route,
+			Title: fmt.Sprintf("High 5xx rate on %s", route),
+			Message: fmt.Sprintf("%d of the last %d requests to %s failed with a 5xx status (%.1f%%, budget %.1f%%) since %s. "+
+				"Further alerts for this route are suppressed until %s.",
+				state.errors, state.requests, route, 100*float64(state.errors)/float64(state.requests), 100*m.config.Threshold,
+				state.windowStart.UTC().Format(time.RFC3339), state.suppressedUntil.UTC().Format(time.RFC3339)),
+			FiredAt: now.UTC(),
+		}
+	}
+	m.mu.Unlock()
+
+	if fire {
+		AlertsFired.Inc(route)
+		// Notifiers call external services, never hold up the response
+		go func() {
+			if err := m.notifier.Notify(alert); err != nil {
+				slog.Error("Failed to send alert", "error", err, "route", route)
+			}
+		}()
+	}
+}
+
+// suppression returns the suppression window of a route, m.mu must be held
+func (m *Monitor) suppression(route string) time.Duration {
+	if suppression, ok := m.suppressions[route]; ok {
+		return suppression
+	}
+	return m.config.Suppression
+}
+
+// SetSuppressions replaces the per route suppression windows, routes missing from the map use the default
+func (m *Monitor) SetSuppressions(suppressions map[string]time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.suppressions = suppressions
+
+	// A shorter window takes effect right away for routes currently suppressed
+	now := time.Now()
+	for route, state := range m.routes {
+		if latest := now.Add(m.suppression(route)); state.suppressedUntil.After(latest) {
+			state.suppressedUntil = latest
+		}
+	}
+}
+
+// Suppressions returns a copy of the per route suppression windows
+func (m *Monitor) Suppressions() map[string]time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	suppressions := make(map[string]time.Duration, len(m.suppressions))
+	for route, suppression := range m.suppressions {
+		suppressions[route] = suppression
+	}
+	return suppressions
+}
+
+// Status returns the current window of every route that received requests, ordered by route
+func (m *Monitor) Status() []RouteStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	statuses := make([]RouteStatus, 0, len(m.routes))
+	for route, state := range m.routes {
+		statuses = append(statuses, RouteStatus{Route: route, Requests: state.requests, Errors: state.errors, SuppressedUntil: state.suppressedUntil})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Route < statuses[j].Route })
+	return statuses
+}
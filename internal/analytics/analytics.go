@@ -0,0 +1,195 @@
+// Package analytics records anonymized product events (signups, imports, exports, searches). Events carry no user
+// ID, contact data or search terms: the actor is a keyed hash of the user ID, so events can be counted per distinct
+// user without telling who the user is. Nothing is recorded unless a sink is configured.
+package analytics
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"log/slog"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/danizion/contact-app/internal/metrics"
+	"github.com/danizion/contact-app/internal/utils"
+)
+
+// Product events
+const (
+	EventSignup = "signup"
+	EventImport = "import_used"
+	EventExport = "export_used"
+	EventSearch = "search_used"
+)
+
+const (
+	bufferSize    = 1024
+	batchSize     = 100
+	flushInterval = 5 * time.Second
+)
+
+// Event is an anonymized product event, Properties only holds enumerated values such as the kind of import
+type Event struct {
+	Name       string            `json:"name"`
+	Actor      string            `json:"actor"`
+	Properties map[string]string `json:"properties,omitempty"`
+	OccurredAt time.Time         `json:"occurred_at"`
+}
+
+// Sink stores batches of events
+type Sink interface {
+	Write(events []Event) error
+}
+
+// Events counts analytics events by outcome (recorded, dropped, failed)
+var Events = metrics.NewCounter("analytics_events")
+
+// Emitter batches events and writes them to its sink in the background, events are dropped rather than slowing
+// requests down when the sink falls behind
+type Emitter struct {
+	sink    Sink
+	salt    []byte
+	enabled atomic.Bool
+	events  chan Event
+}
+
+// NewEmitter creates an emitter writing to sink and starts its background writer. salt keys the actor hash, the same
+// salt must be used across restarts and replicas for actors to stay comparable
+func NewEmitter(sink Sink, salt []byte) *Emitter {
+	e := &Emitter{
+		sink:   sink,
+		salt:   salt,
+		events: make(chan Event, bufferSize),
+	}
+	e.enabled.Store(true)
+	go e.run()
+	return e
+}
+
+// SetEnabled turns recording on or off, it is the instance wide opt-out
+func (e *Emitter) SetEnabled(enabled bool) {
+	e.enabled.Store(enabled)
+}
+
+// Enabled reports whether events are recorded
+func (e *Emitter) Enabled() bool {
+	return e.enabled.Load()
+}
+
+// Track records that userID did something, it never blocks
+func (e *Emitter) Track(name string, userID int, properties map[string]string) {
+	if !e.Enabled() {
+		return
+	}
+	event := Event{Name: name, Actor: e.actor(userID), Properties: properties, OccurredAt: time.Now().UTC()}
+	select {
+	case e.events <- event:
+	default:
+		Events.Inc("dropped")
+	}
+}
+
+// actor is the pseudonym of a user in the events
+func (e *Emitter) actor(userID int) string {
+	mac := hmac.New(sha256.New, e.salt)
+	mac.Write([]byte(strconv.Itoa(userID)))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+func (e *Emitter) run() {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Event, 0, batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := e.sink.Write(batch); err != nil {
+			slog.Error("Failed to write analytics events", "error", err, "count", len(batch))
+			Events.Add("failed", int64(len(batch)))
+		} else {
+			Events.Add("recorded", int64(len(batch)))
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case event := <-e.events:
+			batch = append(batch, event)
+			if len(batch) == batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+var defaultEmitter atomic.Pointer[Emitter]
+
+// Init creates the emitter configured by ANALYTICS_SINK ("postgres", or "http" with ANALYTICS_URL and
+// ANALYTICS_API_KEY) and ANALYTICS_SALT, and makes it the one used by Track. Returns nil when analytics are not configured
+func Init(db *sql.DB) *Emitter {
+	var sink Sink
+	switch kind := utils.GetEnvOrDefault("ANALYTICS_SINK", ""); kind {
+	case "":
+		return nil
+	case "postgres":
+		sink = NewPostgresSink(db)
+	case "http":
+		sink = NewHTTPSink(utils.GetEnvOrDefault("ANALYTICS_URL", ""), utils.GetEnvOrDefault("ANALYTICS_API_KEY", ""))
+	default:
+		slog.Error("Unknown ANALYTICS_SINK, analytics disabled", "sink", kind)
+		return nil
+	}
+
+	salt := []byte(utils.GetEnvOrDefault("ANALYTICS_SALT", ""))
+	if len(salt) == 0 {
+		// Without a shared salt actors change on every restart and differ between replicas
+		slog.Warn("ANALYTICS_SALT not set, using a random salt")
+		salt = make([]byte, 32)
+		if _, err := rand.Read(salt); err != nil {
+			slog.Error("Failed to generate analytics salt, analytics disabled", "error", err)
+			return nil
+		}
+	}
+
+	// Recording starts once the saved opt-out setting is applied with SetEnabled
+	emitter := NewEmitter(sink, salt)
+	emitter.SetEnabled(false)
+	defaultEmitter.Store(emitter)
+	return emitter
+}
+
+// Track records an event on the emitter created by Init, it does nothing when analytics are not configured
+func Track(name string, userID int, properties map[string]string) {
+	if emitter := defaultEmitter.Load(); emitter != nil {
+		emitter.Track(name, userID, properties)
+	}
+}
+
+// Configured reports whether Init created an emitter
+func Configured() bool {
+	return defaultEmitter.Load() != nil
+}
+
+// Buffered returns the number of events waiting to be written by the emitter created by Init
+func Buffered() int {
+	if emitter := defaultEmitter.Load(); emitter != nil {
+		return len(emitter.events)
+	}
+	return 0
+}
+
+// SetEnabled turns recording on or off on the emitter created by Init
+func SetEnabled(enabled bool) {
+	if emitter := defaultEmitter.Load(); emitter != nil {
+		emitter.SetEnabled(enabled)
+	}
+}
@@ -0,0 +1,83 @@
+package analytics
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/danizion/contact-app/internal/repository"
+)
+
+// PostgresSink stores events in the analytics_events table, where the admin stats read them
+type PostgresSink struct {
+	repo *repository.Repository
+}
+
+// NewPostgresSink creates a new instance of PostgresSink
+func NewPostgresSink(db *sql.DB) *PostgresSink {
+	return &PostgresSink{repo: repository.NewRepository(db)}
+}
+
+// Write inserts a batch of events
+func (s *PostgresSink) Write(events []Event) error {
+	rows := make([]models.AnalyticsEvent, len(events))
+	for i, event := range events {
+		properties, err := json.Marshal(event.Properties)
+		if err != nil {
+			return fmt.Errorf("failed to encode event properties: %w", err)
+		}
+		rows[i] = models.AnalyticsEvent{
+			Name:       event.Name,
+			Actor:      event.Actor,
+			Properties: string(properties),
+			OccurredAt: event.OccurredAt,
+		}
+	}
+	return s.repo.CreateAnalyticsEvents(rows)
+}
+
+// HTTPSink posts batches of events to an external collector as {"events": [...]}
+type HTTPSink struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+// NewHTTPSink creates a new instance of HTTPSink
+func NewHTTPSink(endpoint, apiKey string) *HTTPSink {
+	return &HTTPSink{
+		url:    endpoint,
+		apiKey: apiKey,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Write posts a batch of events, any status outside 2xx is a failure
+func (s *HTTPSink) Write(events []Event) error {
+	body, err := json.Marshal(map[string][]Event{"events": events})
+	if err != nil {
+		return fmt.Errorf("failed to encode events: %w", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("analytics request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("analytics collector returned %d", resp.StatusCode)
+	}
+	return nil
+}
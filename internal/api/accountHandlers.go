@@ -0,0 +1,198 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/gin-gonic/gin"
+)
+
+// GetProfile handles GET requests for the account of the current user
+func (h *Handler) GetProfile(c *gin.Context) {
+	userID := h.getUserID(c)
+
+	result, err := h.accountService.WithContext(c.Request.Context()).GetProfile(userID)
+	if err != nil {
+		slog.Error("Failed to get profile", "error", err, "userID", userID)
+		respondError(c, err, "Failed to get profile")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// RequestEmailChange handles POST requests changing the email of the current user, the change is pending until
+// confirmed from both addresses
+func (h *Handler) RequestEmailChange(c *gin.Context) {
+	var req dtos.RequestEmailChangeDto
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid email change request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.UserID = h.getUserID(c)
+	req.ClientIP = c.ClientIP()
+
+	result, err := h.accountService.WithContext(c.Request.Context()).RequestEmailChange(req)
+	if err != nil {
+		slog.Error("Failed to request email change", "error", err, "userID", req.UserID)
+		respondError(c, err, "Failed to request email change")
+		return
+	}
+
+	slog.Info("Email change requested", "userID", req.UserID)
+	c.JSON(http.StatusAccepted, result)
+}
+
+// CancelEmailChange handles DELETE requests dropping the pending email change of the current user
+func (h *Handler) CancelEmailChange(c *gin.Context) {
+	userID := h.getUserID(c)
+
+	if err := h.accountService.WithContext(c.Request.Context()).CancelEmailChange(userID); err != nil {
+		respondError(c, err, "Failed to cancel email change")
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.MessageResponseDto{Message: "Email change cancelled"})
+}
+
+// ConfirmEmailChange handles the confirmation links emailed for an email change, they authenticate with their token
+func (h *Handler) ConfirmEmailChange(c *gin.Context) {
+	result, err := h.accountService.WithContext(c.Request.Context()).ConfirmEmailChange(c.Query("token"))
+	if err != nil {
+		respondError(c, err, "Failed to confirm email change")
+		return
+	}
+
+	if result.Completed {
+		slog.Info("Email changed", "email", result.Email)
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// ChangeUsername handles PUT requests renaming the current user
+func (h *Handler) ChangeUsername(c *gin.Context) {
+	var req dtos.ChangeUsernameRequestDto
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid username change request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.UserID = h.getUserID(c)
+	req.ClientIP = c.ClientIP()
+
+	result, err := h.accountService.WithContext(c.Request.Context()).ChangeUsername(req)
+	if err != nil {
+		slog.Error("Failed to change username", "error", err, "userID", req.UserID)
+		respondError(c, err, "Failed to change username")
+		return
+	}
+
+	slog.Info("Username changed", "userID", req.UserID)
+	c.JSON(http.StatusOK, result)
+}
+
+// LookupUsername handles GET requests finding the account of a username, former usernames lead to their account
+func (h *Handler) LookupUsername(c *gin.Context) {
+	result, err := h.userService.WithContext(c.Request.Context()).LookupUsername(c.Param("username"))
+	if err != nil {
+		respondError(c, err, "Failed to look up username")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// UpdateProfile handles PATCH requests changing the username and the email of the current user, the email change
+// is pending until confirmed from both addresses
+func (h *Handler) UpdateProfile(c *gin.Context) {
+	var req dtos.UpdateProfileRequestDto
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid profile update request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.UserID = h.getUserID(c)
+	req.ClientIP = c.ClientIP()
+
+	result, err := h.accountService.WithContext(c.Request.Context()).UpdateProfile(req)
+	if err != nil {
+		slog.Error("Failed to update profile", "error", err, "userID", req.UserID)
+		respondError(c, err, "Failed to update profile")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// DeleteAccount handles DELETE requests deleting the current user with everything it owns
+func (h *Handler) DeleteAccount(c *gin.Context) {
+	var req dtos.DeleteAccountRequestDto
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid account deletion request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.UserID = h.getUserID(c)
+	req.ClientIP = c.ClientIP()
+
+	if err := h.accountService.WithContext(c.Request.Context()).DeleteAccount(req); err != nil {
+		slog.Error("Failed to delete account", "error", err, "userID", req.UserID)
+		respondError(c, err, "Failed to delete account")
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.MessageResponseDto{Message: "Account deleted"})
+}
+
+// DeactivateAccount handles POST requests freezing the current user until it is reactivated from a link sent by email
+func (h *Handler) DeactivateAccount(c *gin.Context) {
+	var req dtos.DeactivateAccountRequestDto
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid account deactivation request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.UserID = h.getUserID(c)
+	req.ClientIP = c.ClientIP()
+
+	if err := h.accountStateService.WithContext(c.Request.Context()).Deactivate(req); err != nil {
+		slog.Error("Failed to deactivate account", "error", err, "userID", req.UserID)
+		respondError(c, err, "Failed to deactivate account")
+		return
+	}
+
+	slog.Info("Account deactivated", "userID", req.UserID)
+	c.JSON(http.StatusOK, dtos.MessageResponseDto{Message: "Account deactivated"})
+}
+
+// RequestReactivation handles POST requests emailing a reactivation link to a deactivated account. The answer is the
+// same whether the email has a deactivated account or not
+func (h *Handler) RequestReactivation(c *gin.Context) {
+	var req dtos.RequestReactivationDto
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid reactivation request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.accountStateService.WithContext(c.Request.Context()).RequestReactivation(req.Email); err != nil {
+		slog.Error("Failed to request reactivation", "error", err)
+		respondError(c, err, "Failed to request reactivation")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, dtos.MessageResponseDto{Message: "If the account is deactivated, a reactivation link was sent to its email"})
+}
+
+// ConfirmReactivation handles the reactivation links emailed to deactivated accounts, they authenticate with their token
+func (h *Handler) ConfirmReactivation(c *gin.Context) {
+	if err := h.accountStateService.WithContext(c.Request.Context()).Reactivate(c.Query("token")); err != nil {
+		slog.Error("Failed to reactivate account", "error", err)
+		respondError(c, err, "Failed to reactivate account")
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.MessageResponseDto{Message: "Account reactivated, log in again"})
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/gin-gonic/gin"
+)
+
+// ListDuplicateUsers handles admin GET requests listing the accounts that share an email
+func (h *Handler) ListDuplicateUsers(c *gin.Context) {
+	result, err := h.mergeService.WithContext(c.Request.Context()).ListDuplicateUsers()
+	if err != nil {
+		slog.Error("Failed to list duplicate users", "error", err)
+		respondError(c, err, "Failed to list duplicate users")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// MergeUsers handles admin POST requests merging an account into another
+func (h *Handler) MergeUsers(c *gin.Context) {
+	var req dtos.MergeUsersRequestDto
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid merge users request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.AdminID = h.getUserID(c)
+
+	result, err := h.mergeService.WithContext(c.Request.Context()).MergeUsers(req)
+	if err != nil {
+		respondError(c, err, "Failed to merge users")
+		return
+	}
+
+	slog.Info("Users merged", "source", req.SourceUserID, "target", req.TargetUserID, "admin", req.AdminID)
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/gin-gonic/gin"
+)
+
+// GetAlertSettings handles GET requests for the 5xx error budget alerting settings
+func (h *Handler) GetAlertSettings(c *gin.Context) {
+	result, err := h.alertService.WithContext(c.Request.Context()).GetSettings()
+	if err != nil {
+		slog.Error("Failed to get alert settings", "error", err)
+		respondError(c, err, "Failed to get alert settings")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// SetRouteSuppression handles PUT requests setting how long alerts of a route are suppressed after one fires
+func (h *Handler) SetRouteSuppression(c *gin.Context) {
+	var req dtos.SetRouteSuppressionRequestDto
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid set route suppression request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !isRouteKey(req.Route) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": constants.ErrUnknownRoute})
+		return
+	}
+
+	result, err := h.alertService.WithContext(c.Request.Context()).SetRouteSuppression(req)
+	if err != nil {
+		respondError(c, err, "Failed to set route suppression")
+		return
+	}
+
+	slog.Info("Route alert suppression set", "route", req.Route, "suppression", req.Suppression, "adminID", h.getUserID(c))
+	c.JSON(http.StatusOK, result)
+}
+
+// isRouteKey reports whether key names a route of Routes as "METHOD /path", the way alerts identify routes
+func isRouteKey(key string) bool {
+	for _, route := range Routes() {
+		if route.Method+" "+route.Path == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/gin-gonic/gin"
+)
+
+// GetAnalyticsSettings handles GET requests for the instance wide analytics settings
+func (h *Handler) GetAnalyticsSettings(c *gin.Context) {
+	result, err := h.analyticsService.WithContext(c.Request.Context()).GetSettings()
+	if err != nil {
+		slog.Error("Failed to get analytics settings", "error", err)
+		respondError(c, err, "Failed to get analytics settings")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// UpdateAnalyticsSettings handles PUT requests opting the instance in or out of analytics
+func (h *Handler) UpdateAnalyticsSettings(c *gin.Context) {
+	var req dtos.UpdateAnalyticsSettingsRequestDto
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid update analytics settings request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.analyticsService.WithContext(c.Request.Context()).UpdateSettings(req)
+	if err != nil {
+		slog.Error("Failed to update analytics settings", "error", err)
+		respondError(c, err, "Failed to update analytics settings")
+		return
+	}
+
+	slog.Info("Analytics settings updated", "enabled", result.Enabled, "adminID", h.getUserID(c))
+	c.JSON(http.StatusOK, result)
+}
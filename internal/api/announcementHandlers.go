@@ -0,0 +1,97 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateAnnouncement handles admin POST requests posting an announcement to every user
+func (h *Handler) CreateAnnouncement(c *gin.Context) {
+	var req dtos.CreateAnnouncementRequestDto
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid create announcement request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	adminID := h.getUserID(c)
+
+	result, err := h.announcementService.CreateAnnouncement(adminID, req)
+	if err != nil {
+		slog.Error("Failed to create announcement", "error", err, "userID", adminID)
+		respondError(c, err, "Failed to create announcement")
+		return
+	}
+
+	slog.Info("Announcement created", "announcementID", result.ID, "level", result.Level, "userID", adminID)
+	c.JSON(http.StatusCreated, result)
+}
+
+// ListAnnouncements handles admin GET requests listing every announcement, ended and scheduled ones included
+func (h *Handler) ListAnnouncements(c *gin.Context) {
+	result, err := h.announcementService.GetAnnouncements()
+	if err != nil {
+		slog.Error("Failed to get announcements", "error", err)
+		respondError(c, err, "Failed to get announcements")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": result})
+}
+
+// DeleteAnnouncement handles admin DELETE requests removing an announcement
+func (h *Handler) DeleteAnnouncement(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid announcement ID"})
+		return
+	}
+
+	if err := h.announcementService.DeleteAnnouncement(id); err != nil {
+		slog.Error("Failed to delete announcement", "error", err, "announcementID", id)
+		respondError(c, err, "Failed to delete announcement")
+		return
+	}
+
+	slog.Info("Announcement deleted", "announcementID", id, "userID", h.getUserID(c))
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Announcement deleted successfully",
+	})
+}
+
+// GetAnnouncements handles GET requests listing the announcements the current user did not dismiss
+func (h *Handler) GetAnnouncements(c *gin.Context) {
+	userID := h.getUserID(c)
+
+	result, err := h.announcementService.GetUserAnnouncements(userID)
+	if err != nil {
+		slog.Error("Failed to get announcements", "error", err, "userID", userID)
+		respondError(c, err, "Failed to get announcements")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": result})
+}
+
+// DismissAnnouncement handles POST requests hiding an announcement from the current user
+func (h *Handler) DismissAnnouncement(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid announcement ID"})
+		return
+	}
+	userID := h.getUserID(c)
+
+	if err := h.announcementService.DismissAnnouncement(userID, id); err != nil {
+		slog.Error("Failed to dismiss announcement", "error", err, "announcementID", id, "userID", userID)
+		respondError(c, err, "Failed to dismiss announcement")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Announcement dismissed successfully",
+	})
+}
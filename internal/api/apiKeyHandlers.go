@@ -0,0 +1,62 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateAPIKey handles POST requests creating an API key, the response holds its secret
+func (h *Handler) CreateAPIKey(c *gin.Context) {
+	var req dtos.CreateAPIKeyRequestDto
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid create API key request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.UserID = h.getUserID(c)
+
+	result, err := h.apiKeyService.WithContext(c.Request.Context()).CreateAPIKey(req)
+	if err != nil {
+		slog.Error("Failed to create API key", "error", err, "userID", req.UserID)
+		respondError(c, err, "Failed to create API key")
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// ListAPIKeys handles GET requests listing the user's API keys
+func (h *Handler) ListAPIKeys(c *gin.Context) {
+	userID := h.getUserID(c)
+
+	result, err := h.apiKeyService.WithContext(c.Request.Context()).ListAPIKeys(userID)
+	if err != nil {
+		slog.Error("Failed to list API keys", "error", err, "userID", userID)
+		respondError(c, err, "Failed to list API keys")
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.APIKeyListResponseDto{Items: result})
+}
+
+// DeleteAPIKey handles DELETE requests revoking an API key
+func (h *Handler) DeleteAPIKey(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid API key ID"})
+		return
+	}
+	userID := h.getUserID(c)
+
+	if err := h.apiKeyService.WithContext(c.Request.Context()).DeleteAPIKey(userID, id); err != nil {
+		slog.Error("Failed to delete API key", "error", err, "apiKeyID", id)
+		respondError(c, err, "Failed to delete API key")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key deleted successfully"})
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/danizion/contact-app/internal/analytics"
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/export"
+	"github.com/danizion/contact-app/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// ExportAccount handles GET requests downloading the whole account of the current user as an archive, encrypted
+// when a password is given
+func (h *Handler) ExportAccount(c *gin.Context) {
+	userID := h.getUserID(c)
+	password, ok := exportPassword(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.archiveService.WithContext(c.Request.Context()).ExportAccount(userID)
+	if err != nil {
+		slog.Error("Failed to export account", "error", err, "userID", userID)
+		respondError(c, err, "Failed to export account")
+		return
+	}
+
+	analytics.Track(analytics.EventExport, userID, map[string]string{"kind": "account", "encrypted": strconv.FormatBool(password != "")})
+	filename := fmt.Sprintf("%s-%s.json", constants.ArchiveFormat, result.ExportedAt.Format("2006-01-02"))
+	if password == "" {
+		c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+		c.JSON(http.StatusOK, result)
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="`+filename+constants.EncryptedExportExtension+`"`)
+	c.Header("Content-Type", "application/octet-stream")
+	encrypted, err := export.Encrypt(c.Writer, password)
+	if err != nil {
+		slog.Error("Failed to encrypt account export", "error", err, "userID", userID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export account"})
+		return
+	}
+	// The archive is encrypted as it is encoded, a failure midway can only truncate the file
+	c.Status(http.StatusOK)
+	if err := json.NewEncoder(encrypted).Encode(result); err != nil {
+		slog.Error("Failed to write encrypted account export", "error", err, "userID", userID)
+		return
+	}
+	if err := encrypted.Close(); err != nil {
+		slog.Error("Failed to finish encrypted account export", "error", err, "userID", userID)
+	}
+}
+
+// ImportAccount handles POST requests adding an account archive to the current user, the archive is validated
+// as a whole first so a rejected one imports nothing
+func (h *Handler) ImportAccount(c *gin.Context) {
+	userID := h.getUserID(c)
+
+	data, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, constants.MaxArchiveBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": constants.ErrArchiveTooLarge})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+	if password := c.GetHeader(constants.HeaderExportPassword); password != "" {
+		if data, err = decryptArchive(data, password); err != nil {
+			slog.Error("Failed to decrypt account archive", "error", err, "userID", userID)
+			switch {
+			case errors.Is(err, errArchiveTooLarge):
+				c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": constants.ErrArchiveTooLarge})
+			case errors.Is(err, export.ErrWrongPassword):
+				c.JSON(http.StatusBadRequest, gin.H{"error": constants.ErrWrongExportPassword})
+			default:
+				c.JSON(http.StatusBadRequest, gin.H{"error": constants.ErrInvalidEncryptedExport})
+			}
+			return
+		}
+	}
+
+	result, err := h.archiveService.WithContext(c.Request.Context()).ImportAccount(userID, data)
+	if err != nil {
+		slog.Error("Failed to import account", "error", err, "userID", userID)
+		var invalid *service.ArchiveValidationError
+		if errors.As(err, &invalid) {
+			c.JSON(http.StatusBadRequest, dtos.ArchiveValidationErrorDto{Error: constants.ErrInvalidArchive, Problems: invalid.Problems})
+			return
+		}
+		respondError(c, err, "Failed to import account")
+		return
+	}
+
+	slog.Info("Account imported", "userID", userID, "contacts", result.ContactsImported, "skipped", result.ContactsSkipped)
+	analytics.Track(analytics.EventImport, userID, map[string]string{"kind": "account"})
+	c.JSON(http.StatusOK, result)
+}
+
+// exportPassword returns the password an export is encrypted with, empty for a plain export. The password is only
+// read from the request, never logged nor stored. A too short password gets 400 and ok false
+func exportPassword(c *gin.Context) (password string, ok bool) {
+	password = c.GetHeader(constants.HeaderExportPassword)
+	if password != "" && len(password) < constants.MinExportPasswordLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": constants.ErrExportPasswordTooShort})
+		return "", false
+	}
+	return password, true
+}
+
+var errArchiveTooLarge = errors.New(constants.ErrArchiveTooLarge)
+
+// decryptArchive decrypts an archive exported with a password, a compressed archive (gpg --symmetric compresses)
+// cannot grow past the size limit of archives
+func decryptArchive(data []byte, password string) ([]byte, error) {
+	plaintext, err := export.Decrypt(bytes.NewReader(data), password)
+	if err != nil {
+		return nil, err
+	}
+	data, err = io.ReadAll(io.LimitReader(plaintext, constants.MaxArchiveBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", constants.ErrInvalidEncryptedExport, err)
+	}
+	if len(data) > constants.MaxArchiveBytes {
+		return nil, errArchiveTooLarge
+	}
+	return data, nil
+}
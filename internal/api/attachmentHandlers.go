@@ -0,0 +1,160 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/gin-gonic/gin"
+)
+
+// UploadAttachment handles multipart POST requests attaching a file to a contact
+func (h *Handler) UploadAttachment(c *gin.Context) {
+	contactID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		slog.Error("Invalid contact ID", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contact ID"})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		slog.Error("Invalid upload attachment request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing file field in multipart form"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		slog.Error("Failed to open uploaded file", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read uploaded file"})
+		return
+	}
+	defer file.Close()
+
+	req := dtos.UploadAttachmentRequestDto{
+		UserID:    h.getUserID(c),
+		ContactID: contactID,
+		FileName:  fileHeader.Filename,
+		SizeBytes: fileHeader.Size,
+	}
+
+	slog.Info("Uploading attachment", "contactID", contactID, "userID", req.UserID, "size", req.SizeBytes)
+
+	attachment, err := h.attachmentService.WithContext(c.Request.Context()).UploadAttachment(req, file)
+	if err != nil {
+		slog.Error("Failed to upload attachment", "error", err, "contactID", contactID)
+		respondError(c, err, "Failed to upload attachment")
+		return
+	}
+
+	slog.Info("Attachment uploaded successfully", "attachmentID", attachment.ID, "contactID", contactID)
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// ListAttachments handles GET requests listing the attachments of a contact
+func (h *Handler) ListAttachments(c *gin.Context) {
+	contactID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		slog.Error("Invalid contact ID", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contact ID"})
+		return
+	}
+	userID := h.getUserID(c)
+
+	result, err := h.attachmentService.WithContext(c.Request.Context()).ListAttachments(userID, contactID)
+	if err != nil {
+		slog.Error("Failed to list attachments", "error", err, "contactID", contactID)
+		respondError(c, err, "Failed to list attachments")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetAttachmentURL handles GET requests creating a signed download link for an attachment
+func (h *Handler) GetAttachmentURL(c *gin.Context) {
+	contactID, attachmentID, ok := h.parseAttachmentParams(c)
+	if !ok {
+		return
+	}
+	userID := h.getUserID(c)
+
+	result, err := h.attachmentService.WithContext(c.Request.Context()).GetDownloadURL(userID, contactID, attachmentID)
+	if err != nil {
+		slog.Error("Failed to sign attachment URL", "error", err, "attachmentID", attachmentID)
+		respondError(c, err, "Failed to create download link")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// DownloadAttachment handles public GET requests for signed attachment links
+func (h *Handler) DownloadAttachment(c *gin.Context) {
+	attachmentID, err := strconv.Atoi(c.Param("attachmentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attachment ID"})
+		return
+	}
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": constants.ErrInvalidSignedURL})
+		return
+	}
+
+	attachment, content, err := h.attachmentService.WithContext(c.Request.Context()).OpenSignedDownload(attachmentID, expires, c.Query("signature"))
+	if err != nil {
+		slog.Error("Failed to download attachment", "error", err, "attachmentID", attachmentID)
+		respondError(c, err, "Failed to download attachment")
+		return
+	}
+	defer content.Close()
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", attachment.FileName))
+	c.Header("Content-Length", strconv.FormatInt(attachment.SizeBytes, 10))
+	c.Header("Content-Type", attachment.ContentType)
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, content); err != nil {
+		slog.Error("Failed to stream attachment", "error", err, "attachmentID", attachmentID)
+	}
+}
+
+// DeleteAttachment handles DELETE requests removing an attachment from a contact
+func (h *Handler) DeleteAttachment(c *gin.Context) {
+	contactID, attachmentID, ok := h.parseAttachmentParams(c)
+	if !ok {
+		return
+	}
+	userID := h.getUserID(c)
+
+	err := h.attachmentService.WithContext(c.Request.Context()).DeleteAttachment(userID, contactID, attachmentID)
+	if err != nil {
+		slog.Error("Failed to delete attachment", "error", err, "attachmentID", attachmentID)
+		respondError(c, err, "Failed to delete attachment")
+		return
+	}
+
+	slog.Info("Attachment deleted successfully", "attachmentID", attachmentID, "userID", userID)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Attachment deleted successfully",
+	})
+}
+
+func (h *Handler) parseAttachmentParams(c *gin.Context) (int, int, bool) {
+	contactID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contact ID"})
+		return 0, 0, false
+	}
+	attachmentID, err := strconv.Atoi(c.Param("attachmentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attachment ID"})
+		return 0, 0, false
+	}
+	return contactID, attachmentID, true
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/danizion/contact-app/internal/analytics"
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/gin-gonic/gin"
+)
+
+// ExportAuditLog handles GET requests streaming the audit history as a CSV file
+func (h *Handler) ExportAuditLog(c *gin.Context) {
+	req := dtos.AuditExportRequestDto{
+		UserID:  h.getUserID(c),
+		IsAdmin: c.GetBool(constants.AuthIsAdminKey),
+		Format:  c.DefaultQuery("format", constants.ExportFormatCSV),
+		Action:  c.Query("action"),
+	}
+
+	if req.Format != constants.ExportFormatCSV {
+		c.JSON(http.StatusBadRequest, gin.H{"error": constants.ErrUnsupportedExportFormat})
+		return
+	}
+
+	var err error
+	if req.ActorID, err = parseOptionalID(c.Query("actor")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid actor ID"})
+		return
+	}
+	if req.AccountID, err = parseOptionalID(c.Query("user_id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	from, fromErr := parseAuditTime(c.Query("from"), false)
+	to, toErr := parseAuditTime(c.Query("to"), true)
+	if fromErr != nil || toErr != nil || (!from.IsZero() && !to.IsZero() && !from.Before(to)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": constants.ErrInvalidAuditRange})
+		return
+	}
+	req.From, req.To = from, to
+
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", `attachment; filename="audit-log.csv"`)
+	c.Status(http.StatusOK)
+
+	// Rows are streamed as they are read, a failure midway can only truncate the file
+	if err := h.auditService.WithContext(c.Request.Context()).ExportAuditLog(req, c.Writer); err != nil {
+		slog.Error("Failed to export audit log", "error", err, "userID", req.UserID)
+		return
+	}
+	analytics.Track(analytics.EventExport, h.getUserID(c), map[string]string{"kind": "audit_log"})
+}
+
+// parseOptionalID parses an optional positive ID query parameter, 0 when absent
+func parseOptionalID(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	id, err := strconv.Atoi(value)
+	if err != nil || id < 1 {
+		return 0, strconv.ErrSyntax
+	}
+	return id, nil
+}
+
+// parseAuditTime parses an RFC 3339 timestamp or a YYYY-MM-DD date, a date used as the end of a range includes the whole day
+func parseAuditTime(value string, endOfRange bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfRange {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
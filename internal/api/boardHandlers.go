@@ -0,0 +1,76 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/gin-gonic/gin"
+)
+
+// GetBoard handles GET requests for the kanban board, contacts grouped into paginated columns by a picklist field
+func (h *Handler) GetBoard(c *gin.Context) {
+	var req dtos.BoardRequestDto
+	if err := c.ShouldBindQuery(&req); err != nil {
+		slog.Error("Invalid get board request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.UserID = h.getUserID(c)
+
+	if req.Field == "" {
+		req.Field = constants.PicklistFieldStage
+	}
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.PageSize < 1 {
+		req.PageSize = constants.DefaultPageSize
+	}
+	if req.PageSize > constants.MaxPageSize {
+		req.PageSize = constants.MaxPageSize
+	}
+
+	board, err := h.contactService.WithContext(c.Request.Context()).GetBoard(req)
+	if err != nil {
+		slog.Error("Failed to retrieve board", "error", err, "userID", req.UserID)
+		respondError(c, err, "Failed to retrieve board")
+		return
+	}
+
+	c.JSON(http.StatusOK, board)
+}
+
+// MoveContact handles POST requests moving a contact to a stage column at a given position
+func (h *Handler) MoveContact(c *gin.Context) {
+	contactID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		slog.Error("Invalid contact ID", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contact ID"})
+		return
+	}
+
+	var req dtos.MoveContactRequestDto
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid move contact request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.UserID = h.getUserID(c)
+	req.ContactID = contactID
+
+	slog.Info("Moving contact", "contactID", contactID, "stage", req.Stage, "position", req.Position, "userID", req.UserID)
+
+	err = h.contactService.WithContext(c.Request.Context()).MoveContact(req)
+	if err != nil {
+		slog.Error("Failed to move contact", "error", err, "contactID", contactID)
+		respondError(c, err, "Failed to move contact")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Contact moved successfully",
+	})
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/gin-gonic/gin"
+)
+
+// GetContactChanges handles GET requests long polling the changes to the user's contacts after a cursor
+func (h *Handler) GetContactChanges(c *gin.Context) {
+	since, err := strconv.ParseInt(c.DefaultQuery("since", "0"), 10, 64)
+	if err != nil || since < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": constants.ErrInvalidChangesCursor})
+		return
+	}
+	wait, err := parseChangesWait(c.Query("wait"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": constants.ErrInvalidChangesWait})
+		return
+	}
+	userID := h.getUserID(c)
+
+	result, err := h.changesService.WithContext(c.Request.Context()).GetChanges(c.Request.Context(), userID, since, wait)
+	if err != nil {
+		slog.Error("Failed to get contact changes", "error", err, "userID", userID)
+		respondError(c, err, "Failed to get contact changes")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetContactCount handles GET requests for the contact count badge, cheap enough to be polled
+func (h *Handler) GetContactCount(c *gin.Context) {
+	since, err := strconv.ParseInt(c.DefaultQuery("since", "0"), 10, 64)
+	if err != nil || since < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": constants.ErrInvalidCountCursor})
+		return
+	}
+	userID := h.getUserID(c)
+
+	result, err := h.contactService.WithContext(c.Request.Context()).CountContacts(userID, since)
+	if err != nil {
+		slog.Error("Failed to count contacts", "error", err, "userID", userID)
+		respondError(c, err, "Failed to count contacts")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// parseChangesWait parses how long to hold a changes request, as a duration (30s) or a number of seconds
+func parseChangesWait(value string) (time.Duration, error) {
+	if value == "" {
+		return constants.DefaultChangesWait, nil
+	}
+	wait, err := time.ParseDuration(value)
+	if err != nil {
+		seconds, atoiErr := strconv.Atoi(value)
+		if atoiErr != nil {
+			return 0, err
+		}
+		wait = time.Duration(seconds) * time.Second
+	}
+	if wait < 0 || wait > constants.MaxChangesWait {
+		return 0, fmt.Errorf("wait out of range: %s", wait)
+	}
+	return wait, nil
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/gin-gonic/gin"
+)
+
+// GetContactCompleteness handles GET requests scoring how filled in a contact is
+func (h *Handler) GetContactCompleteness(c *gin.Context) {
+	contactID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contact ID"})
+		return
+	}
+	userID := h.getUserID(c)
+
+	completeness, err := h.contactService.WithContext(c.Request.Context()).GetContactCompleteness(userID, contactID)
+	if err != nil {
+		slog.Error("Failed to get contact completeness", "error", err, "contactID", contactID)
+		respondError(c, err, "Failed to get contact completeness")
+		return
+	}
+
+	c.JSON(http.StatusOK, completeness)
+}
+
+// GetIncompleteContacts handles GET requests listing the contacts missing data, least complete first
+func (h *Handler) GetIncompleteContacts(c *gin.Context) {
+	var req dtos.IncompleteContactsRequestDto
+	if err := c.ShouldBindQuery(&req); err != nil {
+		slog.Error("Invalid incomplete contacts request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.UserID = h.getUserID(c)
+
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.PageSize < 1 {
+		req.PageSize = constants.DefaultPageSize
+	}
+	if req.PageSize > constants.MaxPageSize {
+		req.PageSize = constants.MaxPageSize
+	}
+
+	result, err := h.contactService.WithContext(c.Request.Context()).GetIncompleteContacts(req)
+	if err != nil {
+		slog.Error("Failed to get incomplete contacts", "error", err, "userID", req.UserID)
+		respondError(c, err, "Failed to get incomplete contacts")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
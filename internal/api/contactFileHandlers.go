@@ -0,0 +1,110 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/danizion/contact-app/internal/analytics"
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/export"
+	"github.com/danizion/contact-app/internal/importer"
+	"github.com/gin-gonic/gin"
+)
+
+// ImportContactFile handles multipart POST requests adding the contacts of a CSV or vCard file, the format is told
+// by the file extension (.csv, .vcf or .vcard)
+func (h *Handler) ImportContactFile(c *gin.Context) {
+	userID := h.getUserID(c)
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, constants.MaxContactFileBytes)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": constants.ErrContactFileTooLarge})
+			return
+		}
+		slog.Error("Invalid contact file import request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing file field in multipart form"})
+		return
+	}
+
+	format := importer.DetectFormat(fileHeader.Filename)
+	if format == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": constants.ErrUnsupportedContactFileFormat})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		slog.Error("Failed to open uploaded contact file", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read uploaded file"})
+		return
+	}
+	defer file.Close()
+
+	result, err := h.contactFileService.WithContext(c.Request.Context()).ImportContacts(userID, format, file)
+	if err != nil {
+		slog.Error("Failed to import contact file", "error", err, "userID", userID, "format", format)
+		respondError(c, err, "Failed to import contacts")
+		return
+	}
+
+	slog.Info("Contact file imported", "userID", userID, "format", format, "rows", result.Rows, "imported", result.Imported, "failed", result.Failed)
+	analytics.Track(analytics.EventImport, userID, map[string]string{"kind": "contacts_" + format})
+	c.JSON(http.StatusOK, result)
+}
+
+// ExportContactFile handles GET requests streaming every contact of the user as a CSV or vCard file, encrypted when a
+// password is given
+func (h *Handler) ExportContactFile(c *gin.Context) {
+	userID := h.getUserID(c)
+	format := c.DefaultQuery("format", constants.ContactFileFormatCSV)
+	password, ok := exportPassword(c)
+	if !ok {
+		return
+	}
+
+	switch format {
+	case constants.ContactFileFormatCSV:
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+	case constants.ContactFileFormatVCard:
+		c.Header("Content-Type", "text/vcard; charset=utf-8")
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": constants.ErrUnsupportedContactFileFormat})
+		return
+	}
+
+	var out io.Writer = c.Writer
+	var encrypted *export.EncryptWriter
+	if password == "" {
+		c.Header("Content-Disposition", `attachment; filename="contacts.`+format+`"`)
+	} else {
+		c.Header("Content-Disposition", `attachment; filename="contacts.`+format+constants.EncryptedExportExtension+`"`)
+		c.Header("Content-Type", "application/octet-stream")
+		var err error
+		if encrypted, err = export.Encrypt(c.Writer, password); err != nil {
+			slog.Error("Failed to encrypt contacts export", "error", err, "userID", userID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export contacts"})
+			return
+		}
+		out = encrypted
+	}
+	c.Status(http.StatusOK)
+
+	// Contacts are streamed (and encrypted) as they are read, a failure midway can only truncate the file
+	if err := h.contactFileService.WithContext(c.Request.Context()).ExportContacts(userID, format, out); err != nil {
+		slog.Error("Failed to export contacts", "error", err, "userID", userID, "format", format)
+		return
+	}
+	if encrypted != nil {
+		if err := encrypted.Close(); err != nil {
+			slog.Error("Failed to finish encrypted contacts export", "error", err, "userID", userID)
+			return
+		}
+	}
+	analytics.Track(analytics.EventExport, userID, map[string]string{"kind": "contacts_" + format, "encrypted": strconv.FormatBool(password != "")})
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/gin-gonic/gin"
+)
+
+// GetContactTemplate handles GET requests for the contact template, the custom fields and default tags of contacts
+func (h *Handler) GetContactTemplate(c *gin.Context) {
+	result, err := h.templateService.WithContext(c.Request.Context()).GetTemplate()
+	if err != nil {
+		slog.Error("Failed to get contact template", "error", err)
+		respondError(c, err, "Failed to get contact template")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// SaveCustomField handles admin PUT requests creating or replacing a custom field of the contact template
+func (h *Handler) SaveCustomField(c *gin.Context) {
+	var req dtos.SaveCustomFieldRequestDto
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid custom field request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Key = c.Param("key")
+
+	result, err := h.templateService.WithContext(c.Request.Context()).SaveField(req)
+	if err != nil {
+		slog.Error("Failed to save custom field", "error", err, "key", req.Key)
+		respondError(c, err, "Failed to save custom field")
+		return
+	}
+
+	slog.Info("Custom field saved", "key", req.Key, "userID", h.getUserID(c))
+	c.JSON(http.StatusOK, result)
+}
+
+// DeleteCustomField handles admin DELETE requests removing a custom field from the contact template
+func (h *Handler) DeleteCustomField(c *gin.Context) {
+	key := c.Param("key")
+
+	result, err := h.templateService.WithContext(c.Request.Context()).DeleteField(key)
+	if err != nil {
+		slog.Error("Failed to delete custom field", "error", err, "key", key)
+		respondError(c, err, "Failed to delete custom field")
+		return
+	}
+
+	slog.Info("Custom field deleted", "key", key, "userID", h.getUserID(c))
+	c.JSON(http.StatusOK, result)
+}
+
+// SetTemplateTags handles admin PUT requests setting the tags attached to every new contact
+func (h *Handler) SetTemplateTags(c *gin.Context) {
+	var req dtos.SetTemplateTagsRequestDto
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid template tags request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.templateService.WithContext(c.Request.Context()).SetDefaultTags(req)
+	if err != nil {
+		slog.Error("Failed to set template tags", "error", err)
+		respondError(c, err, "Failed to set template tags")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetTemplateMigration handles admin GET requests reporting the migration of the contacts to the current template
+func (h *Handler) GetTemplateMigration(c *gin.Context) {
+	result, err := h.templateService.WithContext(c.Request.Context()).GetMigration()
+	if err != nil {
+		slog.Error("Failed to get template migration", "error", err)
+		respondError(c, err, "Failed to get template migration")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
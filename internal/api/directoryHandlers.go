@@ -0,0 +1,92 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// GetDirectorySettings handles admin GET requests for the settings of the team directory
+func (h *Handler) GetDirectorySettings(c *gin.Context) {
+	result, err := h.directoryService.WithContext(c.Request.Context()).GetSettings()
+	if err != nil {
+		slog.Error("Failed to get directory settings", "error", err)
+		respondError(c, err, "Failed to get directory settings")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// SaveDirectorySettings handles admin PUT requests replacing the settings of the team directory
+func (h *Handler) SaveDirectorySettings(c *gin.Context) {
+	var req dtos.DirectorySettingsDto
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid directory settings request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.directoryService.WithContext(c.Request.Context()).SaveSettings(req)
+	if err != nil {
+		respondError(c, err, "Failed to save directory settings")
+		return
+	}
+
+	slog.Info("Directory settings saved", "enabled", result.Enabled, "slug", result.Slug, "userID", h.getUserID(c))
+	c.JSON(http.StatusOK, result)
+}
+
+// SetDirectoryCard handles PUT requests picking the contact standing for the current user in the team directory
+func (h *Handler) SetDirectoryCard(c *gin.Context) {
+	var req dtos.SetDirectoryCardRequestDto
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid directory card request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.UserID = h.getUserID(c)
+
+	if err := h.directoryService.WithContext(c.Request.Context()).SetCard(req); err != nil {
+		respondError(c, err, "Failed to set directory card")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Directory card updated successfully"})
+}
+
+// GetDirectory handles GET requests for the public team directory, it needs no login
+func (h *Handler) GetDirectory(c *gin.Context) {
+	var req dtos.DirectoryRequestDto
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Slug = c.Param("slug")
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.PageSize < 1 {
+		req.PageSize = constants.DefaultPageSize
+	}
+	if req.PageSize > constants.MaxPageSize {
+		req.PageSize = constants.MaxPageSize
+	}
+
+	result, err := h.directoryService.WithContext(c.Request.Context()).List(req)
+	if err != nil {
+		respondError(c, err, "Failed to get directory")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetBranding handles GET requests for the branding of the deployment, shown by the public pages without login
+func (h *Handler) GetBranding(c *gin.Context) {
+	c.JSON(http.StatusOK, service.Branding())
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/gin-gonic/gin"
+)
+
+// GetDuplicateContacts handles GET requests listing the pairs of contacts likely to be the same person, with why
+func (h *Handler) GetDuplicateContacts(c *gin.Context) {
+	var req dtos.DuplicateContactsRequestDto
+	if err := c.ShouldBindQuery(&req); err != nil {
+		slog.Error("Invalid duplicate contacts request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.UserID = h.getUserID(c)
+
+	result, err := h.contactService.WithContext(c.Request.Context()).GetDuplicateContacts(req)
+	if err != nil {
+		slog.Error("Failed to get duplicate contacts", "error", err, "userID", req.UserID)
+		respondError(c, err, "Failed to get duplicate contacts")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
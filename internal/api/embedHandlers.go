@@ -0,0 +1,92 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateEmbedToken handles POST requests creating an embed token, the response holds the token and the URL of its list
+func (h *Handler) CreateEmbedToken(c *gin.Context) {
+	var req dtos.CreateEmbedTokenRequestDto
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid create embed token request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.UserID = h.getUserID(c)
+
+	result, err := h.embedService.WithContext(c.Request.Context()).CreateToken(req)
+	if err != nil {
+		slog.Error("Failed to create embed token", "error", err, "userID", req.UserID)
+		respondError(c, err, "Failed to create embed token")
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// ListEmbedTokens handles GET requests listing the user's embed tokens
+func (h *Handler) ListEmbedTokens(c *gin.Context) {
+	userID := h.getUserID(c)
+
+	result, err := h.embedService.WithContext(c.Request.Context()).ListTokens(userID)
+	if err != nil {
+		slog.Error("Failed to list embed tokens", "error", err, "userID", userID)
+		respondError(c, err, "Failed to list embed tokens")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// RevokeEmbedToken handles DELETE requests revoking an embed token
+func (h *Handler) RevokeEmbedToken(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid embed token ID"})
+		return
+	}
+	userID := h.getUserID(c)
+
+	if err := h.embedService.WithContext(c.Request.Context()).RevokeToken(userID, id); err != nil {
+		slog.Error("Failed to revoke embed token", "error", err, "embedTokenID", id)
+		respondError(c, err, "Failed to revoke embed token")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Embed token revoked successfully"})
+}
+
+// GetEmbeddedContacts handles GET requests of the pages embedding the contact list of a token. It needs no login and
+// can be called from any origin
+func (h *Handler) GetEmbeddedContacts(c *gin.Context) {
+	c.Header("Access-Control-Allow-Origin", "*")
+
+	var req dtos.EmbedContactsRequestDto
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.PageSize < 1 {
+		req.PageSize = constants.DefaultPageSize
+	}
+	if req.PageSize > constants.MaxPageSize {
+		req.PageSize = constants.MaxPageSize
+	}
+
+	result, err := h.embedService.WithContext(c.Request.Context()).ListContacts(req)
+	if err != nil {
+		respondError(c, err, "Failed to list contacts")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
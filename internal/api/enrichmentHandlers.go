@@ -0,0 +1,106 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EnrichContact handles POST requests asking the enrichment provider for a contact's missing data
+func (h *Handler) EnrichContact(c *gin.Context) {
+	contactID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		slog.Error("Invalid contact ID", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contact ID"})
+		return
+	}
+	userID := h.getUserID(c)
+
+	slog.Info("Enriching contact", "contactID", contactID, "userID", userID)
+
+	result, err := h.enrichmentService.WithContext(c.Request.Context()).EnrichContact(userID, contactID)
+	if err != nil {
+		slog.Error("Failed to enrich contact", "error", err, "contactID", contactID)
+		respondError(c, err, "Failed to enrich contact")
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// ListEnrichments handles GET requests listing the enrichment suggestions of a contact
+func (h *Handler) ListEnrichments(c *gin.Context) {
+	contactID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		slog.Error("Invalid contact ID", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contact ID"})
+		return
+	}
+	userID := h.getUserID(c)
+
+	result, err := h.enrichmentService.WithContext(c.Request.Context()).ListEnrichments(userID, contactID)
+	if err != nil {
+		slog.Error("Failed to list enrichments", "error", err, "contactID", contactID)
+		respondError(c, err, "Failed to list enrichments")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": result})
+}
+
+// AcceptEnrichment handles POST requests confirming an enrichment suggestion
+func (h *Handler) AcceptEnrichment(c *gin.Context) {
+	contactID, enrichmentID, ok := h.parseEnrichmentParams(c)
+	if !ok {
+		return
+	}
+	userID := h.getUserID(c)
+
+	err := h.enrichmentService.WithContext(c.Request.Context()).AcceptEnrichment(userID, contactID, enrichmentID)
+	if err != nil {
+		slog.Error("Failed to accept enrichment", "error", err, "enrichmentID", enrichmentID)
+		respondError(c, err, "Failed to accept enrichment")
+		return
+	}
+
+	slog.Info("Enrichment accepted", "enrichmentID", enrichmentID, "contactID", contactID, "userID", userID)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Enrichment accepted successfully",
+	})
+}
+
+// RejectEnrichment handles POST requests discarding an enrichment suggestion
+func (h *Handler) RejectEnrichment(c *gin.Context) {
+	contactID, enrichmentID, ok := h.parseEnrichmentParams(c)
+	if !ok {
+		return
+	}
+	userID := h.getUserID(c)
+
+	err := h.enrichmentService.WithContext(c.Request.Context()).RejectEnrichment(userID, contactID, enrichmentID)
+	if err != nil {
+		slog.Error("Failed to reject enrichment", "error", err, "enrichmentID", enrichmentID)
+		respondError(c, err, "Failed to reject enrichment")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Enrichment rejected successfully",
+	})
+}
+
+func (h *Handler) parseEnrichmentParams(c *gin.Context) (int, int, bool) {
+	contactID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contact ID"})
+		return 0, 0, false
+	}
+	enrichmentID, err := strconv.Atoi(c.Param("enrichmentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid enrichment ID"})
+		return 0, 0, false
+	}
+	return contactID, enrichmentID, true
+}
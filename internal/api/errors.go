@@ -0,0 +1,64 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// errorStatuses are the HTTP statuses answering the kinds of service errors
+var errorStatuses = []struct {
+	kind   error
+	status int
+}{
+	{service.ErrInvalidInput, http.StatusBadRequest},
+	{service.ErrUnauthorized, http.StatusUnauthorized},
+	{service.ErrForbidden, http.StatusForbidden},
+	{service.ErrNotFound, http.StatusNotFound},
+	{service.ErrConflict, http.StatusConflict},
+	{service.ErrDuplicateContact, http.StatusConflict},
+	{service.ErrTooLarge, http.StatusRequestEntityTooLarge},
+	{service.ErrUnsupportedType, http.StatusUnsupportedMediaType},
+	{service.ErrUnprocessable, http.StatusUnprocessableEntity},
+	{service.ErrQuotaExceeded, http.StatusInsufficientStorage},
+	{service.ErrUnavailable, http.StatusServiceUnavailable},
+}
+
+// errorStatus returns the HTTP status answering an error, 500 for errors of no kind
+func errorStatus(err error) int {
+	for _, entry := range errorStatuses {
+		if errors.Is(err, entry.kind) {
+			return entry.status
+		}
+	}
+	return http.StatusInternalServerError
+}
+
+// respondError answers a request a service failed: with the status of the kind of the error and its message, or
+// with 500 and fallback for internal failures, which are logged. Requests aborted by their timeout get 504, the
+// ones whose client went away get no response
+func respondError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		slog.Warn("Request timed out", "error", err, "path", c.FullPath())
+		c.JSON(http.StatusGatewayTimeout, gin.H{"error": constants.ErrRequestTimeout})
+		return
+	case errors.Is(err, context.Canceled):
+		c.AbortWithStatus(constants.StatusClientClosedRequest)
+		return
+	}
+	status := errorStatus(err)
+	if status == http.StatusInternalServerError {
+		slog.Error(fallback, "error", err, "path", c.FullPath(), "userID", c.GetInt(constants.AuthUserKey))
+		c.JSON(status, gin.H{"error": fallback})
+		return
+	}
+	var serviceErr *service.Error
+	errors.As(err, &serviceErr)
+	c.JSON(status, gin.H{"error": serviceErr.Error()})
+}
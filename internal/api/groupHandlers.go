@@ -0,0 +1,138 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateGroup handles POST requests creating a contact group
+func (h *Handler) CreateGroup(c *gin.Context) {
+	var req dtos.CreateGroupRequestDto
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid create group request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.UserID = h.getUserID(c)
+
+	result, err := h.groupService.WithContext(c.Request.Context()).CreateGroup(req)
+	if err != nil {
+		slog.Error("Failed to create group", "error", err, "userID", req.UserID)
+		respondError(c, err, "Failed to create group")
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// ListGroups handles GET requests listing the user's groups
+func (h *Handler) ListGroups(c *gin.Context) {
+	userID := h.getUserID(c)
+
+	result, err := h.groupService.WithContext(c.Request.Context()).ListGroups(userID)
+	if err != nil {
+		slog.Error("Failed to list groups", "error", err, "userID", userID)
+		respondError(c, err, "Failed to list groups")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": result})
+}
+
+// DeleteGroup handles DELETE requests removing a group, its contacts are kept
+func (h *Handler) DeleteGroup(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+	userID := h.getUserID(c)
+
+	if err := h.groupService.WithContext(c.Request.Context()).DeleteGroup(userID, groupID); err != nil {
+		slog.Error("Failed to delete group", "error", err, "groupID", groupID)
+		respondError(c, err, "Failed to delete group")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Group deleted successfully"})
+}
+
+// AddContactsToGroup handles POST requests attaching many contacts to a group
+func (h *Handler) AddContactsToGroup(c *gin.Context) {
+	h.bulkGroupContacts(c, true)
+}
+
+// RemoveContactsFromGroup handles DELETE requests detaching many contacts from a group
+func (h *Handler) RemoveContactsFromGroup(c *gin.Context) {
+	h.bulkGroupContacts(c, false)
+}
+
+// AddContactsToTag handles POST requests tagging many contacts
+func (h *Handler) AddContactsToTag(c *gin.Context) {
+	h.bulkTagContacts(c, true)
+}
+
+// RemoveContactsFromTag handles DELETE requests untagging many contacts
+func (h *Handler) RemoveContactsFromTag(c *gin.Context) {
+	h.bulkTagContacts(c, false)
+}
+
+func (h *Handler) bulkGroupContacts(c *gin.Context, add bool) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+
+	var req dtos.BulkContactsRequestDto
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid bulk group request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID := h.getUserID(c)
+
+	var result *dtos.BulkContactsResponseDto
+	if add {
+		result, err = h.groupService.WithContext(c.Request.Context()).AddContactsToGroup(userID, groupID, req)
+	} else {
+		result, err = h.groupService.WithContext(c.Request.Context()).RemoveContactsFromGroup(userID, groupID, req)
+	}
+	if err != nil {
+		slog.Error("Failed to update group contacts", "error", err, "groupID", groupID)
+		respondError(c, err, "Failed to update group contacts")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) bulkTagContacts(c *gin.Context, add bool) {
+	var req dtos.BulkContactsRequestDto
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid bulk tag request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID := h.getUserID(c)
+	name := c.Param("name")
+
+	var result *dtos.BulkContactsResponseDto
+	var err error
+	if add {
+		result, err = h.tagService.WithContext(c.Request.Context()).AddContactsToTag(userID, name, req)
+	} else {
+		result, err = h.tagService.WithContext(c.Request.Context()).RemoveContactsFromTag(userID, name, req)
+	}
+	if err != nil {
+		slog.Error("Failed to update tag contacts", "error", err, "tag", name)
+		respondError(c, err, "Failed to update tag contacts")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
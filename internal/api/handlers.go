@@ -2,32 +2,117 @@ package api
 
 import (
 	"database/sql"
-	"fmt"
 	"log/slog"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 
+	"github.com/danizion/contact-app/internal/address"
+	"github.com/danizion/contact-app/internal/alerting"
+	"github.com/danizion/contact-app/internal/analytics"
 	"github.com/danizion/contact-app/internal/constants"
 	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/enrichment"
+	"github.com/danizion/contact-app/internal/geocode"
+	"github.com/danizion/contact-app/internal/mail"
+	"github.com/danizion/contact-app/internal/ocr"
+	"github.com/danizion/contact-app/internal/ratelimit"
+	"github.com/danizion/contact-app/internal/render"
 	"github.com/danizion/contact-app/internal/service"
+	"github.com/danizion/contact-app/internal/storage/blob"
 	"github.com/danizion/contact-app/internal/storage/redis"
 	"github.com/gin-gonic/gin"
 )
 
 // Handler for contact and users routes holds contact and user services to apply all logic
 type Handler struct {
-	contactService *service.ContactService
-	userService    *service.UserService
+	contactService      *service.ContactService
+	userService         *service.UserService
+	accountService      *service.AccountService
+	sessionService      *service.SessionService
+	picklistService     *service.PicklistService
+	attachmentService   *service.AttachmentService
+	cardImportService   *service.CardImportService
+	enrichmentService   *service.EnrichmentService
+	geocodeService      *service.GeocodeService
+	auditService        *service.AuditService
+	groupService        *service.GroupService
+	tagService          *service.TagService
+	snapshotService     *service.SnapshotService
+	preferencesService  *service.PreferencesService
+	webhookService      *service.WebhookService
+	apiKeyService       *service.APIKeyService
+	changesService      *service.ChangesService
+	archiveService      *service.ArchiveService
+	contactFileService  *service.ContactFileService
+	analyticsService    *service.AnalyticsService
+	statsService        *service.StatsService
+	alertService        *service.AlertService
+	mergeService        *service.AccountMergeService
+	usageService        *service.UsageService
+	announcementService *service.AnnouncementService
+	accountStateService *service.AccountStateService
+	templateService     *service.ContactTemplateService
+	sharedBookService   *service.SharedBookService
+	syncService         *service.SyncService
+	embedService        *service.EmbedService
+	directoryService    *service.DirectoryService
+	maintenanceService  *service.MaintenanceService
+	trashService        *service.TrashService
+	rateLimiter         ratelimit.Limiter
+	alertMonitor        *alerting.Monitor
 }
 
-func NewHandler(db *sql.DB, redisClient *redis.Redis) *Handler {
+func NewHandler(db *sql.DB, redisClient *redis.Redis, blobStore blob.Store, ocrProvider ocr.Provider, enrichmentProvider enrichment.Provider,
+	geocodeProvider geocode.Provider, mailSender mail.Sender, alertMonitor *alerting.Monitor) *Handler {
 	return &Handler{
-		contactService: service.NewContactService(db, redisClient),
-		userService:    service.NewUserService(db),
+		contactService:      service.NewContactService(db, redisClient),
+		userService:         service.NewUserService(db),
+		accountService:      service.NewAccountService(db, redisClient, blobStore, mailSender),
+		sessionService:      service.NewSessionService(db, redisClient),
+		picklistService:     service.NewPicklistService(db),
+		attachmentService:   service.NewAttachmentService(db, blobStore),
+		cardImportService:   service.NewCardImportService(ocrProvider),
+		enrichmentService:   service.NewEnrichmentService(db, redisClient, enrichmentProvider),
+		geocodeService:      service.NewGeocodeService(db, redisClient, geocodeProvider),
+		auditService:        service.NewAuditService(db),
+		groupService:        service.NewGroupService(db, redisClient),
+		tagService:          service.NewTagService(db, redisClient),
+		snapshotService:     service.NewSnapshotService(db, redisClient),
+		preferencesService:  service.NewPreferencesService(db),
+		webhookService:      service.NewWebhookService(db),
+		apiKeyService:       service.NewAPIKeyService(db, redisClient),
+		changesService:      service.NewChangesService(db),
+		archiveService:      service.NewArchiveService(db, redisClient),
+		contactFileService:  service.NewContactFileService(db, redisClient),
+		analyticsService:    service.NewAnalyticsService(db),
+		statsService:        service.NewStatsService(db),
+		alertService:        service.NewAlertService(db, alertMonitor),
+		mergeService:        service.NewAccountMergeService(db, redisClient),
+		usageService:        service.NewUsageService(db, redisClient),
+		announcementService: service.NewAnnouncementService(db),
+		accountStateService: service.NewAccountStateService(db, redisClient, mailSender),
+		templateService:     service.NewContactTemplateService(db, redisClient),
+		sharedBookService:   service.NewSharedBookService(db, mailSender),
+		syncService:         service.NewSyncService(db, redisClient),
+		embedService:        service.NewEmbedService(db),
+		directoryService:    service.NewDirectoryService(db),
+		maintenanceService:  service.NewMaintenanceService(db, redisClient),
+		trashService:        service.NewTrashService(db, blobStore),
+		rateLimiter:         newRateLimiter(redisClient),
+		alertMonitor:        alertMonitor,
 	}
 }
 
+// newRateLimiter counts requests in Redis so every replica enforces the same limits, in memory without Redis
+func newRateLimiter(redisClient *redis.Redis) ratelimit.Limiter {
+	if redisClient == nil {
+		return ratelimit.NewMemoryLimiter()
+	}
+	return ratelimit.NewRedisLimiter(redisClient)
+}
+
 func (h *Handler) CreateUser(c *gin.Context) {
 	var req dtos.CreateUserRequestDto
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -36,24 +121,16 @@ func (h *Handler) CreateUser(c *gin.Context) {
 		return
 	}
 
-	userID, err := h.userService.CreateUser(req)
+	req.ClientIP = c.ClientIP()
+	userID, err := h.userService.WithContext(c.Request.Context()).CreateUser(req)
 	if err != nil {
-		if strings.Contains(err.Error(), constants.ErrUsernameExists) {
-			slog.Error("Failed to create user", "error", "username already exists", "username", req.Username)
-			c.JSON(http.StatusConflict, gin.H{"error": constants.ErrUsernameExists})
-			return
-		}
-		if strings.Contains(err.Error(), constants.ErrEmailExists) {
-			slog.Error("Failed to create user", "error", "email already exists", "email", req.Email)
-			c.JSON(http.StatusConflict, gin.H{"error": constants.ErrEmailExists})
-			return
-		}
-		slog.Error("Failed to create user", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+		slog.Warn("User not created", "error", err, "email", req.Email)
+		respondError(c, err, "Failed to create user")
 		return
 	}
 
 	slog.Info("User created successfully", "userID", userID)
+	analytics.Track(analytics.EventSignup, userID, nil)
 	// Return success response with the new user ID
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "User created successfully",
@@ -73,28 +150,24 @@ func (h *Handler) Login(c *gin.Context) {
 	slog.Info("Login attempt", "email", req.Email)
 
 	// Authenticate user
-	user, err := h.userService.AuthenticateUser(req.Email, req.Password)
+	user, err := h.userService.WithContext(c.Request.Context()).AuthenticateUser(req.Email, req.Password, c.ClientIP())
 	if err != nil {
 		slog.Error("Login failed", "error", err, "email", req.Email)
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		respondError(c, err, "Failed to log in")
 		return
 	}
 
-	// Generate and sign token
-	token, err := h.userService.GenerateToken(user.ID, user.Username)
+	// Issue the access and refresh tokens
+	session, err := h.sessionService.WithContext(c.Request.Context()).CreateSession(user)
 	if err != nil {
 		slog.Error("Failed to generate token", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		respondError(c, err, "Failed to generate token")
 		return
 	}
 
 	slog.Info("Login successful", "userID", user.ID, "email", req.Email)
 
-	// Return the JWT token
-	c.JSON(http.StatusOK, dtos.LoginResponseDto{
-		Token:  token,
-		UserID: user.ID,
-	})
+	c.JSON(http.StatusOK, session)
 }
 
 func (h *Handler) GetContacts(c *gin.Context) {
@@ -118,23 +191,185 @@ func (h *Handler) GetContacts(c *gin.Context) {
 	req.LastName = c.Query("last_name")
 	req.PhoneNumber = c.Query("phone_number")
 	req.Address = c.Query("address")
-
-	req.PageSize = constants.DefaultPageSize
+	req.Social = c.Query("social")
+	if req.GroupID, err = parseOptionalID(c.Query("group")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+	req.Tag = c.Query("tag")
+	req.Query = c.Query("q")
+	req.InactiveFor = c.Query("inactive_for")
+	req.SortBy = c.Query("sort_by")
+	req.SortDir = c.Query("sort_dir")
+	req.Cursor = c.Query("cursor")
+
+	req.PageSize, err = strconv.Atoi(c.DefaultQuery("page_size", strconv.Itoa(constants.DefaultPageSize)))
+	if err != nil || req.PageSize < 1 {
+		req.PageSize = constants.DefaultPageSize
+	}
+	if req.PageSize > constants.MaxPageSize {
+		req.PageSize = constants.MaxPageSize
+	}
 
 	slog.Info("Getting contacts", "userID", req.UserID, "page", req.Page, "pageSize", req.PageSize)
 
 	// Get paginated contacts from service
-	result, err := h.contactService.GetContacts(req)
+	result, err := h.contactService.WithContext(c.Request.Context()).GetContacts(req)
 	if err != nil {
-		slog.Error("Failed to retrieve contacts", "error", err, "userID", req.UserID)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve contacts"})
+		respondError(c, err, "Failed to retrieve contacts")
 		return
 	}
 
 	slog.Info("Retrieved contacts", "count", len(result.Items), "total", result.TotalCount, "userID", req.UserID)
+	region := h.phoneRegion(c, req.UserID)
+	for i := range result.Items {
+		localizePhoneNumber(&result.Items[i], region)
+	}
+	if filters := usedContactFilters(req); filters != "" {
+		analytics.Track(analytics.EventSearch, req.UserID, map[string]string{"filters": filters})
+	}
 
-	// Return paginated results
-	c.JSON(http.StatusOK, result)
+	if render.WantsJSONAPI(c) {
+		resources, included, ok := h.contactResources(c, result.Items)
+		if !ok {
+			return
+		}
+		render.JSONAPI(c, http.StatusOK, render.Document{
+			Data:     resources,
+			Included: included,
+			Meta: gin.H{"total_count": result.TotalCount, "page": result.Page, "page_size": result.PageSize,
+				"total_pages": result.TotalPages, "next_cursor": result.NextCursor},
+			Links: contactPageLinks(c, result),
+		})
+		return
+	}
+
+	// Return paginated results, CSV has no room for the page information so it travels in headers
+	render.Respond(c, http.StatusOK, "contacts", result, func() render.Table {
+		c.Header(constants.HeaderTotalCount, strconv.Itoa(result.TotalCount))
+		c.Header(constants.HeaderTotalPages, strconv.Itoa(result.TotalPages))
+		if result.NextCursor != "" {
+			c.Header(constants.HeaderNextCursor, result.NextCursor)
+		}
+		return contactTable(result.Items...)
+	})
+}
+
+// contactPageLinks returns the JSON:API links of a contacts page, cursor links once the client pages by cursor
+func contactPageLinks(c *gin.Context, result *dtos.PaginationResult) map[string]string {
+	if c.Query("cursor") == "" {
+		return render.PageLinks(c, result.Page, result.TotalPages)
+	}
+	return render.CursorLinks(c, result.NextCursor)
+}
+
+// usedContactFilters lists the names of the filters of a contacts request, never their values
+func usedContactFilters(req dtos.GetContactRequestDto) string {
+	var filters []string
+	for name, used := range map[string]bool{
+		"first_name":   req.FirstName != "",
+		"last_name":    req.LastName != "",
+		"phone_number": req.PhoneNumber != "",
+		"address":      req.Address != "",
+		"social":       req.Social != "",
+		"group":        req.GroupID != 0,
+		"tag":          req.Tag != "",
+		"q":            req.Query != "",
+		"inactive_for": req.InactiveFor != "",
+	} {
+		if used {
+			filters = append(filters, name)
+		}
+	}
+	sort.Strings(filters)
+	return strings.Join(filters, ",")
+}
+
+// GetContact handles GET requests for a single contact
+func (h *Handler) GetContact(c *gin.Context) {
+	contactID, ok := h.contactIDParam(c)
+	if !ok {
+		return
+	}
+	userID := h.getUserID(c)
+
+	contact, err := h.contactService.WithContext(c.Request.Context()).GetContact(userID, contactID)
+	if err != nil {
+		respondError(c, err, "Failed to retrieve contact")
+		return
+	}
+	localizePhoneNumber(contact, h.phoneRegion(c, userID))
+
+	if render.WantsJSONAPI(c) {
+		resources, included, ok := h.contactResources(c, []dtos.GetContactsResponseDto{*contact})
+		if !ok {
+			return
+		}
+		render.JSONAPI(c, http.StatusOK, render.Document{Data: resources[0], Included: included})
+		return
+	}
+
+	render.Respond(c, http.StatusOK, "contact", contact, func() render.Table {
+		return contactTable(*contact)
+	})
+}
+
+// phoneRegion returns the region phone numbers are displayed for: the region of the user's preferences, else the
+// region of the preferred language of the request, else the default region of the deployment. Empty when none is
+// known, numbers are then international
+func (h *Handler) phoneRegion(c *gin.Context, userID int) string {
+	prefs, err := h.preferencesService.WithContext(c.Request.Context()).GetPreferences(userID)
+	if err != nil {
+		slog.Error("Failed to retrieve preferences", "error", err, "userID", userID)
+	} else if prefs.Region != "" {
+		return prefs.Region
+	}
+
+	// Accept-Language lists language tags such as en-US or fr;q=0.8, most preferred first
+	for _, tag := range strings.Split(c.GetHeader("Accept-Language"), ",") {
+		tag, _, _ = strings.Cut(strings.TrimSpace(tag), ";")
+		if region := languageRegion(tag); region != "" {
+			return region
+		}
+	}
+	return h.preferencesService.WithContext(c.Request.Context()).DefaultPhoneRegion()
+}
+
+// languageRegion returns the region subtag of a language tag (US in en-US, TW in zh-Hant-TW), empty when it has none
+func languageRegion(tag string) string {
+	subtags := strings.FieldsFunc(tag, func(r rune) bool { return r == '-' || r == '_' })
+	for i := 1; i < len(subtags); i++ {
+		if len(subtags[i]) != 2 {
+			continue
+		}
+		if region, err := address.NormalizeCountryCode(subtags[i]); err == nil {
+			return region
+		}
+	}
+	return ""
+}
+
+// contactResources renders contacts as JSON:API resources with their groups and tags, responding with an error
+// and returning false when they cannot be loaded
+func (h *Handler) contactResources(c *gin.Context, contacts []dtos.GetContactsResponseDto) ([]render.Resource, []render.Resource, bool) {
+	contactIDs := make([]int, len(contacts))
+	for i, contact := range contacts {
+		contactIDs[i] = contact.ID
+	}
+	memberships, err := h.contactService.WithContext(c.Request.Context()).GetContactMemberships(contactIDs)
+	if err != nil {
+		slog.Error("Failed to retrieve contact memberships", "error", err)
+		respondError(c, err, "Failed to retrieve contacts")
+		return nil, nil, false
+	}
+
+	resources, included, err := toContactResources(contacts, memberships)
+	if err != nil {
+		slog.Error("Failed to encode JSON:API document", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode response"})
+		return nil, nil, false
+	}
+	return resources, included, true
 }
 
 // CreateContact handles POST requests for creating a new contact
@@ -151,20 +386,19 @@ func (h *Handler) CreateContact(c *gin.Context) {
 	slog.Info("Creating new contact", "userID", req.UserID)
 
 	// Call service to create contact
-	contactID, err := h.contactService.CreateContact(req)
+	contactID, err := h.contactService.WithContext(c.Request.Context()).CreateContact(req)
 	if err != nil {
-		if strings.Contains(err.Error(), "already exists") {
-			slog.Error("Contact creation failed", "error", err, "userID", req.UserID)
-			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
-			return
-		}
-		slog.Error("Failed to create contact", "error", err, "userID", req.UserID)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create contact"})
+		slog.Error("Contact creation failed", "error", err, "userID", req.UserID)
+		respondError(c, err, "Failed to create contact")
 		return
 	}
 
 	slog.Info("Contact created successfully", "contactID", contactID, "userID", req.UserID)
 
+	// Look up missing data in the background when enrichment on creation is enabled
+	h.enrichmentService.EnrichOnCreate(req.UserID, contactID)
+	h.geocodeService.GeocodeInBackground(req.UserID, contactID)
+
 	// Return success response
 	c.JSON(http.StatusCreated, gin.H{
 		"message":    "Contact created successfully",
@@ -173,11 +407,9 @@ func (h *Handler) CreateContact(c *gin.Context) {
 }
 
 func (h *Handler) UpdateContact(c *gin.Context) {
-	// Get contact ID from URL parameter
-	contactID, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		slog.Error("Invalid contact ID", "id", c.Param("id"), "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contact ID"})
+	// Get contact ID from URL parameter, the contact ID or its client_id
+	contactID, ok := h.contactIDParam(c)
+	if !ok {
 		return
 	}
 
@@ -193,17 +425,19 @@ func (h *Handler) UpdateContact(c *gin.Context) {
 	slog.Info("Updating contact", "contactID", contactID, "userID", req.UserID)
 
 	// Call service to update contact
-	err = h.contactService.UpdateContact(req)
+	err := h.contactService.WithContext(c.Request.Context()).UpdateContact(req)
 	if err != nil {
 		slog.Error("Failed to update contact", "error", err, "contactID", contactID)
-		if strings.Contains(err.Error(), "contact not found") {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Contact not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update contact"})
+		respondError(c, err, "Failed to update contact")
 		return
 	}
 
+	// Locate the new address in the background unless coordinates were given
+	if req.Latitude == nil && (req.Address != "" || req.Street != "" || req.City != "" || req.Region != "" ||
+		req.PostalCode != "" || req.CountryCode != "") {
+		h.geocodeService.GeocodeInBackground(req.UserID, contactID)
+	}
+
 	slog.Info("Contact updated successfully", "contactID", contactID, "userID", req.UserID)
 
 	// Return success response
@@ -213,27 +447,29 @@ func (h *Handler) UpdateContact(c *gin.Context) {
 }
 
 func (h *Handler) DeleteContact(c *gin.Context) {
-	// Get contact ID from URL parameter
-	contactID, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		slog.Error("Invalid contact ID", "id", c.Param("id"), "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contact ID"})
+	// Get contact ID from URL parameter, the contact ID or its client_id
+	contactID, ok := h.contactIDParam(c)
+	if !ok {
 		return
 	}
 
 	userID := h.getUserID(c)
 
-	slog.Info("Deleting contact", "contactID", contactID, "userID", userID)
+	// Contacts go to the trash unless deleted permanently
+	permanent := c.Query("permanent") == "true"
+
+	slog.Info("Deleting contact", "contactID", contactID, "userID", userID, "permanent", permanent)
 
-	// Call service to delete contact
-	err = h.contactService.DeleteContact(userID, contactID)
+	// Call service to delete contact, permanent deletes also remove the attachment files
+	var err error
+	if permanent {
+		err = h.trashService.WithContext(c.Request.Context()).PurgeContact(userID, contactID)
+	} else {
+		err = h.contactService.WithContext(c.Request.Context()).DeleteContact(userID, contactID)
+	}
 	if err != nil {
 		slog.Error("Failed to delete contact", "error", err, "contactID", contactID)
-		if strings.Contains(err.Error(), "contact not found") {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Contact not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to delete contact with error: %v", err)})
+		respondError(c, err, "Failed to delete contact")
 		return
 	}
 
@@ -245,6 +481,17 @@ func (h *Handler) DeleteContact(c *gin.Context) {
 	})
 }
 
+// contactIDParam resolves the id URL parameter, the ID of a contact of the user or its client_id. It responds and
+// returns false when the parameter refers to no contact
+func (h *Handler) contactIDParam(c *gin.Context) (int, bool) {
+	contactID, err := h.contactService.WithContext(c.Request.Context()).ResolveContactID(h.getUserID(c), c.Param("id"))
+	if err != nil {
+		respondError(c, err, "Failed to retrieve contact")
+		return 0, false
+	}
+	return contactID, true
+}
+
 func (h *Handler) getUserID(c *gin.Context) int {
 	userID, exists := c.Get("userID")
 	if !exists {
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/danizion/contact-app/internal/analytics"
+	"github.com/gin-gonic/gin"
+)
+
+// ImportCardImage handles multipart POST requests extracting a draft contact from a business card photo
+func (h *Handler) ImportCardImage(c *gin.Context) {
+	fileHeader, err := c.FormFile("image")
+	if err != nil {
+		slog.Error("Invalid card import request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing image field in multipart form"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		slog.Error("Failed to open uploaded card image", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read uploaded image"})
+		return
+	}
+	defer file.Close()
+
+	userID := h.getUserID(c)
+	slog.Info("Importing business card", "userID", userID, "size", fileHeader.Size)
+
+	result, err := h.cardImportService.ImportCardImage(file)
+	if err != nil {
+		slog.Error("Failed to import business card", "error", err, "userID", userID)
+		// Failures of no kind come from the OCR provider
+		if errorStatus(err) == http.StatusInternalServerError {
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to recognize business card"})
+			return
+		}
+		respondError(c, err, "Failed to recognize business card")
+		return
+	}
+
+	analytics.Track(analytics.EventImport, userID, map[string]string{"kind": "card_image"})
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/gin-gonic/gin"
+)
+
+// LogInteraction handles POST requests logging a call, meeting or message with a contact
+func (h *Handler) LogInteraction(c *gin.Context) {
+	contactID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		slog.Error("Invalid contact ID", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contact ID"})
+		return
+	}
+
+	var req dtos.LogInteractionRequestDto
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid log interaction request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID := h.getUserID(c)
+
+	result, err := h.contactService.WithContext(c.Request.Context()).LogInteraction(userID, contactID, req)
+	if err != nil {
+		slog.Error("Failed to log interaction", "error", err, "contactID", contactID)
+		respondError(c, err, "Failed to log interaction")
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// GetInteractions handles GET requests listing the interactions logged with a contact
+func (h *Handler) GetInteractions(c *gin.Context) {
+	contactID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		slog.Error("Invalid contact ID", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contact ID"})
+		return
+	}
+	userID := h.getUserID(c)
+
+	result, err := h.contactService.WithContext(c.Request.Context()).GetInteractions(userID, contactID)
+	if err != nil {
+		slog.Error("Failed to get interactions", "error", err, "contactID", contactID)
+		respondError(c, err, "Failed to get interactions")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": result})
+}
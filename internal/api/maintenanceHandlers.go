@@ -0,0 +1,45 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/gin-gonic/gin"
+)
+
+// StartMaintenanceJob handles admin POST requests starting a maintenance operation in the background, the job is
+// answered with 202 and polled with GetMaintenanceJob
+func (h *Handler) StartMaintenanceJob(c *gin.Context) {
+	var req dtos.StartMaintenanceJobRequestDto
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid maintenance job request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	job, err := h.maintenanceService.StartJob(req)
+	if err != nil {
+		respondError(c, err, "Failed to start maintenance job")
+		return
+	}
+
+	slog.Info("Maintenance job requested", "job", job.ID, "operation", job.Operation, "admin", h.getUserID(c))
+	c.JSON(http.StatusAccepted, job)
+}
+
+// ListMaintenanceJobs handles admin GET requests listing the maintenance jobs of the replica
+func (h *Handler) ListMaintenanceJobs(c *gin.Context) {
+	c.JSON(http.StatusOK, h.maintenanceService.ListJobs())
+}
+
+// GetMaintenanceJob handles admin GET requests for the status of a maintenance job
+func (h *Handler) GetMaintenanceJob(c *gin.Context) {
+	job, err := h.maintenanceService.GetJob(c.Param("id"))
+	if err != nil {
+		respondError(c, err, "Failed to get maintenance job")
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/gin-gonic/gin"
+)
+
+// GetContactsGeoJSON handles GET requests for the map view, geocoded contacts matching the contact list filters as GeoJSON
+func (h *Handler) GetContactsGeoJSON(c *gin.Context) {
+	req := dtos.ContactsGeoJSONRequestDto{
+		UserID:      h.getUserID(c),
+		FirstName:   c.Query("first_name"),
+		LastName:    c.Query("last_name"),
+		PhoneNumber: c.Query("phone_number"),
+		Address:     c.Query("address"),
+		Social:      c.Query("social"),
+	}
+
+	var err error
+	if req.GroupID, err = parseOptionalID(c.Query("group")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return
+	}
+
+	zoom, err := strconv.Atoi(c.DefaultQuery("zoom", strconv.Itoa(constants.DefaultGeoJSONZoom)))
+	if err != nil || zoom < 0 || zoom > constants.MaxGeoJSONZoom {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "zoom must be an integer between 0 and " + strconv.Itoa(constants.MaxGeoJSONZoom)})
+		return
+	}
+	req.Zoom = zoom
+
+	result, err := h.contactService.WithContext(c.Request.Context()).GetContactsGeoJSON(req)
+	if err != nil {
+		slog.Error("Failed to retrieve contacts map", "error", err, "userID", req.UserID)
+		respondError(c, err, "Failed to retrieve contacts map")
+		return
+	}
+
+	c.Header("Content-Type", "application/geo+json")
+	c.JSON(http.StatusOK, result)
+}
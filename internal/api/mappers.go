@@ -1 +1,107 @@
 package api
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/phone"
+	"github.com/danizion/contact-app/internal/render"
+)
+
+// contactCSVHeader lists the columns of contacts rendered as CSV
+var contactCSVHeader = []string{"id", "first_name", "last_name", "phone_number", "email", "company", "job_title", "source", "stage",
+	"address", "street", "city", "region", "postal_code", "country_code", "timezone", "latitude", "longitude"}
+
+// contactTable renders contacts as CSV rows, one per contact
+func contactTable(contacts ...dtos.GetContactsResponseDto) render.Table {
+	rows := make([][]string, len(contacts))
+	for i, contact := range contacts {
+		rows[i] = []string{
+			strconv.Itoa(contact.ID),
+			contact.FirstName,
+			contact.LastName,
+			contact.PhoneNumber,
+			contact.Email,
+			contact.Company,
+			contact.JobTitle,
+			contact.Source,
+			contact.Stage,
+			contact.Address,
+			contact.Street,
+			contact.City,
+			contact.Region,
+			contact.PostalCode,
+			contact.CountryCode,
+			contact.Timezone,
+			formatOptionalFloat(contact.Latitude),
+			formatOptionalFloat(contact.Longitude),
+		}
+	}
+	return render.Table{Header: contactCSVHeader, Rows: rows}
+}
+
+// localizePhoneNumber sets the formatted phone number of a contact for a reader in region. Numbers that could not be
+// normalized are read as national numbers of region, or kept as entered
+func localizePhoneNumber(contact *dtos.GetContactsResponseDto, region string) {
+	e164, ok := contact.PhoneNumberE164, contact.PhoneNumberE164 != ""
+	if !ok {
+		e164, ok = phone.Normalize(contact.PhoneNumber, region)
+	}
+	contact.PhoneNumberFormatted = contact.PhoneNumber
+	if ok {
+		contact.PhoneNumberFormatted = phone.Format(e164, region)
+	}
+}
+
+func formatOptionalFloat(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'f', -1, 64)
+}
+
+// JSON:API resource types
+const (
+	jsonAPITypeContacts = "contacts"
+	jsonAPITypeGroups   = "groups"
+	jsonAPITypeTags     = "tags"
+)
+
+// toContactResources renders contacts as JSON:API resources related to their groups and tags, the groups and tags
+// are returned once each as included resources
+func toContactResources(contacts []dtos.GetContactsResponseDto, memberships map[int]dtos.ContactMembershipsDto) ([]render.Resource, []render.Resource, error) {
+	resources := make([]render.Resource, len(contacts))
+	included := []render.Resource{}
+	seen := map[render.ResourceIdentifier]bool{}
+	include := func(resourceType, id, name string) render.ResourceIdentifier {
+		identifier := render.ResourceIdentifier{Type: resourceType, ID: id}
+		if !seen[identifier] {
+			seen[identifier] = true
+			included = append(included, render.Resource{Type: resourceType, ID: id, Attributes: map[string]interface{}{"name": name}})
+		}
+		return identifier
+	}
+
+	for i, contact := range contacts {
+		resource, err := render.NewResource(jsonAPITypeContacts, contact.ID, contact)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		groups := render.Relationship{Data: []render.ResourceIdentifier{}}
+		tags := render.Relationship{Data: []render.ResourceIdentifier{}}
+		for _, group := range memberships[contact.ID].Groups {
+			groups.Data = append(groups.Data, include(jsonAPITypeGroups, strconv.Itoa(group.ID), group.Name))
+		}
+		// Tags are identified by name in the API (/tags/:name)
+		for _, tag := range memberships[contact.ID].Tags {
+			tags.Data = append(tags.Data, include(jsonAPITypeTags, tag.Name, tag.Name))
+		}
+
+		resource.Relationships = map[string]render.Relationship{"groups": groups, "tags": tags}
+		resource.Links = map[string]string{"self": fmt.Sprintf("/contacts/%d", contact.ID)}
+		resources[i] = resource
+	}
+	return resources, included, nil
+}
@@ -0,0 +1,11 @@
+package api
+
+import (
+	"github.com/danizion/contact-app/internal/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// GetMetrics handles admin GET requests for the application counters, served in the expvar JSON format
+func (h *Handler) GetMetrics(c *gin.Context) {
+	metrics.Handler().ServeHTTP(c.Writer, c.Request)
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/gin-gonic/gin"
+)
+
+// GetPicklist handles GET requests listing the allowed values of a picklist field
+func (h *Handler) GetPicklist(c *gin.Context) {
+	field := c.Param("field")
+
+	result, err := h.picklistService.WithContext(c.Request.Context()).GetPicklist(field)
+	if err != nil {
+		slog.Error("Failed to retrieve picklist", "error", err, "field", field)
+		respondError(c, err, "Failed to retrieve picklist")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// AddPicklistValue handles admin POST requests adding an allowed value to a picklist field
+func (h *Handler) AddPicklistValue(c *gin.Context) {
+	var req dtos.CreatePicklistValueRequestDto
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid add picklist value request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Field = c.Param("field")
+
+	err := h.picklistService.WithContext(c.Request.Context()).AddPicklistValue(req)
+	if err != nil {
+		slog.Error("Failed to add picklist value", "error", err, "field", req.Field)
+		respondError(c, err, "Failed to add picklist value")
+		return
+	}
+
+	slog.Info("Picklist value added", "field", req.Field, "value", req.Value, "userID", h.getUserID(c))
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Picklist value added successfully",
+	})
+}
+
+// DeletePicklistValue handles admin DELETE requests removing an allowed value from a picklist field
+func (h *Handler) DeletePicklistValue(c *gin.Context) {
+	field := c.Param("field")
+	value := c.Param("value")
+
+	err := h.picklistService.WithContext(c.Request.Context()).RemovePicklistValue(field, value)
+	if err != nil {
+		slog.Error("Failed to delete picklist value", "error", err, "field", field)
+		respondError(c, err, "Failed to delete picklist value")
+		return
+	}
+
+	slog.Info("Picklist value deleted", "field", field, "value", value, "userID", h.getUserID(c))
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Picklist value deleted successfully",
+	})
+}
+
+// GetContactStats handles GET requests aggregating the user's contacts by stage and source
+func (h *Handler) GetContactStats(c *gin.Context) {
+	userID := h.getUserID(c)
+
+	result, err := h.contactService.WithContext(c.Request.Context()).GetContactStats(userID)
+	if err != nil {
+		slog.Error("Failed to retrieve contact stats", "error", err, "userID", userID)
+		respondError(c, err, "Failed to retrieve contact stats")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
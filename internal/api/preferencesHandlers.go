@@ -0,0 +1,74 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/gin-gonic/gin"
+)
+
+// GetPreferences handles GET requests for the preferences of the current user
+func (h *Handler) GetPreferences(c *gin.Context) {
+	userID := h.getUserID(c)
+
+	result, err := h.preferencesService.WithContext(c.Request.Context()).GetPreferences(userID)
+	if err != nil {
+		slog.Error("Failed to get preferences", "error", err, "userID", userID)
+		respondError(c, err, "Failed to get preferences")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// UpdatePreferences handles PATCH requests changing the preferences of the current user
+func (h *Handler) UpdatePreferences(c *gin.Context) {
+	var req dtos.UpdatePreferencesRequestDto
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid update preferences request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.UserID = h.getUserID(c)
+
+	result, err := h.preferencesService.WithContext(c.Request.Context()).UpdatePreferences(req)
+	if err != nil {
+		respondError(c, err, "Failed to update preferences")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetNotificationPreferences handles GET requests for the notification preferences matrix of the current user
+func (h *Handler) GetNotificationPreferences(c *gin.Context) {
+	userID := h.getUserID(c)
+
+	result, err := h.preferencesService.WithContext(c.Request.Context()).GetNotificationPreferences(userID)
+	if err != nil {
+		respondError(c, err, "Failed to get notification preferences")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// UpdateNotificationPreferences handles PATCH requests turning notifications of the current user on or off
+func (h *Handler) UpdateNotificationPreferences(c *gin.Context) {
+	var req dtos.UpdateNotificationPreferencesRequestDto
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid update notification preferences request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.UserID = h.getUserID(c)
+
+	result, err := h.preferencesService.WithContext(c.Request.Context()).UpdateNotificationPreferences(req)
+	if err != nil {
+		respondError(c, err, "Failed to update notification preferences")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
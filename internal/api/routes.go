@@ -0,0 +1,432 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/demo"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/middlewares"
+	"github.com/danizion/contact-app/internal/policy"
+	"github.com/gin-gonic/gin"
+)
+
+// Access levels of a route
+const (
+	AccessPublic = "public"
+	AccessUser   = "user"
+	AccessAdmin  = "admin"
+)
+
+// Route describes an endpoint, the same table registers the handlers and generates the OpenAPI document and the clients
+type Route struct {
+	Method  string
+	Path    string
+	Name    string
+	Summary string
+	Access  string
+	// Resource is the policy resource type admin routes are authorized against
+	Resource string
+	// Query lists the query string parameters the handler reads
+	Query []string
+	// Body and Response are zero values of the request and response DTOs, nil when there is none
+	Body     interface{}
+	Response interface{}
+	Status   int
+	// FileField is the multipart form field of an upload, the request has no JSON body then
+	FileField string
+	// Raw is the content type of a response streamed as is (files, CSV), empty for JSON responses
+	Raw string
+	// Paginated responses are dtos.PaginationResult pages selected with the page query parameter or with the cursor
+	// of the previous page
+	Paginated bool
+	// Negotiated responses are also available as XML and CSV depending on the Accept header, see render.Respond
+	Negotiated bool
+	// DemoDisabled routes are rejected in demo mode, so are admin routes changing data
+	DemoDisabled bool
+	// RateLimit caps the requests per minute of a client to this route on top of the listener limit, 0 for none.
+	// RouteOptions.RouteRateLimits overrides it
+	RateLimit int
+	// Timeout overrides RouteOptions.RequestTimeout, for the requests legitimately taking longer
+	Timeout time.Duration
+	handler func(*Handler, *gin.Context)
+}
+
+// Routes returns every endpoint of the API
+func Routes() []Route {
+	return []Route{
+		// users
+		{Method: http.MethodPost, Path: "/users", Name: "CreateUser", Summary: "Register a user", Access: AccessPublic,
+			Body: dtos.CreateUserRequestDto{}, Response: dtos.CreateUserResponseDto{}, Status: http.StatusCreated,
+			RateLimit: constants.SignupRateLimitPerMinute, handler: (*Handler).CreateUser},
+		{Method: http.MethodPost, Path: "/login", Name: "Login", Summary: "Log in and get an access token and a refresh token", Access: AccessPublic,
+			Body: dtos.LoginRequestDto{}, Response: dtos.LoginResponseDto{}, RateLimit: constants.LoginRateLimitPerMinute, handler: (*Handler).Login},
+		{Method: http.MethodPost, Path: "/token/refresh", Name: "RefreshToken", Summary: "Exchange a refresh token for new tokens", Access: AccessPublic,
+			Body: dtos.RefreshTokenRequestDto{}, Response: dtos.LoginResponseDto{}, handler: (*Handler).RefreshToken},
+		{Method: http.MethodPost, Path: "/logout", Name: "Logout", Summary: "Revoke the access token and the refresh token of the session", Access: AccessUser,
+			Body: dtos.LogoutRequestDto{}, Response: dtos.MessageResponseDto{}, handler: (*Handler).Logout},
+		{Method: http.MethodGet, Path: "/users/me", Name: "GetProfile", Summary: "Get the account of the current user", Access: AccessUser,
+			Response: dtos.ProfileResponseDto{}, handler: (*Handler).GetProfile},
+		{Method: http.MethodPatch, Path: "/users/me", Name: "UpdateProfile", Summary: "Change the username and the email of the current user", Access: AccessUser,
+			Body: dtos.UpdateProfileRequestDto{}, Response: dtos.ProfileResponseDto{}, DemoDisabled: true, handler: (*Handler).UpdateProfile},
+		{Method: http.MethodDelete, Path: "/users/me", Name: "DeleteAccount", Summary: "Delete the current user with its contacts and everything else it owns", Access: AccessUser,
+			Body: dtos.DeleteAccountRequestDto{}, Response: dtos.MessageResponseDto{}, DemoDisabled: true, handler: (*Handler).DeleteAccount},
+		{Method: http.MethodPost, Path: "/users/me/deactivate", Name: "DeactivateAccount", Summary: "Freeze the current user, keeping its data, until reactivated from a link sent by email", Access: AccessUser,
+			Body: dtos.DeactivateAccountRequestDto{}, Response: dtos.MessageResponseDto{}, DemoDisabled: true, handler: (*Handler).DeactivateAccount},
+		{Method: http.MethodPost, Path: "/users/reactivate", Name: "RequestReactivation", Summary: "Email a reactivation link to a deactivated account", Access: AccessPublic,
+			Body: dtos.RequestReactivationDto{}, Response: dtos.MessageResponseDto{}, Status: http.StatusAccepted,
+			RateLimit: constants.ReactivationRateLimitPerMinute, handler: (*Handler).RequestReactivation},
+		{Method: http.MethodGet, Path: "/users/reactivate/confirm", Name: "ConfirmReactivation", Summary: "Reactivate an account from a link sent by email", Access: AccessPublic,
+			Query: []string{"token"}, Response: dtos.MessageResponseDto{}, handler: (*Handler).ConfirmReactivation},
+		{Method: http.MethodPost, Path: "/users/me/email", Name: "RequestEmailChange", Summary: "Change the email once confirmed from the current and the new address", Access: AccessUser,
+			Body: dtos.RequestEmailChangeDto{}, Response: dtos.EmailChangeDto{}, Status: http.StatusAccepted, DemoDisabled: true, handler: (*Handler).RequestEmailChange},
+		{Method: http.MethodDelete, Path: "/users/me/email", Name: "CancelEmailChange", Summary: "Cancel the pending email change", Access: AccessUser,
+			Response: dtos.MessageResponseDto{}, handler: (*Handler).CancelEmailChange},
+		{Method: http.MethodGet, Path: "/users/email/confirm", Name: "ConfirmEmailChange", Summary: "Confirm an email change from a link sent by email", Access: AccessPublic,
+			Query: []string{"token"}, Response: dtos.ConfirmEmailChangeResponseDto{}, handler: (*Handler).ConfirmEmailChange},
+		{Method: http.MethodPut, Path: "/users/me/username", Name: "ChangeUsername", Summary: "Change the username, at most once every 30 days", Access: AccessUser,
+			Body: dtos.ChangeUsernameRequestDto{}, Response: dtos.ProfileResponseDto{}, DemoDisabled: true, handler: (*Handler).ChangeUsername},
+		{Method: http.MethodGet, Path: "/users/me/usage", Name: "GetAPIUsage", Summary: "Get the API requests of the current user by day and endpoint", Access: AccessUser,
+			Query: []string{"days"}, Response: dtos.APIUsageResponseDto{}, handler: (*Handler).GetAPIUsage},
+		{Method: http.MethodGet, Path: "/users/by-username/:username", Name: "LookupUsername", Summary: "Find the account of a username, following former usernames", Access: AccessUser,
+			Response: dtos.UserLookupResponseDto{}, handler: (*Handler).LookupUsername},
+		{Method: http.MethodPut, Path: "/users/me/password", Name: "ChangePassword", Summary: "Change the password and revoke every other session", Access: AccessUser,
+			Body: dtos.ChangePasswordRequestDto{}, Response: dtos.LoginResponseDto{}, DemoDisabled: true, handler: (*Handler).ChangePassword},
+		{Method: http.MethodGet, Path: "/announcements", Name: "GetAnnouncements", Summary: "List the announcements the current user did not dismiss", Access: AccessUser,
+			Response: dtos.AnnouncementListResponseDto{}, handler: (*Handler).GetAnnouncements},
+		{Method: http.MethodPost, Path: "/announcements/:id/dismiss", Name: "DismissAnnouncement", Summary: "Hide an announcement from the current user", Access: AccessUser,
+			Response: dtos.MessageResponseDto{}, handler: (*Handler).DismissAnnouncement},
+		{Method: http.MethodGet, Path: "/users/me/preferences", Name: "GetPreferences", Summary: "Get the preferences of the current user", Access: AccessUser,
+			Response: dtos.PreferencesResponseDto{}, handler: (*Handler).GetPreferences},
+		{Method: http.MethodPatch, Path: "/users/me/preferences", Name: "UpdatePreferences", Summary: "Update the preferences of the current user", Access: AccessUser,
+			Body: dtos.UpdatePreferencesRequestDto{}, Response: dtos.PreferencesResponseDto{}, handler: (*Handler).UpdatePreferences},
+		{Method: http.MethodGet, Path: "/users/me/notifications", Name: "GetNotificationPreferences", Summary: "Get the notification preferences matrix of the current user", Access: AccessUser,
+			Response: dtos.NotificationPreferencesDto{}, handler: (*Handler).GetNotificationPreferences},
+		{Method: http.MethodPatch, Path: "/users/me/notifications", Name: "UpdateNotificationPreferences", Summary: "Turn notifications of the current user on or off by event and channel", Access: AccessUser,
+			Body: dtos.UpdateNotificationPreferencesRequestDto{}, Response: dtos.NotificationPreferencesDto{}, handler: (*Handler).UpdateNotificationPreferences},
+		{Method: http.MethodGet, Path: "/users/me/export", Name: "ExportAccount", Summary: "Download the whole account as a versioned archive", Access: AccessUser,
+			Response: dtos.AccountArchiveDto{}, handler: (*Handler).ExportAccount},
+		{Method: http.MethodPost, Path: "/users/me/import", Name: "ImportAccount", Summary: "Add the contacts, groups, tags and preferences of an account archive", Access: AccessUser,
+			Body: dtos.AccountArchiveDto{}, Response: dtos.ImportAccountResponseDto{}, handler: (*Handler).ImportAccount},
+		{Method: http.MethodGet, Path: "/api-keys", Name: "ListAPIKeys", Summary: "List API keys", Access: AccessUser,
+			Response: dtos.APIKeyListResponseDto{}, handler: (*Handler).ListAPIKeys},
+		{Method: http.MethodPost, Path: "/api-keys", Name: "CreateAPIKey", Summary: "Create an API key", Access: AccessUser,
+			Body: dtos.CreateAPIKeyRequestDto{}, Response: dtos.APIKeyResponseDto{}, Status: http.StatusCreated, handler: (*Handler).CreateAPIKey},
+		{Method: http.MethodDelete, Path: "/api-keys/:id", Name: "DeleteAPIKey", Summary: "Revoke an API key", Access: AccessUser,
+			Response: dtos.MessageResponseDto{}, handler: (*Handler).DeleteAPIKey},
+		{Method: http.MethodGet, Path: "/embed-tokens", Name: "ListEmbedTokens", Summary: "List the read-only tokens embedding contact lists", Access: AccessUser,
+			Response: dtos.EmbedTokenListResponseDto{}, handler: (*Handler).ListEmbedTokens},
+		{Method: http.MethodPost, Path: "/embed-tokens", Name: "CreateEmbedToken", Summary: "Create a read-only token embedding a group or a saved search in another page", Access: AccessUser,
+			Body: dtos.CreateEmbedTokenRequestDto{}, Response: dtos.EmbedTokenDto{}, Status: http.StatusCreated, handler: (*Handler).CreateEmbedToken},
+		{Method: http.MethodDelete, Path: "/embed-tokens/:id", Name: "RevokeEmbedToken", Summary: "Revoke an embed token", Access: AccessUser,
+			Response: dtos.MessageResponseDto{}, handler: (*Handler).RevokeEmbedToken},
+		{Method: http.MethodGet, Path: "/embed/contacts", Name: "GetEmbeddedContacts", Summary: "List the contacts of an embed token, without logging in", Access: AccessPublic,
+			Query: []string{"token", "page", "page_size"}, Response: dtos.EmbedContactListResponseDto{},
+			RateLimit: constants.EmbedRateLimitPerMinute, handler: (*Handler).GetEmbeddedContacts},
+
+		// contacts
+		{Method: http.MethodGet, Path: "/contacts", Name: "GetContacts", Summary: "List contacts, filtered and paginated", Access: AccessUser,
+			Query: []string{"page", "page_size", "cursor", "sort_by", "sort_dir", "first_name", "last_name", "phone_number", "address",
+				"social", "group", "tag", "q", "inactive_for"},
+			Response: dtos.PaginationResult{}, Paginated: true, Negotiated: true, handler: (*Handler).GetContacts},
+		{Method: http.MethodGet, Path: "/contacts/:id", Name: "GetContact", Summary: "Get a contact", Access: AccessUser,
+			Response: dtos.GetContactsResponseDto{}, Negotiated: true, handler: (*Handler).GetContact},
+		{Method: http.MethodPost, Path: "/contacts", Name: "CreateContact", Summary: "Create a contact", Access: AccessUser,
+			Body: dtos.CreateContactRequestDto{}, Response: dtos.CreateContactResponseDto{}, Status: http.StatusCreated, handler: (*Handler).CreateContact},
+		{Method: http.MethodPatch, Path: "/contacts/:id", Name: "UpdateContact", Summary: "Update a contact", Access: AccessUser,
+			Body: dtos.UpdateContactRequestDto{}, Response: dtos.MessageResponseDto{}, handler: (*Handler).UpdateContact},
+		{Method: http.MethodDelete, Path: "/contacts/:id", Name: "DeleteContact", Summary: "Delete a contact", Access: AccessUser,
+			Query: []string{"permanent"}, Response: dtos.MessageResponseDto{}, handler: (*Handler).DeleteContact},
+		{Method: http.MethodGet, Path: "/contacts/incomplete", Name: "GetIncompleteContacts", Summary: "List the contacts missing data, least complete first", Access: AccessUser,
+			Query: []string{"missing", "page", "page_size"}, Response: dtos.IncompleteContactsResponseDto{}, handler: (*Handler).GetIncompleteContacts},
+		{Method: http.MethodGet, Path: "/contacts/duplicates", Name: "GetDuplicateContacts", Summary: "List the pairs of contacts likely to be the same person, with the score of each signal", Access: AccessUser,
+			Query: []string{"sensitivity"}, Response: dtos.DuplicateContactsResponseDto{}, handler: (*Handler).GetDuplicateContacts},
+		{Method: http.MethodGet, Path: "/contacts/:id/completeness", Name: "GetContactCompleteness", Summary: "Score how filled in a contact is", Access: AccessUser,
+			Response: dtos.ContactCompletenessDto{}, handler: (*Handler).GetContactCompleteness},
+		{Method: http.MethodGet, Path: "/contacts/trash", Name: "ListTrash", Summary: "List the deleted contacts of the trash", Access: AccessUser,
+			Response: dtos.TrashListResponseDto{}, handler: (*Handler).ListTrash},
+		{Method: http.MethodGet, Path: "/contacts/trash/:id", Name: "GetTrashedContact", Summary: "Get a deleted contact of the trash", Access: AccessUser,
+			Response: dtos.TrashedContactDto{}, handler: (*Handler).GetTrashedContact},
+		{Method: http.MethodPost, Path: "/contacts/:id/restore", Name: "RestoreContact", Summary: "Restore a contact from the trash", Access: AccessUser,
+			Response: dtos.GetContactsResponseDto{}, handler: (*Handler).RestoreContact},
+		{Method: http.MethodGet, Path: "/contacts/:id/history", Name: "GetContactHistory", Summary: "List the changes of a contact", Access: AccessUser,
+			Response: dtos.ContactHistoryResponseDto{}, handler: (*Handler).GetContactHistory},
+		{Method: http.MethodGet, Path: "/contacts/changes", Name: "GetContactChanges", Summary: "Wait for changes to the contacts after a cursor (long polling)", Access: AccessUser,
+			Query: []string{"since", "wait"}, Response: dtos.ContactChangesResponseDto{}, Timeout: constants.LongPollRequestTimeout,
+			handler: (*Handler).GetContactChanges},
+		{Method: http.MethodGet, Path: "/sync/contacts/:id", Name: "GetSyncContact", Summary: "Get a contact with its version for a sync client", Access: AccessUser,
+			Response: dtos.SyncContactDto{}, handler: (*Handler).GetSyncContact},
+		{Method: http.MethodPatch, Path: "/sync/contacts/:id", Name: "SyncUpdateContact", Summary: "Merge the changes a sync client made to a contact at a base version, field by field", Access: AccessUser,
+			Body: dtos.SyncUpdateContactRequestDto{}, Response: dtos.SyncUpdateContactResponseDto{}, handler: (*Handler).SyncUpdateContact},
+		{Method: http.MethodPost, Path: "/sync/batch", Name: "ApplySyncBatch", Summary: "Apply the queued mutations of an offline client in order, all or none", Access: AccessUser,
+			Body: dtos.SyncBatchRequestDto{}, Response: dtos.SyncBatchResponseDto{}, handler: (*Handler).ApplySyncBatch},
+		{Method: http.MethodGet, Path: "/contacts/count", Name: "GetContactCount", Summary: "Count contacts and unread changes, for polling", Access: AccessUser,
+			Query: []string{"since"}, Response: dtos.ContactCountResponseDto{}, handler: (*Handler).GetContactCount},
+		{Method: http.MethodGet, Path: "/contacts/stats", Name: "GetContactStats", Summary: "Count contacts by stage and source", Access: AccessUser,
+			Response: dtos.ContactStatsResponseDto{}, handler: (*Handler).GetContactStats},
+		{Method: http.MethodGet, Path: "/contacts/geojson", Name: "GetContactsGeoJSON", Summary: "Get contacts as GeoJSON for the map view", Access: AccessUser,
+			Query:    []string{"first_name", "last_name", "phone_number", "address", "social", "group", "zoom"},
+			Response: dtos.GeoJSONFeatureCollectionDto{}, handler: (*Handler).GetContactsGeoJSON},
+		{Method: http.MethodPost, Path: "/contacts/import/card-image", Name: "ImportCardImage", Summary: "Extract a draft contact from a business card photo", Access: AccessUser,
+			FileField: "image", Response: dtos.CardImportResponseDto{}, handler: (*Handler).ImportCardImage},
+		{Method: http.MethodPost, Path: "/contacts/import", Name: "ImportContactFile", Summary: "Add the contacts of a CSV or vCard file, reporting the rows not imported", Access: AccessUser,
+			FileField: "file", Response: dtos.ContactFileImportResponseDto{}, handler: (*Handler).ImportContactFile},
+		{Method: http.MethodGet, Path: "/contacts/export", Name: "ExportContactFile", Summary: "Download every contact as a CSV or vCard file", Access: AccessUser,
+			Query: []string{"format"}, Raw: "text/csv", handler: (*Handler).ExportContactFile},
+
+		// board
+		{Method: http.MethodGet, Path: "/contacts/board", Name: "GetBoard", Summary: "Get the kanban board", Access: AccessUser,
+			Query: []string{"field", "column", "page", "page_size"}, Response: dtos.BoardResponseDto{}, handler: (*Handler).GetBoard},
+		{Method: http.MethodPost, Path: "/contacts/:id/move", Name: "MoveContact", Summary: "Move a contact to a board column", Access: AccessUser,
+			Body: dtos.MoveContactRequestDto{}, Response: dtos.MessageResponseDto{}, handler: (*Handler).MoveContact},
+
+		// enrichment and social profiles
+		{Method: http.MethodPost, Path: "/contacts/:id/enrich", Name: "EnrichContact", Summary: "Fetch enrichment suggestions for a contact", Access: AccessUser,
+			Response: dtos.EnrichmentResponseDto{}, Status: http.StatusCreated, handler: (*Handler).EnrichContact},
+		{Method: http.MethodGet, Path: "/contacts/:id/enrichments", Name: "ListEnrichments", Summary: "List the enrichment suggestions of a contact", Access: AccessUser,
+			Response: dtos.EnrichmentListResponseDto{}, handler: (*Handler).ListEnrichments},
+		{Method: http.MethodPost, Path: "/contacts/:id/enrichments/:enrichmentId/accept", Name: "AcceptEnrichment", Summary: "Apply an enrichment suggestion", Access: AccessUser,
+			Response: dtos.MessageResponseDto{}, handler: (*Handler).AcceptEnrichment},
+		{Method: http.MethodPost, Path: "/contacts/:id/enrichments/:enrichmentId/reject", Name: "RejectEnrichment", Summary: "Discard an enrichment suggestion", Access: AccessUser,
+			Response: dtos.MessageResponseDto{}, handler: (*Handler).RejectEnrichment},
+		{Method: http.MethodGet, Path: "/contacts/:id/social", Name: "GetSocialProfiles", Summary: "List the social profiles of a contact", Access: AccessUser,
+			Response: dtos.SocialProfileListResponseDto{}, handler: (*Handler).GetSocialProfiles},
+		{Method: http.MethodPut, Path: "/contacts/:id/social/:network", Name: "SetSocialProfile", Summary: "Set the profile of a contact on a social network", Access: AccessUser,
+			Body: dtos.SetSocialProfileRequestDto{}, Response: dtos.SocialProfileDto{}, handler: (*Handler).SetSocialProfile},
+		{Method: http.MethodDelete, Path: "/contacts/:id/social/:network", Name: "DeleteSocialProfile", Summary: "Remove the profile of a contact on a social network", Access: AccessUser,
+			Response: dtos.MessageResponseDto{}, handler: (*Handler).DeleteSocialProfile},
+		{Method: http.MethodGet, Path: "/contacts/:id/interactions", Name: "GetInteractions", Summary: "List the interactions logged with a contact", Access: AccessUser,
+			Response: dtos.InteractionListResponseDto{}, handler: (*Handler).GetInteractions},
+		{Method: http.MethodPost, Path: "/contacts/:id/interactions", Name: "LogInteraction", Summary: "Log a call, meeting or message with a contact", Access: AccessUser,
+			Body: dtos.LogInteractionRequestDto{}, Response: dtos.InteractionDto{}, Status: http.StatusCreated, handler: (*Handler).LogInteraction},
+		{Method: http.MethodPost, Path: "/contacts/:id/tags", Name: "TagContact", Summary: "Attach a tag to a contact", Access: AccessUser,
+			Body: dtos.TagContactRequestDto{}, Response: dtos.ContactTagsResponseDto{}, handler: (*Handler).TagContact},
+		{Method: http.MethodDelete, Path: "/contacts/:id/tags/:name", Name: "UntagContact", Summary: "Detach a tag from a contact", Access: AccessUser,
+			Response: dtos.ContactTagsResponseDto{}, handler: (*Handler).UntagContact},
+
+		// attachments
+		{Method: http.MethodGet, Path: "/contacts/:id/attachments", Name: "ListAttachments", Summary: "List the attachments of a contact", Access: AccessUser,
+			Response: dtos.AttachmentListResponseDto{}, handler: (*Handler).ListAttachments},
+		{Method: http.MethodPost, Path: "/contacts/:id/attachments", Name: "UploadAttachment", Summary: "Attach a file to a contact", Access: AccessUser,
+			FileField: "file", Response: dtos.AttachmentResponseDto{}, Status: http.StatusCreated, DemoDisabled: true, handler: (*Handler).UploadAttachment},
+		{Method: http.MethodGet, Path: "/contacts/:id/attachments/:attachmentId/url", Name: "GetAttachmentURL", Summary: "Get a signed download link for an attachment", Access: AccessUser,
+			Response: dtos.AttachmentURLResponseDto{}, handler: (*Handler).GetAttachmentURL},
+		{Method: http.MethodDelete, Path: "/contacts/:id/attachments/:attachmentId", Name: "DeleteAttachment", Summary: "Delete an attachment", Access: AccessUser,
+			Response: dtos.MessageResponseDto{}, handler: (*Handler).DeleteAttachment},
+		{Method: http.MethodGet, Path: "/attachments/:attachmentId/download", Name: "DownloadAttachment", Summary: "Download an attachment from a signed link", Access: AccessPublic,
+			Query: []string{"expires", "signature"}, Raw: "application/octet-stream", handler: (*Handler).DownloadAttachment},
+
+		// shared address book
+		{Method: http.MethodPost, Path: "/contacts/:id/share", Name: "ShareContact", Summary: "Submit a contact to the address book shared with every user", Access: AccessUser,
+			Response: dtos.SharedContactDto{}, handler: (*Handler).ShareContact},
+		{Method: http.MethodDelete, Path: "/contacts/:id/share", Name: "UnshareContact", Summary: "Take a contact out of the shared address book", Access: AccessUser,
+			Response: dtos.MessageResponseDto{}, handler: (*Handler).UnshareContact},
+		{Method: http.MethodGet, Path: "/shared-contacts", Name: "ListSharedContacts", Summary: "List the contacts shared with every user", Access: AccessUser,
+			Response: dtos.SharedContactListResponseDto{}, handler: (*Handler).ListSharedContacts},
+		{Method: http.MethodGet, Path: "/shared-contacts/submissions", Name: "ListSharedSubmissions", Summary: "List the contacts the current user submitted with their review", Access: AccessUser,
+			Response: dtos.SharedContactListResponseDto{}, handler: (*Handler).ListSharedSubmissions},
+		{Method: http.MethodGet, Path: "/shared-contacts/pending", Name: "ListPendingSharedContacts", Summary: "List the submissions waiting for review, admins and editors only", Access: AccessUser,
+			Response: dtos.SharedContactListResponseDto{}, handler: (*Handler).ListPendingSharedContacts},
+		{Method: http.MethodGet, Path: "/shared-contacts/:id", Name: "GetSharedContact", Summary: "Get a contact of the shared address book and mark it viewed", Access: AccessUser,
+			Response: dtos.SharedContactDto{}, handler: (*Handler).GetSharedContact},
+		{Method: http.MethodPost, Path: "/shared-contacts/:id/approve", Name: "ApproveSharedContact", Summary: "Approve a submission to the shared address book, admins and editors only", Access: AccessUser,
+			Body: dtos.ReviewSharedContactRequestDto{}, Response: dtos.SharedContactDto{}, handler: (*Handler).ApproveSharedContact},
+		{Method: http.MethodPost, Path: "/shared-contacts/:id/reject", Name: "RejectSharedContact", Summary: "Reject a submission to the shared address book, admins and editors only", Access: AccessUser,
+			Body: dtos.ReviewSharedContactRequestDto{}, Response: dtos.SharedContactDto{}, handler: (*Handler).RejectSharedContact},
+
+		// team directory
+		{Method: http.MethodGet, Path: "/directory/:slug", Name: "GetDirectory", Summary: "Search the public team directory", Access: AccessPublic,
+			Query: []string{"q", "page", "page_size"}, Response: dtos.DirectoryResponseDto{}, handler: (*Handler).GetDirectory},
+		{Method: http.MethodGet, Path: "/branding", Name: "GetBranding", Summary: "Get the name, support email and logo of the deployment for its public pages", Access: AccessPublic,
+			Response: dtos.BrandingDto{}, handler: (*Handler).GetBranding},
+		{Method: http.MethodPut, Path: "/users/me/directory-card", Name: "SetDirectoryCard", Summary: "Pick the contact standing for the current user in the team directory", Access: AccessUser,
+			Body: dtos.SetDirectoryCardRequestDto{}, Response: dtos.MessageResponseDto{}, handler: (*Handler).SetDirectoryCard},
+		{Method: http.MethodGet, Path: "/admin/directory", Name: "GetDirectorySettings", Summary: "Get the settings of the team directory", Access: AccessAdmin, Resource: policy.ResourceDirectory,
+			Response: dtos.DirectorySettingsDto{}, handler: (*Handler).GetDirectorySettings},
+		{Method: http.MethodPut, Path: "/admin/directory", Name: "SaveDirectorySettings", Summary: "Enable the team directory and choose its path, source and visible fields", Access: AccessAdmin, Resource: policy.ResourceDirectory,
+			Body: dtos.DirectorySettingsDto{}, Response: dtos.DirectorySettingsDto{}, handler: (*Handler).SaveDirectorySettings},
+
+		// picklists
+		{Method: http.MethodGet, Path: "/picklists/:field", Name: "GetPicklist", Summary: "List the allowed values of a picklist field", Access: AccessUser,
+			Response: dtos.PicklistResponseDto{}, handler: (*Handler).GetPicklist},
+		{Method: http.MethodPost, Path: "/admin/picklists/:field", Name: "AddPicklistValue", Summary: "Add an allowed value to a picklist field", Access: AccessAdmin, Resource: policy.ResourcePicklist,
+			Body: dtos.CreatePicklistValueRequestDto{}, Response: dtos.MessageResponseDto{}, Status: http.StatusCreated, handler: (*Handler).AddPicklistValue},
+		{Method: http.MethodDelete, Path: "/admin/picklists/:field/:value", Name: "DeletePicklistValue", Summary: "Remove an allowed value from a picklist field", Access: AccessAdmin, Resource: policy.ResourcePicklist,
+			Response: dtos.MessageResponseDto{}, handler: (*Handler).DeletePicklistValue},
+
+		// contact template
+		{Method: http.MethodGet, Path: "/contact-template", Name: "GetContactTemplate", Summary: "Get the custom fields and default tags of contacts", Access: AccessUser,
+			Response: dtos.ContactTemplateDto{}, handler: (*Handler).GetContactTemplate},
+		{Method: http.MethodPut, Path: "/admin/contact-template/fields/:key", Name: "SaveCustomField", Summary: "Create or replace a custom field of the contact template", Access: AccessAdmin, Resource: policy.ResourceContactTemplate,
+			Body: dtos.SaveCustomFieldRequestDto{}, Response: dtos.ContactTemplateDto{}, handler: (*Handler).SaveCustomField},
+		{Method: http.MethodDelete, Path: "/admin/contact-template/fields/:key", Name: "DeleteCustomField", Summary: "Remove a custom field from the contact template", Access: AccessAdmin, Resource: policy.ResourceContactTemplate,
+			Response: dtos.ContactTemplateDto{}, handler: (*Handler).DeleteCustomField},
+		{Method: http.MethodPut, Path: "/admin/contact-template/tags", Name: "SetTemplateTags", Summary: "Set the tags attached to every new contact", Access: AccessAdmin, Resource: policy.ResourceContactTemplate,
+			Body: dtos.SetTemplateTagsRequestDto{}, Response: dtos.ContactTemplateDto{}, handler: (*Handler).SetTemplateTags},
+		{Method: http.MethodGet, Path: "/admin/contact-template/migration", Name: "GetTemplateMigration", Summary: "Report the migration of the contacts to the current contact template", Access: AccessAdmin, Resource: policy.ResourceContactTemplate,
+			Response: dtos.ContactTemplateMigrationDto{}, handler: (*Handler).GetTemplateMigration},
+
+		// operations
+		{Method: http.MethodGet, Path: "/admin/metrics", Name: "GetMetrics", Summary: "Get the application counters", Access: AccessAdmin, Resource: policy.ResourceMetrics,
+			Raw: "application/json", handler: (*Handler).GetMetrics},
+		{Method: http.MethodGet, Path: "/admin/stats", Name: "GetAdminStats", Summary: "Get instance wide numbers for the admin dashboard", Access: AccessAdmin, Resource: policy.ResourceMetrics,
+			Response: dtos.AdminStatsResponseDto{}, handler: (*Handler).GetAdminStats},
+		{Method: http.MethodGet, Path: "/admin/usage", Name: "GetAdminAPIUsage", Summary: "Get the API requests of every user by day and endpoint, with the top users", Access: AccessAdmin, Resource: policy.ResourceMetrics,
+			Query: []string{"days"}, Response: dtos.AdminAPIUsageResponseDto{}, handler: (*Handler).GetAdminAPIUsage},
+		{Method: http.MethodGet, Path: "/admin/users/duplicates", Name: "ListDuplicateUsers", Summary: "List the accounts sharing an email, candidates for a merge", Access: AccessAdmin, Resource: policy.ResourceUser,
+			Response: dtos.DuplicateUsersResponseDto{}, handler: (*Handler).ListDuplicateUsers},
+		{Method: http.MethodPost, Path: "/admin/users/merge", Name: "MergeUsers", Summary: "Merge an account into another and delete it", Access: AccessAdmin, Resource: policy.ResourceUser,
+			Body: dtos.MergeUsersRequestDto{}, Response: dtos.MergeUsersResponseDto{}, handler: (*Handler).MergeUsers},
+		{Method: http.MethodPost, Path: "/admin/maintenance/jobs", Name: "StartMaintenanceJob", Summary: "Rebuild search indexes, normalized phone numbers, per-user totals or the caches of the most active users in the background", Access: AccessAdmin, Resource: policy.ResourceMaintenance,
+			Body: dtos.StartMaintenanceJobRequestDto{}, Response: dtos.MaintenanceJobDto{}, Status: http.StatusAccepted, handler: (*Handler).StartMaintenanceJob},
+		{Method: http.MethodGet, Path: "/admin/maintenance/jobs", Name: "ListMaintenanceJobs", Summary: "List the maintenance jobs of the replica answering", Access: AccessAdmin, Resource: policy.ResourceMaintenance,
+			Response: dtos.MaintenanceJobListResponseDto{}, handler: (*Handler).ListMaintenanceJobs},
+		{Method: http.MethodGet, Path: "/admin/maintenance/jobs/:id", Name: "GetMaintenanceJob", Summary: "Get the status and result of a maintenance job", Access: AccessAdmin, Resource: policy.ResourceMaintenance,
+			Response: dtos.MaintenanceJobDto{}, handler: (*Handler).GetMaintenanceJob},
+		{Method: http.MethodGet, Path: "/admin/announcements", Name: "ListAnnouncements", Summary: "List every announcement, ended and scheduled ones included", Access: AccessAdmin, Resource: policy.ResourceSettings,
+			Response: dtos.AnnouncementListResponseDto{}, handler: (*Handler).ListAnnouncements},
+		{Method: http.MethodPost, Path: "/admin/announcements", Name: "CreateAnnouncement", Summary: "Post an announcement to every user", Access: AccessAdmin, Resource: policy.ResourceSettings,
+			Body: dtos.CreateAnnouncementRequestDto{}, Response: dtos.AnnouncementDto{}, Status: http.StatusCreated, handler: (*Handler).CreateAnnouncement},
+		{Method: http.MethodDelete, Path: "/admin/announcements/:id", Name: "DeleteAnnouncement", Summary: "Remove an announcement", Access: AccessAdmin, Resource: policy.ResourceSettings,
+			Response: dtos.MessageResponseDto{}, handler: (*Handler).DeleteAnnouncement},
+		{Method: http.MethodGet, Path: "/admin/analytics", Name: "GetAnalyticsSettings", Summary: "Get the instance wide analytics settings", Access: AccessAdmin, Resource: policy.ResourceSettings,
+			Response: dtos.AnalyticsSettingsDto{}, handler: (*Handler).GetAnalyticsSettings},
+		{Method: http.MethodPut, Path: "/admin/analytics", Name: "UpdateAnalyticsSettings", Summary: "Opt the instance in or out of analytics", Access: AccessAdmin, Resource: policy.ResourceSettings,
+			Body: dtos.UpdateAnalyticsSettingsRequestDto{}, Response: dtos.AnalyticsSettingsDto{}, handler: (*Handler).UpdateAnalyticsSettings},
+		{Method: http.MethodGet, Path: "/admin/alerts", Name: "GetAlertSettings", Summary: "Get the 5xx error budget alerting settings and route states", Access: AccessAdmin, Resource: policy.ResourceSettings,
+			Response: dtos.AlertSettingsDto{}, handler: (*Handler).GetAlertSettings},
+		{Method: http.MethodPut, Path: "/admin/alerts/suppressions", Name: "SetRouteSuppression", Summary: "Set how long alerts of a route are suppressed after one fires", Access: AccessAdmin, Resource: policy.ResourceSettings,
+			Body: dtos.SetRouteSuppressionRequestDto{}, Response: dtos.AlertSettingsDto{}, handler: (*Handler).SetRouteSuppression},
+
+		// audit log
+		{Method: http.MethodGet, Path: "/audit/export", Name: "ExportAuditLog", Summary: "Export the audit log as CSV", Access: AccessUser,
+			Query: []string{"format", "action", "actor", "user_id", "from", "to"}, Raw: "text/csv", handler: (*Handler).ExportAuditLog},
+
+		// groups and tags
+		{Method: http.MethodGet, Path: "/groups", Name: "ListGroups", Summary: "List contact groups", Access: AccessUser,
+			Response: dtos.GroupListResponseDto{}, handler: (*Handler).ListGroups},
+		{Method: http.MethodPost, Path: "/groups", Name: "CreateGroup", Summary: "Create a contact group", Access: AccessUser,
+			Body: dtos.CreateGroupRequestDto{}, Response: dtos.GroupResponseDto{}, Status: http.StatusCreated, handler: (*Handler).CreateGroup},
+		{Method: http.MethodDelete, Path: "/groups/:id", Name: "DeleteGroup", Summary: "Delete a contact group", Access: AccessUser,
+			Response: dtos.MessageResponseDto{}, handler: (*Handler).DeleteGroup},
+		{Method: http.MethodPost, Path: "/groups/:id/contacts", Name: "AddContactsToGroup", Summary: "Add contacts to a group", Access: AccessUser,
+			Body: dtos.BulkContactsRequestDto{}, Response: dtos.BulkContactsResponseDto{}, handler: (*Handler).AddContactsToGroup},
+		{Method: http.MethodDelete, Path: "/groups/:id/contacts", Name: "RemoveContactsFromGroup", Summary: "Remove contacts from a group", Access: AccessUser,
+			Body: dtos.BulkContactsRequestDto{}, Response: dtos.BulkContactsResponseDto{}, handler: (*Handler).RemoveContactsFromGroup},
+		{Method: http.MethodGet, Path: "/tags", Name: "ListTags", Summary: "List tags", Access: AccessUser,
+			Response: dtos.TagListResponseDto{}, handler: (*Handler).ListTags},
+		{Method: http.MethodPost, Path: "/tags", Name: "CreateTag", Summary: "Create a tag", Access: AccessUser,
+			Body: dtos.CreateTagRequestDto{}, Response: dtos.TagResponseDto{}, Status: http.StatusCreated, handler: (*Handler).CreateTag},
+		{Method: http.MethodDelete, Path: "/tags/:name", Name: "DeleteTag", Summary: "Delete a tag, its contacts are kept", Access: AccessUser,
+			Response: dtos.MessageResponseDto{}, handler: (*Handler).DeleteTag},
+		{Method: http.MethodPost, Path: "/tags/:name/contacts", Name: "AddContactsToTag", Summary: "Tag contacts", Access: AccessUser,
+			Body: dtos.BulkContactsRequestDto{}, Response: dtos.BulkContactsResponseDto{}, handler: (*Handler).AddContactsToTag},
+		{Method: http.MethodDelete, Path: "/tags/:name/contacts", Name: "RemoveContactsFromTag", Summary: "Untag contacts", Access: AccessUser,
+			Body: dtos.BulkContactsRequestDto{}, Response: dtos.BulkContactsResponseDto{}, handler: (*Handler).RemoveContactsFromTag},
+
+		// snapshots
+		{Method: http.MethodGet, Path: "/snapshots", Name: "ListSnapshots", Summary: "List address book snapshots", Access: AccessUser,
+			Response: dtos.SnapshotListResponseDto{}, handler: (*Handler).ListSnapshots},
+		{Method: http.MethodPost, Path: "/snapshots", Name: "CreateSnapshot", Summary: "Take a snapshot of the address book", Access: AccessUser,
+			Body: dtos.CreateSnapshotRequestDto{}, Response: dtos.SnapshotResponseDto{}, Status: http.StatusCreated, handler: (*Handler).CreateSnapshot},
+		{Method: http.MethodDelete, Path: "/snapshots/:id", Name: "DeleteSnapshot", Summary: "Delete a snapshot", Access: AccessUser,
+			Response: dtos.MessageResponseDto{}, handler: (*Handler).DeleteSnapshot},
+		{Method: http.MethodGet, Path: "/snapshots/:id/diff", Name: "DiffSnapshot", Summary: "Compare a snapshot with the current address book", Access: AccessUser,
+			Response: dtos.SnapshotDiffResponseDto{}, handler: (*Handler).DiffSnapshot},
+		{Method: http.MethodPost, Path: "/snapshots/:id/restore", Name: "RestoreSnapshot", Summary: "Restore the address book from a snapshot", Access: AccessUser,
+			Response: dtos.RestoreSnapshotResponseDto{}, handler: (*Handler).RestoreSnapshot},
+
+		// webhooks
+		{Method: http.MethodGet, Path: "/webhooks", Name: "ListWebhooks", Summary: "List webhooks", Access: AccessUser,
+			Response: dtos.WebhookListResponseDto{}, handler: (*Handler).ListWebhooks},
+		{Method: http.MethodPost, Path: "/webhooks", Name: "CreateWebhook", Summary: "Register a webhook URL", Access: AccessUser,
+			Body: dtos.CreateWebhookRequestDto{}, Response: dtos.WebhookResponseDto{}, Status: http.StatusCreated, DemoDisabled: true, handler: (*Handler).CreateWebhook},
+		{Method: http.MethodDelete, Path: "/webhooks/:id", Name: "DeleteWebhook", Summary: "Delete a webhook", Access: AccessUser,
+			Response: dtos.MessageResponseDto{}, handler: (*Handler).DeleteWebhook},
+		{Method: http.MethodGet, Path: "/webhooks/:id/deliveries", Name: "ListWebhookDeliveries", Summary: "List the latest deliveries of a webhook with their status", Access: AccessUser,
+			Response: dtos.WebhookDeliveryListResponseDto{}, handler: (*Handler).ListWebhookDeliveries},
+		{Method: http.MethodPost, Path: "/webhooks/test", Name: "TestWebhook", Summary: "Send a sample signed event to a webhook", Access: AccessUser,
+			Body: dtos.TestWebhookRequestDto{}, Response: dtos.TestWebhookResponseDto{}, DemoDisabled: true, handler: (*Handler).TestWebhook},
+	}
+}
+
+// RouteOptions select the routes a listener serves and tune its middlewares
+type RouteOptions struct {
+	// Access lists the access levels of the routes registered
+	Access []string
+	// RateLimitPerMinute caps the requests per user or client IP, 0 disables rate limiting
+	RateLimitPerMinute int
+	// RouteRateLimits overrides the RateLimit of routes by name, 0 removes the limit of a route
+	RouteRateLimits map[string]int
+	// RequestTimeout cancels the requests taking longer, 0 leaves them unbounded
+	RequestTimeout time.Duration
+}
+
+// RegisterRoutes registers the endpoints of Routes with one of the access levels of options on router, behind the
+// middlewares of their access level. Requests are rate limited per user once authenticated, per client IP on public
+// routes, and the routes with their own limit are also counted apart. In demo mode the DemoDisabled routes and the
+// admin routes changing data are rejected. Every route counts against the 5xx error budget when alerting is enabled,
+// and authenticated responses carry the announcement of the user in X-Announcement. Requests are canceled after the
+// timeout of their route
+func RegisterRoutes(router gin.IRoutes, h *Handler, options RouteOptions) {
+	authenticate := middlewares.Authenticate(h.apiKeyService, h.sessionService)
+	announce := middlewares.Announce(h.announcementService)
+	requireActive := middlewares.RequireActiveAccount(h.accountStateService)
+	rateLimit := func(c *gin.Context) { c.Next() }
+	if options.RateLimitPerMinute > 0 {
+		rateLimit = middlewares.RateLimit(h.rateLimiter, options.RateLimitPerMinute, constants.RateLimitWindow)
+	}
+	served := make(map[string]bool, len(options.Access))
+	for _, access := range options.Access {
+		served[access] = true
+	}
+	demoMode := demo.Enabled()
+
+	for _, route := range Routes() {
+		if !served[route.Access] {
+			continue
+		}
+		var handlers []gin.HandlerFunc
+		timeout := options.RequestTimeout
+		if route.Timeout > 0 {
+			timeout = route.Timeout
+		}
+		if timeout > 0 {
+			handlers = append(handlers, middlewares.Timeout(timeout))
+		}
+		if h.alertMonitor != nil {
+			handlers = append(handlers, middlewares.ErrorBudget(h.alertMonitor, route.Method+" "+route.Path))
+		}
+		switch route.Access {
+		case AccessPublic:
+			handlers = append(handlers, rateLimit)
+		case AccessUser:
+			handlers = append(handlers, authenticate, requireActive, middlewares.TrackUsage(h.usageService, route.Name), rateLimit, announce)
+		case AccessAdmin:
+			if route.Resource == "" {
+				panic(fmt.Sprintf("admin route %s has no policy resource", route.Name))
+			}
+			handlers = append(handlers, authenticate, requireActive, middlewares.TrackUsage(h.usageService, route.Name), rateLimit, announce)
+		}
+		routeLimit := route.RateLimit
+		if limit, ok := options.RouteRateLimits[route.Name]; ok {
+			routeLimit = limit
+		}
+		// After the listener limit so the headers describe the stricter limit of the route
+		if routeLimit > 0 {
+			handlers = append(handlers, middlewares.RouteRateLimit(h.rateLimiter, route.Name, routeLimit, constants.RateLimitWindow))
+		}
+		if route.Access == AccessAdmin {
+			handlers = append(handlers, middlewares.Authorize(route.Resource))
+		}
+		if demoMode && (route.DemoDisabled || route.Access == AccessAdmin && route.Method != http.MethodGet) {
+			handlers = append(handlers, middlewares.DisabledInDemo())
+		}
+
+		handle := route.handler
+		handlers = append(handlers, func(c *gin.Context) { handle(h, c) })
+		router.Handle(route.Method, route.Path, handlers...)
+	}
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/danizion/contact-app/internal/auth"
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/gin-gonic/gin"
+)
+
+// RefreshToken handles POST requests exchanging a refresh token for new access and refresh tokens
+func (h *Handler) RefreshToken(c *gin.Context) {
+	var req dtos.RefreshTokenRequestDto
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid refresh token request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	session, err := h.sessionService.WithContext(c.Request.Context()).Refresh(req.RefreshToken)
+	if err != nil {
+		slog.Error("Failed to refresh token", "error", err)
+		respondError(c, err, "Failed to refresh token")
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// Logout handles POST requests ending the current session, the access token of the request stops working at once
+func (h *Handler) Logout(c *gin.Context) {
+	var req dtos.LogoutRequestDto
+	// The body is optional, without it only the access token is revoked
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		slog.Error("Invalid logout request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID := h.getUserID(c)
+	// Unset for API key requests, which have no access token to revoke
+	claims, _ := c.Value(constants.AuthTokenKey).(*auth.Claims)
+
+	if err := h.sessionService.WithContext(c.Request.Context()).Logout(userID, claims, req.RefreshToken); err != nil {
+		slog.Error("Failed to log out", "error", err, "userID", userID)
+		respondError(c, err, "Failed to log out")
+		return
+	}
+
+	slog.Info("Logout successful", "userID", userID)
+	c.JSON(http.StatusOK, dtos.MessageResponseDto{Message: "Logged out"})
+}
+
+// ChangePassword handles PUT requests changing the password of the current user. Every session of the user is
+// revoked and the tokens of a new one are returned
+func (h *Handler) ChangePassword(c *gin.Context) {
+	var req dtos.ChangePasswordRequestDto
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid change password request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID := h.getUserID(c)
+
+	user, err := h.userService.WithContext(c.Request.Context()).ChangePassword(userID, req, c.ClientIP())
+	if err != nil {
+		slog.Error("Failed to change password", "error", err, "userID", userID)
+		respondError(c, err, "Failed to change password")
+		return
+	}
+
+	session, err := h.sessionService.WithContext(c.Request.Context()).RevokeSessions(user)
+	if err != nil {
+		slog.Error("Failed to revoke sessions", "error", err, "userID", userID)
+		respondError(c, err, "Password changed but failed to revoke sessions")
+		return
+	}
+
+	slog.Info("Password changed", "userID", userID)
+	c.JSON(http.StatusOK, session)
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+// ShareContact handles POST requests submitting a contact to the shared address book, pending review when review is
+// enabled
+func (h *Handler) ShareContact(c *gin.Context) {
+	contactID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contact ID"})
+		return
+	}
+
+	result, err := h.sharedBookService.WithContext(c.Request.Context()).Submit(middlewares.Subject(c), contactID)
+	if err != nil {
+		respondError(c, err, "Failed to share contact")
+		return
+	}
+
+	slog.Info("Contact submitted to the shared address book", "contactID", contactID, "status", result.Status, "userID", h.getUserID(c))
+	c.JSON(http.StatusOK, result)
+}
+
+// UnshareContact handles DELETE requests taking a contact out of the shared address book
+func (h *Handler) UnshareContact(c *gin.Context) {
+	contactID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contact ID"})
+		return
+	}
+
+	if err := h.sharedBookService.WithContext(c.Request.Context()).Withdraw(h.getUserID(c), contactID); err != nil {
+		respondError(c, err, "Failed to unshare contact")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Contact unshared successfully"})
+}
+
+// ListSharedContacts handles GET requests for the contacts shared with every user
+func (h *Handler) ListSharedContacts(c *gin.Context) {
+	result, err := h.sharedBookService.WithContext(c.Request.Context()).ListShared(h.getUserID(c))
+	if err != nil {
+		slog.Error("Failed to list shared contacts", "error", err)
+		respondError(c, err, "Failed to list shared contacts")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetSharedContact handles GET requests for a contact of the shared address book, marking it viewed by the current
+// user
+func (h *Handler) GetSharedContact(c *gin.Context) {
+	contactID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contact ID"})
+		return
+	}
+
+	result, err := h.sharedBookService.WithContext(c.Request.Context()).Get(h.getUserID(c), contactID)
+	if err != nil {
+		respondError(c, err, "Failed to get shared contact")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ListSharedSubmissions handles GET requests for the contacts the current user submitted
+func (h *Handler) ListSharedSubmissions(c *gin.Context) {
+	result, err := h.sharedBookService.WithContext(c.Request.Context()).ListSubmissions(h.getUserID(c))
+	if err != nil {
+		slog.Error("Failed to list shared submissions", "error", err)
+		respondError(c, err, "Failed to list submissions")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ListPendingSharedContacts handles GET requests for the review queue of the shared address book
+func (h *Handler) ListPendingSharedContacts(c *gin.Context) {
+	result, err := h.sharedBookService.WithContext(c.Request.Context()).ListPending(middlewares.Subject(c))
+	if err != nil {
+		respondError(c, err, "Failed to list pending contacts")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ApproveSharedContact handles POST requests approving a submission to the shared address book
+func (h *Handler) ApproveSharedContact(c *gin.Context) {
+	h.reviewSharedContact(c, true)
+}
+
+// RejectSharedContact handles POST requests rejecting a submission to the shared address book
+func (h *Handler) RejectSharedContact(c *gin.Context) {
+	h.reviewSharedContact(c, false)
+}
+
+func (h *Handler) reviewSharedContact(c *gin.Context, approve bool) {
+	contactID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contact ID"})
+		return
+	}
+	var req dtos.ReviewSharedContactRequestDto
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			slog.Error("Invalid review request", "error", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var result *dtos.SharedContactDto
+	if approve {
+		result, err = h.sharedBookService.WithContext(c.Request.Context()).Approve(middlewares.Subject(c), contactID, req)
+	} else {
+		result, err = h.sharedBookService.WithContext(c.Request.Context()).Reject(middlewares.Subject(c), contactID, req)
+	}
+	if err != nil {
+		respondError(c, err, "Failed to review shared contact")
+		return
+	}
+
+	slog.Info("Shared contact reviewed", "contactID", contactID, "status", result.Status, "reviewerID", h.getUserID(c))
+	c.JSON(http.StatusOK, result)
+}
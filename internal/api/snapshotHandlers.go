@@ -0,0 +1,104 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateSnapshot handles POST requests taking a snapshot of the address book
+func (h *Handler) CreateSnapshot(c *gin.Context) {
+	var req dtos.CreateSnapshotRequestDto
+	// The body is optional, a snapshot without a name is named after its creation time
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			slog.Error("Invalid create snapshot request", "error", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	req.UserID = h.getUserID(c)
+
+	result, err := h.snapshotService.WithContext(c.Request.Context()).CreateSnapshot(req)
+	if err != nil {
+		slog.Error("Failed to create snapshot", "error", err, "userID", req.UserID)
+		respondError(c, err, "Failed to create snapshot")
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// ListSnapshots handles GET requests listing the user's snapshots
+func (h *Handler) ListSnapshots(c *gin.Context) {
+	userID := h.getUserID(c)
+
+	result, err := h.snapshotService.WithContext(c.Request.Context()).ListSnapshots(userID)
+	if err != nil {
+		slog.Error("Failed to list snapshots", "error", err, "userID", userID)
+		respondError(c, err, "Failed to list snapshots")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": result})
+}
+
+// DeleteSnapshot handles DELETE requests removing a snapshot
+func (h *Handler) DeleteSnapshot(c *gin.Context) {
+	snapshotID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid snapshot ID"})
+		return
+	}
+	userID := h.getUserID(c)
+
+	if err := h.snapshotService.WithContext(c.Request.Context()).DeleteSnapshot(userID, snapshotID); err != nil {
+		slog.Error("Failed to delete snapshot", "error", err, "snapshotID", snapshotID)
+		respondError(c, err, "Failed to delete snapshot")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Snapshot deleted successfully"})
+}
+
+// DiffSnapshot handles GET requests comparing a snapshot with the current address book
+func (h *Handler) DiffSnapshot(c *gin.Context) {
+	snapshotID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid snapshot ID"})
+		return
+	}
+	userID := h.getUserID(c)
+
+	result, err := h.snapshotService.WithContext(c.Request.Context()).DiffSnapshot(userID, snapshotID)
+	if err != nil {
+		slog.Error("Failed to diff snapshot", "error", err, "snapshotID", snapshotID)
+		respondError(c, err, "Failed to diff snapshot")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// RestoreSnapshot handles POST requests restoring the address book to a snapshot
+func (h *Handler) RestoreSnapshot(c *gin.Context) {
+	snapshotID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid snapshot ID"})
+		return
+	}
+	userID := h.getUserID(c)
+
+	result, err := h.snapshotService.WithContext(c.Request.Context()).RestoreSnapshot(userID, snapshotID)
+	if err != nil {
+		slog.Error("Failed to restore snapshot", "error", err, "snapshotID", snapshotID)
+		respondError(c, err, "Failed to restore snapshot")
+		return
+	}
+
+	slog.Info("Snapshot restored", "snapshotID", snapshotID, "userID", userID)
+	c.JSON(http.StatusOK, result)
+}
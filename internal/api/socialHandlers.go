@@ -0,0 +1,81 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/gin-gonic/gin"
+)
+
+// GetSocialProfiles handles GET requests listing the social profiles of a contact
+func (h *Handler) GetSocialProfiles(c *gin.Context) {
+	contactID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		slog.Error("Invalid contact ID", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contact ID"})
+		return
+	}
+	userID := h.getUserID(c)
+
+	result, err := h.contactService.WithContext(c.Request.Context()).GetSocialProfiles(userID, contactID)
+	if err != nil {
+		slog.Error("Failed to get social profiles", "error", err, "contactID", contactID)
+		respondError(c, err, "Failed to get social profiles")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": result})
+}
+
+// SetSocialProfile handles PUT requests setting a contact's profile on a social network
+func (h *Handler) SetSocialProfile(c *gin.Context) {
+	contactID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		slog.Error("Invalid contact ID", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contact ID"})
+		return
+	}
+
+	var req dtos.SetSocialProfileRequestDto
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid set social profile request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID := h.getUserID(c)
+	network := c.Param("network")
+
+	result, err := h.contactService.WithContext(c.Request.Context()).SetSocialProfile(userID, contactID, network, req.Value)
+	if err != nil {
+		slog.Error("Failed to set social profile", "error", err, "contactID", contactID, "network", network)
+		respondError(c, err, "Failed to set social profile")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// DeleteSocialProfile handles DELETE requests removing a contact's profile on a social network
+func (h *Handler) DeleteSocialProfile(c *gin.Context) {
+	contactID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		slog.Error("Invalid contact ID", "id", c.Param("id"), "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contact ID"})
+		return
+	}
+	userID := h.getUserID(c)
+	network := c.Param("network")
+
+	err = h.contactService.WithContext(c.Request.Context()).DeleteSocialProfile(userID, contactID, network)
+	if err != nil {
+		slog.Error("Failed to delete social profile", "error", err, "contactID", contactID, "network", network)
+		respondError(c, err, "Failed to delete social profile")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Social profile deleted successfully",
+	})
+}
@@ -0,0 +1,20 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetAdminStats handles GET requests for the instance wide numbers of the admin dashboard
+func (h *Handler) GetAdminStats(c *gin.Context) {
+	result, err := h.statsService.GetAdminStats()
+	if err != nil {
+		slog.Error("Failed to get admin stats", "error", err)
+		respondError(c, err, "Failed to get admin stats")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/gin-gonic/gin"
+)
+
+// addressSyncFields are the fields whose change locates the contact again
+var addressSyncFields = map[string]bool{
+	"address": true, "street": true, "city": true, "region": true, "postal_code": true, "country_code": true,
+}
+
+// GetSyncContact handles GET requests for a contact with its version, the base of the changes of a sync client
+func (h *Handler) GetSyncContact(c *gin.Context) {
+	contactID, ok := h.contactIDParam(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.syncService.WithContext(c.Request.Context()).GetContact(h.getUserID(c), contactID)
+	if err != nil {
+		respondError(c, err, "Failed to get contact")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// SyncUpdateContact handles PATCH requests merging the changes a sync client made to a contact at a base version
+func (h *Handler) SyncUpdateContact(c *gin.Context) {
+	contactID, ok := h.contactIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req dtos.SyncUpdateContactRequestDto
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid sync update request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Changes.UserID = h.getUserID(c)
+	req.Changes.ID = contactID
+
+	result, err := h.syncService.WithContext(c.Request.Context()).UpdateContact(req)
+	if err != nil {
+		respondError(c, err, "Failed to update contact")
+		return
+	}
+
+	h.locateSyncedContact(req.Changes.UserID, contactID, result.Applied)
+
+	slog.Info("Contact synced", "contactID", contactID, "userID", req.Changes.UserID, "applied", len(result.Applied), "conflicts", len(result.Conflicts))
+	c.JSON(http.StatusOK, result)
+}
+
+// ApplySyncBatch handles POST requests applying the queue of mutations of an offline sync client
+func (h *Handler) ApplySyncBatch(c *gin.Context) {
+	var req dtos.SyncBatchRequestDto
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid sync batch request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID := h.getUserID(c)
+
+	result, err := h.syncService.WithContext(c.Request.Context()).ApplyBatch(userID, req)
+	if err != nil {
+		respondError(c, err, "Failed to apply sync batch")
+		return
+	}
+
+	if result.Committed && !result.Replayed {
+		for _, res := range result.Results {
+			if res.Status != constants.SyncMutationApplied || res.ContactID == 0 {
+				continue
+			}
+			switch res.Op {
+			case "create":
+				h.enrichmentService.EnrichOnCreate(userID, res.ContactID)
+				h.geocodeService.GeocodeInBackground(userID, res.ContactID)
+			case "update":
+				h.locateSyncedContact(userID, res.ContactID, res.Applied)
+			}
+		}
+	}
+
+	slog.Info("Sync batch applied", "userID", userID, "mutations", len(req.Mutations), "committed", result.Committed, "replayed", result.Replayed)
+	c.JSON(http.StatusOK, result)
+}
+
+// locateSyncedContact locates a contact again in the background when a sync changed its address without coordinates
+func (h *Handler) locateSyncedContact(userID, contactID int, applied []string) {
+	locate, located := false, false
+	for _, field := range applied {
+		locate = locate || addressSyncFields[field]
+		located = located || field == "latitude"
+	}
+	if locate && !located {
+		h.geocodeService.GeocodeInBackground(userID, contactID)
+	}
+}
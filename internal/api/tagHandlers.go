@@ -0,0 +1,104 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateTag handles POST requests creating a tag
+func (h *Handler) CreateTag(c *gin.Context) {
+	var req dtos.CreateTagRequestDto
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid create tag request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.UserID = h.getUserID(c)
+
+	result, err := h.tagService.WithContext(c.Request.Context()).CreateTag(req)
+	if err != nil {
+		slog.Error("Failed to create tag", "error", err, "userID", req.UserID)
+		respondError(c, err, "Failed to create tag")
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// ListTags handles GET requests listing the user's tags
+func (h *Handler) ListTags(c *gin.Context) {
+	userID := h.getUserID(c)
+
+	result, err := h.tagService.WithContext(c.Request.Context()).ListTags(userID)
+	if err != nil {
+		slog.Error("Failed to list tags", "error", err, "userID", userID)
+		respondError(c, err, "Failed to list tags")
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.TagListResponseDto{Items: result})
+}
+
+// DeleteTag handles DELETE requests removing a tag, its contacts are kept
+func (h *Handler) DeleteTag(c *gin.Context) {
+	userID := h.getUserID(c)
+	name := c.Param("name")
+
+	if err := h.tagService.WithContext(c.Request.Context()).DeleteTag(userID, name); err != nil {
+		slog.Error("Failed to delete tag", "error", err, "tag", name)
+		respondError(c, err, "Failed to delete tag")
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.MessageResponseDto{Message: "Tag deleted successfully"})
+}
+
+// TagContact handles POST requests attaching a tag to a contact
+func (h *Handler) TagContact(c *gin.Context) {
+	contactID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contact ID"})
+		return
+	}
+
+	var req dtos.TagContactRequestDto
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid tag contact request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID := h.getUserID(c)
+
+	result, err := h.tagService.WithContext(c.Request.Context()).TagContact(userID, contactID, req.Name)
+	if err != nil {
+		slog.Error("Failed to tag contact", "error", err, "contactID", contactID, "tag", req.Name)
+		respondError(c, err, "Failed to tag contact")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// UntagContact handles DELETE requests detaching a tag from a contact
+func (h *Handler) UntagContact(c *gin.Context) {
+	contactID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contact ID"})
+		return
+	}
+	userID := h.getUserID(c)
+	name := c.Param("name")
+
+	result, err := h.tagService.WithContext(c.Request.Context()).UntagContact(userID, contactID, name)
+	if err != nil {
+		slog.Error("Failed to untag contact", "error", err, "contactID", contactID, "tag", name)
+		respondError(c, err, "Failed to untag contact")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/gin-gonic/gin"
+)
+
+// ListTrash handles GET requests listing the deleted contacts of the user
+func (h *Handler) ListTrash(c *gin.Context) {
+	userID := h.getUserID(c)
+
+	contacts, err := h.contactService.WithContext(c.Request.Context()).ListTrash(userID)
+	if err != nil {
+		slog.Error("Failed to list trash", "error", err, "userID", userID)
+		respondError(c, err, "Failed to list trash")
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.TrashListResponseDto{Items: contacts})
+}
+
+// GetTrashedContact handles GET requests for a single contact of the trash
+func (h *Handler) GetTrashedContact(c *gin.Context) {
+	contactID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contact ID"})
+		return
+	}
+	userID := h.getUserID(c)
+
+	contact, err := h.contactService.WithContext(c.Request.Context()).GetTrashedContact(userID, contactID)
+	if err != nil {
+		slog.Error("Failed to get trashed contact", "error", err, "contactID", contactID)
+		respondError(c, err, "Failed to get trashed contact")
+		return
+	}
+	localizePhoneNumber(&contact.GetContactsResponseDto, h.phoneRegion(c, userID))
+
+	c.JSON(http.StatusOK, contact)
+}
+
+// RestoreContact handles POST requests taking a contact out of the trash
+func (h *Handler) RestoreContact(c *gin.Context) {
+	contactID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contact ID"})
+		return
+	}
+	userID := h.getUserID(c)
+
+	contact, err := h.contactService.WithContext(c.Request.Context()).RestoreContact(userID, contactID)
+	if err != nil {
+		slog.Error("Failed to restore contact", "error", err, "contactID", contactID)
+		respondError(c, err, "Failed to restore contact")
+		return
+	}
+
+	c.JSON(http.StatusOK, contact)
+}
+
+// GetContactHistory handles GET requests listing the changes of a contact, in the trash or not
+func (h *Handler) GetContactHistory(c *gin.Context) {
+	contactID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid contact ID"})
+		return
+	}
+	userID := h.getUserID(c)
+
+	history, err := h.contactService.WithContext(c.Request.Context()).GetContactHistory(userID, contactID)
+	if err != nil {
+		slog.Error("Failed to get contact history", "error", err, "contactID", contactID)
+		respondError(c, err, "Failed to get contact history")
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/gin-gonic/gin"
+)
+
+// GetAPIUsage handles GET requests for the API usage of the current user over the last days
+func (h *Handler) GetAPIUsage(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", strconv.Itoa(constants.DefaultAPIUsageDays)))
+	if err != nil || days < 1 || days > constants.MaxAPIUsageDays {
+		c.JSON(http.StatusBadRequest, gin.H{"error": constants.ErrInvalidUsageDays})
+		return
+	}
+	userID := h.getUserID(c)
+
+	result, err := h.usageService.WithContext(c.Request.Context()).GetUserUsage(userID, days)
+	if err != nil {
+		slog.Error("Failed to get API usage", "error", err, "userID", userID)
+		respondError(c, err, "Failed to get API usage")
+		return
+	}
+
+	describeEndpoints(result.Endpoints)
+	c.JSON(http.StatusOK, result)
+}
+
+// GetAdminAPIUsage handles GET requests for the API usage of every user over the last days
+func (h *Handler) GetAdminAPIUsage(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", strconv.Itoa(constants.DefaultAPIUsageDays)))
+	if err != nil || days < 1 || days > constants.MaxAPIUsageDays {
+		c.JSON(http.StatusBadRequest, gin.H{"error": constants.ErrInvalidUsageDays})
+		return
+	}
+
+	result, err := h.usageService.WithContext(c.Request.Context()).GetAdminUsage(days)
+	if err != nil {
+		slog.Error("Failed to get admin API usage", "error", err)
+		respondError(c, err, "Failed to get API usage")
+		return
+	}
+
+	describeEndpoints(result.Endpoints)
+	c.JSON(http.StatusOK, result)
+}
+
+// describeEndpoints completes endpoints counted by route name with their method, path and own rate limit from the
+// route table
+func describeEndpoints(endpoints []dtos.APIUsageEndpointDto) {
+	routes := make(map[string]Route)
+	for _, route := range Routes() {
+		routes[route.Name] = route
+	}
+	for i := range endpoints {
+		if route, ok := routes[endpoints[i].Endpoint]; ok {
+			endpoints[i].Method = route.Method
+			endpoints[i].Path = route.Path
+			endpoints[i].RateLimitPerMinute = route.RateLimit
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateWebhook handles POST requests registering a webhook URL, the response holds its signing secret
+func (h *Handler) CreateWebhook(c *gin.Context) {
+	var req dtos.CreateWebhookRequestDto
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid create webhook request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.UserID = h.getUserID(c)
+
+	result, err := h.webhookService.WithContext(c.Request.Context()).CreateWebhook(req)
+	if err != nil {
+		slog.Error("Failed to create webhook", "error", err, "userID", req.UserID)
+		respondError(c, err, "Failed to create webhook")
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// ListWebhooks handles GET requests listing the user's webhooks
+func (h *Handler) ListWebhooks(c *gin.Context) {
+	userID := h.getUserID(c)
+
+	result, err := h.webhookService.WithContext(c.Request.Context()).ListWebhooks(userID)
+	if err != nil {
+		slog.Error("Failed to list webhooks", "error", err, "userID", userID)
+		respondError(c, err, "Failed to list webhooks")
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.WebhookListResponseDto{Items: result})
+}
+
+// DeleteWebhook handles DELETE requests removing a webhook
+func (h *Handler) DeleteWebhook(c *gin.Context) {
+	webhookID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+	userID := h.getUserID(c)
+
+	if err := h.webhookService.WithContext(c.Request.Context()).DeleteWebhook(userID, webhookID); err != nil {
+		slog.Error("Failed to delete webhook", "error", err, "webhookID", webhookID)
+		respondError(c, err, "Failed to delete webhook")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted successfully"})
+}
+
+// TestWebhook handles POST requests sending a sample signed event to a webhook
+func (h *Handler) TestWebhook(c *gin.Context) {
+	var req dtos.TestWebhookRequestDto
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Error("Invalid test webhook request", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.UserID = h.getUserID(c)
+
+	result, err := h.webhookService.WithContext(c.Request.Context()).SendTestEvent(req)
+	if err != nil {
+		slog.Error("Failed to test webhook", "error", err, "webhookID", req.WebhookID)
+		respondError(c, err, "Failed to test webhook")
+		return
+	}
+
+	slog.Info("Webhook test event sent", "webhookID", req.WebhookID, "delivered", result.Delivered, "statusCode", result.StatusCode)
+	c.JSON(http.StatusOK, result)
+}
+
+// ListWebhookDeliveries handles GET requests listing the latest deliveries of a webhook with their status
+func (h *Handler) ListWebhookDeliveries(c *gin.Context) {
+	webhookID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+	userID := h.getUserID(c)
+
+	result, err := h.webhookService.WithContext(c.Request.Context()).ListDeliveries(userID, webhookID)
+	if err != nil {
+		slog.Error("Failed to list webhook deliveries", "error", err, "webhookID", webhookID)
+		respondError(c, err, "Failed to list webhook deliveries")
+		return
+	}
+
+	c.JSON(http.StatusOK, dtos.WebhookDeliveryListResponseDto{Items: result})
+}
@@ -1,9 +1,16 @@
 package auth
 
 import (
-	"github.com/danizion/contact-app/internal/utils"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/utils"
+
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -11,11 +18,55 @@ import (
 // Secret key used to sign JWT tokens - in production this should be stored securely
 var jwtSecretKey = []byte(utils.GetEnvOrDefault("AUTH_SECRET", "im-a-secret-key"))
 
+// Issuer and audience written to and required in every JWT, so tokens minted for another service sharing the
+// secret are rejected
+var (
+	jwtIssuer   = utils.GetEnvOrDefault("JWT_ISSUER", "contact-app")
+	jwtAudience = utils.GetEnvOrDefault("JWT_AUDIENCE", "contact-app-api")
+)
+
+// Lifetimes of the access tokens (JWTs) and of the refresh tokens exchanged for new ones
+var (
+	AccessTokenTTL  = envDuration("ACCESS_TOKEN_TTL", constants.DefaultAccessTokenTTL)
+	RefreshTokenTTL = envDuration("REFRESH_TOKEN_TTL", constants.DefaultRefreshTokenTTL)
+)
+
+// jwtLeeway absorbs clock skew between replicas when validating exp and iat
+const jwtLeeway = 30 * time.Second
+
+// Errors returned by ParseJWT in addition to the jwt package ones
+var (
+	ErrUnexpectedSigningMethod = errors.New("unexpected signing method")
+	ErrMissingUserID           = errors.New("token has no user id")
+)
+
+// Claims of an access token. The token ID (jti) lets a single token be revoked, Generation is the session
+// generation of the user when the token was issued, raising it revokes every older token of the user
 type Claims struct {
-	UserID int `json:"user_id"`
+	UserID     int  `json:"user_id"`
+	IsAdmin    bool `json:"is_admin,omitempty"`
+	Generation int  `json:"gen,omitempty"`
 	jwt.RegisteredClaims
 }
 
+func envDuration(key string, defaultValue time.Duration) time.Duration {
+	if value, err := time.ParseDuration(utils.GetEnvOrDefault(key, "")); err == nil && value > 0 {
+		return value
+	}
+	return defaultValue
+}
+
+// IsAdminEmail reports whether email is listed in the comma separated ADMIN_EMAILS environment variable, the
+// accounts granted the admin role by the grant-admin command when it is given no email
+func IsAdminEmail(email string) bool {
+	for _, adminEmail := range strings.Split(utils.GetEnvOrDefault("ADMIN_EMAILS", ""), ",") {
+		if adminEmail = strings.TrimSpace(adminEmail); adminEmail != "" && strings.EqualFold(adminEmail, email) {
+			return true
+		}
+	}
+	return false
+}
+
 // GetJWTSecret returns the secret key used for JWT signing and verification
 func GetJWTSecret() []byte {
 	return jwtSecretKey
@@ -36,12 +87,21 @@ func CheckPassword(password, hashedPassword string) bool {
 	return err == nil
 }
 
-// GenerateJWT creates a new JWT token for the authenticated user
-func GenerateJWT(userID int, username string) (string, error) {
-	expirationTime := time.Now().Add(24 * time.Hour)
+// GenerateJWT creates a new access token for the authenticated user, valid for AccessTokenTTL
+func GenerateJWT(userID int, username string, isAdmin bool, generation int) (string, error) {
+	tokenID, err := RandomToken(16)
+	if err != nil {
+		return "", err
+	}
+	expirationTime := time.Now().Add(AccessTokenTTL)
 	claims := &Claims{
-		UserID: userID,
+		UserID:     userID,
+		IsAdmin:    isAdmin,
+		Generation: generation,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
+			Issuer:    jwtIssuer,
+			Audience:  jwt.ClaimStrings{jwtAudience},
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
@@ -56,3 +116,48 @@ func GenerateJWT(userID int, username string) (string, error) {
 	}
 	return tokenString, nil
 }
+
+// ParseJWT verifies a token created by GenerateJWT: only HS256 is accepted (no other HMAC variant nor "none"),
+// exp, iat, iss and aud are required and validated and the token must name a user
+func ParseJWT(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if token.Method != jwt.SigningMethodHS256 {
+			return nil, fmt.Errorf("%w: %v", ErrUnexpectedSigningMethod, token.Header["alg"])
+		}
+		return jwtSecretKey, nil
+	},
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithIssuer(jwtIssuer),
+		jwt.WithAudience(jwtAudience),
+		jwt.WithLeeway(jwtLeeway),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if claims.IssuedAt == nil {
+		return nil, fmt.Errorf("%w: iat", jwt.ErrTokenRequiredClaimMissing)
+	}
+	if claims.UserID <= 0 {
+		return nil, ErrMissingUserID
+	}
+	return claims, nil
+}
+
+// RemainingLifetime is how long the token is still accepted by ParseJWT, leeway included
+func (c *Claims) RemainingLifetime() time.Duration {
+	if c.ExpiresAt == nil {
+		return 0
+	}
+	return time.Until(c.ExpiresAt.Time) + jwtLeeway
+}
+
+// RandomToken returns size random bytes hex encoded, for token IDs and refresh tokens
+func RandomToken(size int) (string, error) {
+	random := make([]byte, size)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("failed to generate random token: %w", err)
+	}
+	return hex.EncodeToString(random), nil
+}
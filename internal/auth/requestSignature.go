@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// SignRequest returns the X-Signature value of a request signed with an API key secret. The signed content is
+//
+//	METHOD \n path?query \n timestamp \n nonce \n hex(sha256(body))
+//
+// so a signature is bound to one request and cannot be reused with another body, path or nonce
+func SignRequest(secret, method, requestURI, timestamp, nonce string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	content := strings.Join([]string{strings.ToUpper(method), requestURI, timestamp, nonce, hex.EncodeToString(bodyHash[:])}, "\n")
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(content))
+	return "v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyRequestSignature checks a signature created by SignRequest
+func VerifyRequestSignature(secret, method, requestURI, timestamp, nonce string, body []byte, signature string) bool {
+	expected := SignRequest(secret, method, requestURI, timestamp, nonce, body)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
@@ -0,0 +1,25 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// SignResource returns an HMAC signature granting access to a resource until expiresAt
+func SignResource(resource string, expiresAt time.Time) string {
+	mac := hmac.New(sha256.New, jwtSecretKey)
+	mac.Write([]byte(fmt.Sprintf("%s:%d", resource, expiresAt.Unix())))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyResourceSignature checks a signature created by SignResource and that it has not expired
+func VerifyResourceSignature(resource string, expiresUnix int64, signature string) bool {
+	if time.Now().Unix() > expiresUnix {
+		return false
+	}
+	expected := SignResource(resource, time.Unix(expiresUnix, 0))
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
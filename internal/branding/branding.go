@@ -0,0 +1,34 @@
+// Package branding configures how a deployment presents itself: its name, support contact, logo and the legal text
+// ending its emails. Emails, exports and the public pages read it so self-hosters brand their instance from the
+// environment instead of forking the templates
+package branding
+
+import (
+	"strings"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/utils"
+)
+
+// Config of the branding, read from the environment
+type Config struct {
+	// InstanceName names the deployment in emails, exports and public pages
+	InstanceName string
+	// SupportEmail is where users are told to write for help, empty when none is given
+	SupportEmail string
+	// LogoURL is the logo shown by the public pages, empty when none is given
+	LogoURL string
+	// EmailFooter is the text (legal notice, address) ending every email, empty when none is given
+	EmailFooter string
+}
+
+// Load reads the branding: INSTANCE_NAME, SUPPORT_EMAIL, LOGO_URL and EMAIL_FOOTER, whose \n sequences are line
+// breaks since environment variables are usually written on one line
+func Load() Config {
+	return Config{
+		InstanceName: strings.TrimSpace(utils.GetEnvOrDefault("INSTANCE_NAME", constants.DefaultInstanceName)),
+		SupportEmail: strings.TrimSpace(utils.GetEnvOrDefault("SUPPORT_EMAIL", "")),
+		LogoURL:      strings.TrimSpace(utils.GetEnvOrDefault("LOGO_URL", "")),
+		EmailFooter:  strings.TrimSpace(strings.ReplaceAll(utils.GetEnvOrDefault("EMAIL_FOOTER", ""), `\n`, "\n")),
+	}
+}
@@ -0,0 +1,316 @@
+package config
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danizion/contact-app/internal/address"
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/server"
+)
+
+// kind is how the value of a setting is parsed
+type kind string
+
+const (
+	kindString   kind = "string"
+	kindInt      kind = "int"
+	kindBool     kind = "bool"
+	kindDuration kind = "duration"
+	kindFraction kind = "fraction"
+	kindList     kind = "list"
+	kindURL      kind = "url"
+	kindPort     kind = "port"
+	kindEmail    kind = "email"
+	kindCountry  kind = "country"
+)
+
+// Setting is an environment variable read by the application
+type Setting struct {
+	Name        string
+	Group       string
+	Default     string
+	Kind        kind
+	Secret      bool
+	Description string
+	// Values lists the accepted values when only some are
+	Values []string
+}
+
+// Settings are every environment variable the application reads, listeners add LISTEN_<NAME>_* variables
+var Settings = []Setting{
+	{Name: "POSTGRES_HOST", Group: "database", Default: "localhost", Kind: kindString, Description: "Postgres host"},
+	{Name: "POSTGRES_PORT", Group: "database", Default: "5433", Kind: kindPort, Description: "Postgres port"},
+	{Name: "POSTGRES_USER", Group: "database", Default: "myuser", Kind: kindString, Description: "Postgres user"},
+	{Name: "POSTGRES_PASSWORD", Group: "database", Default: "mypassword", Kind: kindString, Secret: true, Description: "Postgres password"},
+	{Name: "POSTGRES_DB", Group: "database", Default: "mydb", Kind: kindString, Description: "Postgres database"},
+	{Name: "SEARCH_LANGUAGE", Group: "database", Default: constants.DefaultSearchLanguage, Kind: kindString, Description: "text search configuration of contacts, users can choose their own"},
+	{Name: "DEFAULT_PHONE_REGION", Group: "database", Kind: kindCountry, Description: "country of phone numbers entered without a country code, users can choose their own"},
+	{Name: "DB_STATEMENT_TIMEOUT", Group: "database", Default: constants.DefaultDBStatementTimeout.String(), Kind: kindDuration, Description: "statements running longer are canceled by Postgres"},
+
+	{Name: "REDIS_HOST", Group: "redis", Default: "localhost", Kind: kindString, Description: "Redis host"},
+	{Name: "REDIS_PORT", Group: "redis", Default: "6379", Kind: kindPort, Description: "Redis port"},
+	{Name: "REDIS_PASSWORD", Group: "redis", Kind: kindString, Secret: true, Description: "Redis password"},
+	{Name: "REDIS_TIMEOUT", Group: "redis", Default: constants.DefaultRedisTimeout.String(), Kind: kindDuration, Description: "reads and writes of a Redis call"},
+
+	{Name: "PORT", Group: "server", Default: "8080", Kind: kindPort, Description: "port serving every route when LISTENERS is not set"},
+	{Name: "LISTENERS", Group: "server", Kind: kindList, Description: "names of the listeners, each configured by LISTEN_<NAME>_*"},
+	{Name: "TRUSTED_PROXIES", Group: "server", Kind: kindList, Description: "proxies whose forwarded client IP is believed"},
+	{Name: "RATE_LIMIT_PER_MINUTE", Group: "server", Default: strconv.Itoa(constants.DefaultRateLimitPerMinute), Kind: kindInt, Description: "requests per minute of a client"},
+	{Name: "RATE_LIMIT_ROUTES", Group: "server", Kind: kindList, Description: "per route rate limits (Name=limit)"},
+	{Name: "REQUEST_TIMEOUT", Group: "server", Default: constants.DefaultRequestTimeout.String(), Kind: kindDuration, Description: "requests are canceled after it, with their queries and Redis calls"},
+	{Name: "PUBLIC_URL", Group: "server", Default: constants.DefaultPublicURL, Kind: kindURL, Description: "URL of the app in emailed links"},
+
+	{Name: "DATA_DIR", Group: "storage", Default: "./data", Kind: kindString, Description: "local storage directory, overridden by --data-dir"},
+	{Name: "BLOB_DIR", Group: "storage", Kind: kindString, Description: "attachment blob directory, the blobs directory of the data directory by default"},
+	{Name: "MAX_ATTACHMENT_BYTES", Group: "storage", Default: strconv.Itoa(constants.DefaultMaxAttachmentBytes), Kind: kindInt, Description: "size limit of an attachment"},
+	{Name: "USER_STORAGE_QUOTA_BYTES", Group: "storage", Default: strconv.Itoa(constants.DefaultUserStorageQuotaBytes), Kind: kindInt, Description: "attachment storage of a user"},
+
+	{Name: "AUTH_SECRET", Group: "auth", Default: "im-a-secret-key", Kind: kindString, Secret: true, Description: "key signing access tokens"},
+	{Name: "JWT_ISSUER", Group: "auth", Default: "contact-app", Kind: kindString, Description: "issuer of access tokens"},
+	{Name: "JWT_AUDIENCE", Group: "auth", Default: "contact-app-api", Kind: kindString, Description: "audience of access tokens"},
+	{Name: "ACCESS_TOKEN_TTL", Group: "auth", Default: constants.DefaultAccessTokenTTL.String(), Kind: kindDuration, Description: "lifetime of access tokens"},
+	{Name: "REFRESH_TOKEN_TTL", Group: "auth", Default: constants.DefaultRefreshTokenTTL.String(), Kind: kindDuration, Description: "lifetime of refresh tokens"},
+	{Name: "ADMIN_EMAILS", Group: "auth", Kind: kindList, Description: "emails made admins by the grant-admin command"},
+	{Name: "REQUIRE_SIGNED_WRITES", Group: "auth", Default: "false", Kind: kindBool, Description: "require API key writes to be signed"},
+	{Name: "SHARED_BOOK_REVIEW", Group: "auth", Default: "false", Kind: kindBool, Description: "contacts shared with every user wait for an admin or editor approval"},
+	{Name: "SHARED_BOOK_EDITORS", Group: "auth", Kind: kindList, Description: "emails of the users reviewing shared contacts besides admins"},
+	{Name: "SIGNUP_ALLOWED_DOMAINS", Group: "auth", Kind: kindList, Description: "only these email domains may sign up"},
+	{Name: "SIGNUP_BLOCKED_DOMAINS", Group: "auth", Kind: kindList, Description: "email domains refused at signup"},
+	{Name: "SIGNUP_BLOCK_DISPOSABLE", Group: "auth", Default: "true", Kind: kindBool, Description: "refuse disposable email domains at signup"},
+
+	{Name: "SMTP_HOST", Group: "mail", Kind: kindString, Description: "SMTP server, emails are disabled without it"},
+	{Name: "SMTP_PORT", Group: "mail", Default: "587", Kind: kindPort, Description: "SMTP port"},
+	{Name: "SMTP_USERNAME", Group: "mail", Kind: kindString, Description: "SMTP user"},
+	{Name: "SMTP_PASSWORD", Group: "mail", Kind: kindString, Secret: true, Description: "SMTP password"},
+	{Name: "MAIL_FROM", Group: "mail", Default: "contacts@localhost", Kind: kindString, Description: "sender of the emails"},
+
+	{Name: "INSTANCE_NAME", Group: "branding", Default: constants.DefaultInstanceName, Kind: kindString, Description: "name of the deployment in emails, exports and public pages"},
+	{Name: "SUPPORT_EMAIL", Group: "branding", Kind: kindEmail, Description: "support address given in emails and public pages"},
+	{Name: "LOGO_URL", Group: "branding", Kind: kindURL, Description: "logo of the public pages"},
+	{Name: "EMAIL_FOOTER", Group: "branding", Kind: kindString, Description: "legal text ending every email, \\n for line breaks"},
+
+	{Name: "OCR_URL", Group: "providers", Kind: kindURL, Description: "OCR provider, business card import is disabled without it"},
+	{Name: "OCR_API_KEY", Group: "providers", Kind: kindString, Secret: true, Description: "OCR provider API key"},
+	{Name: "ENRICHMENT_PROVIDER", Group: "providers", Default: "http", Kind: kindString, Description: "name of the enrichment provider"},
+	{Name: "ENRICHMENT_URL", Group: "providers", Kind: kindURL, Description: "enrichment provider, enrichment is disabled without it"},
+	{Name: "ENRICHMENT_API_KEY", Group: "providers", Kind: kindString, Secret: true, Description: "enrichment provider API key"},
+	{Name: "ENRICH_ON_CREATE", Group: "providers", Default: "false", Kind: kindBool, Description: "enrich contacts when they are created"},
+	{Name: "GEOCODER_URL", Group: "providers", Kind: kindURL, Description: "geocoding provider, address geocoding is disabled without it"},
+	{Name: "GEOCODER_API_KEY", Group: "providers", Kind: kindString, Secret: true, Description: "geocoding provider API key"},
+	{Name: "GEOJSON_CLUSTER_THRESHOLD", Group: "providers", Default: strconv.Itoa(constants.DefaultGeoJSONClusterThreshold), Kind: kindInt, Description: "contacts on the map before they are clustered"},
+
+	{Name: "ANALYTICS_SINK", Group: "analytics", Kind: kindString, Values: []string{"postgres", "http"}, Description: "where analytics events go, analytics are disabled without it"},
+	{Name: "ANALYTICS_URL", Group: "analytics", Kind: kindURL, Description: "collector of the http sink"},
+	{Name: "ANALYTICS_API_KEY", Group: "analytics", Kind: kindString, Secret: true, Description: "collector API key"},
+	{Name: "ANALYTICS_SALT", Group: "analytics", Kind: kindString, Secret: true, Description: "salt anonymizing users, random per process without it"},
+
+	{Name: "ALERT_5XX_THRESHOLD", Group: "alerting", Default: strconv.FormatFloat(constants.DefaultAlertThreshold, 'f', -1, 64), Kind: kindFraction, Description: "share of 5xx responses raising an alert"},
+	{Name: "ALERT_MIN_REQUESTS", Group: "alerting", Default: strconv.Itoa(constants.DefaultAlertMinRequests), Kind: kindInt, Description: "requests in a window before a route can alert"},
+	{Name: "ALERT_WINDOW", Group: "alerting", Default: constants.DefaultAlertWindow.String(), Kind: kindDuration, Description: "window the 5xx share is measured over"},
+	{Name: "ALERT_SUPPRESSION", Group: "alerting", Default: constants.DefaultAlertSuppression.String(), Kind: kindDuration, Description: "quiet period after an alert"},
+	{Name: "ALERT_SLACK_WEBHOOK_URL", Group: "alerting", Kind: kindURL, Secret: true, Description: "Slack incoming webhook for alerts"},
+	{Name: "ALERT_WEBHOOK_URL", Group: "alerting", Kind: kindURL, Description: "webhook receiving alerts"},
+	{Name: "ALERT_WEBHOOK_SECRET", Group: "alerting", Kind: kindString, Secret: true, Description: "key signing alert webhooks"},
+	{Name: "ALERT_EMAIL_TO", Group: "alerting", Kind: kindEmail, Description: "email receiving alerts, needs SMTP_HOST"},
+
+	{Name: "DEMO_MODE", Group: "demo", Default: "false", Kind: kindBool, Description: "provision a public demo account"},
+	{Name: "DEMO_USERNAME", Group: "demo", Default: constants.DefaultDemoUsername, Kind: kindString, Description: "username of the demo account"},
+	{Name: "DEMO_EMAIL", Group: "demo", Default: constants.DefaultDemoEmail, Kind: kindEmail, Description: "email of the demo account"},
+	{Name: "DEMO_PASSWORD", Group: "demo", Default: constants.DefaultDemoPassword, Kind: kindString, Secret: true, Description: "password of the demo account"},
+	{Name: "DEMO_RESET_INTERVAL", Group: "demo", Default: constants.DefaultDemoResetInterval.String(), Kind: kindDuration, Description: "how often the demo data is reset"},
+}
+
+// Value is the effective value of a setting
+type Value struct {
+	Name        string `json:"name"`
+	Group       string `json:"group"`
+	Value       string `json:"value"`
+	Source      string `json:"source"`
+	Secret      bool   `json:"secret,omitempty"`
+	Description string `json:"description"`
+	Error       string `json:"error,omitempty"`
+}
+
+// Problem is a finding of the check, errors are settings the application ignores or refuses, warnings are
+// settings that work but are likely a mistake
+type Problem struct {
+	Severity string `json:"severity"`
+	Setting  string `json:"setting,omitempty"`
+	Message  string `json:"message"`
+}
+
+// Report is the effective configuration with the problems found in it
+type Report struct {
+	Values   []Value   `json:"values"`
+	Problems []Problem `json:"problems"`
+	Probes   []Probe   `json:"probes,omitempty"`
+}
+
+// Failed reports whether the check found an error or a probe failed
+func (r *Report) Failed() bool {
+	for _, problem := range r.Problems {
+		if problem.Severity == "error" {
+			return true
+		}
+	}
+	for _, probe := range r.Probes {
+		if !probe.OK {
+			return true
+		}
+	}
+	return false
+}
+
+// Check reads every setting from the environment and validates it. dataDir is the data directory the server would
+// use, the --data-dir flag or DATA_DIR
+func Check(dataDir string) *Report {
+	report := &Report{}
+	values := make(map[string]string, len(Settings))
+
+	for _, setting := range Settings {
+		value, set := os.LookupEnv(setting.Name)
+		// The application treats an empty variable as unset
+		source := "env"
+		if !set || value == "" {
+			value, source = setting.Default, "default"
+		}
+		if setting.Name == "BLOB_DIR" && source == "default" {
+			value, source = filepath.Join(dataDir, "blobs"), "data dir"
+		}
+		if setting.Name == "DATA_DIR" && dataDir != value {
+			value, source = dataDir, "flag"
+		}
+		values[setting.Name] = value
+
+		entry := Value{Name: setting.Name, Group: setting.Group, Value: value, Source: source, Secret: setting.Secret,
+			Description: setting.Description}
+		if err := setting.validate(value); err != nil {
+			entry.Error = err.Error()
+			report.add("error", setting.Name, fmt.Sprintf("%s, it is ignored", err))
+		}
+		if setting.Secret && value != "" {
+			entry.Value = mask(value)
+		}
+		report.Values = append(report.Values, entry)
+		if setting.Name == "LISTENERS" {
+			report.Values = append(report.Values, listenerValues()...)
+		}
+	}
+
+	// Listeners are validated the way the server loads them
+	if _, err := server.Load(); err != nil {
+		report.add("error", "LISTENERS", err.Error())
+	}
+
+	if values["AUTH_SECRET"] == "im-a-secret-key" {
+		report.add("warning", "AUTH_SECRET", "the built-in secret is used, anyone can sign access tokens")
+	}
+	if values["ALERT_EMAIL_TO"] != "" && values["SMTP_HOST"] == "" {
+		report.add("warning", "ALERT_EMAIL_TO", "set without SMTP_HOST, alerts are not emailed")
+	}
+	if values["ANALYTICS_SINK"] == "http" && values["ANALYTICS_URL"] == "" {
+		report.add("error", "ANALYTICS_URL", "required by the http analytics sink")
+	}
+	if values["ANALYTICS_SINK"] != "" && values["ANALYTICS_SALT"] == "" {
+		report.add("warning", "ANALYTICS_SALT", "not set, users are anonymized differently on every replica and restart")
+	}
+	if values["DEMO_MODE"] == "true" && values["DEMO_PASSWORD"] == constants.DefaultDemoPassword {
+		report.add("warning", "DEMO_PASSWORD", "the demo account uses the built-in password")
+	}
+	return report
+}
+
+func (r *Report) add(severity, setting, message string) {
+	r.Problems = append(r.Problems, Problem{Severity: severity, Setting: setting, Message: message})
+}
+
+// validate checks a value against the kind of the setting, empty values are always valid
+func (s Setting) validate(value string) error {
+	if value == "" {
+		return nil
+	}
+	if len(s.Values) > 0 {
+		for _, accepted := range s.Values {
+			if value == accepted {
+				return nil
+			}
+		}
+		return fmt.Errorf("unknown value %q, expected one of %s", value, strings.Join(s.Values, ", "))
+	}
+
+	switch s.Kind {
+	case kindInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("%q is not an integer", value)
+		}
+	case kindPort:
+		if port, err := strconv.Atoi(value); err != nil || port < 1 || port > 65535 {
+			return fmt.Errorf("%q is not a port", value)
+		}
+	case kindBool:
+		if value != "true" && value != "false" {
+			return fmt.Errorf("%q is neither true nor false", value)
+		}
+	case kindDuration:
+		if duration, err := time.ParseDuration(value); err != nil || duration <= 0 {
+			return fmt.Errorf("%q is not a positive duration such as 30m", value)
+		}
+	case kindFraction:
+		if fraction, err := strconv.ParseFloat(value, 64); err != nil || fraction <= 0 || fraction > 1 {
+			return fmt.Errorf("%q is not a fraction between 0 and 1", value)
+		}
+	case kindURL:
+		if parsed, err := url.Parse(value); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return fmt.Errorf("%s is not an absolute URL", maskURL(value, s.Secret))
+		}
+	case kindEmail:
+		if _, err := mail.ParseAddress(value); err != nil {
+			return fmt.Errorf("%q is not an email address", value)
+		}
+	case kindCountry:
+		if _, err := address.NormalizeCountryCode(value); err != nil {
+			return fmt.Errorf("%q is not an ISO 3166-1 alpha-2 country code", value)
+		}
+	}
+	return nil
+}
+
+// listenerValues lists the LISTEN_<NAME>_* variables, they are named after the listeners so are not in Settings
+func listenerValues() []Value {
+	var values []Value
+	for _, env := range os.Environ() {
+		name, value, _ := strings.Cut(env, "=")
+		if strings.HasPrefix(name, "LISTEN_") && value != "" {
+			values = append(values, Value{Name: name, Group: "server", Value: value, Source: "env", Description: "listener setting"})
+		}
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i].Name < values[j].Name })
+	return values
+}
+
+// mask hides a secret, only its length class is kept so an empty or truncated secret can still be spotted
+func mask(value string) string {
+	if len(value) < 8 {
+		return "****"
+	}
+	return "********"
+}
+
+// maskURL hides a URL carrying a secret, keeping its start for debugging
+func maskURL(value string, secret bool) string {
+	if !secret {
+		return strconv.Quote(value)
+	}
+	if len(value) > 12 {
+		return strconv.Quote(value[:12] + "****")
+	}
+	return "****"
+}
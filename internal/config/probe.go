@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/storage/blob"
+	"github.com/danizion/contact-app/internal/storage/db"
+	"github.com/danizion/contact-app/internal/storage/redis"
+	"github.com/danizion/contact-app/internal/utils"
+)
+
+// Probe is the result of a connectivity test
+type Probe struct {
+	Name     string `json:"name"`
+	OK       bool   `json:"ok"`
+	Skipped  bool   `json:"skipped,omitempty"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// RunProbes connects to the services the configuration points at and adds the results to the report
+func (r *Report) RunProbes(blobDir string) {
+	r.Probes = append(r.Probes,
+		runProbe("postgres", db.Ping),
+		runProbe("redis", redis.Ping),
+		runProbe("blob store", func() error { return probeBlobStore(blobDir) }),
+	)
+
+	if host := utils.GetEnvOrDefault("SMTP_HOST", ""); host != "" {
+		addr := net.JoinHostPort(host, utils.GetEnvOrDefault("SMTP_PORT", "587"))
+		r.Probes = append(r.Probes, runProbe("smtp", func() error { return probeTCP(addr) }))
+	} else {
+		r.Probes = append(r.Probes, Probe{Name: "smtp", OK: true, Skipped: true})
+	}
+}
+
+// runProbe runs test, giving up after ConfigProbeTimeout so an unreachable host does not hang the check
+func runProbe(name string, test func() error) Probe {
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- test() }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-time.After(constants.ConfigProbeTimeout):
+		err = fmt.Errorf("no answer after %s", constants.ConfigProbeTimeout)
+	}
+
+	probe := Probe{Name: name, OK: err == nil, Duration: time.Since(start).Round(time.Millisecond).String()}
+	if err != nil {
+		probe.Error = err.Error()
+	}
+	return probe
+}
+
+// probeBlobStore writes then deletes a blob, checking the directory exists and is writable
+func probeBlobStore(dir string) error {
+	store, err := blob.NewLocalStore(dir)
+	if err != nil {
+		return err
+	}
+	key := fmt.Sprintf("config-check/%d", time.Now().UnixNano())
+	if _, err := store.Put(key, strings.NewReader("ok")); err != nil {
+		return err
+	}
+	return store.Delete(key)
+}
+
+func probeTCP(addr string) error {
+	conn, err := net.DialTimeout("tcp", addr, constants.ConfigProbeTimeout)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
@@ -0,0 +1,13 @@
+package constants
+
+import "time"
+
+// ReactivationTTL is how long the reactivation link of a deactivated account stays valid
+const ReactivationTTL = 24 * time.Hour
+
+// Account state related error messages
+const (
+	ErrAccountDeactivated       = "account is deactivated, request a reactivation link with POST /users/reactivate"
+	ErrInvalidAccountTransition = "invalid account state change"
+	ErrInvalidReactivationToken = "invalid or expired reactivation link"
+)
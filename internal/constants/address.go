@@ -0,0 +1,6 @@
+package constants
+
+// Address related error messages
+const (
+	ErrInvalidAddress = "invalid address"
+)
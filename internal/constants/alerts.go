@@ -0,0 +1,22 @@
+package constants
+
+import "time"
+
+// Error budget alerting defaults
+const (
+	DefaultAlertThreshold   = 0.05
+	DefaultAlertMinRequests = 20
+	DefaultAlertWindow      = 5 * time.Minute
+	DefaultAlertSuppression = 30 * time.Minute
+	// SettingAlertSuppressions is the instance setting holding the per route suppression windows, a JSON object
+	// mapping routes ("GET /contacts") to durations ("2h")
+	SettingAlertSuppressions = "alert_suppressions"
+	// AlertSettingsRefreshInterval is how often replicas pick up suppressions saved by another one
+	AlertSettingsRefreshInterval = time.Minute
+)
+
+// Alerting errors
+const (
+	ErrUnknownRoute       = "unknown route"
+	ErrInvalidSuppression = "invalid suppression, expected a duration such as 30m or 2h"
+)
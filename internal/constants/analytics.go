@@ -0,0 +1,11 @@
+package constants
+
+import "time"
+
+// Analytics settings
+const (
+	// SettingAnalyticsEnabled is the instance setting holding the analytics opt-out, "true" or "false"
+	SettingAnalyticsEnabled = "analytics_enabled"
+	// AnalyticsSettingsRefreshInterval is how often replicas pick up an opt-out saved by another one
+	AnalyticsSettingsRefreshInterval = time.Minute
+)
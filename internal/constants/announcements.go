@@ -0,0 +1,28 @@
+package constants
+
+import "time"
+
+// Announcement levels, from the least to the most pressing
+const (
+	AnnouncementLevelInfo        = "info"
+	AnnouncementLevelWarning     = "warning"
+	AnnouncementLevelMaintenance = "maintenance"
+)
+
+// AnnouncementLevels lists the accepted announcement levels
+var AnnouncementLevels = []string{AnnouncementLevelInfo, AnnouncementLevelWarning, AnnouncementLevelMaintenance}
+
+const (
+	// HeaderAnnouncement tells users about the newest announcement they did not dismiss, "<id>; level=<level>"
+	HeaderAnnouncement = "X-Announcement"
+	// AnnouncementCacheTTL is how long a replica keeps the active announcements in memory, announcements posted
+	// through another replica show up after at most this long
+	AnnouncementCacheTTL = 30 * time.Second
+)
+
+// Announcement related error messages
+const (
+	ErrAnnouncementNotFound      = "announcement not found"
+	ErrInvalidAnnouncementLevel  = "invalid announcement level, expected info, warning or maintenance"
+	ErrInvalidAnnouncementWindow = "ends_at must be after starts_at"
+)
@@ -0,0 +1,35 @@
+package constants
+
+import "time"
+
+// MaxAPIKeysPerUser is the number of API keys a user can create
+const MaxAPIKeysPerUser = 10
+
+// Headers of a signed request, see auth.SignRequest for the signed content
+const (
+	HeaderSignatureTimestamp = "X-Signature-Timestamp"
+	HeaderSignatureNonce     = "X-Signature-Nonce"
+	HeaderSignature          = "X-Signature"
+)
+
+// SignedRequestTolerance is how far the timestamp of a signed request may be from the server clock,
+// nonces are remembered twice as long so a request can never be replayed while its timestamp is accepted
+const SignedRequestTolerance = 5 * time.Minute
+
+// Length bounds of the nonce of a signed request
+const (
+	MinSignatureNonceLength = 16
+	MaxSignatureNonceLength = 64
+)
+
+// API key related error messages
+const (
+	ErrAPIKeyNotFound     = "API key not found"
+	ErrAPIKeyLimitReached = "API key limit reached, delete an unused key first"
+	ErrInvalidAPIKey      = "Invalid API key"
+	ErrSignatureRequired  = "Signed request required for writes with an API key"
+	ErrInvalidSignature   = "Invalid request signature"
+	ErrSignatureExpired   = "Request signature timestamp outside the allowed window"
+	ErrInvalidNonce       = "Request nonce must be 16 to 64 characters"
+	ErrNonceReused        = "Request nonce already used"
+)
@@ -0,0 +1,20 @@
+package constants
+
+// Account archive format, Version is raised whenever a section or field changes meaning
+const (
+	ArchiveFormat  = "contact-app-account"
+	ArchiveVersion = 1
+	// MaxArchiveBytes bounds the size of an archive accepted by the import
+	MaxArchiveBytes = 20 << 20
+	// MaxArchiveProblems bounds the validation problems reported for a rejected archive
+	MaxArchiveProblems = 50
+)
+
+// ArchiveSections are the top level members of an archive understood by this version, others are skipped on import
+var ArchiveSections = []string{"format", "version", "exported_at", "contacts", "groups", "tags", "preferences"}
+
+// Account archive related error messages
+const (
+	ErrInvalidArchive  = "invalid account archive"
+	ErrArchiveTooLarge = "account archive exceeds the maximum allowed size"
+)
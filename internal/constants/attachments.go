@@ -0,0 +1,27 @@
+package constants
+
+// Attachment limits, overridable through the environment
+const (
+	DefaultMaxAttachmentBytes    = 10 << 20
+	DefaultUserStorageQuotaBytes = 100 << 20
+	AttachmentURLTTLMinutes      = 15
+)
+
+// AllowedAttachmentTypes lists the sniffed content types accepted for attachments
+var AllowedAttachmentTypes = map[string]bool{
+	"application/pdf": true,
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"text/plain":      true,
+}
+
+// Attachment related error messages
+const (
+	ErrAttachmentNotFound     = "attachment not found"
+	ErrAttachmentTooLarge     = "attachment exceeds the maximum allowed size"
+	ErrAttachmentTypeNotAllow = "attachment type is not allowed"
+	ErrStorageQuotaExceeded   = "storage quota exceeded"
+	ErrInvalidSignedURL       = "invalid or expired download link"
+)
@@ -0,0 +1,53 @@
+package constants
+
+// Audit log actions
+const (
+	AuditActionUserRegistered     = "user.registered"
+	AuditActionLogin              = "user.login"
+	AuditActionLoginFailed        = "user.login_failed"
+	AuditActionPasswordChanged    = "user.password_changed"
+	AuditActionUserMerged         = "user.merged"
+	AuditActionEmailChangeStart   = "user.email_change_requested"
+	AuditActionEmailChangeCancel  = "user.email_change_cancelled"
+	AuditActionEmailChanged       = "user.email_changed"
+	AuditActionUsernameChanged    = "user.username_changed"
+	AuditActionAccountDeactivated = "user.deactivated"
+	AuditActionAccountReactivated = "user.reactivated"
+	AuditActionAdminGranted       = "user.admin_granted"
+	AuditActionAdminRevoked       = "user.admin_revoked"
+	AuditActionContactCreated     = "contact.created"
+	AuditActionContactUpdated     = "contact.updated"
+	AuditActionContactDeleted     = "contact.deleted"
+	AuditActionContactRestored    = "contact.restored"
+	AuditActionContactPurged      = "contact.purged"
+	AuditActionStageChanged       = "contact.stage_changed"
+	AuditActionContactSubmitted   = "contact.share_submitted"
+	AuditActionContactShared      = "contact.share_approved"
+	AuditActionContactRejected    = "contact.share_rejected"
+	AuditActionContactUnshared    = "contact.unshared"
+	AuditActionAttachmentAdded    = "attachment.uploaded"
+	AuditActionAttachmentDeleted  = "attachment.deleted"
+	AuditActionSnapshotCreated    = "snapshot.created"
+	AuditActionSnapshotRestored   = "snapshot.restored"
+	AuditActionEmbedTokenCreated  = "embed_token.created"
+	AuditActionEmbedTokenRevoked  = "embed_token.revoked"
+)
+
+// Audit log entity types
+const (
+	AuditEntityUser       = "user"
+	AuditEntityContact    = "contact"
+	AuditEntitySnapshot   = "snapshot"
+	AuditEntityEmbedToken = "embed_token"
+)
+
+// Export formats
+const (
+	ExportFormatCSV = "csv"
+)
+
+// Audit related error messages
+const (
+	ErrUnsupportedExportFormat = "unsupported export format"
+	ErrInvalidAuditRange       = "invalid date range, expected RFC 3339 timestamps or YYYY-MM-DD dates with from before to"
+)
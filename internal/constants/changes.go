@@ -0,0 +1,34 @@
+package constants
+
+import "time"
+
+// Long polling of the contact changes feed
+const (
+	DefaultChangesWait = 30 * time.Second
+	MaxChangesWait     = 60 * time.Second
+	// MaxChangesPerResponse bounds a response, has_more tells the client to ask again from the returned cursor
+	MaxChangesPerResponse = 100
+)
+
+// ContactCountTTL bounds how long the number of contacts kept in Redis for the count badge is trusted before being
+// counted again from the database, correcting any drift
+const ContactCountTTL = time.Hour
+
+// ContactChangeActions are the audit log actions reported by the contact changes feed
+var ContactChangeActions = []string{
+	AuditActionContactCreated,
+	AuditActionContactUpdated,
+	AuditActionContactDeleted,
+	AuditActionContactRestored,
+	AuditActionContactPurged,
+	AuditActionStageChanged,
+	AuditActionSnapshotRestored,
+	AuditActionUserMerged,
+}
+
+// Changes feed related error messages
+const (
+	ErrInvalidChangesCursor = "invalid since cursor, expected the cursor of a previous response"
+	ErrInvalidChangesWait   = "invalid wait, expected a duration such as 30s of at most 60s"
+	ErrInvalidCountCursor   = "invalid since cursor, expected the cursor of a previous count"
+)
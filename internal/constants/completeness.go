@@ -0,0 +1,20 @@
+package constants
+
+// CompletenessFields are the optional fields of a contact its completeness is scored on, in the order they are
+// reported. The names are required so they do not count
+var CompletenessFields = []string{"phone_number", "email", "address", "company", "job_title", "timezone"}
+
+// IsCompletenessField reports whether a field is one of CompletenessFields
+func IsCompletenessField(field string) bool {
+	for _, candidate := range CompletenessFields {
+		if field == candidate {
+			return true
+		}
+	}
+	return false
+}
+
+// Completeness related error messages
+const (
+	ErrInvalidCompletenessField = "missing must be a comma separated list of phone_number, email, address, company, job_title, timezone"
+)
@@ -0,0 +1,6 @@
+package constants
+
+import "time"
+
+// ConfigProbeTimeout bounds each connectivity test of the config check
+const ConfigProbeTimeout = 5 * time.Second
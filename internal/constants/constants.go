@@ -2,18 +2,28 @@ package constants
 
 // User related error messages
 const (
-	ErrUserExists        = "user already exists"
-	ErrUsernameExists    = "username already exists"
-	ErrEmailExists       = "email already exists"
+	ErrUserExists     = "user already exists"
+	ErrUsernameExists = "username already exists"
+	ErrEmailExists    = "email already exists"
+	ErrUserNotFound   = "user not found"
+	ErrMergeSameUser  = "cannot merge a user into itself"
+	// ErrProfileUnchanged is returned by a profile update changing neither the username nor the email
+	ErrProfileUnchanged = "nothing to update, provide a new user_name or email"
 )
 
 // Contact related error messages
 const (
 	ErrContactNotFound = "contact not found"
 	ErrNotAuthorized   = "not authorized to access this contact"
+	// ErrContactClientIDExists is returned by creates reusing the client_id of another contact of the user
+	ErrContactClientIDExists = "contact with this client_id already exists"
+	ErrInvalidContactRef     = "invalid contact ID, expected a contact ID or a client_id"
 )
 
 // Authentication related constants
 const (
-	AuthUserKey = "userID"
+	AuthUserKey    = "userID"
+	AuthIsAdminKey = "isAdmin"
+	// AuthTokenKey holds the claims of the JWT of the request, unset for API key requests
+	AuthTokenKey = "token"
 )
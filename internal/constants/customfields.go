@@ -0,0 +1,28 @@
+package constants
+
+import "time"
+
+// Contact template
+const (
+	// SettingContactTemplateVersion is the instance setting counting the changes of the contact template, contacts
+	// record the version they were migrated to
+	SettingContactTemplateVersion = "contact_template_version"
+	// SettingContactTemplateTags is the instance setting holding the tags attached to new contacts, a JSON array
+	SettingContactTemplateTags = "contact_template_tags"
+	// MaxContactTemplateTags is the number of tags the template attaches to new contacts at most
+	MaxContactTemplateTags = 20
+	// ContactTemplateMigrationInterval is how often contacts following a previous version of the template are migrated
+	ContactTemplateMigrationInterval = time.Minute
+	// ContactTemplateMigrationBatchSize is the number of contacts migrated per batch
+	ContactTemplateMigrationBatchSize = 500
+)
+
+// Custom field related error messages
+const (
+	ErrInvalidCustomField     = "invalid custom field"
+	ErrUnknownCustomField     = "unknown custom field"
+	ErrMissingCustomField     = "missing required custom field"
+	ErrCustomFieldNotFound    = "custom field not found"
+	ErrInvalidFieldDefinition = "invalid custom field definition"
+	ErrTooManyTemplateTags    = "too many default tags"
+)
@@ -0,0 +1,17 @@
+package constants
+
+import "time"
+
+// Demo mode defaults, the demo credentials are public by design
+const (
+	DefaultDemoUsername      = "demo"
+	DefaultDemoEmail         = "demo@example.com"
+	DefaultDemoPassword      = "demo1234"
+	DefaultDemoResetInterval = time.Hour
+	MinDemoResetInterval     = time.Minute
+)
+
+// Demo mode related error messages
+const (
+	ErrDisabledInDemo = "this action is disabled on the demo instance"
+)
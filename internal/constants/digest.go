@@ -0,0 +1,10 @@
+package constants
+
+import "time"
+
+// Weekly digest schedule, due digests are looked for every DigestCheckInterval and sent once per DigestPeriod
+const (
+	DigestPeriod         = 7 * 24 * time.Hour
+	DigestCheckInterval  = time.Hour
+	DigestMaxNewContacts = 10
+)
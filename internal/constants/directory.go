@@ -0,0 +1,23 @@
+package constants
+
+// Team directory
+const (
+	// SettingTeamDirectory is the instance setting holding the team directory settings, a JSON object
+	SettingTeamDirectory = "team_directory"
+	// DirectorySourceShared lists the contacts of the shared address book, DirectorySourceMembers the cards of the
+	// users, the contact each one picked to stand for themselves
+	DirectorySourceShared  = "shared"
+	DirectorySourceMembers = "members"
+)
+
+// DirectoryFields are the contact fields the team directory can show besides the name, none is shown unless the
+// settings list it
+var DirectoryFields = []string{"email", "phone_number", "company", "job_title", "city", "region", "country_code",
+	"social_profiles"}
+
+// Team directory related error messages
+const (
+	ErrDirectoryNotFound     = "directory not found"
+	ErrInvalidDirectorySlug  = "invalid directory slug, expected 2 to 50 lowercase letters, digits and dashes"
+	ErrInvalidDirectoryField = "invalid directory field"
+)
@@ -0,0 +1,9 @@
+package constants
+
+// MaxDuplicatePairs is the most pairs of duplicate contacts listed at once, the most alike first
+const MaxDuplicatePairs = 100
+
+// Duplicate detection related error messages
+const (
+	ErrInvalidDuplicateSensitivity = "duplicate sensitivity must be low, medium or high"
+)
@@ -0,0 +1,20 @@
+package constants
+
+import "time"
+
+// EmailChangeTTL is how long the confirmation links of an email change stay valid
+const EmailChangeTTL = 24 * time.Hour
+
+// DefaultPublicURL is where the API is reached by users, PUBLIC_URL overrides it. Links sent by email start with it
+const DefaultPublicURL = "http://localhost"
+
+// Email change related error messages
+const (
+	ErrEmailNotConfigured      = "email is not configured on this server"
+	ErrEmailUnchanged          = "new email is the current email"
+	ErrEmailChangeNotFound     = "no pending email change"
+	ErrInvalidEmailChangeToken = "invalid or expired email confirmation link"
+)
+
+// DefaultInstanceName names the deployment in emails, exports and public pages, INSTANCE_NAME overrides it
+const DefaultInstanceName = "Contact App"
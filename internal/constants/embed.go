@@ -0,0 +1,19 @@
+package constants
+
+import "time"
+
+const (
+	// MaxEmbedTokensPerUser is the number of embed tokens a user can hold, revoked and expired ones excluded
+	MaxEmbedTokensPerUser = 20
+	// MaxEmbedTokenLifetime bounds the expiry of embed tokens, they never last longer
+	MaxEmbedTokenLifetime = 365 * 24 * time.Hour
+)
+
+// Embed token related error messages
+const (
+	ErrEmbedTokenNotFound     = "embed token not found"
+	ErrEmbedTokenLimitReached = "embed token limit reached, revoke an unused token first"
+	ErrInvalidEmbedToken      = "invalid, expired or revoked embed token"
+	ErrInvalidEmbedScope      = "an embed token is limited to exactly one of group_id or a saved search (q and/or tag)"
+	ErrInvalidEmbedExpiry     = "expires_at must be in the future and within a year"
+)
@@ -0,0 +1,19 @@
+package constants
+
+// Export encryption, exports are encrypted with the password of HeaderExportPassword which is never stored
+const (
+	HeaderExportPassword    = "X-Export-Password"
+	MinExportPasswordLength = 8
+	// EncryptedExportExtension is appended to the file name of encrypted exports, gpg --decrypt opens them
+	EncryptedExportExtension = ".gpg"
+	// ExportPasswordS2KCount is the number of bytes hashed when deriving the key from the password, the OpenPGP
+	// maximum, slowing down password guessing
+	ExportPasswordS2KCount = 65011712
+)
+
+// Export encryption related error messages
+const (
+	ErrExportPasswordTooShort = "export password must be at least 8 characters"
+	ErrWrongExportPassword    = "wrong export password"
+	ErrInvalidEncryptedExport = "invalid encrypted export"
+)
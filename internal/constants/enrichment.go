@@ -0,0 +1,17 @@
+package constants
+
+// Enrichment suggestion statuses
+const (
+	EnrichmentStatusPending  = "pending"
+	EnrichmentStatusAccepted = "accepted"
+	EnrichmentStatusRejected = "rejected"
+)
+
+// Enrichment related error messages
+const (
+	ErrEnrichmentNotConfigured = "contact enrichment is not available: no enrichment provider configured"
+	ErrEnrichmentNotFound      = "enrichment not found"
+	ErrEnrichmentResolved      = "enrichment was already accepted or rejected"
+	ErrEnrichmentNoMatch       = "no enrichment data found for this contact"
+	ErrEnrichmentNoIdentifier  = "contact has no email or phone number to enrich from"
+)
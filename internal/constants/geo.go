@@ -0,0 +1,14 @@
+package constants
+
+// Map view limits, above the cluster threshold contacts are grouped into grid cells server-side
+const (
+	DefaultGeoJSONClusterThreshold = 500
+	MaxGeoJSONContacts             = 10000
+	DefaultGeoJSONZoom             = 3
+	MaxGeoJSONZoom                 = 20
+)
+
+// Geolocation related error messages
+const (
+	ErrInvalidLocation = "invalid location, latitude and longitude must be given together"
+)
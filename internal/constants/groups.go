@@ -0,0 +1,11 @@
+package constants
+
+// Group and tag related error messages
+const (
+	ErrGroupNotFound    = "group not found"
+	ErrGroupExists      = "group with this name already exists"
+	ErrTagNotFound      = "tag not found"
+	ErrTagExists        = "tag with this name already exists"
+	ErrInvalidTagName   = "tag name must be 1 to 50 characters"
+	ErrContactsNotOwned = "one or more contacts not found"
+)
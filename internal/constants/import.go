@@ -0,0 +1,31 @@
+package constants
+
+// Business card import limits
+const (
+	MaxCardImageBytes = 5 << 20
+)
+
+// Import related error messages
+const (
+	ErrOCRNotConfigured     = "business card import is not available: no OCR provider configured"
+	ErrCardImageInvalidType = "card image must be a JPEG or PNG image"
+	ErrCardImageTooLarge    = "card image exceeds the maximum allowed size"
+	ErrCardNotRecognized    = "no text could be recognized on the card image"
+)
+
+// Contact file import and export
+const (
+	ContactFileFormatCSV   = "csv"
+	ContactFileFormatVCard = "vcf"
+	MaxContactFileBytes    = 10 << 20
+	MaxContactFileRows     = 10000
+)
+
+// Contact file import related error messages
+const (
+	ErrUnsupportedContactFileFormat = "unsupported contact file format, expected csv or vcf"
+	ErrContactFileTooLarge          = "contact file exceeds the maximum allowed size"
+	ErrContactFileTooManyRows       = "contact file exceeds the maximum number of contacts"
+	ErrInvalidContactFile           = "invalid contact file"
+	ErrContactFileMissingName       = "CSV header must name the first_name and last_name (or name) columns"
+)
@@ -0,0 +1,25 @@
+package constants
+
+import "time"
+
+// Kinds of the interactions logged with a contact
+const (
+	InteractionCall    = "call"
+	InteractionMeeting = "meeting"
+	InteractionMessage = "message"
+	InteractionEmail   = "email"
+	InteractionOther   = "other"
+)
+
+// InteractionKinds are the accepted interaction kinds
+var InteractionKinds = []string{InteractionCall, InteractionMeeting, InteractionMessage, InteractionEmail, InteractionOther}
+
+// ContactViewResolution is how often viewing a contact moves its last interaction, views closer than this are
+// not written to keep reads cheap
+const ContactViewResolution = time.Hour
+
+// Interaction related error messages
+const (
+	ErrInvalidInteractionKind = "invalid interaction kind, expected one of call, meeting, message, email, other"
+	ErrInteractionInFuture    = "occurred_at cannot be in the future"
+)
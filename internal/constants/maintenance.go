@@ -0,0 +1,37 @@
+package constants
+
+import "time"
+
+// Admin maintenance operations rebuilding the data derived from the contacts, run from the command line or as
+// background jobs of the admin API
+const (
+	// MaintenanceReindexSearch recomputes the search vector of every contact
+	MaintenanceReindexSearch = "reindex-search"
+	// MaintenanceNormalizePhones writes the normalized phone number of every contact again, in the current regions
+	MaintenanceNormalizePhones = "normalize-phones"
+	// MaintenanceRecount recomputes the per-user totals from the rows they count
+	MaintenanceRecount = "recount"
+	// MaintenanceWarmCache fills the cached first contact page and count of the most active users
+	MaintenanceWarmCache = "warm-cache"
+
+	// MaintenanceBatchSize is the number of contacts or users rewritten per transaction
+	MaintenanceBatchSize = 1000
+	// DefaultWarmCacheUsers is the number of users whose caches are warmed when the admin gives none
+	DefaultWarmCacheUsers = 100
+	// MaxWarmCacheUsers is the most users whose caches can be warmed at once
+	MaxWarmCacheUsers = 10000
+	// WarmCacheActivityWindow is the period the activity of the users is counted over to pick the most active ones
+	WarmCacheActivityWindow = 7 * 24 * time.Hour
+	// MaintenanceJobRetention is how long finished maintenance jobs stay listed
+	MaintenanceJobRetention = 24 * time.Hour
+
+	MaintenanceJobRunning   = "running"
+	MaintenanceJobSucceeded = "succeeded"
+	MaintenanceJobFailed    = "failed"
+
+	ErrUnknownMaintenanceOperation = "unknown maintenance operation, expected one of reindex-search, normalize-phones, recount, warm-cache"
+	ErrInvalidWarmCacheUsers       = "top must be between 1 and 10000"
+	ErrMaintenanceJobRunning       = "this maintenance operation is already running"
+	ErrMaintenanceJobNotFound      = "maintenance job not found"
+	ErrCacheUnavailable            = "the cache is not available"
+)
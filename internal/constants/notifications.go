@@ -0,0 +1,22 @@
+package constants
+
+// Events of the notification preferences matrix
+const (
+	// NotificationSharedContactUpdated is a shared contact changed by its owner, told to the other users
+	NotificationSharedContactUpdated = "shared_contact_updated"
+	// NotificationSharedContactReviewed is the review of a submission to the shared address book, told to the submitter
+	NotificationSharedContactReviewed = "shared_contact_reviewed"
+)
+
+// Channels of the notification preferences matrix
+const (
+	NotificationChannelEmail = "email"
+	// NotificationChannelInApp marks what changed in the responses of the API
+	NotificationChannelInApp = "in_app"
+)
+
+// Notification related error messages
+const (
+	ErrUnknownNotificationEvent   = "unknown notification event"
+	ErrUnknownNotificationChannel = "unknown notification channel"
+)
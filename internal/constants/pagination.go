@@ -5,3 +5,27 @@ const (
 	DefaultPageSize = 10
 	MaxPageSize     = 100
 ) 
+
+// Headers carrying the page information of responses whose format has no room for it (CSV)
+const (
+	HeaderTotalCount = "X-Total-Count"
+	HeaderTotalPages = "X-Total-Pages"
+	HeaderNextCursor = "X-Next-Cursor"
+)
+
+// Sort orders of the contact listings, the default is the creation order
+const (
+	SortByName            = "name"
+	SortByCreatedAt       = "created_at"
+	SortByUpdatedAt       = "updated_at"
+	SortByLastInteraction = "last_interacted_at"
+	SortAsc               = "asc"
+	SortDesc              = "desc"
+)
+
+// Errors of the contact listings
+const (
+	ErrInvalidSort        = "sort_by must be one of name, created_at, updated_at, last_interacted_at and sort_dir one of asc, desc"
+	ErrInvalidCursor      = "invalid cursor"
+	ErrInvalidInactiveFor = "invalid inactive_for, expected a number of days such as 90d"
+)
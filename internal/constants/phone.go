@@ -0,0 +1,7 @@
+package constants
+
+// Phone related error messages
+const (
+	ErrInvalidRegion      = "invalid region"
+	ErrInvalidPhoneNumber = "invalid phone number"
+)
@@ -0,0 +1,28 @@
+package constants
+
+// Picklist fields that hold admin-managed allowed values
+const (
+	PicklistFieldSource = "source"
+	PicklistFieldStage  = "stage"
+)
+
+// PicklistFields lists every contact field backed by a picklist
+var PicklistFields = []string{PicklistFieldSource, PicklistFieldStage}
+
+// Picklist related error messages
+const (
+	ErrUnknownPicklistField = "unknown picklist field"
+	ErrInvalidPicklistValue = "value is not allowed for this picklist"
+	ErrPicklistValueExists  = "picklist value already exists"
+	ErrPicklistValueMissing = "picklist value not found"
+)
+
+// IsPicklistField reports whether field is one of the known picklist fields
+func IsPicklistField(field string) bool {
+	for _, f := range PicklistFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
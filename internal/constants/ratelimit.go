@@ -0,0 +1,31 @@
+package constants
+
+import "time"
+
+// DefaultRateLimitPerMinute is the number of requests a user (or an IP address for public endpoints) may send
+// per RateLimitWindow, RATE_LIMIT_PER_MINUTE overrides it and 0 disables rate limiting
+const DefaultRateLimitPerMinute = 600
+
+// Per route limits of the routes open to brute force, requests per RateLimitWindow and client IP
+const (
+	LoginRateLimitPerMinute  = 10
+	SignupRateLimitPerMinute = 5
+	// ReactivationRateLimitPerMinute limits the reactivation emails a client can trigger
+	ReactivationRateLimitPerMinute = 5
+	// EmbedRateLimitPerMinute limits the guesses of embed tokens, embedding pages share the IP of their viewers' proxy
+	EmbedRateLimitPerMinute = 120
+)
+
+// RateLimitWindow is the period rate limits are counted over
+const RateLimitWindow = time.Minute
+
+// Headers describing the rate limit of the caller, returned on every response
+const (
+	HeaderRateLimitLimit     = "X-RateLimit-Limit"
+	HeaderRateLimitRemaining = "X-RateLimit-Remaining"
+	HeaderRateLimitReset     = "X-RateLimit-Reset"
+	HeaderRetryAfter         = "Retry-After"
+)
+
+// ErrRateLimited is the error of a request rejected by the rate limit
+const ErrRateLimited = "rate limit exceeded, retry later"
@@ -0,0 +1,19 @@
+package constants
+
+import "time"
+
+// Full-text search
+const (
+	// DefaultSearchLanguage is the text search configuration of the deployment when SEARCH_LANGUAGE is not set,
+	// simple only lowercases words so it suits every language
+	DefaultSearchLanguage = "simple"
+	// SettingSearchLanguage is the instance setting holding the deployment search language, read by the database
+	// when indexing the contacts of users without their own
+	SettingSearchLanguage = "search_language"
+	// SearchReindexInterval is how often contacts indexed in a language that is no longer their owner's are reindexed
+	SearchReindexInterval = time.Minute
+	// SearchReindexBatchSize is the number of contacts reindexed per transaction
+	SearchReindexBatchSize = 1000
+
+	ErrInvalidSearchLanguage = "unknown search language"
+)
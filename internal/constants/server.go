@@ -0,0 +1,10 @@
+package constants
+
+import "os"
+
+// UnixSocketMode is the permission of the Unix domain sockets the API listens on, only the user and group of the
+// process can connect
+const UnixSocketMode os.FileMode = 0660
+
+// ErrClientNotAllowed is the error of a request from a network the listener does not accept
+const ErrClientNotAllowed = "client not allowed on this listener"
@@ -0,0 +1,15 @@
+package constants
+
+import "time"
+
+// Session token lifetimes, ACCESS_TOKEN_TTL and REFRESH_TOKEN_TTL override them
+const (
+	DefaultAccessTokenTTL  = 15 * time.Minute
+	DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// Session related error messages
+const (
+	ErrInvalidRefreshToken = "Invalid or expired refresh token"
+	ErrInvalidPassword     = "current password is incorrect"
+)
@@ -0,0 +1,15 @@
+package constants
+
+// Statuses of a contact submitted to the shared address book
+const (
+	SharedContactPending  = "pending"
+	SharedContactShared   = "shared"
+	SharedContactRejected = "rejected"
+)
+
+// Shared address book related error messages
+const (
+	ErrSharedContactNotFound = "contact is not in the shared address book"
+	ErrNotSharedBookReviewer = "only admins and shared address book editors review submissions"
+	ErrSubmissionNotPending  = "submission is not pending review"
+)
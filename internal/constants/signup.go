@@ -0,0 +1,8 @@
+package constants
+
+// Signup policy related error messages
+const (
+	ErrEmailDomainNotAllowed = "registration is restricted to approved email domains"
+	ErrEmailDomainBlocked    = "registration from this email domain is blocked"
+	ErrDisposableEmail       = "disposable email addresses cannot register"
+)
@@ -0,0 +1,10 @@
+package constants
+
+// MaxSnapshotsPerUser is the number of snapshots a user can keep, older ones must be deleted to take new ones
+const MaxSnapshotsPerUser = 20
+
+// Snapshot related error messages
+const (
+	ErrSnapshotNotFound     = "snapshot not found"
+	ErrSnapshotLimitReached = "snapshot limit reached, delete an older snapshot first"
+)
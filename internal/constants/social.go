@@ -0,0 +1,11 @@
+package constants
+
+// Social profile related error messages
+const (
+	ErrUnsupportedSocialNetwork = "unsupported social network"
+	ErrInvalidSocialProfile     = "invalid social profile"
+	ErrSocialProfileNotFound    = "social profile not found"
+)
+
+// SocialCSVColumnPrefix prefixes the network of the CSV columns holding social profile URLs
+const SocialCSVColumnPrefix = "social_"
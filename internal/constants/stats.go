@@ -0,0 +1,13 @@
+package constants
+
+import "time"
+
+// Admin stats
+const (
+	// AdminStatsCacheTTL is how long the instance stats are served from memory before being computed again
+	AdminStatsCacheTTL = time.Minute
+	// ActiveUserWindow is the period a user counts as active in after logging in or changing data
+	ActiveUserWindow = 24 * time.Hour
+	// UsageWindow is the period imports and exports are counted over
+	UsageWindow = 24 * time.Hour
+)
@@ -0,0 +1,31 @@
+package constants
+
+import "time"
+
+// Outcomes of the mutations of a sync batch
+const (
+	SyncMutationApplied = "applied"
+	SyncMutationFailed  = "failed"
+	// SyncMutationSkipped mutations were valid but not applied, another mutation of the batch failed
+	SyncMutationSkipped = "skipped"
+)
+
+const (
+	// MaxSyncBatchMutations bounds the mutations of a sync batch, clients flush longer queues in several batches
+	MaxSyncBatchMutations = 100
+	// SyncBatchRetention is how long the outcome of a batch is kept for clients replaying it
+	SyncBatchRetention = 7 * 24 * time.Hour
+	// SyncBatchCleanupInterval is how often the outcomes of batches older than SyncBatchRetention are deleted
+	SyncBatchCleanupInterval = time.Hour
+)
+
+// Sync related error messages
+const (
+	ErrInvalidBaseVersion   = "invalid base_version, expected the version of the contact the changes were made on"
+	ErrInvalidMutation      = "invalid mutation"
+	ErrUnknownTempID        = "unknown temp_id, expected the temp_id of a create earlier in the batch"
+	ErrDuplicateTempID      = "temp_id already used by an earlier create of the batch"
+	ErrSyncBatchConflict    = "contacts changed while the batch was applied, send it again"
+	ErrSyncContactConflict  = "contact changed while the changes were merged, send them again"
+	ErrDuplicateContactName = "contact with this name already exists"
+)
@@ -0,0 +1,22 @@
+package constants
+
+import "time"
+
+// Bounds of the work done for a request, REQUEST_TIMEOUT, DB_STATEMENT_TIMEOUT and REDIS_TIMEOUT override them. The
+// context of a request is canceled after the request timeout, aborting its in-flight queries and Redis calls.
+// Postgres cancels the statements running longer than the statement timeout even when no request bounds them
+// (jobs, admin commands)
+const (
+	DefaultRequestTimeout     = 30 * time.Second
+	DefaultDBStatementTimeout = time.Minute
+	DefaultRedisTimeout       = 3 * time.Second
+)
+
+// LongPollRequestTimeout bounds the requests waiting for contact changes, which wait up to MaxChangesWait
+const LongPollRequestTimeout = MaxChangesWait + 10*time.Second
+
+// StatusClientClosedRequest answers requests whose client went away before the response, it is never received
+const StatusClientClosedRequest = 499
+
+// ErrRequestTimeout is the error of a request that did not complete within its timeout
+const ErrRequestTimeout = "request timed out"
@@ -0,0 +1,12 @@
+package constants
+
+// Working hours used for the "best time to call" hint, in the contact's local time
+const (
+	WorkingHoursStart = 9
+	WorkingHoursEnd   = 18
+)
+
+// Timezone related error messages
+const (
+	ErrInvalidTimezone = "invalid timezone, expected an IANA name such as Europe/London"
+)
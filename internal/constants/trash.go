@@ -0,0 +1,15 @@
+package constants
+
+import "time"
+
+// Deleted contacts stay in the trash for TrashRetention, until restored or deleted permanently
+const (
+	TrashRetention     = 30 * 24 * time.Hour
+	TrashPurgeInterval = time.Hour
+)
+
+// Trash related error messages
+const (
+	ErrContactNotInTrash = "contact not found in trash"
+	ErrContactNameTaken  = "an active contact already has this name, rename it before restoring"
+)
@@ -0,0 +1,20 @@
+package constants
+
+import "time"
+
+// API usage statistics
+const (
+	// APIUsageRollupInterval is how often the usage counted in Redis is copied to Postgres, the admin usage lags by
+	// at most this much
+	APIUsageRollupInterval = 5 * time.Minute
+	// APIUsageCounterTTL keeps the Redis counters of a day long enough for the last rollup of the day
+	APIUsageCounterTTL = 48 * time.Hour
+	// DefaultAPIUsageDays and MaxAPIUsageDays bound the period of a usage report, in days ending today
+	DefaultAPIUsageDays = 30
+	MaxAPIUsageDays     = 90
+	// APIUsageTopUsers is the number of users listed in the admin usage
+	APIUsageTopUsers = 20
+)
+
+// ErrInvalidUsageDays is the error of a usage report period out of range
+const ErrInvalidUsageDays = "invalid days, expected a number of days between 1 and 90"
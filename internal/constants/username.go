@@ -0,0 +1,16 @@
+package constants
+
+import "time"
+
+// A user can change its username once per UsernameChangeCooldown, a former username stays reserved to its last owner
+// for UsernameReservation so others cannot take it over and lookups of it are redirected to the account
+const (
+	UsernameChangeCooldown = 30 * 24 * time.Hour
+	UsernameReservation    = 90 * 24 * time.Hour
+)
+
+// Username change related error messages
+const (
+	ErrUsernameUnchanged     = "new username is the current username"
+	ErrUsernameChangeTooSoon = "username can only be changed once every 30 days"
+)
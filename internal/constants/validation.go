@@ -0,0 +1,15 @@
+package constants
+
+// Kinds of the problems found by admin validate-data
+const (
+	DataIssueInvalidEncoding = "invalid_encoding"
+	DataIssueMojibake        = "mojibake"
+	DataIssueMalformedPhone  = "malformed_phone"
+	DataIssueTooLong         = "too_long"
+)
+
+// Phone numbers hold between MinPhoneDigits and MaxPhoneDigits digits, the longest E.164 number has 15
+const (
+	MinPhoneDigits = 3
+	MaxPhoneDigits = 15
+)
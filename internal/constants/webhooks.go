@@ -0,0 +1,53 @@
+package constants
+
+import "time"
+
+// MaxWebhooksPerUser is the number of webhook endpoints a user can register
+const MaxWebhooksPerUser = 10
+
+// WebhookTimeout bounds a single webhook delivery
+const WebhookTimeout = 10 * time.Second
+
+// WebhookResolveTimeout bounds the resolution of the host of a webhook URL when it is registered
+const WebhookResolveTimeout = 3 * time.Second
+
+// Failed deliveries of events are retried with an exponential backoff, WebhookRetryBaseDelay after the first attempt
+// and doubling up to WebhookRetryMaxDelay, until WebhookMaxAttempts attempts were made
+const (
+	WebhookMaxAttempts    = 6
+	WebhookRetryBaseDelay = 30 * time.Second
+	WebhookRetryMaxDelay  = time.Hour
+	// WebhookRetryInterval is how often due retries are looked for, WebhookRetryBatchSize of them at most per run
+	WebhookRetryInterval  = 15 * time.Second
+	WebhookRetryBatchSize = 50
+	// WebhookDeliveryLease is how long an attempt may take before another replica takes the delivery over
+	WebhookDeliveryLease = 2 * time.Minute
+)
+
+// Webhook deliveries are kept for WebhookDeliveryRetention, GET /webhooks/:id/deliveries lists the latest ones
+const (
+	WebhookDeliveryRetention       = 30 * 24 * time.Hour
+	WebhookDeliveryCleanupInterval = time.Hour
+	WebhookDeliveriesListLimit     = 50
+)
+
+// Webhook delivery statuses
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookEventTest is the type of the sample event sent by POST /webhooks/test
+const WebhookEventTest = "webhook.test"
+
+// Webhook related error messages
+const (
+	ErrWebhookNotFound     = "webhook not found"
+	ErrWebhookLimitReached = "webhook limit reached, delete an unused webhook first"
+	ErrInvalidWebhookURL   = "webhook URL must be an absolute http or https URL"
+	ErrWebhookURLNotPublic = "webhook URL must not point to a loopback, private or link-local address"
+	// ErrWebhookUnreachable is the error of every failed delivery, the cause is only logged so deliveries cannot be
+	// used to probe what the server reaches
+	ErrWebhookUnreachable = "endpoint could not be reached"
+)
@@ -0,0 +1,116 @@
+// Package customfield validates the values of the custom fields admins define for every contact of the deployment
+package customfield
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Types of custom fields, values are always stored as strings
+const (
+	Text    = "text"
+	Number  = "number"
+	Date    = "date"
+	Boolean = "boolean"
+	Select  = "select"
+)
+
+// MaxValueLength is the longest value a custom field holds
+const MaxValueLength = 255
+
+var keyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// Definition describes a custom field of the contacts
+type Definition struct {
+	Key      string
+	Label    string
+	Type     string
+	Options  []string
+	Required bool
+	// Default fills the field on new contacts created without it, and on existing ones when the field is added
+	Default string
+}
+
+// Types returns the supported field types
+func Types() []string {
+	return []string{Text, Number, Date, Boolean, Select}
+}
+
+// ValidateDefinition checks a definition: its key, its type, the options of select fields and its default value
+func ValidateDefinition(def Definition) error {
+	if !keyPattern.MatchString(def.Key) {
+		return fmt.Errorf("key must be lowercase letters, digits and underscores, starting with a letter")
+	}
+	switch def.Type {
+	case Text, Number, Date, Boolean:
+		if len(def.Options) > 0 {
+			return fmt.Errorf("only select fields have options")
+		}
+	case Select:
+		if len(def.Options) == 0 {
+			return fmt.Errorf("select fields need options")
+		}
+	default:
+		return fmt.Errorf("unknown type %q, expected one of %s", def.Type, strings.Join(Types(), ", "))
+	}
+	if def.Default != "" {
+		if err := Validate(def, def.Default); err != nil {
+			return fmt.Errorf("default: %w", err)
+		}
+	}
+	return nil
+}
+
+// Validate checks a value against the type of its field, empty values are left to the required check
+func Validate(def Definition, value string) error {
+	if value == "" {
+		return nil
+	}
+	if len(value) > MaxValueLength {
+		return fmt.Errorf("%s is longer than %d characters", def.Key, MaxValueLength)
+	}
+	switch def.Type {
+	case Number:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("%s must be a number", def.Key)
+		}
+	case Date:
+		if _, err := time.Parse(time.DateOnly, value); err != nil {
+			return fmt.Errorf("%s must be a date formatted as YYYY-MM-DD", def.Key)
+		}
+	case Boolean:
+		if value != "true" && value != "false" {
+			return fmt.Errorf("%s must be true or false", def.Key)
+		}
+	case Select:
+		for _, option := range def.Options {
+			if value == option {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s must be one of %s", def.Key, strings.Join(def.Options, ", "))
+	}
+	return nil
+}
+
+// Migrate brings the custom field values of a contact to the current definitions: values of fields that no longer
+// exist or no longer valid for their type are dropped, and missing fields with a default get it
+func Migrate(defs []Definition, values map[string]string) map[string]string {
+	migrated := make(map[string]string, len(defs))
+	for _, def := range defs {
+		value := values[def.Key]
+		if value != "" && Validate(def, value) != nil {
+			value = ""
+		}
+		if value == "" {
+			value = def.Default
+		}
+		if value != "" {
+			migrated[def.Key] = value
+		}
+	}
+	return migrated
+}
@@ -0,0 +1,231 @@
+package dedupe
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// Signals scored on a pair of contacts, in the order they are reported
+const (
+	SignalName  = "name_similarity"
+	SignalPhone = "phone_match"
+	SignalEmail = "email_match"
+)
+
+// Weights of the signals in the score of a pair. They add up to 1 so scores range from 0 to 1
+const (
+	nameWeight  = 0.4
+	phoneWeight = 0.35
+	emailWeight = 0.25
+)
+
+// Sensitivities of the detection, the higher the less alike contacts must be to be flagged
+const (
+	Low                = "low"
+	Medium             = "medium"
+	High               = "high"
+	DefaultSensitivity = Medium
+)
+
+// Thresholds decide which pairs of contacts are duplicates
+type Thresholds struct {
+	// Score is the lowest score of a pair flagged as duplicates
+	Score float64
+	// NameSimilarity is the lowest name similarity counted as a match, names less alike do not add to the score
+	NameSimilarity float64
+}
+
+var sensitivities = map[string]Thresholds{
+	Low:    {Score: 0.75, NameSimilarity: 0.9},
+	Medium: {Score: 0.6, NameSimilarity: 0.8},
+	High:   {Score: 0.4, NameSimilarity: 0.7},
+}
+
+// ThresholdsFor returns the thresholds of a sensitivity, ok is false when it is unknown
+func ThresholdsFor(sensitivity string) (t Thresholds, ok bool) {
+	t, ok = sensitivities[sensitivity]
+	return t, ok
+}
+
+// Contact is what duplicates are detected on. Phone should be normalized (E.164) so numbers written differently
+// match
+type Contact struct {
+	ID    int
+	Name  string
+	Phone string
+	Email string
+}
+
+// Signal is how alike a pair of contacts is on one signal
+type Signal struct {
+	Name string
+	// Score goes from 0 (different) to 1 (same), phones and emails either match or not
+	Score  float64
+	Weight float64
+	// Contribution is what the signal adds to the score of the pair, nothing unless it matched
+	Contribution float64
+	// Threshold is the lowest score counted as a match
+	Threshold float64
+	Matched   bool
+}
+
+// Match is the score of a pair of contacts, the sum of the contributions of its signals
+type Match struct {
+	Score   float64
+	Signals []Signal
+}
+
+// Pair is a pair of contacts flagged as duplicates, A has the lowest ID
+type Pair struct {
+	A, B int
+	Match
+}
+
+// Compare scores how alike two contacts are
+func Compare(a, b Contact, t Thresholds) Match {
+	signals := []Signal{
+		signal(SignalName, round(NameSimilarity(a.Name, b.Name)), nameWeight, t.NameSimilarity),
+		signal(SignalPhone, same(a.Phone, b.Phone), phoneWeight, 1),
+		signal(SignalEmail, same(strings.ToLower(strings.TrimSpace(a.Email)), strings.ToLower(strings.TrimSpace(b.Email))), emailWeight, 1),
+	}
+	var score float64
+	for _, s := range signals {
+		score += s.Contribution
+	}
+	return Match{Score: round(score), Signals: signals}
+}
+
+// Find returns the pairs of contacts scoring at least the threshold, the most alike first. Only contacts sharing a
+// phone, an email or the start of a name are compared, so address books of any size are checked quickly
+func Find(contacts []Contact, t Thresholds) []Pair {
+	blocks := make(map[string][]int)
+	for i, contact := range contacts {
+		for _, key := range blockingKeys(contact) {
+			blocks[key] = append(blocks[key], i)
+		}
+	}
+
+	compared := make(map[[2]int]bool)
+	var pairs []Pair
+	for _, members := range blocks {
+		for x := 0; x < len(members); x++ {
+			for y := x + 1; y < len(members); y++ {
+				key := [2]int{members[x], members[y]}
+				if compared[key] {
+					continue
+				}
+				compared[key] = true
+
+				a, b := contacts[members[x]], contacts[members[y]]
+				if a.ID > b.ID {
+					a, b = b, a
+				}
+				if match := Compare(a, b, t); match.Score >= t.Score {
+					pairs = append(pairs, Pair{A: a.ID, B: b.ID, Match: match})
+				}
+			}
+		}
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].Score != pairs[j].Score {
+			return pairs[i].Score > pairs[j].Score
+		}
+		if pairs[i].A != pairs[j].A {
+			return pairs[i].A < pairs[j].A
+		}
+		return pairs[i].B < pairs[j].B
+	})
+	return pairs
+}
+
+// NameSimilarity scores how alike two names are from 0 to 1, regardless of case and of the order of their words
+func NameSimilarity(a, b string) float64 {
+	wordsA, wordsB := strings.Fields(strings.ToLower(a)), strings.Fields(strings.ToLower(b))
+	if len(wordsA) == 0 || len(wordsB) == 0 {
+		return 0
+	}
+	similarity := levenshteinSimilarity(strings.Join(wordsA, " "), strings.Join(wordsB, " "))
+	sort.Strings(wordsA)
+	sort.Strings(wordsB)
+	return math.Max(similarity, levenshteinSimilarity(strings.Join(wordsA, " "), strings.Join(wordsB, " ")))
+}
+
+func signal(name string, score, weight, threshold float64) Signal {
+	s := Signal{Name: name, Score: score, Weight: weight, Threshold: threshold, Matched: score >= threshold}
+	if s.Matched {
+		s.Contribution = round(score * weight)
+	}
+	return s
+}
+
+// same scores 1 for equal non-empty values, 0 otherwise
+func same(a, b string) float64 {
+	if a != "" && a == b {
+		return 1
+	}
+	return 0
+}
+
+// blockingKeys are the keys of the blocks a contact is compared within: its phone, its email and the first letters
+// of each word of its name
+func blockingKeys(contact Contact) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	add := func(key string) {
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	if contact.Phone != "" {
+		add("p:" + contact.Phone)
+	}
+	if email := strings.ToLower(strings.TrimSpace(contact.Email)); email != "" {
+		add("e:" + email)
+	}
+	for _, word := range strings.Fields(strings.ToLower(contact.Name)) {
+		runes := []rune(word)
+		if len(runes) > 3 {
+			runes = runes[:3]
+		}
+		add("n:" + string(runes))
+	}
+	return keys
+}
+
+// levenshteinSimilarity is 1 minus the edit distance of two strings over the length of the longest
+func levenshteinSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return 1 - float64(previous[len(rb)])/float64(longest)
+}
+
+// round keeps two decimals of scores
+func round(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,53 @@
+// Package demo configures the public demo mode: a shared demo account whose data is reset from seed fixtures
+// on a schedule, while actions unsafe on a public instance are disabled
+package demo
+
+import (
+	_ "embed"
+	"log/slog"
+	"time"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/utils"
+)
+
+// seed is the account archive (see dtos.AccountArchiveDto) the demo account is reset to
+//
+//go:embed seed.json
+var seed []byte
+
+// Config of the demo mode, read from the environment
+type Config struct {
+	Enabled       bool
+	Username      string
+	Email         string
+	Password      string
+	ResetInterval time.Duration
+}
+
+// Load reads the demo mode configuration: DEMO_MODE enables it, DEMO_EMAIL, DEMO_PASSWORD and DEMO_USERNAME set the
+// credentials of the demo account and DEMO_RESET_INTERVAL how often its data is reset
+func Load() Config {
+	interval, err := time.ParseDuration(utils.GetEnvOrDefault("DEMO_RESET_INTERVAL", constants.DefaultDemoResetInterval.String()))
+	if err != nil || interval < constants.MinDemoResetInterval {
+		slog.Warn("Invalid DEMO_RESET_INTERVAL, using the default", "default", constants.DefaultDemoResetInterval)
+		interval = constants.DefaultDemoResetInterval
+	}
+	return Config{
+		Enabled:       Enabled(),
+		Username:      utils.GetEnvOrDefault("DEMO_USERNAME", constants.DefaultDemoUsername),
+		Email:         utils.GetEnvOrDefault("DEMO_EMAIL", constants.DefaultDemoEmail),
+		Password:      utils.GetEnvOrDefault("DEMO_PASSWORD", constants.DefaultDemoPassword),
+		ResetInterval: interval,
+	}
+}
+
+// Enabled reports whether the instance runs in demo mode
+func Enabled() bool {
+	return utils.GetEnvOrDefault("DEMO_MODE", "false") == "true"
+}
+
+// Seed returns the account archive the demo account is reset to
+func Seed() []byte {
+	return seed
+}
@@ -1,5 +1,10 @@
 package dtos
 
+import "time"
+
+// Request DTOs tag the fields filled in by the server (from the JWT or the path) with client:"-",
+// they are left out of the OpenAPI document and the generated clients
+
 //type CreateContactDto struct {
 //	UserID      int    `json:"user_id"`
 //	FirstName   string `json:"first_name"`
@@ -10,22 +15,70 @@ package dtos
 
 // GetContactsResponseDto represents a contact for API responses
 type GetContactsResponseDto struct {
-	ID          int    `json:"id"`
-	UserID      int    `json:"user_id"`
-	FirstName   string `json:"first_name"`
-	LastName    string `json:"last_name"`
-	PhoneNumber string `json:"phone_number"`
-	Address     string `json:"address,omitempty"`
+	ID             int                `json:"id"`
+	UserID         int                `json:"user_id"`
+	FirstName      string             `json:"first_name"`
+	LastName       string             `json:"last_name"`
+	PhoneNumber    string             `json:"phone_number"`
+	Address        string             `json:"address,omitempty"`
+	Email          string             `json:"email,omitempty"`
+	Company        string             `json:"company,omitempty"`
+	JobTitle       string             `json:"job_title,omitempty"`
+	Source         string             `json:"source,omitempty"`
+	Stage          string             `json:"stage,omitempty"`
+	SocialProfiles []SocialProfileDto `json:"social_profiles,omitempty"`
+	Timezone       string             `json:"timezone,omitempty"`
+	Street         string             `json:"street,omitempty"`
+	City           string             `json:"city,omitempty"`
+	Region         string             `json:"region,omitempty"`
+	PostalCode     string             `json:"postal_code,omitempty"`
+	CountryCode    string             `json:"country_code,omitempty"`
+	// FormattedAddress renders the structured address following the conventions of its country, one line per entry
+	FormattedAddress []string `json:"formatted_address,omitempty"`
+	Latitude         *float64 `json:"latitude,omitempty"`
+	Longitude        *float64 `json:"longitude,omitempty"`
+	// LocalTime and WithinWorkingHours are computed per request from Timezone, they are never cached. Without a
+	// Timezone they are estimated from Longitude and LocalTimeEstimated is set
+	LocalTime          string `json:"local_time,omitempty"`
+	WithinWorkingHours *bool  `json:"within_working_hours,omitempty"`
+	LocalTimeEstimated bool   `json:"local_time_estimated,omitempty"`
+	// DeletedAt is set on the contacts of the trash
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	// PhoneNumberE164 is PhoneNumber normalized, empty when it cannot be. PhoneNumberFormatted renders it for the
+	// region of the requesting user and is computed per request, never cached
+	PhoneNumberE164      string `json:"phone_number_e164,omitempty"`
+	PhoneNumberFormatted string `json:"phone_number_formatted,omitempty"`
+	// LastInteractedAt is the last interaction logged with the contact or view of it, omitted when never
+	LastInteractedAt *time.Time `json:"last_interacted_at,omitempty"`
+	// CustomFields holds the values of the fields of the contact template by key
+	CustomFields map[string]string `json:"custom_fields,omitempty"`
+	// ClientID is the UUID the client gave the contact when creating it
+	ClientID string `json:"client_id,omitempty"`
 }
 
 // UpdateContactRequestDto represents the data for updating a contact
 type UpdateContactRequestDto struct {
-	ID          int    `json:"contact_id"`
-	UserID      int    `json:"user_id"`
-	FirstName   string `json:"first_name,omitempty"`
-	LastName    string `json:"last_name,omitempty"`
-	PhoneNumber string `json:"phone_number,omitempty"`
-	Address     string `json:"address,omitempty"`
+	ID          int      `json:"contact_id" client:"-"`
+	UserID      int      `json:"user_id" client:"-"`
+	FirstName   string   `json:"first_name,omitempty"`
+	LastName    string   `json:"last_name,omitempty"`
+	PhoneNumber string   `json:"phone_number,omitempty"`
+	Address     string   `json:"address,omitempty"`
+	Email       string   `json:"email,omitempty" binding:"omitempty,email"`
+	Company     string   `json:"company,omitempty" binding:"max=100"`
+	JobTitle    string   `json:"job_title,omitempty" binding:"max=100"`
+	Timezone    string   `json:"timezone,omitempty"`
+	Street      string   `json:"street,omitempty" binding:"max=255"`
+	City        string   `json:"city,omitempty" binding:"max=100"`
+	Region      string   `json:"region,omitempty" binding:"max=100"`
+	PostalCode  string   `json:"postal_code,omitempty" binding:"max=20"`
+	CountryCode string   `json:"country_code,omitempty"`
+	Latitude    *float64 `json:"latitude,omitempty" binding:"omitempty,min=-90,max=90"`
+	Longitude   *float64 `json:"longitude,omitempty" binding:"omitempty,min=-180,max=180"`
+	Source      string   `json:"source,omitempty"`
+	Stage       string   `json:"stage,omitempty"`
+	// CustomFields sets fields of the contact template by key, an empty value clears an optional field
+	CustomFields map[string]string `json:"custom_fields,omitempty"`
 }
 
 // Define request structure with user ID in body
@@ -37,15 +90,43 @@ type GetContactRequestDto struct {
 	LastName    string `json:"last_name,omitempty"`
 	PhoneNumber string `json:"phone_number,omitempty"`
 	Address     string `json:"address,omitempty"`
+	Social      string `json:"social,omitempty"`
+	GroupID     int    `json:"group,omitempty"`
+	Tag         string `json:"tag,omitempty"`
+	// Query searches first name, last name, phone number and address together, tolerating typos
+	Query string `json:"q,omitempty"`
+	// InactiveFor keeps the contacts without interaction for this long, in days ("90d")
+	InactiveFor string `json:"inactive_for,omitempty"`
+	SortBy      string `json:"sort_by,omitempty"`
+	SortDir     string `json:"sort_dir,omitempty"`
+	// Cursor is the next_cursor of the previous page, Page is ignored when set
+	Cursor string `json:"cursor,omitempty"`
 }
 
 // Define request structure for creating a contact
 type CreateContactRequestDto struct {
-	UserID      int    `json:"user_id"`
-	FirstName   string `json:"first_name" binding:"required"`
-	LastName    string `json:"last_name" binding:"required"`
-	PhoneNumber string `json:"phone_number" binding:"required"`
-	Address     string `json:"address" binding:"required"`
+	UserID      int      `json:"user_id" client:"-"`
+	FirstName   string   `json:"first_name" binding:"required"`
+	LastName    string   `json:"last_name" binding:"required"`
+	PhoneNumber string   `json:"phone_number" binding:"required"`
+	Address     string   `json:"address" binding:"required_without=Street"`
+	Email       string   `json:"email,omitempty" binding:"omitempty,email"`
+	Company     string   `json:"company,omitempty" binding:"max=100"`
+	JobTitle    string   `json:"job_title,omitempty" binding:"max=100"`
+	Timezone    string   `json:"timezone,omitempty"`
+	Street      string   `json:"street,omitempty" binding:"max=255"`
+	City        string   `json:"city,omitempty" binding:"max=100"`
+	Region      string   `json:"region,omitempty" binding:"max=100"`
+	PostalCode  string   `json:"postal_code,omitempty" binding:"max=20"`
+	CountryCode string   `json:"country_code,omitempty"`
+	Latitude    *float64 `json:"latitude,omitempty" binding:"omitempty,min=-90,max=90"`
+	Longitude   *float64 `json:"longitude,omitempty" binding:"omitempty,min=-180,max=180"`
+	Source      string   `json:"source,omitempty"`
+	Stage       string   `json:"stage,omitempty"`
+	// CustomFields sets fields of the contact template by key, fields left out get their default
+	CustomFields map[string]string `json:"custom_fields,omitempty"`
+	// ClientID is a UUID chosen by the client, the contact can be referred to by it in place of its ID
+	ClientID string `json:"client_id,omitempty" binding:"omitempty,uuid"`
 }
 
 type DeleteContactRequestDto struct {
@@ -53,19 +134,24 @@ type DeleteContactRequestDto struct {
 	ContactID int `json:"contact_id" binding:"required"`
 }
 
-// PaginationResult represents a paginated response
+// PaginationResult represents a paginated response. Pages reached by cursor have Page 0, and TotalCount and
+// TotalPages 0 too unless ranked by relevance
 type PaginationResult struct {
 	Items      []GetContactsResponseDto `json:"items"`
 	TotalCount int                      `json:"total_count"`
 	Page       int                      `json:"page"`
 	PageSize   int                      `json:"page_size"`
 	TotalPages int                      `json:"total_pages"`
+	// NextCursor selects the next page with the cursor query parameter, empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 type CreateUserRequestDto struct {
 	Username string `json:"user_name" binding:"required"`
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=6"`
+	// ClientIP is the IP the request came from, recorded in the audit log
+	ClientIP string `json:"-"`
 }
 
 type LoginRequestDto struct {
@@ -73,7 +159,1285 @@ type LoginRequestDto struct {
 	Password string `json:"password" binding:"required"`
 }
 
+// LoginResponseDto carries a short lived access token and the refresh token to get the next one from /token/refresh
 type LoginResponseDto struct {
-	Token  string `json:"token"`
-	UserID int    `json:"user_id"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	// ExpiresIn is the lifetime of the access token in seconds
+	ExpiresIn int `json:"expires_in"`
+	UserID    int `json:"user_id"`
+}
+
+type RefreshTokenRequestDto struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// LogoutRequestDto optionally names the refresh token of the session to end with the access token
+type LogoutRequestDto struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type ChangePasswordRequestDto struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=6"`
+}
+
+// PicklistResponseDto lists the allowed values of a picklist field
+type PicklistResponseDto struct {
+	Field  string   `json:"field"`
+	Values []string `json:"values"`
+}
+
+// CreatePicklistValueRequestDto adds an allowed value to a picklist field
+type CreatePicklistValueRequestDto struct {
+	Field    string `json:"field" client:"-"`
+	Value    string `json:"value" binding:"required,max=50"`
+	Position int    `json:"position,omitempty"`
+}
+
+// CustomFieldDto is a custom field of the contact template
+type CustomFieldDto struct {
+	Key      string   `json:"key"`
+	Label    string   `json:"label"`
+	Type     string   `json:"type"`
+	Options  []string `json:"options,omitempty"`
+	Required bool     `json:"required"`
+	Default  string   `json:"default,omitempty"`
+	Position int      `json:"position"`
+}
+
+// SaveCustomFieldRequestDto creates or replaces a custom field of the contact template
+type SaveCustomFieldRequestDto struct {
+	Key      string   `json:"key" client:"-"`
+	Label    string   `json:"label" binding:"required,max=100"`
+	Type     string   `json:"type" binding:"required"`
+	Options  []string `json:"options,omitempty"`
+	Required bool     `json:"required,omitempty"`
+	Default  string   `json:"default,omitempty" binding:"max=255"`
+	Position int      `json:"position,omitempty"`
+}
+
+// SetTemplateTagsRequestDto sets the tags attached to every new contact
+type SetTemplateTagsRequestDto struct {
+	Tags []string `json:"tags"`
+}
+
+// ContactTemplateDto is the template new contacts follow: their custom fields and the tags they get
+type ContactTemplateDto struct {
+	Version     int              `json:"version"`
+	Fields      []CustomFieldDto `json:"fields"`
+	DefaultTags []string         `json:"default_tags"`
+}
+
+// ContactTemplateMigrationDto reports the migration of the contacts to the current template version
+type ContactTemplateMigrationDto struct {
+	Version int `json:"version"`
+	// OutdatedContacts still follow a previous version, they are migrated in the background
+	OutdatedContacts int `json:"outdated_contacts"`
+	// MissingRequired contacts lack a required field without default, they must set it on their next update
+	MissingRequired int `json:"missing_required"`
+}
+
+// ContactStatsResponseDto aggregates a user's contacts by picklist fields
+type ContactStatsResponseDto struct {
+	TotalCount int            `json:"total_count"`
+	ByStage    map[string]int `json:"by_stage"`
+	BySource   map[string]int `json:"by_source"`
+}
+
+// BoardRequestDto represents a request for the kanban board grouped by a picklist field
+type BoardRequestDto struct {
+	UserID   int    `json:"user_id"`
+	Field    string `form:"field" json:"field"`
+	Column   string `form:"column" json:"column,omitempty"`
+	Page     int    `form:"page" json:"page"`
+	PageSize int    `form:"page_size" json:"page_size"`
+}
+
+// BoardColumnDto is a single paginated column of the kanban board
+type BoardColumnDto struct {
+	Value      string                   `json:"value"`
+	Items      []GetContactsResponseDto `json:"items"`
+	TotalCount int                      `json:"total_count"`
+	Page       int                      `json:"page"`
+	PageSize   int                      `json:"page_size"`
+	TotalPages int                      `json:"total_pages"`
+}
+
+// BoardResponseDto represents the kanban board, one column per allowed picklist value
+type BoardResponseDto struct {
+	Field   string           `json:"field"`
+	Columns []BoardColumnDto `json:"columns"`
+}
+
+// MoveContactRequestDto represents the data for moving a contact to a stage column
+type MoveContactRequestDto struct {
+	UserID    int    `json:"user_id" client:"-"`
+	ContactID int    `json:"contact_id" client:"-"`
+	Stage     string `json:"stage" binding:"required"`
+	Position  int    `json:"position,omitempty"`
+}
+
+// UploadAttachmentRequestDto represents the metadata of an uploaded attachment
+type UploadAttachmentRequestDto struct {
+	UserID    int    `json:"user_id"`
+	ContactID int    `json:"contact_id"`
+	FileName  string `json:"file_name"`
+	SizeBytes int64  `json:"size_bytes"`
+}
+
+// AttachmentResponseDto represents an attachment for API responses
+type AttachmentResponseDto struct {
+	ID          int       `json:"id"`
+	ContactID   int       `json:"contact_id"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	SizeBytes   int64     `json:"size_bytes"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// StorageUsageDto represents the attachment storage used by a user against the quota
+type StorageUsageDto struct {
+	UsedBytes  int64 `json:"used_bytes"`
+	QuotaBytes int64 `json:"quota_bytes"`
+}
+
+// AttachmentListResponseDto lists the attachments of a contact with the user's storage usage
+type AttachmentListResponseDto struct {
+	Items   []AttachmentResponseDto `json:"items"`
+	Storage StorageUsageDto         `json:"storage"`
+}
+
+// AttachmentURLResponseDto represents a signed, expiring download link
+type AttachmentURLResponseDto struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// CardImportResponseDto is a draft contact extracted from a business card photo, to be confirmed with POST /contacts
+type CardImportResponseDto struct {
+	Draft   CreateContactRequestDto `json:"draft"`
+	RawText string                  `json:"raw_text"`
+}
+
+// EnrichmentResponseDto represents data suggested by an enrichment provider together with its provenance
+type EnrichmentResponseDto struct {
+	ID             int               `json:"id"`
+	ContactID      int               `json:"contact_id"`
+	Provider       string            `json:"provider"`
+	MatchedOn      string            `json:"matched_on"`
+	Status         string            `json:"status"`
+	Company        string            `json:"company,omitempty"`
+	JobTitle       string            `json:"job_title,omitempty"`
+	SocialProfiles map[string]string `json:"social_profiles,omitempty"`
+	FetchedAt      time.Time         `json:"fetched_at"`
+	ResolvedAt     *time.Time        `json:"resolved_at,omitempty"`
+}
+
+// SocialProfileDto represents a contact's profile on a social network
+type SocialProfileDto struct {
+	Network string `json:"network"`
+	Handle  string `json:"handle"`
+	URL     string `json:"url"`
+}
+
+// SetSocialProfileRequestDto sets a social profile from a handle or a profile URL
+type SetSocialProfileRequestDto struct {
+	Value string `json:"value" binding:"required"`
+}
+
+// ContactsGeoJSONRequestDto selects the contacts plotted on the map, using the same filters as the contact list
+type ContactsGeoJSONRequestDto struct {
+	UserID      int    `json:"user_id"`
+	FirstName   string `json:"first_name,omitempty"`
+	LastName    string `json:"last_name,omitempty"`
+	PhoneNumber string `json:"phone_number,omitempty"`
+	Address     string `json:"address,omitempty"`
+	Social      string `json:"social,omitempty"`
+	GroupID     int    `json:"group,omitempty"`
+	Zoom        int    `json:"zoom"`
+}
+
+// GeoJSONFeatureCollectionDto is a GeoJSON FeatureCollection of contacts or contact clusters
+type GeoJSONFeatureCollectionDto struct {
+	Type      string              `json:"type"`
+	Features  []GeoJSONFeatureDto `json:"features"`
+	Clustered bool                `json:"clustered"`
+}
+
+// GeoJSONFeatureDto is a GeoJSON Feature, a single contact or a cluster of nearby contacts
+type GeoJSONFeatureDto struct {
+	Type       string                 `json:"type"`
+	Geometry   GeoJSONPointDto        `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// GeoJSONPointDto is a GeoJSON Point, coordinates are [longitude, latitude]
+type GeoJSONPointDto struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+// AuditExportRequestDto selects the audit history to export, AccountID narrows an admin export to one account
+type AuditExportRequestDto struct {
+	UserID    int
+	IsAdmin   bool
+	AccountID int
+	Format    string
+	Action    string
+	ActorID   int
+	From      time.Time
+	To        time.Time
+}
+
+// CreateGroupRequestDto creates a contact group
+type CreateGroupRequestDto struct {
+	UserID int    `json:"user_id" client:"-"`
+	Name   string `json:"name" binding:"required,max=50"`
+}
+
+// GroupResponseDto represents a contact group for API responses
+type GroupResponseDto struct {
+	ID           int       `json:"id"`
+	Name         string    `json:"name"`
+	ContactCount int       `json:"contact_count"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type CreateTagRequestDto struct {
+	UserID int    `json:"user_id" client:"-"`
+	Name   string `json:"name" binding:"required,max=50"`
+}
+
+// TagResponseDto represents a tag for API responses
+type TagResponseDto struct {
+	ID           int       `json:"id"`
+	Name         string    `json:"name"`
+	ContactCount int       `json:"contact_count"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// TagContactRequestDto names the tag attached to a contact
+type TagContactRequestDto struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// ContactTagsResponseDto lists the tags of a contact
+type ContactTagsResponseDto struct {
+	ContactID int             `json:"contact_id"`
+	Tags      []MembershipDto `json:"tags"`
+}
+
+// ContactMembershipsDto lists the groups and tags a contact belongs to
+type ContactMembershipsDto struct {
+	Groups []MembershipDto `json:"groups"`
+	Tags   []MembershipDto `json:"tags"`
+}
+
+// MembershipDto identifies a group or tag
+type MembershipDto struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// BulkContactsRequestDto lists the contacts attached to or detached from a group or tag at once
+type BulkContactsRequestDto struct {
+	ContactIDs []int `json:"contact_ids" binding:"required,min=1,max=1000,dive,min=1"`
+}
+
+// BulkContactsResponseDto reports how many contacts a bulk request actually changed
+type BulkContactsResponseDto struct {
+	Requested int `json:"requested"`
+	Changed   int `json:"changed"`
+}
+
+// CreateSnapshotRequestDto takes a named snapshot of the address book
+type CreateSnapshotRequestDto struct {
+	UserID int    `json:"user_id" client:"-"`
+	Name   string `json:"name" binding:"max=100"`
+}
+
+// SnapshotResponseDto represents a snapshot for API responses
+type SnapshotResponseDto struct {
+	ID           int       `json:"id"`
+	Name         string    `json:"name"`
+	ContactCount int       `json:"contact_count"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// SnapshotContactDto identifies a contact in a snapshot diff
+type SnapshotContactDto struct {
+	ID        int    `json:"id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+// SnapshotFieldChangeDto is a field whose value differs between the snapshot and now
+type SnapshotFieldChangeDto struct {
+	Field    string `json:"field"`
+	Snapshot string `json:"snapshot"`
+	Current  string `json:"current"`
+}
+
+// SnapshotContactChangeDto lists the changed fields of a contact
+type SnapshotContactChangeDto struct {
+	SnapshotContactDto
+	Changes []SnapshotFieldChangeDto `json:"changes"`
+}
+
+// SnapshotDiffResponseDto describes how the current address book differs from a snapshot
+type SnapshotDiffResponseDto struct {
+	SnapshotID int                        `json:"snapshot_id"`
+	Added      []SnapshotContactDto       `json:"added"`
+	Removed    []SnapshotContactDto       `json:"removed"`
+	Changed    []SnapshotContactChangeDto `json:"changed"`
+}
+
+// RestoreSnapshotResponseDto reports what restoring a snapshot changed
+type RestoreSnapshotResponseDto struct {
+	SnapshotID int `json:"snapshot_id"`
+	Recreated  int `json:"recreated"`
+	Updated    int `json:"updated"`
+	Deleted    int `json:"deleted"`
+}
+
+// PreferencesResponseDto represents the preferences of a user
+type PreferencesResponseDto struct {
+	WeeklyDigest bool `json:"weekly_digest"`
+	// Region is the ISO 3166-1 alpha-2 country of the user, empty when not set
+	Region string `json:"region"`
+	// SearchLanguage is the language contacts are searched in (a Postgres text search configuration such as
+	// english), empty when the deployment one is used
+	SearchLanguage string `json:"search_language"`
+	// DuplicateSensitivity is how alike contacts must be to be listed as duplicates: low, medium or high
+	DuplicateSensitivity string `json:"duplicate_sensitivity"`
+}
+
+// UpdatePreferencesRequestDto changes the preferences of a user, omitted fields are kept
+type UpdatePreferencesRequestDto struct {
+	UserID       int     `json:"user_id" client:"-"`
+	WeeklyDigest *bool   `json:"weekly_digest"`
+	Region       *string `json:"region"`
+	// SearchLanguage changes the language contacts are searched in, empty to use the deployment one. Contacts are
+	// reindexed in the background
+	SearchLanguage *string `json:"search_language"`
+	// DuplicateSensitivity changes how alike contacts must be to be listed as duplicates, empty for the default
+	DuplicateSensitivity *string `json:"duplicate_sensitivity"`
+}
+
+// NotificationPreferencesDto is the notification preferences matrix of a user: whether they are notified of each
+// event on each channel
+type NotificationPreferencesDto struct {
+	Events map[string]map[string]bool `json:"events"`
+}
+
+// UpdateNotificationPreferencesRequestDto turns cells of the notification preferences matrix on or off, the cells
+// omitted are kept
+type UpdateNotificationPreferencesRequestDto struct {
+	UserID int                        `json:"user_id" client:"-"`
+	Events map[string]map[string]bool `json:"events" binding:"required"`
+}
+
+// CreateAnnouncementRequestDto posts an announcement to every user
+type CreateAnnouncementRequestDto struct {
+	Message string `json:"message" binding:"required,max=500"`
+	// Level is info (default), warning or maintenance
+	Level string `json:"level"`
+	// StartsAt schedules the announcement, now when omitted
+	StartsAt *time.Time `json:"starts_at"`
+	// EndsAt hides the announcement from then on, it is shown until deleted when omitted
+	EndsAt *time.Time `json:"ends_at"`
+}
+
+// AnnouncementDto represents a deployment wide announcement
+type AnnouncementDto struct {
+	ID        int        `json:"id"`
+	Message   string     `json:"message"`
+	Level     string     `json:"level"`
+	StartsAt  time.Time  `json:"starts_at"`
+	EndsAt    *time.Time `json:"ends_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// AnnouncementListResponseDto is the body of the announcement listings
+type AnnouncementListResponseDto struct {
+	Items []AnnouncementDto `json:"items"`
+}
+
+// ErrorResponseDto is the body of every error response
+type ErrorResponseDto struct {
+	Error string `json:"error"`
+}
+
+// RateLimitErrorResponseDto is the body of a 429 response, Reset is a Unix timestamp and RetryAfter a number of seconds
+type RateLimitErrorResponseDto struct {
+	Error      string `json:"error"`
+	Limit      int    `json:"limit"`
+	Remaining  int    `json:"remaining"`
+	Reset      int64  `json:"reset"`
+	RetryAfter int    `json:"retry_after"`
+}
+
+// MessageResponseDto is the body of responses confirming an action
+type MessageResponseDto struct {
+	Message string `json:"message"`
+}
+
+// CreateUserResponseDto is returned when a user registers
+type CreateUserResponseDto struct {
+	Message string `json:"message"`
+	UserID  int    `json:"userID"`
+}
+
+// CreateContactResponseDto is returned when a contact is created
+type CreateContactResponseDto struct {
+	Message   string `json:"message"`
+	ContactID int    `json:"contact_id"`
+}
+
+// EnrichmentListResponseDto lists the enrichment suggestions of a contact
+type EnrichmentListResponseDto struct {
+	Items []EnrichmentResponseDto `json:"items"`
+}
+
+// ContactCompletenessDto scores how filled in the optional fields of a contact are, from 0 to 100
+type ContactCompletenessDto struct {
+	ContactID int      `json:"contact_id"`
+	Score     int      `json:"score"`
+	Filled    []string `json:"filled"`
+	Missing   []string `json:"missing"`
+}
+
+// IncompleteContactsRequestDto selects a page of the contacts missing any of the fields, every scored field when
+// Missing is empty
+type IncompleteContactsRequestDto struct {
+	UserID   int    `json:"user_id"`
+	Missing  string `form:"missing" json:"missing,omitempty"`
+	Page     int    `form:"page" json:"page"`
+	PageSize int    `form:"page_size" json:"page_size"`
+}
+
+// IncompleteContactDto is a contact with the fields it is missing
+type IncompleteContactDto struct {
+	GetContactsResponseDto
+	Completeness int      `json:"completeness"`
+	Missing      []string `json:"missing"`
+}
+
+// IncompleteContactsResponseDto is a page of the contacts missing data, least complete first
+type IncompleteContactsResponseDto struct {
+	Items      []IncompleteContactDto `json:"items"`
+	TotalCount int                    `json:"total_count"`
+	Page       int                    `json:"page"`
+	PageSize   int                    `json:"page_size"`
+	TotalPages int                    `json:"total_pages"`
+}
+
+// DuplicateContactsRequestDto selects the duplicate contacts of a user, at the sensitivity of their preferences when
+// Sensitivity is empty
+type DuplicateContactsRequestDto struct {
+	UserID      int    `json:"user_id"`
+	Sensitivity string `form:"sensitivity" json:"sensitivity,omitempty"`
+}
+
+// DuplicateThresholdsDto are the thresholds pairs of contacts were flagged with
+type DuplicateThresholdsDto struct {
+	// Score is the lowest score of a pair flagged as duplicates
+	Score float64 `json:"score"`
+	// NameSimilarity is the lowest name similarity counted as a match
+	NameSimilarity float64 `json:"name_similarity"`
+}
+
+// DuplicateSignalDto is how alike a pair of contacts is on one signal: name_similarity, phone_match or email_match.
+// Contribution is what it adds to the score of the pair, nothing unless Matched
+type DuplicateSignalDto struct {
+	Signal       string  `json:"signal"`
+	Score        float64 `json:"score"`
+	Weight       float64 `json:"weight"`
+	Contribution float64 `json:"contribution"`
+	Threshold    float64 `json:"threshold"`
+	Matched      bool    `json:"matched"`
+}
+
+// DuplicatePairDto is a pair of contacts flagged as duplicates, the score being the sum of the contributions of its
+// signals
+type DuplicatePairDto struct {
+	Contacts []GetContactsResponseDto `json:"contacts"`
+	Score    float64                  `json:"score"`
+	Signals  []DuplicateSignalDto     `json:"signals"`
+}
+
+// DuplicateContactsResponseDto lists the pairs of duplicate contacts of a user, the most alike first. Truncated is
+// true when more pairs than listed were flagged
+type DuplicateContactsResponseDto struct {
+	Sensitivity string                 `json:"sensitivity"`
+	Thresholds  DuplicateThresholdsDto `json:"thresholds"`
+	Pairs       []DuplicatePairDto     `json:"pairs"`
+	Truncated   bool                   `json:"truncated"`
+}
+
+// TrashListResponseDto lists the contacts of a user in the trash, most recently deleted first
+type TrashListResponseDto struct {
+	Items []GetContactsResponseDto `json:"items"`
+}
+
+// TrashedContactDto is a contact of the trash with its memberships and when and by whom it was deleted
+type TrashedContactDto struct {
+	GetContactsResponseDto
+	Groups []MembershipDto `json:"groups"`
+	Tags   []MembershipDto `json:"tags"`
+	// DeletedBy is the user who moved the contact to the trash, unknown for contacts deleted before the history
+	DeletedBy *int `json:"deleted_by,omitempty"`
+	// PurgeAt is when the contact is deleted for good unless restored
+	PurgeAt time.Time `json:"purge_at"`
+}
+
+// ContactFieldChangeDto is the value of a field before and after a change, From is null when the contact was created
+type ContactFieldChangeDto struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// ContactHistoryEntryDto is a change of a contact: created, updated, deleted or restored, with the changed fields
+type ContactHistoryEntryDto struct {
+	ID        int64                            `json:"id"`
+	Action    string                           `json:"action"`
+	Changes   map[string]ContactFieldChangeDto `json:"changes"`
+	CreatedAt time.Time                        `json:"created_at"`
+}
+
+// ContactHistoryResponseDto lists the changes of a contact, oldest first
+type ContactHistoryResponseDto struct {
+	ContactID int                      `json:"contact_id"`
+	Items     []ContactHistoryEntryDto `json:"items"`
+}
+
+// LogInteractionRequestDto logs an interaction with a contact, OccurredAt defaults to now
+type LogInteractionRequestDto struct {
+	Kind       string     `json:"kind" binding:"required"`
+	Note       string     `json:"note,omitempty" binding:"max=2000"`
+	OccurredAt *time.Time `json:"occurred_at,omitempty"`
+}
+
+// InteractionDto represents an interaction logged with a contact
+type InteractionDto struct {
+	ID         int       `json:"id"`
+	ContactID  int       `json:"contact_id"`
+	Kind       string    `json:"kind"`
+	Note       string    `json:"note,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// InteractionListResponseDto lists the interactions of a contact, the most recent first
+type InteractionListResponseDto struct {
+	Items []InteractionDto `json:"items"`
+}
+
+// SocialProfileListResponseDto lists the social profiles of a contact
+type SocialProfileListResponseDto struct {
+	Items []SocialProfileDto `json:"items"`
+}
+
+// GroupListResponseDto lists the contact groups of a user
+type GroupListResponseDto struct {
+	Items []GroupResponseDto `json:"items"`
+}
+
+// TagListResponseDto lists the tags of a user
+type TagListResponseDto struct {
+	Items []TagResponseDto `json:"items"`
+}
+
+// SnapshotListResponseDto lists the snapshots of a user
+type SnapshotListResponseDto struct {
+	Items []SnapshotResponseDto `json:"items"`
+}
+
+// CreateWebhookRequestDto registers a URL receiving signed event deliveries
+type CreateWebhookRequestDto struct {
+	UserID int    `json:"user_id" client:"-"`
+	URL    string `json:"url" binding:"required,url,max=2048"`
+}
+
+// WebhookResponseDto represents a webhook for API responses, the secret is only returned when it is created
+type WebhookResponseDto struct {
+	ID        int       `json:"id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookListResponseDto lists the webhooks of a user
+type WebhookListResponseDto struct {
+	Items []WebhookResponseDto `json:"items"`
+}
+
+// TestWebhookRequestDto selects the webhook receiving a sample event
+type TestWebhookRequestDto struct {
+	UserID    int `json:"user_id" client:"-"`
+	WebhookID int `json:"webhook_id" binding:"required"`
+}
+
+// TestWebhookResponseDto reports the outcome of a test delivery
+type TestWebhookResponseDto struct {
+	WebhookID  int    `json:"webhook_id"`
+	EventID    string `json:"event_id"`
+	Delivered  bool   `json:"delivered"`
+	StatusCode int    `json:"status_code,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// WebhookDeliveryDto is the delivery of an event to a webhook. Pending deliveries are retried at NextAttemptAt,
+// failed ones ran out of attempts. StatusCode and Error describe the last attempt
+type WebhookDeliveryDto struct {
+	ID            int64      `json:"id"`
+	EventID       string     `json:"event_id"`
+	EventType     string     `json:"event_type"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	StatusCode    int        `json:"status_code,omitempty"`
+	Error         string     `json:"error,omitempty"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+}
+
+// WebhookDeliveryListResponseDto lists the latest deliveries of a webhook, newest first
+type WebhookDeliveryListResponseDto struct {
+	Items []WebhookDeliveryDto `json:"items"`
+}
+
+// CreateAPIKeyRequestDto creates an API key for an integration
+type CreateAPIKeyRequestDto struct {
+	UserID int    `json:"user_id" client:"-"`
+	Name   string `json:"name" binding:"required,max=50"`
+}
+
+// APIKeyResponseDto represents an API key for API responses, the secret is only returned when it is created
+type APIKeyResponseDto struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	KeyID     string    `json:"key_id"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// APIKeyListResponseDto lists the API keys of a user
+type APIKeyListResponseDto struct {
+	Items []APIKeyResponseDto `json:"items"`
+}
+
+// ContactChangeDto is a change to the contacts of a user, EntityType is contact, or snapshot when the whole
+// address book was restored
+type ContactChangeDto struct {
+	ID         int64     `json:"id"`
+	Action     string    `json:"action"`
+	EntityType string    `json:"entity_type"`
+	EntityID   int       `json:"entity_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ContactChangesResponseDto lists the changes after a cursor, Cursor is passed as since to the next request
+type ContactChangesResponseDto struct {
+	Changes []ContactChangeDto `json:"changes"`
+	Cursor  int64              `json:"cursor"`
+	HasMore bool               `json:"has_more"`
+}
+
+// ContactCountResponseDto is the contact count badge: the number of contacts and of the changes after since.
+// Cursor is passed as since once the client synced
+type ContactCountResponseDto struct {
+	Total         int64 `json:"total"`
+	UnreadChanges int64 `json:"unread_changes"`
+	Cursor        int64 `json:"cursor"`
+}
+
+// AccountArchiveDto is the export of a whole account. Format and Version identify the layout, an import skips the
+// sections and fields it does not know so archives of newer versions still import what this version understands
+type AccountArchiveDto struct {
+	Format      string                  `json:"format" binding:"required"`
+	Version     int                     `json:"version" binding:"required,min=1"`
+	ExportedAt  time.Time               `json:"exported_at"`
+	Contacts    []ArchiveContactDto     `json:"contacts" binding:"dive"`
+	Groups      []string                `json:"groups" binding:"dive,required,max=50"`
+	Tags        []string                `json:"tags" binding:"dive,required,max=50"`
+	Preferences *PreferencesResponseDto `json:"preferences,omitempty"`
+}
+
+// ArchiveContactDto is a contact of an account archive with the names of its groups and tags
+type ArchiveContactDto struct {
+	FirstName      string             `json:"first_name" binding:"required,max=100"`
+	LastName       string             `json:"last_name" binding:"required,max=100"`
+	PhoneNumber    string             `json:"phone_number" binding:"required,max=20"`
+	Address        string             `json:"address,omitempty"`
+	Email          string             `json:"email,omitempty" binding:"omitempty,email,max=100"`
+	Company        string             `json:"company,omitempty" binding:"max=100"`
+	JobTitle       string             `json:"job_title,omitempty" binding:"max=100"`
+	Timezone       string             `json:"timezone,omitempty"`
+	Street         string             `json:"street,omitempty" binding:"max=255"`
+	City           string             `json:"city,omitempty" binding:"max=100"`
+	Region         string             `json:"region,omitempty" binding:"max=100"`
+	PostalCode     string             `json:"postal_code,omitempty" binding:"max=20"`
+	CountryCode    string             `json:"country_code,omitempty"`
+	Latitude       *float64           `json:"latitude,omitempty" binding:"omitempty,min=-90,max=90"`
+	Longitude      *float64           `json:"longitude,omitempty" binding:"omitempty,min=-180,max=180"`
+	Source         string             `json:"source,omitempty"`
+	Stage          string             `json:"stage,omitempty"`
+	SocialProfiles []SocialProfileDto `json:"social_profiles,omitempty"`
+	Groups         []string           `json:"groups,omitempty"`
+	Tags           []string           `json:"tags,omitempty"`
+}
+
+// ImportAccountResponseDto reports what an account import added, Warnings lists what was skipped
+type ImportAccountResponseDto struct {
+	ContactsImported    int      `json:"contacts_imported"`
+	ContactsSkipped     int      `json:"contacts_skipped"`
+	GroupsCreated       int      `json:"groups_created"`
+	TagsCreated         int      `json:"tags_created"`
+	PreferencesImported bool     `json:"preferences_imported"`
+	Warnings            []string `json:"warnings,omitempty"`
+}
+
+// ArchiveValidationErrorDto rejects an account archive, Problems lists what is wrong with it
+type ArchiveValidationErrorDto struct {
+	Error    string   `json:"error"`
+	Problems []string `json:"problems"`
+}
+
+// AnalyticsSettingsDto represents the instance wide analytics settings, Configured is false when no sink is set up
+// and then nothing is recorded whatever Enabled says
+type AnalyticsSettingsDto struct {
+	Enabled    bool `json:"enabled"`
+	Configured bool `json:"configured"`
+}
+
+// UpdateAnalyticsSettingsRequestDto opts the instance in or out of analytics
+type UpdateAnalyticsSettingsRequestDto struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+// AdminStatsResponseDto aggregates instance wide numbers for the admin dashboard. Imports and exports are read from
+// the analytics events and stay 0 unless they are stored in Postgres, the cache and job numbers are those of the
+// replica answering
+type AdminStatsResponseDto struct {
+	Users            int           `json:"users"`
+	Contacts         int           `json:"contacts"`
+	DailyActiveUsers int           `json:"daily_active_users"`
+	ImportsLast24h   int           `json:"imports_last_24h"`
+	ExportsLast24h   int           `json:"exports_last_24h"`
+	Cache            CacheStatsDto `json:"cache"`
+	Jobs             JobStatsDto   `json:"jobs"`
+	GeneratedAt      time.Time     `json:"generated_at"`
+}
+
+// CacheStatsDto counts the lookups of the contacts cache, HitRate is omitted before the first lookup
+type CacheStatsDto struct {
+	Hits    int64    `json:"hits"`
+	Misses  int64    `json:"misses"`
+	HitRate *float64 `json:"hit_rate,omitempty"`
+}
+
+// JobStatsDto is the work waiting for the background jobs
+type JobStatsDto struct {
+	DigestsDue        int `json:"digests_due"`
+	AnalyticsBuffered int `json:"analytics_buffered"`
+}
+
+// APIUsageCountsDto counts API requests and the bytes of their bodies, RateLimited counts the requests rejected
+// with 429
+type APIUsageCountsDto struct {
+	Requests    int64 `json:"requests"`
+	RateLimited int64 `json:"rate_limited"`
+	BytesIn     int64 `json:"bytes_in"`
+	BytesOut    int64 `json:"bytes_out"`
+}
+
+// APIUsageDayDto is the usage of a UTC day
+type APIUsageDayDto struct {
+	Date string `json:"date"`
+	APIUsageCountsDto
+}
+
+// APIUsageEndpointDto is the usage of an endpoint, RateLimitPerMinute is set for endpoints limited on their own
+// on top of the limit shared by every endpoint
+type APIUsageEndpointDto struct {
+	Endpoint           string `json:"endpoint"`
+	Method             string `json:"method"`
+	Path               string `json:"path"`
+	RateLimitPerMinute int    `json:"rate_limit_per_minute,omitempty"`
+	APIUsageCountsDto
+}
+
+// APIUsageResponseDto is the API usage over the days From to To (UTC, inclusive), every day is listed
+type APIUsageResponseDto struct {
+	From      string                `json:"from"`
+	To        string                `json:"to"`
+	Total     APIUsageCountsDto     `json:"total"`
+	Days      []APIUsageDayDto      `json:"days"`
+	Endpoints []APIUsageEndpointDto `json:"endpoints"`
+}
+
+// APIUserUsageDto is the usage of a user over the period of a report
+type APIUserUsageDto struct {
+	UserID   int    `json:"user_id"`
+	Username string `json:"username"`
+	APIUsageCountsDto
+}
+
+// AdminAPIUsageResponseDto is the API usage of every user, with the users sending the most requests
+type AdminAPIUsageResponseDto struct {
+	APIUsageResponseDto
+	TopUsers []APIUserUsageDto `json:"top_users"`
+}
+
+// AlertSettingsDto represents the 5xx error budget alerting, Enabled is false when no notifier is configured. Routes
+// lists the routes with a suppression override or traffic in the current window on the replica answering
+type AlertSettingsDto struct {
+	Enabled            bool            `json:"enabled"`
+	Threshold          float64         `json:"threshold"`
+	MinRequests        int             `json:"min_requests"`
+	Window             string          `json:"window"`
+	DefaultSuppression string          `json:"default_suppression"`
+	Routes             []RouteAlertDto `json:"routes"`
+}
+
+// RouteAlertDto represents the alerting state of a route, Suppression is empty when the route uses the default
+type RouteAlertDto struct {
+	Route           string     `json:"route"`
+	Suppression     string     `json:"suppression,omitempty"`
+	Requests        int        `json:"requests"`
+	Errors          int        `json:"errors"`
+	SuppressedUntil *time.Time `json:"suppressed_until,omitempty"`
+}
+
+// SetRouteSuppressionRequestDto sets how long alerts of a route ("GET /contacts/:id") are suppressed after one fires,
+// an empty Suppression resets the route to the default
+type SetRouteSuppressionRequestDto struct {
+	Route       string `json:"route" binding:"required"`
+	Suppression string `json:"suppression"`
+}
+
+// ContactFileImportResponseDto reports the import of a CSV or vCard file, Errors lists every row that was not
+// imported with the line it starts on
+type ContactFileImportResponseDto struct {
+	Format   string                   `json:"format"`
+	Rows     int                      `json:"rows"`
+	Imported int                      `json:"imported"`
+	Failed   int                      `json:"failed"`
+	Errors   []ContactFileRowErrorDto `json:"errors"`
+	Warnings []string                 `json:"warnings,omitempty"`
+}
+
+// ContactFileRowErrorDto explains why a row of a contact file was not imported
+type ContactFileRowErrorDto struct {
+	Line  int    `json:"line"`
+	Name  string `json:"name,omitempty"`
+	Error string `json:"error"`
+}
+
+// DataValidationReportDto is the report of admin validate-data, Issues lists every problem found in the scanned
+// contacts and ByKind counts them by kind
+type DataValidationReportDto struct {
+	Scanned int            `json:"scanned"`
+	Issues  []DataIssueDto `json:"issues"`
+	ByKind  map[string]int `json:"by_kind"`
+	Fixed   int            `json:"fixed"`
+}
+
+// DataIssueDto is a problem in a field of a contact. Fix is the value it can be repaired to, empty when it needs a
+// human, Fixed tells whether the fix was applied
+type DataIssueDto struct {
+	ContactID int    `json:"contact_id"`
+	UserID    int    `json:"user_id"`
+	Field     string `json:"field"`
+	Kind      string `json:"kind"`
+	Value     string `json:"value"`
+	Fix       string `json:"fix,omitempty"`
+	Fixed     bool   `json:"fixed"`
+}
+
+// MergeUsersRequestDto asks to move everything of the source account to the target account and delete the source
+type MergeUsersRequestDto struct {
+	SourceUserID int `json:"source_user_id" binding:"required,min=1"`
+	TargetUserID int `json:"target_user_id" binding:"required,min=1"`
+	// AdminID is the admin merging the accounts, recorded in the audit log
+	AdminID int `json:"-"`
+}
+
+// MergeUsersResponseDto counts what was moved to the target account, groups and tags named like one of the target
+// are folded into it and not counted
+type MergeUsersResponseDto struct {
+	SourceUserID int `json:"source_user_id"`
+	TargetUserID int `json:"target_user_id"`
+	Contacts     int `json:"contacts"`
+	Groups       int `json:"groups"`
+	Tags         int `json:"tags"`
+	Attachments  int `json:"attachments"`
+	Snapshots    int `json:"snapshots"`
+	Webhooks     int `json:"webhooks"`
+	APIKeys      int `json:"api_keys"`
+}
+
+// UserSummaryDto is an account as listed to admins
+type UserSummaryDto struct {
+	ID        int       `json:"id"`
+	UserName  string    `json:"user_name"`
+	Email     string    `json:"email"`
+	IsAdmin   bool      `json:"is_admin"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DuplicateUsersDto are the accounts sharing an email once lower cased and trimmed, oldest first
+type DuplicateUsersDto struct {
+	Email string           `json:"email"`
+	Users []UserSummaryDto `json:"users"`
+}
+
+// DuplicateUsersResponseDto lists the accounts that are candidates for a merge
+type DuplicateUsersResponseDto struct {
+	Duplicates []DuplicateUsersDto `json:"duplicates"`
+}
+
+// ProfileResponseDto is the account of the current user
+type ProfileResponseDto struct {
+	ID        int       `json:"id"`
+	UserName  string    `json:"user_name"`
+	Email     string    `json:"email"`
+	IsAdmin   bool      `json:"is_admin"`
+	CreatedAt time.Time `json:"created_at"`
+	// PendingEmailChange is the email change waiting for confirmation, if any
+	PendingEmailChange *EmailChangeDto `json:"pending_email_change,omitempty"`
+	// UsernameChangeAvailableAt is set while the username was changed too recently to change it again
+	UsernameChangeAvailableAt *time.Time          `json:"username_change_available_at,omitempty"`
+	UsernameHistory           []UsernameChangeDto `json:"username_history,omitempty"`
+}
+
+// UsernameChangeDto is a past change of the username of a user
+type UsernameChangeDto struct {
+	OldUserName string    `json:"old_user_name"`
+	NewUserName string    `json:"new_user_name"`
+	ChangedAt   time.Time `json:"changed_at"`
+}
+
+// UpdateProfileRequestDto changes the username and starts changing the email of the current user, omitted fields
+// are kept. The password is required with an email
+type UpdateProfileRequestDto struct {
+	UserID   int    `json:"user_id" client:"-"`
+	UserName string `json:"user_name,omitempty" binding:"max=50"`
+	Email    string `json:"email,omitempty" binding:"omitempty,email,max=100"`
+	Password string `json:"password,omitempty"`
+	ClientIP string `json:"-"`
+}
+
+// DeleteAccountRequestDto deletes the current user, the password is required
+type DeleteAccountRequestDto struct {
+	UserID   int    `json:"user_id" client:"-"`
+	Password string `json:"password" binding:"required"`
+	ClientIP string `json:"-"`
+}
+
+// DeactivateAccountRequestDto deactivates the current user, the password is required
+type DeactivateAccountRequestDto struct {
+	UserID   int    `json:"user_id" client:"-"`
+	Password string `json:"password" binding:"required"`
+	ClientIP string `json:"-"`
+}
+
+// RequestReactivationDto asks for the reactivation link of the deactivated account of an email
+type RequestReactivationDto struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ChangeUsernameRequestDto renames the current user
+type ChangeUsernameRequestDto struct {
+	UserID   int    `json:"user_id" client:"-"`
+	UserName string `json:"user_name" binding:"required,max=50"`
+	ClientIP string `json:"-"`
+}
+
+// UserLookupResponseDto is the account a username belongs to. RedirectedFrom is set when the username is a former
+// one of the account, still reserved to it
+type UserLookupResponseDto struct {
+	ID             int    `json:"id"`
+	UserName       string `json:"user_name"`
+	RedirectedFrom string `json:"redirected_from,omitempty"`
+}
+
+// RequestEmailChangeDto starts changing the email of a user, the current password is required
+type RequestEmailChangeDto struct {
+	UserID   int    `json:"user_id" client:"-"`
+	NewEmail string `json:"new_email" binding:"required,email,max=100"`
+	Password string `json:"password" binding:"required"`
+	ClientIP string `json:"-"`
+}
+
+// EmailChangeDto is the state of a pending email change, which takes effect once both addresses confirmed it
+type EmailChangeDto struct {
+	NewEmail string `json:"new_email"`
+	// CurrentConfirmed and NewConfirmed tell which of the links sent to the current and the new address were followed
+	CurrentConfirmed bool      `json:"current_confirmed"`
+	NewConfirmed     bool      `json:"new_confirmed"`
+	RequestedAt      time.Time `json:"requested_at"`
+	ExpiresAt        time.Time `json:"expires_at"`
+}
+
+// ConfirmEmailChangeResponseDto is the outcome of following a confirmation link, Completed once the email changed
+type ConfirmEmailChangeResponseDto struct {
+	Completed bool            `json:"completed"`
+	Email     string          `json:"email,omitempty"`
+	Pending   *EmailChangeDto `json:"pending,omitempty"`
+}
+
+// SharedContactDto is a contact submitted to the address book shared with every user, with its review
+type SharedContactDto struct {
+	Contact     GetContactsResponseDto `json:"contact"`
+	Status      string                 `json:"status"`
+	SubmittedBy int                    `json:"submitted_by"`
+	SubmittedAt time.Time              `json:"submitted_at"`
+	ReviewedAt  *time.Time             `json:"reviewed_at,omitempty"`
+	ReviewNote  string                 `json:"review_note,omitempty"`
+	// ChangedAt is when the owner last changed the contact while it was shared
+	ChangedAt *time.Time `json:"changed_at,omitempty"`
+	// UpdatedSinceViewed marks the shared contacts changed by their owner since the current user last viewed them,
+	// for users with the in-app shared_contact_updated notification on
+	UpdatedSinceViewed bool `json:"updated_since_viewed,omitempty"`
+}
+
+// SharedContactListResponseDto is the body of the shared address book listings
+type SharedContactListResponseDto struct {
+	Items []SharedContactDto `json:"items"`
+}
+
+// ReviewSharedContactRequestDto approves or rejects a contact submitted to the shared address book, the note is
+// sent to the submitter
+type ReviewSharedContactRequestDto struct {
+	Note string `json:"note,omitempty" binding:"max=500"`
+}
+
+// SyncContactDto is a contact with its version, the base of the next changes of a sync client
+type SyncContactDto struct {
+	Contact GetContactsResponseDto `json:"contact"`
+	Version int64                  `json:"version"`
+}
+
+// SyncUpdateContactRequestDto are changes a sync client made to a contact at BaseVersion, possibly offline
+type SyncUpdateContactRequestDto struct {
+	BaseVersion int64                   `json:"base_version"`
+	Changes     UpdateContactRequestDto `json:"changes"`
+}
+
+// SyncFieldConflictDto is a field changed both by the client and on the server since the base version of the client,
+// the server value is kept
+type SyncFieldConflictDto struct {
+	Field       string      `json:"field"`
+	ServerValue interface{} `json:"server_value"`
+	ClientValue interface{} `json:"client_value"`
+}
+
+// SyncUpdateContactResponseDto is the contact once merged: the changed fields nobody else touched are applied, the
+// others are reported as conflicts
+type SyncUpdateContactResponseDto struct {
+	Contact   GetContactsResponseDto `json:"contact"`
+	Version   int64                  `json:"version"`
+	Applied   []string               `json:"applied"`
+	Conflicts []SyncFieldConflictDto `json:"conflicts"`
+}
+
+// SyncMutationDto is a change a sync client queued offline. Creates name the new contact with TempID, updates and
+// deletes target ContactID or the TempID of a create earlier in the batch
+type SyncMutationDto struct {
+	Op          string                   `json:"op" binding:"required,oneof=create update delete"`
+	TempID      string                   `json:"temp_id,omitempty" binding:"max=64"`
+	ContactID   int                      `json:"contact_id,omitempty"`
+	BaseVersion int64                    `json:"base_version,omitempty"`
+	Create      *CreateContactRequestDto `json:"create,omitempty"`
+	Update      *UpdateContactRequestDto `json:"update,omitempty"`
+}
+
+// SyncBatchRequestDto is the queue of a sync client, applied in order and all at once. A batch sent again with the
+// same BatchID returns the outcome of the first one instead of being applied again
+type SyncBatchRequestDto struct {
+	BatchID   string            `json:"batch_id,omitempty" binding:"max=64"`
+	Mutations []SyncMutationDto `json:"mutations" binding:"required,min=1,max=100,dive"`
+}
+
+// SyncMutationResultDto is the outcome of a mutation of a batch
+type SyncMutationResultDto struct {
+	Index     int                    `json:"index"`
+	Op        string                 `json:"op"`
+	Status    string                 `json:"status"`
+	TempID    string                 `json:"temp_id,omitempty"`
+	ContactID int                    `json:"contact_id,omitempty"`
+	Version   int64                  `json:"version,omitempty"`
+	Applied   []string               `json:"applied,omitempty"`
+	Conflicts []SyncFieldConflictDto `json:"conflicts,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+}
+
+// SyncBatchResponseDto is the outcome of a batch. Nothing is applied unless Committed, the failed mutation tells why.
+// IDMap maps the temp IDs of the created contacts to their IDs
+type SyncBatchResponseDto struct {
+	Committed bool                    `json:"committed"`
+	Replayed  bool                    `json:"replayed,omitempty"`
+	IDMap     map[string]int          `json:"id_map"`
+	Results   []SyncMutationResultDto `json:"results"`
+}
+
+// CreateEmbedTokenRequestDto creates a read-only token embedding a contact list in another page. The list is either a
+// group or a saved search, the contacts matching Query and Tag like the q and tag filters of the contact listing
+type CreateEmbedTokenRequestDto struct {
+	UserID  int    `json:"user_id" client:"-"`
+	Name    string `json:"name" binding:"required,max=50"`
+	GroupID *int   `json:"group_id,omitempty"`
+	Query   string `json:"q,omitempty" binding:"max=200"`
+	Tag     string `json:"tag,omitempty" binding:"max=50"`
+	// ExpiresAt ends the token, it never expires when left out
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// EmbedTokenDto represents an embed token, the token and the URL of the list are only returned when it is created
+type EmbedTokenDto struct {
+	ID          int        `json:"id"`
+	Name        string     `json:"name"`
+	Token       string     `json:"token,omitempty"`
+	URL         string     `json:"url,omitempty"`
+	TokenPrefix string     `json:"token_prefix"`
+	GroupID     *int       `json:"group_id,omitempty"`
+	Query       string     `json:"q,omitempty"`
+	Tag         string     `json:"tag,omitempty"`
+	Active      bool       `json:"active"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// EmbedTokenListResponseDto lists the embed tokens of a user
+type EmbedTokenListResponseDto struct {
+	Items []EmbedTokenDto `json:"items"`
+}
+
+// EmbedContactsRequestDto selects a page of the contact list of an embed token
+type EmbedContactsRequestDto struct {
+	Token    string `form:"token" json:"token" binding:"required"`
+	Page     int    `form:"page" json:"page"`
+	PageSize int    `form:"page_size" json:"page_size"`
+}
+
+// EmbedContactDto is a contact as shown by an embedded list, limited to how to reach it
+type EmbedContactDto struct {
+	FirstName      string             `json:"first_name"`
+	LastName       string             `json:"last_name"`
+	Email          string             `json:"email,omitempty"`
+	PhoneNumber    string             `json:"phone_number,omitempty"`
+	Company        string             `json:"company,omitempty"`
+	JobTitle       string             `json:"job_title,omitempty"`
+	SocialProfiles []SocialProfileDto `json:"social_profiles,omitempty"`
+}
+
+// EmbedContactListResponseDto is a page of the contact list of an embed token, Name is the name of the token
+type EmbedContactListResponseDto struct {
+	Name       string            `json:"name"`
+	Branding   BrandingDto       `json:"branding"`
+	Items      []EmbedContactDto `json:"items"`
+	TotalCount int               `json:"total_count"`
+	Page       int               `json:"page"`
+	PageSize   int               `json:"page_size"`
+	TotalPages int               `json:"total_pages"`
+}
+
+// DirectorySettingsDto configures the public team directory. When Enabled it is served at /directory/<Slug>, listing
+// the contacts of the shared address book or the cards of the members by Source with the Fields listed besides
+// their name
+type DirectorySettingsDto struct {
+	Enabled bool     `json:"enabled"`
+	Slug    string   `json:"slug"`
+	Title   string   `json:"title,omitempty" binding:"max=100"`
+	Source  string   `json:"source" binding:"required,oneof=shared members"`
+	Fields  []string `json:"fields"`
+}
+
+// SetDirectoryCardRequestDto picks the contact standing for the current user in the team directory, null takes the
+// user out of it
+type SetDirectoryCardRequestDto struct {
+	UserID    int  `json:"user_id" client:"-"`
+	ContactID *int `json:"contact_id"`
+}
+
+// DirectoryRequestDto selects a page of the team directory, Query keeps the entries whose name, or company when the
+// directory shows it, contains it
+type DirectoryRequestDto struct {
+	Slug     string `json:"slug" client:"-"`
+	Query    string `form:"q" json:"q,omitempty" binding:"max=100"`
+	Page     int    `form:"page" json:"page"`
+	PageSize int    `form:"page_size" json:"page_size"`
+}
+
+// DirectoryEntryDto is a contact of the team directory, only the fields the directory shows are set
+type DirectoryEntryDto struct {
+	FirstName   string `json:"first_name"`
+	LastName    string `json:"last_name"`
+	Email       string `json:"email,omitempty"`
+	PhoneNumber string `json:"phone_number,omitempty"`
+	Company     string `json:"company,omitempty"`
+	JobTitle    string `json:"job_title,omitempty"`
+	City        string `json:"city,omitempty"`
+	Region      string `json:"region,omitempty"`
+	CountryCode string `json:"country_code,omitempty"`
+	// SocialProfiles links to the profiles of the contact when the directory shows social_profiles
+	SocialProfiles []SocialProfileDto `json:"social_profiles,omitempty"`
+}
+
+// DirectoryResponseDto is a page of the team directory
+type DirectoryResponseDto struct {
+	Title      string              `json:"title,omitempty"`
+	Branding   BrandingDto         `json:"branding"`
+	Fields     []string            `json:"fields"`
+	Items      []DirectoryEntryDto `json:"items"`
+	TotalCount int                 `json:"total_count"`
+	Page       int                 `json:"page"`
+	PageSize   int                 `json:"page_size"`
+	TotalPages int                 `json:"total_pages"`
+}
+
+// BrandingDto is how the deployment presents itself on its public pages, empty fields are not configured
+type BrandingDto struct {
+	InstanceName string `json:"instance_name"`
+	SupportEmail string `json:"support_email,omitempty"`
+	LogoURL      string `json:"logo_url,omitempty"`
+}
+
+// StartMaintenanceJobRequestDto starts an admin maintenance operation in the background, Top is the number of most
+// active users warm-cache fills the caches of (100 when 0)
+type StartMaintenanceJobRequestDto struct {
+	Operation string `json:"operation" binding:"required,oneof=reindex-search normalize-phones recount warm-cache"`
+	Top       int    `json:"top,omitempty" binding:"min=0,max=10000"`
+}
+
+// MaintenanceResultDto is what a maintenance operation went through: the contacts or users it scanned, the ones
+// whose derived data was stale and rewritten, and the users whose caches were dropped or warmed
+type MaintenanceResultDto struct {
+	Operation string `json:"operation"`
+	Scanned   int    `json:"scanned"`
+	Updated   int    `json:"updated"`
+	Users     int    `json:"users"`
+}
+
+// MaintenanceJobDto is a maintenance operation run in the background by the replica answering, Result is set once it
+// succeeded and Error once it failed
+type MaintenanceJobDto struct {
+	ID         string                `json:"id"`
+	Operation  string                `json:"operation"`
+	Status     string                `json:"status"`
+	StartedAt  time.Time             `json:"started_at"`
+	FinishedAt *time.Time            `json:"finished_at,omitempty"`
+	Result     *MaintenanceResultDto `json:"result,omitempty"`
+	Error      string                `json:"error,omitempty"`
+}
+
+// MaintenanceJobListResponseDto lists the maintenance jobs of the replica answering, most recent first
+type MaintenanceJobListResponseDto struct {
+	Items []MaintenanceJobDto `json:"items"`
 }
@@ -0,0 +1,109 @@
+package enrichment
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/danizion/contact-app/internal/utils"
+)
+
+// ErrNoMatch is returned by providers that have no data for the query
+var ErrNoMatch = errors.New("no enrichment data found")
+
+// Query identifies the person to look up, providers use whichever identifiers they support
+type Query struct {
+	Email       string
+	PhoneNumber string
+	FullName    string
+}
+
+// Result holds the data a provider found for a query
+type Result struct {
+	Company        string            `json:"company"`
+	JobTitle       string            `json:"job_title"`
+	SocialProfiles map[string]string `json:"social_profiles"`
+}
+
+// Provider looks up public profile data (company, job title, social profiles) for a contact
+type Provider interface {
+	Name() string
+	Enrich(query Query) (*Result, error)
+}
+
+// HTTPProvider queries a Clearbit-style HTTP API: GET <url>?email=&phone=&name= answering a Result as JSON, 404 when nothing matched
+type HTTPProvider struct {
+	name   string
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+// Init creates the enrichment provider configured by the ENRICHMENT_URL, ENRICHMENT_API_KEY and ENRICHMENT_PROVIDER
+// environment variables, returns nil when enrichment is not configured
+func Init() Provider {
+	endpoint := utils.GetEnvOrDefault("ENRICHMENT_URL", "")
+	if endpoint == "" {
+		return nil
+	}
+	return NewHTTPProvider(utils.GetEnvOrDefault("ENRICHMENT_PROVIDER", "http"), endpoint, utils.GetEnvOrDefault("ENRICHMENT_API_KEY", ""))
+}
+
+// NewHTTPProvider creates a new instance of HTTPProvider
+func NewHTTPProvider(name, endpoint, apiKey string) *HTTPProvider {
+	return &HTTPProvider{
+		name:   name,
+		url:    endpoint,
+		apiKey: apiKey,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name returns the provider name recorded as provenance of its results
+func (p *HTTPProvider) Name() string {
+	return p.name
+}
+
+// Enrich queries the provider API
+func (p *HTTPProvider) Enrich(query Query) (*Result, error) {
+	params := url.Values{}
+	if query.Email != "" {
+		params.Set("email", query.Email)
+	}
+	if query.PhoneNumber != "" {
+		params.Set("phone", query.PhoneNumber)
+	}
+	if query.FullName != "" {
+		params.Set("name", query.FullName)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, p.url+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("enrichment request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNoMatch
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("enrichment provider returned %d", resp.StatusCode)
+	}
+
+	var result Result
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("invalid enrichment response: %w", err)
+	}
+	return &result, nil
+}
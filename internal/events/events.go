@@ -0,0 +1,86 @@
+// Package events is the internal event bus. Services publish what happened to the contacts of a user and the
+// subscribers react to it: the contacts cache is invalidated, webhooks are delivered and the changes feed wakes its
+// waiting clients. New consumers (search indexing, notifications) subscribe here instead of being called by services.
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/storage/redis"
+)
+
+// Event types, the audit log actions of the same changes
+const (
+	ContactCreated   = constants.AuditActionContactCreated
+	ContactUpdated   = constants.AuditActionContactUpdated
+	ContactDeleted   = constants.AuditActionContactDeleted
+	ContactRestored  = constants.AuditActionContactRestored
+	ContactPurged    = constants.AuditActionContactPurged
+	StageChanged     = constants.AuditActionStageChanged
+	SnapshotRestored = constants.AuditActionSnapshotRestored
+	AccountMerged    = constants.AuditActionUserMerged
+)
+
+// ContactEvents are the types of the events changing the contacts of a user
+var ContactEvents = []string{ContactCreated, ContactUpdated, ContactDeleted, ContactRestored, ContactPurged, StageChanged,
+	SnapshotRestored, AccountMerged}
+
+// Event is something that happened to the contacts of a user. ContactID is 0 for events about many contacts
+// (a snapshot restore, an account merged into this one), Details holds the same values as the audit log entry
+type Event struct {
+	Type       string                 `json:"type"`
+	UserID     int                    `json:"user_id"`
+	ContactID  int                    `json:"contact_id,omitempty"`
+	Details    map[string]interface{} `json:"details,omitempty"`
+	OccurredAt time.Time              `json:"occurred_at"`
+}
+
+// Handler reacts to an event. Handlers run in the goroutine of the publisher, before Publish returns, so they must
+// be quick and hand slow work (network calls) to a goroutine of their own
+type Handler func(Event)
+
+// Bus carries the events of the application
+type Bus interface {
+	// Publish hands an event to the subscribers of this process and to the watchers of its user on every replica
+	Publish(event Event)
+	// Subscribe calls handler for the events of these types (every event when none is given) published by this
+	// process, so each event is handled once across replicas
+	Subscribe(handler Handler, types ...string)
+	// Watch streams the events of a user published by any replica until ctx is done. Events are dropped when the
+	// watcher falls behind, watchers re-read their source of truth on wake up
+	Watch(ctx context.Context, userID int) (<-chan Event, error)
+}
+
+// bus is the bus of the application, in process until Init is called
+var bus Bus = NewLocal()
+
+// Init sets up the bus of the application: watchers are reached through Redis when a client is given so every
+// replica sees every event, the bus stays in process otherwise
+func Init(redisClient *redis.Redis) Bus {
+	if redisClient != nil {
+		bus = NewRedis(redisClient)
+	} else {
+		bus = NewLocal()
+	}
+	return bus
+}
+
+// Publish publishes an event on the bus of the application, OccurredAt defaults to now
+func Publish(event Event) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now().UTC()
+	}
+	bus.Publish(event)
+}
+
+// Subscribe subscribes handler to the events of these types on the bus of the application
+func Subscribe(handler Handler, types ...string) {
+	bus.Subscribe(handler, types...)
+}
+
+// Watch streams the events of a user from the bus of the application
+func Watch(ctx context.Context, userID int) (<-chan Event, error) {
+	return bus.Watch(ctx, userID)
+}
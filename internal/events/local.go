@@ -0,0 +1,103 @@
+package events
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// watchBuffer is the number of events a watcher may have pending before new ones are dropped
+const watchBuffer = 16
+
+type subscription struct {
+	handler Handler
+	types   map[string]bool
+}
+
+// Local is the in process bus, enough for a single replica
+type Local struct {
+	mu            sync.RWMutex
+	subscriptions []subscription
+	watchers      map[int]map[chan Event]bool
+}
+
+// NewLocal creates an in process bus
+func NewLocal() *Local {
+	return &Local{watchers: make(map[int]map[chan Event]bool)}
+}
+
+// Publish calls the subscribers of the event type and wakes the watchers of its user
+func (l *Local) Publish(event Event) {
+	l.dispatch(event)
+	l.notifyWatchers(event)
+}
+
+// Subscribe calls handler for the events of these types, every event when none is given
+func (l *Local) Subscribe(handler Handler, types ...string) {
+	sub := subscription{handler: handler}
+	if len(types) > 0 {
+		sub.types = make(map[string]bool, len(types))
+		for _, eventType := range types {
+			sub.types[eventType] = true
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.subscriptions = append(l.subscriptions, sub)
+}
+
+// Watch streams the events of a user published on this bus until ctx is done
+func (l *Local) Watch(ctx context.Context, userID int) (<-chan Event, error) {
+	events := make(chan Event, watchBuffer)
+	l.mu.Lock()
+	if l.watchers[userID] == nil {
+		l.watchers[userID] = make(map[chan Event]bool)
+	}
+	l.watchers[userID][events] = true
+	l.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.watchers[userID], events)
+		if len(l.watchers[userID]) == 0 {
+			delete(l.watchers, userID)
+		}
+	}()
+	return events, nil
+}
+
+// dispatch calls the subscribers of the event type, a panicking subscriber neither stops the others nor the publisher
+func (l *Local) dispatch(event Event) {
+	l.mu.RLock()
+	subscriptions := l.subscriptions
+	l.mu.RUnlock()
+
+	for _, sub := range subscriptions {
+		if sub.types != nil && !sub.types[event.Type] {
+			continue
+		}
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					slog.Error("Event subscriber panicked", "type", event.Type, "panic", r)
+				}
+			}()
+			sub.handler(event)
+		}()
+	}
+}
+
+// notifyWatchers hands the event to the watchers of its user without ever blocking the publisher
+func (l *Local) notifyWatchers(event Event) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	for events := range l.watchers[event.UserID] {
+		select {
+		case events <- event:
+		default:
+		}
+	}
+}
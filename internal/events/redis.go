@@ -0,0 +1,78 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/danizion/contact-app/internal/storage/redis"
+)
+
+// Redis is the bus of a deployment with several replicas: subscribers run in the publishing process as with Local,
+// watchers receive the events of their user through a Redis channel from every replica
+type Redis struct {
+	local *Local
+	redis *redis.Redis
+}
+
+// NewRedis creates a bus reaching the watchers through Redis
+func NewRedis(redisClient *redis.Redis) *Redis {
+	return &Redis{local: NewLocal(), redis: redisClient}
+}
+
+func userChannel(userID int) string {
+	return fmt.Sprintf("events:user:%d", userID)
+}
+
+// Publish calls the subscribers of this process and sends the event to the watchers of its user, a Redis failure
+// only delays the watchers until their timeout
+func (b *Redis) Publish(event Event) {
+	b.local.dispatch(event)
+
+	message, err := json.Marshal(event)
+	if err != nil {
+		slog.Error("Failed to encode event", "type", event.Type, "error", err)
+		return
+	}
+	if err := b.redis.Publish(userChannel(event.UserID), message); err != nil {
+		slog.Error("Failed to publish event", "type", event.Type, "userID", event.UserID, "error", err)
+	}
+}
+
+// Subscribe calls handler for the events of these types published by this process
+func (b *Redis) Subscribe(handler Handler, types ...string) {
+	b.local.Subscribe(handler, types...)
+}
+
+// Watch streams the events of a user published by any replica until ctx is done
+func (b *Redis) Watch(ctx context.Context, userID int) (<-chan Event, error) {
+	messages, err := b.redis.Subscribe(ctx, userChannel(userID), watchBuffer)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan Event, watchBuffer)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case message, ok := <-messages:
+				if !ok {
+					return
+				}
+				var event Event
+				if err := json.Unmarshal([]byte(message), &event); err != nil {
+					slog.Error("Failed to decode event", "error", err)
+					continue
+				}
+				select {
+				case events <- event:
+				default:
+				}
+			}
+		}
+	}()
+	return events, nil
+}
@@ -0,0 +1,54 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// flushEvery is the number of rows buffered before they are pushed to the client
+const flushEvery = 100
+
+// flusher is implemented by writers able to push buffered data to the client, such as gin's ResponseWriter
+type flusher interface {
+	Flush()
+}
+
+// CSVWriter writes CSV rows to a response as they are produced instead of building the whole file in memory
+type CSVWriter struct {
+	out     io.Writer
+	writer  *csv.Writer
+	pending int
+}
+
+// NewCSVWriter creates a new instance of CSVWriter writing to out
+func NewCSVWriter(out io.Writer) *CSVWriter {
+	return &CSVWriter{
+		out:    out,
+		writer: csv.NewWriter(out),
+	}
+}
+
+// Write writes one row, flushing to the client every flushEvery rows
+func (w *CSVWriter) Write(row []string) error {
+	if err := w.writer.Write(row); err != nil {
+		return err
+	}
+	w.pending++
+	if w.pending >= flushEvery {
+		return w.Flush()
+	}
+	return nil
+}
+
+// Flush pushes the buffered rows to the client
+func (w *CSVWriter) Flush() error {
+	w.writer.Flush()
+	if err := w.writer.Error(); err != nil {
+		return err
+	}
+	if f, ok := w.out.(flusher); ok {
+		f.Flush()
+	}
+	w.pending = 0
+	return nil
+}
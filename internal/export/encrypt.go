@@ -0,0 +1,78 @@
+package export
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/danizion/contact-app/internal/constants"
+)
+
+// encryptionConfig encrypts with AES-256, the key derived from the password with a salted and iterated SHA-256
+var encryptionConfig = &packet.Config{
+	DefaultCipher: packet.CipherAES256,
+	DefaultHash:   crypto.SHA256,
+	S2KCount:      constants.ExportPasswordS2KCount,
+}
+
+// EncryptWriter encrypts what is written to it as it is written
+type EncryptWriter struct {
+	out       io.Writer
+	plaintext io.WriteCloser
+}
+
+// Encrypt returns a writer encrypting what is written to it into out as an OpenPGP message protected by password,
+// which gpg --decrypt opens. The data is never held as a whole, Close must be called to finish the message
+func Encrypt(out io.Writer, password string) (*EncryptWriter, error) {
+	plaintext, err := openpgp.SymmetricallyEncrypt(out, []byte(password), &openpgp.FileHints{IsBinary: true}, encryptionConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &EncryptWriter{out: out, plaintext: plaintext}, nil
+}
+
+// Write encrypts p into the output
+func (w *EncryptWriter) Write(p []byte) (int, error) {
+	return w.plaintext.Write(p)
+}
+
+// Flush pushes the data encrypted so far to the client, the CSV and vCard writers flush through it
+func (w *EncryptWriter) Flush() {
+	if f, ok := w.out.(flusher); ok {
+		f.Flush()
+	}
+}
+
+// Close finishes the message, it does not close the output
+func (w *EncryptWriter) Close() error {
+	return w.plaintext.Close()
+}
+
+// ErrWrongPassword is returned by Decrypt when the password does not decrypt the message
+var ErrWrongPassword = errors.New(constants.ErrWrongExportPassword)
+
+// Decrypt returns the content of a message written by Encrypt (or gpg --symmetric) with password, decrypted as it
+// is read. The integrity of the content is checked at its end, reading the last bytes fails when it was altered
+func Decrypt(in io.Reader, password string) (io.Reader, error) {
+	prompted := false
+	prompt := func(keys []openpgp.Key, symmetric bool) ([]byte, error) {
+		// The prompt is called again when the password did not decrypt the message
+		if prompted || !symmetric {
+			return nil, ErrWrongPassword
+		}
+		prompted = true
+		return []byte(password), nil
+	}
+
+	message, err := openpgp.ReadMessage(in, openpgp.EntityList{}, prompt, encryptionConfig)
+	if err != nil {
+		if errors.Is(err, ErrWrongPassword) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s: %w", constants.ErrInvalidEncryptedExport, err)
+	}
+	return message.UnverifiedBody, nil
+}
@@ -0,0 +1,141 @@
+package export
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// maxVCardLineBytes is the line length vCard lines are folded at
+const maxVCardLineBytes = 75
+
+// VCard is the content of a vCard, empty fields are left out
+type VCard struct {
+	FirstName   string
+	LastName    string
+	PhoneNumber string
+	Email       string
+	Company     string
+	JobTitle    string
+	// Address is used when the structured address is empty
+	Address     string
+	Street      string
+	City        string
+	Region      string
+	PostalCode  string
+	CountryCode string
+	Timezone    string
+	Latitude    *float64
+	Longitude   *float64
+	// SocialProfiles are written as X-SOCIALPROFILE properties typed by their network
+	SocialProfiles []SocialProfile
+}
+
+// SocialProfile is the profile of a contact on a social network
+type SocialProfile struct {
+	Network string
+	URL     string
+}
+
+// VCardWriter writes vCard 3.0 cards to a response as they are produced instead of building the whole file in memory
+type VCardWriter struct {
+	out    io.Writer
+	writer *bufio.Writer
+	// product names the application producing the cards in their PRODID, left out when empty
+	product string
+	pending int
+}
+
+// NewVCardWriter creates a new instance of VCardWriter writing to out the cards of product
+func NewVCardWriter(out io.Writer, product string) *VCardWriter {
+	return &VCardWriter{
+		out:     out,
+		writer:  bufio.NewWriter(out),
+		product: product,
+	}
+}
+
+// Write writes one card, flushing to the client every flushEvery cards
+func (w *VCardWriter) Write(card VCard) error {
+	w.line("BEGIN:VCARD")
+	w.line("VERSION:3.0")
+	if w.product != "" {
+		w.line("PRODID:-//" + escapeVCard(w.product) + "//EN")
+	}
+	w.line("N:" + escapeVCard(card.LastName) + ";" + escapeVCard(card.FirstName) + ";;;")
+	w.line("FN:" + escapeVCard(strings.TrimSpace(card.FirstName+" "+card.LastName)))
+	if card.PhoneNumber != "" {
+		w.line("TEL;TYPE=CELL:" + escapeVCard(card.PhoneNumber))
+	}
+	if card.Email != "" {
+		w.line("EMAIL:" + escapeVCard(card.Email))
+	}
+	if card.Company != "" {
+		w.line("ORG:" + escapeVCard(card.Company))
+	}
+	if card.JobTitle != "" {
+		w.line("TITLE:" + escapeVCard(card.JobTitle))
+	}
+	switch {
+	case card.Street != "" || card.City != "" || card.PostalCode != "" || card.CountryCode != "":
+		w.line("ADR:;;" + escapeVCard(card.Street) + ";" + escapeVCard(card.City) + ";" + escapeVCard(card.Region) + ";" +
+			escapeVCard(card.PostalCode) + ";" + escapeVCard(card.CountryCode))
+	case card.Address != "":
+		w.line("ADR:;;" + escapeVCard(card.Address) + ";;;;")
+	}
+	if card.Timezone != "" {
+		w.line("TZ:" + escapeVCard(card.Timezone))
+	}
+	if card.Latitude != nil && card.Longitude != nil {
+		w.line("GEO:" + strconv.FormatFloat(*card.Latitude, 'f', -1, 64) + ";" + strconv.FormatFloat(*card.Longitude, 'f', -1, 64))
+	}
+	for _, profile := range card.SocialProfiles {
+		w.line("X-SOCIALPROFILE;TYPE=" + profile.Network + ":" + escapeVCard(profile.URL))
+	}
+	if err := w.line("END:VCARD"); err != nil {
+		return err
+	}
+
+	w.pending++
+	if w.pending >= flushEvery {
+		return w.Flush()
+	}
+	return nil
+}
+
+// Flush pushes the buffered cards to the client
+func (w *VCardWriter) Flush() error {
+	if err := w.writer.Flush(); err != nil {
+		return err
+	}
+	if f, ok := w.out.(flusher); ok {
+		f.Flush()
+	}
+	w.pending = 0
+	return nil
+}
+
+// line writes a content line folded at maxVCardLineBytes, without splitting UTF-8 sequences. Write errors are
+// sticky in the bufio.Writer, so checking the last line of a card is enough
+func (w *VCardWriter) line(content string) error {
+	// Continuation lines start with a space, which counts towards their length
+	limit := maxVCardLineBytes
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && content[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.writer.WriteString(content[:cut])
+		w.writer.WriteString("\r\n ")
+		content = content[cut:]
+		limit = maxVCardLineBytes - 1
+	}
+	_, err := w.writer.WriteString(content + "\r\n")
+	return err
+}
+
+// escapeVCard escapes the characters with a meaning in vCard values
+func escapeVCard(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\r\n", `\n`, "\n", `\n`).Replace(value)
+}
@@ -0,0 +1,82 @@
+package geocode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/danizion/contact-app/internal/utils"
+)
+
+// ErrNoMatch is returned when the geocoder could not locate an address
+var ErrNoMatch = errors.New("address could not be geocoded")
+
+// Location is a point in WGS84 coordinates
+type Location struct {
+	Latitude  float64 `json:"lat"`
+	Longitude float64 `json:"lng"`
+}
+
+// Provider resolves a postal address to coordinates
+type Provider interface {
+	Geocode(address string) (*Location, error)
+}
+
+// HTTPProvider queries an HTTP geocoding API: GET <url>?q=<address> answering a Location as JSON, 404 when nothing matched
+type HTTPProvider struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+// Init creates the geocoding provider configured by the GEOCODER_URL and GEOCODER_API_KEY environment variables,
+// returns nil when geocoding is not configured
+func Init() Provider {
+	endpoint := utils.GetEnvOrDefault("GEOCODER_URL", "")
+	if endpoint == "" {
+		return nil
+	}
+	return NewHTTPProvider(endpoint, utils.GetEnvOrDefault("GEOCODER_API_KEY", ""))
+}
+
+// NewHTTPProvider creates a new instance of HTTPProvider
+func NewHTTPProvider(endpoint, apiKey string) *HTTPProvider {
+	return &HTTPProvider{
+		url:    endpoint,
+		apiKey: apiKey,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Geocode queries the geocoding API
+func (p *HTTPProvider) Geocode(address string) (*Location, error) {
+	req, err := http.NewRequest(http.MethodGet, p.url+"?"+url.Values{"q": {address}}.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("geocoding request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNoMatch
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("geocoder returned %d", resp.StatusCode)
+	}
+
+	var location Location
+	if err := json.NewDecoder(resp.Body).Decode(&location); err != nil {
+		return nil, fmt.Errorf("invalid geocoder response: %w", err)
+	}
+	return &location, nil
+}
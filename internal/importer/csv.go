@@ -0,0 +1,168 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/social"
+)
+
+// csvColumns maps the normalized headers of the CSV files of this app, Google Contacts and Outlook to the contact
+// fields. Headers are normalized to lower case words joined by underscores ("E-mail 1 - Value" is e_mail_1_value)
+var csvColumns = map[string]string{
+	"first_name": "first_name", "given_name": "first_name", "first": "first_name",
+	"last_name": "last_name", "family_name": "last_name", "surname": "last_name", "last": "last_name",
+	"name": "name", "full_name": "name", "display_name": "name",
+	"phone_number": "phone_number", "phone": "phone_number", "mobile_phone": "phone_number", "phone_1_value": "phone_number",
+	"primary_phone": "phone_number", "business_phone": "phone_number", "home_phone": "phone_number",
+	"email": "email", "e_mail": "email", "email_address": "email", "e_mail_address": "email", "e_mail_1_value": "email",
+	"company": "company", "organization": "company", "organization_name": "company", "organization_1_name": "company",
+	"job_title": "job_title", "title": "job_title", "organization_title": "job_title", "organization_1_title": "job_title",
+	"address": "address", "address_1_formatted": "address",
+	"street": "street", "address_1_street": "street", "business_street": "street", "home_street": "street",
+	"city": "city", "address_1_city": "city", "business_city": "city", "home_city": "city",
+	"region": "region", "state": "region", "address_1_region": "region", "business_state": "region", "home_state": "region",
+	"postal_code": "postal_code", "zip": "postal_code", "zip_code": "postal_code", "address_1_postal_code": "postal_code",
+	"business_postal_code": "postal_code", "home_postal_code": "postal_code",
+	"country_code": "country_code", "address_1_country": "country", "country": "country",
+	"timezone": "timezone", "latitude": "latitude", "longitude": "longitude", "source": "source", "stage": "stage",
+}
+
+func init() {
+	// The social_<network> columns of the export hold profile URLs
+	for _, network := range social.Networks() {
+		csvColumns[constants.SocialCSVColumnPrefix+network] = constants.SocialCSVColumnPrefix + network
+	}
+}
+
+// multiValueSeparator separates the values of a cell in Google Contacts exports, only the first one is imported
+const multiValueSeparator = " ::: "
+
+type csvReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%s: the file is empty", constants.ErrInvalidContactFile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", constants.ErrInvalidContactFile, err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		if i == 0 {
+			// Spreadsheets save UTF-8 files with a byte order mark
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		if field, ok := csvColumns[normalizeHeader(name)]; ok {
+			if _, taken := columns[field]; !taken {
+				columns[field] = i
+			}
+		}
+	}
+	_, hasFirst := columns["first_name"]
+	_, hasLast := columns["last_name"]
+	_, hasName := columns["name"]
+	if !hasName && !(hasFirst && hasLast) {
+		return nil, errors.New(constants.ErrContactFileMissingName)
+	}
+	return &csvReader{reader: reader, columns: columns}, nil
+}
+
+func (r *csvReader) Next() (Row, error) {
+	record, err := r.reader.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return Row{Line: parseErr.StartLine, Err: parseErr.Err}, nil
+		}
+		return Row{}, err
+	}
+	line, _ := r.reader.FieldPos(0)
+
+	value := func(field string) string {
+		i, ok := r.columns[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		first, _, _ := strings.Cut(record[i], multiValueSeparator)
+		return strings.TrimSpace(first)
+	}
+
+	row := Row{Line: line}
+	contact := &row.Contact
+	contact.FirstName, contact.LastName = value("first_name"), value("last_name")
+	if contact.FirstName == "" && contact.LastName == "" {
+		contact.FirstName, contact.LastName = splitName(value("name"))
+	}
+	contact.PhoneNumber = value("phone_number")
+	contact.Email = value("email")
+	contact.Company = value("company")
+	contact.JobTitle = value("job_title")
+	contact.Timezone = value("timezone")
+	contact.Source = value("source")
+	contact.Stage = value("stage")
+	contact.Address = value("address")
+	contact.Street, contact.City, contact.Region, contact.PostalCode = value("street"), value("city"), value("region"), value("postal_code")
+	contact.CountryCode = value("country_code")
+
+	country := value("country")
+	if contact.CountryCode == "" && isCountryCode(country) {
+		contact.CountryCode, country = country, ""
+	}
+	// A structured address needs a country code, without one the address is kept as free text
+	if contact.CountryCode == "" {
+		if contact.Address == "" {
+			contact.Address = joinNonEmpty(", ", contact.Street, contact.City, contact.Region, contact.PostalCode, country)
+		}
+		contact.Street, contact.City, contact.Region, contact.PostalCode = "", "", "", ""
+	}
+
+	for _, network := range social.Networks() {
+		if profile := value(constants.SocialCSVColumnPrefix + network); profile != "" {
+			contact.SocialProfiles = append(contact.SocialProfiles, dtos.SocialProfileDto{Network: network, Handle: profile})
+		}
+	}
+
+	if contact.Latitude, err = parseCoordinate(value("latitude")); err != nil {
+		row.Err = fmt.Errorf("invalid latitude %q", value("latitude"))
+	} else if contact.Longitude, err = parseCoordinate(value("longitude")); err != nil {
+		row.Err = fmt.Errorf("invalid longitude %q", value("longitude"))
+	}
+	return row, nil
+}
+
+// normalizeHeader lower cases a header and joins its words with underscores
+func normalizeHeader(header string) string {
+	words := strings.FieldsFunc(strings.ToLower(header), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, "_")
+}
+
+func parseCoordinate(value string) (*float64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	coordinate, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &coordinate, nil
+}
@@ -0,0 +1,85 @@
+// Package importer reads contacts from the files other address books export, CSV (this app's own export, Google
+// Contacts, Outlook) and vCard (.vcf, phones, Apple and Google Contacts). Files are read one contact at a time so
+// large files are never held in memory, and a malformed contact is reported on its row without stopping the file
+package importer
+
+import (
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+)
+
+// Row is a contact read from a file, Line is where it starts in the file. Err is set when the row could not be
+// read, Contact then holds what could be read of it (the name, to report the row)
+type Row struct {
+	Line    int
+	Contact dtos.ArchiveContactDto
+	Err     error
+}
+
+// Reader reads the contacts of a file
+type Reader interface {
+	// Next returns the next row, io.EOF once the file is read. Any other error means the file cannot be read further
+	Next() (Row, error)
+}
+
+// NewReader returns the reader of a file in format (constants.ContactFileFormatCSV or ContactFileFormatVCard)
+func NewReader(format string, r io.Reader) (Reader, error) {
+	switch format {
+	case constants.ContactFileFormatCSV:
+		return newCSVReader(r)
+	case constants.ContactFileFormatVCard:
+		return newVCardReader(r), nil
+	default:
+		return nil, errors.New(constants.ErrUnsupportedContactFileFormat)
+	}
+}
+
+// DetectFormat returns the format of a file from its name, empty when the extension is not recognized
+func DetectFormat(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return constants.ContactFileFormatCSV
+	case ".vcf", ".vcard":
+		return constants.ContactFileFormatVCard
+	default:
+		return ""
+	}
+}
+
+// splitName splits a display name into first and last name on its last space
+func splitName(name string) (string, string) {
+	name = strings.TrimSpace(name)
+	if i := strings.LastIndex(name, " "); i > 0 {
+		return strings.TrimSpace(name[:i]), name[i+1:]
+	}
+	return name, ""
+}
+
+// joinNonEmpty joins the non empty parts with sep
+func joinNonEmpty(sep string, parts ...string) string {
+	var kept []string
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			kept = append(kept, part)
+		}
+	}
+	return strings.Join(kept, sep)
+}
+
+// isCountryCode reports whether value looks like an ISO 3166-1 alpha-2 code, address books often hold country names
+func isCountryCode(value string) bool {
+	if len(value) != 2 {
+		return false
+	}
+	for _, r := range strings.ToUpper(value) {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,311 @@
+package importer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"mime/quotedprintable"
+	"strconv"
+	"strings"
+
+	"github.com/danizion/contact-app/internal/dtos"
+)
+
+// vCardProperties are the properties read from a vCard, the others (photos in particular) are skipped unread
+var vCardProperties = map[string]bool{
+	"BEGIN": true, "END": true, "N": true, "FN": true, "TEL": true, "EMAIL": true, "ORG": true, "TITLE": true,
+	"ADR": true, "TZ": true, "GEO": true, "X-SOCIALPROFILE": true,
+}
+
+// maxVCardLineBytes bounds a property once unfolded, longer ones are skipped
+const maxVCardLineBytes = 64 << 10
+
+type vCardProperty struct {
+	name   string
+	params map[string][]string
+	value  string
+}
+
+type vCardReader struct {
+	reader *bufio.Reader
+	line   int
+	// pending is a physical line read ahead to find the end of a folded property
+	pending *string
+}
+
+func newVCardReader(r io.Reader) *vCardReader {
+	return &vCardReader{reader: bufio.NewReader(r)}
+}
+
+func (r *vCardReader) Next() (Row, error) {
+	var row *Row
+	for {
+		property, line, err := r.readProperty()
+		if err == io.EOF {
+			if row != nil {
+				row.Err = errors.New("vCard not terminated by END:VCARD")
+				return *row, nil
+			}
+			return Row{}, io.EOF
+		}
+		if err != nil {
+			return Row{}, err
+		}
+
+		switch {
+		case property.name == "BEGIN" && strings.EqualFold(property.value, "VCARD"):
+			if row != nil {
+				return Row{Line: row.Line, Contact: row.Contact, Err: errors.New("vCard not terminated by END:VCARD")}, nil
+			}
+			row = &Row{Line: line}
+		case row == nil:
+			// Outside of a card
+		case property.name == "END" && strings.EqualFold(property.value, "VCARD"):
+			if row.Contact.FirstName == "" && row.Contact.LastName == "" && row.Err == nil {
+				row.Err = errors.New("vCard has no name")
+			}
+			return *row, nil
+		case row.Err == nil:
+			if err := applyVCardProperty(row, property); err != nil {
+				row.Err = err
+			}
+		}
+	}
+}
+
+// applyVCardProperty sets the field of the contact a property holds, only the first phone, email and address are kept
+func applyVCardProperty(row *Row, property vCardProperty) error {
+	contact := &row.Contact
+	switch property.name {
+	case "N":
+		parts := splitVCardValue(property.value, ';')
+		contact.LastName = strings.TrimSpace(parts[0])
+		if len(parts) > 1 {
+			contact.FirstName = strings.TrimSpace(parts[1])
+		}
+	case "FN":
+		if contact.FirstName == "" && contact.LastName == "" {
+			contact.FirstName, contact.LastName = splitName(unescapeVCard(property.value))
+		}
+	case "TEL":
+		if contact.PhoneNumber == "" {
+			contact.PhoneNumber = strings.TrimPrefix(strings.TrimSpace(unescapeVCard(property.value)), "tel:")
+		}
+	case "EMAIL":
+		if contact.Email == "" {
+			contact.Email = strings.TrimSpace(unescapeVCard(property.value))
+		}
+	case "ORG":
+		contact.Company = strings.TrimSpace(splitVCardValue(property.value, ';')[0])
+	case "TITLE":
+		contact.JobTitle = strings.TrimSpace(unescapeVCard(property.value))
+	case "ADR":
+		if contact.Address != "" || contact.Street != "" {
+			return nil
+		}
+		// post office box; extended address; street; locality; region; postal code; country
+		parts := append(splitVCardValue(property.value, ';'), make([]string, 7)...)
+		street := joinNonEmpty(", ", parts[2], parts[1])
+		if country := strings.TrimSpace(parts[6]); isCountryCode(country) {
+			contact.Street, contact.City, contact.Region, contact.PostalCode, contact.CountryCode = street, parts[3], parts[4], parts[5], country
+		} else {
+			contact.Address = joinNonEmpty(", ", street, parts[3], parts[4], parts[5], country)
+		}
+	case "TZ":
+		// Only IANA names are kept, UTC offsets do not say which rules apply
+		if tz := strings.TrimSpace(unescapeVCard(property.value)); strings.Contains(tz, "/") {
+			contact.Timezone = tz
+		}
+	case "GEO":
+		// geo:lat,lon in vCard 4, lat;lon in vCard 3
+		value := strings.TrimPrefix(strings.TrimSpace(property.value), "geo:")
+		lat, lon, ok := strings.Cut(value, ",")
+		if !ok {
+			lat, lon, ok = strings.Cut(value, ";")
+		}
+		latitude, latErr := strconv.ParseFloat(strings.TrimSpace(lat), 64)
+		longitude, lonErr := strconv.ParseFloat(strings.TrimSpace(lon), 64)
+		if !ok || latErr != nil || lonErr != nil {
+			return fmt.Errorf("invalid GEO %q", property.value)
+		}
+		contact.Latitude, contact.Longitude = &latitude, &longitude
+	case "X-SOCIALPROFILE":
+		if network := property.params["TYPE"]; len(network) > 0 {
+			contact.SocialProfiles = append(contact.SocialProfiles,
+				dtos.SocialProfileDto{Network: strings.ToLower(network[0]), Handle: strings.TrimSpace(unescapeVCard(property.value))})
+		}
+	}
+	return nil
+}
+
+// readProperty reads the next property, unfolding it, and returns the line it starts on
+func (r *vCardReader) readProperty() (vCardProperty, int, error) {
+	for {
+		first, err := r.readLine()
+		if err != nil {
+			return vCardProperty{}, 0, err
+		}
+		start := r.line
+		if strings.TrimSpace(first) == "" {
+			continue
+		}
+
+		name, rest, ok := cutPropertyName(first)
+		if !ok {
+			continue
+		}
+		keep := vCardProperties[name]
+		quotedPrintable := strings.Contains(strings.ToUpper(rest), "QUOTED-PRINTABLE")
+
+		var b strings.Builder
+		if keep {
+			b.WriteString(rest)
+		}
+		for {
+			next, err := r.readLine()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return vCardProperty{}, 0, err
+			}
+			switch {
+			case next != "" && (next[0] == ' ' || next[0] == '\t'):
+				// Folded line
+				next = next[1:]
+			case quotedPrintable && strings.HasSuffix(b.String(), "="):
+				// vCard 2.1 soft line break, kept for the quoted-printable decoder
+				next = "\n" + next
+			default:
+				r.pending = &next
+				r.line--
+			}
+			if r.pending != nil {
+				break
+			}
+			if keep && b.Len()+len(next) <= maxVCardLineBytes {
+				b.WriteString(next)
+			}
+		}
+		if !keep {
+			continue
+		}
+
+		property, err := parseVCardProperty(name, b.String())
+		if err != nil {
+			return vCardProperty{}, 0, err
+		}
+		return property, start, nil
+	}
+}
+
+func (r *vCardReader) readLine() (string, error) {
+	if r.pending != nil {
+		line := *r.pending
+		r.pending = nil
+		r.line++
+		return line, nil
+	}
+	line, err := r.reader.ReadString('\n')
+	if err == io.EOF && line == "" {
+		return "", io.EOF
+	}
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	r.line++
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// cutPropertyName splits "item1.TEL;TYPE=CELL:+1..." into TEL and ";TYPE=CELL:+1..."
+func cutPropertyName(line string) (string, string, bool) {
+	end := strings.IndexAny(line, ";:")
+	if end < 0 {
+		return "", "", false
+	}
+	name := strings.ToUpper(strings.TrimSpace(line[:end]))
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	return name, line[end:], true
+}
+
+// parseVCardProperty parses the parameters and value of a property, rest starts with ";" or ":"
+func parseVCardProperty(name, rest string) (vCardProperty, error) {
+	property := vCardProperty{name: name, params: make(map[string][]string)}
+	colon := strings.Index(rest, ":")
+	if colon < 0 {
+		return property, nil
+	}
+	property.value = rest[colon+1:]
+
+	var encoding string
+	for _, param := range strings.Split(rest[:colon], ";")[1:] {
+		key, value, ok := strings.Cut(param, "=")
+		if !ok {
+			// vCard 2.1 bare parameters, TEL;CELL or ADR;QUOTED-PRINTABLE
+			key, value = "TYPE", param
+			if strings.EqualFold(param, "QUOTED-PRINTABLE") {
+				key = "ENCODING"
+			}
+		}
+		key = strings.ToUpper(strings.TrimSpace(key))
+		for _, v := range strings.Split(value, ",") {
+			property.params[key] = append(property.params[key], strings.Trim(v, `"`))
+		}
+		if key == "ENCODING" {
+			encoding = strings.ToUpper(value)
+		}
+	}
+
+	if encoding == "QUOTED-PRINTABLE" {
+		decoded, err := io.ReadAll(quotedprintable.NewReader(strings.NewReader(property.value)))
+		if err != nil {
+			return property, fmt.Errorf("invalid quoted-printable %s value: %w", name, err)
+		}
+		property.value = string(decoded)
+	}
+	return property, nil
+}
+
+// splitVCardValue splits a structured value on its unescaped separators and unescapes the parts
+func splitVCardValue(value string, sep byte) []string {
+	var parts []string
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] == '\\' && i+1 < len(value):
+			b.WriteByte('\\')
+			b.WriteByte(value[i+1])
+			i++
+		case value[i] == sep:
+			parts = append(parts, unescapeVCard(b.String()))
+			b.Reset()
+		default:
+			b.WriteByte(value[i])
+		}
+	}
+	return append(parts, unescapeVCard(b.String()))
+}
+
+// unescapeVCard decodes the \n, \, \; and \\ escapes of a value, new lines become spaces
+func unescapeVCard(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) {
+			i++
+			if value[i] == 'n' || value[i] == 'N' {
+				b.WriteByte(' ')
+			} else {
+				b.WriteByte(value[i])
+			}
+			continue
+		}
+		b.WriteByte(value[i])
+	}
+	return b.String()
+}
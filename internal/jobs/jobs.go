@@ -0,0 +1,23 @@
+package jobs
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Every runs fn in the background every interval until the process exits, failures are logged and retried on the next run
+func Every(name string, interval time.Duration, fn func() error) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			start := time.Now()
+			if err := fn(); err != nil {
+				slog.Error("Scheduled job failed", "job", name, "error", err)
+				continue
+			}
+			slog.Info("Scheduled job finished", "job", name, "duration", time.Since(start))
+		}
+	}()
+}
@@ -0,0 +1,108 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"mime"
+	"net/smtp"
+	"strings"
+	"text/template"
+
+	"github.com/danizion/contact-app/internal/branding"
+	"github.com/danizion/contact-app/internal/utils"
+)
+
+//go:embed templates/*.tmpl
+var templateFiles embed.FS
+
+// templates can read the branding of the deployment with {{brand.InstanceName}}
+var templates = template.Must(template.New("mail").Funcs(template.FuncMap{"brand": branding.Load}).
+	ParseFS(templateFiles, "templates/*.tmpl"))
+
+// Message is a plain text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers emails
+type Sender interface {
+	Send(msg Message) error
+}
+
+// SMTPSender delivers emails through an SMTP relay
+type SMTPSender struct {
+	addr string
+	from string
+	// fromName is the display name of the sender, the instance name
+	fromName string
+	auth     smtp.Auth
+}
+
+// Init creates the sender configured by the SMTP_HOST, SMTP_PORT, SMTP_USERNAME, SMTP_PASSWORD and MAIL_FROM
+// environment variables, returns nil when email is not configured. Emails are sent in the name of the instance
+func Init() Sender {
+	host := utils.GetEnvOrDefault("SMTP_HOST", "")
+	if host == "" {
+		return nil
+	}
+	return NewSMTPSender(host, utils.GetEnvOrDefault("SMTP_PORT", "587"), utils.GetEnvOrDefault("SMTP_USERNAME", ""),
+		utils.GetEnvOrDefault("SMTP_PASSWORD", ""), utils.GetEnvOrDefault("MAIL_FROM", "contacts@localhost"), branding.Load().InstanceName)
+}
+
+// NewSMTPSender creates a new instance of SMTPSender, authentication is skipped when no username is given and the
+// sender has no display name when fromName is empty
+func NewSMTPSender(host, port, username, password, from, fromName string) *SMTPSender {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &SMTPSender{
+		addr:     host + ":" + port,
+		from:     from,
+		fromName: fromName,
+		auth:     auth,
+	}
+}
+
+// Send delivers a message
+func (s *SMTPSender) Send(msg Message) error {
+	var body strings.Builder
+	if s.fromName != "" {
+		fmt.Fprintf(&body, "From: %s <%s>\r\n", mime.QEncoding.Encode("UTF-8", s.fromName), s.from)
+	} else {
+		fmt.Fprintf(&body, "From: %s\r\n", s.from)
+	}
+	fmt.Fprintf(&body, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&body, "Subject: %s\r\n", msg.Subject)
+	body.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n")
+	body.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+	if err := smtp.SendMail(s.addr, s.auth, s.from, []string{msg.To}, []byte(body.String())); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// Render builds a message from an embedded template, each template file defines a "<name>_subject" and a "<name>_body"
+// template. Bodies end with the footer of the deployment branding
+func Render(name, to string, data interface{}) (Message, error) {
+	var subject, body bytes.Buffer
+	if err := templates.ExecuteTemplate(&subject, name+"_subject", data); err != nil {
+		return Message{}, fmt.Errorf("failed to render %s subject: %w", name, err)
+	}
+	if err := templates.ExecuteTemplate(&body, name+"_body", data); err != nil {
+		return Message{}, fmt.Errorf("failed to render %s body: %w", name, err)
+	}
+	if err := templates.ExecuteTemplate(&body, "footer", branding.Load()); err != nil {
+		return Message{}, fmt.Errorf("failed to render %s footer: %w", name, err)
+	}
+
+	return Message{
+		To:      to,
+		Subject: strings.TrimSpace(subject.String()),
+		Body:    body.String(),
+	}, nil
+}
@@ -0,0 +1,71 @@
+// Package metrics holds the application counters, published through expvar and served as JSON by GET /admin/metrics
+package metrics
+
+import (
+	"expvar"
+	"net/http"
+	"runtime"
+)
+
+// Counter counts events by label, for example authentication failures by reason
+type Counter struct {
+	values *expvar.Map
+}
+
+// NewCounter creates and publishes a counter, name must be unique across the application
+func NewCounter(name string) *Counter {
+	return &Counter{values: expvar.NewMap(name)}
+}
+
+// Inc adds one event with label
+func (c *Counter) Inc(label string) {
+	c.values.Add(label, 1)
+}
+
+// Add adds delta events with label
+func (c *Counter) Add(label string, delta int64) {
+	c.values.Add(label, delta)
+}
+
+// Value returns the number of events with label
+func (c *Counter) Value(label string) int64 {
+	if value, ok := c.values.Get(label).(*expvar.Int); ok {
+		return value.Value()
+	}
+	return 0
+}
+
+// Application counters
+var (
+	// AuthFailures counts rejected authentications by reason (expired, invalid_issuer, ...)
+	AuthFailures = NewCounter("auth_failures")
+	// CacheLookups counts the lookups of the contacts cache by outcome (hit, miss, error)
+	CacheLookups = NewCounter("cache_lookups")
+	// RepositoryCalls counts the database calls of the repository by method, RepositoryErrors the failed ones,
+	// RepositoryRows the rows they returned and RepositoryDuration their total duration in microseconds
+	RepositoryCalls    = NewCounter("repository_calls")
+	RepositoryErrors   = NewCounter("repository_errors")
+	RepositoryRows     = NewCounter("repository_rows")
+	RepositoryDuration = NewCounter("repository_duration_us")
+	// RepositoryCanceled counts the database calls of the repository aborted by the end of their request or by the
+	// statement timeout, by method
+	RepositoryCanceled = NewCounter("repository_canceled")
+)
+
+func init() {
+	// a number of goroutines growing with the requests served is a leak, requests whose client went away included
+	Publish("goroutines", func() interface{} { return runtime.NumGoroutine() })
+}
+
+// Publish publishes a gauge read when the metrics are served, for example the connections of a pool. A name already
+// published is left as is
+func Publish(name string, read func() interface{}) {
+	if expvar.Get(name) == nil {
+		expvar.Publish(name, expvar.Func(read))
+	}
+}
+
+// Handler serves every published variable (counters, memstats, cmdline) as a JSON object
+func Handler() http.Handler {
+	return expvar.Handler()
+}
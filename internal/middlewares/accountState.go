@@ -0,0 +1,37 @@
+package middlewares
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/gin-gonic/gin"
+)
+
+// AccountStates tells whether the account of a user is in a state allowing it to use the API
+type AccountStates interface {
+	CanUseAPI(userID int) (bool, error)
+}
+
+// RequireActiveAccount middleware refuses the requests of authenticated users whose account was deactivated, whether
+// they come with a JWT issued before or with an API key
+func RequireActiveAccount(states AccountStates) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetInt(constants.AuthUserKey)
+		if userID == 0 {
+			c.Next()
+			return
+		}
+		allowed, err := states.CanUseAPI(userID)
+		if err != nil {
+			slog.Error("Failed to check account state", "error", err, "userID", userID)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to authenticate"})
+			return
+		}
+		if !allowed {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": constants.ErrAccountDeactivated})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,26 @@
+package middlewares
+
+import (
+	"log/slog"
+	"net"
+	"net/http"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/gin-gonic/gin"
+)
+
+// AllowNetworks middleware rejects the requests whose client IP is outside networks, it restricts a listener
+// (for example the admin one) to internal clients
+func AllowNetworks(networks []*net.IPNet) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ip := net.ParseIP(c.ClientIP())
+		for _, network := range networks {
+			if ip != nil && network.Contains(ip) {
+				c.Next()
+				return
+			}
+		}
+		slog.Warn("Client not allowed", "clientIP", c.ClientIP(), "path", c.FullPath())
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": constants.ErrClientNotAllowed})
+	}
+}
@@ -0,0 +1,26 @@
+package middlewares
+
+import (
+	"fmt"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/gin-gonic/gin"
+)
+
+// AnnouncementSource finds the latest announcement a user did not dismiss
+type AnnouncementSource interface {
+	CurrentAnnouncement(userID int) (id int, level string, ok bool)
+}
+
+// Announce middleware tells authenticated users about the latest announcement they did not dismiss in the
+// X-Announcement header, clients show it from GET /announcements
+func Announce(source AnnouncementSource) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if userID := c.GetInt(constants.AuthUserKey); userID > 0 {
+			if id, level, ok := source.CurrentAnnouncement(userID); ok {
+				c.Header(constants.HeaderAnnouncement, fmt.Sprintf("%d; level=%s", id, level))
+			}
+		}
+		c.Next()
+	}
+}
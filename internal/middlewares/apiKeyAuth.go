@@ -0,0 +1,117 @@
+package middlewares
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danizion/contact-app/internal/auth"
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/danizion/contact-app/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyStore resolves API keys and remembers the nonces of signed requests
+type APIKeyStore interface {
+	ResolveAPIKey(keyID string) (*models.APIKey, error)
+	ClaimNonce(keyID, nonce string, ttl time.Duration) (bool, error)
+}
+
+// Authenticate middleware accepts either a JWT ("Bearer <token>") or an API key. API key clients send
+// "ApiKey <key_id>:<secret>", or "ApiKey <key_id>" together with the signature headers so the secret never travels.
+// When REQUIRE_SIGNED_WRITES is true every API key request other than GET and HEAD must be signed.
+func Authenticate(keys APIKeyStore, tokens TokenStore) gin.HandlerFunc {
+	authenticateJWT := AuthenticateJWT(tokens)
+	requireSignedWrites := utils.GetEnvOrDefault("REQUIRE_SIGNED_WRITES", "false") == "true"
+
+	return func(c *gin.Context) {
+		scheme, credentials, _ := strings.Cut(c.GetHeader("Authorization"), " ")
+		if scheme != "ApiKey" {
+			authenticateJWT(c)
+			return
+		}
+
+		keyID, secret, hasSecret := strings.Cut(credentials, ":")
+		key, err := keys.ResolveAPIKey(keyID)
+		if err != nil {
+			slog.Error("Failed to resolve API key", "error", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to authenticate"})
+			return
+		}
+		if key == nil {
+			rejectAuth(c, "invalid_api_key", http.StatusUnauthorized, constants.ErrInvalidAPIKey)
+			return
+		}
+
+		signature := c.GetHeader(constants.HeaderSignature)
+		switch {
+		case signature != "":
+			if !verifySignedRequest(c, keys, key, signature) {
+				return
+			}
+		case requireSignedWrites && c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead:
+			rejectAuth(c, "signature_required", http.StatusUnauthorized, constants.ErrSignatureRequired)
+			return
+		case !hasSecret || subtle.ConstantTimeCompare([]byte(secret), []byte(key.Secret)) != 1:
+			rejectAuth(c, "invalid_api_key", http.StatusUnauthorized, constants.ErrInvalidAPIKey)
+			return
+		}
+
+		// API keys act as their user but never carry admin privileges
+		c.Set(constants.AuthUserKey, key.UserID)
+		c.Set(constants.AuthIsAdminKey, false)
+		c.Next()
+	}
+}
+
+// verifySignedRequest checks the timestamp, signature and nonce of a signed request, aborting it when one is invalid
+func verifySignedRequest(c *gin.Context, keys APIKeyStore, key *models.APIKey, signature string) bool {
+	timestamp := c.GetHeader(constants.HeaderSignatureTimestamp)
+	nonce := c.GetHeader(constants.HeaderSignatureNonce)
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		rejectAuth(c, "invalid_signature", http.StatusUnauthorized, constants.ErrInvalidSignature)
+		return false
+	}
+	if age := time.Since(time.Unix(unix, 0)); age > constants.SignedRequestTolerance || age < -constants.SignedRequestTolerance {
+		rejectAuth(c, "signature_expired", http.StatusUnauthorized, constants.ErrSignatureExpired)
+		return false
+	}
+	if len(nonce) < constants.MinSignatureNonceLength || len(nonce) > constants.MaxSignatureNonceLength {
+		rejectAuth(c, "invalid_nonce", http.StatusUnauthorized, constants.ErrInvalidNonce)
+		return false
+	}
+
+	// The body is part of the signature, put it back for the handler once read
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return false
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	if !auth.VerifyRequestSignature(key.Secret, c.Request.Method, c.Request.URL.RequestURI(), timestamp, nonce, body, signature) {
+		rejectAuth(c, "invalid_signature", http.StatusUnauthorized, constants.ErrInvalidSignature)
+		return false
+	}
+
+	// Claim the nonce only once the signature is valid so forged requests cannot burn the nonces of a client
+	fresh, err := keys.ClaimNonce(key.KeyID, nonce, 2*constants.SignedRequestTolerance)
+	if err != nil {
+		slog.Error("Failed to record request nonce", "error", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to authenticate"})
+		return false
+	}
+	if !fresh {
+		rejectAuth(c, "nonce_reused", http.StatusUnauthorized, constants.ErrNonceReused)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,41 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/policy"
+	"github.com/gin-gonic/gin"
+)
+
+// Subject returns the authenticated caller of a request, must run after Authenticate
+func Subject(c *gin.Context) policy.Subject {
+	return policy.Subject{
+		UserID:  c.GetInt(constants.AuthUserKey),
+		IsAdmin: c.GetBool(constants.AuthIsAdminKey),
+	}
+}
+
+// Authorize middleware rejects requests the policy does not allow on the resource type, the action is
+// derived from the HTTP method. Must run after Authenticate
+func Authorize(resourceType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !policy.Can(Subject(c), actionForMethod(c.Request.Method), policy.Resource{Type: resourceType}) {
+			rejectAuth(c, "forbidden", http.StatusForbidden, "Admin privileges required")
+			return
+		}
+		c.Next()
+	}
+}
+
+// actionForMethod maps an HTTP method to the policy action it performs
+func actionForMethod(method string) policy.Action {
+	switch method {
+	case http.MethodGet, http.MethodHead:
+		return policy.ActionRead
+	case http.MethodDelete:
+		return policy.ActionDelete
+	default:
+		return policy.ActionWrite
+	}
+}
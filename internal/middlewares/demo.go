@@ -0,0 +1,15 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/gin-gonic/gin"
+)
+
+// DisabledInDemo middleware rejects every request, it guards the routes unsafe on a public demo instance
+func DisabledInDemo() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": constants.ErrDisabledInDemo})
+	}
+}
@@ -0,0 +1,24 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/danizion/contact-app/internal/alerting"
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorBudget middleware records the status of every response of route ("GET /contacts/:id") on monitor, a panic
+// counts as a 500 before being passed on to the recovery middleware
+func ErrorBudget(monitor *alerting.Monitor, route string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if err := recover(); err != nil {
+				monitor.Record(route, http.StatusInternalServerError)
+				panic(err)
+			}
+		}()
+
+		c.Next()
+		monitor.Record(route, c.Writer.Status())
+	}
+}
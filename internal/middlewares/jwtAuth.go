@@ -1,44 +1,97 @@
 package middlewares
 
 import (
+	"errors"
+	"log/slog"
 	"net/http"
 	"strings"
 
 	"github.com/danizion/contact-app/internal/auth"
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/metrics"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// AuthenticateJWT middleware for verifying JWT tokens
-func AuthenticateJWT() gin.HandlerFunc {
+// TokenStore tells whether an access token was revoked by a logout or a password change
+type TokenStore interface {
+	IsTokenRevoked(claims *auth.Claims) (bool, error)
+}
+
+// AuthenticateJWT middleware for verifying JWT tokens, tokens revoked in the store are rejected
+func AuthenticateJWT(tokens TokenStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Retrieve the Authorization header
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing Authorization header"})
+			rejectAuth(c, "missing_header", http.StatusUnauthorized, "Missing Authorization header")
 			return
 		}
 
 		// Expecting header format: "Bearer <token>"
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid Authorization header format"})
+			rejectAuth(c, "malformed_header", http.StatusUnauthorized, "Invalid Authorization header format")
 			return
 		}
 		tokenString := parts[1]
 
 		// Parse and validate the token
-		claims := &auth.Claims{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			return auth.GetJWTSecret(), nil
-		})
-		if err != nil || !token.Valid {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		claims, err := auth.ParseJWT(tokenString)
+		if err != nil {
+			rejectAuth(c, jwtFailureReason(err), http.StatusUnauthorized, "Invalid or expired token")
+			return
+		}
+
+		revoked, err := tokens.IsTokenRevoked(claims)
+		if err != nil {
+			slog.Error("Failed to check token revocation", "error", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to authenticate"})
+			return
+		}
+		if revoked {
+			rejectAuth(c, "revoked", http.StatusUnauthorized, "Invalid or expired token")
 			return
 		}
 
 		// Save the user ID into the context for downstream handlers.
-		c.Set("userID", claims.UserID)
+		c.Set(constants.AuthUserKey, claims.UserID)
+		c.Set(constants.AuthIsAdminKey, claims.IsAdmin)
+		c.Set(constants.AuthTokenKey, claims)
 		c.Next()
 	}
 }
+
+// jwtFailureReason maps a token validation error to the reason recorded in the auth failure metrics
+func jwtFailureReason(err error) string {
+	switch {
+	case errors.Is(err, auth.ErrUnexpectedSigningMethod):
+		return "unexpected_algorithm"
+	case errors.Is(err, auth.ErrMissingUserID):
+		return "missing_user_id"
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return "expired"
+	case errors.Is(err, jwt.ErrTokenUsedBeforeIssued), errors.Is(err, jwt.ErrTokenNotValidYet):
+		return "not_yet_valid"
+	case errors.Is(err, jwt.ErrTokenInvalidIssuer):
+		return "invalid_issuer"
+	case errors.Is(err, jwt.ErrTokenInvalidAudience):
+		return "invalid_audience"
+	case errors.Is(err, jwt.ErrTokenRequiredClaimMissing):
+		return "missing_claim"
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid):
+		return "invalid_signature"
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		return "malformed_token"
+	default:
+		return "invalid_token"
+	}
+}
+
+// rejectAuth aborts an unauthenticated request, recording why in the logs and the auth failure metrics.
+// The reason is never sent to the client
+func rejectAuth(c *gin.Context, reason string, status int, message string) {
+	metrics.AuthFailures.Inc(reason)
+	slog.Warn("Authentication failed", "reason", reason, "path", c.FullPath(), "clientIP", c.ClientIP())
+	c.AbortWithStatusJSON(status, gin.H{"error": message})
+}
@@ -0,0 +1,67 @@
+package middlewares
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit middleware counts requests per user, or per client IP before authentication, and rejects them with
+// 429 once limit requests were sent within window. The X-RateLimit-* headers are set on every response so
+// clients can slow down before being rejected. When the limiter fails the request is let through
+func RateLimit(limiter ratelimit.Limiter, limit int, window time.Duration) gin.HandlerFunc {
+	return rateLimit(limiter, "", limit, window)
+}
+
+// RouteRateLimit middleware caps the requests of a user or client IP to a single route, counted apart from the
+// other routes. It tightens the limit of sensitive public routes (log in, sign up) against brute force
+func RouteRateLimit(limiter ratelimit.Limiter, route string, limit int, window time.Duration) gin.HandlerFunc {
+	return rateLimit(limiter, "route:"+route+":", limit, window)
+}
+
+func rateLimit(limiter ratelimit.Limiter, prefix string, limit int, window time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		result, err := limiter.Allow(prefix+rateLimitKey(c), limit, window)
+		if err != nil {
+			slog.Error("Failed to check rate limit", "error", err)
+			c.Next()
+			return
+		}
+
+		c.Header(constants.HeaderRateLimitLimit, strconv.Itoa(result.Limit))
+		c.Header(constants.HeaderRateLimitRemaining, strconv.Itoa(result.Remaining))
+		c.Header(constants.HeaderRateLimitReset, strconv.FormatInt(result.Reset.Unix(), 10))
+
+		if !result.Allowed {
+			retryAfter := int(math.Ceil(time.Until(result.Reset).Seconds()))
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+			c.Header(constants.HeaderRetryAfter, strconv.Itoa(retryAfter))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, dtos.RateLimitErrorResponseDto{
+				Error:      constants.ErrRateLimited,
+				Limit:      result.Limit,
+				Remaining:  result.Remaining,
+				Reset:      result.Reset.Unix(),
+				RetryAfter: retryAfter,
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// rateLimitKey identifies who a request is counted for
+func rateLimitKey(c *gin.Context) string {
+	if userID := c.GetInt(constants.AuthUserKey); userID > 0 {
+		return "user:" + strconv.Itoa(userID)
+	}
+	return "ip:" + c.ClientIP()
+}
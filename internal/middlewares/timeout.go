@@ -0,0 +1,21 @@
+package middlewares
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Timeout middleware cancels the context of a request after timeout, or as soon as its client goes away. The
+// queries and Redis calls run under the context of the request are aborted then, handlers answer the aborted
+// requests with api.respondError
+func Timeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
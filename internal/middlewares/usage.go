@@ -0,0 +1,33 @@
+package middlewares
+
+import (
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/gin-gonic/gin"
+)
+
+// UsageRecorder counts the requests of a user to a route
+type UsageRecorder interface {
+	Record(userID int, route string, status int, bytesIn, bytesOut int64)
+}
+
+// TrackUsage middleware counts the requests of authenticated users to route (by route name) with the size of the
+// request and response bodies. It runs before the rate limits so rejected requests are counted too
+func TrackUsage(usage UsageRecorder, route string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		userID := c.GetInt(constants.AuthUserKey)
+		if userID <= 0 {
+			return
+		}
+		bytesIn := c.Request.ContentLength
+		if bytesIn < 0 {
+			bytesIn = 0
+		}
+		bytesOut := int64(c.Writer.Size())
+		if bytesOut < 0 {
+			bytesOut = 0
+		}
+		usage.Record(userID, route, c.Writer.Status(), bytesIn, bytesOut)
+	}
+}
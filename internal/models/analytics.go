@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// AnalyticsEvent is an anonymized product event, Properties is a JSON object
+type AnalyticsEvent struct {
+	ID         int64     `db:"id"`
+	Name       string    `db:"name"`
+	Actor      string    `db:"actor"`
+	Properties string    `db:"properties"`
+	OccurredAt time.Time `db:"occurred_at"`
+}
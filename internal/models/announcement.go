@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// Announcement is a deployment wide message from the admins, shown to every user between StartsAt and EndsAt
+type Announcement struct {
+	ID       int        `db:"id"`
+	Message  string     `db:"message"`
+	Level    string     `db:"level"`
+	StartsAt time.Time  `db:"starts_at"`
+	EndsAt   *time.Time `db:"ends_at"`
+	// CreatedBy is the admin who posted the announcement, nil once deleted
+	CreatedBy *int      `db:"created_by"`
+	CreatedAt time.Time `db:"created_at"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// APIUsage counts the API requests of a user to a route (by route name) during a UTC day
+type APIUsage struct {
+	UserID      int       `db:"user_id"`
+	Day         time.Time `db:"day"`
+	Route       string    `db:"route"`
+	Requests    int64     `db:"requests"`
+	RateLimited int64     `db:"rate_limited"`
+	BytesIn     int64     `db:"bytes_in"`
+	BytesOut    int64     `db:"bytes_out"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// APIKey lets an integration call the API on behalf of a user, KeyID identifies it and Secret authenticates or
+// signs its requests
+type APIKey struct {
+	ID        int       `db:"id"`
+	UserID    int       `db:"user_id"`
+	Name      string    `db:"name"`
+	KeyID     string    `db:"key_id"`
+	Secret    string    `db:"secret"`
+	CreatedAt time.Time `db:"created_at"`
+}
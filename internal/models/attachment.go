@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+type Attachment struct {
+	ID          int       `db:"id"`
+	ContactID   int       `db:"contact_id"`
+	UserID      int       `db:"user_id"`
+	FileName    string    `db:"file_name"`
+	ContentType string    `db:"content_type"`
+	SizeBytes   int64     `db:"size_bytes"`
+	StorageKey  string    `db:"storage_key"`
+	CreatedAt   time.Time `db:"created_at"`
+}
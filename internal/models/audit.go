@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// AuditEntry records an action performed on a user's account, UserID is the account owner and ActorID who acted
+type AuditEntry struct {
+	ID         int64     `db:"id"`
+	UserID     int       `db:"user_id"`
+	ActorID    int       `db:"actor_id"`
+	Action     string    `db:"action"`
+	EntityType string    `db:"entity_type"`
+	EntityID   int       `db:"entity_id"`
+	Details    string    `db:"details"`
+	CreatedAt  time.Time `db:"created_at"`
+}
@@ -3,12 +3,39 @@ package models
 import "time"
 
 type Contact struct {
-	ID          int       `db:"id"`
-	UserID      int       `db:"user_id"`
-	FirstName   string    `db:"first_name"`
-	LastName    string    `db:"last_name"`
-	PhoneNumber string    `db:"phone_number"`
-	Address     string    `db:"address"`
-	CreatedAt   time.Time `db:"created_at"`
-	UpdatedAt   time.Time `db:"updated_at"`
+	ID            int       `db:"id"`
+	UserID        int       `db:"user_id"`
+	FirstName     string    `db:"first_name"`
+	LastName      string    `db:"last_name"`
+	PhoneNumber   string    `db:"phone_number"`
+	Address       string    `db:"address"`
+	Email         string    `db:"email"`
+	Company       string    `db:"company"`
+	JobTitle      string    `db:"job_title"`
+	Timezone      string    `db:"timezone"`
+	Street        string    `db:"street"`
+	City          string    `db:"city"`
+	Region        string    `db:"region"`
+	PostalCode    string    `db:"postal_code"`
+	CountryCode   string    `db:"country_code"`
+	Source        string    `db:"source"`
+	Stage         string    `db:"stage"`
+	BoardPosition int       `db:"board_position"`
+	Latitude      *float64  `db:"latitude"`
+	Longitude     *float64  `db:"longitude"`
+	CreatedAt     time.Time `db:"created_at"`
+	UpdatedAt     time.Time `db:"updated_at"`
+	// DeletedAt is set while the contact is in the trash
+	DeletedAt *time.Time `db:"deleted_at"`
+	// LastInteractedAt is the last interaction logged with the contact or view of it, nil when never
+	LastInteractedAt *time.Time `db:"last_interacted_at"`
+	// CustomFields holds the values of the fields of the contact template, TemplateVersion the version of the template
+	// they were validated against
+	CustomFields    CustomFields `db:"custom_fields"`
+	TemplateVersion int          `db:"template_version"`
+	// ClientID is the UUID an offline client gave the contact before it was created, unique per user
+	ClientID *string `db:"client_id"`
+	// PhoneNormalized is the E.164 form of PhoneNumber, empty when it could not be normalized. It is written with the
+	// phone number and regenerated by the normalize-phones admin operation when the regions it was read in change
+	PhoneNormalized string `db:"phone_normalized"`
 }
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// CustomFieldDefinition is a custom field admins defined for every contact, see the customfield package
+type CustomFieldDefinition struct {
+	Key          string         `db:"key"`
+	Label        string         `db:"label"`
+	Type         string         `db:"type"`
+	Options      pq.StringArray `db:"options"`
+	Required     bool           `db:"required"`
+	DefaultValue string         `db:"default_value"`
+	Position     int            `db:"position"`
+	CreatedAt    time.Time      `db:"created_at"`
+	UpdatedAt    time.Time      `db:"updated_at"`
+}
+
+// CustomFields are the custom field values of a contact by key, stored as a JSONB object
+type CustomFields map[string]string
+
+// Value encodes the fields for the database, never as NULL
+func (f CustomFields) Value() (driver.Value, error) {
+	if f == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(f)
+}
+
+// Scan decodes the fields read from the database
+func (f *CustomFields) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*f = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into custom fields", src)
+	}
+	return json.Unmarshal(data, f)
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// EmailChange is a pending change of the email of a user. A confirmation link is sent to both the current and the
+// new address, only the SHA-256 hashes of their tokens are stored
+type EmailChange struct {
+	UserID         int        `db:"user_id"`
+	NewEmail       string     `db:"new_email"`
+	OldTokenHash   string     `db:"old_token_hash"`
+	NewTokenHash   string     `db:"new_token_hash"`
+	OldConfirmedAt *time.Time `db:"old_confirmed_at"`
+	NewConfirmedAt *time.Time `db:"new_confirmed_at"`
+	CreatedAt      time.Time  `db:"created_at"`
+	ExpiresAt      time.Time  `db:"expires_at"`
+}
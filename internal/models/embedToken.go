@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// EmbedToken gives read-only access to a list of contacts of a user without logging in, for embedding it in another
+// page. The list is a group of the user, or the contacts matching a saved search (Query and Tag)
+type EmbedToken struct {
+	ID     int    `db:"id"`
+	UserID int    `db:"user_id"`
+	Name   string `db:"name"`
+	// TokenHash is the SHA-256 of the token, TokenPrefix its start shown to tell tokens apart
+	TokenHash   string     `db:"token_hash"`
+	TokenPrefix string     `db:"token_prefix"`
+	GroupID     *int       `db:"group_id"`
+	Query       string     `db:"query"`
+	Tag         string     `db:"tag"`
+	ExpiresAt   *time.Time `db:"expires_at"`
+	RevokedAt   *time.Time `db:"revoked_at"`
+	LastUsedAt  *time.Time `db:"last_used_at"`
+	CreatedAt   time.Time  `db:"created_at"`
+}
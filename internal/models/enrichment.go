@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// ContactEnrichment is data suggested by an enrichment provider, applied to the contact only once the user accepts it
+type ContactEnrichment struct {
+	ID             int        `db:"id"`
+	ContactID      int        `db:"contact_id"`
+	UserID         int        `db:"user_id"`
+	Provider       string     `db:"provider"`
+	MatchedOn      string     `db:"matched_on"`
+	Status         string     `db:"status"`
+	Company        string     `db:"company"`
+	JobTitle       string     `db:"job_title"`
+	SocialProfiles string     `db:"social_profiles"`
+	FetchedAt      time.Time  `db:"fetched_at"`
+	ResolvedAt     *time.Time `db:"resolved_at"`
+}
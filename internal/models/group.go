@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Group is a named collection of a user's contacts
+type Group struct {
+	ID           int       `db:"id"`
+	UserID       int       `db:"user_id"`
+	Name         string    `db:"name"`
+	ContactCount int       `db:"contact_count"`
+	CreatedAt    time.Time `db:"created_at"`
+}
+
+// Tag is a free-form label attached to a user's contacts, identified by its name
+type Tag struct {
+	ID           int       `db:"id"`
+	UserID       int       `db:"user_id"`
+	Name         string    `db:"name"`
+	ContactCount int       `db:"contact_count"`
+	CreatedAt    time.Time `db:"created_at"`
+}
+
+// ContactMembership is a group or tag a contact belongs to
+type ContactMembership struct {
+	ContactID int    `db:"contact_id"`
+	ID        int    `db:"id"`
+	Name      string `db:"name"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// ContactInteraction is a call, meeting or message with a contact logged by its owner
+type ContactInteraction struct {
+	ID         int       `db:"id"`
+	ContactID  int       `db:"contact_id"`
+	UserID     int       `db:"user_id"`
+	Kind       string    `db:"kind"`
+	Note       string    `db:"note"`
+	OccurredAt time.Time `db:"occurred_at"`
+	CreatedAt  time.Time `db:"created_at"`
+}
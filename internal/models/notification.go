@@ -0,0 +1,9 @@
+package models
+
+// NotificationPreference is a cell of the notification preferences matrix a user changed from its default
+type NotificationPreference struct {
+	UserID  int    `db:"user_id"`
+	Event   string `db:"event"`
+	Channel string `db:"channel"`
+	Enabled bool   `db:"enabled"`
+}
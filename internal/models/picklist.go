@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+type PicklistValue struct {
+	ID        int       `db:"id"`
+	Field     string    `db:"field"`
+	Value     string    `db:"value"`
+	Position  int       `db:"position"`
+	CreatedAt time.Time `db:"created_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// UserPreferences holds the settings of a user, users without a stored row use the defaults (zero values)
+type UserPreferences struct {
+	UserID       int    `db:"user_id"`
+	WeeklyDigest bool   `db:"weekly_digest"`
+	Region       string `db:"region"`
+	// SearchLanguage is the text search configuration contacts are indexed in, empty for the deployment one
+	SearchLanguage string `db:"search_language"`
+	// DuplicateSensitivity is how alike contacts must be to be listed as duplicates, empty for the default
+	DuplicateSensitivity string     `db:"duplicate_sensitivity"`
+	DigestSentAt         *time.Time `db:"digest_sent_at"`
+	UpdatedAt            time.Time  `db:"updated_at"`
+}
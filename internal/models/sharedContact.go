@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// SharedContact is a contact its owner submitted to the address book shared with every user of the deployment
+type SharedContact struct {
+	Contact
+	Status      string    `db:"status"`
+	SubmittedAt time.Time `db:"submitted_at"`
+	// ReviewedBy is the admin or editor who approved or rejected the submission, nil while pending
+	ReviewedBy *int       `db:"reviewed_by"`
+	ReviewedAt *time.Time `db:"reviewed_at"`
+	ReviewNote string     `db:"review_note"`
+	// ChangedAt is when the owner last changed the contact while it was shared, nil when never
+	ChangedAt *time.Time `db:"changed_at"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// ContactSnapshot is a named copy of a user's address book, Contacts holds the contacts as JSON
+type ContactSnapshot struct {
+	ID           int       `db:"id"`
+	UserID       int       `db:"user_id"`
+	Name         string    `db:"name"`
+	ContactCount int       `db:"contact_count"`
+	Contacts     string    `db:"contacts"`
+	CreatedAt    time.Time `db:"created_at"`
+}
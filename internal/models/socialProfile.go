@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+type SocialProfile struct {
+	ID        int       `db:"id"`
+	ContactID int       `db:"contact_id"`
+	Network   string    `db:"network"`
+	Handle    string    `db:"handle"`
+	URL       string    `db:"url"`
+	CreatedAt time.Time `db:"created_at"`
+}
@@ -3,10 +3,14 @@ package models
 import "time"
 
 type User struct {
-	ID             int       `db:"id"`
-	Username       string    `db:"username"`
-	Email          string    `db:"email"`
-	HashedPassword string    `db:"hashed_password"`
-	CreatedAt      time.Time `db:"created_at"`
-	UpdatedAt      time.Time `db:"updated_at"`
+	ID             int    `db:"id"`
+	Username       string `db:"username"`
+	Email          string `db:"email"`
+	HashedPassword string `db:"hashed_password"`
+	IsAdmin        bool   `db:"is_admin"`
+	// State is the state of the account, see the accountstate package
+	State         string     `db:"state"`
+	DeactivatedAt *time.Time `db:"deactivated_at"`
+	CreatedAt     time.Time  `db:"created_at"`
+	UpdatedAt     time.Time  `db:"updated_at"`
 }
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// UsernameChange is a change of the username of a user, the former username is reserved to the user for a while
+type UsernameChange struct {
+	ID          int       `db:"id"`
+	UserID      int       `db:"user_id"`
+	OldUsername string    `db:"old_username"`
+	NewUsername string    `db:"new_username"`
+	ChangedAt   time.Time `db:"changed_at"`
+}
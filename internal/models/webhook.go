@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Webhook is a URL registered by a user to receive signed event deliveries
+type Webhook struct {
+	ID        int       `db:"id"`
+	UserID    int       `db:"user_id"`
+	URL       string    `db:"url"`
+	Secret    string    `db:"secret"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// WebhookDelivery is an event sent to a webhook, retried until delivered or out of attempts. Payload is the exact
+// body sent, every attempt carries the same event ID
+type WebhookDelivery struct {
+	ID            int64      `db:"id"`
+	WebhookID     int        `db:"webhook_id"`
+	EventID       string     `db:"event_id"`
+	EventType     string     `db:"event_type"`
+	Payload       string     `db:"payload"`
+	Status        string     `db:"status"`
+	Attempts      int        `db:"attempts"`
+	StatusCode    *int       `db:"status_code"`
+	LastError     string     `db:"last_error"`
+	NextAttemptAt *time.Time `db:"next_attempt_at"`
+	CreatedAt     time.Time  `db:"created_at"`
+	DeliveredAt   *time.Time `db:"delivered_at"`
+}
@@ -0,0 +1,165 @@
+// Package notify sends operational alerts to the operators of the instance through Slack, a webhook or email
+package notify
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/danizion/contact-app/internal/mail"
+	"github.com/danizion/contact-app/internal/utils"
+	"github.com/danizion/contact-app/pkg/webhook"
+)
+
+// AlertEventType is the type of the webhook event carrying an alert
+const AlertEventType = "alert.fired"
+
+// Alert is a message for the operators, Key identifies what it is about (for example the route failing)
+type Alert struct {
+	Key     string    `json:"key"`
+	Title   string    `json:"title"`
+	Message string    `json:"message"`
+	FiredAt time.Time `json:"fired_at"`
+}
+
+// Notifier delivers alerts
+type Notifier interface {
+	Notify(alert Alert) error
+}
+
+// Init creates the notifier configured by ALERT_SLACK_WEBHOOK_URL, ALERT_WEBHOOK_URL (signed with ALERT_WEBHOOK_SECRET)
+// and ALERT_EMAIL_TO (sent through sender), alerts go to every configured channel. Returns nil when none is configured
+func Init(sender mail.Sender) Notifier {
+	var notifiers Multi
+	if url := utils.GetEnvOrDefault("ALERT_SLACK_WEBHOOK_URL", ""); url != "" {
+		notifiers = append(notifiers, NewSlackNotifier(url))
+	}
+	if url := utils.GetEnvOrDefault("ALERT_WEBHOOK_URL", ""); url != "" {
+		notifiers = append(notifiers, NewWebhookNotifier(url, utils.GetEnvOrDefault("ALERT_WEBHOOK_SECRET", "")))
+	}
+	if to := utils.GetEnvOrDefault("ALERT_EMAIL_TO", ""); to != "" && sender != nil {
+		notifiers = append(notifiers, NewEmailNotifier(sender, to))
+	}
+	if len(notifiers) == 0 {
+		return nil
+	}
+	return notifiers
+}
+
+// Multi sends every alert to several notifiers, a failing one does not prevent the others from being notified
+type Multi []Notifier
+
+// Notify sends the alert to every notifier and returns their errors joined
+func (m Multi) Notify(alert Alert) error {
+	var errs []error
+	for _, notifier := range m {
+		if err := notifier.Notify(alert); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SlackNotifier posts alerts to a Slack incoming webhook
+type SlackNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewSlackNotifier creates a new instance of SlackNotifier
+func NewSlackNotifier(url string) *SlackNotifier {
+	return &SlackNotifier{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Notify posts the alert as a Slack message
+func (n *SlackNotifier) Notify(alert Alert) error {
+	body, err := json.Marshal(map[string]string{"text": fmt.Sprintf("*%s*\n%s", alert.Title, alert.Message)})
+	if err != nil {
+		return err
+	}
+	return post(n.client, n.url, body, nil)
+}
+
+// WebhookNotifier posts alerts as webhook events, signed like the user webhooks (see pkg/webhook) when a secret is set
+type WebhookNotifier struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+// NewWebhookNotifier creates a new instance of WebhookNotifier
+func NewWebhookNotifier(url, secret string) *WebhookNotifier {
+	return &WebhookNotifier{url: url, secret: secret, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Notify posts the alert as the data of an alert.fired event
+func (n *WebhookNotifier) Notify(alert Alert) error {
+	data, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	random := make([]byte, 12)
+	if _, err := rand.Read(random); err != nil {
+		return fmt.Errorf("failed to generate event ID: %w", err)
+	}
+	event := webhook.Event{ID: "evt_" + hex.EncodeToString(random), Type: AlertEventType, CreatedAt: alert.FiredAt, Data: data}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return post(n.client, n.url, body, func(header http.Header) {
+		if n.secret != "" {
+			webhook.SetHeaders(header, n.secret, event.ID, time.Now(), body)
+		}
+	})
+}
+
+// EmailNotifier emails alerts to the operators
+type EmailNotifier struct {
+	sender mail.Sender
+	to     string
+}
+
+// NewEmailNotifier creates a new instance of EmailNotifier
+func NewEmailNotifier(sender mail.Sender, to string) *EmailNotifier {
+	return &EmailNotifier{sender: sender, to: to}
+}
+
+// Notify emails the alert
+func (n *EmailNotifier) Notify(alert Alert) error {
+	msg, err := mail.Render("alert", n.to, alert)
+	if err != nil {
+		return err
+	}
+	return n.sender.Send(msg)
+}
+
+// post sends a JSON body, any status outside 2xx is a failure
+func post(client *http.Client, url string, body []byte, setHeaders func(http.Header)) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if setHeaders != nil {
+		setHeaders(req.Header)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("alert delivery failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("alert receiver %s returned %d", url, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package ocr
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Card holds the contact details recognized on a business card
+type Card struct {
+	FirstName   string
+	LastName    string
+	PhoneNumber string
+	Email       string
+	Company     string
+	Address     string
+}
+
+var (
+	emailPattern   = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	phonePattern   = regexp.MustCompile(`\+?[0-9][0-9 ().\-]{6,}[0-9]`)
+	urlPattern     = regexp.MustCompile(`(?i)^(https?://|www\.)`)
+	companyMarkers = []string{"ltd", "inc", "llc", "corp", "gmbh", "co.", "company", "group", "technologies", "solutions"}
+	addressMarkers = []string{"street", "st.", "road", "rd.", "ave", "avenue", "blvd", "suite", "floor", "p.o. box"}
+)
+
+// ParseCard extracts contact details from the raw text of a business card using simple line heuristics,
+// fields that could not be recognized are left empty for the user to fill in
+func ParseCard(text string) Card {
+	var card Card
+	var unclassified []string
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		lower := strings.ToLower(line)
+
+		switch {
+		case card.Email == "" && emailPattern.MatchString(line):
+			card.Email = emailPattern.FindString(line)
+		case card.PhoneNumber == "" && phonePattern.MatchString(line):
+			card.PhoneNumber = strings.TrimSpace(phonePattern.FindString(line))
+		case urlPattern.MatchString(line):
+			// websites are not stored on the contact
+		case card.Company == "" && containsAny(lower, companyMarkers):
+			card.Company = line
+		case card.Address == "" && (containsAny(lower, addressMarkers) || startsWithDigit(line)):
+			card.Address = line
+		default:
+			unclassified = append(unclassified, line)
+		}
+	}
+
+	// The first line that looks like a person's name is the name, the next unclassified line the company
+	for _, line := range unclassified {
+		if card.FirstName == "" && looksLikeName(line) {
+			fields := strings.Fields(line)
+			card.FirstName = fields[0]
+			card.LastName = strings.Join(fields[1:], " ")
+			continue
+		}
+		if card.Company == "" {
+			card.Company = line
+		}
+	}
+
+	return card
+}
+
+func containsAny(s string, markers []string) bool {
+	for _, marker := range markers {
+		if strings.Contains(s, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func startsWithDigit(s string) bool {
+	return len(s) > 0 && unicode.IsDigit(rune(s[0]))
+}
+
+// looksLikeName accepts two to four words made of letters only
+func looksLikeName(line string) bool {
+	fields := strings.Fields(line)
+	if len(fields) < 2 || len(fields) > 4 {
+		return false
+	}
+	for _, r := range line {
+		if !unicode.IsLetter(r) && r != ' ' && r != '-' && r != '\'' && r != '.' {
+			return false
+		}
+	}
+	return true
+}
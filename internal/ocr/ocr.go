@@ -0,0 +1,78 @@
+package ocr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/danizion/contact-app/internal/utils"
+)
+
+// ErrNotConfigured is returned when no OCR provider is configured for the deployment
+var ErrNotConfigured = errors.New("ocr provider not configured")
+
+// Provider extracts the text printed on an image
+type Provider interface {
+	ExtractText(image []byte, contentType string) (string, error)
+}
+
+// HTTPProvider sends images to an external OCR service and expects a JSON body of the form {"text": "..."}
+type HTTPProvider struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+// Init creates the OCR provider configured by the OCR_URL and OCR_API_KEY environment variables,
+// returns nil when OCR is not configured
+func Init() Provider {
+	url := utils.GetEnvOrDefault("OCR_URL", "")
+	if url == "" {
+		return nil
+	}
+	return NewHTTPProvider(url, utils.GetEnvOrDefault("OCR_API_KEY", ""))
+}
+
+// NewHTTPProvider creates a new instance of HTTPProvider
+func NewHTTPProvider(url, apiKey string) *HTTPProvider {
+	return &HTTPProvider{
+		url:    url,
+		apiKey: apiKey,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// ExtractText posts the image to the OCR service and returns the recognized text
+func (p *HTTPProvider) ExtractText(image []byte, contentType string) (string, error) {
+	req, err := http.NewRequest(http.MethodPost, p.url, bytes.NewReader(image))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("ocr request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("ocr provider returned %d: %s", resp.StatusCode, body)
+	}
+
+	var result struct {
+		Text string `json:"text"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("invalid ocr response: %w", err)
+	}
+	return result.Text, nil
+}
@@ -0,0 +1,153 @@
+package phone
+
+import (
+	"strings"
+)
+
+// country holds the dialing conventions of a country
+type country struct {
+	CallingCode string
+	// TrunkPrefix is dialed before national numbers and dropped in international ones
+	TrunkPrefix string
+	// Groups are the digit groups of national significant numbers, the last group takes the remaining digits
+	Groups    []int
+	Separator string
+	// AreaParens puts the first group in parentheses in the national format, as in North America
+	AreaParens bool
+	// Length is the length of national significant numbers where it is fixed
+	Length int
+}
+
+// Per-country conventions, countries sharing a calling code are formatted alike. Countries without an entry are
+// only understood in international form.
+var countries = map[string]country{
+	"US": {CallingCode: "1", Groups: []int{3, 3, 4}, Separator: "-", AreaParens: true, Length: 10},
+	"CA": {CallingCode: "1", Groups: []int{3, 3, 4}, Separator: "-", AreaParens: true, Length: 10},
+	"GB": {CallingCode: "44", TrunkPrefix: "0", Groups: []int{4, 6}, Separator: " "},
+	"IE": {CallingCode: "353", TrunkPrefix: "0", Groups: []int{2, 3, 4}, Separator: " "},
+	"DE": {CallingCode: "49", TrunkPrefix: "0", Groups: []int{3, 8}, Separator: " "},
+	"AT": {CallingCode: "43", TrunkPrefix: "0", Groups: []int{3, 8}, Separator: " "},
+	"CH": {CallingCode: "41", TrunkPrefix: "0", Groups: []int{2, 3, 2, 2}, Separator: " "},
+	"FR": {CallingCode: "33", TrunkPrefix: "0", Groups: []int{1, 2, 2, 2, 2}, Separator: " "},
+	"ES": {CallingCode: "34", Groups: []int{3, 3, 3}, Separator: " "},
+	"IT": {CallingCode: "39", Groups: []int{3, 3, 4}, Separator: " "},
+	"NL": {CallingCode: "31", TrunkPrefix: "0", Groups: []int{1, 8}, Separator: " "},
+	"IL": {CallingCode: "972", TrunkPrefix: "0", Groups: []int{2, 3, 4}, Separator: "-"},
+	"JP": {CallingCode: "81", TrunkPrefix: "0", Groups: []int{2, 4, 4}, Separator: "-"},
+	"IN": {CallingCode: "91", TrunkPrefix: "0", Groups: []int{5, 5}, Separator: " "},
+	"AU": {CallingCode: "61", TrunkPrefix: "0", Groups: []int{3, 3, 3}, Separator: " "},
+}
+
+// callingCodes maps calling codes to a country using them
+var callingCodes = make(map[string]string)
+
+func init() {
+	for code, c := range countries {
+		if existing, ok := callingCodes[c.CallingCode]; !ok || code < existing {
+			callingCodes[c.CallingCode] = code
+		}
+	}
+}
+
+// E.164 numbers hold at most maxDigits digits, calling code included
+const (
+	minDigits = 7
+	maxDigits = 15
+)
+
+// Normalize returns a phone number in E.164 form (+ then the calling code and the national significant number).
+// Numbers starting with + or 00 are international, others are national numbers of countryCode. ok is false when
+// the number cannot be normalized: too short or long, or national with an unknown country.
+func Normalize(raw, countryCode string) (e164 string, ok bool) {
+	raw = strings.TrimSpace(raw)
+	digits := onlyDigits(raw)
+	international := strings.HasPrefix(raw, "+")
+	if !international && strings.HasPrefix(digits, "00") {
+		digits, international = digits[2:], true
+	}
+
+	if !international {
+		c, known := countries[strings.ToUpper(countryCode)]
+		if !known {
+			return "", false
+		}
+		switch {
+		case c.TrunkPrefix != "" && strings.HasPrefix(digits, c.TrunkPrefix):
+			digits = strings.TrimPrefix(digits, c.TrunkPrefix)
+		case c.Length != 0 && len(digits) == len(c.CallingCode)+c.Length && strings.HasPrefix(digits, c.CallingCode):
+			digits = strings.TrimPrefix(digits, c.CallingCode)
+		}
+		if c.Length != 0 && len(digits) != c.Length {
+			return "", false
+		}
+		digits = c.CallingCode + digits
+	}
+
+	if len(digits) < minDigits || len(digits) > maxDigits || digits[0] == '0' {
+		return "", false
+	}
+	return "+" + digits, true
+}
+
+// KnownRegion reports whether national numbers of a country can be normalized
+func KnownRegion(countryCode string) bool {
+	_, known := countries[strings.ToUpper(countryCode)]
+	return known
+}
+
+// Format renders an E.164 number for a reader in region: in the national format of region when the number has
+// the same calling code, in international format otherwise. Numbers of unknown countries are returned unchanged.
+func Format(e164, region string) string {
+	callingCode, nsn, ok := split(e164)
+	if !ok {
+		return e164
+	}
+	c := countries[callingCodes[callingCode]]
+	groups := group(nsn, c.Groups)
+
+	if reader, known := countries[strings.ToUpper(region)]; known && reader.CallingCode == callingCode {
+		if c.AreaParens && len(groups) > 1 {
+			return "(" + groups[0] + ") " + strings.Join(groups[1:], c.Separator)
+		}
+		return c.TrunkPrefix + strings.Join(groups, c.Separator)
+	}
+	return "+" + callingCode + " " + strings.Join(groups, c.Separator)
+}
+
+// split separates the calling code of a known country from the national significant number of an E.164 number
+func split(e164 string) (callingCode, nsn string, ok bool) {
+	if !strings.HasPrefix(e164, "+") {
+		return "", "", false
+	}
+	digits := e164[1:]
+	// Calling codes are prefix-free, at most one length matches
+	for length := 1; length <= 3 && length < len(digits); length++ {
+		if _, known := callingCodes[digits[:length]]; known {
+			return digits[:length], digits[length:], true
+		}
+	}
+	return "", "", false
+}
+
+// group cuts digits into groups of the given sizes, the last group taking the remaining digits
+func group(digits string, sizes []int) []string {
+	var groups []string
+	for i, size := range sizes {
+		if len(digits) <= size || i == len(sizes)-1 {
+			break
+		}
+		groups = append(groups, digits[:size])
+		digits = digits[size:]
+	}
+	return append(groups, digits)
+}
+
+func onlyDigits(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
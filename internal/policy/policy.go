@@ -0,0 +1,144 @@
+// Package policy decides whether a subject may perform an action on a resource. Handlers and services ask
+// the policy instead of comparing user IDs themselves, so sharing, delegation or roles only add rules here.
+// Queries already scoped to the requesting user (WHERE user_id = $1) need no check, the policy is consulted
+// when a resource is loaded first or when the outcome depends on who is asking.
+package policy
+
+import "errors"
+
+// ErrForbidden is returned by Authorize when no rule allows the action
+var ErrForbidden = errors.New("not authorized to perform this action")
+
+// Action is something a subject does to a resource
+type Action string
+
+// Actions checked by the application
+const (
+	ActionRead   Action = "read"
+	ActionWrite  Action = "write"
+	ActionDelete Action = "delete"
+	ActionManage Action = "manage"
+)
+
+// Resource types checked by the application
+const (
+	ResourceContact    = "contact"
+	ResourceAttachment = "attachment"
+	ResourceAuditLog   = "audit_log"
+	ResourcePicklist   = "picklist"
+	// ResourceContactTemplate is the custom fields and default tags of the contacts of the deployment
+	ResourceContactTemplate = "contact_template"
+	ResourceMetrics         = "metrics"
+	ResourceSettings        = "settings"
+	ResourceUser            = "user"
+	// ResourceSharedBook is the review queue of the contacts shared with every user
+	ResourceSharedBook = "shared_book"
+	// ResourceDirectory is the settings of the public team directory
+	ResourceDirectory = "directory"
+	// ResourceMaintenance is the jobs rebuilding the data derived from the contacts of every account
+	ResourceMaintenance = "maintenance"
+)
+
+// Subject is the authenticated caller
+type Subject struct {
+	UserID  int
+	IsAdmin bool
+}
+
+// Resource identifies what is accessed, OwnerID is 0 for resources without an owner (global settings,
+// every account at once)
+type Resource struct {
+	Type    string
+	ID      int
+	OwnerID int
+}
+
+// Effect is the outcome of a single rule
+type Effect int
+
+const (
+	// Abstain leaves the decision to the other rules
+	Abstain Effect = iota
+	// Allow grants the action unless another rule denies it
+	Allow
+	// Deny refuses the action whatever the other rules say
+	Deny
+)
+
+// Rule inspects a request and returns its effect
+type Rule func(subject Subject, action Action, resource Resource) Effect
+
+// Policy combines rules: a request is allowed when at least one rule allows it and none denies it
+type Policy struct {
+	rules []Rule
+}
+
+// New creates a policy from rules
+func New(rules ...Rule) *Policy {
+	return &Policy{rules: rules}
+}
+
+// Can reports whether the subject may perform the action on the resource
+func (p *Policy) Can(subject Subject, action Action, resource Resource) bool {
+	allowed := false
+	for _, rule := range p.rules {
+		switch rule(subject, action, resource) {
+		case Deny:
+			return false
+		case Allow:
+			allowed = true
+		}
+	}
+	return allowed
+}
+
+// Authorize returns ErrForbidden when the subject may not perform the action on the resource
+func (p *Policy) Authorize(subject Subject, action Action, resource Resource) error {
+	if !p.Can(subject, action, resource) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// adminResources lists the resource types admins manage for every account
+var adminResources = map[string]bool{
+	ResourceAuditLog:        true,
+	ResourcePicklist:        true,
+	ResourceContactTemplate: true,
+	ResourceMetrics:         true,
+	ResourceSharedBook:      true,
+	ResourceDirectory:       true,
+	ResourceMaintenance:     true,
+	ResourceSettings:        true,
+	ResourceUser:            true,
+}
+
+// OwnerRule allows users every action on the resources they own
+func OwnerRule(subject Subject, _ Action, resource Resource) Effect {
+	if subject.UserID > 0 && resource.OwnerID == subject.UserID {
+		return Allow
+	}
+	return Abstain
+}
+
+// AdminRule allows admins every action on the resource types they manage, admins get no access to the
+// contacts of other users
+func AdminRule(subject Subject, _ Action, resource Resource) Effect {
+	if subject.IsAdmin && adminResources[resource.Type] {
+		return Allow
+	}
+	return Abstain
+}
+
+// Default is the policy enforced by the application
+var Default = New(OwnerRule, AdminRule)
+
+// Can reports whether the default policy lets the subject perform the action on the resource
+func Can(subject Subject, action Action, resource Resource) bool {
+	return Default.Can(subject, action, resource)
+}
+
+// Authorize checks the action against the default policy
+func Authorize(subject Subject, action Action, resource Resource) error {
+	return Default.Authorize(subject, action, resource)
+}
@@ -0,0 +1,88 @@
+// Package ratelimit counts requests per key over time windows, in memory or in Redis
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Result describes the state of a key after a request was counted
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is when the current window ends, for a rejected request when the next one will be allowed
+	Reset time.Time
+}
+
+// Limiter counts a request for key and reports whether it fits in limit requests per window
+type Limiter interface {
+	Allow(key string, limit int, window time.Duration) (Result, error)
+}
+
+// MemoryLimiter is a Limiter keeping its counters in process memory, every instance of the application
+// counts separately
+type MemoryLimiter struct {
+	mu        sync.Mutex
+	windows   map[string]*fixedWindow
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type fixedWindow struct {
+	count int
+	reset time.Time
+}
+
+// NewMemoryLimiter creates an empty MemoryLimiter
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		windows: map[string]*fixedWindow{},
+		now:     time.Now,
+	}
+}
+
+// Allow implements Limiter, rejected requests count too so a client hammering the API stays limited
+func (l *MemoryLimiter) Allow(key string, limit int, window time.Duration) (Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now, window)
+
+	w, ok := l.windows[key]
+	if !ok || !now.Before(w.reset) {
+		w = &fixedWindow{reset: now.Truncate(window).Add(window)}
+		l.windows[key] = w
+	}
+	w.count++
+
+	return newResult(w.count, limit, w.reset), nil
+}
+
+// sweep drops expired windows at most once per window so idle keys do not accumulate
+func (l *MemoryLimiter) sweep(now time.Time, window time.Duration) {
+	if now.Sub(l.lastSweep) < window {
+		return
+	}
+	for key, w := range l.windows {
+		if !now.Before(w.reset) {
+			delete(l.windows, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// newResult builds the Result of the count-th request of a window ending at reset
+func newResult(count, limit int, reset time.Time) Result {
+	remaining := limit - count
+	if remaining < 0 {
+		remaining = 0
+	}
+	return Result{
+		Allowed:   count <= limit,
+		Limit:     limit,
+		Remaining: remaining,
+		Reset:     reset,
+	}
+}
@@ -0,0 +1,56 @@
+package ratelimit
+
+import (
+	"math"
+	"time"
+
+	"github.com/danizion/contact-app/internal/storage/redis"
+)
+
+// RedisLimiter is a Limiter keeping its counters in Redis, so every instance of the application shares them. It
+// counts over a sliding window: the requests of the previous fixed window count in proportion to how much of it
+// still overlaps the last window, so a client cannot send twice the limit around a window boundary
+type RedisLimiter struct {
+	redis *redis.Redis
+	now   func() time.Time
+}
+
+// NewRedisLimiter creates a RedisLimiter storing its counters with redisClient
+func NewRedisLimiter(redisClient *redis.Redis) *RedisLimiter {
+	return &RedisLimiter{
+		redis: redisClient,
+		now:   time.Now,
+	}
+}
+
+// Allow implements Limiter, rejected requests count too so a client hammering the API stays limited
+func (l *RedisLimiter) Allow(key string, limit int, window time.Duration) (Result, error) {
+	now := l.now()
+	start := now.Truncate(window)
+	current, previous, err := l.redis.CountRequest(key, start, window)
+	if err != nil {
+		return Result{}, err
+	}
+
+	overlap := 1 - float64(now.Sub(start))/float64(window)
+	count := int(current) + int(float64(previous)*overlap)
+	result := newResult(count, limit, start.Add(window))
+	if !result.Allowed {
+		result.Reset = allowedAt(start, window, int(current), int(previous), limit)
+	}
+	return result, nil
+}
+
+// allowedAt returns when a request fits in the limit again if none is sent meanwhile: later in this window once
+// enough of the previous window slid out, in the next window once enough of this one did otherwise
+func allowedAt(start time.Time, window time.Duration, current, previous, limit int) time.Time {
+	if current < limit && previous > 0 {
+		elapsed := 1 - float64(limit-current-1)/float64(previous)
+		return start.Add(time.Duration(elapsed * float64(window)))
+	}
+	elapsed := 0.0
+	if current > 0 {
+		elapsed = math.Max(0, 1-float64(limit-1)/float64(current))
+	}
+	return start.Add(window + time.Duration(elapsed*float64(window)))
+}
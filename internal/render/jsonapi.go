@@ -0,0 +1,127 @@
+package render
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MIMEJSONAPI is the media type of JSON:API documents
+const MIMEJSONAPI = "application/vnd.api+json"
+
+// Document is a JSON:API top level document, Data is a Resource or a slice of them
+type Document struct {
+	JSONAPI  map[string]string `json:"jsonapi"`
+	Data     interface{}       `json:"data"`
+	Included []Resource        `json:"included,omitempty"`
+	Meta     interface{}       `json:"meta,omitempty"`
+	Links    map[string]string `json:"links,omitempty"`
+}
+
+// Resource is a JSON:API resource object
+type Resource struct {
+	Type          string                  `json:"type"`
+	ID            string                  `json:"id"`
+	Attributes    map[string]interface{}  `json:"attributes,omitempty"`
+	Relationships map[string]Relationship `json:"relationships,omitempty"`
+	Links         map[string]string       `json:"links,omitempty"`
+}
+
+// Relationship is a to-many JSON:API relationship
+type Relationship struct {
+	Data []ResourceIdentifier `json:"data"`
+}
+
+// ResourceIdentifier references a resource by type and ID
+type ResourceIdentifier struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// WantsJSONAPI reports whether the client opted in to JSON:API documents, with ?format=jsonapi or by
+// accepting application/vnd.api+json
+func WantsJSONAPI(c *gin.Context) bool {
+	return c.Query("format") == "jsonapi" || strings.Contains(c.GetHeader("Accept"), MIMEJSONAPI)
+}
+
+// JSONAPI writes a JSON:API document
+func JSONAPI(c *gin.Context, status int, document Document) {
+	document.JSONAPI = map[string]string{"version": "1.1"}
+	c.Render(status, jsonAPIRender{document})
+}
+
+type jsonAPIRender struct {
+	document Document
+}
+
+func (r jsonAPIRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	// links carry query strings, keep their & readable
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(r.document)
+}
+
+func (r jsonAPIRender) WriteContentType(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", MIMEJSONAPI)
+}
+
+// NewResource builds a resource whose attributes are the JSON fields of value, without the id and type members
+// reserved by JSON:API
+func NewResource(resourceType string, id int, value interface{}) (Resource, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return Resource{}, err
+	}
+	var attributes map[string]interface{}
+	if err := json.Unmarshal(encoded, &attributes); err != nil {
+		return Resource{}, err
+	}
+	delete(attributes, "id")
+	delete(attributes, "type")
+
+	return Resource{Type: resourceType, ID: strconv.Itoa(id), Attributes: attributes}, nil
+}
+
+// PageLinks returns the self, first, last, prev and next links of page out of totalPages, keeping the other
+// query parameters of the request
+func PageLinks(c *gin.Context, page, totalPages int) map[string]string {
+	link := func(page int) string {
+		query := c.Request.URL.Query()
+		query.Set("page", strconv.Itoa(page))
+		return (&url.URL{Path: c.Request.URL.Path, RawQuery: query.Encode()}).String()
+	}
+
+	last := totalPages
+	if last < 1 {
+		last = 1
+	}
+	links := map[string]string{
+		"self":  link(page),
+		"first": link(1),
+		"last":  link(last),
+	}
+	if page > 1 {
+		links["prev"] = link(page - 1)
+	}
+	if page < totalPages {
+		links["next"] = link(page + 1)
+	}
+	return links
+}
+
+// CursorLinks returns the self and next links of a page selected by cursor, there is no next link on the last page
+func CursorLinks(c *gin.Context, nextCursor string) map[string]string {
+	links := map[string]string{"self": c.Request.URL.RequestURI()}
+	if nextCursor != "" {
+		query := c.Request.URL.Query()
+		query.Set("cursor", nextCursor)
+		query.Del("page")
+		links["next"] = (&url.URL{Path: c.Request.URL.Path, RawQuery: query.Encode()}).String()
+	}
+	return links
+}
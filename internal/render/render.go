@@ -0,0 +1,134 @@
+// Package render encodes responses in the format negotiated from the Accept header. JSON is the default, XML is
+// derived from the JSON encoding so both always carry the same fields under the same names, and CSV is offered
+// for tabular data
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/danizion/contact-app/internal/export"
+	"github.com/gin-gonic/gin"
+)
+
+// Negotiable content types, JSON first as it is the default
+const (
+	MIMEJSON    = "application/json"
+	MIMEXML     = "application/xml"
+	MIMETextXML = "text/xml"
+	MIMECSV     = "text/csv"
+)
+
+// ErrNotAcceptable is returned when the client accepts none of the formats of a response
+const ErrNotAcceptable = "Not acceptable, supported formats are application/json, application/xml and text/csv"
+
+// Table is the CSV rendering of a response
+type Table struct {
+	Header []string
+	Rows   [][]string
+}
+
+// Respond writes data with status in the format accepted by the client: root names the XML document element and
+// table builds the CSV rendering. Clients accepting none of the formats get 406
+func Respond(c *gin.Context, status int, root string, data interface{}, table func() Table) {
+	switch c.NegotiateFormat(MIMEJSON, MIMEXML, MIMETextXML, MIMECSV) {
+	case MIMEJSON:
+		c.JSON(status, data)
+	case MIMEXML, MIMETextXML:
+		body, err := XML(root, data)
+		if err != nil {
+			slog.Error("Failed to encode XML response", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode response"})
+			return
+		}
+		c.Data(status, MIMEXML+"; charset=utf-8", body)
+	case MIMECSV:
+		writeCSV(c, status, table())
+	default:
+		c.JSON(http.StatusNotAcceptable, gin.H{"error": ErrNotAcceptable})
+	}
+}
+
+func writeCSV(c *gin.Context, status int, table Table) {
+	c.Header("Content-Type", MIMECSV+"; charset=utf-8")
+	c.Status(status)
+
+	writer := export.NewCSVWriter(c.Writer)
+	if err := writer.Write(table.Header); err != nil {
+		slog.Error("Failed to write CSV response", "error", err)
+		return
+	}
+	for _, row := range table.Rows {
+		if err := writer.Write(row); err != nil {
+			slog.Error("Failed to write CSV response", "error", err)
+			return
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		slog.Error("Failed to write CSV response", "error", err)
+	}
+}
+
+// XML encodes data as an XML document named root. Elements are named after the JSON keys of data, array entries
+// become <item> elements and null values are left out
+func XML(root string, data interface{}) ([]byte, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+
+	var out bytes.Buffer
+	out.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&out)
+	if err := writeXMLValue(encoder, decoder, root); err != nil {
+		return nil, err
+	}
+	if err := encoder.Flush(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// writeXMLValue converts the next JSON value of decoder to an element named name
+func writeXMLValue(encoder *xml.Encoder, decoder *json.Decoder, name string) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+
+	switch value := token.(type) {
+	case nil:
+		return nil
+	case json.Delim:
+		if err := encoder.EncodeToken(start); err != nil {
+			return err
+		}
+		for decoder.More() {
+			childName := "item"
+			if value == '{' {
+				key, err := decoder.Token()
+				if err != nil {
+					return err
+				}
+				childName = key.(string)
+			}
+			if err := writeXMLValue(encoder, decoder, childName); err != nil {
+				return err
+			}
+		}
+		// consume the closing delimiter
+		if _, err := decoder.Token(); err != nil {
+			return err
+		}
+		return encoder.EncodeToken(start.End())
+	default:
+		return encoder.EncodeElement(fmt.Sprint(value), start)
+	}
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"database/sql"
+	"log"
+	"time"
+)
+
+// GetAccountState returns the state of the account of a user, empty when the user does not exist
+func (r *Repository) GetAccountState(userID int) (string, error) {
+	var state string
+	err := r.db.Get(&state, `SELECT state FROM users WHERE id = $1`, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		log.Printf("Error fetching account state: %v", err)
+		return "", err
+	}
+	return state, nil
+}
+
+// SetAccountState moves the account of a user from state from to state to, it returns false when the account was not
+// in state from anymore so concurrent changes cannot both apply. deactivated_at is set while the account is not active
+func (r *Repository) SetAccountState(userID int, from, to string) (bool, error) {
+	query := `UPDATE users SET state = $3,
+				deactivated_at = CASE WHEN $3 = 'active' THEN NULL ELSE COALESCE(deactivated_at, NOW()) END,
+				updated_at = NOW()
+			  WHERE id = $1 AND state = $2`
+	result, err := r.db.Exec(query, userID, from, to)
+	if err != nil {
+		log.Printf("Error changing account state: %v", err)
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("Error getting rows affected: %v", err)
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// SaveReactivation stores the reactivation link of a user, replacing the previous one
+func (r *Repository) SaveReactivation(userID int, tokenHash string, expiresAt time.Time) error {
+	query := `INSERT INTO account_reactivations (user_id, token_hash, expires_at) VALUES ($1, $2, $3)
+			  ON CONFLICT (user_id) DO UPDATE SET token_hash = EXCLUDED.token_hash, created_at = NOW(),
+			  expires_at = EXCLUDED.expires_at`
+	_, err := r.db.Exec(query, userID, tokenHash, expiresAt)
+	if err != nil {
+		log.Printf("Error saving reactivation: %v", err)
+		return err
+	}
+	return nil
+}
+
+// ClaimReactivation consumes the reactivation link of a token and returns its user, 0 when the link is unknown or
+// expired. A link works once
+func (r *Repository) ClaimReactivation(tokenHash string) (int, error) {
+	var userID int
+	err := r.db.Get(&userID, `DELETE FROM account_reactivations WHERE token_hash = $1 AND expires_at > NOW() RETURNING user_id`, tokenHash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		log.Printf("Error claiming reactivation: %v", err)
+		return 0, err
+	}
+	return userID, nil
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"log"
+
+	"github.com/danizion/contact-app/internal/models"
+)
+
+// CreateAnalyticsEvents inserts a batch of analytics events in one statement
+func (r *Repository) CreateAnalyticsEvents(events []models.AnalyticsEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	query := `INSERT INTO analytics_events (name, actor, properties, occurred_at)
+			  VALUES (:name, :actor, :properties, :occurred_at)`
+	_, err := r.db.NamedExec(query, events)
+	if err != nil {
+		log.Printf("Error creating analytics events: %v", err)
+		return err
+	}
+	return nil
+}
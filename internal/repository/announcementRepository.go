@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"log"
+	"time"
+
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/lib/pq"
+)
+
+const announcementColumns = `id, message, level, starts_at, ends_at, created_by, created_at`
+
+// CreateAnnouncement stores an announcement and returns it with its ID
+func (r *Repository) CreateAnnouncement(announcement models.Announcement) (*models.Announcement, error) {
+	query := `INSERT INTO announcements (message, level, starts_at, ends_at, created_by)
+			  VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`
+	err := r.db.QueryRow(query, announcement.Message, announcement.Level, announcement.StartsAt, announcement.EndsAt, announcement.CreatedBy).
+		Scan(&announcement.ID, &announcement.CreatedAt)
+	if err != nil {
+		log.Printf("Error creating announcement: %v", err)
+		return nil, err
+	}
+	return &announcement, nil
+}
+
+// GetAnnouncements returns every announcement, the latest first
+func (r *Repository) GetAnnouncements() ([]models.Announcement, error) {
+	query := `SELECT ` + announcementColumns + ` FROM announcements ORDER BY starts_at DESC, id DESC`
+	var announcements []models.Announcement
+	err := r.db.Select(&announcements, query)
+	if err != nil {
+		log.Printf("Error fetching announcements: %v", err)
+		return nil, err
+	}
+	return announcements, nil
+}
+
+// GetUnexpiredAnnouncements returns the announcements not ended at now, started or scheduled, the latest first
+func (r *Repository) GetUnexpiredAnnouncements(now time.Time) ([]models.Announcement, error) {
+	query := `SELECT ` + announcementColumns + ` FROM announcements
+			  WHERE ends_at IS NULL OR ends_at > $1 ORDER BY starts_at DESC, id DESC`
+	var announcements []models.Announcement
+	err := r.db.Select(&announcements, query, now)
+	if err != nil {
+		log.Printf("Error fetching unexpired announcements: %v", err)
+		return nil, err
+	}
+	return announcements, nil
+}
+
+// DeleteAnnouncement deletes an announcement with its dismissals, it returns false when it does not exist
+func (r *Repository) DeleteAnnouncement(id int) (bool, error) {
+	result, err := r.db.Exec(`DELETE FROM announcements WHERE id = $1`, id)
+	if err != nil {
+		log.Printf("Error deleting announcement: %v", err)
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("Error getting rows affected: %v", err)
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// DismissAnnouncement records that a user dismissed an announcement, dismissing it again changes nothing
+func (r *Repository) DismissAnnouncement(userID, announcementID int) error {
+	query := `INSERT INTO announcement_dismissals (user_id, announcement_id) VALUES ($1, $2)
+			  ON CONFLICT (user_id, announcement_id) DO NOTHING`
+	_, err := r.db.Exec(query, userID, announcementID)
+	if err != nil {
+		log.Printf("Error dismissing announcement: %v", err)
+		return err
+	}
+	return nil
+}
+
+// GetDismissedAnnouncements returns which of the announcements ids a user dismissed
+func (r *Repository) GetDismissedAnnouncements(userID int, ids []int) (map[int]bool, error) {
+	query := `SELECT announcement_id FROM announcement_dismissals WHERE user_id = $1 AND announcement_id = ANY($2)`
+	var dismissedIDs []int
+	err := r.db.Select(&dismissedIDs, query, userID, pq.Array(ids))
+	if err != nil {
+		log.Printf("Error fetching dismissed announcements: %v", err)
+		return nil, err
+	}
+	dismissed := make(map[int]bool, len(dismissedIDs))
+	for _, id := range dismissedIDs {
+		dismissed[id] = true
+	}
+	return dismissed, nil
+}
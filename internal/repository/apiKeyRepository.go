@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/danizion/contact-app/internal/models"
+)
+
+// CreateAPIKey inserts a new API key into the "api_keys" table
+func (r *Repository) CreateAPIKey(key models.APIKey) (int, error) {
+	query := `INSERT INTO api_keys (user_id, name, key_id, secret) VALUES ($1, $2, $3, $4) RETURNING id`
+	var id int
+	err := r.db.QueryRow(query, key.UserID, key.Name, key.KeyID, key.Secret).Scan(&id)
+	if err != nil {
+		log.Printf("Error creating API key: %v", err)
+		return 0, err
+	}
+	return id, nil
+}
+
+// GetAPIKeysByUser retrieves the API keys of a user in creation order
+func (r *Repository) GetAPIKeysByUser(userID int) ([]models.APIKey, error) {
+	query := `SELECT id, user_id, name, key_id, secret, created_at FROM api_keys WHERE user_id = $1 ORDER BY id`
+	var keys []models.APIKey
+	err := r.db.Select(&keys, query, userID)
+	if err != nil {
+		log.Printf("Error fetching API keys: %v", err)
+		return nil, err
+	}
+	return keys, nil
+}
+
+// CountAPIKeysByUser counts the API keys of a user
+func (r *Repository) CountAPIKeysByUser(userID int) (int, error) {
+	var count int
+	err := r.db.Get(&count, `SELECT COUNT(*) FROM api_keys WHERE user_id = $1`, userID)
+	if err != nil {
+		log.Printf("Error counting API keys: %v", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetAPIKeyByKeyID retrieves an API key by its public key ID, returns nil if it does not exist
+func (r *Repository) GetAPIKeyByKeyID(keyID string) (*models.APIKey, error) {
+	query := `SELECT id, user_id, name, key_id, secret, created_at FROM api_keys WHERE key_id = $1`
+	var key models.APIKey
+	err := r.db.Get(&key, query, keyID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Printf("Error fetching API key: %v", err)
+		return nil, err
+	}
+	return &key, nil
+}
+
+// DeleteAPIKey removes an API key
+func (r *Repository) DeleteAPIKey(userID, id int) error {
+	result, err := r.db.Exec(`DELETE FROM api_keys WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		log.Printf("Error deleting API key: %v", err)
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("API key %w or does not belong to the specified user", ErrNotFound)
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"database/sql"
+	"log"
+
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+// ArchivedContact is a contact of an account archive with the names of its groups and tags and its social profiles
+type ArchivedContact struct {
+	Contact        models.Contact
+	Groups         []string
+	Tags           []string
+	SocialProfiles []models.SocialProfile
+}
+
+// AccountImport is what ImportAccount added to the account, SkippedIndexes are the positions of the skipped contacts
+type AccountImport struct {
+	ContactIDs     []int
+	Skipped        int
+	SkippedIndexes []int
+	GroupsCreated  int
+	TagsCreated    int
+}
+
+// ImportAccount adds the groups, tags, contacts and preferences of an archive to a user in one transaction.
+// Groups and tags are matched by name, contacts whose name is already taken are skipped and prefs is saved when not nil
+func (r *Repository) ImportAccount(userID int, groups, tags []string, contacts []ArchivedContact, prefs *models.UserPreferences) (*AccountImport, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		log.Printf("Error starting import transaction: %v", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result := &AccountImport{}
+	groupIDs := make(map[string]int, len(groups))
+	for _, name := range groups {
+		id, created, err := getOrCreateNamed(tx, "groups", userID, name)
+		if err != nil {
+			log.Printf("Error importing group %q: %v", name, err)
+			return nil, err
+		}
+		groupIDs[name] = id
+		if created {
+			result.GroupsCreated++
+		}
+	}
+	tagIDs := make(map[string]int, len(tags))
+	for _, name := range tags {
+		id, created, err := getOrCreateNamed(tx, "tags", userID, name)
+		if err != nil {
+			log.Printf("Error importing tag %q: %v", name, err)
+			return nil, err
+		}
+		tagIDs[name] = id
+		if created {
+			result.TagsCreated++
+		}
+	}
+
+	for i, archived := range contacts {
+		contact := archived.Contact
+		var exists bool
+		err := tx.Get(&exists, `SELECT EXISTS (SELECT 1 FROM contacts WHERE user_id = $1 AND first_name = $2 AND last_name = $3 AND deleted_at IS NULL)`,
+			userID, contact.FirstName, contact.LastName)
+		if err != nil {
+			log.Printf("Error checking existing contact: %v", err)
+			return nil, err
+		}
+		if exists {
+			result.Skipped++
+			result.SkippedIndexes = append(result.SkippedIndexes, i)
+			continue
+		}
+
+		var contactID int
+		err = tx.QueryRow(`INSERT INTO contacts (user_id, first_name, last_name, phone_number, address, email, company, job_title, timezone,
+								   street, city, region, postal_code, country_code, source, stage, latitude, longitude,
+								   phone_normalized, board_position)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19,
+					  (SELECT COALESCE(MAX(board_position), 0) + 1 FROM contacts WHERE user_id = $1 AND stage = $16))
+			  RETURNING id`,
+			userID, contact.FirstName, contact.LastName, contact.PhoneNumber, contact.Address,
+			contact.Email, contact.Company, contact.JobTitle, contact.Timezone,
+			contact.Street, contact.City, contact.Region, contact.PostalCode, contact.CountryCode,
+			contact.Source, contact.Stage, contact.Latitude, contact.Longitude, contact.PhoneNormalized).Scan(&contactID)
+		if err != nil {
+			log.Printf("Error importing contact: %v", err)
+			return nil, err
+		}
+		if err := recordContactAudit(tx, userID, contactID, ContactAuditCreated, contactChanges(nil, &contact)); err != nil {
+			return nil, err
+		}
+		result.ContactIDs = append(result.ContactIDs, contactID)
+
+		for _, name := range archived.Groups {
+			_, err = tx.Exec(`INSERT INTO contact_groups (contact_id, group_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, contactID, groupIDs[name])
+			if err != nil {
+				log.Printf("Error importing group membership: %v", err)
+				return nil, err
+			}
+		}
+		for _, name := range archived.Tags {
+			_, err = tx.Exec(`INSERT INTO contact_tags (contact_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, contactID, tagIDs[name])
+			if err != nil {
+				log.Printf("Error importing contact tag: %v", err)
+				return nil, err
+			}
+		}
+		for _, profile := range archived.SocialProfiles {
+			_, err = tx.Exec(`INSERT INTO contact_social_profiles (contact_id, network, handle, url) VALUES ($1, $2, $3, $4)
+					  ON CONFLICT (contact_id, network) DO NOTHING`, contactID, profile.Network, profile.Handle, profile.URL)
+			if err != nil {
+				log.Printf("Error importing social profile: %v", err)
+				return nil, err
+			}
+		}
+	}
+
+	if prefs != nil {
+		_, err = tx.Exec(`INSERT INTO user_preferences (user_id, weekly_digest, region, search_language, duplicate_sensitivity)
+			  VALUES ($1, $2, $3, $4, $5)
+			  ON CONFLICT (user_id) DO UPDATE SET weekly_digest = EXCLUDED.weekly_digest, region = EXCLUDED.region,
+			  search_language = EXCLUDED.search_language, duplicate_sensitivity = EXCLUDED.duplicate_sensitivity,
+			  updated_at = NOW()`, userID, prefs.WeeklyDigest, prefs.Region, prefs.SearchLanguage, prefs.DuplicateSensitivity)
+		if err != nil {
+			log.Printf("Error importing preferences: %v", err)
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing import: %v", err)
+		return nil, err
+	}
+	return result, nil
+}
+
+// ImportContacts adds contacts to a user in one transaction, contacts whose name is already taken are skipped
+func (r *Repository) ImportContacts(userID int, contacts []ArchivedContact) (*AccountImport, error) {
+	return r.ImportAccount(userID, nil, nil, contacts, nil)
+}
+
+// getOrCreateNamed returns the ID of the group or tag of a user with this name, creating it when missing.
+// table is "groups" or "tags", never user input
+func getOrCreateNamed(tx *sqlx.Tx, table string, userID int, name string) (int, bool, error) {
+	var id int
+	err := tx.QueryRow(`INSERT INTO `+table+` (user_id, name) VALUES ($1, $2) ON CONFLICT (user_id, name) DO NOTHING RETURNING id`,
+		userID, name).Scan(&id)
+	if err == nil {
+		return id, true, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, false, err
+	}
+	err = tx.Get(&id, `SELECT id FROM `+table+` WHERE user_id = $1 AND name = $2`, userID, name)
+	return id, false, err
+}
+
+// ClearAccountData deletes everything a user owns except the user itself in one transaction: contacts (with their
+// attachments records, memberships and profiles), groups, tags, snapshots, webhooks, API keys, preferences and audit log
+func (r *Repository) ClearAccountData(userID int) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		log.Printf("Error starting account clear transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, table := range []string{"contacts", "groups", "tags", "contact_snapshots", "webhooks", "api_keys", "user_preferences", "audit_log"} {
+		if _, err := tx.Exec(`DELETE FROM `+table+` WHERE user_id = $1`, userID); err != nil {
+			log.Printf("Error clearing %s of user %d: %v", table, userID, err)
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/danizion/contact-app/internal/models"
+)
+
+// IsContactOwnedByUser checks if a contact exists and belongs to the specified user
+func (r *Repository) IsContactOwnedByUser(userID, contactID int) (bool, error) {
+	query := `SELECT COUNT(*) FROM contacts WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`
+	var count int
+	err := r.db.Get(&count, query, contactID, userID)
+	if err != nil {
+		log.Printf("Error checking contact ownership: %v", err)
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// ErrStorageQuotaExceeded is returned by CreateAttachment when the attachment does not fit in the quota of its user
+var ErrStorageQuotaExceeded = errors.New("storage quota exceeded")
+
+// CreateAttachment inserts a new attachment into the "attachments" table when the attachments of its user, this one
+// included, fit in quotaBytes. The user row is locked so concurrent uploads of the user are checked one at a time
+func (r *Repository) CreateAttachment(attachment models.Attachment, quotaBytes int64) (int, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		log.Printf("Error starting attachment transaction: %v", err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// The usage is read by a statement started once the lock is held, so it sees the upload that held it before
+	var lockedID int
+	if err := tx.Get(&lockedID, `SELECT id FROM users WHERE id = $1 FOR UPDATE`, attachment.UserID); err != nil {
+		log.Printf("Error locking user: %v", err)
+		return 0, err
+	}
+	var used int64
+	err = tx.Get(&used, `SELECT COALESCE((SELECT attachment_bytes FROM user_counts WHERE user_id = $1), 0)`, attachment.UserID)
+	if err != nil {
+		log.Printf("Error fetching storage usage: %v", err)
+		return 0, err
+	}
+	if used+attachment.SizeBytes > quotaBytes {
+		return 0, ErrStorageQuotaExceeded
+	}
+
+	query := `INSERT INTO attachments (contact_id, user_id, file_name, content_type, size_bytes, storage_key)
+			  VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+	var attachmentID int
+	err = tx.QueryRow(query, attachment.ContactID, attachment.UserID, attachment.FileName,
+		attachment.ContentType, attachment.SizeBytes, attachment.StorageKey).Scan(&attachmentID)
+	if err != nil {
+		log.Printf("Error creating attachment: %v", err)
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing attachment: %v", err)
+		return 0, err
+	}
+	return attachmentID, nil
+}
+
+// GetAttachmentsByContact retrieves all attachments of a user's contact
+func (r *Repository) GetAttachmentsByContact(userID, contactID int) ([]models.Attachment, error) {
+	query := `SELECT id, contact_id, user_id, file_name, content_type, size_bytes, storage_key, created_at
+			  FROM attachments WHERE contact_id = $1 AND user_id = $2 ORDER BY id`
+	var attachments []models.Attachment
+	err := r.db.Select(&attachments, query, contactID, userID)
+	if err != nil {
+		log.Printf("Error fetching attachments: %v", err)
+		return nil, err
+	}
+	return attachments, nil
+}
+
+// GetAttachment retrieves a single attachment by ID, returns nil when it does not exist
+func (r *Repository) GetAttachment(attachmentID int) (*models.Attachment, error) {
+	query := `SELECT id, contact_id, user_id, file_name, content_type, size_bytes, storage_key, created_at
+			  FROM attachments WHERE id = $1`
+	var attachment models.Attachment
+	err := r.db.Get(&attachment, query, attachmentID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Printf("Error fetching attachment: %v", err)
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// DeleteAttachment deletes an attachment of a user's contact
+func (r *Repository) DeleteAttachment(userID, contactID, attachmentID int) error {
+	query := `DELETE FROM attachments WHERE id = $1 AND contact_id = $2 AND user_id = $3`
+	result, err := r.db.Exec(query, attachmentID, contactID, userID)
+	if err != nil {
+		log.Printf("Error deleting attachment: %v", err)
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("attachment %w", ErrNotFound)
+	}
+	return nil
+}
+
+// GetAttachmentKeysByUser returns the storage keys of the content of every attachment of a user
+func (r *Repository) GetAttachmentKeysByUser(userID int) ([]string, error) {
+	var keys []string
+	err := r.db.Select(&keys, `SELECT storage_key FROM attachments WHERE user_id = $1`, userID)
+	if err != nil {
+		log.Printf("Error fetching attachment keys: %v", err)
+		return nil, err
+	}
+	return keys, nil
+}
+
+// GetUserStorageUsage returns the total size in bytes of all attachments stored by a user, kept up to date by triggers
+func (r *Repository) GetUserStorageUsage(userID int) (int64, error) {
+	query := `SELECT COALESCE((SELECT attachment_bytes FROM user_counts WHERE user_id = $1), 0)`
+	var used int64
+	err := r.db.Get(&used, query, userID)
+	if err != nil {
+		log.Printf("Error fetching storage usage: %v", err)
+		return 0, err
+	}
+	return used, nil
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/lib/pq"
+)
+
+// AuditFilter selects audit entries, zero values disable a filter
+type AuditFilter struct {
+	UserID  int
+	ActorID int
+	Action  string
+	From    time.Time
+	To      time.Time
+}
+
+// CreateAuditEntry appends an entry to the audit log
+func (r *Repository) CreateAuditEntry(entry models.AuditEntry) error {
+	query := `INSERT INTO audit_log (user_id, actor_id, action, entity_type, entity_id, details)
+			  VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err := r.db.Exec(query, entry.UserID, entry.ActorID, entry.Action, entry.EntityType, entry.EntityID, entry.Details)
+	if err != nil {
+		log.Printf("Error creating audit entry: %v", err)
+		return err
+	}
+	return nil
+}
+
+// StreamAuditEntries calls fn for every audit entry matching the filter in chronological order,
+// rows are read one at a time so exports of any size run in constant memory
+func (r *Repository) StreamAuditEntries(filter AuditFilter, fn func(models.AuditEntry) error) error {
+	params := []interface{}{}
+	query := `SELECT id, user_id, actor_id, action, entity_type, entity_id, details, created_at FROM audit_log WHERE TRUE`
+
+	if filter.UserID != 0 {
+		params = append(params, filter.UserID)
+		query += fmt.Sprintf(" AND user_id = $%d", len(params))
+	}
+	if filter.ActorID != 0 {
+		params = append(params, filter.ActorID)
+		query += fmt.Sprintf(" AND actor_id = $%d", len(params))
+	}
+	if filter.Action != "" {
+		params = append(params, filter.Action)
+		query += fmt.Sprintf(" AND action = $%d", len(params))
+	}
+	if !filter.From.IsZero() {
+		params = append(params, filter.From)
+		query += fmt.Sprintf(" AND created_at >= $%d", len(params))
+	}
+	if !filter.To.IsZero() {
+		params = append(params, filter.To)
+		query += fmt.Sprintf(" AND created_at < $%d", len(params))
+	}
+	query += " ORDER BY created_at, id"
+
+	rows, err := r.db.Queryx(query, params...)
+	if err != nil {
+		log.Printf("Error fetching audit entries: %v", err)
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry models.AuditEntry
+		if err := rows.StructScan(&entry); err != nil {
+			log.Printf("Error scanning audit entry: %v", err)
+			return err
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// GetAuditEntriesAfter retrieves up to limit audit entries of a user with one of actions and an ID above afterID,
+// oldest first
+func (r *Repository) GetAuditEntriesAfter(userID int, afterID int64, actions []string, limit int) ([]models.AuditEntry, error) {
+	query := `SELECT id, user_id, actor_id, action, entity_type, entity_id, details, created_at FROM audit_log
+			  WHERE user_id = $1 AND id > $2 AND action = ANY($3) ORDER BY id LIMIT $4`
+	var entries []models.AuditEntry
+	err := r.db.Select(&entries, query, userID, afterID, pq.Array(actions), limit)
+	if err != nil {
+		log.Printf("Error fetching audit entries: %v", err)
+		return nil, err
+	}
+	return entries, nil
+}
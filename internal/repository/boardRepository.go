@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/danizion/contact-app/internal/models"
+)
+
+// GetContactsByFieldValuePaginated retrieves one board column: a user's contacts whose picklist field equals value, ordered by board position
+func (r *Repository) GetContactsByFieldValuePaginated(userID int, field, value string, page, pageSize int) ([]models.Contact, int, error) {
+	// field is interpolated into the query so only known picklist columns are accepted
+	if field != "source" && field != "stage" {
+		return nil, 0, fmt.Errorf("unknown picklist field %s", field)
+	}
+
+	offset := (page - 1) * pageSize
+	baseQuery := fmt.Sprintf(`FROM contacts WHERE user_id = $1 AND %s = $2 AND deleted_at IS NULL`, field)
+
+	var total int
+	err := r.db.Get(&total, `SELECT COUNT(*) `+baseQuery, userID, value)
+	if err != nil {
+		log.Printf("Error counting board column contacts: %v", err)
+		return nil, 0, err
+	}
+
+	limitOffset := fmt.Sprintf("%s LIMIT %d OFFSET %d", orderByWithID([]string{"board_position"}, "ASC"), pageSize, offset)
+	query := `SELECT ` + contactColumns + ` ` + baseQuery + limitOffset
+	var contacts []models.Contact
+	err = r.db.Select(&contacts, query, userID, value)
+	if err != nil {
+		log.Printf("Error fetching board column contacts: %v", err)
+		return nil, 0, err
+	}
+
+	return contacts, total, nil
+}
+
+// MoveContactToStage sets the stage of a contact and places it at position (1-based) inside the target column,
+// a position of 0 or past the end of the column appends the contact
+func (r *Repository) MoveContactToStage(userID, contactID int, stage string, position int) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		log.Printf("Error starting move transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	// Lock the contact row and verify it belongs to the user
+	var current string
+	err = tx.Get(&current, `SELECT stage FROM contacts WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL FOR UPDATE`, contactID, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrContactNotFound
+		}
+		log.Printf("Error checking contact ownership: %v", err)
+		return err
+	}
+
+	// Renumber the target column without the moved contact so positions are 1..n
+	renumberQuery := `UPDATE contacts c SET board_position = ranked.rn
+					  FROM (SELECT id, ROW_NUMBER() OVER (ORDER BY board_position, id) AS rn
+							FROM contacts WHERE user_id = $1 AND stage = $2 AND id <> $3 AND deleted_at IS NULL) ranked
+					  WHERE c.id = ranked.id`
+	result, err := tx.Exec(renumberQuery, userID, stage, contactID)
+	if err != nil {
+		log.Printf("Error renumbering board column: %v", err)
+		return err
+	}
+	columnSize, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if position <= 0 || position > int(columnSize)+1 {
+		position = int(columnSize) + 1
+	}
+
+	// Make room for the moved contact
+	_, err = tx.Exec(`UPDATE contacts SET board_position = board_position + 1
+					  WHERE user_id = $1 AND stage = $2 AND id <> $3 AND board_position >= $4
+					  AND deleted_at IS NULL`, userID, stage, contactID, position)
+	if err != nil {
+		log.Printf("Error shifting board column: %v", err)
+		return err
+	}
+
+	_, err = tx.Exec(`UPDATE contacts SET stage = $1, board_position = $2, updated_at = NOW()
+					  WHERE id = $3 AND user_id = $4`, stage, position, contactID, userID)
+	if err != nil {
+		log.Printf("Error moving contact: %v", err)
+		return err
+	}
+	if current != stage {
+		changes := map[string]ContactFieldChange{"stage": {From: current, To: stage}}
+		if err := recordContactAudit(tx, userID, contactID, ContactAuditUpdated, changes); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/danizion/contact-app/internal/models"
+)
+
+// completenessColumns are the columns a contact can be missing, the address column is nullable
+var completenessColumns = map[string]bool{
+	"phone_number": true,
+	"email":        true,
+	"address":      true,
+	"company":      true,
+	"job_title":    true,
+	"timezone":     true,
+}
+
+// GetIncompleteContactsPaginated retrieves a user's contacts missing any of the fields, ordered from the least filled
+// in of these fields to the most, then by ID
+func (r *Repository) GetIncompleteContactsPaginated(userID int, fields []string, page, pageSize int) ([]models.Contact, int, error) {
+	// fields are interpolated into the query so only known columns are accepted
+	if len(fields) == 0 {
+		return nil, 0, fmt.Errorf("no completeness field given")
+	}
+	missing := make([]string, len(fields))
+	filled := make([]string, len(fields))
+	for i, field := range fields {
+		if !completenessColumns[field] {
+			return nil, 0, fmt.Errorf("unknown completeness field %s", field)
+		}
+		missing[i] = fmt.Sprintf("TRIM(COALESCE(%s, '')) = ''", field)
+		filled[i] = fmt.Sprintf("(CASE WHEN TRIM(COALESCE(%s, '')) = '' THEN 0 ELSE 1 END)", field)
+	}
+
+	offset := (page - 1) * pageSize
+	baseQuery := `FROM contacts WHERE user_id = $1 AND deleted_at IS NULL AND (` + strings.Join(missing, " OR ") + `)`
+
+	var total int
+	err := r.db.Get(&total, `SELECT COUNT(*) `+baseQuery, userID)
+	if err != nil {
+		log.Printf("Error counting incomplete contacts: %v", err)
+		return nil, 0, err
+	}
+
+	order := fmt.Sprintf("%s LIMIT %d OFFSET %d", orderByWithID([]string{"(" + strings.Join(filled, " + ") + ")"}, "ASC"), pageSize, offset)
+	query := `SELECT ` + contactColumns + ` ` + baseQuery + order
+	var contacts []models.Contact
+	err = r.db.Select(&contacts, query, userID)
+	if err != nil {
+		log.Printf("Error fetching incomplete contacts: %v", err)
+		return nil, 0, err
+	}
+
+	return contacts, total, nil
+}
@@ -0,0 +1,301 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Actions of the contact history
+const (
+	ContactAuditCreated  = "created"
+	ContactAuditUpdated  = "updated"
+	ContactAuditDeleted  = "deleted"
+	ContactAuditRestored = "restored"
+)
+
+// auditedContactFields are the fields of a contact whose changes are recorded in its history
+var auditedContactFields = []struct {
+	name  string
+	value func(*models.Contact) interface{}
+}{
+	{"first_name", func(c *models.Contact) interface{} { return c.FirstName }},
+	{"last_name", func(c *models.Contact) interface{} { return c.LastName }},
+	{"phone_number", func(c *models.Contact) interface{} { return c.PhoneNumber }},
+	{"address", func(c *models.Contact) interface{} { return c.Address }},
+	{"email", func(c *models.Contact) interface{} { return c.Email }},
+	{"company", func(c *models.Contact) interface{} { return c.Company }},
+	{"job_title", func(c *models.Contact) interface{} { return c.JobTitle }},
+	{"timezone", func(c *models.Contact) interface{} { return c.Timezone }},
+	{"street", func(c *models.Contact) interface{} { return c.Street }},
+	{"city", func(c *models.Contact) interface{} { return c.City }},
+	{"region", func(c *models.Contact) interface{} { return c.Region }},
+	{"postal_code", func(c *models.Contact) interface{} { return c.PostalCode }},
+	{"country_code", func(c *models.Contact) interface{} { return c.CountryCode }},
+	{"source", func(c *models.Contact) interface{} { return c.Source }},
+	{"stage", func(c *models.Contact) interface{} { return c.Stage }},
+	{"latitude", func(c *models.Contact) interface{} { return derefFloat(c.Latitude) }},
+	{"longitude", func(c *models.Contact) interface{} { return derefFloat(c.Longitude) }},
+}
+
+func derefFloat(value *float64) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
+
+// ContactFieldChange is the value of a contact field before and after a change, From is nil for a new contact
+type ContactFieldChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// ContactAuditEntry is an entry of the history of a contact
+type ContactAuditEntry struct {
+	ID        int64     `db:"id"`
+	ContactID int       `db:"contact_id"`
+	UserID    int       `db:"user_id"`
+	Action    string    `db:"action"`
+	Changes   []byte    `db:"changes"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// contactChanges lists the fields that differ between two versions of a contact, every set field of after when
+// before is nil
+func contactChanges(before, after *models.Contact) map[string]ContactFieldChange {
+	changes := map[string]ContactFieldChange{}
+	for _, field := range auditedContactFields {
+		to := field.value(after)
+		if before == nil {
+			if to != nil && to != "" {
+				changes[field.name] = ContactFieldChange{To: to}
+			}
+			continue
+		}
+		if from := field.value(before); from != to {
+			changes[field.name] = ContactFieldChange{From: from, To: to}
+		}
+	}
+
+	// Custom fields are recorded one by one as custom_fields.<key>
+	var beforeFields models.CustomFields
+	if before != nil {
+		beforeFields = before.CustomFields
+	}
+	for key, to := range after.CustomFields {
+		if from, ok := beforeFields[key]; !ok {
+			changes["custom_fields."+key] = ContactFieldChange{To: to}
+		} else if from != to {
+			changes["custom_fields."+key] = ContactFieldChange{From: from, To: to}
+		}
+	}
+	for key, from := range beforeFields {
+		if _, ok := after.CustomFields[key]; !ok {
+			changes["custom_fields."+key] = ContactFieldChange{From: from}
+		}
+	}
+	return changes
+}
+
+// ContactFieldValues returns the values of the audited fields of a contact by their name in its history, custom
+// fields as custom_fields.<key>
+func ContactFieldValues(contact *models.Contact) map[string]interface{} {
+	values := make(map[string]interface{}, len(auditedContactFields)+len(contact.CustomFields))
+	for _, field := range auditedContactFields {
+		values[field.name] = field.value(contact)
+	}
+	for key, value := range contact.CustomFields {
+		values["custom_fields."+key] = value
+	}
+	return values
+}
+
+// recordContactAudit appends an entry to the history of a contact, in the transaction changing it
+func recordContactAudit(tx sqlx.Execer, userID, contactID int, action string, changes map[string]ContactFieldChange) error {
+	if changes == nil {
+		changes = map[string]ContactFieldChange{}
+	}
+	encoded, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`INSERT INTO contact_audit (contact_id, user_id, action, changes) VALUES ($1, $2, $3, $4)`,
+		contactID, userID, action, encoded)
+	if err != nil {
+		log.Printf("Error recording contact history: %v", err)
+		return err
+	}
+	return nil
+}
+
+// GetContactHistory retrieves the history of a contact of a user, oldest first
+func (r *Repository) GetContactHistory(userID, contactID int) ([]ContactAuditEntry, error) {
+	query := `SELECT id, contact_id, user_id, action, changes, created_at FROM contact_audit
+			  WHERE contact_id = $1 AND user_id = $2 ORDER BY id`
+	var entries []ContactAuditEntry
+	if err := r.db.Select(&entries, query, contactID, userID); err != nil {
+		log.Printf("Error fetching contact history: %v", err)
+		return nil, err
+	}
+	return entries, nil
+}
+
+// GetContactHistoryAfter retrieves the entries of the history of a contact of a user after the afterID entry, oldest
+// first
+func (r *Repository) GetContactHistoryAfter(userID, contactID int, afterID int64) ([]ContactAuditEntry, error) {
+	query := `SELECT id, contact_id, user_id, action, changes, created_at FROM contact_audit
+			  WHERE contact_id = $1 AND user_id = $2 AND id > $3 ORDER BY id`
+	var entries []ContactAuditEntry
+	if err := r.db.Select(&entries, query, contactID, userID, afterID); err != nil {
+		log.Printf("Error fetching contact history: %v", err)
+		return nil, err
+	}
+	return entries, nil
+}
+
+// GetContactVersion returns the version of a contact of a user, the ID of the latest entry of its history. It is 0
+// for a contact without history
+func (r *Repository) GetContactVersion(userID, contactID int) (int64, error) {
+	var version int64
+	query := `SELECT COALESCE(MAX(id), 0) FROM contact_audit WHERE contact_id = $1 AND user_id = $2`
+	if err := r.db.Get(&version, query, contactID, userID); err != nil {
+		log.Printf("Error fetching contact version: %v", err)
+		return 0, err
+	}
+	return version, nil
+}
+
+// GetLastContactAudit retrieves the latest entry of an action in the history of a contact of a user, returns nil
+// when there is none
+func (r *Repository) GetLastContactAudit(userID, contactID int, action string) (*ContactAuditEntry, error) {
+	query := `SELECT id, contact_id, user_id, action, changes, created_at FROM contact_audit
+			  WHERE contact_id = $1 AND user_id = $2 AND action = $3 ORDER BY id DESC LIMIT 1`
+	var entry ContactAuditEntry
+	err := r.db.Get(&entry, query, contactID, userID, action)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Printf("Error fetching contact history entry: %v", err)
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// GetTrashedContacts retrieves the contacts of a user in the trash, most recently deleted first
+func (r *Repository) GetTrashedContacts(userID int) ([]models.Contact, error) {
+	query := `SELECT ` + contactColumns + ` FROM contacts
+			  WHERE user_id = $1 AND deleted_at IS NOT NULL ORDER BY deleted_at DESC, id DESC`
+	var contacts []models.Contact
+	if err := r.db.Select(&contacts, query, userID); err != nil {
+		log.Printf("Error fetching trashed contacts: %v", err)
+		return nil, err
+	}
+	return contacts, nil
+}
+
+// GetTrashedContact retrieves a contact of a user in the trash, returns nil when it is not in the trash
+func (r *Repository) GetTrashedContact(userID, contactID int) (*models.Contact, error) {
+	query := `SELECT ` + contactColumns + ` FROM contacts WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL`
+	var contact models.Contact
+	err := r.db.Get(&contact, query, contactID, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Printf("Error fetching trashed contact: %v", err)
+		return nil, err
+	}
+	return &contact, nil
+}
+
+// setContactDeleted moves a contact of a user to the trash or out of it, recording it in its history
+func (r *Repository) setContactDeleted(userID, contactID int, deleted bool) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		log.Printf("Error starting contact transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := trashContact(tx, userID, contactID, deleted); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// trashContact moves a contact of a user to the trash or out of it and records it in its history in tx
+func trashContact(tx *sqlx.Tx, userID, contactID int, deleted bool) error {
+	query, action := `UPDATE contacts SET deleted_at = NOW() WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`, ContactAuditDeleted
+	if !deleted {
+		query, action = `UPDATE contacts SET deleted_at = NULL, updated_at = NOW() WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL`, ContactAuditRestored
+	}
+	result, err := tx.Exec(query, contactID, userID)
+	if err != nil {
+		log.Printf("Error updating contact trash state: %v", err)
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrContactNotFound
+	}
+
+	return recordContactAudit(tx, userID, contactID, action, nil)
+}
+
+// DeleteContact moves a contact of a user to the trash, where it stays until restored or purged
+func (r *Repository) DeleteContact(userID, contactID int) error {
+	return r.setContactDeleted(userID, contactID, true)
+}
+
+// RestoreContact takes a contact of a user out of the trash
+func (r *Repository) RestoreContact(userID, contactID int) error {
+	return r.setContactDeleted(userID, contactID, false)
+}
+
+// PurgeContact deletes a contact of a user for good with its history, whether it is in the trash or not. Returns
+// whether it was in the trash and the storage keys of its attachments, whose rows go with it: the caller deletes
+// their blobs
+func (r *Repository) PurgeContact(userID, contactID int) (bool, []string, error) {
+	// The statement reads the attachments as they were before the delete cascaded to them
+	query := `WITH purged AS (DELETE FROM contacts WHERE id = $1 AND user_id = $2 RETURNING id, deleted_at)
+			  SELECT p.deleted_at IS NOT NULL,
+			         ARRAY(SELECT a.storage_key FROM attachments a WHERE a.contact_id = p.id)
+			  FROM purged p`
+	var trashed bool
+	var storageKeys []string
+	err := r.db.QueryRow(query, contactID, userID).Scan(&trashed, pq.Array(&storageKeys))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil, ErrContactNotFound
+		}
+		log.Printf("Error purging contact: %v", err)
+		return false, nil, err
+	}
+	return trashed, storageKeys, nil
+}
+
+// PurgeTrashBefore deletes for good the contacts moved to the trash before a point in time, returns how many and the
+// storage keys of their attachments, whose rows go with them: the caller deletes their blobs
+func (r *Repository) PurgeTrashBefore(before time.Time) (int64, []string, error) {
+	query := `WITH purged AS (DELETE FROM contacts WHERE deleted_at < $1 RETURNING id)
+			  SELECT (SELECT COUNT(*) FROM purged),
+			         ARRAY(SELECT a.storage_key FROM attachments a JOIN purged p ON p.id = a.contact_id)`
+	var count int64
+	var storageKeys []string
+	err := r.db.QueryRow(query, before).Scan(&count, pq.Array(&storageKeys))
+	if err != nil {
+		log.Printf("Error purging trash: %v", err)
+		return 0, nil, err
+	}
+	return count, storageKeys, nil
+}
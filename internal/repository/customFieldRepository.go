@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"log"
+
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const customFieldColumns = `key, label, type, options, required, default_value, position, created_at, updated_at`
+
+// GetCustomFieldDefinitions retrieves the custom fields of the contact template in display order
+func (r *Repository) GetCustomFieldDefinitions() ([]models.CustomFieldDefinition, error) {
+	query := `SELECT ` + customFieldColumns + ` FROM custom_field_definitions ORDER BY position, key`
+	var defs []models.CustomFieldDefinition
+	err := r.db.Select(&defs, query)
+	if err != nil {
+		log.Printf("Error fetching custom field definitions: %v", err)
+		return nil, err
+	}
+	return defs, nil
+}
+
+// SaveCustomFieldDefinition inserts or replaces the definition of a custom field and bumps the template version, in
+// one transaction so contacts are never validated against a definition of an older version. Returns the new version
+func (r *Repository) SaveCustomFieldDefinition(def models.CustomFieldDefinition, versionKey string) (int, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		log.Printf("Error starting custom field transaction: %v", err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`INSERT INTO custom_field_definitions (key, label, type, options, required, default_value, position)
+					  VALUES ($1, $2, $3, $4, $5, $6, $7)
+					  ON CONFLICT (key) DO UPDATE SET label = EXCLUDED.label, type = EXCLUDED.type, options = EXCLUDED.options,
+					  required = EXCLUDED.required, default_value = EXCLUDED.default_value, position = EXCLUDED.position,
+					  updated_at = NOW()`,
+		def.Key, def.Label, def.Type, pq.Array(def.Options), def.Required, def.DefaultValue, def.Position)
+	if err != nil {
+		log.Printf("Error saving custom field definition: %v", err)
+		return 0, err
+	}
+	version, err := bumpSettingCounter(tx, versionKey)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing custom field definition: %v", err)
+		return 0, err
+	}
+	return version, nil
+}
+
+// DeleteCustomFieldDefinition removes a custom field and bumps the template version, found is false when the field
+// does not exist. The values contacts hold for it are dropped by their migration
+func (r *Repository) DeleteCustomFieldDefinition(key, versionKey string) (found bool, err error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		log.Printf("Error starting custom field transaction: %v", err)
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`DELETE FROM custom_field_definitions WHERE key = $1`, key)
+	if err != nil {
+		log.Printf("Error deleting custom field definition: %v", err)
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("Error getting rows affected: %v", err)
+		return false, err
+	}
+	if rows == 0 {
+		return false, nil
+	}
+	if _, err := bumpSettingCounter(tx, versionKey); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing custom field deletion: %v", err)
+		return false, err
+	}
+	return true, nil
+}
+
+// bumpSettingCounter increments an instance setting holding a number, starting from 0, and returns its new value
+func bumpSettingCounter(tx *sqlx.Tx, key string) (int, error) {
+	var value int
+	err := tx.QueryRow(`INSERT INTO instance_settings (key, value) VALUES ($1, '1')
+						ON CONFLICT (key) DO UPDATE SET value = (instance_settings.value::integer + 1)::text, updated_at = NOW()
+						RETURNING value::integer`, key).Scan(&value)
+	if err != nil {
+		log.Printf("Error bumping instance setting %s: %v", key, err)
+		return 0, err
+	}
+	return value, nil
+}
+
+// OutdatedContact is the custom field values of a contact following a previous version of the template
+type OutdatedContact struct {
+	ID           int                 `db:"id"`
+	UserID       int                 `db:"user_id"`
+	CustomFields models.CustomFields `db:"custom_fields"`
+}
+
+// GetOutdatedContacts retrieves up to limit contacts, trashed ones included, following a template version older
+// than version
+func (r *Repository) GetOutdatedContacts(version, limit int) ([]OutdatedContact, error) {
+	query := `SELECT id, user_id, custom_fields FROM contacts WHERE template_version < $1 ORDER BY id LIMIT $2`
+	var contacts []OutdatedContact
+	err := r.db.Select(&contacts, query, version, limit)
+	if err != nil {
+		log.Printf("Error fetching outdated contacts: %v", err)
+		return nil, err
+	}
+	return contacts, nil
+}
+
+// MigrateContactFields saves the custom fields of a contact migrated to version, unless a concurrent migration
+// already moved it to a newer one
+func (r *Repository) MigrateContactFields(contactID int, fields models.CustomFields, version int) error {
+	_, err := r.db.Exec(`UPDATE contacts SET custom_fields = $1, template_version = $2
+						 WHERE id = $3 AND template_version < $2`, fields, version, contactID)
+	if err != nil {
+		log.Printf("Error migrating contact fields: %v", err)
+		return err
+	}
+	return nil
+}
+
+// CountContactsByTemplate counts the contacts following a template version older than version, and those missing
+// one of the required fields among the others
+func (r *Repository) CountContactsByTemplate(version int, required []string) (outdated, missingRequired int, err error) {
+	query := `SELECT COUNT(*) FILTER (WHERE template_version < $1),
+					 COUNT(*) FILTER (WHERE template_version >= $1 AND NOT jsonb_exists_all(custom_fields, $2))
+			  FROM contacts WHERE deleted_at IS NULL`
+	err = r.db.QueryRow(query, version, pq.Array(required)).Scan(&outdated, &missingRequired)
+	if err != nil {
+		log.Printf("Error counting contacts by template: %v", err)
+		return 0, 0, err
+	}
+	return outdated, missingRequired, nil
+}
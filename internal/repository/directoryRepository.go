@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/danizion/contact-app/internal/models"
+)
+
+// Conditions selecting the contacts of the team directory: the contacts of the shared address book, or the cards of
+// the members. Only the contacts of active accounts are listed
+const (
+	directorySharedContacts = `id IN (SELECT contact_id FROM shared_contacts WHERE status = 'shared')
+			AND user_id IN (SELECT id FROM users WHERE state = 'active')`
+	directoryMemberCards = `id IN (SELECT directory_card_id FROM users WHERE state = 'active' AND directory_card_id IS NOT NULL)`
+)
+
+// GetDirectoryContacts returns a page of the contacts of the team directory ordered by name, with the count of all
+// of them. members selects the cards of the members instead of the shared address book, a query keeps the contacts
+// whose name contains it, or whose company does when searchCompany is set
+func (r *Repository) GetDirectoryContacts(members bool, query string, searchCompany bool, limit, offset int) ([]models.Contact, int, error) {
+	source := directorySharedContacts
+	if members {
+		source = directoryMemberCards
+	}
+	baseQuery := `FROM contacts WHERE deleted_at IS NULL AND ` + source
+	params := []interface{}{}
+	if query != "" {
+		params = append(params, query)
+		searched := `first_name || ' ' || last_name`
+		if searchCompany {
+			searched += ` || ' ' || company`
+		}
+		baseQuery += ` AND strpos(lower(` + searched + `), lower($1)) > 0`
+	}
+
+	var total int
+	if err := r.db.Get(&total, `SELECT COUNT(*) `+baseQuery, params...); err != nil {
+		log.Printf("Error counting directory contacts: %v", err)
+		return nil, 0, err
+	}
+
+	var contacts []models.Contact
+	pageQuery := `SELECT ` + contactColumns + ` ` + baseQuery + orderByWithID([]string{"last_name", "first_name"}, "ASC") +
+		fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
+	if err := r.db.Select(&contacts, pageQuery, params...); err != nil {
+		log.Printf("Error fetching directory contacts: %v", err)
+		return nil, 0, err
+	}
+	return contacts, total, nil
+}
+
+// SetDirectoryCard sets the contact standing for a user in the team directory, nil takes the user out of it
+func (r *Repository) SetDirectoryCard(userID int, contactID *int) error {
+	_, err := r.db.Exec(`UPDATE users SET directory_card_id = $2, updated_at = NOW() WHERE id = $1`, userID, contactID)
+	if err != nil {
+		log.Printf("Error setting directory card: %v", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"database/sql"
+	"log"
+
+	"github.com/danizion/contact-app/internal/models"
+)
+
+const emailChangeColumns = `user_id, new_email, old_token_hash, new_token_hash, old_confirmed_at, new_confirmed_at,
+	created_at, expires_at`
+
+// SaveEmailChange stores the pending email change of a user, replacing the previous one and its links
+func (r *Repository) SaveEmailChange(change models.EmailChange) error {
+	query := `INSERT INTO email_changes (user_id, new_email, old_token_hash, new_token_hash, expires_at)
+			  VALUES ($1, $2, $3, $4, $5)
+			  ON CONFLICT (user_id) DO UPDATE SET new_email = EXCLUDED.new_email, old_token_hash = EXCLUDED.old_token_hash,
+			  new_token_hash = EXCLUDED.new_token_hash, old_confirmed_at = NULL, new_confirmed_at = NULL,
+			  created_at = NOW(), expires_at = EXCLUDED.expires_at`
+	_, err := r.db.Exec(query, change.UserID, change.NewEmail, change.OldTokenHash, change.NewTokenHash, change.ExpiresAt)
+	if err != nil {
+		log.Printf("Error saving email change: %v", err)
+		return err
+	}
+	return nil
+}
+
+// GetEmailChange retrieves the pending email change of a user, returns nil if there is none or it expired
+func (r *Repository) GetEmailChange(userID int) (*models.EmailChange, error) {
+	query := `SELECT ` + emailChangeColumns + ` FROM email_changes WHERE user_id = $1 AND expires_at > NOW()`
+	var change models.EmailChange
+	err := r.db.Get(&change, query, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Printf("Error fetching email change: %v", err)
+		return nil, err
+	}
+	return &change, nil
+}
+
+// ConfirmEmailChange marks the address a confirmation token was sent to as confirmed and returns the change, nil
+// when no unexpired change has this token
+func (r *Repository) ConfirmEmailChange(tokenHash string) (*models.EmailChange, error) {
+	query := `UPDATE email_changes SET
+			  old_confirmed_at = CASE WHEN old_token_hash = $1 THEN COALESCE(old_confirmed_at, NOW()) ELSE old_confirmed_at END,
+			  new_confirmed_at = CASE WHEN new_token_hash = $1 THEN COALESCE(new_confirmed_at, NOW()) ELSE new_confirmed_at END
+			  WHERE (old_token_hash = $1 OR new_token_hash = $1) AND expires_at > NOW()
+			  RETURNING ` + emailChangeColumns
+	var change models.EmailChange
+	err := r.db.Get(&change, query, tokenHash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Printf("Error confirming email change: %v", err)
+		return nil, err
+	}
+	return &change, nil
+}
+
+// CompleteEmailChange sets the new email of a user and drops the pending change, in one transaction
+func (r *Repository) CompleteEmailChange(userID int, email string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		log.Printf("Error starting email change transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE users SET email = $1, updated_at = NOW() WHERE id = $2`, email, userID); err != nil {
+		log.Printf("Error updating user email: %v", err)
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM email_changes WHERE user_id = $1`, userID); err != nil {
+		log.Printf("Error deleting email change: %v", err)
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing email change: %v", err)
+		return err
+	}
+	return nil
+}
+
+// DeleteEmailChange drops the pending email change of a user, returns false if there was none
+func (r *Repository) DeleteEmailChange(userID int) (bool, error) {
+	result, err := r.db.Exec(`DELETE FROM email_changes WHERE user_id = $1 AND expires_at > NOW()`, userID)
+	if err != nil {
+		log.Printf("Error deleting email change: %v", err)
+		return false, err
+	}
+	deleted, _ := result.RowsAffected()
+	return deleted > 0, nil
+}
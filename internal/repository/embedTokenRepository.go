@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/danizion/contact-app/internal/models"
+)
+
+const embedTokenColumns = `id, user_id, name, token_hash, token_prefix, group_id, query, tag, expires_at, revoked_at,
+	last_used_at, created_at`
+
+// CreateEmbedToken inserts a new embed token into the "embed_tokens" table
+func (r *Repository) CreateEmbedToken(token models.EmbedToken) (int, error) {
+	query := `INSERT INTO embed_tokens (user_id, name, token_hash, token_prefix, group_id, query, tag, expires_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`
+	var id int
+	err := r.db.QueryRow(query, token.UserID, token.Name, token.TokenHash, token.TokenPrefix, token.GroupID,
+		token.Query, token.Tag, token.ExpiresAt).Scan(&id)
+	if err != nil {
+		log.Printf("Error creating embed token: %v", err)
+		return 0, err
+	}
+	return id, nil
+}
+
+// GetEmbedTokensByUser retrieves the embed tokens of a user in creation order, revoked and expired ones included
+func (r *Repository) GetEmbedTokensByUser(userID int) ([]models.EmbedToken, error) {
+	query := `SELECT ` + embedTokenColumns + ` FROM embed_tokens WHERE user_id = $1 ORDER BY id`
+	var tokens []models.EmbedToken
+	if err := r.db.Select(&tokens, query, userID); err != nil {
+		log.Printf("Error fetching embed tokens: %v", err)
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// GetEmbedToken retrieves an embed token of a user, returns nil if it does not exist
+func (r *Repository) GetEmbedToken(userID, id int) (*models.EmbedToken, error) {
+	query := `SELECT ` + embedTokenColumns + ` FROM embed_tokens WHERE id = $1 AND user_id = $2`
+	var token models.EmbedToken
+	if err := r.db.Get(&token, query, id, userID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Printf("Error fetching embed token: %v", err)
+		return nil, err
+	}
+	return &token, nil
+}
+
+// CountActiveEmbedTokens counts the embed tokens of a user neither revoked nor expired at now
+func (r *Repository) CountActiveEmbedTokens(userID int, now time.Time) (int, error) {
+	var count int
+	err := r.db.Get(&count, `SELECT COUNT(*) FROM embed_tokens WHERE user_id = $1 AND revoked_at IS NULL
+							 AND (expires_at IS NULL OR expires_at > $2)`, userID, now)
+	if err != nil {
+		log.Printf("Error counting embed tokens: %v", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+// UseEmbedToken returns the embed token with a hash when it is neither revoked nor expired at now and records its
+// use, nil otherwise
+func (r *Repository) UseEmbedToken(tokenHash string, now time.Time) (*models.EmbedToken, error) {
+	query := `UPDATE embed_tokens SET last_used_at = $2
+			  WHERE token_hash = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > $2)
+			  RETURNING ` + embedTokenColumns
+	var token models.EmbedToken
+	if err := r.db.Get(&token, query, tokenHash, now); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Printf("Error using embed token: %v", err)
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RevokeEmbedToken revokes an embed token of a user, it returns false when the user has no such token not revoked yet
+func (r *Repository) RevokeEmbedToken(userID, id int) (bool, error) {
+	result, err := r.db.Exec(`UPDATE embed_tokens SET revoked_at = NOW() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`,
+		id, userID)
+	if err != nil {
+		log.Printf("Error revoking embed token: %v", err)
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"database/sql"
+	"log"
+
+	"github.com/danizion/contact-app/internal/models"
+)
+
+// CreateEnrichment stores a pending enrichment suggestion
+func (r *Repository) CreateEnrichment(enrichment models.ContactEnrichment) (int, error) {
+	query := `INSERT INTO contact_enrichments (contact_id, user_id, provider, matched_on, status, company, job_title, social_profiles)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`
+	var enrichmentID int
+	err := r.db.QueryRow(query, enrichment.ContactID, enrichment.UserID, enrichment.Provider, enrichment.MatchedOn,
+		enrichment.Status, enrichment.Company, enrichment.JobTitle, enrichment.SocialProfiles).Scan(&enrichmentID)
+	if err != nil {
+		log.Printf("Error creating enrichment: %v", err)
+		return 0, err
+	}
+	return enrichmentID, nil
+}
+
+// GetEnrichmentsByContact retrieves all enrichment suggestions of a user's contact, newest first
+func (r *Repository) GetEnrichmentsByContact(userID, contactID int) ([]models.ContactEnrichment, error) {
+	query := `SELECT id, contact_id, user_id, provider, matched_on, status, company, job_title, social_profiles, fetched_at, resolved_at
+			  FROM contact_enrichments WHERE contact_id = $1 AND user_id = $2 ORDER BY id DESC`
+	var enrichments []models.ContactEnrichment
+	err := r.db.Select(&enrichments, query, contactID, userID)
+	if err != nil {
+		log.Printf("Error fetching enrichments: %v", err)
+		return nil, err
+	}
+	return enrichments, nil
+}
+
+// GetEnrichment retrieves an enrichment suggestion of a user's contact, returns nil when it does not exist
+func (r *Repository) GetEnrichment(userID, contactID, enrichmentID int) (*models.ContactEnrichment, error) {
+	query := `SELECT id, contact_id, user_id, provider, matched_on, status, company, job_title, social_profiles, fetched_at, resolved_at
+			  FROM contact_enrichments WHERE id = $1 AND contact_id = $2 AND user_id = $3`
+	var enrichment models.ContactEnrichment
+	err := r.db.Get(&enrichment, query, enrichmentID, contactID, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Printf("Error fetching enrichment: %v", err)
+		return nil, err
+	}
+	return &enrichment, nil
+}
+
+// AcceptEnrichment marks a pending suggestion as accepted and copies its values into the contact fields that are still empty
+func (r *Repository) AcceptEnrichment(enrichment models.ContactEnrichment) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		log.Printf("Error starting enrichment transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE contact_enrichments SET status = 'accepted', resolved_at = NOW()
+							WHERE id = $1 AND status = 'pending'`, enrichment.ID)
+	if err != nil {
+		log.Printf("Error accepting enrichment: %v", err)
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrEnrichmentResolved
+	}
+
+	var before models.Contact
+	err = tx.Get(&before, `SELECT `+contactColumns+` FROM contacts WHERE id = $1 AND user_id = $2 FOR UPDATE`,
+		enrichment.ContactID, enrichment.UserID)
+	if err != nil {
+		log.Printf("Error fetching enriched contact: %v", err)
+		return err
+	}
+
+	// Never overwrite data the user entered
+	var after models.Contact
+	err = tx.Get(&after, `UPDATE contacts SET
+						company = CASE WHEN company = '' THEN $1 ELSE company END,
+						job_title = CASE WHEN job_title = '' THEN $2 ELSE job_title END,
+						updated_at = NOW()
+					  WHERE id = $3 AND user_id = $4 RETURNING `+contactColumns,
+		enrichment.Company, enrichment.JobTitle, enrichment.ContactID, enrichment.UserID)
+	if err != nil {
+		log.Printf("Error applying enrichment: %v", err)
+		return err
+	}
+	if changes := contactChanges(&before, &after); len(changes) > 0 {
+		if err := recordContactAudit(tx, enrichment.UserID, enrichment.ContactID, ContactAuditUpdated, changes); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// RejectEnrichment marks a pending suggestion as rejected
+func (r *Repository) RejectEnrichment(enrichmentID int) error {
+	result, err := r.db.Exec(`UPDATE contact_enrichments SET status = 'rejected', resolved_at = NOW()
+							  WHERE id = $1 AND status = 'pending'`, enrichmentID)
+	if err != nil {
+		log.Printf("Error rejecting enrichment: %v", err)
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrEnrichmentResolved
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/lib/pq"
+)
+
+// CreateGroup inserts a new group into the "groups" table
+func (r *Repository) CreateGroup(group models.Group) (int, error) {
+	query := `INSERT INTO groups (user_id, name) VALUES ($1, $2) RETURNING id`
+	var groupID int
+	err := r.db.QueryRow(query, group.UserID, group.Name).Scan(&groupID)
+	if err != nil {
+		log.Printf("Error creating group: %v", err)
+		return 0, err
+	}
+	return groupID, nil
+}
+
+// GetGroupsByUser retrieves the groups of a user with the number of contacts in each
+func (r *Repository) GetGroupsByUser(userID int) ([]models.Group, error) {
+	query := `SELECT g.id, g.user_id, g.name, g.created_at, COUNT(cg.contact_id) AS contact_count
+			  FROM groups g LEFT JOIN contact_groups cg ON cg.group_id = g.id
+			  AND EXISTS (SELECT 1 FROM contacts c WHERE c.id = cg.contact_id AND c.deleted_at IS NULL)
+			  WHERE g.user_id = $1 GROUP BY g.id ORDER BY g.name`
+	var groups []models.Group
+	err := r.db.Select(&groups, query, userID)
+	if err != nil {
+		log.Printf("Error fetching groups: %v", err)
+		return nil, err
+	}
+	return groups, nil
+}
+
+// GetGroup retrieves a group of a user, returns nil if it does not exist
+func (r *Repository) GetGroup(userID, groupID int) (*models.Group, error) {
+	query := `SELECT id, user_id, name, created_at FROM groups WHERE id = $1 AND user_id = $2`
+	var group models.Group
+	err := r.db.Get(&group, query, groupID, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Printf("Error fetching group: %v", err)
+		return nil, err
+	}
+	return &group, nil
+}
+
+// IsGroupNameTaken checks if a user already has a group with this name
+func (r *Repository) IsGroupNameTaken(userID int, name string) (bool, error) {
+	query := `SELECT COUNT(*) FROM groups WHERE user_id = $1 AND name = $2`
+	var count int
+	err := r.db.Get(&count, query, userID, name)
+	if err != nil {
+		log.Printf("Error checking group name: %v", err)
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// DeleteGroup removes a group, its contacts are only detached
+func (r *Repository) DeleteGroup(userID, groupID int) error {
+	result, err := r.db.Exec(`DELETE FROM groups WHERE id = $1 AND user_id = $2`, groupID, userID)
+	if err != nil {
+		log.Printf("Error deleting group: %v", err)
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("group %w or does not belong to the specified user", ErrNotFound)
+	}
+	return nil
+}
+
+// CountOwnedContacts counts how many of the given contact IDs belong to the user, in a single query
+func (r *Repository) CountOwnedContacts(userID int, contactIDs []int) (int, error) {
+	query := `SELECT COUNT(*) FROM contacts WHERE user_id = $1 AND id = ANY($2) AND deleted_at IS NULL`
+	var count int
+	err := r.db.Get(&count, query, userID, pq.Array(contactIDs))
+	if err != nil {
+		log.Printf("Error checking contacts ownership: %v", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+// AddContactsToGroup attaches contacts to a group, contacts already in it are skipped, returns the number attached
+func (r *Repository) AddContactsToGroup(groupID int, contactIDs []int) (int, error) {
+	query := `INSERT INTO contact_groups (contact_id, group_id)
+			  SELECT UNNEST($1::int[]), $2 ON CONFLICT DO NOTHING`
+	result, err := r.db.Exec(query, pq.Array(contactIDs), groupID)
+	if err != nil {
+		log.Printf("Error adding contacts to group: %v", err)
+		return 0, err
+	}
+	rows, err := result.RowsAffected()
+	return int(rows), err
+}
+
+// RemoveContactsFromGroup detaches contacts from a group, returns the number detached
+func (r *Repository) RemoveContactsFromGroup(groupID int, contactIDs []int) (int, error) {
+	query := `DELETE FROM contact_groups WHERE group_id = $1 AND contact_id = ANY($2)`
+	result, err := r.db.Exec(query, groupID, pq.Array(contactIDs))
+	if err != nil {
+		log.Printf("Error removing contacts from group: %v", err)
+		return 0, err
+	}
+	rows, err := result.RowsAffected()
+	return int(rows), err
+}
+
+// GetGroupsByContacts retrieves the groups of several contacts at once
+func (r *Repository) GetGroupsByContacts(contactIDs []int) ([]models.ContactMembership, error) {
+	if len(contactIDs) == 0 {
+		return nil, nil
+	}
+
+	query := `SELECT cg.contact_id, g.id, g.name
+			  FROM contact_groups cg JOIN groups g ON g.id = cg.group_id
+			  WHERE cg.contact_id = ANY($1) ORDER BY cg.contact_id, g.name`
+	var memberships []models.ContactMembership
+	err := r.db.Select(&memberships, query, pq.Array(contactIDs))
+	if err != nil {
+		log.Printf("Error fetching contact groups: %v", err)
+		return nil, err
+	}
+	return memberships, nil
+}
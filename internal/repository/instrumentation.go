@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/danizion/contact-app/internal/metrics"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// instrumentedDB records the calls, rows returned and duration of the queries of the repository by repository
+// method. Queries run in a transaction are not recorded, the rows streamed by Queryx and read by QueryRow are not
+// counted and their duration stops at the first row.
+//
+// Queries run under ctx, lib/pq cancels them on the server when it is done. Transactions begun under it are rolled
+// back then
+type instrumentedDB struct {
+	*sqlx.DB
+	ctx context.Context
+}
+
+func (db *instrumentedDB) Get(dest interface{}, query string, args ...interface{}) error {
+	start := time.Now()
+	err := db.interrupted(db.DB.GetContext(db.ctx, dest, query, args...))
+	rows := 0
+	if err == nil {
+		rows = 1
+	}
+	record(start, rows, err)
+	return err
+}
+
+func (db *instrumentedDB) Select(dest interface{}, query string, args ...interface{}) error {
+	start := time.Now()
+	err := db.interrupted(db.DB.SelectContext(db.ctx, dest, query, args...))
+	rows := 0
+	if value := reflect.ValueOf(dest); err == nil && value.Kind() == reflect.Ptr && value.Elem().Kind() == reflect.Slice {
+		rows = value.Elem().Len()
+	}
+	record(start, rows, err)
+	return err
+}
+
+func (db *instrumentedDB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	start := time.Now()
+	result, err := db.DB.ExecContext(db.ctx, query, args...)
+	err = db.interrupted(err)
+	record(start, 0, err)
+	return result, err
+}
+
+func (db *instrumentedDB) NamedExec(query string, arg interface{}) (sql.Result, error) {
+	start := time.Now()
+	result, err := db.DB.NamedExecContext(db.ctx, query, arg)
+	err = db.interrupted(err)
+	record(start, 0, err)
+	return result, err
+}
+
+func (db *instrumentedDB) QueryRow(query string, args ...interface{}) *sql.Row {
+	start := time.Now()
+	row := db.DB.QueryRowContext(db.ctx, query, args...)
+	record(start, 0, row.Err())
+	return row
+}
+
+func (db *instrumentedDB) Queryx(query string, args ...interface{}) (*sqlx.Rows, error) {
+	start := time.Now()
+	rows, err := db.DB.QueryxContext(db.ctx, query, args...)
+	err = db.interrupted(err)
+	record(start, 0, err)
+	return rows, err
+}
+
+func (db *instrumentedDB) Beginx() (*sqlx.Tx, error) {
+	return db.DB.BeginTxx(db.ctx, nil)
+}
+
+// interrupted tells the failures of the queries aborted by the end of their context apart, they wrap its error
+// (context.Canceled, context.DeadlineExceeded) while lib/pq only reports a canceled statement
+func (db *instrumentedDB) interrupted(err error) error {
+	if err == nil || db.ctx.Err() == nil || errors.Is(err, db.ctx.Err()) {
+		return err
+	}
+	return fmt.Errorf("%w: %v", db.ctx.Err(), err)
+}
+
+// record adds a query to the metrics of the repository method calling the instrumented DB
+func record(start time.Time, rows int, err error) {
+	method := callerMethod()
+	metrics.RepositoryCalls.Inc(method)
+	metrics.RepositoryDuration.Add(method, time.Since(start).Microseconds())
+	if rows > 0 {
+		metrics.RepositoryRows.Add(method, int64(rows))
+	}
+	if err != nil && err != sql.ErrNoRows {
+		metrics.RepositoryErrors.Inc(method)
+	}
+	if canceled(err) {
+		metrics.RepositoryCanceled.Inc(method)
+	}
+}
+
+// canceled reports whether a query failed because it was canceled, by its context or by the statement timeout
+func canceled(err error) bool {
+	var pqErr *pq.Error
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) ||
+		errors.As(err, &pqErr) && pqErr.Code.Name() == "query_canceled"
+}
+
+// callerMethod returns the name of the repository method calling the instrumented DB, two frames above record
+func callerMethod() string {
+	pc, _, _, ok := runtime.Caller(3)
+	if !ok {
+		return "unknown"
+	}
+	name := runtime.FuncForPC(pc).Name()
+	// github.com/.../repository.(*Repository).GetContacts.func1 is a closure of GetContacts
+	name, _, _ = strings.Cut(name[strings.LastIndex(name, "/")+1:], ".func")
+	return name[strings.LastIndex(name, ".")+1:]
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/danizion/contact-app/internal/storage/db"
+)
+
+// sleepQuery is recognized in pg_stat_activity by its comment
+const sleepQuery = `SELECT pg_sleep(30) /* instrumentation_test */`
+
+// TestQueryCanceledWithContext runs a long query under a context canceled while it is in flight: it must fail with
+// context.Canceled, stop on the server and give its connection and goroutines back. It needs the Postgres server
+// set by the POSTGRES_* environment variables and is skipped without one
+func TestQueryCanceledWithContext(t *testing.T) {
+	if err := db.Ping(); err != nil {
+		t.Skipf("no database: %v", err)
+	}
+	sqlDB := db.Init()
+	defer sqlDB.Close()
+	repo := NewRepository(sqlDB)
+	if err := repo.db.Ping(); err != nil {
+		t.Fatal(err)
+	}
+	inUse, goroutines := sqlDB.Stats().InUse, runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		var result string
+		done <- repo.WithContext(ctx).db.Get(&result, sleepQuery)
+	}()
+
+	waitFor(t, "the query to start", func() bool { return runningSleepQueries(t, repo) == 1 })
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("got %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the query was not aborted by its context")
+	}
+
+	waitFor(t, "the query to stop on the server", func() bool { return runningSleepQueries(t, repo) == 0 })
+	waitFor(t, "the connection to go back to the pool", func() bool { return sqlDB.Stats().InUse <= inUse })
+	waitFor(t, "the goroutines to end", func() bool { return runtime.NumGoroutine() <= goroutines })
+}
+
+// runningSleepQueries counts the sleep queries of the test running on the server, the query counting them left out
+func runningSleepQueries(t *testing.T, repo *Repository) int {
+	var count int
+	err := repo.db.Get(&count, `SELECT COUNT(*) FROM pg_stat_activity
+		WHERE state = 'active' AND query = $1 AND pid <> pg_backend_pid()`, sleepQuery)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+// waitFor polls condition for up to 5 seconds, failing the test with what it waited for when it never holds
+func waitFor(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"log"
+	"time"
+
+	"github.com/danizion/contact-app/internal/models"
+)
+
+// CreateInteraction logs an interaction with a contact and moves the last interaction of the contact to it unless
+// a later one is known, in one transaction
+func (r *Repository) CreateInteraction(interaction models.ContactInteraction) (*models.ContactInteraction, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO contact_interactions (contact_id, user_id, kind, note, occurred_at)
+			  VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`
+	err = tx.QueryRow(query, interaction.ContactID, interaction.UserID, interaction.Kind, interaction.Note, interaction.OccurredAt).
+		Scan(&interaction.ID, &interaction.CreatedAt)
+	if err != nil {
+		log.Printf("Error creating interaction: %v", err)
+		return nil, err
+	}
+
+	_, err = tx.Exec(`UPDATE contacts SET last_interacted_at = $1
+					  WHERE id = $2 AND (last_interacted_at IS NULL OR last_interacted_at < $1)`, interaction.OccurredAt, interaction.ContactID)
+	if err != nil {
+		log.Printf("Error updating last interaction: %v", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing interaction: %v", err)
+		return nil, err
+	}
+	return &interaction, nil
+}
+
+// GetContactInteractions returns the interactions logged with a contact, the most recent first
+func (r *Repository) GetContactInteractions(contactID int) ([]models.ContactInteraction, error) {
+	query := `SELECT id, contact_id, user_id, kind, note, occurred_at, created_at FROM contact_interactions
+			  WHERE contact_id = $1 ORDER BY occurred_at DESC, id DESC`
+	var interactions []models.ContactInteraction
+	err := r.db.Select(&interactions, query, contactID)
+	if err != nil {
+		log.Printf("Error fetching interactions: %v", err)
+		return nil, err
+	}
+	return interactions, nil
+}
+
+// TouchContactInteraction moves the last interaction of a contact to at when it is older than at minus resolution,
+// it returns whether the contact was updated
+func (r *Repository) TouchContactInteraction(userID, contactID int, at time.Time, resolution time.Duration) (bool, error) {
+	result, err := r.db.Exec(`UPDATE contacts SET last_interacted_at = $1
+							  WHERE id = $2 AND user_id = $3 AND (last_interacted_at IS NULL OR last_interacted_at < $4)`,
+		at, contactID, userID, at.Add(-resolution))
+	if err != nil {
+		log.Printf("Error updating last interaction: %v", err)
+		return false, err
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return updated > 0, nil
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"log"
+	"time"
+
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/lib/pq"
+)
+
+// ContactOwner is a contact with the user owning it
+type ContactOwner struct {
+	ID     int `db:"id"`
+	UserID int `db:"user_id"`
+}
+
+// RebuildContactSearch recomputes the search vector of up to limit contacts with an ID above afterID, trashed ones
+// included, and returns them. Setting search_language fires the contacts_search_vector trigger even when the
+// language is unchanged
+func (r *Repository) RebuildContactSearch(afterID, limit int) ([]ContactOwner, error) {
+	query := `WITH batch AS (
+				SELECT id FROM contacts WHERE id > $1 ORDER BY id LIMIT $2 FOR UPDATE
+			  )
+			  UPDATE contacts SET search_language = user_search_language(contacts.user_id) FROM batch
+			  WHERE contacts.id = batch.id
+			  RETURNING contacts.id, contacts.user_id`
+	var contacts []ContactOwner
+	err := r.db.Select(&contacts, query, afterID, limit)
+	if err != nil {
+		log.Printf("Error rebuilding contact search: %v", err)
+		return nil, err
+	}
+	return contacts, nil
+}
+
+// GetContactPhones returns the ID, owner, phone number, country and normalized phone number of up to limit contacts
+// with an ID above afterID in ID order, trashed ones included
+func (r *Repository) GetContactPhones(afterID, limit int) ([]models.Contact, error) {
+	query := `SELECT id, user_id, phone_number, country_code, phone_normalized
+			  FROM contacts WHERE id > $1 ORDER BY id LIMIT $2`
+	var contacts []models.Contact
+	err := r.db.Select(&contacts, query, afterID, limit)
+	if err != nil {
+		log.Printf("Error fetching contact phones: %v", err)
+		return nil, err
+	}
+	return contacts, nil
+}
+
+// SetContactPhonesNormalized writes the normalized phone number of contacts and returns the number of contacts
+// updated. Contacts whose phone number changed since it was read are left to the update that changed it
+func (r *Repository) SetContactPhonesNormalized(contacts []models.Contact) (int, error) {
+	ids := make([]int64, len(contacts))
+	numbers := make([]string, len(contacts))
+	normalized := make([]string, len(contacts))
+	for i, contact := range contacts {
+		ids[i], numbers[i], normalized[i] = int64(contact.ID), contact.PhoneNumber, contact.PhoneNormalized
+	}
+
+	result, err := r.db.Exec(`UPDATE contacts SET phone_normalized = v.normalized
+			  FROM unnest($1::int[], $2::text[], $3::text[]) AS v(id, number, normalized)
+			  WHERE contacts.id = v.id AND contacts.phone_number = v.number`,
+		pq.Array(ids), pq.Array(numbers), pq.Array(normalized))
+	if err != nil {
+		log.Printf("Error writing normalized phone numbers: %v", err)
+		return 0, err
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(updated), nil
+}
+
+// RecountUserCounts recomputes the totals of up to limit users with an ID above afterUserID from their contacts and
+// attachments, and returns the users scanned and the ones whose totals were wrong. The rows are locked before counting
+// so changes committed meanwhile are counted once, by the recount or by their trigger
+func (r *Repository) RecountUserCounts(afterUserID, limit int) (scanned, fixed []int, err error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		log.Printf("Error starting recount transaction: %v", err)
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	// Users missing their row (created before the counters existed and never counted) get one first
+	_, err = tx.Exec(`INSERT INTO user_counts (user_id)
+			  SELECT id FROM users WHERE id > $1 ORDER BY id LIMIT $2
+			  ON CONFLICT (user_id) DO NOTHING`, afterUserID, limit)
+	if err != nil {
+		log.Printf("Error creating missing user counts: %v", err)
+		return nil, nil, err
+	}
+	err = tx.Select(&scanned, `SELECT user_id FROM user_counts WHERE user_id > $1 ORDER BY user_id LIMIT $2 FOR UPDATE`,
+		afterUserID, limit)
+	if err != nil {
+		log.Printf("Error locking user counts: %v", err)
+		return nil, nil, err
+	}
+	if len(scanned) == 0 {
+		return nil, nil, nil
+	}
+
+	err = tx.Select(&fixed, `WITH counted AS (
+				SELECT uc.user_id,
+					   (SELECT COUNT(*) FROM contacts c WHERE c.user_id = uc.user_id AND c.deleted_at IS NULL) AS contacts,
+					   (SELECT COUNT(*) FROM contacts c WHERE c.user_id = uc.user_id AND c.deleted_at IS NOT NULL) AS trashed_contacts,
+					   (SELECT COALESCE(SUM(a.size_bytes), 0) FROM attachments a WHERE a.user_id = uc.user_id) AS attachment_bytes
+				FROM user_counts uc WHERE uc.user_id = ANY($1)
+			  )
+			  UPDATE user_counts SET contacts = counted.contacts, trashed_contacts = counted.trashed_contacts,
+					 attachment_bytes = counted.attachment_bytes, updated_at = NOW()
+			  FROM counted
+			  WHERE user_counts.user_id = counted.user_id
+				AND (user_counts.contacts, user_counts.trashed_contacts, user_counts.attachment_bytes)
+					IS DISTINCT FROM (counted.contacts, counted.trashed_contacts, counted.attachment_bytes)
+			  RETURNING user_counts.user_id`, pq.Array(scanned))
+	if err != nil {
+		log.Printf("Error recounting user counts: %v", err)
+		return nil, nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing recount: %v", err)
+		return nil, nil, err
+	}
+	return scanned, fixed, nil
+}
+
+// GetMostActiveUsers returns up to limit users by the number of changes they made since since, most active first
+func (r *Repository) GetMostActiveUsers(since time.Time, limit int) ([]int, error) {
+	query := `SELECT a.actor_id FROM audit_log a JOIN users u ON u.id = a.actor_id
+			  WHERE a.created_at >= $1
+			  GROUP BY a.actor_id ORDER BY COUNT(*) DESC, a.actor_id LIMIT $2`
+	var userIDs []int
+	err := r.db.Select(&userIDs, query, since, limit)
+	if err != nil {
+		log.Printf("Error fetching most active users: %v", err)
+		return nil, err
+	}
+	return userIDs, nil
+}
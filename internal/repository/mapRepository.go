@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/danizion/contact-app/internal/models"
+)
+
+// GetGeocodedContacts retrieves up to limit of a user's contacts that have coordinates and match the optional filters
+func (r *Repository) GetGeocodedContacts(userID int, filter ContactFilter, limit int) ([]models.Contact, error) {
+	baseQuery, params := contactFilterQuery(userID, filter)
+	query := `SELECT ` + contactColumns + ` ` + baseQuery +
+		fmt.Sprintf(" AND latitude IS NOT NULL AND longitude IS NOT NULL ORDER BY id LIMIT %d", limit)
+
+	var contacts []models.Contact
+	err := r.db.Select(&contacts, query, params...)
+	if err != nil {
+		log.Printf("Error fetching geocoded contacts: %v", err)
+		return nil, err
+	}
+	return contacts, nil
+}
+
+// SetContactLocation stores the coordinates of a contact, recording them in its history
+func (r *Repository) SetContactLocation(userID, contactID int, latitude, longitude float64) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		log.Printf("Error starting contact transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	var before models.Contact
+	err = tx.Get(&before, `SELECT `+contactColumns+` FROM contacts WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL FOR UPDATE`,
+		contactID, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrContactNotFound
+		}
+		log.Printf("Error fetching contact: %v", err)
+		return err
+	}
+
+	var after models.Contact
+	query := `UPDATE contacts SET latitude = $1, longitude = $2, updated_at = NOW() WHERE id = $3 AND user_id = $4 RETURNING ` + contactColumns
+	if err := tx.Get(&after, query, latitude, longitude, contactID, userID); err != nil {
+		log.Printf("Error setting contact location: %v", err)
+		return err
+	}
+	if changes := contactChanges(&before, &after); len(changes) > 0 {
+		if err := recordContactAudit(tx, userID, contactID, ContactAuditUpdated, changes); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
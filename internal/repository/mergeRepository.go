@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"log"
+
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/lib/pq"
+)
+
+// DuplicateUsers are accounts whose emails only differ by case or surrounding spaces, oldest first
+type DuplicateUsers struct {
+	Email   string        `db:"email"`
+	UserIDs pq.Int64Array `db:"user_ids"`
+}
+
+// GetDuplicateUsers lists the groups of accounts sharing an email once normalized
+func (r *Repository) GetDuplicateUsers() ([]DuplicateUsers, error) {
+	query := `SELECT LOWER(TRIM(email)) AS email, ARRAY_AGG(id ORDER BY id) AS user_ids FROM users
+			  GROUP BY LOWER(TRIM(email)) HAVING COUNT(*) > 1 ORDER BY 1`
+	var duplicates []DuplicateUsers
+	if err := r.db.Select(&duplicates, query); err != nil {
+		log.Printf("Error fetching duplicate users: %v", err)
+		return nil, err
+	}
+	return duplicates, nil
+}
+
+// GetUsersByIDs retrieves the users with these IDs in ID order
+func (r *Repository) GetUsersByIDs(ids []int64) ([]models.User, error) {
+	query := `SELECT id, username, email, hashed_password, is_admin, created_at, updated_at
+			  FROM users WHERE id = ANY($1) ORDER BY id`
+	var users []models.User
+	if err := r.db.Select(&users, query, pq.Array(ids)); err != nil {
+		log.Printf("Error fetching users: %v", err)
+		return nil, err
+	}
+	return users, nil
+}
+
+// MergedAccount counts what MergeUsers moved from the source account
+type MergedAccount struct {
+	Contacts    int
+	Groups      int
+	Tags        int
+	Attachments int
+	Snapshots   int
+	Webhooks    int
+	APIKeys     int
+}
+
+// MergeUsers moves everything the source user owns to the target user and deletes the source, in one transaction.
+// Groups and tags named like one of the target are folded into it, the target keeps its preferences with the weekly
+// digest on when either account had it on, and the audit log of the source is kept under the target
+func (r *Repository) MergeUsers(sourceID, targetID int) (*MergedAccount, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		log.Printf("Error starting account merge transaction: %v", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	merged := &MergedAccount{}
+	// Memberships of groups and tags the target already has move to its own, the emptied duplicates are dropped
+	for _, labels := range []struct{ table, memberships, key string }{
+		{"groups", "contact_groups", "group_id"},
+		{"tags", "contact_tags", "tag_id"},
+	} {
+		_, err := tx.Exec(`INSERT INTO `+labels.memberships+` (`+labels.key+`, contact_id)
+			SELECT t.id, m.contact_id FROM `+labels.memberships+` m
+			JOIN `+labels.table+` s ON s.id = m.`+labels.key+`
+			JOIN `+labels.table+` t ON t.user_id = $2 AND t.name = s.name
+			WHERE s.user_id = $1 ON CONFLICT DO NOTHING`, sourceID, targetID)
+		if err != nil {
+			log.Printf("Error merging %s of user %d: %v", labels.table, sourceID, err)
+			return nil, err
+		}
+		_, err = tx.Exec(`DELETE FROM `+labels.table+` s USING `+labels.table+` t
+			WHERE s.user_id = $1 AND t.user_id = $2 AND t.name = s.name`, sourceID, targetID)
+		if err != nil {
+			log.Printf("Error merging %s of user %d: %v", labels.table, sourceID, err)
+			return nil, err
+		}
+	}
+
+	for _, owned := range []struct {
+		table string
+		count *int
+	}{
+		{"contacts", &merged.Contacts},
+		{"groups", &merged.Groups},
+		{"tags", &merged.Tags},
+		{"attachments", &merged.Attachments},
+		{"contact_enrichments", nil},
+		{"contact_snapshots", &merged.Snapshots},
+		{"contact_audit", nil},
+		{"contact_interactions", nil},
+		{"webhooks", &merged.Webhooks},
+		{"api_keys", &merged.APIKeys},
+		{"embed_tokens", nil},
+		{"username_history", nil},
+		{"audit_log", nil},
+	} {
+		result, err := tx.Exec(`UPDATE `+owned.table+` SET user_id = $2 WHERE user_id = $1`, sourceID, targetID)
+		if err != nil {
+			log.Printf("Error moving %s of user %d: %v", owned.table, sourceID, err)
+			return nil, err
+		}
+		if owned.count != nil {
+			moved, _ := result.RowsAffected()
+			*owned.count = int(moved)
+		}
+	}
+
+	// Rows keyed by user merge with those of the target instead of moving: sync batches the target already recorded
+	// keep its result, the target keeps its own notification choices, the latest view of a shared contact, the
+	// dismissals of either account and the summed API usage are kept. The rows of the source left are deleted with it
+	for _, keyed := range []struct{ table, query string }{
+		{"sync_batches", `INSERT INTO sync_batches (user_id, batch_id, result, created_at)
+			SELECT $2, batch_id, result, created_at FROM sync_batches WHERE user_id = $1
+			ON CONFLICT (user_id, batch_id) DO NOTHING`},
+		{"notification_preferences", `INSERT INTO notification_preferences (user_id, event, channel, enabled, updated_at)
+			SELECT $2, event, channel, enabled, updated_at FROM notification_preferences WHERE user_id = $1
+			ON CONFLICT (user_id, event, channel) DO NOTHING`},
+		{"shared_contact_views", `INSERT INTO shared_contact_views (user_id, contact_id, viewed_at)
+			SELECT $2, contact_id, viewed_at FROM shared_contact_views WHERE user_id = $1
+			ON CONFLICT (user_id, contact_id) DO UPDATE SET viewed_at = GREATEST(shared_contact_views.viewed_at, EXCLUDED.viewed_at)`},
+		{"announcement_dismissals", `INSERT INTO announcement_dismissals (user_id, announcement_id, dismissed_at)
+			SELECT $2, announcement_id, dismissed_at FROM announcement_dismissals WHERE user_id = $1
+			ON CONFLICT (user_id, announcement_id) DO NOTHING`},
+		{"api_usage", `INSERT INTO api_usage (user_id, day, route, requests, rate_limited, bytes_in, bytes_out)
+			SELECT $2, day, route, requests, rate_limited, bytes_in, bytes_out FROM api_usage WHERE user_id = $1
+			ON CONFLICT (user_id, day, route) DO UPDATE SET requests = api_usage.requests + EXCLUDED.requests,
+			rate_limited = api_usage.rate_limited + EXCLUDED.rate_limited, bytes_in = api_usage.bytes_in + EXCLUDED.bytes_in,
+			bytes_out = api_usage.bytes_out + EXCLUDED.bytes_out`},
+	} {
+		if _, err := tx.Exec(keyed.query, sourceID, targetID); err != nil {
+			log.Printf("Error merging %s of user %d: %v", keyed.table, sourceID, err)
+			return nil, err
+		}
+	}
+
+	_, err = tx.Exec(`INSERT INTO user_preferences (user_id, weekly_digest, region, digest_sent_at, updated_at)
+		SELECT $2, weekly_digest, region, digest_sent_at, NOW() FROM user_preferences WHERE user_id = $1
+		ON CONFLICT (user_id) DO UPDATE SET weekly_digest = user_preferences.weekly_digest OR EXCLUDED.weekly_digest,
+		region = COALESCE(NULLIF(user_preferences.region, ''), EXCLUDED.region), updated_at = NOW()`, sourceID, targetID)
+	if err != nil {
+		log.Printf("Error merging preferences of user %d: %v", sourceID, err)
+		return nil, err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM users WHERE id = $1`, sourceID); err != nil {
+		log.Printf("Error deleting merged user %d: %v", sourceID, err)
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing account merge: %v", err)
+		return nil, err
+	}
+	return merged, nil
+}
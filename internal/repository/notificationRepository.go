@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"log"
+
+	"github.com/danizion/contact-app/internal/models"
+)
+
+// GetNotificationPreferences returns the cells of the notification preferences matrix a user changed from their
+// default
+func (r *Repository) GetNotificationPreferences(userID int) ([]models.NotificationPreference, error) {
+	query := `SELECT user_id, event, channel, enabled FROM notification_preferences WHERE user_id = $1`
+	var prefs []models.NotificationPreference
+	err := r.db.Select(&prefs, query, userID)
+	if err != nil {
+		log.Printf("Error fetching notification preferences: %v", err)
+		return nil, err
+	}
+	return prefs, nil
+}
+
+// SaveNotificationPreferences inserts or updates cells of the notification preferences matrix of their users,
+// all or none
+func (r *Repository) SaveNotificationPreferences(prefs []models.NotificationPreference) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		log.Printf("Error starting notification preferences transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, pref := range prefs {
+		_, err := tx.Exec(`INSERT INTO notification_preferences (user_id, event, channel, enabled) VALUES ($1, $2, $3, $4)
+				  ON CONFLICT (user_id, event, channel) DO UPDATE SET enabled = EXCLUDED.enabled, updated_at = NOW()`,
+			pref.UserID, pref.Event, pref.Channel, pref.Enabled)
+		if err != nil {
+			log.Printf("Error saving notification preference: %v", err)
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing notification preferences: %v", err)
+		return err
+	}
+	return nil
+}
+
+// GetNotifiedUsers returns the active users but excludeUserID who are notified of an event on a channel, by their
+// own choice or by enabledByDefault when they made none
+func (r *Repository) GetNotifiedUsers(event, channel string, enabledByDefault bool, excludeUserID int) ([]models.User, error) {
+	query := `SELECT u.id, u.username, u.email, u.hashed_password, u.is_admin, u.state, u.deactivated_at, u.created_at, u.updated_at
+			  FROM users u LEFT JOIN notification_preferences n ON n.user_id = u.id AND n.event = $1 AND n.channel = $2
+			  WHERE u.state = 'active' AND u.id <> $4 AND COALESCE(n.enabled, $3)
+			  ORDER BY u.id`
+	var users []models.User
+	err := r.db.Select(&users, query, event, channel, enabledByDefault, excludeUserID)
+	if err != nil {
+		log.Printf("Error fetching notified users: %v", err)
+		return nil, err
+	}
+	return users, nil
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/danizion/contact-app/internal/models"
+)
+
+// GetPicklistValues retrieves the allowed values of a picklist field ordered by position
+func (r *Repository) GetPicklistValues(field string) ([]models.PicklistValue, error) {
+	query := `SELECT id, field, value, position, created_at
+			  FROM picklist_values WHERE field = $1 ORDER BY position, id`
+	var values []models.PicklistValue
+	err := r.db.Select(&values, query, field)
+	if err != nil {
+		log.Printf("Error fetching picklist values: %v", err)
+		return nil, err
+	}
+	return values, nil
+}
+
+// IsPicklistValueAllowed checks if value is one of the allowed values of a picklist field
+func (r *Repository) IsPicklistValueAllowed(field, value string) (bool, error) {
+	query := `SELECT COUNT(*) FROM picklist_values WHERE field = $1 AND value = $2`
+	var count int
+	err := r.db.Get(&count, query, field, value)
+	if err != nil {
+		log.Printf("Error checking picklist value: %v", err)
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// CreatePicklistValue inserts a new allowed value, appending it after the existing ones when no position is given
+func (r *Repository) CreatePicklistValue(value models.PicklistValue) (int, error) {
+	query := `INSERT INTO picklist_values (field, value, position)
+			  VALUES ($1, $2, CASE WHEN $3 > 0 THEN $3 ELSE (SELECT COALESCE(MAX(position), 0) + 1 FROM picklist_values WHERE field = $1) END)
+			  RETURNING id`
+	var valueID int
+	err := r.db.QueryRow(query, value.Field, value.Value, value.Position).Scan(&valueID)
+	if err != nil {
+		log.Printf("Error creating picklist value: %v", err)
+		return 0, err
+	}
+	return valueID, nil
+}
+
+// DeletePicklistValue removes an allowed value from a picklist field
+func (r *Repository) DeletePicklistValue(field, value string) error {
+	query := `DELETE FROM picklist_values WHERE field = $1 AND value = $2`
+	result, err := r.db.Exec(query, field, value)
+	if err != nil {
+		log.Printf("Error deleting picklist value: %v", err)
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("picklist value %w", ErrNotFound)
+	}
+	return nil
+}
+
+// CountContactsByField aggregates a user's contacts by the value of a picklist field
+func (r *Repository) CountContactsByField(userID int, field string) (map[string]int, error) {
+	// field is interpolated into the query so only known picklist columns are accepted
+	if field != "source" && field != "stage" {
+		return nil, fmt.Errorf("unknown picklist field %s", field)
+	}
+
+	query := fmt.Sprintf(`SELECT %s AS value, COUNT(*) AS count FROM contacts WHERE user_id = $1 AND deleted_at IS NULL GROUP BY %s`, field, field)
+	var rows []struct {
+		Value string `db:"value"`
+		Count int    `db:"count"`
+	}
+	err := r.db.Select(&rows, query, userID)
+	if err != nil {
+		log.Printf("Error aggregating contacts by %s: %v", field, err)
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.Value] = row.Count
+	}
+	return counts, nil
+}
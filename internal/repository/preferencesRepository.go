@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/lib/pq"
+)
+
+// GetUserPreferences retrieves the preferences of a user, returning the defaults when none were saved
+func (r *Repository) GetUserPreferences(userID int) (*models.UserPreferences, error) {
+	query := `SELECT user_id, weekly_digest, region, search_language, duplicate_sensitivity, digest_sent_at, updated_at FROM user_preferences WHERE user_id = $1`
+	var prefs models.UserPreferences
+	err := r.db.Get(&prefs, query, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return &models.UserPreferences{UserID: userID}, nil
+		}
+		log.Printf("Error fetching user preferences: %v", err)
+		return nil, err
+	}
+	return &prefs, nil
+}
+
+// SaveUserPreferences inserts or updates the preferences of a user
+func (r *Repository) SaveUserPreferences(prefs models.UserPreferences) error {
+	query := `INSERT INTO user_preferences (user_id, weekly_digest, region, search_language, duplicate_sensitivity)
+			  VALUES ($1, $2, $3, $4, $5)
+			  ON CONFLICT (user_id) DO UPDATE SET weekly_digest = EXCLUDED.weekly_digest, region = EXCLUDED.region,
+			  search_language = EXCLUDED.search_language, duplicate_sensitivity = EXCLUDED.duplicate_sensitivity,
+			  updated_at = NOW()`
+	_, err := r.db.Exec(query, prefs.UserID, prefs.WeeklyDigest, prefs.Region, prefs.SearchLanguage, prefs.DuplicateSensitivity)
+	if err != nil {
+		log.Printf("Error saving user preferences: %v", err)
+		return err
+	}
+	return nil
+}
+
+// GetDigestRecipients retrieves the active users who opted in to the weekly digest and were not sent one since before
+func (r *Repository) GetDigestRecipients(before time.Time) ([]models.User, error) {
+	query := `SELECT u.id, u.username, u.email, u.hashed_password, u.is_admin, u.state, u.deactivated_at, u.created_at, u.updated_at
+			  FROM users u JOIN user_preferences p ON p.user_id = u.id
+			  WHERE p.weekly_digest AND u.state = 'active' AND (p.digest_sent_at IS NULL OR p.digest_sent_at < $1)`
+	var users []models.User
+	err := r.db.Select(&users, query, before)
+	if err != nil {
+		log.Printf("Error fetching digest recipients: %v", err)
+		return nil, err
+	}
+	return users, nil
+}
+
+// ClaimDigest marks the digest of a user as sent if it was not sent since before, returns false when another
+// instance already claimed it so each digest is sent once even with several replicas
+func (r *Repository) ClaimDigest(userID int, before time.Time) (bool, error) {
+	query := `UPDATE user_preferences SET digest_sent_at = NOW()
+			  WHERE user_id = $1 AND weekly_digest AND (digest_sent_at IS NULL OR digest_sent_at < $2)`
+	result, err := r.db.Exec(query, userID, before)
+	if err != nil {
+		log.Printf("Error claiming digest: %v", err)
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// CountAuditActions counts the audit entries of a user per action since a point in time
+func (r *Repository) CountAuditActions(userID int, since time.Time, actions []string) (map[string]int, error) {
+	query := `SELECT action, COUNT(*) AS count FROM audit_log
+			  WHERE user_id = $1 AND created_at >= $2 AND action = ANY($3) GROUP BY action`
+	var rows []struct {
+		Action string `db:"action"`
+		Count  int    `db:"count"`
+	}
+	err := r.db.Select(&rows, query, userID, since, pq.Array(actions))
+	if err != nil {
+		log.Printf("Error counting audit actions: %v", err)
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.Action] = row.Count
+	}
+	return counts, nil
+}
+
+// GetContactsCreatedSince retrieves up to limit of the contacts a user created since a point in time, newest first
+func (r *Repository) GetContactsCreatedSince(userID int, since time.Time, limit int) ([]models.Contact, error) {
+	query := `SELECT ` + contactColumns + ` FROM contacts
+			  WHERE user_id = $1 AND created_at >= $2 AND deleted_at IS NULL ORDER BY created_at DESC, id DESC LIMIT $3`
+	var contacts []models.Contact
+	err := r.db.Select(&contacts, query, userID, since, limit)
+	if err != nil {
+		log.Printf("Error fetching new contacts: %v", err)
+		return nil, err
+	}
+	return contacts, nil
+}
@@ -1,32 +1,56 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/danizion/contact-app/internal/models"
 	"github.com/jmoiron/sqlx"
 )
 
+// contactColumns lists the columns selected into models.Contact
+const contactColumns = `id, user_id, first_name, last_name, phone_number, address, email, company, job_title, timezone,
+	street, city, region, postal_code, country_code, source, stage, board_position,
+	latitude, longitude, created_at, updated_at, deleted_at, last_interacted_at, custom_fields, template_version,
+	client_id, phone_normalized`
+
+// ErrContactNotFound is returned by the changes of a contact when the user has no such contact
+var ErrContactNotFound = errors.New("contact not found or does not belong to the specified user")
+
+// ErrNotFound is wrapped by the errors of the changes matching no row
+var ErrNotFound = errors.New("not found")
+
+// ErrEnrichmentResolved is returned when resolving an enrichment suggestion that was already accepted or rejected
+var ErrEnrichmentResolved = errors.New("enrichment was already accepted or rejected")
+
 // Repository defines the structure of the repository for database interaction
 type Repository struct {
-	db *sqlx.DB
+	db *instrumentedDB
 }
 
 // NewRepository creates a new instance of the Repository
 func NewRepository(db *sql.DB) *Repository {
 	sqlxDB := sqlx.NewDb(db, "postgres")
-	return &Repository{db: sqlxDB}
+	return &Repository{db: &instrumentedDB{DB: sqlxDB, ctx: context.Background()}}
+}
+
+// WithContext returns a repository running its queries under ctx, usually the context of a request: they are
+// aborted when it is canceled or times out
+func (r *Repository) WithContext(ctx context.Context) *Repository {
+	return &Repository{db: &instrumentedDB{DB: r.db.DB, ctx: ctx}}
 }
 
 // CreateUser inserts a new user into the "users" table
 func (r *Repository) CreateUser(user models.User) (int, error) {
-	query := `INSERT INTO users (username, email, hashed_password) 
-			  VALUES ($1, $2, $3) RETURNING id`
+	query := `INSERT INTO users (username, email, hashed_password, is_admin) 
+			  VALUES ($1, $2, $3, $4) RETURNING id`
 	var userID int
-	err := r.db.QueryRow(query, user.Username, user.Email, user.HashedPassword).Scan(&userID)
+	err := r.db.QueryRow(query, user.Username, user.Email, user.HashedPassword, user.IsAdmin).Scan(&userID)
 	if err != nil {
 		log.Printf("Error creating user: %v", err)
 		return 0, err
@@ -36,7 +60,7 @@ func (r *Repository) CreateUser(user models.User) (int, error) {
 
 // GetUser retrieves a user by ID from the "users" table
 func (r *Repository) GetUser(userID int) (*models.User, error) {
-	query := `SELECT id, username, email, hashed_password, created_at, updated_at 
+	query := `SELECT id, username, email, hashed_password, is_admin, state, deactivated_at, created_at, updated_at
 			  FROM users WHERE id = $1`
 	var user models.User
 	err := r.db.Get(&user, query, userID)
@@ -49,7 +73,7 @@ func (r *Repository) GetUser(userID int) (*models.User, error) {
 
 // GetUserByEmail retrieves a user by email from the "users" table
 func (r *Repository) GetUserByEmail(email string) (*models.User, error) {
-	query := `SELECT id, username, email, hashed_password, created_at, updated_at 
+	query := `SELECT id, username, email, hashed_password, is_admin, state, deactivated_at, created_at, updated_at
 			  FROM users WHERE email = $1`
 	var user models.User
 	err := r.db.Get(&user, query, email)
@@ -65,7 +89,7 @@ func (r *Repository) GetUserByEmail(email string) (*models.User, error) {
 
 // GetUserByUsername retrieves a user by username from the "users" table
 func (r *Repository) GetUserByUsername(username string) (*models.User, error) {
-	query := `SELECT id, username, email, hashed_password, created_at, updated_at 
+	query := `SELECT id, username, email, hashed_password, is_admin, state, deactivated_at, created_at, updated_at
 			  FROM users WHERE username = $1`
 	var user models.User
 	err := r.db.Get(&user, query, username)
@@ -79,23 +103,95 @@ func (r *Repository) GetUserByUsername(username string) (*models.User, error) {
 	return &user, nil
 }
 
-// CreateContact inserts a new contact into the "contacts" table
+// SetUserAdmin grants or revokes the admin role of the user with an email, compared case-insensitively, and returns
+// the user, nil when there is none
+func (r *Repository) SetUserAdmin(email string, admin bool) (*models.User, error) {
+	query := `UPDATE users SET is_admin = $2, updated_at = NOW() WHERE LOWER(email) = LOWER($1)
+			  RETURNING id, username, email, hashed_password, is_admin, state, deactivated_at, created_at, updated_at`
+	var user models.User
+	err := r.db.Get(&user, query, email, admin)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Printf("Error setting admin role: %v", err)
+		return nil, err
+	}
+	return &user, nil
+}
+
+// UpdateUserPassword replaces the hashed password of a user
+func (r *Repository) UpdateUserPassword(userID int, hashedPassword string) error {
+	query := `UPDATE users SET hashed_password = $1, updated_at = NOW() WHERE id = $2`
+	_, err := r.db.Exec(query, hashedPassword, userID)
+	if err != nil {
+		log.Printf("Error updating user password: %v", err)
+		return err
+	}
+	return nil
+}
+
+// DeleteUser deletes a user, everything the user owns is deleted with it by the foreign keys. Returns false when
+// there was no such user
+func (r *Repository) DeleteUser(userID int) (bool, error) {
+	result, err := r.db.Exec(`DELETE FROM users WHERE id = $1`, userID)
+	if err != nil {
+		log.Printf("Error deleting user: %v", err)
+		return false, err
+	}
+	deleted, _ := result.RowsAffected()
+	return deleted > 0, nil
+}
+
+// CreateContact inserts a new contact into the "contacts" table and starts its history
 func (r *Repository) CreateContact(contact models.Contact) (int, error) {
-	query := `INSERT INTO contacts (user_id, first_name, last_name, phone_number, address) 
-			  VALUES ($1, $2, $3, $4, $5) RETURNING id`
+	tx, err := r.db.Beginx()
+	if err != nil {
+		log.Printf("Error starting contact transaction: %v", err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	contactID, err := insertContact(tx, contact)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing contact: %v", err)
+		return 0, err
+	}
+	return contactID, nil
+}
+
+// insertContact inserts a new contact and starts its history in tx
+func insertContact(tx *sqlx.Tx, contact models.Contact) (int, error) {
+	// New contacts are appended to the end of their stage column on the board
+	query := `INSERT INTO contacts (user_id, first_name, last_name, phone_number, address, email, company, job_title, timezone,
+								   street, city, region, postal_code, country_code, source, stage, latitude, longitude,
+								   custom_fields, template_version, client_id, phone_normalized, board_position)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22,
+					  (SELECT COALESCE(MAX(board_position), 0) + 1 FROM contacts WHERE user_id = $1 AND stage = $16))
+			  RETURNING id`
 	var contactID int
-	err := r.db.QueryRow(query, contact.UserID, contact.FirstName, contact.LastName, contact.PhoneNumber, contact.Address).Scan(&contactID)
+	err := tx.QueryRow(query, contact.UserID, contact.FirstName, contact.LastName, contact.PhoneNumber, contact.Address,
+		contact.Email, contact.Company, contact.JobTitle, contact.Timezone,
+		contact.Street, contact.City, contact.Region, contact.PostalCode, contact.CountryCode,
+		contact.Source, contact.Stage, contact.Latitude, contact.Longitude, contact.CustomFields, contact.TemplateVersion,
+		contact.ClientID, contact.PhoneNormalized).Scan(&contactID)
 	if err != nil {
 		log.Printf("Error creating contact: %v", err)
 		return 0, err
 	}
+	if err := recordContactAudit(tx, contact.UserID, contactID, ContactAuditCreated, contactChanges(nil, &contact)); err != nil {
+		return 0, err
+	}
 	return contactID, nil
 }
 
 // GetContactsByUser retrieves all contacts for a specific user
 func (r *Repository) GetContactsByUser(userID int) ([]models.Contact, error) {
-	query := `SELECT id, user_id, first_name, last_name, phone_number, address, created_at, updated_at 
-			  FROM contacts WHERE user_id = $1`
+	query := `SELECT ` + contactColumns + `
+			  FROM contacts WHERE user_id = $1 AND deleted_at IS NULL`
 	var contacts []models.Contact
 	err := r.db.Select(&contacts, query, userID)
 	if err != nil {
@@ -105,63 +201,259 @@ func (r *Repository) GetContactsByUser(userID int) ([]models.Contact, error) {
 	return contacts, nil
 }
 
-// GetContactsByUserPaginated retrieves contacts for a user with pagination
-func (r *Repository) GetContactsByUserPaginated(userID int, page, pageSize int, firstName, lastName, phoneNumber string, address string) ([]models.Contact, int, error) {
-	// Calculate offset
-	offset := (page - 1) * pageSize
+// StreamContactsByUser calls fn for every contact of a user ordered by name, rows are read one at a time so
+// exports of any size run in constant memory
+func (r *Repository) StreamContactsByUser(userID int, fn func(models.Contact) error) error {
+	query := `SELECT ` + contactColumns + `
+			  FROM contacts WHERE user_id = $1 AND deleted_at IS NULL ORDER BY last_name, first_name, id`
+	rows, err := r.db.Queryx(query, userID)
+	if err != nil {
+		log.Printf("Error fetching contacts: %v", err)
+		return err
+	}
+	defer rows.Close()
 
+	for rows.Next() {
+		var contact models.Contact
+		if err := rows.StructScan(&contact); err != nil {
+			log.Printf("Error scanning contact: %v", err)
+			return err
+		}
+		if err := fn(contact); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// StreamAllContacts calls fn for every contact of every user in ID order, without loading them all in memory
+func (r *Repository) StreamAllContacts(fn func(models.Contact) error) error {
+	query := `SELECT ` + contactColumns + ` FROM contacts WHERE deleted_at IS NULL ORDER BY id`
+	rows, err := r.db.Queryx(query)
+	if err != nil {
+		log.Printf("Error fetching contacts: %v", err)
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var contact models.Contact
+		if err := rows.StructScan(&contact); err != nil {
+			log.Printf("Error scanning contact: %v", err)
+			return err
+		}
+		if err := fn(contact); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// GetContactByID retrieves a single contact of a user, returns nil when it does not exist or belongs to another user
+func (r *Repository) GetContactByID(userID, contactID int) (*models.Contact, error) {
+	query := `SELECT ` + contactColumns + `
+			  FROM contacts WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`
+	var contact models.Contact
+	err := r.db.Get(&contact, query, contactID, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Printf("Error fetching contact: %v", err)
+		return nil, err
+	}
+	return &contact, nil
+}
+
+// ContactFilter holds the optional filters of contact listings, zero values disable a filter
+type ContactFilter struct {
+	FirstName    string
+	LastName     string
+	PhoneNumber  string
+	Address      string
+	SocialHandle string
+	GroupID      int
+	// Tag is the name of a tag, already normalized
+	Tag string
+	// Query searches first name, last name, phone number and address together, tolerating typos
+	Query string
+	// InactiveSince keeps the contacts without interaction since this time
+	InactiveSince time.Time
+}
+
+// contactSearchDocument is the text searched by ContactFilter.Query, it must match the expression of the
+// idx_contacts_search_trgm index for the index to be used
+const contactSearchDocument = `(first_name || ' ' || last_name || ' ' || phone_number || ' ' || COALESCE(address, ''))`
+
+// contactSearchQuery parses the search terms of parameter n in the search language of the user of parameter 1, the
+// language the user's contacts are indexed with by the contacts_search_vector trigger
+func contactSearchQuery(n int) string {
+	return fmt.Sprintf("plainto_tsquery(user_search_language($1), $%d)", n)
+}
+
+// ContactLastInteraction is the last interaction with a contact, its creation when it never had one. It must match
+// the expression of the idx_contacts_user_interacted index for the index to be used
+const ContactLastInteraction = `COALESCE(last_interacted_at, created_at)`
+
+// ContactSort orders contact listings by Columns then by ID, all ascending or all descending. Columns are trusted
+// column names, callers map the sort requested by users to them
+type ContactSort struct {
+	Columns []string
+	Desc    bool
+	// Relevance orders by how well contacts match the filter's Query instead, best first. Pages are then selected
+	// by number only
+	Relevance bool
+}
+
+// orderByWithID returns an ORDER BY clause ordering by keys then by id, all in direction. Every listing read by
+// pages must order through it: rows with equal keys (timestamps written in the same transaction, equal scores)
+// come in any order otherwise and move between pages from one request to the next
+func orderByWithID(keys []string, direction string) string {
+	terms := make([]string, 0, len(keys)+1)
+	for _, key := range append(append([]string{}, keys...), "id") {
+		terms = append(terms, key+" "+direction)
+	}
+	return " ORDER BY " + strings.Join(terms, ", ")
+}
+
+// ContactPage selects a page of a contact listing: the Size contacts following After when set, the page Page
+// (starting at 1) otherwise
+type ContactPage struct {
+	Page int
+	Size int
+	Sort ContactSort
+	// After holds the values of the sort columns then the ID of the last contact of the previous page
+	After []interface{}
+}
+
+// GetContactsByUserPaginated retrieves a page of the contacts of a user with the total count of contacts matching
+// the filter. more tells whether contacts follow the page. Pages following After are not counted, total is 0: the
+// count reads every matching row, which is what seeking past the previous page avoids
+func (r *Repository) GetContactsByUserPaginated(userID int, page ContactPage, filter ContactFilter) (contacts []models.Contact, total int, more bool, err error) {
+	baseQuery, params := contactFilterQuery(userID, filter)
+	keyset := page.After != nil && !page.Sort.Relevance
+
+	// Get total count
+	if !keyset {
+		countQuery := `SELECT COUNT(*) ` + baseQuery
+		err = r.db.Get(&total, countQuery, params...)
+		if err != nil {
+			log.Printf("Error counting contacts: %v", err)
+			return nil, 0, false, err
+		}
+	}
+
+	sortKey := strings.Join(append(append([]string{}, page.Sort.Columns...), "id"), ", ")
+	direction, comparison := "ASC", ">"
+	if page.Sort.Desc {
+		direction, comparison = "DESC", "<"
+	}
+
+	// Keyset pagination seeks past the previous page through the index instead of reading and skipping its rows
+	offset := 0
+	if keyset {
+		placeholders := make([]string, len(page.After))
+		for i, value := range page.After {
+			params = append(params, value)
+			placeholders[i] = fmt.Sprintf("$%d", len(params))
+		}
+		baseQuery += fmt.Sprintf(" AND (%s) %s (%s)", sortKey, comparison, strings.Join(placeholders, ", "))
+	} else {
+		offset = (page.Page - 1) * page.Size
+	}
+
+	// Get paginated contacts, one more than the page tells whether another page follows
+	orderBy := orderByWithID(page.Sort.Columns, direction)
+	if page.Sort.Relevance && filter.Query != "" {
+		params = append(params, filter.Query)
+		orderBy = orderByWithID([]string{
+			fmt.Sprintf("ts_rank(search_vector, %s)", contactSearchQuery(len(params))),
+			fmt.Sprintf("word_similarity($%d, %s)", len(params), contactSearchDocument),
+		}, "DESC")
+	}
+	limitOffset := fmt.Sprintf("%s LIMIT %d OFFSET %d", orderBy, page.Size+1, offset)
+	query := `SELECT ` + contactColumns + ` ` + baseQuery + limitOffset
+	err = r.db.Select(&contacts, query, params...)
+	if err != nil {
+		log.Printf("Error fetching paginated contacts: %v", err)
+		return nil, 0, false, err
+	}
+
+	if len(contacts) > page.Size {
+		return contacts[:page.Size], total, true, nil
+	}
+	return contacts, total, false, nil
+}
+
+// contactFilterQuery builds the FROM and WHERE clauses selecting a user's contacts matching the optional filters
+func contactFilterQuery(userID int, filter ContactFilter) (string, []interface{}) {
 	// Initialize parameters
 	params := []interface{}{userID}
 	paramIndex := 1
 
 	// Build the base query with conditional filters
-	baseQuery := `FROM contacts WHERE user_id = $1`
+	baseQuery := `FROM contacts WHERE user_id = $1 AND deleted_at IS NULL`
 
 	// Add optional filters if provided
-	if firstName != "" {
+	if filter.FirstName != "" {
 		paramIndex++
 		baseQuery += fmt.Sprintf(" AND first_name ILIKE $%d", paramIndex)
-		params = append(params, "%"+firstName+"%")
+		params = append(params, "%"+filter.FirstName+"%")
 	}
 
-	if lastName != "" {
+	if filter.LastName != "" {
 		paramIndex++
 		baseQuery += fmt.Sprintf(" AND last_name ILIKE $%d", paramIndex)
-		params = append(params, "%"+lastName+"%")
+		params = append(params, "%"+filter.LastName+"%")
 	}
 
-	if phoneNumber != "" {
+	if filter.PhoneNumber != "" {
 		paramIndex++
 		baseQuery += fmt.Sprintf(" AND phone_number ILIKE $%d", paramIndex)
-		params = append(params, "%"+phoneNumber+"%")
+		params = append(params, "%"+filter.PhoneNumber+"%")
 	}
 
-	if address != "" {
+	if filter.Address != "" {
 		paramIndex++
 		baseQuery += fmt.Sprintf(" AND address ILIKE $%d", paramIndex)
-		params = append(params, "%"+address+"%")
+		params = append(params, "%"+filter.Address+"%")
 	}
 
-	// Get total count
-	var total int
-	countQuery := `SELECT COUNT(*) ` + baseQuery
-	err := r.db.Get(&total, countQuery, params...)
-	if err != nil {
-		log.Printf("Error counting contacts: %v", err)
-		return nil, 0, err
+	if filter.SocialHandle != "" {
+		paramIndex++
+		baseQuery += fmt.Sprintf(" AND EXISTS (SELECT 1 FROM contact_social_profiles sp WHERE sp.contact_id = contacts.id AND sp.handle ILIKE $%d)", paramIndex)
+		params = append(params, "%"+strings.TrimPrefix(filter.SocialHandle, "@")+"%")
 	}
 
-	// Get paginated contacts
-	limitOffset := fmt.Sprintf(" ORDER BY id LIMIT %d OFFSET %d", pageSize, offset)
-	query := `SELECT id, user_id, first_name, last_name, phone_number, address, created_at, updated_at ` + baseQuery + limitOffset
-	var contacts []models.Contact
-	err = r.db.Select(&contacts, query, params...)
-	if err != nil {
-		log.Printf("Error fetching paginated contacts: %v", err)
-		return nil, 0, err
+	if filter.GroupID != 0 {
+		paramIndex++
+		baseQuery += fmt.Sprintf(" AND EXISTS (SELECT 1 FROM contact_groups cg WHERE cg.contact_id = contacts.id AND cg.group_id = $%d)", paramIndex)
+		params = append(params, filter.GroupID)
 	}
 
-	return contacts, total, nil
+	if filter.Tag != "" {
+		paramIndex++
+		baseQuery += fmt.Sprintf(" AND EXISTS (SELECT 1 FROM contact_tags ct JOIN tags t ON t.id = ct.tag_id WHERE ct.contact_id = contacts.id AND t.name = $%d)", paramIndex)
+		params = append(params, filter.Tag)
+	}
+
+	// Full-text search matches the words in the user's language (stems, accents), the trigram operator misspelled
+	// words and the ILIKE fallback short terms trigrams miss
+	if filter.Query != "" {
+		paramIndex++
+		baseQuery += fmt.Sprintf(" AND (search_vector @@ %s OR $%d <%% %s OR %s ILIKE $%d)",
+			contactSearchQuery(paramIndex), paramIndex, contactSearchDocument, contactSearchDocument, paramIndex+1)
+		params = append(params, filter.Query, "%"+filter.Query+"%")
+		paramIndex++
+	}
+
+	if !filter.InactiveSince.IsZero() {
+		paramIndex++
+		baseQuery += fmt.Sprintf(" AND %s < $%d", ContactLastInteraction, paramIndex)
+		params = append(params, filter.InactiveSince)
+	}
+
+	return baseQuery, params
 }
 
 // GetContactsTotalCount retrieves only the total count of contacts matching the criteria
@@ -171,7 +463,7 @@ func (r *Repository) GetContactsTotalCount(userID int, firstName, lastName, phon
 	paramIndex := 1
 
 	// Build the base query with conditional filters
-	baseQuery := `FROM contacts WHERE user_id = $1`
+	baseQuery := `FROM contacts WHERE user_id = $1 AND deleted_at IS NULL`
 
 	// Add optional filters if provided
 	if firstName != "" {
@@ -204,19 +496,37 @@ func (r *Repository) GetContactsTotalCount(userID int, firstName, lastName, phon
 	return total, nil
 }
 
-// UpdateContact updates an existing contact in the database
+// UpdateContact updates an existing contact in the database, the changed values are recorded in its history
 func (r *Repository) UpdateContact(contact models.Contact, updateFields map[string]bool) error {
-	// First verify the contact exists and belongs to the specified user
-	checkQuery := `SELECT COUNT(*) FROM contacts WHERE id = $1 AND user_id = $2`
-	var count int
-	err := r.db.Get(&count, checkQuery, contact.ID, contact.UserID)
+	tx, err := r.db.Beginx()
 	if err != nil {
-		log.Printf("Error checking contact ownership: %v", err)
+		log.Printf("Error starting contact transaction: %v", err)
 		return err
 	}
+	defer tx.Rollback()
 
-	if count == 0 {
-		return fmt.Errorf("contact not found or does not belong to the specified user")
+	if err := updateContact(tx, contact, updateFields); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing contact update: %v", err)
+		return err
+	}
+	return nil
+}
+
+// updateContact updates the updateFields of an existing contact and records the changed values in its history in tx
+func updateContact(tx *sqlx.Tx, contact models.Contact, updateFields map[string]bool) error {
+	// First verify the contact exists and belongs to the specified user, locking it until the history is recorded
+	checkQuery := `SELECT ` + contactColumns + ` FROM contacts WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL FOR UPDATE`
+	var before models.Contact
+	err := tx.Get(&before, checkQuery, contact.ID, contact.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrContactNotFound
+		}
+		log.Printf("Error checking contact ownership: %v", err)
+		return err
 	}
 
 	// Build dynamic update query based on provided fields
@@ -243,6 +553,9 @@ func (r *Repository) UpdateContact(contact models.Contact, updateFields map[stri
 		paramIndex++
 		updates = append(updates, fmt.Sprintf(" phone_number = $%d", paramIndex))
 		params = append(params, contact.PhoneNumber)
+		paramIndex++
+		updates = append(updates, fmt.Sprintf(" phone_normalized = $%d", paramIndex))
+		params = append(params, contact.PhoneNormalized)
 	}
 
 	if updateFields["address"] {
@@ -251,6 +564,82 @@ func (r *Repository) UpdateContact(contact models.Contact, updateFields map[stri
 		params = append(params, contact.Address)
 	}
 
+	if updateFields["email"] {
+		paramIndex++
+		updates = append(updates, fmt.Sprintf(" email = $%d", paramIndex))
+		params = append(params, contact.Email)
+	}
+
+	if updateFields["company"] {
+		paramIndex++
+		updates = append(updates, fmt.Sprintf(" company = $%d", paramIndex))
+		params = append(params, contact.Company)
+	}
+
+	if updateFields["job_title"] {
+		paramIndex++
+		updates = append(updates, fmt.Sprintf(" job_title = $%d", paramIndex))
+		params = append(params, contact.JobTitle)
+	}
+
+	if updateFields["timezone"] {
+		paramIndex++
+		updates = append(updates, fmt.Sprintf(" timezone = $%d", paramIndex))
+		params = append(params, contact.Timezone)
+	}
+
+	// Structured address fields are always updated together since the free-text address is derived from them
+	if updateFields["structured_address"] {
+		for _, field := range []struct {
+			column string
+			value  string
+		}{
+			{"street", contact.Street},
+			{"city", contact.City},
+			{"region", contact.Region},
+			{"postal_code", contact.PostalCode},
+			{"country_code", contact.CountryCode},
+		} {
+			paramIndex++
+			updates = append(updates, fmt.Sprintf(" %s = $%d", field.column, paramIndex))
+			params = append(params, field.value)
+		}
+	}
+
+	if updateFields["location"] {
+		paramIndex++
+		updates = append(updates, fmt.Sprintf(" latitude = $%d", paramIndex))
+		params = append(params, contact.Latitude)
+		paramIndex++
+		updates = append(updates, fmt.Sprintf(" longitude = $%d", paramIndex))
+		params = append(params, contact.Longitude)
+	}
+
+	if updateFields["source"] {
+		paramIndex++
+		updates = append(updates, fmt.Sprintf(" source = $%d", paramIndex))
+		params = append(params, contact.Source)
+	}
+
+	if updateFields["stage"] {
+		paramIndex++
+		updates = append(updates, fmt.Sprintf(" stage = $%d", paramIndex))
+		params = append(params, contact.Stage)
+		// A contact changing stage is appended to the end of its new column on the board, like a new contact
+		if contact.Stage != before.Stage {
+			paramIndex++
+			updates = append(updates, fmt.Sprintf(" board_position = (SELECT COALESCE(MAX(board_position), 0) + 1 FROM contacts WHERE user_id = $%d AND stage = $%d)", paramIndex, paramIndex-1))
+			params = append(params, contact.UserID)
+		}
+	}
+
+	// Custom fields are sent whole, merged with the stored ones by the service
+	if updateFields["custom_fields"] {
+		paramIndex++
+		updates = append(updates, fmt.Sprintf(" custom_fields = $%d", paramIndex))
+		params = append(params, contact.CustomFields)
+	}
+
 	// If no fields to update, return early
 	if len(updates) == 0 {
 		return nil
@@ -274,44 +663,39 @@ func (r *Repository) UpdateContact(contact models.Contact, updateFields map[stri
 	params = append(params, contact.UserID)
 
 	// Execute the update
-	_, err = r.db.Exec(query, params...)
+	var after models.Contact
+	err = tx.Get(&after, query+` RETURNING `+contactColumns, params...)
 	if err != nil {
 		log.Printf("Error updating contact: %v", err)
 		return err
 	}
 
+	if changes := contactChanges(&before, &after); len(changes) > 0 {
+		if err := recordContactAudit(tx, contact.UserID, contact.ID, ContactAuditUpdated, changes); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-// DeleteContact deletes a contact by ID and user ID
-func (r *Repository) DeleteContact(userID, contactID int) error {
-	// First verify the contact exists and belongs to the specified user
-	checkQuery := `SELECT COUNT(*) FROM contacts WHERE  user_id = $1 AND id = $2`
-	var count int
-	err := r.db.Get(&count, checkQuery, contactID, userID)
-	if err != nil {
-		log.Printf("Error checking contact ownership: %v", err)
-		return err
-	}
-
-	if count == 0 {
-		return fmt.Errorf("contact not found or does not belong to the specified user")
-	}
-
-	// Delete the contact
-	query := `DELETE FROM contacts WHERE user_id = $1 AND id = $2`
-	_, err = r.db.Exec(query, contactID, userID)
+// GetContactIDByClientID returns the ID of the contact of a user with a client_id, in the trash or not, 0 when there
+// is none
+func (r *Repository) GetContactIDByClientID(userID int, clientID string) (int, error) {
+	var contactID int
+	err := r.db.Get(&contactID, `SELECT id FROM contacts WHERE user_id = $1 AND client_id = $2`, userID, clientID)
 	if err != nil {
-		log.Printf("Error deleting contact: %v", err)
-		return err
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		log.Printf("Error fetching contact by client_id: %v", err)
+		return 0, err
 	}
-
-	return nil
+	return contactID, nil
 }
 
 // IsContactExists checks if a contact with the same first and last name exists for a user
 func (r *Repository) IsContactExists(userID int, firstName, lastName string) (bool, error) {
-	query := `SELECT COUNT(*) FROM contacts WHERE user_id = $1 AND first_name = $2 AND last_name = $3`
+	query := `SELECT COUNT(*) FROM contacts WHERE user_id = $1 AND first_name = $2 AND last_name = $3 AND deleted_at IS NULL`
 	var count int
 	err := r.db.Get(&count, query, userID, firstName, lastName)
 	if err != nil {
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"log"
+)
+
+// GetSearchLanguages returns the text search configurations of the database, the languages contacts can be indexed in
+func (r *Repository) GetSearchLanguages() ([]string, error) {
+	query := `SELECT cfgname FROM pg_ts_config ORDER BY cfgname`
+	var languages []string
+	err := r.db.Select(&languages, query)
+	if err != nil {
+		log.Printf("Error fetching search languages: %v", err)
+		return nil, err
+	}
+	return languages, nil
+}
+
+// ReindexContactSearch reindexes up to limit contacts indexed in another language than their owner's and returns the
+// owners of the reindexed contacts, one per contact. The contacts_search_vector trigger recomputes the search vector
+// when the language changes; contacts locked by a concurrent reindex are skipped
+func (r *Repository) ReindexContactSearch(limit int) ([]int, error) {
+	query := `WITH stale AS (
+				SELECT c.id, l.language FROM contacts c
+				JOIN (SELECT id, user_search_language(id) AS language FROM users) l ON l.id = c.user_id
+				WHERE c.search_language IS DISTINCT FROM l.language
+				LIMIT $1 FOR UPDATE OF c SKIP LOCKED
+			  )
+			  UPDATE contacts SET search_language = stale.language FROM stale WHERE contacts.id = stale.id
+			  RETURNING contacts.user_id`
+	var userIDs []int
+	err := r.db.Select(&userIDs, query, limit)
+	if err != nil {
+		log.Printf("Error reindexing contact search: %v", err)
+		return nil, err
+	}
+	return userIDs, nil
+}
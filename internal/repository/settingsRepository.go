@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"database/sql"
+	"log"
+)
+
+// GetInstanceSetting retrieves an instance wide setting, found is false when it was never saved
+func (r *Repository) GetInstanceSetting(key string) (value string, found bool, err error) {
+	query := `SELECT value FROM instance_settings WHERE key = $1`
+	err = r.db.Get(&value, query, key)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", false, nil
+		}
+		log.Printf("Error fetching instance setting %s: %v", key, err)
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+// SaveInstanceSetting inserts or updates an instance wide setting
+func (r *Repository) SaveInstanceSetting(key, value string) error {
+	query := `INSERT INTO instance_settings (key, value) VALUES ($1, $2)
+			  ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, updated_at = NOW()`
+	_, err := r.db.Exec(query, key, value)
+	if err != nil {
+		log.Printf("Error saving instance setting %s: %v", key, err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/danizion/contact-app/internal/models"
+)
+
+const sharedContactColumns = `status, submitted_at, reviewed_by, reviewed_at, review_note, changed_at`
+
+// SubmitSharedContact submits a contact to the shared address book with status, a contact submitted again is
+// reviewed again
+func (r *Repository) SubmitSharedContact(contactID int, status string) error {
+	query := `INSERT INTO shared_contacts (contact_id, status) VALUES ($1, $2)
+			  ON CONFLICT (contact_id) DO UPDATE SET status = EXCLUDED.status, submitted_at = NOW(),
+			  reviewed_by = NULL, reviewed_at = NULL, review_note = ''`
+	_, err := r.db.Exec(query, contactID, status)
+	if err != nil {
+		log.Printf("Error submitting shared contact: %v", err)
+		return err
+	}
+	return nil
+}
+
+// RemoveSharedContact takes a contact out of the shared address book, it returns false when it was not submitted
+func (r *Repository) RemoveSharedContact(contactID int) (bool, error) {
+	result, err := r.db.Exec(`DELETE FROM shared_contacts WHERE contact_id = $1`, contactID)
+	if err != nil {
+		log.Printf("Error removing shared contact: %v", err)
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("Error getting rows affected: %v", err)
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// GetSharedContact returns a submitted contact with its review, nil when it was not submitted or is deleted
+func (r *Repository) GetSharedContact(contactID int) (*models.SharedContact, error) {
+	query := `SELECT ` + contactColumns + `, ` + sharedContactColumns + `
+			  FROM contacts JOIN shared_contacts ON shared_contacts.contact_id = contacts.id
+			  WHERE contacts.id = $1 AND deleted_at IS NULL`
+	var contact models.SharedContact
+	err := r.db.Get(&contact, query, contactID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Printf("Error fetching shared contact: %v", err)
+		return nil, err
+	}
+	return &contact, nil
+}
+
+// GetSharedContacts returns the submitted contacts in a status, the oldest submission first. Deleted contacts are
+// left out until they are restored
+func (r *Repository) GetSharedContacts(status string) ([]models.SharedContact, error) {
+	query := `SELECT ` + contactColumns + `, ` + sharedContactColumns + `
+			  FROM contacts JOIN shared_contacts ON shared_contacts.contact_id = contacts.id
+			  WHERE status = $1 AND deleted_at IS NULL
+			  ORDER BY submitted_at, contacts.id`
+	var contacts []models.SharedContact
+	err := r.db.Select(&contacts, query, status)
+	if err != nil {
+		log.Printf("Error fetching shared contacts: %v", err)
+		return nil, err
+	}
+	return contacts, nil
+}
+
+// ReviewSharedContact approves or rejects a pending submission with status, it returns false when the contact is
+// not pending anymore so two reviewers cannot both decide
+func (r *Repository) ReviewSharedContact(contactID, reviewerID int, status, note string) (bool, error) {
+	query := `UPDATE shared_contacts SET status = $3, reviewed_by = $2, reviewed_at = NOW(), review_note = $4
+			  WHERE contact_id = $1 AND status = 'pending'`
+	result, err := r.db.Exec(query, contactID, reviewerID, status, note)
+	if err != nil {
+		log.Printf("Error reviewing shared contact: %v", err)
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("Error getting rows affected: %v", err)
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// GetSubmittedContacts returns the contacts a user submitted to the shared address book in any status, the latest
+// submission first
+func (r *Repository) GetSubmittedContacts(userID int) ([]models.SharedContact, error) {
+	query := `SELECT ` + contactColumns + `, ` + sharedContactColumns + `
+			  FROM contacts JOIN shared_contacts ON shared_contacts.contact_id = contacts.id
+			  WHERE user_id = $1 AND deleted_at IS NULL
+			  ORDER BY submitted_at DESC, contacts.id DESC`
+	var contacts []models.SharedContact
+	err := r.db.Select(&contacts, query, userID)
+	if err != nil {
+		log.Printf("Error fetching submitted contacts: %v", err)
+		return nil, err
+	}
+	return contacts, nil
+}
+
+// MarkSharedContactChanged records that the owner of a contact changed it, it returns false when the contact is not
+// shared with every user
+func (r *Repository) MarkSharedContactChanged(contactID int) (bool, error) {
+	result, err := r.db.Exec(`UPDATE shared_contacts SET changed_at = NOW() WHERE contact_id = $1 AND status = 'shared'`, contactID)
+	if err != nil {
+		log.Printf("Error marking shared contact changed: %v", err)
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("Error getting rows affected: %v", err)
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// RecordSharedContactView records that a user viewed a shared contact now
+func (r *Repository) RecordSharedContactView(userID, contactID int) error {
+	_, err := r.db.Exec(`INSERT INTO shared_contact_views (user_id, contact_id) VALUES ($1, $2)
+			  ON CONFLICT (user_id, contact_id) DO UPDATE SET viewed_at = NOW()`, userID, contactID)
+	if err != nil {
+		log.Printf("Error recording shared contact view: %v", err)
+	}
+	return err
+}
+
+// GetSharedContactViews returns when a user last viewed each shared contact, by contact ID
+func (r *Repository) GetSharedContactViews(userID int) (map[int]time.Time, error) {
+	var views []struct {
+		ContactID int       `db:"contact_id"`
+		ViewedAt  time.Time `db:"viewed_at"`
+	}
+	err := r.db.Select(&views, `SELECT contact_id, viewed_at FROM shared_contact_views WHERE user_id = $1`, userID)
+	if err != nil {
+		log.Printf("Error fetching shared contact views: %v", err)
+		return nil, err
+	}
+	result := make(map[int]time.Time, len(views))
+	for _, view := range views {
+		result[view.ContactID] = view.ViewedAt
+	}
+	return result, nil
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/lib/pq"
+)
+
+// CreateSnapshot inserts a new snapshot into the "contact_snapshots" table
+func (r *Repository) CreateSnapshot(snapshot models.ContactSnapshot) (int, error) {
+	query := `INSERT INTO contact_snapshots (user_id, name, contact_count, contacts)
+			  VALUES ($1, $2, $3, $4) RETURNING id`
+	var snapshotID int
+	err := r.db.QueryRow(query, snapshot.UserID, snapshot.Name, snapshot.ContactCount, snapshot.Contacts).Scan(&snapshotID)
+	if err != nil {
+		log.Printf("Error creating snapshot: %v", err)
+		return 0, err
+	}
+	return snapshotID, nil
+}
+
+// GetSnapshotsByUser retrieves the snapshots of a user, newest first, without their contacts
+func (r *Repository) GetSnapshotsByUser(userID int) ([]models.ContactSnapshot, error) {
+	query := `SELECT id, user_id, name, contact_count, created_at
+			  FROM contact_snapshots WHERE user_id = $1 ORDER BY created_at DESC, id DESC`
+	var snapshots []models.ContactSnapshot
+	err := r.db.Select(&snapshots, query, userID)
+	if err != nil {
+		log.Printf("Error fetching snapshots: %v", err)
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// CountSnapshotsByUser counts the snapshots of a user
+func (r *Repository) CountSnapshotsByUser(userID int) (int, error) {
+	var count int
+	err := r.db.Get(&count, `SELECT COUNT(*) FROM contact_snapshots WHERE user_id = $1`, userID)
+	if err != nil {
+		log.Printf("Error counting snapshots: %v", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetSnapshot retrieves a snapshot of a user with its contacts, returns nil if it does not exist
+func (r *Repository) GetSnapshot(userID, snapshotID int) (*models.ContactSnapshot, error) {
+	query := `SELECT id, user_id, name, contact_count, contacts, created_at
+			  FROM contact_snapshots WHERE id = $1 AND user_id = $2`
+	var snapshot models.ContactSnapshot
+	err := r.db.Get(&snapshot, query, snapshotID, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Printf("Error fetching snapshot: %v", err)
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// DeleteSnapshot removes a snapshot
+func (r *Repository) DeleteSnapshot(userID, snapshotID int) error {
+	result, err := r.db.Exec(`DELETE FROM contact_snapshots WHERE id = $1 AND user_id = $2`, snapshotID, userID)
+	if err != nil {
+		log.Printf("Error deleting snapshot: %v", err)
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("snapshot %w or does not belong to the specified user", ErrNotFound)
+	}
+	return nil
+}
+
+// ReplaceContacts makes a user's contacts exactly the given ones in one transaction: contacts missing from the list
+// are moved to the trash, existing ones overwritten (and taken out of the trash) and the others re-created with their
+// original IDs
+func (r *Repository) ReplaceContacts(userID int, contacts []models.Contact) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		log.Printf("Error starting restore transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	contactIDs := make([]int, len(contacts))
+	for i, contact := range contacts {
+		contactIDs[i] = contact.ID
+	}
+	_, err = tx.Exec(`WITH trashed AS (
+				UPDATE contacts SET deleted_at = NOW() WHERE user_id = $1 AND deleted_at IS NULL AND NOT (id = ANY($2)) RETURNING id
+			  )
+			  INSERT INTO contact_audit (contact_id, user_id, action) SELECT id, $1, $3 FROM trashed`,
+		userID, pq.Array(contactIDs), ContactAuditDeleted)
+	if err != nil {
+		log.Printf("Error trashing contacts missing from snapshot: %v", err)
+		return err
+	}
+
+	stmt, err := tx.Preparex(`INSERT INTO contacts (id, user_id, first_name, last_name, phone_number, address, email, company, job_title,
+								   timezone, street, city, region, postal_code, country_code, latitude, longitude, source, stage,
+								   phone_normalized, board_position)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20,
+					  (SELECT COALESCE(MAX(board_position), 0) + 1 FROM contacts WHERE user_id = $2 AND stage = $19))
+			  ON CONFLICT (id) DO UPDATE SET
+					  first_name = EXCLUDED.first_name, last_name = EXCLUDED.last_name, phone_number = EXCLUDED.phone_number,
+					  address = EXCLUDED.address, email = EXCLUDED.email, company = EXCLUDED.company, job_title = EXCLUDED.job_title,
+					  timezone = EXCLUDED.timezone, street = EXCLUDED.street, city = EXCLUDED.city, region = EXCLUDED.region,
+					  postal_code = EXCLUDED.postal_code, country_code = EXCLUDED.country_code,
+					  latitude = EXCLUDED.latitude, longitude = EXCLUDED.longitude,
+					  source = EXCLUDED.source, stage = EXCLUDED.stage, phone_normalized = EXCLUDED.phone_normalized,
+					  updated_at = NOW(), deleted_at = NULL
+			  WHERE contacts.user_id = EXCLUDED.user_id`)
+	if err != nil {
+		log.Printf("Error preparing contact restore: %v", err)
+		return err
+	}
+	defer stmt.Close()
+
+	for _, contact := range contacts {
+		_, err = stmt.Exec(contact.ID, userID, contact.FirstName, contact.LastName, contact.PhoneNumber, contact.Address,
+			contact.Email, contact.Company, contact.JobTitle, contact.Timezone,
+			contact.Street, contact.City, contact.Region, contact.PostalCode, contact.CountryCode,
+			contact.Latitude, contact.Longitude, contact.Source, contact.Stage, contact.PhoneNormalized)
+		if err != nil {
+			log.Printf("Error restoring contact %d: %v", contact.ID, err)
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/lib/pq"
+)
+
+// UpsertSocialProfile sets the profile of a contact on a social network, replacing the previous one
+func (r *Repository) UpsertSocialProfile(profile models.SocialProfile) error {
+	query := `INSERT INTO contact_social_profiles (contact_id, network, handle, url)
+			  VALUES ($1, $2, $3, $4)
+			  ON CONFLICT (contact_id, network) DO UPDATE SET handle = EXCLUDED.handle, url = EXCLUDED.url`
+	_, err := r.db.Exec(query, profile.ContactID, profile.Network, profile.Handle, profile.URL)
+	if err != nil {
+		log.Printf("Error saving social profile: %v", err)
+		return err
+	}
+	return nil
+}
+
+// AddSocialProfileIfMissing sets the profile of a contact on a social network only when it has none yet
+func (r *Repository) AddSocialProfileIfMissing(profile models.SocialProfile) error {
+	query := `INSERT INTO contact_social_profiles (contact_id, network, handle, url)
+			  VALUES ($1, $2, $3, $4)
+			  ON CONFLICT (contact_id, network) DO NOTHING`
+	_, err := r.db.Exec(query, profile.ContactID, profile.Network, profile.Handle, profile.URL)
+	if err != nil {
+		log.Printf("Error adding social profile: %v", err)
+		return err
+	}
+	return nil
+}
+
+// DeleteSocialProfile removes the profile of a contact on a social network
+func (r *Repository) DeleteSocialProfile(contactID int, network string) error {
+	result, err := r.db.Exec(`DELETE FROM contact_social_profiles WHERE contact_id = $1 AND network = $2`, contactID, network)
+	if err != nil {
+		log.Printf("Error deleting social profile: %v", err)
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("social profile %w", ErrNotFound)
+	}
+	return nil
+}
+
+// GetSocialProfilesByUser retrieves the social profiles of the contacts of a user, trashed contacts left out
+func (r *Repository) GetSocialProfilesByUser(userID int) ([]models.SocialProfile, error) {
+	query := `SELECT p.id, p.contact_id, p.network, p.handle, p.url, p.created_at
+			  FROM contact_social_profiles p JOIN contacts c ON c.id = p.contact_id
+			  WHERE c.user_id = $1 AND c.deleted_at IS NULL ORDER BY p.contact_id, p.network`
+	var profiles []models.SocialProfile
+	err := r.db.Select(&profiles, query, userID)
+	if err != nil {
+		log.Printf("Error fetching social profiles: %v", err)
+		return nil, err
+	}
+	return profiles, nil
+}
+
+// GetSocialProfilesByContacts retrieves the social profiles of several contacts at once
+func (r *Repository) GetSocialProfilesByContacts(contactIDs []int) ([]models.SocialProfile, error) {
+	if len(contactIDs) == 0 {
+		return nil, nil
+	}
+
+	query := `SELECT id, contact_id, network, handle, url, created_at
+			  FROM contact_social_profiles WHERE contact_id = ANY($1) ORDER BY contact_id, network`
+	var profiles []models.SocialProfile
+	err := r.db.Select(&profiles, query, pq.Array(contactIDs))
+	if err != nil {
+		log.Printf("Error fetching social profiles: %v", err)
+		return nil, err
+	}
+	return profiles, nil
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"log"
+	"time"
+)
+
+// InstanceCounts holds the instance wide totals of the admin stats
+type InstanceCounts struct {
+	Users       int `db:"users"`
+	Contacts    int `db:"contacts"`
+	ActiveUsers int `db:"active_users"`
+	DigestsDue  int `db:"digests_due"`
+}
+
+// GetInstanceCounts counts users, contacts (from the per-user totals), the users active (logged in or changing data) since activeSince and the
+// weekly digests not sent since digestsBefore, across every account
+func (r *Repository) GetInstanceCounts(activeSince, digestsBefore time.Time) (*InstanceCounts, error) {
+	query := `SELECT
+				(SELECT COUNT(*) FROM users) AS users,
+				(SELECT COALESCE(SUM(contacts), 0) FROM user_counts) AS contacts,
+				(SELECT COUNT(DISTINCT actor_id) FROM audit_log WHERE created_at >= $1) AS active_users,
+				(SELECT COUNT(*) FROM user_preferences
+				 WHERE weekly_digest AND (digest_sent_at IS NULL OR digest_sent_at < $2)) AS digests_due`
+	var counts InstanceCounts
+	err := r.db.Get(&counts, query, activeSince, digestsBefore)
+	if err != nil {
+		log.Printf("Error counting instance stats: %v", err)
+		return nil, err
+	}
+	return &counts, nil
+}
+
+// CountAnalyticsEventsByName counts the analytics events recorded since a time, by event name
+func (r *Repository) CountAnalyticsEventsByName(since time.Time) (map[string]int, error) {
+	query := `SELECT name, COUNT(*) AS count FROM analytics_events WHERE occurred_at >= $1 GROUP BY name`
+	var rows []struct {
+		Name  string `db:"name"`
+		Count int    `db:"count"`
+	}
+	err := r.db.Select(&rows, query, since)
+	if err != nil {
+		log.Printf("Error counting analytics events: %v", err)
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.Name] = row.Count
+	}
+	return counts, nil
+}
+
+// CountUserContacts returns the number of contacts of a user outside the trash, kept up to date by triggers
+func (r *Repository) CountUserContacts(userID int) (int, error) {
+	var count int
+	err := r.db.Get(&count, `SELECT COALESCE((SELECT contacts FROM user_counts WHERE user_id = $1), 0)`, userID)
+	if err != nil {
+		log.Printf("Error fetching contact count: %v", err)
+		return 0, err
+	}
+	return count, nil
+}
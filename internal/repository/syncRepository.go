@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/lib/pq"
+)
+
+// Operations of a contact mutation
+const (
+	MutationCreate = "create"
+	MutationUpdate = "update"
+	MutationDelete = "delete"
+)
+
+// ErrContactVersionChanged is returned by ApplyContactMutations when a contact changed after its mutations were
+// prepared
+var ErrContactVersionChanged = errors.New("contact changed while the batch was applied")
+
+// ContactMutation is a change to a contact of a batch applied by ApplyContactMutations
+type ContactMutation struct {
+	Op string
+	// Contact is the new contact to create, or the contact to update with the values of UpdateFields. Only its ID is
+	// read to delete it
+	Contact      models.Contact
+	UpdateFields map[string]bool
+	// Version is the version of the contact the mutation was prepared on, checked for updates and deletes
+	Version int64
+}
+
+// ApplyContactMutations applies mutations to the contacts of a user in order, all of them or none. It returns the
+// IDs of the contacts by mutation, the new ones for creates, with the versions the contacts have then. When batchID
+// is set the outcome built by result is stored with the changes, so a replayed batch returns it.
+// ErrContactVersionChanged is returned when a contact updated or deleted changed since its mutation was prepared
+func (r *Repository) ApplyContactMutations(userID int, mutations []ContactMutation, batchID string,
+	result func(ids []int, versions map[int]int64) ([]byte, error)) ([]int, map[int]int64, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		log.Printf("Error starting sync batch transaction: %v", err)
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	ids := make([]int, len(mutations))
+	checked := make(map[int]bool)
+	for i, mutation := range mutations {
+		contact := mutation.Contact
+		contact.UserID = userID
+		if mutation.Op != MutationCreate && !checked[contact.ID] {
+			// The first change of a contact checks its version, the next ones see the changes of the batch. The row is
+			// locked first, so a change committed by another transaction is either in the version read or waits
+			// for this one to end
+			var lockedID int
+			err := tx.Get(&lockedID, `SELECT id FROM contacts WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+										FOR UPDATE`, contact.ID, userID)
+			if err == sql.ErrNoRows {
+				return nil, nil, ErrContactVersionChanged
+			}
+			if err != nil {
+				log.Printf("Error locking contact: %v", err)
+				return nil, nil, err
+			}
+			var version int64
+			err = tx.Get(&version, `SELECT COALESCE(MAX(id), 0) FROM contact_audit WHERE contact_id = $1`, contact.ID)
+			if err != nil {
+				log.Printf("Error checking contact version: %v", err)
+				return nil, nil, err
+			}
+			if version != mutation.Version {
+				return nil, nil, ErrContactVersionChanged
+			}
+			checked[contact.ID] = true
+		}
+
+		switch mutation.Op {
+		case MutationCreate:
+			if ids[i], err = insertContact(tx, contact); err != nil {
+				return nil, nil, err
+			}
+		case MutationUpdate:
+			if err := updateContact(tx, contact, mutation.UpdateFields); err != nil {
+				return nil, nil, err
+			}
+			ids[i] = contact.ID
+		case MutationDelete:
+			if err := trashContact(tx, userID, contact.ID, true); err != nil {
+				return nil, nil, err
+			}
+			ids[i] = contact.ID
+		default:
+			return nil, nil, fmt.Errorf("unknown mutation %q", mutation.Op)
+		}
+	}
+
+	versions := make(map[int]int64, len(ids))
+	rows, err := tx.Queryx(`SELECT contact_id, MAX(id) FROM contact_audit WHERE contact_id = ANY($1) GROUP BY contact_id`, pq.Array(ids))
+	if err != nil {
+		log.Printf("Error fetching contact versions: %v", err)
+		return nil, nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var contactID int
+		var version int64
+		if err := rows.Scan(&contactID, &version); err != nil {
+			return nil, nil, err
+		}
+		versions[contactID] = version
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	if batchID != "" {
+		encoded, err := result(ids, versions)
+		if err != nil {
+			return nil, nil, err
+		}
+		_, err = tx.Exec(`INSERT INTO sync_batches (user_id, batch_id, result) VALUES ($1, $2, $3)`, userID, batchID, encoded)
+		if err != nil {
+			log.Printf("Error saving sync batch: %v", err)
+			return nil, nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing sync batch: %v", err)
+		return nil, nil, err
+	}
+	return ids, versions, nil
+}
+
+// GetSyncBatchResult returns the stored outcome of a batch of a user, nil when it was not applied
+func (r *Repository) GetSyncBatchResult(userID int, batchID string) ([]byte, error) {
+	var result []byte
+	err := r.db.Get(&result, `SELECT result FROM sync_batches WHERE user_id = $1 AND batch_id = $2`, userID, batchID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Printf("Error fetching sync batch: %v", err)
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteSyncBatchesBefore forgets the outcome of the batches applied before cutoff, returns how many were deleted
+func (r *Repository) DeleteSyncBatchesBefore(cutoff time.Time) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM sync_batches WHERE created_at < $1`, cutoff)
+	if err != nil {
+		log.Printf("Error deleting sync batches: %v", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/lib/pq"
+)
+
+// CreateTag inserts a new tag into the "tags" table
+func (r *Repository) CreateTag(userID int, name string) (int, error) {
+	query := `INSERT INTO tags (user_id, name) VALUES ($1, $2) RETURNING id`
+	var tagID int
+	err := r.db.QueryRow(query, userID, name).Scan(&tagID)
+	if err != nil {
+		log.Printf("Error creating tag: %v", err)
+		return 0, err
+	}
+	return tagID, nil
+}
+
+// DeleteTag removes a tag of a user by name, its contacts are only untagged
+func (r *Repository) DeleteTag(userID int, name string) error {
+	result, err := r.db.Exec(`DELETE FROM tags WHERE user_id = $1 AND name = $2`, userID, name)
+	if err != nil {
+		log.Printf("Error deleting tag: %v", err)
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("tag %w or does not belong to the specified user", ErrNotFound)
+	}
+	return nil
+}
+
+// GetOrCreateTag returns the tag of a user with this name, creating it when missing
+func (r *Repository) GetOrCreateTag(userID int, name string) (int, error) {
+	// The no-op update makes RETURNING yield the existing row on conflict
+	query := `INSERT INTO tags (user_id, name) VALUES ($1, $2)
+			  ON CONFLICT (user_id, name) DO UPDATE SET name = EXCLUDED.name
+			  RETURNING id`
+	var tagID int
+	err := r.db.QueryRow(query, userID, name).Scan(&tagID)
+	if err != nil {
+		log.Printf("Error creating tag: %v", err)
+		return 0, err
+	}
+	return tagID, nil
+}
+
+// GetTagByName retrieves a tag of a user by name, returns nil if it does not exist
+func (r *Repository) GetTagByName(userID int, name string) (*models.Tag, error) {
+	query := `SELECT id, user_id, name, created_at FROM tags WHERE user_id = $1 AND name = $2`
+	var tag models.Tag
+	err := r.db.Get(&tag, query, userID, name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Printf("Error fetching tag: %v", err)
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// AddContactsToTag attaches a tag to contacts, contacts already tagged are skipped, returns the number tagged
+func (r *Repository) AddContactsToTag(tagID int, contactIDs []int) (int, error) {
+	query := `INSERT INTO contact_tags (contact_id, tag_id)
+			  SELECT UNNEST($1::int[]), $2 ON CONFLICT DO NOTHING`
+	result, err := r.db.Exec(query, pq.Array(contactIDs), tagID)
+	if err != nil {
+		log.Printf("Error tagging contacts: %v", err)
+		return 0, err
+	}
+	rows, err := result.RowsAffected()
+	return int(rows), err
+}
+
+// RemoveContactsFromTag detaches a tag from contacts, returns the number untagged
+func (r *Repository) RemoveContactsFromTag(tagID int, contactIDs []int) (int, error) {
+	query := `DELETE FROM contact_tags WHERE tag_id = $1 AND contact_id = ANY($2)`
+	result, err := r.db.Exec(query, tagID, pq.Array(contactIDs))
+	if err != nil {
+		log.Printf("Error untagging contacts: %v", err)
+		return 0, err
+	}
+	rows, err := result.RowsAffected()
+	return int(rows), err
+}
+
+// GetTagsByContacts retrieves the tags of several contacts at once
+func (r *Repository) GetTagsByContacts(contactIDs []int) ([]models.ContactMembership, error) {
+	if len(contactIDs) == 0 {
+		return nil, nil
+	}
+
+	query := `SELECT ct.contact_id, t.id, t.name
+			  FROM contact_tags ct JOIN tags t ON t.id = ct.tag_id
+			  WHERE ct.contact_id = ANY($1) ORDER BY ct.contact_id, t.name`
+	var memberships []models.ContactMembership
+	err := r.db.Select(&memberships, query, pq.Array(contactIDs))
+	if err != nil {
+		log.Printf("Error fetching contact tags: %v", err)
+		return nil, err
+	}
+	return memberships, nil
+}
+
+// GetTagsByUser retrieves the tags of a user ordered by name with the number of contacts tagged
+func (r *Repository) GetTagsByUser(userID int) ([]models.Tag, error) {
+	query := `SELECT t.id, t.user_id, t.name, t.created_at, COUNT(ct.contact_id) AS contact_count
+			  FROM tags t LEFT JOIN contact_tags ct ON ct.tag_id = t.id
+			  AND EXISTS (SELECT 1 FROM contacts c WHERE c.id = ct.contact_id AND c.deleted_at IS NULL)
+			  WHERE t.user_id = $1 GROUP BY t.id ORDER BY t.name`
+	var tags []models.Tag
+	err := r.db.Select(&tags, query, userID)
+	if err != nil {
+		log.Printf("Error fetching tags: %v", err)
+		return nil, err
+	}
+	return tags, nil
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"log"
+	"time"
+
+	"github.com/danizion/contact-app/internal/models"
+)
+
+// APIUserUsage is the usage of a user summed over a period
+type APIUserUsage struct {
+	UserID      int    `db:"user_id"`
+	Username    string `db:"username"`
+	Requests    int64  `db:"requests"`
+	RateLimited int64  `db:"rate_limited"`
+	BytesIn     int64  `db:"bytes_in"`
+	BytesOut    int64  `db:"bytes_out"`
+}
+
+const apiUsageSums = `SUM(requests)::BIGINT AS requests, SUM(rate_limited)::BIGINT AS rate_limited,
+					  SUM(bytes_in)::BIGINT AS bytes_in, SUM(bytes_out)::BIGINT AS bytes_out`
+
+// UpsertAPIUsage stores the usage counted for a day. The counters of a day only grow, so a replica rolling up
+// counters read before another one never moves them back
+func (r *Repository) UpsertAPIUsage(usage []models.APIUsage) error {
+	if len(usage) == 0 {
+		return nil
+	}
+
+	query := `INSERT INTO api_usage (user_id, day, route, requests, rate_limited, bytes_in, bytes_out)
+			  VALUES (:user_id, :day, :route, :requests, :rate_limited, :bytes_in, :bytes_out)
+			  ON CONFLICT (user_id, day, route) DO UPDATE SET
+				requests = GREATEST(api_usage.requests, EXCLUDED.requests),
+				rate_limited = GREATEST(api_usage.rate_limited, EXCLUDED.rate_limited),
+				bytes_in = GREATEST(api_usage.bytes_in, EXCLUDED.bytes_in),
+				bytes_out = GREATEST(api_usage.bytes_out, EXCLUDED.bytes_out)`
+	_, err := r.db.NamedExec(query, usage)
+	if err != nil {
+		log.Printf("Error storing API usage: %v", err)
+		return err
+	}
+	return nil
+}
+
+// GetUserAPIUsage returns the usage of a user from a day on, by day and route
+func (r *Repository) GetUserAPIUsage(userID int, since time.Time) ([]models.APIUsage, error) {
+	query := `SELECT user_id, day, route, requests, rate_limited, bytes_in, bytes_out FROM api_usage
+			  WHERE user_id = $1 AND day >= $2::date ORDER BY day, route`
+	var usage []models.APIUsage
+	err := r.db.Select(&usage, query, userID, since)
+	if err != nil {
+		log.Printf("Error fetching API usage: %v", err)
+		return nil, err
+	}
+	return usage, nil
+}
+
+// GetAPIUsageByDay sums the usage of every user from a day on, by day
+func (r *Repository) GetAPIUsageByDay(since time.Time) ([]models.APIUsage, error) {
+	query := `SELECT day, ` + apiUsageSums + ` FROM api_usage WHERE day >= $1::date GROUP BY day ORDER BY day`
+	var usage []models.APIUsage
+	err := r.db.Select(&usage, query, since)
+	if err != nil {
+		log.Printf("Error summing API usage by day: %v", err)
+		return nil, err
+	}
+	return usage, nil
+}
+
+// GetAPIUsageByRoute sums the usage of every user from a day on, by route, most requested first
+func (r *Repository) GetAPIUsageByRoute(since time.Time) ([]models.APIUsage, error) {
+	query := `SELECT route, ` + apiUsageSums + ` FROM api_usage WHERE day >= $1::date
+			  GROUP BY route ORDER BY requests DESC, route`
+	var usage []models.APIUsage
+	err := r.db.Select(&usage, query, since)
+	if err != nil {
+		log.Printf("Error summing API usage by route: %v", err)
+		return nil, err
+	}
+	return usage, nil
+}
+
+// GetTopAPIUsers returns the limit users sending the most requests from a day on
+func (r *Repository) GetTopAPIUsers(since time.Time, limit int) ([]APIUserUsage, error) {
+	query := `SELECT u.id AS user_id, u.username, ` + apiUsageSums + `
+			  FROM api_usage a JOIN users u ON u.id = a.user_id
+			  WHERE a.day >= $1::date
+			  GROUP BY u.id, u.username ORDER BY requests DESC, u.id LIMIT $2`
+	var usage []APIUserUsage
+	err := r.db.Select(&usage, query, since, limit)
+	if err != nil {
+		log.Printf("Error fetching top API users: %v", err)
+		return nil, err
+	}
+	return usage, nil
+}
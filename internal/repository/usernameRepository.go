@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/danizion/contact-app/internal/models"
+)
+
+// ChangeUsername renames a user and records the former username in its history, in one transaction
+func (r *Repository) ChangeUsername(userID int, oldUsername, newUsername string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		log.Printf("Error starting username change transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	// Matching the old username makes concurrent renames of the same user fail instead of losing a history entry
+	result, err := tx.Exec(`UPDATE users SET username = $1, updated_at = NOW() WHERE id = $2 AND username = $3`,
+		newUsername, userID, oldUsername)
+	if err != nil {
+		log.Printf("Error updating username: %v", err)
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("user %w", ErrNotFound)
+	}
+	_, err = tx.Exec(`INSERT INTO username_history (user_id, old_username, new_username) VALUES ($1, $2, $3)`,
+		userID, oldUsername, newUsername)
+	if err != nil {
+		log.Printf("Error recording username change: %v", err)
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing username change: %v", err)
+		return err
+	}
+	return nil
+}
+
+// GetUsernameHistory retrieves the username changes of a user, newest first
+func (r *Repository) GetUsernameHistory(userID int) ([]models.UsernameChange, error) {
+	query := `SELECT id, user_id, old_username, new_username, changed_at FROM username_history
+			  WHERE user_id = $1 ORDER BY changed_at DESC, id DESC`
+	var changes []models.UsernameChange
+	if err := r.db.Select(&changes, query, userID); err != nil {
+		log.Printf("Error fetching username history: %v", err)
+		return nil, err
+	}
+	return changes, nil
+}
+
+// GetFormerUsernameOwner retrieves the user that last gave up a username since a point in time, nil when none did
+func (r *Repository) GetFormerUsernameOwner(username string, since time.Time) (*models.User, error) {
+	query := `SELECT u.id, u.username, u.email, u.hashed_password, u.is_admin, u.created_at, u.updated_at
+			  FROM username_history h JOIN users u ON u.id = h.user_id
+			  WHERE h.old_username = $1 AND h.changed_at >= $2 ORDER BY h.changed_at DESC, h.id DESC LIMIT 1`
+	var user models.User
+	err := r.db.Get(&user, query, username, since)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Printf("Error fetching former username owner: %v", err)
+		return nil, err
+	}
+	return &user, nil
+}
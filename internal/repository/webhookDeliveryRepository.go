@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"log"
+	"time"
+
+	"github.com/danizion/contact-app/internal/models"
+)
+
+const webhookDeliveryColumns = `id, webhook_id, event_id, event_type, payload, status, attempts, status_code, last_error,
+	next_attempt_at, created_at, delivered_at`
+
+// DueWebhookDelivery is a delivery claimed for an attempt with the webhook it goes to
+type DueWebhookDelivery struct {
+	models.WebhookDelivery
+	URL    string `db:"url"`
+	Secret string `db:"secret"`
+}
+
+// CreateWebhookDelivery inserts a pending delivery claimed until claimedUntil by the caller, which attempts it at once
+func (r *Repository) CreateWebhookDelivery(delivery models.WebhookDelivery, claimedUntil time.Time) (int64, error) {
+	query := `INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload, status, next_attempt_at)
+			  VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+	var id int64
+	err := r.db.QueryRow(query, delivery.WebhookID, delivery.EventID, delivery.EventType, delivery.Payload,
+		delivery.Status, claimedUntil).Scan(&id)
+	if err != nil {
+		log.Printf("Error creating webhook delivery: %v", err)
+		return 0, err
+	}
+	return id, nil
+}
+
+// ClaimDueWebhookDeliveries claims up to limit pending deliveries whose next attempt is due until claimedUntil,
+// deliveries claimed by another replica are skipped so each attempt is made once. Deliveries of deactivated accounts
+// stay pending until the account is reactivated
+func (r *Repository) ClaimDueWebhookDeliveries(limit int, claimedUntil time.Time) ([]DueWebhookDelivery, error) {
+	query := `WITH claimed AS (
+				UPDATE webhook_deliveries SET next_attempt_at = $2
+				WHERE id IN (SELECT d.id FROM webhook_deliveries d
+							 JOIN webhooks w ON w.id = d.webhook_id JOIN users u ON u.id = w.user_id
+							 WHERE d.status = 'pending' AND d.next_attempt_at <= NOW() AND u.state = 'active'
+							 ORDER BY d.next_attempt_at, d.id LIMIT $1 FOR UPDATE OF d SKIP LOCKED)
+				RETURNING ` + webhookDeliveryColumns + `)
+			  SELECT claimed.*, w.url, w.secret FROM claimed JOIN webhooks w ON w.id = claimed.webhook_id
+			  ORDER BY claimed.id`
+	var deliveries []DueWebhookDelivery
+	if err := r.db.Select(&deliveries, query, limit, claimedUntil); err != nil {
+		log.Printf("Error claiming webhook deliveries: %v", err)
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// RecordWebhookAttempt stores the outcome of an attempt, the status, response and schedule of the delivery
+func (r *Repository) RecordWebhookAttempt(delivery models.WebhookDelivery) error {
+	query := `UPDATE webhook_deliveries SET status = $2, attempts = attempts + 1, status_code = $3, last_error = $4,
+			  next_attempt_at = $5, delivered_at = $6
+			  WHERE id = $1`
+	_, err := r.db.Exec(query, delivery.ID, delivery.Status, delivery.StatusCode, delivery.LastError,
+		delivery.NextAttemptAt, delivery.DeliveredAt)
+	if err != nil {
+		log.Printf("Error recording webhook attempt: %v", err)
+		return err
+	}
+	return nil
+}
+
+// GetWebhookDeliveries retrieves the latest deliveries of a webhook, newest first
+func (r *Repository) GetWebhookDeliveries(webhookID, limit int) ([]models.WebhookDelivery, error) {
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE webhook_id = $1 ORDER BY id DESC LIMIT $2`
+	var deliveries []models.WebhookDelivery
+	if err := r.db.Select(&deliveries, query, webhookID, limit); err != nil {
+		log.Printf("Error fetching webhook deliveries: %v", err)
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// DeleteWebhookDeliveriesBefore removes the deliveries created before a point in time that are no longer pending
+func (r *Repository) DeleteWebhookDeliveriesBefore(before time.Time) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM webhook_deliveries WHERE created_at < $1 AND status <> 'pending'`, before)
+	if err != nil {
+		log.Printf("Error deleting webhook deliveries: %v", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
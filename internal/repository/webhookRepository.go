@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/danizion/contact-app/internal/models"
+)
+
+// CreateWebhook inserts a new webhook into the "webhooks" table
+func (r *Repository) CreateWebhook(webhook models.Webhook) (int, error) {
+	query := `INSERT INTO webhooks (user_id, url, secret) VALUES ($1, $2, $3) RETURNING id`
+	var webhookID int
+	err := r.db.QueryRow(query, webhook.UserID, webhook.URL, webhook.Secret).Scan(&webhookID)
+	if err != nil {
+		log.Printf("Error creating webhook: %v", err)
+		return 0, err
+	}
+	return webhookID, nil
+}
+
+// GetWebhooksByUser retrieves the webhooks of a user in creation order
+func (r *Repository) GetWebhooksByUser(userID int) ([]models.Webhook, error) {
+	query := `SELECT id, user_id, url, secret, created_at FROM webhooks WHERE user_id = $1 ORDER BY id`
+	var webhooks []models.Webhook
+	err := r.db.Select(&webhooks, query, userID)
+	if err != nil {
+		log.Printf("Error fetching webhooks: %v", err)
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// CountWebhooksByUser counts the webhooks of a user
+func (r *Repository) CountWebhooksByUser(userID int) (int, error) {
+	var count int
+	err := r.db.Get(&count, `SELECT COUNT(*) FROM webhooks WHERE user_id = $1`, userID)
+	if err != nil {
+		log.Printf("Error counting webhooks: %v", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetWebhook retrieves a webhook of a user, returns nil if it does not exist
+func (r *Repository) GetWebhook(userID, webhookID int) (*models.Webhook, error) {
+	query := `SELECT id, user_id, url, secret, created_at FROM webhooks WHERE id = $1 AND user_id = $2`
+	var webhook models.Webhook
+	err := r.db.Get(&webhook, query, webhookID, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Printf("Error fetching webhook: %v", err)
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// DeleteWebhook removes a webhook
+func (r *Repository) DeleteWebhook(userID, webhookID int) error {
+	result, err := r.db.Exec(`DELETE FROM webhooks WHERE id = $1 AND user_id = $2`, webhookID, userID)
+	if err != nil {
+		log.Printf("Error deleting webhook: %v", err)
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("webhook %w or does not belong to the specified user", ErrNotFound)
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+// Package server configures the listeners the API is served on: TCP addresses or Unix domain sockets, each serving
+// a subset of the routes behind its own middlewares
+package server
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/utils"
+)
+
+// Access levels a listener may serve, the api.Access* values
+var accessLevels = []string{"public", "user", "admin"}
+
+// Listener is where a part of the API is served
+type Listener struct {
+	Name string
+	// Addr is a TCP address (":8080") or a Unix domain socket ("unix:/run/contact-app/api.sock")
+	Addr string
+	// Access lists the access levels of the routes served
+	Access []string
+	// RateLimitPerMinute caps the requests per user or client IP, 0 disables rate limiting
+	RateLimitPerMinute int
+	// RouteRateLimits overrides the per route limits by route name, 0 removes the limit of a route
+	RouteRateLimits map[string]int
+	// Allow restricts the clients to these networks, every client is accepted when empty
+	Allow []*net.IPNet
+	// TrustedProxies are the proxies whose X-Forwarded-For and X-Real-IP headers are believed
+	TrustedProxies []string
+}
+
+// Load reads the listeners from the environment. LISTENERS names them (comma separated) and each listener NAME is
+// configured by LISTEN_NAME_ADDR (required), LISTEN_NAME_ROUTES (access levels, all by default),
+// LISTEN_NAME_RATE_LIMIT (default RATE_LIMIT_PER_MINUTE), LISTEN_NAME_ALLOW (CIDRs) and LISTEN_NAME_TRUSTED_PROXIES
+// (default TRUSTED_PROXIES). Without LISTENERS every route is served on PORT. RATE_LIMIT_ROUTES overrides the per
+// route limits of every listener ("Login=20,CreateUser=0")
+func Load() ([]Listener, error) {
+	rateLimit := utils.GetEnvIntOrDefault("RATE_LIMIT_PER_MINUTE", constants.DefaultRateLimitPerMinute)
+	trustedProxies := utils.GetEnvList("TRUSTED_PROXIES")
+	routeRateLimits, err := parseRouteRateLimits(utils.GetEnvList("RATE_LIMIT_ROUTES"))
+	if err != nil {
+		return nil, err
+	}
+
+	names := utils.GetEnvList("LISTENERS")
+	if len(names) == 0 {
+		return []Listener{{
+			Name:               "default",
+			Addr:               ":" + utils.GetEnvOrDefault("PORT", "8080"),
+			Access:             accessLevels,
+			RateLimitPerMinute: rateLimit,
+			RouteRateLimits:    routeRateLimits,
+			TrustedProxies:     trustedProxies,
+		}}, nil
+	}
+
+	listeners := make([]Listener, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		prefix := "LISTEN_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		if seen[prefix] {
+			return nil, fmt.Errorf("listener %s is declared twice", name)
+		}
+		seen[prefix] = true
+
+		listener := Listener{
+			Name:               name,
+			Addr:               utils.GetEnvOrDefault(prefix+"ADDR", ""),
+			Access:             utils.GetEnvList(prefix + "ROUTES"),
+			RateLimitPerMinute: utils.GetEnvIntOrDefault(prefix+"RATE_LIMIT", rateLimit),
+			RouteRateLimits:    routeRateLimits,
+			TrustedProxies:     trustedProxies,
+		}
+		if listener.Addr == "" {
+			return nil, fmt.Errorf("listener %s has no %sADDR", name, prefix)
+		}
+		if len(listener.Access) == 0 {
+			listener.Access = accessLevels
+		}
+		for _, access := range listener.Access {
+			if !isAccessLevel(access) {
+				return nil, fmt.Errorf("listener %s: unknown route access level %q, expected one of %s", name, access,
+					strings.Join(accessLevels, ", "))
+			}
+		}
+		for _, cidr := range utils.GetEnvList(prefix + "ALLOW") {
+			_, network, err := net.ParseCIDR(cidr)
+			if err != nil {
+				return nil, fmt.Errorf("listener %s: invalid %sALLOW: %w", name, prefix, err)
+			}
+			listener.Allow = append(listener.Allow, network)
+		}
+		if proxies := utils.GetEnvList(prefix + "TRUSTED_PROXIES"); len(proxies) > 0 {
+			listener.TrustedProxies = proxies
+		}
+		listeners = append(listeners, listener)
+	}
+	return listeners, nil
+}
+
+// parseRouteRateLimits reads route limits written as Name=limit
+func parseRouteRateLimits(entries []string) (map[string]int, error) {
+	limits := make(map[string]int, len(entries))
+	for _, entry := range entries {
+		name, value, found := strings.Cut(entry, "=")
+		limit, err := strconv.Atoi(strings.TrimSpace(value))
+		if !found || strings.TrimSpace(name) == "" || err != nil || limit < 0 {
+			return nil, fmt.Errorf("invalid RATE_LIMIT_ROUTES entry %q, expected RouteName=requests per minute", entry)
+		}
+		limits[strings.TrimSpace(name)] = limit
+	}
+	return limits, nil
+}
+
+func isAccessLevel(access string) bool {
+	for _, level := range accessLevels {
+		if access == level {
+			return true
+		}
+	}
+	return false
+}
+
+// Serves reports whether the listener serves the routes of this access level
+func (l Listener) Serves(access string) bool {
+	for _, served := range l.Access {
+		if served == access {
+			return true
+		}
+	}
+	return false
+}
+
+// socketPath returns the path of a Unix domain socket listener, "" for TCP listeners
+func (l Listener) socketPath() string {
+	path, found := strings.CutPrefix(l.Addr, "unix:")
+	if !found {
+		return ""
+	}
+	return path
+}
+
+// listen opens the listener. A socket left over by a previous run is replaced and the new socket is only reachable
+// by the user and group of the process
+func (l Listener) listen() (net.Listener, error) {
+	path := l.socketPath()
+	if path == "" {
+		return net.Listen("tcp", l.Addr)
+	}
+
+	if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, constants.UnixSocketMode); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+// Serve serves every listener with its handler until one of them fails, it returns that error
+func Serve(listeners []Listener, handlers []http.Handler) error {
+	if len(listeners) != len(handlers) {
+		return errors.New("every listener needs a handler")
+	}
+
+	errs := make(chan error, len(listeners))
+	for i, listener := range listeners {
+		ln, err := listener.listen()
+		if err != nil {
+			return fmt.Errorf("listener %s: %w", listener.Name, err)
+		}
+		handler := handlers[i]
+		if listener.socketPath() != "" {
+			handler = localPeer(handler)
+		}
+		slog.Info("Listening", "listener", listener.Name, "addr", listener.Addr, "routes", strings.Join(listener.Access, ","))
+		go func(name string) {
+			errs <- fmt.Errorf("listener %s: %w", name, http.Serve(ln, handler))
+		}(listener.Name)
+	}
+	return <-errs
+}
+
+// localPeer marks the requests of a Unix domain socket as coming from the loopback address. Their peer has no IP,
+// which would leave the client IP empty and the forwarded headers of a proxy on the socket ignored
+func localPeer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, _, err := net.SplitHostPort(r.RemoteAddr); err != nil {
+			r.RemoteAddr = "127.0.0.1:0"
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/events"
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/danizion/contact-app/internal/repository"
+	"github.com/danizion/contact-app/internal/storage/redis"
+)
+
+// AccountMergeService lets admins find duplicate accounts and merge them into one
+type AccountMergeService struct {
+	repo  *repository.Repository
+	redis *redis.Redis
+}
+
+// NewAccountMergeService creates a new instance of AccountMergeService
+func NewAccountMergeService(db *sql.DB, redisClient *redis.Redis) *AccountMergeService {
+	return &AccountMergeService{
+		repo:  repository.NewRepository(db),
+		redis: redisClient,
+	}
+}
+
+// WithContext returns the service running its queries and cache calls under ctx, the context of a request so they stop with it
+func (s *AccountMergeService) WithContext(ctx context.Context) *AccountMergeService {
+	bound := *s
+	bound.repo = s.repo.WithContext(ctx)
+	bound.redis = s.redis.WithContext(ctx)
+	return &bound
+}
+
+// ListDuplicateUsers returns the accounts sharing an email once lower cased and trimmed
+func (s *AccountMergeService) ListDuplicateUsers() (*dtos.DuplicateUsersResponseDto, error) {
+	duplicates, err := s.repo.GetDuplicateUsers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get duplicate users: %w", err)
+	}
+
+	result := &dtos.DuplicateUsersResponseDto{Duplicates: make([]dtos.DuplicateUsersDto, len(duplicates))}
+	for i, duplicate := range duplicates {
+		users, err := s.repo.GetUsersByIDs(duplicate.UserIDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get users: %w", err)
+		}
+		result.Duplicates[i] = dtos.DuplicateUsersDto{Email: duplicate.Email, Users: make([]dtos.UserSummaryDto, len(users))}
+		for j, user := range users {
+			result.Duplicates[i].Users[j] = toUserSummaryDto(user)
+		}
+	}
+	return result, nil
+}
+
+// MergeUsers moves the contacts, groups, tags, attachments, snapshots, webhooks, API keys, preferences and audit
+// log of the source account to the target account and deletes the source. The target keeps its credentials: the
+// password of the source is dropped, its API keys now act as the target and its sessions are revoked
+func (s *AccountMergeService) MergeUsers(req dtos.MergeUsersRequestDto) (*dtos.MergeUsersResponseDto, error) {
+	if req.SourceUserID == req.TargetUserID {
+		return nil, newError(ErrInvalidInput, constants.ErrMergeSameUser)
+	}
+	users, err := s.repo.GetUsersByIDs([]int64{int64(req.SourceUserID), int64(req.TargetUserID)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+	if len(users) != 2 {
+		return nil, newError(ErrNotFound, constants.ErrUserNotFound)
+	}
+	source := users[0]
+	if source.ID != req.SourceUserID {
+		source = users[1]
+	}
+
+	merged, err := s.repo.MergeUsers(req.SourceUserID, req.TargetUserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge users: %w", err)
+	}
+
+	// The source is gone but its access tokens would keep authenticating until they expire
+	if s.redis != nil {
+		if _, err := s.redis.RevokeSessions(source.ID); err != nil {
+			slog.Error("Failed to revoke the sessions of a merged user", "error", err, "userID", source.ID)
+		}
+	}
+
+	details := map[string]interface{}{
+		"source_user_id":   source.ID,
+		"source_user_name": source.Username,
+		"source_email":     source.Email,
+		"contacts":         merged.Contacts,
+	}
+	recordAuditBy(s.repo, req.AdminID, req.TargetUserID, constants.AuditActionUserMerged, constants.AuditEntityUser, req.TargetUserID, details)
+	events.Publish(events.Event{Type: events.AccountMerged, UserID: req.TargetUserID, Details: details})
+
+	return &dtos.MergeUsersResponseDto{
+		SourceUserID: req.SourceUserID,
+		TargetUserID: req.TargetUserID,
+		Contacts:     merged.Contacts,
+		Groups:       merged.Groups,
+		Tags:         merged.Tags,
+		Attachments:  merged.Attachments,
+		Snapshots:    merged.Snapshots,
+		Webhooks:     merged.Webhooks,
+		APIKeys:      merged.APIKeys,
+	}, nil
+}
+
+func toUserSummaryDto(user models.User) dtos.UserSummaryDto {
+	return dtos.UserSummaryDto{
+		ID:        user.ID,
+		UserName:  user.Username,
+		Email:     user.Email,
+		IsAdmin:   user.IsAdmin,
+		CreatedAt: user.CreatedAt,
+	}
+}
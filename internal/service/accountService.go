@@ -0,0 +1,393 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danizion/contact-app/internal/auth"
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/mail"
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/danizion/contact-app/internal/repository"
+	"github.com/danizion/contact-app/internal/signup"
+	"github.com/danizion/contact-app/internal/storage/blob"
+	"github.com/danizion/contact-app/internal/storage/redis"
+	"github.com/danizion/contact-app/internal/utils"
+)
+
+// emailChangeData is the data rendered by the email change templates
+type emailChangeData struct {
+	Username  string
+	NewEmail  string
+	Link      string
+	ExpiresAt time.Time
+}
+
+// AccountService handles the account of the current user: its profile and the change of its email, which takes
+// effect once confirmed from both the current and the new address
+type AccountService struct {
+	repo      *repository.Repository
+	redis     *redis.Redis
+	blobStore blob.Store
+	sender    mail.Sender
+	// publicURL starts the confirmation links sent by email
+	publicURL string
+	// signup restricts the email domains of the accounts, new emails included
+	signup signup.Policy
+}
+
+// NewAccountService creates a new instance of AccountService, email changes are refused without a sender
+func NewAccountService(db *sql.DB, redisClient *redis.Redis, blobStore blob.Store, sender mail.Sender) *AccountService {
+	return &AccountService{
+		repo:      repository.NewRepository(db),
+		redis:     redisClient,
+		blobStore: blobStore,
+		sender:    sender,
+		publicURL: strings.TrimSuffix(utils.GetEnvOrDefault("PUBLIC_URL", constants.DefaultPublicURL), "/"),
+		signup:    signup.Load(),
+	}
+}
+
+// WithContext returns the service running its queries and cache calls under ctx, the context of a request so they stop with it
+func (s *AccountService) WithContext(ctx context.Context) *AccountService {
+	bound := *s
+	bound.repo = s.repo.WithContext(ctx)
+	bound.redis = s.redis.WithContext(ctx)
+	return &bound
+}
+
+// GetProfile returns the account of a user with its pending email change
+func (s *AccountService) GetProfile(userID int) (*dtos.ProfileResponseDto, error) {
+	user, err := s.repo.GetUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	change, err := s.repo.GetEmailChange(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get email change: %w", err)
+	}
+
+	profile := &dtos.ProfileResponseDto{
+		ID:        user.ID,
+		UserName:  user.Username,
+		Email:     user.Email,
+		IsAdmin:   user.IsAdmin,
+		CreatedAt: user.CreatedAt,
+	}
+	if change != nil {
+		profile.PendingEmailChange = toEmailChangeDto(*change)
+	}
+
+	history, err := s.repo.GetUsernameHistory(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get username history: %w", err)
+	}
+	for _, renamed := range history {
+		profile.UsernameHistory = append(profile.UsernameHistory, dtos.UsernameChangeDto{
+			OldUserName: renamed.OldUsername,
+			NewUserName: renamed.NewUsername,
+			ChangedAt:   renamed.ChangedAt,
+		})
+	}
+	if len(history) > 0 {
+		if availableAt := history[0].ChangedAt.Add(constants.UsernameChangeCooldown); availableAt.After(time.Now()) {
+			profile.UsernameChangeAvailableAt = &availableAt
+		}
+	}
+	return profile, nil
+}
+
+// ChangeUsername renames a user, once per UsernameChangeCooldown. The former username stays reserved to the user for
+// UsernameReservation: nobody else can take it and lookups of it lead to the user
+func (s *AccountService) ChangeUsername(req dtos.ChangeUsernameRequestDto) (*dtos.ProfileResponseDto, error) {
+	user, err := s.repo.GetUser(req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if err := s.checkUsernameChange(user, req.UserName); err != nil {
+		return nil, err
+	}
+	if err := s.renameUser(user, req.UserName, req.ClientIP); err != nil {
+		return nil, err
+	}
+	return s.GetProfile(user.ID)
+}
+
+// checkUsernameChange returns why a user cannot take a username, nil when it can
+func (s *AccountService) checkUsernameChange(user *models.User, newUsername string) error {
+	if newUsername == user.Username {
+		return newError(ErrInvalidInput, constants.ErrUsernameUnchanged)
+	}
+
+	history, err := s.repo.GetUsernameHistory(user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get username history: %w", err)
+	}
+	if len(history) > 0 && time.Since(history[0].ChangedAt) < constants.UsernameChangeCooldown {
+		return newError(ErrConflict, constants.ErrUsernameChangeTooSoon)
+	}
+
+	taken, err := usernameTaken(s.repo, newUsername, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to check username: %w", err)
+	}
+	if taken {
+		return newError(ErrConflict, constants.ErrUsernameExists)
+	}
+	return nil
+}
+
+func (s *AccountService) renameUser(user *models.User, newUsername, clientIP string) error {
+	if err := s.repo.ChangeUsername(user.ID, user.Username, newUsername); err != nil {
+		return fmt.Errorf("failed to change username: %w", err)
+	}
+	details := clientIPDetails(clientIP)
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+	details["old_user_name"], details["new_user_name"] = user.Username, newUsername
+	recordAudit(s.repo, user.ID, constants.AuditActionUsernameChanged, constants.AuditEntityUser, user.ID, details)
+	return nil
+}
+
+// UpdateProfile changes the username and the email of a user, with the rules of ChangeUsername and
+// RequestEmailChange: the email only changes once confirmed from both addresses. The username is checked before the
+// email change starts and changed after, so a refused request changes nothing
+func (s *AccountService) UpdateProfile(req dtos.UpdateProfileRequestDto) (*dtos.ProfileResponseDto, error) {
+	user, err := s.repo.GetUser(req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	rename := req.UserName != "" && req.UserName != user.Username
+	changeEmail := req.Email != "" && !strings.EqualFold(strings.TrimSpace(req.Email), user.Email)
+	if !rename && !changeEmail {
+		return nil, newError(ErrInvalidInput, constants.ErrProfileUnchanged)
+	}
+
+	if rename {
+		if err := s.checkUsernameChange(user, req.UserName); err != nil {
+			return nil, err
+		}
+	}
+	if changeEmail {
+		_, err := s.RequestEmailChange(dtos.RequestEmailChangeDto{
+			UserID:   user.ID,
+			NewEmail: req.Email,
+			Password: req.Password,
+			ClientIP: req.ClientIP,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if rename {
+		if err := s.renameUser(user, req.UserName, req.ClientIP); err != nil {
+			return nil, err
+		}
+	}
+	return s.GetProfile(user.ID)
+}
+
+// DeleteAccount deletes a user once the password is verified, with its contacts and everything else it owns. The
+// attachment files, cached contact pages and sessions of the user are dropped too
+func (s *AccountService) DeleteAccount(req dtos.DeleteAccountRequestDto) error {
+	user, err := s.repo.GetUser(req.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if !auth.CheckPassword(req.Password, user.HashedPassword) {
+		return newError(ErrForbidden, constants.ErrInvalidPassword)
+	}
+
+	// The keys are read first, the attachments rows go with the user
+	storageKeys, err := s.repo.GetAttachmentKeysByUser(user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get attachments: %w", err)
+	}
+	deleted, err := s.repo.DeleteUser(user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	if !deleted {
+		return newError(ErrNotFound, constants.ErrUserNotFound)
+	}
+
+	// The account is gone, what is left below only wastes space or would keep working until it expires
+	for _, key := range storageKeys {
+		if err := s.blobStore.Delete(key); err != nil {
+			log.Printf("Error deleting blob %s: %v", key, err)
+		}
+	}
+	if s.redis != nil {
+		if _, err := s.redis.RevokeSessions(user.ID); err != nil {
+			slog.Error("Failed to revoke the sessions of a deleted user", "error", err, "userID", user.ID)
+		}
+		if err := s.redis.InvalidateUserCache(strconv.Itoa(user.ID)); err != nil {
+			slog.Error("Failed to clear the contacts cache of a deleted user", "error", err, "userID", user.ID)
+		}
+	}
+	slog.Info("Account deleted", "userID", user.ID, "ip", req.ClientIP)
+	return nil
+}
+
+// RequestEmailChange starts changing the email of a user once the current password is verified. A confirmation link
+// is sent to the current and to the new address, a new request replaces the pending change and its links
+func (s *AccountService) RequestEmailChange(req dtos.RequestEmailChangeDto) (*dtos.EmailChangeDto, error) {
+	if s.sender == nil {
+		return nil, newError(ErrUnavailable, constants.ErrEmailNotConfigured)
+	}
+	user, err := s.repo.GetUser(req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if !auth.CheckPassword(req.Password, user.HashedPassword) {
+		return nil, newError(ErrForbidden, constants.ErrInvalidPassword)
+	}
+
+	newEmail := strings.TrimSpace(req.NewEmail)
+	if strings.EqualFold(newEmail, user.Email) {
+		return nil, newError(ErrInvalidInput, constants.ErrEmailUnchanged)
+	}
+	if err := s.signup.Check(newEmail); err != nil {
+		return nil, newError(ErrForbidden, "%w", err)
+	}
+	existingUser, err := s.repo.GetUserByEmail(newEmail)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check email: %w", err)
+	}
+	if existingUser != nil {
+		return nil, newError(ErrConflict, constants.ErrEmailExists)
+	}
+
+	currentToken, err := randomToken("", 32)
+	if err != nil {
+		return nil, err
+	}
+	newToken, err := randomToken("", 32)
+	if err != nil {
+		return nil, err
+	}
+	change := models.EmailChange{
+		UserID:       user.ID,
+		NewEmail:     newEmail,
+		OldTokenHash: hashEmailChangeToken(currentToken),
+		NewTokenHash: hashEmailChangeToken(newToken),
+		CreatedAt:    time.Now(),
+		ExpiresAt:    time.Now().Add(constants.EmailChangeTTL).Truncate(time.Second),
+	}
+	if err := s.repo.SaveEmailChange(change); err != nil {
+		return nil, fmt.Errorf("failed to save email change: %w", err)
+	}
+
+	if err := s.sendConfirmation("email_change_current", user.Email, user, change, currentToken); err == nil {
+		err = s.sendConfirmation("email_change_new", newEmail, user, change, newToken)
+	}
+	if err != nil {
+		// Without both links the change could never complete, drop it so the user can retry
+		if _, deleteErr := s.repo.DeleteEmailChange(user.ID); deleteErr != nil {
+			log.Printf("Error dropping email change of user %d: %v", user.ID, deleteErr)
+		}
+		return nil, fmt.Errorf("failed to send confirmation email: %w", err)
+	}
+
+	details := clientIPDetails(req.ClientIP)
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+	details["new_email"] = newEmail
+	recordAudit(s.repo, user.ID, constants.AuditActionEmailChangeStart, constants.AuditEntityUser, user.ID, details)
+	return toEmailChangeDto(change), nil
+}
+
+func (s *AccountService) sendConfirmation(template, to string, user *models.User, change models.EmailChange, token string) error {
+	msg, err := mail.Render(template, to, emailChangeData{
+		Username:  user.Username,
+		NewEmail:  change.NewEmail,
+		Link:      s.publicURL + "/users/email/confirm?token=" + url.QueryEscape(token),
+		ExpiresAt: change.ExpiresAt,
+	})
+	if err != nil {
+		return err
+	}
+	return s.sender.Send(msg)
+}
+
+// ConfirmEmailChange records that the address a confirmation link was sent to was confirmed. The email of the user
+// changes with the second confirmation, unless another account took the new email meanwhile
+func (s *AccountService) ConfirmEmailChange(token string) (*dtos.ConfirmEmailChangeResponseDto, error) {
+	if token == "" {
+		return nil, newError(ErrNotFound, constants.ErrInvalidEmailChangeToken)
+	}
+	change, err := s.repo.ConfirmEmailChange(hashEmailChangeToken(token))
+	if err != nil {
+		return nil, fmt.Errorf("failed to confirm email change: %w", err)
+	}
+	if change == nil {
+		return nil, newError(ErrNotFound, constants.ErrInvalidEmailChangeToken)
+	}
+	if change.OldConfirmedAt == nil || change.NewConfirmedAt == nil {
+		return &dtos.ConfirmEmailChangeResponseDto{Pending: toEmailChangeDto(*change)}, nil
+	}
+
+	existingUser, err := s.repo.GetUserByEmail(change.NewEmail)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check email: %w", err)
+	}
+	if existingUser != nil && existingUser.ID != change.UserID {
+		if _, err := s.repo.DeleteEmailChange(change.UserID); err != nil {
+			return nil, fmt.Errorf("failed to drop email change: %w", err)
+		}
+		return nil, newError(ErrConflict, constants.ErrEmailExists)
+	}
+
+	user, err := s.repo.GetUser(change.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if err := s.repo.CompleteEmailChange(change.UserID, change.NewEmail); err != nil {
+		return nil, fmt.Errorf("failed to change email: %w", err)
+	}
+	recordAudit(s.repo, change.UserID, constants.AuditActionEmailChanged, constants.AuditEntityUser, change.UserID,
+		map[string]interface{}{"old_email": user.Email, "new_email": change.NewEmail})
+	return &dtos.ConfirmEmailChangeResponseDto{Completed: true, Email: change.NewEmail}, nil
+}
+
+// CancelEmailChange drops the pending email change of a user, its links stop working
+func (s *AccountService) CancelEmailChange(userID int) error {
+	deleted, err := s.repo.DeleteEmailChange(userID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel email change: %w", err)
+	}
+	if !deleted {
+		return newError(ErrNotFound, constants.ErrEmailChangeNotFound)
+	}
+	recordAudit(s.repo, userID, constants.AuditActionEmailChangeCancel, constants.AuditEntityUser, userID, nil)
+	return nil
+}
+
+// hashEmailChangeToken is the key a confirmation token is stored under, so a leak of the database does not leak
+// usable links
+func hashEmailChangeToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func toEmailChangeDto(change models.EmailChange) *dtos.EmailChangeDto {
+	return &dtos.EmailChangeDto{
+		NewEmail:         change.NewEmail,
+		CurrentConfirmed: change.OldConfirmedAt != nil,
+		NewConfirmed:     change.NewConfirmedAt != nil,
+		RequestedAt:      change.CreatedAt,
+		ExpiresAt:        change.ExpiresAt,
+	}
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/danizion/contact-app/internal/accountstate"
+	"github.com/danizion/contact-app/internal/auth"
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/mail"
+	"github.com/danizion/contact-app/internal/repository"
+	"github.com/danizion/contact-app/internal/storage/redis"
+	"github.com/danizion/contact-app/internal/utils"
+)
+
+// reactivationData is the data rendered by the reactivation email template
+type reactivationData struct {
+	Username  string
+	Link      string
+	ExpiresAt time.Time
+}
+
+// AccountStateService moves accounts through the states of the accountstate package: users deactivate their own
+// account, and reactivate it from a link sent to its email. It backs the middleware refusing deactivated accounts
+type AccountStateService struct {
+	repo   *repository.Repository
+	redis  *redis.Redis
+	sender mail.Sender
+	// publicURL starts the reactivation links sent by email
+	publicURL string
+}
+
+// NewAccountStateService creates a new instance of AccountStateService, reactivation links are not sent without a
+// sender
+func NewAccountStateService(db *sql.DB, redisClient *redis.Redis, sender mail.Sender) *AccountStateService {
+	return &AccountStateService{
+		repo:      repository.NewRepository(db),
+		redis:     redisClient,
+		sender:    sender,
+		publicURL: strings.TrimSuffix(utils.GetEnvOrDefault("PUBLIC_URL", constants.DefaultPublicURL), "/"),
+	}
+}
+
+// WithContext returns the service running its queries and cache calls under ctx, the context of a request so they stop with it
+func (s *AccountStateService) WithContext(ctx context.Context) *AccountStateService {
+	bound := *s
+	bound.repo = s.repo.WithContext(ctx)
+	bound.redis = s.redis.WithContext(ctx)
+	return &bound
+}
+
+// CanUseAPI tells whether the account of a user is in a state allowing it to use the API
+func (s *AccountStateService) CanUseAPI(userID int) (bool, error) {
+	state, err := s.repo.GetAccountState(userID)
+	if err != nil {
+		return false, err
+	}
+	return accountstate.CanUseAPI(state), nil
+}
+
+// Deactivate freezes the account of a user once the password is verified. Its data is kept but its sessions are
+// revoked, it cannot log in and its webhooks and digests are paused until it is reactivated
+func (s *AccountStateService) Deactivate(req dtos.DeactivateAccountRequestDto) error {
+	user, err := s.repo.GetUser(req.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if !auth.CheckPassword(req.Password, user.HashedPassword) {
+		return newError(ErrForbidden, constants.ErrInvalidPassword)
+	}
+	if err := s.transition(user.ID, user.State, accountstate.Deactivate); err != nil {
+		return err
+	}
+
+	if s.redis != nil {
+		if _, err := s.redis.RevokeSessions(user.ID); err != nil {
+			slog.Error("Failed to revoke the sessions of a deactivated user", "error", err, "userID", user.ID)
+		}
+	}
+	recordAudit(s.repo, user.ID, constants.AuditActionAccountDeactivated, constants.AuditEntityUser, user.ID, clientIPDetails(req.ClientIP))
+	return nil
+}
+
+// RequestReactivation emails a reactivation link to the account of an email when it is deactivated, replacing the
+// previous link. Nothing is sent for other emails and the caller is not told, so the endpoint does not reveal which
+// emails have an account
+func (s *AccountStateService) RequestReactivation(email string) error {
+	if s.sender == nil {
+		return newError(ErrUnavailable, constants.ErrEmailNotConfigured)
+	}
+	user, err := s.repo.GetUserByEmail(strings.TrimSpace(email))
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil
+	}
+	if _, err := accountstate.Next(user.State, accountstate.Reactivate); err != nil {
+		return nil
+	}
+
+	token, err := randomToken("", 32)
+	if err != nil {
+		return err
+	}
+	expiresAt := time.Now().Add(constants.ReactivationTTL).Truncate(time.Second)
+	if err := s.repo.SaveReactivation(user.ID, hashEmailChangeToken(token), expiresAt); err != nil {
+		return fmt.Errorf("failed to save reactivation: %w", err)
+	}
+
+	msg, err := mail.Render("account_reactivation", user.Email, reactivationData{
+		Username:  user.Username,
+		Link:      s.publicURL + "/users/reactivate/confirm?token=" + url.QueryEscape(token),
+		ExpiresAt: expiresAt,
+	})
+	if err == nil {
+		err = s.sender.Send(msg)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to send reactivation email: %w", err)
+	}
+	return nil
+}
+
+// Reactivate reactivates the account of a reactivation link, the link works once. The user then logs in again
+func (s *AccountStateService) Reactivate(token string) error {
+	if token == "" {
+		return newError(ErrNotFound, constants.ErrInvalidReactivationToken)
+	}
+	userID, err := s.repo.ClaimReactivation(hashEmailChangeToken(token))
+	if err != nil {
+		return fmt.Errorf("failed to claim reactivation: %w", err)
+	}
+	if userID == 0 {
+		return newError(ErrNotFound, constants.ErrInvalidReactivationToken)
+	}
+	state, err := s.repo.GetAccountState(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get account state: %w", err)
+	}
+	if err := s.transition(userID, state, accountstate.Reactivate); err != nil {
+		return err
+	}
+	recordAudit(s.repo, userID, constants.AuditActionAccountReactivated, constants.AuditEntityUser, userID, nil)
+	return nil
+}
+
+// transition applies event to an account in state, failing when the state machine does not allow it or when the
+// account changed state meanwhile
+func (s *AccountStateService) transition(userID int, state, event string) error {
+	next, err := accountstate.Next(state, event)
+	if err != nil {
+		return err
+	}
+	changed, err := s.repo.SetAccountState(userID, state, next)
+	if err != nil {
+		return fmt.Errorf("failed to change account state: %w", err)
+	}
+	if !changed {
+		return newError(ErrConflict, "%s: the account changed state meanwhile", constants.ErrInvalidAccountTransition)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/danizion/contact-app/internal/alerting"
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/repository"
+)
+
+// AlertService manages the per route suppression windows of the 5xx error budget alerting
+type AlertService struct {
+	repo    *repository.Repository
+	monitor *alerting.Monitor
+}
+
+// NewAlertService creates a new instance of AlertService, monitor is nil when alerting is disabled
+func NewAlertService(db *sql.DB, monitor *alerting.Monitor) *AlertService {
+	return &AlertService{
+		repo:    repository.NewRepository(db),
+		monitor: monitor,
+	}
+}
+
+// WithContext returns the service running its queries under ctx, the context of a request so they stop with it
+func (s *AlertService) WithContext(ctx context.Context) *AlertService {
+	bound := *s
+	bound.repo = s.repo.WithContext(ctx)
+	return &bound
+}
+
+// GetSettings returns the error budget and the state of the routes with a suppression override or recent traffic
+func (s *AlertService) GetSettings() (*dtos.AlertSettingsDto, error) {
+	suppressions, err := s.suppressions()
+	if err != nil {
+		return nil, err
+	}
+
+	config := alerting.LoadConfig()
+	var statuses []alerting.RouteStatus
+	if s.monitor != nil {
+		config = s.monitor.Config()
+		statuses = s.monitor.Status()
+	}
+
+	routes := make(map[string]*dtos.RouteAlertDto)
+	for route, suppression := range suppressions {
+		routes[route] = &dtos.RouteAlertDto{Route: route, Suppression: suppression.String()}
+	}
+	for _, status := range statuses {
+		route, ok := routes[status.Route]
+		if !ok {
+			route = &dtos.RouteAlertDto{Route: status.Route}
+			routes[status.Route] = route
+		}
+		route.Requests, route.Errors = status.Requests, status.Errors
+		if status.SuppressedUntil.After(time.Now()) {
+			suppressedUntil := status.SuppressedUntil.UTC()
+			route.SuppressedUntil = &suppressedUntil
+		}
+	}
+
+	result := &dtos.AlertSettingsDto{
+		Enabled:            s.monitor != nil,
+		Threshold:          config.Threshold,
+		MinRequests:        config.MinRequests,
+		Window:             config.Window.String(),
+		DefaultSuppression: config.Suppression.String(),
+		Routes:             make([]dtos.RouteAlertDto, 0, len(routes)),
+	}
+	for _, route := range routes {
+		result.Routes = append(result.Routes, *route)
+	}
+	sort.Slice(result.Routes, func(i, j int) bool { return result.Routes[i].Route < result.Routes[j].Route })
+	return result, nil
+}
+
+// SetRouteSuppression saves the suppression window of a route and applies it right away on this replica, an empty
+// suppression resets the route to the default
+func (s *AlertService) SetRouteSuppression(req dtos.SetRouteSuppressionRequestDto) (*dtos.AlertSettingsDto, error) {
+	suppressions, err := s.suppressions()
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Suppression == "" {
+		delete(suppressions, req.Route)
+	} else {
+		suppression, err := time.ParseDuration(req.Suppression)
+		if err != nil || suppression <= 0 {
+			return nil, newError(ErrInvalidInput, constants.ErrInvalidSuppression)
+		}
+		suppressions[req.Route] = suppression
+	}
+
+	value := make(map[string]string, len(suppressions))
+	for route, suppression := range suppressions {
+		value[route] = suppression.String()
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode alert suppressions: %w", err)
+	}
+	if err := s.repo.SaveInstanceSetting(constants.SettingAlertSuppressions, string(data)); err != nil {
+		return nil, fmt.Errorf("failed to save alert suppressions: %w", err)
+	}
+
+	if s.monitor != nil {
+		s.monitor.SetSuppressions(suppressions)
+	}
+	return s.GetSettings()
+}
+
+// ApplySettings loads the saved suppression windows into the monitor, it runs on startup and periodically so every
+// replica follows a change made through another one
+func (s *AlertService) ApplySettings() error {
+	suppressions, err := s.suppressions()
+	if err != nil {
+		return err
+	}
+	if s.monitor != nil {
+		s.monitor.SetSuppressions(suppressions)
+	}
+	return nil
+}
+
+func (s *AlertService) suppressions() (map[string]time.Duration, error) {
+	value, found, err := s.repo.GetInstanceSetting(constants.SettingAlertSuppressions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get alert suppressions: %w", err)
+	}
+
+	suppressions := make(map[string]time.Duration)
+	if !found {
+		return suppressions, nil
+	}
+	var saved map[string]string
+	if err := json.Unmarshal([]byte(value), &saved); err != nil {
+		return nil, fmt.Errorf("failed to decode alert suppressions: %w", err)
+	}
+	for route, text := range saved {
+		if suppression, err := time.ParseDuration(text); err == nil && suppression > 0 {
+			suppressions[route] = suppression
+		}
+	}
+	return suppressions, nil
+}
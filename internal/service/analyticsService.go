@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	"github.com/danizion/contact-app/internal/analytics"
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/repository"
+)
+
+// AnalyticsService manages the instance wide analytics opt-out
+type AnalyticsService struct {
+	repo *repository.Repository
+}
+
+// NewAnalyticsService creates a new instance of AnalyticsService
+func NewAnalyticsService(db *sql.DB) *AnalyticsService {
+	return &AnalyticsService{
+		repo: repository.NewRepository(db),
+	}
+}
+
+// WithContext returns the service running its queries under ctx, the context of a request so they stop with it
+func (s *AnalyticsService) WithContext(ctx context.Context) *AnalyticsService {
+	bound := *s
+	bound.repo = s.repo.WithContext(ctx)
+	return &bound
+}
+
+// GetSettings returns the analytics settings, analytics are enabled until an admin opts the instance out
+func (s *AnalyticsService) GetSettings() (*dtos.AnalyticsSettingsDto, error) {
+	enabled, err := s.enabled()
+	if err != nil {
+		return nil, err
+	}
+	return &dtos.AnalyticsSettingsDto{Enabled: enabled, Configured: analytics.Configured()}, nil
+}
+
+// UpdateSettings saves the analytics opt-out and applies it right away on this replica
+func (s *AnalyticsService) UpdateSettings(req dtos.UpdateAnalyticsSettingsRequestDto) (*dtos.AnalyticsSettingsDto, error) {
+	if err := s.repo.SaveInstanceSetting(constants.SettingAnalyticsEnabled, strconv.FormatBool(*req.Enabled)); err != nil {
+		return nil, fmt.Errorf("failed to save analytics settings: %w", err)
+	}
+	analytics.SetEnabled(*req.Enabled)
+	return &dtos.AnalyticsSettingsDto{Enabled: *req.Enabled, Configured: analytics.Configured()}, nil
+}
+
+// ApplySettings loads the saved opt-out into the emitter, it runs on startup and periodically so every replica
+// follows a change made through another one
+func (s *AnalyticsService) ApplySettings() error {
+	enabled, err := s.enabled()
+	if err != nil {
+		return err
+	}
+	analytics.SetEnabled(enabled)
+	return nil
+}
+
+func (s *AnalyticsService) enabled() (bool, error) {
+	value, found, err := s.repo.GetInstanceSetting(constants.SettingAnalyticsEnabled)
+	if err != nil {
+		return false, fmt.Errorf("failed to get analytics settings: %w", err)
+	}
+	return !found || value == "true", nil
+}
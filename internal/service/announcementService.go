@@ -0,0 +1,217 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/danizion/contact-app/internal/repository"
+)
+
+// AnnouncementService handles the announcements admins post to every user. The announcements not ended yet are
+// kept in memory for AnnouncementCacheTTL, so requests only read the database for the dismissals of their user
+// while an announcement is shown
+type AnnouncementService struct {
+	repo *repository.Repository
+	now  func() time.Time
+
+	mu       sync.Mutex
+	cached   []models.Announcement
+	loadedAt time.Time
+}
+
+// NewAnnouncementService creates a new instance of AnnouncementService
+func NewAnnouncementService(db *sql.DB) *AnnouncementService {
+	return &AnnouncementService{
+		repo: repository.NewRepository(db),
+		now:  time.Now,
+	}
+}
+
+// CreateAnnouncement posts an announcement from an admin
+func (s *AnnouncementService) CreateAnnouncement(adminID int, req dtos.CreateAnnouncementRequestDto) (*dtos.AnnouncementDto, error) {
+	announcement := models.Announcement{
+		Message:   strings.TrimSpace(req.Message),
+		Level:     strings.ToLower(strings.TrimSpace(req.Level)),
+		StartsAt:  s.now(),
+		EndsAt:    req.EndsAt,
+		CreatedBy: &adminID,
+	}
+	if announcement.Level == "" {
+		announcement.Level = constants.AnnouncementLevelInfo
+	}
+	if !isAnnouncementLevel(announcement.Level) {
+		return nil, newError(ErrInvalidInput, constants.ErrInvalidAnnouncementLevel)
+	}
+	if req.StartsAt != nil {
+		announcement.StartsAt = *req.StartsAt
+	}
+	if announcement.EndsAt != nil && !announcement.EndsAt.After(announcement.StartsAt) {
+		return nil, newError(ErrInvalidInput, constants.ErrInvalidAnnouncementWindow)
+	}
+
+	created, err := s.repo.CreateAnnouncement(announcement)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create announcement: %w", err)
+	}
+	s.invalidate()
+	return toAnnouncementDto(*created), nil
+}
+
+// GetAnnouncements lists every announcement for the admins, ended and scheduled ones included
+func (s *AnnouncementService) GetAnnouncements() ([]dtos.AnnouncementDto, error) {
+	announcements, err := s.repo.GetAnnouncements()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get announcements: %w", err)
+	}
+	result := make([]dtos.AnnouncementDto, 0, len(announcements))
+	for _, announcement := range announcements {
+		result = append(result, *toAnnouncementDto(announcement))
+	}
+	return result, nil
+}
+
+// DeleteAnnouncement removes an announcement, it stops being shown right away on this replica
+func (s *AnnouncementService) DeleteAnnouncement(id int) error {
+	deleted, err := s.repo.DeleteAnnouncement(id)
+	if err != nil {
+		return fmt.Errorf("failed to delete announcement: %w", err)
+	}
+	if !deleted {
+		return newError(ErrNotFound, constants.ErrAnnouncementNotFound)
+	}
+	s.invalidate()
+	return nil
+}
+
+// GetUserAnnouncements lists the announcements shown to a user, the latest first
+func (s *AnnouncementService) GetUserAnnouncements(userID int) ([]dtos.AnnouncementDto, error) {
+	announcements, err := s.shownTo(userID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]dtos.AnnouncementDto, 0, len(announcements))
+	for _, announcement := range announcements {
+		result = append(result, *toAnnouncementDto(announcement))
+	}
+	return result, nil
+}
+
+// DismissAnnouncement hides an announcement from a user, only announcements being shown can be dismissed
+func (s *AnnouncementService) DismissAnnouncement(userID, id int) error {
+	active, err := s.active()
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, announcement := range active {
+		if announcement.ID == id {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return newError(ErrNotFound, constants.ErrAnnouncementNotFound)
+	}
+
+	if err := s.repo.DismissAnnouncement(userID, id); err != nil {
+		return fmt.Errorf("failed to dismiss announcement: %w", err)
+	}
+	return nil
+}
+
+// CurrentAnnouncement returns the latest announcement a user did not dismiss, for the X-Announcement header.
+// Failures are only logged, they never fail the request
+func (s *AnnouncementService) CurrentAnnouncement(userID int) (id int, level string, ok bool) {
+	announcements, err := s.shownTo(userID)
+	if err != nil {
+		slog.Error("Failed to get current announcement", "error", err, "userID", userID)
+		return 0, "", false
+	}
+	if len(announcements) == 0 {
+		return 0, "", false
+	}
+	return announcements[0].ID, announcements[0].Level, true
+}
+
+// shownTo returns the active announcements a user did not dismiss, the latest first
+func (s *AnnouncementService) shownTo(userID int) ([]models.Announcement, error) {
+	active, err := s.active()
+	if err != nil || len(active) == 0 {
+		return nil, err
+	}
+
+	ids := make([]int, len(active))
+	for i, announcement := range active {
+		ids[i] = announcement.ID
+	}
+	dismissed, err := s.repo.GetDismissedAnnouncements(userID, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dismissed announcements: %w", err)
+	}
+
+	shown := make([]models.Announcement, 0, len(active))
+	for _, announcement := range active {
+		if !dismissed[announcement.ID] {
+			shown = append(shown, announcement)
+		}
+	}
+	return shown, nil
+}
+
+// active returns the announcements started and not ended, reloading them when the cache is older than
+// AnnouncementCacheTTL. Scheduled announcements are cached too and start being shown on time
+func (s *AnnouncementService) active() ([]models.Announcement, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if s.loadedAt.IsZero() || now.Sub(s.loadedAt) >= constants.AnnouncementCacheTTL {
+		announcements, err := s.repo.GetUnexpiredAnnouncements(now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get announcements: %w", err)
+		}
+		s.cached, s.loadedAt = announcements, now
+	}
+
+	active := make([]models.Announcement, 0, len(s.cached))
+	for _, announcement := range s.cached {
+		if !announcement.StartsAt.After(now) && (announcement.EndsAt == nil || announcement.EndsAt.After(now)) {
+			active = append(active, announcement)
+		}
+	}
+	return active, nil
+}
+
+// invalidate drops the cached announcements so a change shows up on the next request
+func (s *AnnouncementService) invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loadedAt = time.Time{}
+}
+
+func isAnnouncementLevel(level string) bool {
+	for _, known := range constants.AnnouncementLevels {
+		if level == known {
+			return true
+		}
+	}
+	return false
+}
+
+func toAnnouncementDto(announcement models.Announcement) *dtos.AnnouncementDto {
+	return &dtos.AnnouncementDto{
+		ID:        announcement.ID,
+		Message:   announcement.Message,
+		Level:     announcement.Level,
+		StartsAt:  announcement.StartsAt,
+		EndsAt:    announcement.EndsAt,
+		CreatedAt: announcement.CreatedAt,
+	}
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/danizion/contact-app/internal/repository"
+	"github.com/danizion/contact-app/internal/storage/redis"
+)
+
+// APIKeyService manages the API keys of users and backs the API key authentication of the middlewares
+type APIKeyService struct {
+	repo  *repository.Repository
+	redis *redis.Redis
+}
+
+// NewAPIKeyService creates a new instance of APIKeyService
+func NewAPIKeyService(db *sql.DB, redisClient *redis.Redis) *APIKeyService {
+	return &APIKeyService{
+		repo:  repository.NewRepository(db),
+		redis: redisClient,
+	}
+}
+
+// WithContext returns the service running its queries and cache calls under ctx, the context of a request so they stop with it
+func (s *APIKeyService) WithContext(ctx context.Context) *APIKeyService {
+	bound := *s
+	bound.repo = s.repo.WithContext(ctx)
+	bound.redis = s.redis.WithContext(ctx)
+	return &bound
+}
+
+// CreateAPIKey generates a new API key, its secret is only returned here
+func (s *APIKeyService) CreateAPIKey(req dtos.CreateAPIKeyRequestDto) (*dtos.APIKeyResponseDto, error) {
+	count, err := s.repo.CountAPIKeysByUser(req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count API keys: %w", err)
+	}
+	if count >= constants.MaxAPIKeysPerUser {
+		return nil, newError(ErrConflict, constants.ErrAPIKeyLimitReached)
+	}
+
+	keyID, err := randomToken("ck_", 12)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := randomToken("cs_", 24)
+	if err != nil {
+		return nil, err
+	}
+	key := models.APIKey{
+		UserID:    req.UserID,
+		Name:      strings.TrimSpace(req.Name),
+		KeyID:     keyID,
+		Secret:    secret,
+		CreatedAt: time.Now(),
+	}
+	key.ID, err = s.repo.CreateAPIKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	result := toAPIKeyDto(key)
+	result.Secret = key.Secret
+	return &result, nil
+}
+
+// ListAPIKeys returns the user's API keys without their secrets
+func (s *APIKeyService) ListAPIKeys(userID int) ([]dtos.APIKeyResponseDto, error) {
+	keys, err := s.repo.GetAPIKeysByUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API keys: %w", err)
+	}
+
+	result := make([]dtos.APIKeyResponseDto, len(keys))
+	for i, key := range keys {
+		result[i] = toAPIKeyDto(key)
+	}
+	return result, nil
+}
+
+// DeleteAPIKey revokes an API key
+func (s *APIKeyService) DeleteAPIKey(userID, id int) error {
+	err := s.repo.DeleteAPIKey(userID, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return newError(ErrNotFound, constants.ErrAPIKeyNotFound)
+		}
+		return fmt.Errorf("failed to delete API key: %w", err)
+	}
+	return nil
+}
+
+// ResolveAPIKey returns the API key with the public keyID, nil when there is none
+func (s *APIKeyService) ResolveAPIKey(keyID string) (*models.APIKey, error) {
+	return s.repo.GetAPIKeyByKeyID(keyID)
+}
+
+// ClaimNonce records the nonce of a signed request, it returns false when the nonce was already used
+func (s *APIKeyService) ClaimNonce(keyID, nonce string, ttl time.Duration) (bool, error) {
+	return s.redis.ClaimNonce("apikey:"+keyID, nonce, ttl)
+}
+
+func toAPIKeyDto(key models.APIKey) dtos.APIKeyResponseDto {
+	return dtos.APIKeyResponseDto{
+		ID:        key.ID,
+		Name:      key.Name,
+		KeyID:     key.KeyID,
+		CreatedAt: key.CreatedAt,
+	}
+}
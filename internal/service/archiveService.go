@@ -0,0 +1,412 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/danizion/contact-app/internal/address"
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/danizion/contact-app/internal/repository"
+	"github.com/danizion/contact-app/internal/social"
+	"github.com/danizion/contact-app/internal/storage/redis"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// ArchiveService exports a whole account to a versioned archive and imports such archives into an account
+type ArchiveService struct {
+	repo  *repository.Repository
+	redis *redis.Redis
+}
+
+// NewArchiveService creates a new instance of ArchiveService
+func NewArchiveService(db *sql.DB, redisClient *redis.Redis) *ArchiveService {
+	return &ArchiveService{
+		repo:  repository.NewRepository(db),
+		redis: redisClient,
+	}
+}
+
+// WithContext returns the service running its queries and cache calls under ctx, the context of a request so they stop with it
+func (s *ArchiveService) WithContext(ctx context.Context) *ArchiveService {
+	bound := *s
+	bound.repo = s.repo.WithContext(ctx)
+	bound.redis = s.redis.WithContext(ctx)
+	return &bound
+}
+
+// ArchiveValidationError rejects an archive before anything is imported, Problems lists what is wrong with it
+type ArchiveValidationError struct {
+	Problems []string
+}
+
+func (e *ArchiveValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", constants.ErrInvalidArchive, strings.Join(e.Problems, "; "))
+}
+
+// ExportAccount returns the contacts, groups, tags and preferences of a user as an archive
+func (s *ArchiveService) ExportAccount(userID int) (*dtos.AccountArchiveDto, error) {
+	contacts, err := s.repo.GetContactsByUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contacts: %w", err)
+	}
+	groups, err := s.repo.GetGroupsByUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get groups: %w", err)
+	}
+	tags, err := s.repo.GetTagsByUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+	prefs, err := s.repo.GetUserPreferences(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get preferences: %w", err)
+	}
+
+	contactIDs := make([]int, len(contacts))
+	for i, contact := range contacts {
+		contactIDs[i] = contact.ID
+	}
+	contactGroups, err := s.repo.GetGroupsByContacts(contactIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contact groups: %w", err)
+	}
+	contactTags, err := s.repo.GetTagsByContacts(contactIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contact tags: %w", err)
+	}
+	profiles, err := s.repo.GetSocialProfilesByContacts(contactIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get social profiles: %w", err)
+	}
+
+	groupNames := make(map[int][]string)
+	for _, membership := range contactGroups {
+		groupNames[membership.ContactID] = append(groupNames[membership.ContactID], membership.Name)
+	}
+	tagNames := make(map[int][]string)
+	for _, membership := range contactTags {
+		tagNames[membership.ContactID] = append(tagNames[membership.ContactID], membership.Name)
+	}
+	contactProfiles := make(map[int][]dtos.SocialProfileDto)
+	for _, profile := range profiles {
+		contactProfiles[profile.ContactID] = append(contactProfiles[profile.ContactID],
+			dtos.SocialProfileDto{Network: profile.Network, Handle: profile.Handle, URL: profile.URL})
+	}
+
+	archive := &dtos.AccountArchiveDto{
+		Format:      constants.ArchiveFormat,
+		Version:     constants.ArchiveVersion,
+		ExportedAt:  time.Now().UTC(),
+		Contacts:    make([]dtos.ArchiveContactDto, len(contacts)),
+		Groups:      make([]string, len(groups)),
+		Tags:        make([]string, len(tags)),
+		Preferences: toPreferencesDto(prefs),
+	}
+	for i, contact := range contacts {
+		archive.Contacts[i] = toArchiveContact(contact)
+		archive.Contacts[i].Groups = groupNames[contact.ID]
+		archive.Contacts[i].Tags = tagNames[contact.ID]
+		archive.Contacts[i].SocialProfiles = contactProfiles[contact.ID]
+	}
+	for i, group := range groups {
+		archive.Groups[i] = group.Name
+	}
+	for i, tag := range tags {
+		archive.Tags[i] = tag.Name
+	}
+	return archive, nil
+}
+
+// ImportAccount validates a whole archive and then adds it to the account in one transaction. Groups and tags are
+// matched by name and contacts whose name is already taken are skipped. Sections unknown to this version, and
+// social profiles on networks it does not support, are skipped with a warning so newer archives still import
+func (s *ArchiveService) ImportAccount(userID int, data []byte) (*dtos.ImportAccountResponseDto, error) {
+	var sections map[string]json.RawMessage
+	if err := json.Unmarshal(data, &sections); err != nil {
+		return nil, &ArchiveValidationError{Problems: []string{"archive must be a JSON object"}}
+	}
+	var archive dtos.AccountArchiveDto
+	if err := json.Unmarshal(data, &archive); err != nil {
+		return nil, &ArchiveValidationError{Problems: []string{err.Error()}}
+	}
+
+	result := &dtos.ImportAccountResponseDto{}
+	var unknownSections []string
+	for name := range sections {
+		if !isArchiveSection(name) {
+			unknownSections = append(unknownSections, name)
+		}
+	}
+	sort.Strings(unknownSections)
+	for _, name := range unknownSections {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("skipped section %q unknown to archive version %d", name, constants.ArchiveVersion))
+	}
+	if archive.Version > constants.ArchiveVersion {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("archive version %d is newer than %d, fields unknown to this version were skipped",
+			archive.Version, constants.ArchiveVersion))
+	}
+
+	groups, tags, contacts, warnings, err := s.validateArchive(&archive)
+	if err != nil {
+		return nil, err
+	}
+	result.Warnings = append(result.Warnings, warnings...)
+
+	var prefs *models.UserPreferences
+	if archive.Preferences != nil {
+		prefs = &models.UserPreferences{UserID: userID, WeeklyDigest: archive.Preferences.WeeklyDigest}
+		if prefs.Region, err = normalizeRegion(archive.Preferences.Region); err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("skipped unknown region %q", archive.Preferences.Region))
+		}
+		if prefs.SearchLanguage, err = normalizeSearchLanguage(s.repo, archive.Preferences.SearchLanguage); err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("skipped unknown search language %q", archive.Preferences.SearchLanguage))
+		}
+		if prefs.DuplicateSensitivity, err = normalizeDuplicateSensitivity(archive.Preferences.DuplicateSensitivity); err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("skipped unknown duplicate sensitivity %q", archive.Preferences.DuplicateSensitivity))
+		}
+	}
+
+	// Imported preferences are written with the contacts, their region is the one of the phone numbers
+	sources := defaultPhoneRegions(s.repo)
+	if prefs != nil {
+		sources = []phoneRegionSource{fixedPhoneRegion(prefs.Region), fixedPhoneRegion(deploymentPhoneRegion())}
+	}
+	region, err := resolvePhoneRegion(sources, userID, "")
+	if err != nil {
+		return nil, err
+	}
+	normalizeImportedPhones(contacts, region)
+
+	imported, err := s.repo.ImportAccount(userID, groups, tags, contacts, prefs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to import account: %w", err)
+	}
+	result.ContactsImported = len(imported.ContactIDs)
+	result.ContactsSkipped = imported.Skipped
+	result.GroupsCreated = imported.GroupsCreated
+	result.TagsCreated = imported.TagsCreated
+	result.PreferencesImported = prefs != nil
+
+	for _, contactID := range imported.ContactIDs {
+		recordContactChange(s.repo, userID, constants.AuditActionContactCreated, contactID, map[string]interface{}{"source": "account_import"})
+	}
+	return result, nil
+}
+
+// validateArchive checks every value of the archive against the rules applied when creating contacts, groups and
+// tags one by one, and returns the groups, tags and contacts to import. Nothing is imported when a problem is found
+func (s *ArchiveService) validateArchive(archive *dtos.AccountArchiveDto) ([]string, []string, []repository.ArchivedContact, []string, error) {
+	var problems, warnings []string
+	if archive.Format != constants.ArchiveFormat {
+		problems = append(problems, fmt.Sprintf("format must be %q", constants.ArchiveFormat))
+	}
+	if err := binding.Validator.ValidateStruct(archive); err != nil {
+		var validationErrors validator.ValidationErrors
+		if !errors.As(err, &validationErrors) {
+			return nil, nil, nil, nil, fmt.Errorf("failed to validate archive: %w", err)
+		}
+		for _, fieldError := range validationErrors {
+			field := strings.TrimPrefix(fieldError.Namespace(), "AccountArchiveDto.")
+			problems = append(problems, fmt.Sprintf("%s failed the %s rule", archiveFieldPath(field), fieldError.Tag()))
+		}
+	}
+
+	groups := newNameSet()
+	for _, name := range archive.Groups {
+		groups.add(strings.TrimSpace(name))
+	}
+	tags := newNameSet()
+	for _, name := range archive.Tags {
+		tag, err := normalizeTagName(name)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("tags: %v", err))
+			continue
+		}
+		tags.add(tag)
+	}
+
+	contacts := make([]repository.ArchivedContact, len(archive.Contacts))
+	for i, entry := range archive.Contacts {
+		contact, contactWarnings, err := validateArchiveContact(s.repo, entry)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("contacts[%d]: %v", i, err))
+			continue
+		}
+		for _, warning := range contactWarnings {
+			warnings = append(warnings, fmt.Sprintf("contacts[%d]: %s", i, warning))
+		}
+		for _, name := range contact.Groups {
+			groups.add(name)
+		}
+		for _, name := range contact.Tags {
+			tags.add(name)
+		}
+		contacts[i] = contact
+	}
+
+	if len(problems) > 0 {
+		if len(problems) > constants.MaxArchiveProblems {
+			problems = append(problems[:constants.MaxArchiveProblems], fmt.Sprintf("and %d more", len(problems)-constants.MaxArchiveProblems))
+		}
+		return nil, nil, nil, nil, &ArchiveValidationError{Problems: problems}
+	}
+	return groups.names, tags.names, contacts, warnings, nil
+}
+
+// validateArchiveContact applies the rules of contact creation to a contact read from an archive or a contact file,
+// and returns it with its groups, tags and social profiles normalized. Unsupported social networks are warnings
+func validateArchiveContact(repo *repository.Repository, entry dtos.ArchiveContactDto) (repository.ArchivedContact, []string, error) {
+	archived := repository.ArchivedContact{}
+	if err := validatePicklistValue(repo, constants.PicklistFieldSource, entry.Source); err != nil {
+		return archived, nil, err
+	}
+	if err := validatePicklistValue(repo, constants.PicklistFieldStage, entry.Stage); err != nil {
+		return archived, nil, err
+	}
+	if err := validateTimezone(entry.Timezone); err != nil {
+		return archived, nil, err
+	}
+	if (entry.Latitude == nil) != (entry.Longitude == nil) {
+		return archived, nil, newError(ErrInvalidInput, constants.ErrInvalidLocation)
+	}
+
+	// A structured address replaces the free-text one with its country specific rendering, as on create
+	structuredAddress := address.Address{
+		Street:      entry.Street,
+		City:        entry.City,
+		Region:      entry.Region,
+		PostalCode:  entry.PostalCode,
+		CountryCode: entry.CountryCode,
+	}
+	if !structuredAddress.IsEmpty() {
+		if err := address.Validate(structuredAddress); err != nil {
+			return archived, nil, newError(ErrInvalidInput, "%s: %w", constants.ErrInvalidAddress, err)
+		}
+		structuredAddress.CountryCode = strings.ToUpper(structuredAddress.CountryCode)
+		entry.CountryCode = structuredAddress.CountryCode
+		entry.Address = address.FormatSingleLine(structuredAddress)
+	}
+
+	for _, name := range entry.Groups {
+		name = strings.TrimSpace(name)
+		if name == "" || len(name) > 50 {
+			return archived, nil, fmt.Errorf("group name %q must be 1 to 50 characters", name)
+		}
+		archived.Groups = append(archived.Groups, name)
+	}
+	for _, name := range entry.Tags {
+		tag, err := normalizeTagName(name)
+		if err != nil {
+			return archived, nil, err
+		}
+		archived.Tags = append(archived.Tags, tag)
+	}
+
+	var warnings []string
+	for _, profile := range entry.SocialProfiles {
+		if !social.IsSupported(profile.Network) {
+			warnings = append(warnings, fmt.Sprintf("skipped profile on unsupported social network %q", profile.Network))
+			continue
+		}
+		handle, url, err := social.Normalize(profile.Network, firstNonEmpty(profile.Handle, profile.URL))
+		if err != nil {
+			return archived, nil, err
+		}
+		archived.SocialProfiles = append(archived.SocialProfiles, models.SocialProfile{Network: profile.Network, Handle: handle, URL: url})
+	}
+
+	archived.Contact = models.Contact{
+		FirstName:   entry.FirstName,
+		LastName:    entry.LastName,
+		PhoneNumber: entry.PhoneNumber,
+		Address:     entry.Address,
+		Email:       entry.Email,
+		Company:     entry.Company,
+		JobTitle:    entry.JobTitle,
+		Timezone:    entry.Timezone,
+		Street:      entry.Street,
+		City:        entry.City,
+		Region:      entry.Region,
+		PostalCode:  entry.PostalCode,
+		CountryCode: entry.CountryCode,
+		Latitude:    entry.Latitude,
+		Longitude:   entry.Longitude,
+		Source:      entry.Source,
+		Stage:       entry.Stage,
+	}
+	return archived, warnings, nil
+}
+
+func toArchiveContact(contact models.Contact) dtos.ArchiveContactDto {
+	return dtos.ArchiveContactDto{
+		FirstName:   contact.FirstName,
+		LastName:    contact.LastName,
+		PhoneNumber: contact.PhoneNumber,
+		Address:     contact.Address,
+		Email:       contact.Email,
+		Company:     contact.Company,
+		JobTitle:    contact.JobTitle,
+		Timezone:    contact.Timezone,
+		Street:      contact.Street,
+		City:        contact.City,
+		Region:      contact.Region,
+		PostalCode:  contact.PostalCode,
+		CountryCode: contact.CountryCode,
+		Latitude:    contact.Latitude,
+		Longitude:   contact.Longitude,
+		Source:      contact.Source,
+		Stage:       contact.Stage,
+	}
+}
+
+// archiveFieldPath turns the Go path of a field (Contacts[0].FirstName) into its path in the archive
+// (contacts[0].first_name), the archive DTOs name every JSON field after its Go field
+func archiveFieldPath(field string) string {
+	var b strings.Builder
+	for i, r := range field {
+		if unicode.IsUpper(r) {
+			if i > 0 && field[i-1] != '.' {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func isArchiveSection(name string) bool {
+	for _, section := range constants.ArchiveSections {
+		if section == name {
+			return true
+		}
+	}
+	return false
+}
+
+// nameSet collects names once each, keeping the order they were first seen in
+type nameSet struct {
+	seen  map[string]bool
+	names []string
+}
+
+func newNameSet() *nameSet {
+	return &nameSet{seen: make(map[string]bool)}
+}
+
+func (s *nameSet) add(name string) {
+	if name != "" && !s.seen[name] {
+		s.seen[name] = true
+		s.names = append(s.names, name)
+	}
+}
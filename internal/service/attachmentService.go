@@ -0,0 +1,275 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danizion/contact-app/internal/auth"
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/danizion/contact-app/internal/policy"
+	"github.com/danizion/contact-app/internal/repository"
+	"github.com/danizion/contact-app/internal/storage/blob"
+	"github.com/danizion/contact-app/internal/utils"
+)
+
+// AttachmentService handles files attached to contacts, metadata is kept in the repository and content in the blob store
+type AttachmentService struct {
+	repo       *repository.Repository
+	blobStore  blob.Store
+	maxBytes   int64
+	quotaBytes int64
+}
+
+// NewAttachmentService creates a new instance of AttachmentService
+func NewAttachmentService(db *sql.DB, blobStore blob.Store) *AttachmentService {
+	return &AttachmentService{
+		repo:       repository.NewRepository(db),
+		blobStore:  blobStore,
+		maxBytes:   int64(utils.GetEnvIntOrDefault("MAX_ATTACHMENT_BYTES", constants.DefaultMaxAttachmentBytes)),
+		quotaBytes: int64(utils.GetEnvIntOrDefault("USER_STORAGE_QUOTA_BYTES", constants.DefaultUserStorageQuotaBytes)),
+	}
+}
+
+// WithContext returns the service running its queries under ctx, the context of a request so they stop with it
+func (s *AttachmentService) WithContext(ctx context.Context) *AttachmentService {
+	bound := *s
+	bound.repo = s.repo.WithContext(ctx)
+	return &bound
+}
+
+// UploadAttachment validates the file against the size, type and quota limits and stores it for the contact
+func (s *AttachmentService) UploadAttachment(req dtos.UploadAttachmentRequestDto, file io.Reader) (*dtos.AttachmentResponseDto, error) {
+	if err := s.checkContactOwnership(req.UserID, req.ContactID); err != nil {
+		return nil, err
+	}
+
+	if req.SizeBytes > s.maxBytes {
+		return nil, newError(ErrTooLarge, constants.ErrAttachmentTooLarge)
+	}
+
+	// Uploads announced over the quota are refused before reading them, the quota is enforced on the size written
+	// when the attachment is recorded
+	used, err := s.repo.GetUserStorageUsage(req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check storage usage: %w", err)
+	}
+	if used+req.SizeBytes > s.quotaBytes {
+		return nil, newError(ErrQuotaExceeded, constants.ErrStorageQuotaExceeded)
+	}
+
+	// Sniff the content type from the first bytes instead of trusting the client
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, fmt.Errorf("failed to read attachment: %w", err)
+	}
+	head = head[:n]
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	if !constants.AllowedAttachmentTypes[contentType] {
+		return nil, newError(ErrUnsupportedType, "%s: %s", constants.ErrAttachmentTypeNotAllow, contentType)
+	}
+
+	storageKey, err := newAttachmentKey(req.UserID, req.FileName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read one byte past the limit so a lying Content-Length is still caught
+	content := io.LimitReader(io.MultiReader(bytes.NewReader(head), file), s.maxBytes+1)
+	written, err := s.blobStore.Put(storageKey, content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store attachment: %w", err)
+	}
+	if written > s.maxBytes {
+		s.deleteBlob(storageKey)
+		return nil, newError(ErrTooLarge, constants.ErrAttachmentTooLarge)
+	}
+
+	attachment := models.Attachment{
+		ContactID:   req.ContactID,
+		UserID:      req.UserID,
+		FileName:    filepath.Base(req.FileName),
+		ContentType: contentType,
+		SizeBytes:   written,
+		StorageKey:  storageKey,
+	}
+	attachment.ID, err = s.repo.CreateAttachment(attachment, s.quotaBytes)
+	if err != nil {
+		s.deleteBlob(storageKey)
+		if errors.Is(err, repository.ErrStorageQuotaExceeded) {
+			return nil, newError(ErrQuotaExceeded, constants.ErrStorageQuotaExceeded)
+		}
+		return nil, fmt.Errorf("failed to create attachment: %w", err)
+	}
+
+	recordAudit(s.repo, req.UserID, constants.AuditActionAttachmentAdded, constants.AuditEntityContact, req.ContactID,
+		map[string]interface{}{"attachment_id": attachment.ID, "file_name": attachment.FileName})
+
+	result := toAttachmentDto(attachment)
+	result.CreatedAt = time.Now()
+	return &result, nil
+}
+
+// ListAttachments returns the attachments of a contact together with the user's storage usage
+func (s *AttachmentService) ListAttachments(userID, contactID int) (*dtos.AttachmentListResponseDto, error) {
+	if err := s.checkContactOwnership(userID, contactID); err != nil {
+		return nil, err
+	}
+
+	attachments, err := s.repo.GetAttachmentsByContact(userID, contactID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachments: %w", err)
+	}
+
+	used, err := s.repo.GetUserStorageUsage(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get storage usage: %w", err)
+	}
+
+	result := &dtos.AttachmentListResponseDto{
+		Items: make([]dtos.AttachmentResponseDto, len(attachments)),
+		Storage: dtos.StorageUsageDto{
+			UsedBytes:  used,
+			QuotaBytes: s.quotaBytes,
+		},
+	}
+	for i, attachment := range attachments {
+		result.Items[i] = toAttachmentDto(attachment)
+	}
+	return result, nil
+}
+
+// GetDownloadURL returns a signed link to download an attachment without a JWT, valid for a limited time
+func (s *AttachmentService) GetDownloadURL(userID, contactID, attachmentID int) (*dtos.AttachmentURLResponseDto, error) {
+	attachment, err := s.getOwnedAttachment(userID, contactID, attachmentID, policy.ActionRead)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(constants.AttachmentURLTTLMinutes * time.Minute).Truncate(time.Second)
+	signature := auth.SignResource(attachmentResource(attachment.ID), expiresAt)
+
+	return &dtos.AttachmentURLResponseDto{
+		URL:       fmt.Sprintf("/attachments/%d/download?expires=%d&signature=%s", attachment.ID, expiresAt.Unix(), signature),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// OpenSignedDownload verifies a signed link and opens the attachment content, the caller must close the reader
+func (s *AttachmentService) OpenSignedDownload(attachmentID int, expiresUnix int64, signature string) (*models.Attachment, io.ReadCloser, error) {
+	if !auth.VerifyResourceSignature(attachmentResource(attachmentID), expiresUnix, signature) {
+		return nil, nil, newError(ErrForbidden, constants.ErrInvalidSignedURL)
+	}
+
+	attachment, err := s.repo.GetAttachment(attachmentID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get attachment: %w", err)
+	}
+	if attachment == nil {
+		return nil, nil, newError(ErrNotFound, constants.ErrAttachmentNotFound)
+	}
+
+	content, err := s.blobStore.Get(attachment.StorageKey)
+	if err != nil {
+		if err == blob.ErrNotFound {
+			return nil, nil, newError(ErrNotFound, constants.ErrAttachmentNotFound)
+		}
+		return nil, nil, fmt.Errorf("failed to read attachment: %w", err)
+	}
+	return attachment, content, nil
+}
+
+// DeleteAttachment removes an attachment and its stored content, freeing its quota
+func (s *AttachmentService) DeleteAttachment(userID, contactID, attachmentID int) error {
+	attachment, err := s.getOwnedAttachment(userID, contactID, attachmentID, policy.ActionDelete)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.DeleteAttachment(userID, contactID, attachmentID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return newError(ErrNotFound, constants.ErrAttachmentNotFound)
+		}
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+
+	s.deleteBlob(attachment.StorageKey)
+	recordAudit(s.repo, userID, constants.AuditActionAttachmentDeleted, constants.AuditEntityContact, contactID,
+		map[string]interface{}{"attachment_id": attachment.ID, "file_name": attachment.FileName})
+	return nil
+}
+
+func (s *AttachmentService) checkContactOwnership(userID, contactID int) error {
+	owned, err := s.repo.IsContactOwnedByUser(userID, contactID)
+	if err != nil {
+		return fmt.Errorf("failed to check contact: %w", err)
+	}
+	if !owned {
+		return newError(ErrNotFound, constants.ErrContactNotFound)
+	}
+	return nil
+}
+
+// getOwnedAttachment loads an attachment of the contact the user may perform action on, attachments of other
+// users are reported as not found so their existence does not leak
+func (s *AttachmentService) getOwnedAttachment(userID, contactID, attachmentID int, action policy.Action) (*models.Attachment, error) {
+	attachment, err := s.repo.GetAttachment(attachmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachment: %w", err)
+	}
+	if attachment == nil || attachment.ContactID != contactID {
+		return nil, newError(ErrNotFound, constants.ErrAttachmentNotFound)
+	}
+	resource := policy.Resource{Type: policy.ResourceAttachment, ID: attachment.ID, OwnerID: attachment.UserID}
+	if !policy.Can(policy.Subject{UserID: userID}, action, resource) {
+		return nil, newError(ErrNotFound, constants.ErrAttachmentNotFound)
+	}
+	return attachment, nil
+}
+
+// deleteBlob removes stored content on a best effort basis, a leftover blob only wastes disk space
+func (s *AttachmentService) deleteBlob(storageKey string) {
+	if err := s.blobStore.Delete(storageKey); err != nil {
+		log.Printf("Error deleting blob %s: %v", storageKey, err)
+	}
+}
+
+func toAttachmentDto(attachment models.Attachment) dtos.AttachmentResponseDto {
+	return dtos.AttachmentResponseDto{
+		ID:          attachment.ID,
+		ContactID:   attachment.ContactID,
+		FileName:    attachment.FileName,
+		ContentType: attachment.ContentType,
+		SizeBytes:   attachment.SizeBytes,
+		CreatedAt:   attachment.CreatedAt,
+	}
+}
+
+func attachmentResource(attachmentID int) string {
+	return "attachment:" + strconv.Itoa(attachmentID)
+}
+
+// newAttachmentKey builds a unique, unguessable blob key keeping the original file extension
+func newAttachmentKey(userID int, fileName string) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("failed to generate attachment key: %w", err)
+	}
+	return fmt.Sprintf("attachments/%d/%s%s", userID, hex.EncodeToString(random), strings.ToLower(filepath.Ext(fileName))), nil
+}
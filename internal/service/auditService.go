@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/export"
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/danizion/contact-app/internal/policy"
+	"github.com/danizion/contact-app/internal/repository"
+)
+
+// auditCSVHeader lists the columns of the audit log CSV export
+var auditCSVHeader = []string{"id", "created_at", "user_id", "actor_id", "action", "entity_type", "entity_id", "details"}
+
+// AuditService gives access to the audit log of user accounts
+type AuditService struct {
+	repo *repository.Repository
+}
+
+// NewAuditService creates a new instance of AuditService
+func NewAuditService(db *sql.DB) *AuditService {
+	return &AuditService{
+		repo: repository.NewRepository(db),
+	}
+}
+
+// WithContext returns the service running its queries under ctx, the context of a request so they stop with it
+func (s *AuditService) WithContext(ctx context.Context) *AuditService {
+	bound := *s
+	bound.repo = s.repo.WithContext(ctx)
+	return &bound
+}
+
+// ExportAuditLog streams the audit history matching the request to out, admins export every account
+// unless they filter on one, other users only their own
+func (s *AuditService) ExportAuditLog(req dtos.AuditExportRequestDto, out io.Writer) error {
+	if req.Format != constants.ExportFormatCSV {
+		return newError(ErrInvalidInput, "%s: %s", constants.ErrUnsupportedExportFormat, req.Format)
+	}
+
+	filter := repository.AuditFilter{
+		UserID:  req.UserID,
+		ActorID: req.ActorID,
+		Action:  req.Action,
+		From:    req.From,
+		To:      req.To,
+	}
+	subject := policy.Subject{UserID: req.UserID, IsAdmin: req.IsAdmin}
+	if policy.Can(subject, policy.ActionRead, policy.Resource{Type: policy.ResourceAuditLog, OwnerID: req.AccountID}) {
+		filter.UserID = req.AccountID
+	}
+
+	writer := export.NewCSVWriter(out)
+	if err := writer.Write(auditCSVHeader); err != nil {
+		return err
+	}
+
+	err := s.repo.StreamAuditEntries(filter, func(entry models.AuditEntry) error {
+		return writer.Write([]string{
+			strconv.FormatInt(entry.ID, 10),
+			entry.CreatedAt.UTC().Format(time.RFC3339),
+			strconv.Itoa(entry.UserID),
+			strconv.Itoa(entry.ActorID),
+			entry.Action,
+			entry.EntityType,
+			strconv.Itoa(entry.EntityID),
+			entry.Details,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to export audit log: %w", err)
+	}
+	return writer.Flush()
+}
+
+// recordAudit appends an action performed by a user on their own account to the audit log,
+// failures are logged and never fail the audited operation
+func recordAudit(repo *repository.Repository, userID int, action, entityType string, entityID int, details map[string]interface{}) {
+	recordAuditBy(repo, userID, userID, action, entityType, entityID, details)
+}
+
+// recordAuditBy appends an action performed by actorID (an admin) on the account of userID to the audit log
+func recordAuditBy(repo *repository.Repository, actorID, userID int, action, entityType string, entityID int, details map[string]interface{}) {
+	detailsJSON := []byte("{}")
+	if details != nil {
+		var err error
+		detailsJSON, err = json.Marshal(details)
+		if err != nil {
+			log.Printf("Error encoding audit details for %s: %v", action, err)
+			return
+		}
+	}
+
+	err := repo.CreateAuditEntry(models.AuditEntry{
+		UserID:     userID,
+		ActorID:    actorID,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Details:    string(detailsJSON),
+	})
+	if err != nil {
+		log.Printf("Error recording audit entry %s for user %d: %v", action, userID, err)
+	}
+}
+
+// recordStageChange records the lifecycle stage history of a contact
+func recordStageChange(repo *repository.Repository, userID, contactID int, from, to string) {
+	recordContactChange(repo, userID, constants.AuditActionStageChanged, contactID,
+		map[string]interface{}{"from": from, "to": to})
+}
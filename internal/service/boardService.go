@@ -0,0 +1,95 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/repository"
+)
+
+// GetBoard returns the user's contacts grouped into one paginated column per allowed value of a picklist field,
+// contacts without a value are listed in a leading column with an empty value
+func (s *ContactService) GetBoard(req dtos.BoardRequestDto) (*dtos.BoardResponseDto, error) {
+	if !constants.IsPicklistField(req.Field) {
+		return nil, newError(ErrInvalidInput, constants.ErrUnknownPicklistField)
+	}
+
+	picklist, err := s.repo.GetPicklistValues(req.Field)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get board columns: %w", err)
+	}
+
+	columnValues := []string{""}
+	for _, value := range picklist {
+		columnValues = append(columnValues, value.Value)
+	}
+
+	board := &dtos.BoardResponseDto{
+		Field:   req.Field,
+		Columns: []dtos.BoardColumnDto{},
+	}
+	for _, value := range columnValues {
+		// When a single column is requested only that column is paged
+		if req.Column != "" && req.Column != value {
+			continue
+		}
+
+		repoContacts, total, err := s.repo.GetContactsByFieldValuePaginated(req.UserID, req.Field, value, req.Page, req.PageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get board column %s: %w", value, err)
+		}
+
+		column := dtos.BoardColumnDto{
+			Value:      value,
+			Items:      make([]dtos.GetContactsResponseDto, len(repoContacts)),
+			TotalCount: total,
+			Page:       req.Page,
+			PageSize:   req.PageSize,
+			TotalPages: (total + req.PageSize - 1) / req.PageSize,
+		}
+		for i, repoContact := range repoContacts {
+			column.Items[i] = toContactDto(repoContact)
+		}
+		applyLocalTime(column.Items, time.Now())
+		board.Columns = append(board.Columns, column)
+	}
+
+	return board, nil
+}
+
+// MoveContact changes the stage of a contact and places it at the requested position of the stage column
+func (s *ContactService) MoveContact(req dtos.MoveContactRequestDto) error {
+	if err := validatePicklistValue(s.repo, constants.PicklistFieldStage, req.Stage); err != nil {
+		return err
+	}
+
+	current, err := s.repo.GetContactByID(req.UserID, req.ContactID)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.MoveContactToStage(req.UserID, req.ContactID, req.Stage, req.Position)
+	if err != nil {
+		if errors.Is(err, repository.ErrContactNotFound) {
+			return newError(ErrNotFound, constants.ErrContactNotFound)
+		}
+		return err
+	}
+	if current != nil && current.Stage != req.Stage {
+		recordStageChange(s.repo, req.UserID, req.ContactID, current.Stage, req.Stage)
+	}
+
+	// Invalidate cache for this user if Redis is available
+	if s.redis != nil {
+		err := s.redis.InvalidateUserCache(strconv.Itoa(req.UserID))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,16 @@
+package service
+
+import (
+	"github.com/danizion/contact-app/internal/branding"
+	"github.com/danizion/contact-app/internal/dtos"
+)
+
+// Branding returns how the deployment presents itself on its public pages, the email footer is left to emails
+func Branding() dtos.BrandingDto {
+	config := branding.Load()
+	return dtos.BrandingDto{
+		InstanceName: config.InstanceName,
+		SupportEmail: config.SupportEmail,
+		LogoURL:      config.LogoURL,
+	}
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/ocr"
+)
+
+// CardImportService turns business card photos into draft contacts through a pluggable OCR provider
+type CardImportService struct {
+	ocr ocr.Provider
+}
+
+// NewCardImportService creates a new instance of CardImportService, provider may be nil when OCR is not configured
+func NewCardImportService(provider ocr.Provider) *CardImportService {
+	return &CardImportService{
+		ocr: provider,
+	}
+}
+
+// ImportCardImage recognizes the text on a card image and returns a prefilled draft contact, nothing is stored
+func (s *CardImportService) ImportCardImage(image io.Reader) (*dtos.CardImportResponseDto, error) {
+	if s.ocr == nil {
+		return nil, newError(ErrUnavailable, constants.ErrOCRNotConfigured)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(image, constants.MaxCardImageBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read card image: %w", err)
+	}
+	if len(content) > constants.MaxCardImageBytes {
+		return nil, newError(ErrTooLarge, constants.ErrCardImageTooLarge)
+	}
+
+	contentType := http.DetectContentType(content)
+	if contentType != "image/jpeg" && contentType != "image/png" {
+		return nil, newError(ErrUnsupportedType, constants.ErrCardImageInvalidType)
+	}
+
+	text, err := s.ocr.ExtractText(content, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recognize card image: %w", err)
+	}
+	if strings.TrimSpace(text) == "" {
+		return nil, newError(ErrUnprocessable, constants.ErrCardNotRecognized)
+	}
+
+	card := ocr.ParseCard(text)
+	return &dtos.CardImportResponseDto{
+		Draft: dtos.CreateContactRequestDto{
+			FirstName:   card.FirstName,
+			LastName:    card.LastName,
+			PhoneNumber: card.PhoneNumber,
+			Address:     card.Address,
+			Email:       card.Email,
+			Company:     card.Company,
+		},
+		RawText: text,
+	}, nil
+}
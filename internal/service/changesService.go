@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/events"
+	"github.com/danizion/contact-app/internal/repository"
+	"github.com/danizion/contact-app/internal/storage/redis"
+)
+
+// ChangesService serves the feed of changes to the contacts of a user, read from the audit log
+type ChangesService struct {
+	repo *repository.Repository
+}
+
+// NewChangesService creates a new instance of ChangesService
+func NewChangesService(db *sql.DB) *ChangesService {
+	return &ChangesService{
+		repo: repository.NewRepository(db),
+	}
+}
+
+// WithContext returns the service running its queries under ctx, the context of a request so they stop with it
+func (s *ChangesService) WithContext(ctx context.Context) *ChangesService {
+	bound := *s
+	bound.repo = s.repo.WithContext(ctx)
+	return &bound
+}
+
+// GetChanges returns the changes after the since cursor. When there are none it waits up to wait for one to
+// happen, or for ctx to be done, and returns an empty list with the same cursor on timeout
+func (s *ChangesService) GetChanges(ctx context.Context, userID int, since int64, wait time.Duration) (*dtos.ContactChangesResponseDto, error) {
+	ctx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+
+	// Watch before reading so a change committed in between still wakes us up
+	var changes <-chan events.Event
+	if wait > 0 {
+		var err error
+		changes, err = events.Watch(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to watch changes: %w", err)
+		}
+	}
+
+	for {
+		result, err := s.readChanges(userID, since)
+		if err != nil || len(result.Changes) > 0 || changes == nil {
+			return result, err
+		}
+
+		select {
+		case <-changes:
+		case <-ctx.Done():
+			return result, nil
+		}
+	}
+}
+
+func (s *ChangesService) readChanges(userID int, since int64) (*dtos.ContactChangesResponseDto, error) {
+	entries, err := s.repo.GetAuditEntriesAfter(userID, since, constants.ContactChangeActions, constants.MaxChangesPerResponse)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get changes: %w", err)
+	}
+
+	result := &dtos.ContactChangesResponseDto{
+		Changes: make([]dtos.ContactChangeDto, len(entries)),
+		Cursor:  since,
+		HasMore: len(entries) == constants.MaxChangesPerResponse,
+	}
+	for i, entry := range entries {
+		result.Changes[i] = dtos.ContactChangeDto{
+			ID:         entry.ID,
+			Action:     entry.Action,
+			EntityType: entry.EntityType,
+			EntityID:   entry.EntityID,
+			CreatedAt:  entry.CreatedAt,
+		}
+		result.Cursor = entry.ID
+	}
+	return result, nil
+}
+
+// recordContactChange records a change to a contact in the audit log and publishes it on the event bus
+func recordContactChange(repo *repository.Repository, userID int, action string, contactID int, details map[string]interface{}) {
+	recordAudit(repo, userID, action, constants.AuditEntityContact, contactID, details)
+	events.Publish(events.Event{Type: action, UserID: userID, ContactID: contactID, Details: details})
+}
+
+// CountContactChanges returns the event subscriber maintaining the Redis counters of the contact count badge
+func CountContactChanges(redisClient *redis.Redis) events.Handler {
+	return func(event events.Event) {
+		var delta int64
+		switch event.Type {
+		case events.ContactCreated, events.ContactRestored:
+			delta = 1
+		case events.ContactDeleted:
+			delta = -1
+		}
+		if err := redisClient.CountContactChange(event.UserID, delta); err != nil {
+			slog.Error("Failed to count contact change", "error", err, "userID", event.UserID, "event", event.Type)
+		}
+		// Events about many contacts do not tell how many, the total is counted again
+		if event.ContactID == 0 {
+			if err := redisClient.ForgetContactTotal(event.UserID); err != nil {
+				slog.Error("Failed to forget contact count", "error", err, "userID", event.UserID, "event", event.Type)
+			}
+		}
+	}
+}
+
+// InvalidateContactsCache returns the event subscriber dropping the cached contact pages of the user of an event
+func InvalidateContactsCache(redisClient *redis.Redis) events.Handler {
+	return func(event events.Event) {
+		if err := redisClient.InvalidateUserCache(strconv.Itoa(event.UserID)); err != nil {
+			slog.Error("Failed to invalidate contacts cache", "error", err, "userID", event.UserID, "event", event.Type)
+		}
+	}
+}
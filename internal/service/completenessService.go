@@ -0,0 +1,92 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/models"
+)
+
+// GetContactCompleteness scores how filled in the optional fields of a contact of a user are
+func (s *ContactService) GetContactCompleteness(userID, contactID int) (*dtos.ContactCompletenessDto, error) {
+	contact, err := s.repo.GetContactByID(userID, contactID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contact: %w", err)
+	}
+	if contact == nil {
+		return nil, newError(ErrNotFound, constants.ErrContactNotFound)
+	}
+
+	completeness := contactCompleteness(*contact)
+	return &completeness, nil
+}
+
+// GetIncompleteContacts returns a page of the contacts of a user missing any of the requested fields, least complete
+// first, to help filling in the address book
+func (s *ContactService) GetIncompleteContacts(req dtos.IncompleteContactsRequestDto) (*dtos.IncompleteContactsResponseDto, error) {
+	fields := constants.CompletenessFields
+	if req.Missing != "" {
+		fields = nil
+		for _, field := range strings.Split(req.Missing, ",") {
+			field = strings.TrimSpace(field)
+			if !constants.IsCompletenessField(field) {
+				return nil, newError(ErrInvalidInput, constants.ErrInvalidCompletenessField)
+			}
+			fields = append(fields, field)
+		}
+	}
+
+	repoContacts, total, err := s.repo.GetIncompleteContactsPaginated(req.UserID, fields, req.Page, req.PageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get incomplete contacts: %w", err)
+	}
+
+	contacts := make([]dtos.GetContactsResponseDto, len(repoContacts))
+	for i, repoContact := range repoContacts {
+		contacts[i] = toContactDto(repoContact)
+	}
+	applyLocalTime(contacts, time.Now())
+
+	result := &dtos.IncompleteContactsResponseDto{
+		Items:      make([]dtos.IncompleteContactDto, len(repoContacts)),
+		TotalCount: total,
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+		TotalPages: (total + req.PageSize - 1) / req.PageSize,
+	}
+	for i, repoContact := range repoContacts {
+		completeness := contactCompleteness(repoContact)
+		result.Items[i] = dtos.IncompleteContactDto{
+			GetContactsResponseDto: contacts[i],
+			Completeness:           completeness.Score,
+			Missing:                completeness.Missing,
+		}
+	}
+	return result, nil
+}
+
+// contactCompleteness lists the filled in and missing CompletenessFields of a contact, the score being the
+// percentage filled in
+func contactCompleteness(contact models.Contact) dtos.ContactCompletenessDto {
+	values := map[string]string{
+		"phone_number": contact.PhoneNumber,
+		"email":        contact.Email,
+		"address":      contact.Address,
+		"company":      contact.Company,
+		"job_title":    contact.JobTitle,
+		"timezone":     contact.Timezone,
+	}
+	completeness := dtos.ContactCompletenessDto{ContactID: contact.ID, Filled: []string{}, Missing: []string{}}
+	for _, field := range constants.CompletenessFields {
+		if strings.TrimSpace(values[field]) != "" {
+			completeness.Filled = append(completeness.Filled, field)
+		} else {
+			completeness.Missing = append(completeness.Missing, field)
+		}
+	}
+	completeness.Score = len(completeness.Filled) * 100 / len(constants.CompletenessFields)
+	return completeness
+}
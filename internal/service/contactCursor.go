@@ -0,0 +1,128 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/danizion/contact-app/internal/repository"
+)
+
+// contactSortColumns maps the sort_by values of the contact listings to the columns they order by, the contacts
+// are in creation order (by ID) without sort_by
+var contactSortColumns = map[string][]string{
+	"":                        nil,
+	constants.SortByName:      {"last_name", "first_name"},
+	constants.SortByCreatedAt: {"created_at"},
+	constants.SortByUpdatedAt: {"updated_at"},
+	// never interacted contacts sort by their creation, see repository.ContactLastInteraction
+	constants.SortByLastInteraction: {repository.ContactLastInteraction},
+}
+
+// contactCursor is the position of the last contact of a page. It is handed to clients as opaque base64 JSON and
+// only valid for the sort it was issued for
+type contactCursor struct {
+	SortBy string   `json:"s,omitempty"`
+	Desc   bool     `json:"d,omitempty"`
+	Values []string `json:"v,omitempty"`
+	ID     int      `json:"id,omitempty"`
+	// Page is the next page of a listing ranked by relevance, whose order cannot be sought by keyset
+	Page int `json:"p,omitempty"`
+}
+
+// contactSort validates the sort requested for a contact listing against the whitelisted orders
+func contactSort(sortBy, sortDir string) (repository.ContactSort, error) {
+	columns, ok := contactSortColumns[sortBy]
+	if !ok || (sortDir != "" && sortDir != constants.SortAsc && sortDir != constants.SortDesc) {
+		return repository.ContactSort{}, newError(ErrInvalidInput, constants.ErrInvalidSort)
+	}
+	return repository.ContactSort{Columns: columns, Desc: sortDir == constants.SortDesc}, nil
+}
+
+// contactSortValues returns the values of the sort columns of a contact, in the order of contactSortColumns
+func contactSortValues(sortBy string, contact models.Contact) []string {
+	switch sortBy {
+	case constants.SortByName:
+		return []string{contact.LastName, contact.FirstName}
+	case constants.SortByCreatedAt:
+		return []string{contact.CreatedAt.Format(time.RFC3339Nano)}
+	case constants.SortByUpdatedAt:
+		return []string{contact.UpdatedAt.Format(time.RFC3339Nano)}
+	case constants.SortByLastInteraction:
+		if contact.LastInteractedAt != nil {
+			return []string{contact.LastInteractedAt.Format(time.RFC3339Nano)}
+		}
+		return []string{contact.CreatedAt.Format(time.RFC3339Nano)}
+	}
+	return nil
+}
+
+// encodeContactCursor returns the cursor of the page following a contact
+func encodeContactCursor(sortBy string, desc bool, last models.Contact) (string, error) {
+	cursor, err := json.Marshal(contactCursor{SortBy: sortBy, Desc: desc, Values: contactSortValues(sortBy, last), ID: last.ID})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(cursor), nil
+}
+
+// decodeContactCursor returns the keyset values of a cursor, the values of its sort columns then its ID. Cursors
+// issued for another sort are rejected as the position they hold means nothing in this one
+func decodeContactCursor(encoded, sortBy string, desc bool) ([]interface{}, error) {
+	cursor, err := parseContactCursor(encoded)
+	if err != nil || cursor.ID < 1 {
+		return nil, newError(ErrInvalidInput, constants.ErrInvalidCursor)
+	}
+	if cursor.SortBy != sortBy || cursor.Desc != desc || len(cursor.Values) != len(contactSortColumns[sortBy]) {
+		return nil, newError(ErrInvalidInput, constants.ErrInvalidCursor)
+	}
+
+	after := make([]interface{}, 0, len(cursor.Values)+1)
+	for _, value := range cursor.Values {
+		if sortBy == constants.SortByCreatedAt || sortBy == constants.SortByUpdatedAt || sortBy == constants.SortByLastInteraction {
+			at, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				return nil, newError(ErrInvalidInput, constants.ErrInvalidCursor)
+			}
+			after = append(after, at)
+			continue
+		}
+		after = append(after, value)
+	}
+	return append(after, cursor.ID), nil
+}
+
+// encodeRelevanceCursor returns the cursor of the page next of a listing ranked by relevance
+func encodeRelevanceCursor(next int) (string, error) {
+	cursor, err := json.Marshal(contactCursor{Page: next})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(cursor), nil
+}
+
+// decodeRelevanceCursor returns the page number held by a cursor of a listing ranked by relevance
+func decodeRelevanceCursor(encoded string) (int, error) {
+	cursor, err := parseContactCursor(encoded)
+	if err != nil || cursor.Page < 1 || cursor.ID != 0 {
+		return 0, newError(ErrInvalidInput, constants.ErrInvalidCursor)
+	}
+	return cursor.Page, nil
+}
+
+// parseContactCursor decodes the base64 JSON of a cursor
+func parseContactCursor(encoded string) (contactCursor, error) {
+	var cursor contactCursor
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(encoded, "="))
+	if err != nil {
+		return cursor, newError(ErrInvalidInput, constants.ErrInvalidCursor)
+	}
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return cursor, newError(ErrInvalidInput, constants.ErrInvalidCursor)
+	}
+	return cursor, nil
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/danizion/contact-app/internal/branding"
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/export"
+	"github.com/danizion/contact-app/internal/importer"
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/danizion/contact-app/internal/repository"
+	"github.com/danizion/contact-app/internal/social"
+	"github.com/danizion/contact-app/internal/storage/redis"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// contactFileCSVHeader lists the columns of contacts exported as CSV, the importer reads them back. The profile URLs
+// of each social network follow the fields, in social_<network> columns
+var contactFileCSVHeader = append([]string{"first_name", "last_name", "phone_number", "email", "company", "job_title", "source",
+	"stage", "address", "street", "city", "region", "postal_code", "country_code", "timezone", "latitude", "longitude"},
+	socialCSVColumns()...)
+
+func socialCSVColumns() []string {
+	var columns []string
+	for _, network := range social.Networks() {
+		columns = append(columns, constants.SocialCSVColumnPrefix+network)
+	}
+	return columns
+}
+
+// ContactFileService imports contacts from CSV and vCard files and exports them to these formats
+type ContactFileService struct {
+	repo  *repository.Repository
+	redis *redis.Redis
+}
+
+// NewContactFileService creates a new instance of ContactFileService
+func NewContactFileService(db *sql.DB, redisClient *redis.Redis) *ContactFileService {
+	return &ContactFileService{
+		repo:  repository.NewRepository(db),
+		redis: redisClient,
+	}
+}
+
+// WithContext returns the service running its queries and cache calls under ctx, the context of a request so they stop with it
+func (s *ContactFileService) WithContext(ctx context.Context) *ContactFileService {
+	bound := *s
+	bound.repo = s.repo.WithContext(ctx)
+	bound.redis = s.redis.WithContext(ctx)
+	return &bound
+}
+
+// importedRow is a valid row of a contact file waiting to be inserted
+type importedRow struct {
+	line int
+	name string
+}
+
+// ImportContacts reads a contact file and adds its valid contacts in one transaction. Rows that cannot be read, fail
+// the rules of contact creation or name an existing contact are reported in the response and never stop the import
+func (s *ContactFileService) ImportContacts(userID int, format string, file io.Reader) (*dtos.ContactFileImportResponseDto, error) {
+	reader, err := importer.NewReader(format, file)
+	if err != nil {
+		return nil, newError(ErrInvalidInput, "%w", err)
+	}
+
+	result := &dtos.ContactFileImportResponseDto{Format: format, Errors: []dtos.ContactFileRowErrorDto{}}
+	var contacts []repository.ArchivedContact
+	var rows []importedRow
+	for {
+		row, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, newError(ErrInvalidInput, "%s: %w", constants.ErrInvalidContactFile, err)
+		}
+		result.Rows++
+		if result.Rows > constants.MaxContactFileRows {
+			return nil, newError(ErrTooLarge, constants.ErrContactFileTooManyRows)
+		}
+
+		name := strings.TrimSpace(row.Contact.FirstName + " " + row.Contact.LastName)
+		if row.Err == nil {
+			row.Err = validateContactFileRow(row.Contact)
+		}
+		var contact repository.ArchivedContact
+		var warnings []string
+		if row.Err == nil {
+			contact, warnings, row.Err = validateArchiveContact(s.repo, row.Contact)
+		}
+		if row.Err != nil {
+			result.Errors = append(result.Errors, dtos.ContactFileRowErrorDto{Line: row.Line, Name: name, Error: row.Err.Error()})
+			continue
+		}
+		for _, warning := range warnings {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("line %d: %s", row.Line, warning))
+		}
+		contacts = append(contacts, contact)
+		rows = append(rows, importedRow{line: row.Line, name: name})
+	}
+
+	if len(contacts) > 0 {
+		region, err := resolvePhoneRegion(defaultPhoneRegions(s.repo), userID, "")
+		if err != nil {
+			return nil, err
+		}
+		normalizeImportedPhones(contacts, region)
+		imported, err := s.repo.ImportContacts(userID, contacts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import contacts: %w", err)
+		}
+		result.Imported = len(imported.ContactIDs)
+		for _, i := range imported.SkippedIndexes {
+			result.Errors = append(result.Errors, dtos.ContactFileRowErrorDto{Line: rows[i].line, Name: rows[i].name,
+				Error: fmt.Sprintf("contact with name %s %s already exists", contacts[i].Contact.FirstName, contacts[i].Contact.LastName)})
+		}
+
+		for _, contactID := range imported.ContactIDs {
+			recordContactChange(s.repo, userID, constants.AuditActionContactCreated, contactID,
+				map[string]interface{}{"source": "file_import", "format": format})
+		}
+	}
+
+	sort.SliceStable(result.Errors, func(i, j int) bool { return result.Errors[i].Line < result.Errors[j].Line })
+	result.Failed = len(result.Errors)
+	return result, nil
+}
+
+// validateContactFileRow checks the binding rules of a contact read from a file, as a JSON body is checked
+func validateContactFileRow(contact dtos.ArchiveContactDto) error {
+	err := binding.Validator.ValidateStruct(contact)
+	if err == nil {
+		return nil
+	}
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return err
+	}
+	problems := make([]string, len(validationErrors))
+	for i, fieldError := range validationErrors {
+		problems[i] = fmt.Sprintf("%s failed the %s rule", archiveFieldPath(fieldError.Field()), fieldError.Tag())
+	}
+	return errors.New(strings.Join(problems, "; "))
+}
+
+// ExportContacts streams every contact of a user to out as CSV or vCard, in the columns and properties the import reads
+func (s *ContactFileService) ExportContacts(userID int, format string, out io.Writer) error {
+	if format != constants.ContactFileFormatCSV && format != constants.ContactFileFormatVCard {
+		return newError(ErrInvalidInput, constants.ErrUnsupportedContactFileFormat)
+	}
+	// The profiles are far fewer than the contacts, they are read at once and the contacts streamed
+	profiles, err := s.repo.GetSocialProfilesByUser(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get social profiles: %w", err)
+	}
+	contactProfiles := make(map[int][]models.SocialProfile)
+	for _, profile := range profiles {
+		contactProfiles[profile.ContactID] = append(contactProfiles[profile.ContactID], profile)
+	}
+
+	var write func(models.Contact) error
+	var flush func() error
+	switch format {
+	case constants.ContactFileFormatCSV:
+		writer := export.NewCSVWriter(out)
+		if err := writer.Write(contactFileCSVHeader); err != nil {
+			return err
+		}
+		write = func(contact models.Contact) error {
+			return writer.Write(contactFileCSVRow(contact, contactProfiles[contact.ID]))
+		}
+		flush = writer.Flush
+	case constants.ContactFileFormatVCard:
+		writer := export.NewVCardWriter(out, branding.Load().InstanceName)
+		write = func(contact models.Contact) error {
+			return writer.Write(contactVCard(contact, contactProfiles[contact.ID]))
+		}
+		flush = writer.Flush
+	}
+
+	if err := s.repo.StreamContactsByUser(userID, write); err != nil {
+		return fmt.Errorf("failed to export contacts: %w", err)
+	}
+	return flush()
+}
+
+func contactFileCSVRow(contact models.Contact, profiles []models.SocialProfile) []string {
+	urls := make(map[string]string, len(profiles))
+	for _, profile := range profiles {
+		urls[profile.Network] = profile.URL
+	}
+	row := []string{
+		contact.FirstName,
+		contact.LastName,
+		contact.PhoneNumber,
+		contact.Email,
+		contact.Company,
+		contact.JobTitle,
+		contact.Source,
+		contact.Stage,
+		contact.Address,
+		contact.Street,
+		contact.City,
+		contact.Region,
+		contact.PostalCode,
+		contact.CountryCode,
+		contact.Timezone,
+		formatCoordinate(contact.Latitude),
+		formatCoordinate(contact.Longitude),
+	}
+	for _, network := range social.Networks() {
+		row = append(row, urls[network])
+	}
+	return row
+}
+
+func contactVCard(contact models.Contact, profiles []models.SocialProfile) export.VCard {
+	card := export.VCard{
+		FirstName:   contact.FirstName,
+		LastName:    contact.LastName,
+		PhoneNumber: contact.PhoneNumber,
+		Email:       contact.Email,
+		Company:     contact.Company,
+		JobTitle:    contact.JobTitle,
+		Address:     contact.Address,
+		Street:      contact.Street,
+		City:        contact.City,
+		Region:      contact.Region,
+		PostalCode:  contact.PostalCode,
+		CountryCode: contact.CountryCode,
+		Timezone:    contact.Timezone,
+		Latitude:    contact.Latitude,
+		Longitude:   contact.Longitude,
+	}
+	for _, profile := range profiles {
+		card.SocialProfiles = append(card.SocialProfiles, export.SocialProfile{Network: profile.Network, URL: profile.URL})
+	}
+	return card
+}
+
+func formatCoordinate(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'f', -1, 64)
+}
@@ -1,97 +1,309 @@
 package service
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/danizion/contact-app/internal/address"
+	"github.com/danizion/contact-app/internal/constants"
 	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/metrics"
 	"github.com/danizion/contact-app/internal/models"
+	"github.com/danizion/contact-app/internal/phone"
 	"github.com/danizion/contact-app/internal/repository"
 	"github.com/danizion/contact-app/internal/storage/redis"
 )
 
+// clientIDPattern matches the UUIDs clients give to contacts
+var clientIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
 // ContactService handles business logic for contacts has a pointer for repository for db interaction and redis for cache interaction
 type ContactService struct {
 	repo  *repository.Repository
 	redis *redis.Redis
+	// phoneRegions give the region phone numbers entered without a country code are read in, the first having one
+	// is used
+	phoneRegions []phoneRegionSource
 }
 
 // NewContactService creates a new instance of ContactService
 func NewContactService(db *sql.DB, redisClient *redis.Redis) *ContactService {
 
+	repo := repository.NewRepository(db)
 	return &ContactService{
-		repo:  repository.NewRepository(db),
-		redis: redisClient,
+		repo:         repo,
+		redis:        redisClient,
+		phoneRegions: defaultPhoneRegions(repo),
 	}
 }
 
+// WithContext returns the service running its queries and cache calls under ctx, the context of a request so they
+// stop with it
+func (s *ContactService) WithContext(ctx context.Context) *ContactService {
+	bound := *s
+	bound.repo = s.repo.WithContext(ctx)
+	bound.redis = s.redis.WithContext(ctx)
+	return &bound
+}
+
 func (s *ContactService) CreateContact(contact dtos.CreateContactRequestDto) (int, error) {
+	repoContact, template, err := s.prepareNewContact(contact)
+	if err != nil {
+		return 0, err
+	}
+
 	// Check if contact with same name exists
 	exists, err := s.repo.IsContactExists(contact.UserID, contact.FirstName, contact.LastName)
 	if err != nil {
 		return 0, fmt.Errorf("failed to check existing contact: %w", err)
 	}
 	if exists {
-		return 0, fmt.Errorf("contact with name %s %s already exists. Please use update to change the number or use a different name",
+		return 0, newError(ErrDuplicateContact, "contact with name %s %s already exists. Please use update to change the number or use a different name",
 			contact.FirstName, contact.LastName)
 	}
 
+	if err := s.checkClientID(contact.UserID, repoContact.ClientID); err != nil {
+		return 0, err
+	}
+
+	contactID, err := s.repo.CreateContact(repoContact)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create contact: %w", err)
+	}
+	s.contactCreated(contact.UserID, contactID, template)
+	return contactID, nil
+}
+
+// prepareNewContact validates a new contact and maps it to the model to insert, following the contact template
+func (s *ContactService) prepareNewContact(contact dtos.CreateContactRequestDto) (models.Contact, *contactTemplate, error) {
+	// Validate picklist fields against their allowed values
+	if err := validatePicklistValue(s.repo, constants.PicklistFieldSource, contact.Source); err != nil {
+		return models.Contact{}, nil, err
+	}
+	if err := validatePicklistValue(s.repo, constants.PicklistFieldStage, contact.Stage); err != nil {
+		return models.Contact{}, nil, err
+	}
+	if err := validateTimezone(contact.Timezone); err != nil {
+		return models.Contact{}, nil, err
+	}
+
+	// A structured address replaces the free-text one with its country specific rendering
+	structuredAddress := address.Address{
+		Street:      contact.Street,
+		City:        contact.City,
+		Region:      contact.Region,
+		PostalCode:  contact.PostalCode,
+		CountryCode: contact.CountryCode,
+	}
+	if !structuredAddress.IsEmpty() {
+		if err := address.Validate(structuredAddress); err != nil {
+			return models.Contact{}, nil, newError(ErrInvalidInput, "%s: %w", constants.ErrInvalidAddress, err)
+		}
+		structuredAddress.CountryCode = strings.ToUpper(structuredAddress.CountryCode)
+		contact.CountryCode = structuredAddress.CountryCode
+		contact.Address = address.FormatSingleLine(structuredAddress)
+	}
+
+	if (contact.Latitude == nil) != (contact.Longitude == nil) {
+		return models.Contact{}, nil, newError(ErrInvalidInput, constants.ErrInvalidLocation)
+	}
+	phoneNormalized, err := s.normalizeContactPhone(contact.UserID, contact.PhoneNumber, contact.CountryCode)
+	if err != nil {
+		return models.Contact{}, nil, err
+	}
+
+	// Custom fields follow the contact template of the deployment, which also tags new contacts
+	template, err := loadContactTemplate(s.repo)
+	if err != nil {
+		return models.Contact{}, nil, err
+	}
+	customFields, err := template.newContactFields(contact.CustomFields)
+	if err != nil {
+		return models.Contact{}, nil, err
+	}
+
+	var clientID *string
+	if contact.ClientID != "" {
+		normalized := strings.ToLower(contact.ClientID)
+		clientID = &normalized
+	}
+
 	// Map DTO to model
-	repoContact := models.Contact{
+	return models.Contact{
 		UserID:      contact.UserID,
 		FirstName:   contact.FirstName,
 		LastName:    contact.LastName,
 		PhoneNumber: contact.PhoneNumber,
 		Address:     contact.Address,
+		Email:       contact.Email,
+		Company:     contact.Company,
+		JobTitle:    contact.JobTitle,
+		Timezone:    contact.Timezone,
+		Street:      contact.Street,
+		City:        contact.City,
+		Region:      contact.Region,
+		PostalCode:  contact.PostalCode,
+		CountryCode: contact.CountryCode,
+		Latitude:    contact.Latitude,
+		Longitude:   contact.Longitude,
+		Source:      contact.Source,
+		Stage:       contact.Stage,
+
+		PhoneNormalized: phoneNormalized,
+		CustomFields:    customFields,
+		TemplateVersion: template.version,
+		ClientID:        clientID,
+	}, template, nil
+}
+
+// normalizeContactPhone returns the E.164 form of the phone number of a contact of a user, numbers without a country
+// code being read in the region resolved for the contact. It fails when the number cannot be normalized, the form is
+// empty for numbers kept as entered
+func (s *ContactService) normalizeContactPhone(userID int, number, countryCode string) (string, error) {
+	region, err := resolvePhoneRegion(s.phoneRegions, userID, countryCode)
+	if err != nil {
+		return "", err
+	}
+	if err := validatePhoneNumber(number, region); err != nil {
+		return "", err
 	}
+	return normalizedPhone(number, region), nil
+}
 
-	contactID, err := s.repo.CreateContact(repoContact)
+// checkClientID fails when the client_id of a new contact is already the one of another contact of the user
+func (s *ContactService) checkClientID(userID int, clientID *string) error {
+	if clientID == nil {
+		return nil
+	}
+	existing, err := s.repo.GetContactIDByClientID(userID, *clientID)
 	if err != nil {
-		return 0, fmt.Errorf("failed to create contact: %w", err)
+		return fmt.Errorf("failed to check existing contact: %w", err)
 	}
+	if existing != 0 {
+		return newError(ErrDuplicateContact, constants.ErrContactClientIDExists)
+	}
+	return nil
+}
 
-	// Invalidate cache for this user if Redis is available
-	if s.redis != nil {
-		// Convert userID to string for cache key
-		userIDStr := strconv.Itoa(contact.UserID)
+// ResolveContactID returns the ID of the contact of a user referred to by ref, its ID or its client_id
+func (s *ContactService) ResolveContactID(userID int, ref string) (int, error) {
+	if contactID, err := strconv.Atoi(ref); err == nil {
+		return contactID, nil
+	}
+	if !clientIDPattern.MatchString(ref) {
+		return 0, newError(ErrInvalidInput, constants.ErrInvalidContactRef)
+	}
+	contactID, err := s.repo.GetContactIDByClientID(userID, strings.ToLower(ref))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get contact: %w", err)
+	}
+	if contactID == 0 {
+		return 0, newError(ErrNotFound, constants.ErrContactNotFound)
+	}
+	return contactID, nil
+}
 
-		err := s.redis.InvalidateUserCache(userIDStr)
+// contactCreated attaches the default tags of the template to a new contact and records its creation
+func (s *ContactService) contactCreated(userID, contactID int, template *contactTemplate) {
+	for _, name := range template.defaultTags {
+		tagID, err := s.repo.GetOrCreateTag(userID, name)
+		if err == nil {
+			_, err = s.repo.AddContactsToTag(tagID, []int{contactID})
+		}
 		if err != nil {
-			return 0, err
+			slog.Error("Failed to apply template tag", "error", err, "contactID", contactID, "tag", name)
 		}
 	}
-
-	return contactID, nil
+	recordContactChange(s.repo, userID, constants.AuditActionContactCreated, contactID, nil)
 }
 
 // GetContacts retrieves contacts for a user with pagination
 func (s *ContactService) GetContacts(req dtos.GetContactRequestDto) (*dtos.PaginationResult, error) {
-	// Validate pagination parameters
+	// Tags are stored normalized, see normalizeTagName
+	req.Tag = strings.ToLower(strings.TrimSpace(req.Tag))
 
-	if s.redis != nil {
-		// Create filter map
-		filters := map[string]string{
-			"first_name":   req.FirstName,
-			"last_name":    req.LastName,
-			"phone_number": req.PhoneNumber,
-			"address":      req.Address,
+	order, err := contactSort(req.SortBy, req.SortDir)
+	if err != nil {
+		return nil, err
+	}
+	inactiveFor, err := parseInactiveFor(req.InactiveFor)
+	if err != nil {
+		return nil, err
+	}
+	// Searches without an explicit sort return the best matches first
+	order.Relevance = req.Query != "" && req.SortBy == ""
+	page := repository.ContactPage{Page: req.Page, Size: req.PageSize, Sort: order}
+	if req.Cursor != "" && order.Relevance {
+		if page.Page, err = decodeRelevanceCursor(req.Cursor); err != nil {
+			return nil, err
+		}
+	} else if req.Cursor != "" {
+		if page.After, err = decodeContactCursor(req.Cursor, req.SortBy, order.Desc); err != nil {
+			return nil, err
 		}
+	}
 
-		// Convert userID to string for cache key
-		userIDStr := strconv.Itoa(req.UserID)
+	// Create filter map, the sort and the cursor are part of the cache key as well
+	filters := map[string]string{
+		"first_name":   req.FirstName,
+		"last_name":    req.LastName,
+		"phone_number": req.PhoneNumber,
+		"address":      req.Address,
+		"social":       req.Social,
+		"group":        formatOptionalID(req.GroupID),
+		"tag":          req.Tag,
+		"q":            req.Query,
+		"inactive_for": req.InactiveFor,
+		"sort_by":      req.SortBy,
+		"sort_dir":     req.SortDir,
+		"cursor":       req.Cursor,
+	}
+	// Convert userID to string for cache key
+	userIDStr := strconv.Itoa(req.UserID)
 
+	if s.redis != nil {
 		// Try to get pagination result from cache
 		var cachedResult dtos.PaginationResult
 		found, err := s.redis.GetCachedPaginationResult(userIDStr, filters, req.Page, req.PageSize, &cachedResult)
+		switch {
+		case err != nil:
+			metrics.CacheLookups.Inc("error")
+		case found:
+			metrics.CacheLookups.Inc("hit")
+		default:
+			metrics.CacheLookups.Inc("miss")
+		}
 		if err == nil && found {
 			// Cache hit - return the pagination result directly
+			applyLocalTime(cachedResult.Items, time.Now())
 			return &cachedResult, nil
 		}
 	}
 
 	// Cache miss or Redis not available, get from database
-	repoContacts, total, err := s.repo.GetContactsByUserPaginated(req.UserID, req.Page, req.PageSize, req.FirstName, req.LastName, req.PhoneNumber, req.Address)
+	filter := repository.ContactFilter{
+		FirstName:    req.FirstName,
+		LastName:     req.LastName,
+		PhoneNumber:  req.PhoneNumber,
+		Address:      req.Address,
+		SocialHandle: req.Social,
+		GroupID:      req.GroupID,
+		Tag:          req.Tag,
+		Query:        req.Query,
+	}
+	if inactiveFor > 0 {
+		filter.InactiveSince = time.Now().Add(-inactiveFor)
+	}
+	repoContacts, total, more, err := s.repo.GetContactsByUserPaginated(req.UserID, page, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get paginated contacts: %w", err)
 	}
@@ -99,14 +311,11 @@ func (s *ContactService) GetContacts(req dtos.GetContactRequestDto) (*dtos.Pagin
 	// Map repository models to DTOs
 	contacts := make([]dtos.GetContactsResponseDto, len(repoContacts))
 	for i, repoContact := range repoContacts {
-		contacts[i] = dtos.GetContactsResponseDto{
-			ID:          repoContact.ID,
-			UserID:      repoContact.UserID,
-			FirstName:   repoContact.FirstName,
-			LastName:    repoContact.LastName,
-			PhoneNumber: repoContact.PhoneNumber,
-			Address:     repoContact.Address,
-		}
+		contacts[i] = toContactDto(repoContact)
+	}
+
+	if err := s.attachSocialProfiles(contacts); err != nil {
+		return nil, err
 	}
 
 	// Calculate total pages
@@ -123,19 +332,24 @@ func (s *ContactService) GetContacts(req dtos.GetContactRequestDto) (*dtos.Pagin
 		PageSize:   req.PageSize,
 		TotalPages: totalPages,
 	}
+	if req.Cursor != "" {
+		// The page number of a page reached by cursor is unknown, and so are the totals of the pages seeking past the
+		// previous one, whose count is skipped
+		result.Page = 0
+	}
+	if more && order.Relevance {
+		if result.NextCursor, err = encodeRelevanceCursor(page.Page + 1); err != nil {
+			return nil, err
+		}
+	} else if more {
+		result.NextCursor, err = encodeContactCursor(req.SortBy, order.Desc, repoContacts[len(repoContacts)-1])
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	// Cache the result if Redis is available
 	if s.redis != nil {
-		// Create filter map
-		filters := map[string]string{
-			"first_name":   req.FirstName,
-			"last_name":    req.LastName,
-			"phone_number": req.PhoneNumber,
-		}
-
-		// Convert userID to string for cache key
-		userIDStr := strconv.Itoa(req.UserID)
-
 		// Cache the pagination result
 		err := s.redis.CachePaginationResult(userIDStr, filters, req.Page, req.PageSize, result)
 		if err != nil {
@@ -143,11 +357,110 @@ func (s *ContactService) GetContacts(req dtos.GetContactRequestDto) (*dtos.Pagin
 		}
 	}
 
+	applyLocalTime(result.Items, time.Now())
 	return result, nil
 }
 
+// GetContact retrieves a single contact of a user with its social profiles
+func (s *ContactService) GetContact(userID, contactID int) (*dtos.GetContactsResponseDto, error) {
+	contact, err := s.repo.GetContactByID(userID, contactID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contact: %w", err)
+	}
+	if contact == nil {
+		return nil, newError(ErrNotFound, constants.ErrContactNotFound)
+	}
+	s.recordContactView(contact)
+
+	contacts := []dtos.GetContactsResponseDto{toContactDto(*contact)}
+	if err := s.attachSocialProfiles(contacts); err != nil {
+		return nil, err
+	}
+	applyLocalTime(contacts, time.Now())
+	return &contacts[0], nil
+}
+
+// GetContactMemberships retrieves the groups and tags of contacts already loaded for a user, indexed by contact ID
+func (s *ContactService) GetContactMemberships(contactIDs []int) (map[int]dtos.ContactMembershipsDto, error) {
+	groups, err := s.repo.GetGroupsByContacts(contactIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contact groups: %w", err)
+	}
+	tags, err := s.repo.GetTagsByContacts(contactIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contact tags: %w", err)
+	}
+
+	memberships := make(map[int]dtos.ContactMembershipsDto, len(contactIDs))
+	for _, group := range groups {
+		m := memberships[group.ContactID]
+		m.Groups = append(m.Groups, dtos.MembershipDto{ID: group.ID, Name: group.Name})
+		memberships[group.ContactID] = m
+	}
+	for _, tag := range tags {
+		m := memberships[tag.ContactID]
+		m.Tags = append(m.Tags, dtos.MembershipDto{ID: tag.ID, Name: tag.Name})
+		memberships[tag.ContactID] = m
+	}
+	return memberships, nil
+}
+
 // UpdateContact updates an existing contact, only update none empty fields
 func (s *ContactService) UpdateContact(updateContactRequestDto dtos.UpdateContactRequestDto) error {
+	// The stored contact is needed to merge a partial structured address and custom fields, and to record stage changes
+	var current *models.Contact
+	if needsStoredContact(updateContactRequestDto) {
+		var err error
+		current, err = s.repo.GetContactByID(updateContactRequestDto.UserID, updateContactRequestDto.ID)
+		if err != nil {
+			return err
+		}
+		if current == nil {
+			return newError(ErrNotFound, constants.ErrContactNotFound)
+		}
+	}
+
+	repoContact, updateFields, err := s.prepareContactUpdate(current, updateContactRequestDto)
+	if err != nil {
+		return err
+	}
+	err = s.repo.UpdateContact(repoContact, updateFields)
+	if err != nil {
+		if errors.Is(err, repository.ErrContactNotFound) {
+			return newError(ErrNotFound, constants.ErrContactNotFound)
+		}
+		return err
+	}
+	s.contactUpdated(current, updateContactRequestDto, updateFields)
+	return nil
+}
+
+// needsStoredContact reports whether an update is merged with the stored contact by prepareContactUpdate
+func needsStoredContact(req dtos.UpdateContactRequestDto) bool {
+	return req.PhoneNumber != "" || req.Street != "" || req.City != "" || req.Region != "" || req.PostalCode != "" ||
+		req.CountryCode != "" || req.Stage != "" || len(req.CustomFields) > 0
+}
+
+// prepareContactUpdate validates an update and maps it to the model and the fields to update. current is the stored
+// contact, required when needsStoredContact
+func (s *ContactService) prepareContactUpdate(current *models.Contact, updateContactRequestDto dtos.UpdateContactRequestDto) (models.Contact, map[string]bool, error) {
+	// Validate picklist fields against their allowed values
+	if err := validatePicklistValue(s.repo, constants.PicklistFieldSource, updateContactRequestDto.Source); err != nil {
+		return models.Contact{}, nil, err
+	}
+	if err := validatePicklistValue(s.repo, constants.PicklistFieldStage, updateContactRequestDto.Stage); err != nil {
+		return models.Contact{}, nil, err
+	}
+	if err := validateTimezone(updateContactRequestDto.Timezone); err != nil {
+		return models.Contact{}, nil, err
+	}
+	if (updateContactRequestDto.Latitude == nil) != (updateContactRequestDto.Longitude == nil) {
+		return models.Contact{}, nil, newError(ErrInvalidInput, constants.ErrInvalidLocation)
+	}
+
+	hasStructuredAddress := updateContactRequestDto.Street != "" || updateContactRequestDto.City != "" ||
+		updateContactRequestDto.Region != "" || updateContactRequestDto.PostalCode != "" || updateContactRequestDto.CountryCode != ""
+
 	// Map DTO to model
 	repoContact := models.Contact{
 		ID:          updateContactRequestDto.ID,
@@ -156,6 +469,14 @@ func (s *ContactService) UpdateContact(updateContactRequestDto dtos.UpdateContac
 		LastName:    updateContactRequestDto.LastName,
 		PhoneNumber: updateContactRequestDto.PhoneNumber,
 		Address:     updateContactRequestDto.Address,
+		Email:       updateContactRequestDto.Email,
+		Company:     updateContactRequestDto.Company,
+		JobTitle:    updateContactRequestDto.JobTitle,
+		Timezone:    updateContactRequestDto.Timezone,
+		Latitude:    updateContactRequestDto.Latitude,
+		Longitude:   updateContactRequestDto.Longitude,
+		Source:      updateContactRequestDto.Source,
+		Stage:       updateContactRequestDto.Stage,
 	}
 
 	// Only update fields that are not empty
@@ -170,6 +491,12 @@ func (s *ContactService) UpdateContact(updateContactRequestDto dtos.UpdateContac
 	}
 
 	if updateContactRequestDto.PhoneNumber != "" {
+		countryCode := strings.ToUpper(firstNonEmpty(updateContactRequestDto.CountryCode, current.CountryCode))
+		phoneNormalized, err := s.normalizeContactPhone(updateContactRequestDto.UserID, updateContactRequestDto.PhoneNumber, countryCode)
+		if err != nil {
+			return models.Contact{}, nil, err
+		}
+		repoContact.PhoneNormalized = phoneNormalized
 		updateFields["phone_number"] = true
 	}
 
@@ -177,45 +504,330 @@ func (s *ContactService) UpdateContact(updateContactRequestDto dtos.UpdateContac
 		updateFields["address"] = true
 	}
 
-	err := s.repo.UpdateContact(repoContact, updateFields)
-	if err != nil {
-		return err
+	if updateContactRequestDto.Email != "" {
+		updateFields["email"] = true
 	}
 
-	// Invalidate cache for this user if Redis is available
-	if s.redis != nil {
-		// Convert userID to string for cache key
-		userIDStr := strconv.Itoa(updateContactRequestDto.UserID)
+	if updateContactRequestDto.Company != "" {
+		updateFields["company"] = true
+	}
 
-		// Invalidate cache for the given user
-		err := s.redis.InvalidateUserCache(userIDStr)
+	if updateContactRequestDto.JobTitle != "" {
+		updateFields["job_title"] = true
+	}
+
+	if updateContactRequestDto.Timezone != "" {
+		updateFields["timezone"] = true
+	}
+
+	// Structured address fields are merged with the stored ones and the free-text address re-rendered from them
+	if hasStructuredAddress {
+		merged := address.Address{
+			Street:      firstNonEmpty(updateContactRequestDto.Street, current.Street),
+			City:        firstNonEmpty(updateContactRequestDto.City, current.City),
+			Region:      firstNonEmpty(updateContactRequestDto.Region, current.Region),
+			PostalCode:  firstNonEmpty(updateContactRequestDto.PostalCode, current.PostalCode),
+			CountryCode: strings.ToUpper(firstNonEmpty(updateContactRequestDto.CountryCode, current.CountryCode)),
+		}
+		if err := address.Validate(merged); err != nil {
+			return models.Contact{}, nil, newError(ErrInvalidInput, "%s: %w", constants.ErrInvalidAddress, err)
+		}
+
+		repoContact.Street = merged.Street
+		repoContact.City = merged.City
+		repoContact.Region = merged.Region
+		repoContact.PostalCode = merged.PostalCode
+		repoContact.CountryCode = merged.CountryCode
+		repoContact.Address = address.FormatSingleLine(merged)
+		updateFields["structured_address"] = true
+		updateFields["address"] = true
+	}
+
+	// Coordinates given explicitly are stored as is, otherwise an address change clears them until it is geocoded again
+	if updateContactRequestDto.Latitude != nil || updateFields["address"] {
+		updateFields["location"] = true
+	}
+
+	if updateContactRequestDto.Source != "" {
+		updateFields["source"] = true
+	}
+
+	if updateContactRequestDto.Stage != "" {
+		updateFields["stage"] = true
+	}
+
+	if len(updateContactRequestDto.CustomFields) > 0 {
+		template, err := loadContactTemplate(s.repo)
 		if err != nil {
-			return err
+			return models.Contact{}, nil, err
+		}
+		repoContact.CustomFields, err = template.updatedContactFields(current.CustomFields, updateContactRequestDto.CustomFields)
+		if err != nil {
+			return models.Contact{}, nil, err
 		}
+		updateFields["custom_fields"] = true
 	}
+	return repoContact, updateFields, nil
+}
 
-	return nil
+// contactUpdated records the update of a contact and the change of its stage, current is the contact before it
+func (s *ContactService) contactUpdated(current *models.Contact, updateContactRequestDto dtos.UpdateContactRequestDto, updateFields map[string]bool) {
+	changedFields := make([]string, 0, len(updateFields))
+	for field := range updateFields {
+		changedFields = append(changedFields, field)
+	}
+	sort.Strings(changedFields)
+	recordContactChange(s.repo, updateContactRequestDto.UserID, constants.AuditActionContactUpdated,
+		updateContactRequestDto.ID, map[string]interface{}{"fields": changedFields})
+	if current != nil && updateContactRequestDto.Stage != "" && current.Stage != updateContactRequestDto.Stage {
+		recordStageChange(s.repo, updateContactRequestDto.UserID, updateContactRequestDto.ID, current.Stage, updateContactRequestDto.Stage)
+	}
 }
 
-// DeleteContact deletes a contact by ID and user ID
+// DeleteContact moves a contact of a user to the trash, TrashService deletes contacts for good
 func (s *ContactService) DeleteContact(userID, contactID int) error {
-	// Invalidate cache for this user if Redis is available
-	if s.redis != nil {
-		// Convert userID to string for cache key
-		userIDStr := strconv.Itoa(userID)
+	if err := s.repo.DeleteContact(userID, contactID); err != nil {
+		if errors.Is(err, repository.ErrContactNotFound) {
+			return newError(ErrNotFound, constants.ErrContactNotFound)
+		}
+		return fmt.Errorf("failed to delete contact: %w", err)
+	}
+	recordContactChange(s.repo, userID, constants.AuditActionContactDeleted, contactID, nil)
+	return nil
+}
+
+// ListTrash returns the contacts of a user in the trash, most recently deleted first
+func (s *ContactService) ListTrash(userID int) ([]dtos.GetContactsResponseDto, error) {
+	contacts, err := s.repo.GetTrashedContacts(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trash: %w", err)
+	}
+	result := make([]dtos.GetContactsResponseDto, 0, len(contacts))
+	for _, contact := range contacts {
+		result = append(result, toContactDto(contact))
+	}
+	return result, nil
+}
+
+// GetTrashedContact returns a contact of a user in the trash with its memberships and deletion details
+func (s *ContactService) GetTrashedContact(userID, contactID int) (*dtos.TrashedContactDto, error) {
+	contact, err := s.repo.GetTrashedContact(userID, contactID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trashed contact: %w", err)
+	}
+	if contact == nil {
+		return nil, newError(ErrNotFound, constants.ErrContactNotInTrash)
+	}
+
+	contacts := []dtos.GetContactsResponseDto{toContactDto(*contact)}
+	if err := s.attachSocialProfiles(contacts); err != nil {
+		return nil, err
+	}
+	memberships, err := s.GetContactMemberships([]int{contactID})
+	if err != nil {
+		return nil, err
+	}
+	deletion, err := s.repo.GetLastContactAudit(userID, contactID, repository.ContactAuditDeleted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contact deletion: %w", err)
+	}
 
-		// Invalidate cache for the given user
-		err := s.redis.InvalidateUserCache(userIDStr)
+	trashed := &dtos.TrashedContactDto{
+		GetContactsResponseDto: contacts[0],
+		Groups:                 memberships[contactID].Groups,
+		Tags:                   memberships[contactID].Tags,
+		PurgeAt:                contact.DeletedAt.Add(constants.TrashRetention),
+	}
+	if deletion != nil {
+		trashed.DeletedBy = &deletion.UserID
+	}
+	if trashed.Groups == nil {
+		trashed.Groups = []dtos.MembershipDto{}
+	}
+	if trashed.Tags == nil {
+		trashed.Tags = []dtos.MembershipDto{}
+	}
+	return trashed, nil
+}
+
+// RestoreContact takes a contact of a user out of the trash, unless an active contact took its name meanwhile
+func (s *ContactService) RestoreContact(userID, contactID int) (*dtos.GetContactsResponseDto, error) {
+	contact, err := s.repo.GetTrashedContact(userID, contactID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trashed contact: %w", err)
+	}
+	if contact == nil {
+		return nil, newError(ErrNotFound, constants.ErrContactNotInTrash)
+	}
+	exists, err := s.repo.IsContactExists(userID, contact.FirstName, contact.LastName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing contact: %w", err)
+	}
+	if exists {
+		return nil, newError(ErrDuplicateContact, constants.ErrContactNameTaken)
+	}
+
+	if err := s.repo.RestoreContact(userID, contactID); err != nil {
+		if errors.Is(err, repository.ErrContactNotFound) {
+			return nil, newError(ErrNotFound, constants.ErrContactNotInTrash)
+		}
+		return nil, fmt.Errorf("failed to restore contact: %w", err)
+	}
+	recordContactChange(s.repo, userID, constants.AuditActionContactRestored, contactID, nil)
+	return s.GetContact(userID, contactID)
+}
+
+// GetContactHistory returns the changes of a contact of a user, active or in the trash, oldest first
+func (s *ContactService) GetContactHistory(userID, contactID int) (*dtos.ContactHistoryResponseDto, error) {
+	contact, err := s.repo.GetContactByID(userID, contactID)
+	if err == nil && contact == nil {
+		contact, err = s.repo.GetTrashedContact(userID, contactID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contact: %w", err)
+	}
+	if contact == nil {
+		return nil, newError(ErrNotFound, constants.ErrContactNotFound)
+	}
+
+	entries, err := s.repo.GetContactHistory(userID, contactID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contact history: %w", err)
+	}
+	history := &dtos.ContactHistoryResponseDto{ContactID: contactID, Items: make([]dtos.ContactHistoryEntryDto, 0, len(entries))}
+	for _, entry := range entries {
+		item := dtos.ContactHistoryEntryDto{ID: entry.ID, Action: entry.Action, CreatedAt: entry.CreatedAt}
+		if err := json.Unmarshal(entry.Changes, &item.Changes); err != nil {
+			return nil, fmt.Errorf("failed to decode contact history: %w", err)
+		}
+		history.Items = append(history.Items, item)
+	}
+	return history, nil
+}
+
+// CountContacts returns the number of contacts of a user and of the changes after since from the Redis counters,
+// only reading the database when the counters do not know the number of contacts
+func (s *ContactService) CountContacts(userID int, since int64) (*dtos.ContactCountResponseDto, error) {
+	if s.redis == nil {
+		total, err := s.repo.CountUserContacts(userID)
 		if err != nil {
-			return err
+			return nil, fmt.Errorf("failed to count contacts: %w", err)
 		}
+		return &dtos.ContactCountResponseDto{Total: int64(total)}, nil
+	}
+
+	total, ok, changes, err := s.redis.GetContactCounts(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contact counts: %w", err)
+	}
+	if !ok {
+		counted, err := s.repo.CountUserContacts(userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count contacts: %w", err)
+		}
+		total = int64(counted)
+		if err := s.redis.SetContactTotal(userID, total, constants.ContactCountTTL); err != nil {
+			slog.Error("Failed to cache contact count", "error", err, "userID", userID)
+		}
+	}
 
+	// A cursor ahead of the counter was issued before the counters were lost, every counted change is unread
+	unread := changes - since
+	if since > changes {
+		unread = changes
 	}
+	return &dtos.ContactCountResponseDto{Total: total, UnreadChanges: unread, Cursor: changes}, nil
+}
 
-	err := s.repo.DeleteContact(contactID, userID)
+// GetContactStats aggregates a user's contacts by stage and source
+func (s *ContactService) GetContactStats(userID int) (*dtos.ContactStatsResponseDto, error) {
+	byStage, err := s.repo.CountContactsByField(userID, constants.PicklistFieldStage)
 	if err != nil {
-		return fmt.Errorf("failed to delete contact: %w", err)
+		return nil, fmt.Errorf("failed to aggregate contacts by stage: %w", err)
 	}
 
-	return nil
+	bySource, err := s.repo.CountContactsByField(userID, constants.PicklistFieldSource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate contacts by source: %w", err)
+	}
+
+	total := 0
+	for _, count := range byStage {
+		total += count
+	}
+
+	return &dtos.ContactStatsResponseDto{
+		TotalCount: total,
+		ByStage:    byStage,
+		BySource:   bySource,
+	}, nil
+}
+
+// toContactDto maps a repository contact to its API representation
+func clientIDOf(contact models.Contact) string {
+	if contact.ClientID == nil {
+		return ""
+	}
+	return *contact.ClientID
+}
+
+func toContactDto(contact models.Contact) dtos.GetContactsResponseDto {
+	structuredAddress := address.Address{
+		Street:      contact.Street,
+		City:        contact.City,
+		Region:      contact.Region,
+		PostalCode:  contact.PostalCode,
+		CountryCode: contact.CountryCode,
+	}
+	var formattedAddress []string
+	if !structuredAddress.IsEmpty() {
+		formattedAddress = address.Format(structuredAddress)
+	}
+	e164, _ := phone.Normalize(contact.PhoneNumber, contact.CountryCode)
+
+	return dtos.GetContactsResponseDto{
+		ID:          contact.ID,
+		UserID:      contact.UserID,
+		FirstName:   contact.FirstName,
+		LastName:    contact.LastName,
+		PhoneNumber: contact.PhoneNumber,
+		Address:     contact.Address,
+		Email:       contact.Email,
+		Company:     contact.Company,
+		JobTitle:    contact.JobTitle,
+		Timezone:    contact.Timezone,
+		Street:      contact.Street,
+		City:        contact.City,
+		Region:      contact.Region,
+		PostalCode:  contact.PostalCode,
+		CountryCode: contact.CountryCode,
+		Source:      contact.Source,
+		Stage:       contact.Stage,
+		Latitude:    contact.Latitude,
+		Longitude:   contact.Longitude,
+		DeletedAt:   contact.DeletedAt,
+
+		FormattedAddress: formattedAddress,
+		PhoneNumberE164:  e164,
+		LastInteractedAt: contact.LastInteractedAt,
+		CustomFields:     contact.CustomFields,
+		ClientID:         clientIDOf(contact),
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// formatOptionalID formats an ID used as a cache filter, empty when unset so the filter is left out of the key
+func formatOptionalID(id int) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.Itoa(id)
 }
@@ -0,0 +1,346 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/customfield"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/danizion/contact-app/internal/repository"
+	"github.com/danizion/contact-app/internal/storage/redis"
+)
+
+// contactTemplate is the template every contact of the deployment follows: its custom fields and the tags new
+// contacts get
+type contactTemplate struct {
+	version     int
+	fields      []customfield.Definition
+	defaultTags []string
+}
+
+// ContactTemplateService handles the admin-managed contact template. Every change of its fields bumps its version,
+// contacts following a previous version are migrated by MigrateContacts
+type ContactTemplateService struct {
+	repo  *repository.Repository
+	redis *redis.Redis
+}
+
+// NewContactTemplateService creates a new instance of ContactTemplateService
+func NewContactTemplateService(db *sql.DB, redisClient *redis.Redis) *ContactTemplateService {
+	return &ContactTemplateService{
+		repo:  repository.NewRepository(db),
+		redis: redisClient,
+	}
+}
+
+// WithContext returns the service running its queries and cache calls under ctx, the context of a request so they stop with it
+func (s *ContactTemplateService) WithContext(ctx context.Context) *ContactTemplateService {
+	bound := *s
+	bound.repo = s.repo.WithContext(ctx)
+	bound.redis = s.redis.WithContext(ctx)
+	return &bound
+}
+
+// GetTemplate returns the current contact template
+func (s *ContactTemplateService) GetTemplate() (*dtos.ContactTemplateDto, error) {
+	defs, err := s.repo.GetCustomFieldDefinitions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get custom fields: %w", err)
+	}
+	version, err := templateVersion(s.repo)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := templateTags(s.repo)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dtos.ContactTemplateDto{
+		Version:     version,
+		Fields:      make([]dtos.CustomFieldDto, len(defs)),
+		DefaultTags: tags,
+	}
+	for i, def := range defs {
+		result.Fields[i] = dtos.CustomFieldDto{
+			Key:      def.Key,
+			Label:    def.Label,
+			Type:     def.Type,
+			Options:  def.Options,
+			Required: def.Required,
+			Default:  def.DefaultValue,
+			Position: def.Position,
+		}
+	}
+	return result, nil
+}
+
+// SaveField creates or replaces a custom field of the template. Existing contacts are migrated to it in the
+// background: they get its default when they lack it, and lose values no longer valid for its type
+func (s *ContactTemplateService) SaveField(req dtos.SaveCustomFieldRequestDto) (*dtos.ContactTemplateDto, error) {
+	def := customfield.Definition{
+		Key:      strings.TrimSpace(req.Key),
+		Label:    strings.TrimSpace(req.Label),
+		Type:     req.Type,
+		Options:  req.Options,
+		Required: req.Required,
+		Default:  req.Default,
+	}
+	if err := customfield.ValidateDefinition(def); err != nil {
+		return nil, newError(ErrInvalidInput, "%s: %w", constants.ErrInvalidFieldDefinition, err)
+	}
+
+	version, err := s.repo.SaveCustomFieldDefinition(models.CustomFieldDefinition{
+		Key:          def.Key,
+		Label:        def.Label,
+		Type:         def.Type,
+		Options:      def.Options,
+		Required:     def.Required,
+		DefaultValue: def.Default,
+		Position:     req.Position,
+	}, constants.SettingContactTemplateVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save custom field: %w", err)
+	}
+	slog.Info("Contact template changed, contacts will be migrated", "field", def.Key, "version", version)
+	return s.GetTemplate()
+}
+
+// DeleteField removes a custom field from the template, contacts lose its value once migrated
+func (s *ContactTemplateService) DeleteField(key string) (*dtos.ContactTemplateDto, error) {
+	found, err := s.repo.DeleteCustomFieldDefinition(key, constants.SettingContactTemplateVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete custom field: %w", err)
+	}
+	if !found {
+		return nil, newError(ErrNotFound, constants.ErrCustomFieldNotFound)
+	}
+	slog.Info("Contact template changed, contacts will be migrated", "removedField", key)
+	return s.GetTemplate()
+}
+
+// SetDefaultTags sets the tags attached to every new contact, existing contacts keep their tags
+func (s *ContactTemplateService) SetDefaultTags(req dtos.SetTemplateTagsRequestDto) (*dtos.ContactTemplateDto, error) {
+	if len(req.Tags) > constants.MaxContactTemplateTags {
+		return nil, newError(ErrInvalidInput, "%s, at most %d", constants.ErrTooManyTemplateTags, constants.MaxContactTemplateTags)
+	}
+	tags := make([]string, 0, len(req.Tags))
+	seen := make(map[string]bool, len(req.Tags))
+	for _, tag := range req.Tags {
+		name, err := normalizeTagName(tag)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[name] {
+			seen[name] = true
+			tags = append(tags, name)
+		}
+	}
+
+	encoded, err := json.Marshal(tags)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveInstanceSetting(constants.SettingContactTemplateTags, string(encoded)); err != nil {
+		return nil, fmt.Errorf("failed to save default tags: %w", err)
+	}
+	return s.GetTemplate()
+}
+
+// GetMigration reports how far the contacts are from following the current template
+func (s *ContactTemplateService) GetMigration() (*dtos.ContactTemplateMigrationDto, error) {
+	template, err := loadContactTemplate(s.repo)
+	if err != nil {
+		return nil, err
+	}
+	var required []string
+	for _, field := range template.fields {
+		if field.Required {
+			required = append(required, field.Key)
+		}
+	}
+	outdated, missing, err := s.repo.CountContactsByTemplate(template.version, required)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count contacts: %w", err)
+	}
+	return &dtos.ContactTemplateMigrationDto{
+		Version:          template.version,
+		OutdatedContacts: outdated,
+		MissingRequired:  missing,
+	}, nil
+}
+
+// MigrateContacts brings the custom fields of the contacts following a previous template version to the current
+// one, by batches until none is left. It runs periodically and drops the cached listings of the users whose contacts
+// changed
+func (s *ContactTemplateService) MigrateContacts() error {
+	template, err := loadContactTemplate(s.repo)
+	if err != nil {
+		return err
+	}
+
+	migrated := make(map[int]bool)
+	for {
+		contacts, err := s.repo.GetOutdatedContacts(template.version, constants.ContactTemplateMigrationBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to get outdated contacts: %w", err)
+		}
+		for _, contact := range contacts {
+			fields := customfield.Migrate(template.fields, contact.CustomFields)
+			if err := s.repo.MigrateContactFields(contact.ID, fields, template.version); err != nil {
+				return fmt.Errorf("failed to migrate contact %d: %w", contact.ID, err)
+			}
+			migrated[contact.UserID] = true
+		}
+		if len(contacts) < constants.ContactTemplateMigrationBatchSize {
+			break
+		}
+	}
+
+	if s.redis != nil {
+		for userID := range migrated {
+			if err := s.redis.InvalidateUserCache(strconv.Itoa(userID)); err != nil {
+				slog.Error("Failed to invalidate contacts cache", "error", err, "userID", userID)
+			}
+		}
+	}
+	return nil
+}
+
+// loadContactTemplate reads the current contact template
+func loadContactTemplate(repo *repository.Repository) (*contactTemplate, error) {
+	defs, err := repo.GetCustomFieldDefinitions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get custom fields: %w", err)
+	}
+	version, err := templateVersion(repo)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := templateTags(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	template := &contactTemplate{version: version, defaultTags: tags}
+	for _, def := range defs {
+		template.fields = append(template.fields, customfield.Definition{
+			Key:      def.Key,
+			Label:    def.Label,
+			Type:     def.Type,
+			Options:  def.Options,
+			Required: def.Required,
+			Default:  def.DefaultValue,
+		})
+	}
+	return template, nil
+}
+
+func templateVersion(repo *repository.Repository) (int, error) {
+	value, found, err := repo.GetInstanceSetting(constants.SettingContactTemplateVersion)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get template version: %w", err)
+	}
+	if !found {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
+
+func templateTags(repo *repository.Repository) ([]string, error) {
+	value, found, err := repo.GetInstanceSetting(constants.SettingContactTemplateTags)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get default tags: %w", err)
+	}
+	tags := []string{}
+	if found {
+		if err := json.Unmarshal([]byte(value), &tags); err != nil {
+			return nil, fmt.Errorf("failed to decode default tags: %w", err)
+		}
+	}
+	return tags, nil
+}
+
+// field returns the definition of a custom field of the template, nil when there is none
+func (t *contactTemplate) field(key string) *customfield.Definition {
+	for i := range t.fields {
+		if t.fields[i].Key == key {
+			return &t.fields[i]
+		}
+	}
+	return nil
+}
+
+// newContactFields validates the custom fields of a new contact: every field must be defined and valid, fields left
+// out get their default and required ones must be set then
+func (t *contactTemplate) newContactFields(values map[string]string) (models.CustomFields, error) {
+	fields := models.CustomFields{}
+	for key, value := range values {
+		def := t.field(key)
+		if def == nil {
+			return nil, newError(ErrInvalidInput, "%s: %s", constants.ErrUnknownCustomField, key)
+		}
+		if err := customfield.Validate(*def, value); err != nil {
+			return nil, newError(ErrInvalidInput, "%s: %w", constants.ErrInvalidCustomField, err)
+		}
+		if value != "" {
+			fields[key] = value
+		}
+	}
+
+	var missing []string
+	for _, def := range t.fields {
+		if fields[def.Key] == "" && def.Default != "" {
+			fields[def.Key] = def.Default
+		}
+		if def.Required && fields[def.Key] == "" {
+			missing = append(missing, def.Key)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, newError(ErrInvalidInput, "%s: %s", constants.ErrMissingCustomField, strings.Join(missing, ", "))
+	}
+	return fields, nil
+}
+
+// updatedContactFields merges changed custom fields into the current ones of a contact, an empty value clears a
+// field unless it is required
+func (t *contactTemplate) updatedContactFields(current models.CustomFields, changes map[string]string) (models.CustomFields, error) {
+	fields := make(models.CustomFields, len(current)+len(changes))
+	for key, value := range current {
+		fields[key] = value
+	}
+
+	keys := make([]string, 0, len(changes))
+	for key := range changes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := changes[key]
+		def := t.field(key)
+		if def == nil {
+			return nil, newError(ErrInvalidInput, "%s: %s", constants.ErrUnknownCustomField, key)
+		}
+		if value == "" {
+			if def.Required {
+				return nil, newError(ErrInvalidInput, "%s: %s", constants.ErrMissingCustomField, key)
+			}
+			delete(fields, key)
+			continue
+		}
+		if err := customfield.Validate(*def, value); err != nil {
+			return nil, newError(ErrInvalidInput, "%s: %w", constants.ErrInvalidCustomField, err)
+		}
+		fields[key] = value
+	}
+	return fields, nil
+}
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/danizion/contact-app/internal/repository"
+)
+
+// DataValidationService looks for malformed data in the contacts of every user, mostly left by imports from messy
+// sources, and repairs what can be repaired without a human
+type DataValidationService struct {
+	repo *repository.Repository
+	// phoneRegions give the region repaired phone numbers without a country code are normalized in
+	phoneRegions []phoneRegionSource
+}
+
+// NewDataValidationService creates a new instance of DataValidationService
+func NewDataValidationService(db *sql.DB) *DataValidationService {
+	repo := repository.NewRepository(db)
+	return &DataValidationService{
+		repo:         repo,
+		phoneRegions: defaultPhoneRegions(repo),
+	}
+}
+
+// validatedField is a text field of contacts checked by ValidateData
+type validatedField struct {
+	name string
+	// maxLength is the length the API accepts in runes, 0 when unlimited
+	maxLength int
+	// update is the repository.UpdateContact field writing the column
+	update string
+	value  func(*models.Contact) *string
+}
+
+// validatedFields are the text fields of contacts with the limits of the create and update requests
+var validatedFields = []validatedField{
+	{"first_name", 100, "first_name", func(c *models.Contact) *string { return &c.FirstName }},
+	{"last_name", 100, "last_name", func(c *models.Contact) *string { return &c.LastName }},
+	{"phone_number", 20, "phone_number", func(c *models.Contact) *string { return &c.PhoneNumber }},
+	{"email", 100, "email", func(c *models.Contact) *string { return &c.Email }},
+	{"company", 100, "company", func(c *models.Contact) *string { return &c.Company }},
+	{"job_title", 100, "job_title", func(c *models.Contact) *string { return &c.JobTitle }},
+	{"address", 0, "address", func(c *models.Contact) *string { return &c.Address }},
+	{"street", 255, "structured_address", func(c *models.Contact) *string { return &c.Street }},
+	{"city", 100, "structured_address", func(c *models.Contact) *string { return &c.City }},
+	{"region", 100, "structured_address", func(c *models.Contact) *string { return &c.Region }},
+	{"postal_code", 20, "structured_address", func(c *models.Contact) *string { return &c.PostalCode }},
+	{"timezone", 64, "timezone", func(c *models.Contact) *string { return &c.Timezone }},
+}
+
+// contactFix is the repaired copy of a contact with the fields to write
+type contactFix struct {
+	contact models.Contact
+	fields  []string
+	updates map[string]bool
+	issues  []int
+}
+
+// ValidateData scans the contacts of a user, every user when userID is 0, and reports malformed phone numbers,
+// over-length fields and encoding problems. With fix the repairable fields are updated, over-length fields and
+// phone numbers that stay malformed once cleaned are only reported
+func (s *DataValidationService) ValidateData(userID int, fix bool) (*dtos.DataValidationReportDto, error) {
+	report := &dtos.DataValidationReportDto{Issues: []dtos.DataIssueDto{}, ByKind: map[string]int{}}
+	var fixes []contactFix
+
+	scan := func(contact models.Contact) error {
+		report.Scanned++
+		repaired := contactFix{contact: contact, updates: map[string]bool{}}
+		for _, field := range validatedFields {
+			value := field.value(&repaired.contact)
+			for _, issue := range checkField(field, *value) {
+				issue.ContactID, issue.UserID = contact.ID, contact.UserID
+				report.ByKind[issue.Kind]++
+				if issue.Fix != "" {
+					*value = issue.Fix
+					if !repaired.updates[field.update] {
+						repaired.fields = append(repaired.fields, field.name)
+					}
+					repaired.updates[field.update] = true
+					repaired.issues = append(repaired.issues, len(report.Issues))
+				}
+				report.Issues = append(report.Issues, issue)
+			}
+		}
+		if len(repaired.issues) > 0 {
+			fixes = append(fixes, repaired)
+		}
+		return nil
+	}
+
+	var err error
+	if userID != 0 {
+		err = s.repo.StreamContactsByUser(userID, scan)
+	} else {
+		err = s.repo.StreamAllContacts(scan)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan contacts: %w", err)
+	}
+	if !fix {
+		return report, nil
+	}
+
+	// Fixes are written once the scan is done so the rows being read are never the ones being updated
+	for _, repaired := range fixes {
+		if repaired.updates["phone_number"] {
+			region, err := resolvePhoneRegion(s.phoneRegions, repaired.contact.UserID, "")
+			if err != nil {
+				return report, err
+			}
+			repaired.contact.PhoneNormalized = storedPhoneNormalized(repaired.contact, region)
+		}
+		if err := s.repo.UpdateContact(repaired.contact, repaired.updates); err != nil {
+			return report, fmt.Errorf("failed to fix contact %d: %w", repaired.contact.ID, err)
+		}
+		recordContactChange(s.repo, repaired.contact.UserID, constants.AuditActionContactUpdated, repaired.contact.ID,
+			map[string]interface{}{"fields": repaired.fields, "source": "validate_data"})
+		for _, i := range repaired.issues {
+			report.Issues[i].Fixed = true
+			report.Fixed++
+		}
+	}
+	return report, nil
+}
+
+// checkField returns the problems of a value, each with the value it is repaired to when it can be. A fix builds on
+// the previous one: a phone number is checked once its encoding is repaired
+func checkField(field validatedField, value string) []dtos.DataIssueDto {
+	var issues []dtos.DataIssueDto
+	report := func(kind, fixed string) {
+		issues = append(issues, dtos.DataIssueDto{Field: field.name, Kind: kind, Value: value, Fix: fixed})
+		if fixed != "" {
+			value = fixed
+		}
+	}
+
+	if cleaned := cleanEncoding(value); cleaned != value {
+		report(constants.DataIssueInvalidEncoding, cleaned)
+	}
+	if decoded, ok := decodeMojibake(value); ok {
+		report(constants.DataIssueMojibake, decoded)
+	}
+	if field.name == "phone_number" && value != "" && !validPhoneNumber(value) {
+		cleaned := cleanPhoneNumber(value)
+		if !validPhoneNumber(cleaned) {
+			cleaned = ""
+		}
+		report(constants.DataIssueMalformedPhone, cleaned)
+	}
+	if field.maxLength > 0 && utf8.RuneCountInString(value) > field.maxLength {
+		// Truncating would lose data, shortening is left to the owner of the contact
+		report(constants.DataIssueTooLong, "")
+	}
+	return issues
+}
+
+// cleanEncoding drops invalid UTF-8 sequences, replacement characters left by a lossy conversion and control
+// characters, except the line breaks and tabs of multi-line values
+func cleanEncoding(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r == utf8.RuneError || (unicode.IsControl(r) && r != '\n' && r != '\r' && r != '\t') {
+			return -1
+		}
+		return r
+	}, strings.ToValidUTF8(value, ""))
+}
+
+// decodeMojibake repairs UTF-8 text that was decoded as Latin-1 and encoded again ("JosÃ©" for "José"): the runes
+// of such a value are all Latin-1 and their bytes are valid UTF-8 holding multi-byte characters
+func decodeMojibake(value string) (string, bool) {
+	raw := make([]byte, 0, len(value))
+	for _, r := range value {
+		if r > unicode.MaxLatin1 {
+			return "", false
+		}
+		raw = append(raw, byte(r))
+	}
+	if !utf8.Valid(raw) || utf8.RuneCount(raw) == len(raw) {
+		return "", false
+	}
+	return string(raw), true
+}
+
+// validPhoneNumber accepts digits with an optional leading + and the usual separators (spaces, dashes, dots and
+// parentheses), with between MinPhoneDigits and MaxPhoneDigits digits
+func validPhoneNumber(phone string) bool {
+	digits := 0
+	for i, r := range phone {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r == '+' && i == 0:
+		case strings.ContainsRune(" -.()", r):
+		default:
+			return false
+		}
+	}
+	return digits >= constants.MinPhoneDigits && digits <= constants.MaxPhoneDigits
+}
+
+// cleanPhoneNumber keeps the digits and separators of a phone number, dropping labels and prefixes ("tel:",
+// "Mobile: ") and collapsing spaces. A + is kept when it starts the number. Letters after the first digit (an
+// extension, a note) cannot be dropped safely, the number is not cleaned then
+func cleanPhoneNumber(phone string) string {
+	var b strings.Builder
+	digits := false
+	for _, r := range phone {
+		switch {
+		case r >= '0' && r <= '9':
+			digits = true
+			b.WriteRune(r)
+		case strings.ContainsRune("-.()", r):
+			b.WriteRune(r)
+		case r == '+' && strings.TrimSpace(b.String()) == "":
+			b.Reset()
+			b.WriteRune(r)
+		case unicode.IsSpace(r):
+			b.WriteRune(' ')
+		case unicode.IsLetter(r) && digits:
+			return ""
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+
+	"github.com/danizion/contact-app/internal/auth"
+	"github.com/danizion/contact-app/internal/demo"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/repository"
+	"github.com/danizion/contact-app/internal/storage/redis"
+)
+
+// DemoService keeps the public demo account: it creates it when missing and resets its data to the seed fixtures
+type DemoService struct {
+	repo     *repository.Repository
+	users    *UserService
+	archives *ArchiveService
+	config   demo.Config
+}
+
+// NewDemoService creates a new instance of DemoService
+func NewDemoService(db *sql.DB, redisClient *redis.Redis, config demo.Config) *DemoService {
+	return &DemoService{
+		repo:     repository.NewRepository(db),
+		users:    NewUserService(db),
+		archives: NewArchiveService(db, redisClient),
+		config:   config,
+	}
+}
+
+// Reset provisions the demo account when missing and replaces all of its data with the seed fixtures
+func (s *DemoService) Reset() error {
+	// the demo credentials are public, an admin demo account would open the admin routes to everyone
+	if auth.IsAdminEmail(s.config.Email) {
+		return fmt.Errorf("demo account %s must not be listed in ADMIN_EMAILS", s.config.Email)
+	}
+
+	user, err := s.repo.GetUserByEmail(s.config.Email)
+	if err != nil {
+		return fmt.Errorf("failed to get demo user: %w", err)
+	}
+
+	var userID int
+	if user != nil {
+		userID = user.ID
+	} else {
+		userID, err = s.users.CreateUser(dtos.CreateUserRequestDto{
+			Username: s.config.Username,
+			Email:    s.config.Email,
+			Password: s.config.Password,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create demo user: %w", err)
+		}
+		slog.Info("Demo account created", "userID", userID, "email", s.config.Email)
+	}
+
+	if err := s.repo.ClearAccountData(userID); err != nil {
+		return fmt.Errorf("failed to clear demo account: %w", err)
+	}
+	result, err := s.archives.ImportAccount(userID, demo.Seed())
+	if err != nil {
+		return fmt.Errorf("failed to seed demo account: %w", err)
+	}
+	slog.Info("Demo account reset", "userID", userID, "contacts", result.ContactsImported)
+	return nil
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/mail"
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/danizion/contact-app/internal/repository"
+)
+
+// digestData is the data rendered by the digest email template
+type digestData struct {
+	Username    string
+	From        time.Time
+	To          time.Time
+	Added       int
+	Updated     int
+	Deleted     int
+	NewContacts []string
+}
+
+// DigestService emails the users who opted in a weekly summary of the changes to their address book
+type DigestService struct {
+	repo   *repository.Repository
+	sender mail.Sender
+}
+
+// NewDigestService creates a new instance of DigestService
+func NewDigestService(db *sql.DB, sender mail.Sender) *DigestService {
+	return &DigestService{
+		repo:   repository.NewRepository(db),
+		sender: sender,
+	}
+}
+
+// SendDueDigests sends the digest of every opted-in user who did not receive one during the last period,
+// a failure for one user does not prevent the others from receiving theirs
+func (s *DigestService) SendDueDigests() error {
+	now := time.Now()
+	due := now.Add(-constants.DigestPeriod)
+
+	users, err := s.repo.GetDigestRecipients(due)
+	if err != nil {
+		return fmt.Errorf("failed to get digest recipients: %w", err)
+	}
+
+	failed := 0
+	for _, user := range users {
+		// Claim first so concurrent replicas never send the same digest twice
+		claimed, err := s.repo.ClaimDigest(user.ID, due)
+		if err != nil || !claimed {
+			continue
+		}
+		if err := s.sendDigest(user, due, now); err != nil {
+			log.Printf("Error sending digest to user %d: %v", user.ID, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to send %d of %d digests", failed, len(users))
+	}
+	return nil
+}
+
+func (s *DigestService) sendDigest(user models.User, from, to time.Time) error {
+	counts, err := s.repo.CountAuditActions(user.ID, from, []string{
+		constants.AuditActionContactCreated,
+		constants.AuditActionContactUpdated,
+		constants.AuditActionContactDeleted,
+	})
+	if err != nil {
+		return err
+	}
+
+	data := digestData{
+		Username: user.Username,
+		From:     from,
+		To:       to,
+		Added:    counts[constants.AuditActionContactCreated],
+		Updated:  counts[constants.AuditActionContactUpdated],
+		Deleted:  counts[constants.AuditActionContactDeleted],
+	}
+	// Nothing happened this week, skip the email rather than send an empty digest
+	if data.Added+data.Updated+data.Deleted == 0 {
+		return nil
+	}
+
+	newContacts, err := s.repo.GetContactsCreatedSince(user.ID, from, constants.DigestMaxNewContacts)
+	if err != nil {
+		return err
+	}
+	for _, contact := range newContacts {
+		data.NewContacts = append(data.NewContacts, strings.TrimSpace(contact.FirstName+" "+contact.LastName))
+	}
+
+	msg, err := mail.Render("digest", user.Email, data)
+	if err != nil {
+		return err
+	}
+	return s.sender.Send(msg)
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/danizion/contact-app/internal/repository"
+)
+
+// directorySlugPattern matches the vanity paths of the team directory
+var directorySlugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,49}$`)
+
+// DirectoryService handles the team directory, a public read-only page of the deployment listing the contacts of the
+// shared address book or the cards the members picked for themselves. Admins choose its path and the fields it shows
+type DirectoryService struct {
+	repo *repository.Repository
+}
+
+// NewDirectoryService creates a new instance of DirectoryService
+func NewDirectoryService(db *sql.DB) *DirectoryService {
+	return &DirectoryService{repo: repository.NewRepository(db)}
+}
+
+// WithContext returns the service running its queries under ctx, the context of a request so they stop with it
+func (s *DirectoryService) WithContext(ctx context.Context) *DirectoryService {
+	bound := *s
+	bound.repo = s.repo.WithContext(ctx)
+	return &bound
+}
+
+// GetSettings returns the settings of the team directory, disabled until saved
+func (s *DirectoryService) GetSettings() (*dtos.DirectorySettingsDto, error) {
+	value, found, err := s.repo.GetInstanceSetting(constants.SettingTeamDirectory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get directory settings: %w", err)
+	}
+	settings := &dtos.DirectorySettingsDto{Source: constants.DirectorySourceShared, Fields: []string{}}
+	if found {
+		if err := json.Unmarshal([]byte(value), settings); err != nil {
+			return nil, fmt.Errorf("failed to decode directory settings: %w", err)
+		}
+	}
+	return settings, nil
+}
+
+// SaveSettings replaces the settings of the team directory. Fields are kept in the order of
+// constants.DirectoryFields
+func (s *DirectoryService) SaveSettings(req dtos.DirectorySettingsDto) (*dtos.DirectorySettingsDto, error) {
+	req.Slug = strings.ToLower(strings.TrimSpace(req.Slug))
+	req.Title = strings.TrimSpace(req.Title)
+	if req.Enabled || req.Slug != "" {
+		if !directorySlugPattern.MatchString(req.Slug) {
+			return nil, newError(ErrInvalidInput, constants.ErrInvalidDirectorySlug)
+		}
+	}
+	requested := make(map[string]bool, len(req.Fields))
+	for _, field := range req.Fields {
+		if !isDirectoryField(field) {
+			return nil, newError(ErrInvalidInput, "%s: %s", constants.ErrInvalidDirectoryField, field)
+		}
+		requested[field] = true
+	}
+	fields := []string{}
+	for _, field := range constants.DirectoryFields {
+		if requested[field] {
+			fields = append(fields, field)
+		}
+	}
+	req.Fields = fields
+
+	encoded, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveInstanceSetting(constants.SettingTeamDirectory, string(encoded)); err != nil {
+		return nil, fmt.Errorf("failed to save directory settings: %w", err)
+	}
+	return &req, nil
+}
+
+// SetCard picks the contact of a user standing for them in the directory of the members, nil takes them out of it
+func (s *DirectoryService) SetCard(req dtos.SetDirectoryCardRequestDto) error {
+	if req.ContactID != nil {
+		contact, err := s.repo.GetContactByID(req.UserID, *req.ContactID)
+		if err != nil {
+			return fmt.Errorf("failed to get contact: %w", err)
+		}
+		if contact == nil {
+			return newError(ErrNotFound, constants.ErrContactNotFound)
+		}
+	}
+	if err := s.repo.SetDirectoryCard(req.UserID, req.ContactID); err != nil {
+		return fmt.Errorf("failed to set directory card: %w", err)
+	}
+	return nil
+}
+
+// List returns a page of the team directory at a slug. A disabled directory and any other slug are not found
+func (s *DirectoryService) List(req dtos.DirectoryRequestDto) (*dtos.DirectoryResponseDto, error) {
+	settings, err := s.GetSettings()
+	if err != nil {
+		return nil, err
+	}
+	if !settings.Enabled || settings.Slug != strings.ToLower(req.Slug) {
+		return nil, newError(ErrNotFound, constants.ErrDirectoryNotFound)
+	}
+
+	visible := make(map[string]bool, len(settings.Fields))
+	for _, field := range settings.Fields {
+		visible[field] = true
+	}
+	// Only the visible fields are searched, so a search never reveals a hidden company
+	members := settings.Source == constants.DirectorySourceMembers
+	contacts, total, err := s.repo.GetDirectoryContacts(members, strings.TrimSpace(req.Query), visible["company"],
+		req.PageSize, (req.Page-1)*req.PageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get directory contacts: %w", err)
+	}
+	var profiles map[int][]dtos.SocialProfileDto
+	if visible["social_profiles"] {
+		if profiles, err = socialProfilesByContact(s.repo, contacts); err != nil {
+			return nil, err
+		}
+	}
+
+	totalPages := total / req.PageSize
+	if total%req.PageSize > 0 {
+		totalPages++
+	}
+	result := &dtos.DirectoryResponseDto{
+		Title:      settings.Title,
+		Branding:   Branding(),
+		Fields:     settings.Fields,
+		Items:      make([]dtos.DirectoryEntryDto, len(contacts)),
+		TotalCount: total,
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+		TotalPages: totalPages,
+	}
+	for i, contact := range contacts {
+		result.Items[i] = toDirectoryEntry(contact, visible)
+		result.Items[i].SocialProfiles = profiles[contact.ID]
+	}
+	return result, nil
+}
+
+func isDirectoryField(field string) bool {
+	for _, known := range constants.DirectoryFields {
+		if field == known {
+			return true
+		}
+	}
+	return false
+}
+
+// toDirectoryEntry maps a contact to a directory entry showing its name and the visible fields only
+func toDirectoryEntry(contact models.Contact, visible map[string]bool) dtos.DirectoryEntryDto {
+	show := func(field, value string) string {
+		if visible[field] {
+			return value
+		}
+		return ""
+	}
+	return dtos.DirectoryEntryDto{
+		FirstName:   contact.FirstName,
+		LastName:    contact.LastName,
+		Email:       show("email", contact.Email),
+		PhoneNumber: show("phone_number", contact.PhoneNumber),
+		Company:     show("company", contact.Company),
+		JobTitle:    show("job_title", contact.JobTitle),
+		City:        show("city", contact.City),
+		Region:      show("region", contact.Region),
+		CountryCode: show("country_code", contact.CountryCode),
+	}
+}
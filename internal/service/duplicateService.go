@@ -0,0 +1,113 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dedupe"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/danizion/contact-app/internal/phone"
+)
+
+// GetDuplicateContacts lists the pairs of contacts of a user likely to be the same person, with the score of each
+// signal so clients can tell why they were flagged. The sensitivity of the request overrides the one of the
+// preferences of the user
+func (s *ContactService) GetDuplicateContacts(req dtos.DuplicateContactsRequestDto) (*dtos.DuplicateContactsResponseDto, error) {
+	prefs, err := s.repo.GetUserPreferences(req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get preferences: %w", err)
+	}
+	sensitivity := duplicateSensitivity(prefs)
+	override, err := normalizeDuplicateSensitivity(req.Sensitivity)
+	if err != nil {
+		return nil, err
+	}
+	if override != "" {
+		sensitivity = override
+	}
+	thresholds, _ := dedupe.ThresholdsFor(sensitivity)
+	region, err := resolvePhoneRegion(s.phoneRegions, req.UserID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	repoContacts, err := s.repo.GetContactsByUser(req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contacts: %w", err)
+	}
+	candidates := make([]dedupe.Contact, len(repoContacts))
+	byID := make(map[int]models.Contact, len(repoContacts))
+	for i, contact := range repoContacts {
+		candidates[i] = dedupe.Contact{
+			ID:    contact.ID,
+			Name:  contact.FirstName + " " + contact.LastName,
+			Phone: comparablePhone(contact, region),
+			Email: contact.Email,
+		}
+		byID[contact.ID] = contact
+	}
+
+	pairs := dedupe.Find(candidates, thresholds)
+	result := &dtos.DuplicateContactsResponseDto{
+		Sensitivity: sensitivity,
+		Thresholds:  dtos.DuplicateThresholdsDto{Score: thresholds.Score, NameSimilarity: thresholds.NameSimilarity},
+		Pairs:       []dtos.DuplicatePairDto{},
+		Truncated:   len(pairs) > constants.MaxDuplicatePairs,
+	}
+	if result.Truncated {
+		pairs = pairs[:constants.MaxDuplicatePairs]
+	}
+	now := time.Now()
+	for _, pair := range pairs {
+		contacts := []dtos.GetContactsResponseDto{toContactDto(byID[pair.A]), toContactDto(byID[pair.B])}
+		applyLocalTime(contacts, now)
+		dto := dtos.DuplicatePairDto{Contacts: contacts, Score: pair.Score, Signals: make([]dtos.DuplicateSignalDto, len(pair.Signals))}
+		for i, signal := range pair.Signals {
+			dto.Signals[i] = dtos.DuplicateSignalDto{
+				Signal:       signal.Name,
+				Score:        signal.Score,
+				Weight:       signal.Weight,
+				Contribution: signal.Contribution,
+				Threshold:    signal.Threshold,
+				Matched:      signal.Matched,
+			}
+		}
+		result.Pairs = append(result.Pairs, dto)
+	}
+	return result, nil
+}
+
+// comparablePhone returns the phone number of a contact in E.164 form, national numbers of contacts without a
+// country being read in the region resolved for the user. Numbers that cannot be normalized are compared as entered
+func comparablePhone(contact models.Contact, region string) string {
+	if e164, ok := phone.Normalize(contact.PhoneNumber, contact.CountryCode); ok {
+		return e164
+	}
+	if e164, ok := phone.Normalize(contact.PhoneNumber, region); ok {
+		return e164
+	}
+	return strings.TrimSpace(contact.PhoneNumber)
+}
+
+// duplicateSensitivity returns the duplicate sensitivity of the preferences of a user, the default when not set
+func duplicateSensitivity(prefs *models.UserPreferences) string {
+	if prefs.DuplicateSensitivity == "" {
+		return dedupe.DefaultSensitivity
+	}
+	return prefs.DuplicateSensitivity
+}
+
+// normalizeDuplicateSensitivity validates a duplicate sensitivity, empty to use the default
+func normalizeDuplicateSensitivity(sensitivity string) (string, error) {
+	sensitivity = strings.ToLower(strings.TrimSpace(sensitivity))
+	if sensitivity == "" {
+		return "", nil
+	}
+	if _, ok := dedupe.ThresholdsFor(sensitivity); !ok {
+		return "", newError(ErrInvalidInput, constants.ErrInvalidDuplicateSensitivity)
+	}
+	return sensitivity, nil
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/danizion/contact-app/internal/accountstate"
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/danizion/contact-app/internal/repository"
+	"github.com/danizion/contact-app/internal/utils"
+)
+
+// EmbedService manages the read-only tokens users create to embed a contact list, such as a team directory, in an
+// intranet page, and serves the lists to the holders of the tokens
+type EmbedService struct {
+	repo *repository.Repository
+	// publicURL starts the URLs of the embedded lists
+	publicURL string
+}
+
+// NewEmbedService creates a new instance of EmbedService
+func NewEmbedService(db *sql.DB) *EmbedService {
+	return &EmbedService{
+		repo:      repository.NewRepository(db),
+		publicURL: strings.TrimSuffix(utils.GetEnvOrDefault("PUBLIC_URL", constants.DefaultPublicURL), "/"),
+	}
+}
+
+// WithContext returns the service running its queries under ctx, the context of a request so they stop with it
+func (s *EmbedService) WithContext(ctx context.Context) *EmbedService {
+	bound := *s
+	bound.repo = s.repo.WithContext(ctx)
+	return &bound
+}
+
+// CreateToken generates a new embed token limited to a group or a saved search of the user, the token is only
+// returned here
+func (s *EmbedService) CreateToken(req dtos.CreateEmbedTokenRequestDto) (*dtos.EmbedTokenDto, error) {
+	now := time.Now()
+	token := models.EmbedToken{
+		UserID:    req.UserID,
+		Name:      strings.TrimSpace(req.Name),
+		GroupID:   req.GroupID,
+		Query:     strings.TrimSpace(req.Query),
+		ExpiresAt: req.ExpiresAt,
+	}
+	if req.Tag != "" {
+		tag, err := normalizeTagName(req.Tag)
+		if err != nil {
+			return nil, err
+		}
+		token.Tag = tag
+	}
+	if (token.GroupID != nil) == (token.Query != "" || token.Tag != "") {
+		return nil, newError(ErrInvalidInput, constants.ErrInvalidEmbedScope)
+	}
+	if token.ExpiresAt != nil && (!token.ExpiresAt.After(now) || token.ExpiresAt.After(now.Add(constants.MaxEmbedTokenLifetime))) {
+		return nil, newError(ErrInvalidInput, constants.ErrInvalidEmbedExpiry)
+	}
+	if token.GroupID != nil {
+		group, err := s.repo.GetGroup(req.UserID, *token.GroupID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get group: %w", err)
+		}
+		if group == nil {
+			return nil, newError(ErrNotFound, constants.ErrGroupNotFound)
+		}
+	}
+
+	count, err := s.repo.CountActiveEmbedTokens(req.UserID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count embed tokens: %w", err)
+	}
+	if count >= constants.MaxEmbedTokensPerUser {
+		return nil, newError(ErrConflict, constants.ErrEmbedTokenLimitReached)
+	}
+
+	secret, err := randomToken("et_", 24)
+	if err != nil {
+		return nil, err
+	}
+	token.TokenHash = hashEmbedToken(secret)
+	token.TokenPrefix = secret[:11]
+	token.CreatedAt = now
+	token.ID, err = s.repo.CreateEmbedToken(token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create embed token: %w", err)
+	}
+	recordAudit(s.repo, req.UserID, constants.AuditActionEmbedTokenCreated, constants.AuditEntityEmbedToken, token.ID, nil)
+
+	result := toEmbedTokenDto(token, now)
+	result.Token = secret
+	result.URL = s.publicURL + "/embed/contacts?token=" + url.QueryEscape(secret)
+	return &result, nil
+}
+
+// ListTokens returns the embed tokens of a user without the tokens themselves, revoked and expired ones included
+func (s *EmbedService) ListTokens(userID int) (*dtos.EmbedTokenListResponseDto, error) {
+	tokens, err := s.repo.GetEmbedTokensByUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get embed tokens: %w", err)
+	}
+	now := time.Now()
+	result := &dtos.EmbedTokenListResponseDto{Items: make([]dtos.EmbedTokenDto, len(tokens))}
+	for i, token := range tokens {
+		result.Items[i] = toEmbedTokenDto(token, now)
+	}
+	return result, nil
+}
+
+// RevokeToken ends an embed token, the pages embedding its list stop showing it
+func (s *EmbedService) RevokeToken(userID, id int) error {
+	revoked, err := s.repo.RevokeEmbedToken(userID, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke embed token: %w", err)
+	}
+	if !revoked {
+		return newError(ErrNotFound, constants.ErrEmbedTokenNotFound)
+	}
+	recordAudit(s.repo, userID, constants.AuditActionEmbedTokenRevoked, constants.AuditEntityEmbedToken, id, nil)
+	return nil
+}
+
+// ListContacts returns a page of the contact list of an embed token, ordered by name. Tokens of deactivated accounts
+// show nothing until the account is reactivated
+func (s *EmbedService) ListContacts(req dtos.EmbedContactsRequestDto) (*dtos.EmbedContactListResponseDto, error) {
+	token, err := s.repo.UseEmbedToken(hashEmbedToken(req.Token), time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get embed token: %w", err)
+	}
+	if token == nil {
+		return nil, newError(ErrUnauthorized, constants.ErrInvalidEmbedToken)
+	}
+	state, err := s.repo.GetAccountState(token.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account state: %w", err)
+	}
+	if !accountstate.CanUseAPI(state) {
+		return nil, newError(ErrUnauthorized, constants.ErrInvalidEmbedToken)
+	}
+
+	filter := repository.ContactFilter{Query: token.Query, Tag: token.Tag}
+	if token.GroupID != nil {
+		filter.GroupID = *token.GroupID
+	}
+	page := repository.ContactPage{
+		Page: req.Page,
+		Size: req.PageSize,
+		Sort: repository.ContactSort{Columns: []string{"last_name", "first_name"}},
+	}
+	contacts, total, _, err := s.repo.GetContactsByUserPaginated(token.UserID, page, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contacts: %w", err)
+	}
+	profiles, err := socialProfilesByContact(s.repo, contacts)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := total / req.PageSize
+	if total%req.PageSize > 0 {
+		totalPages++
+	}
+	result := &dtos.EmbedContactListResponseDto{
+		Name:       token.Name,
+		Branding:   Branding(),
+		Items:      make([]dtos.EmbedContactDto, len(contacts)),
+		TotalCount: total,
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+		TotalPages: totalPages,
+	}
+	for i, contact := range contacts {
+		result.Items[i] = dtos.EmbedContactDto{
+			FirstName:      contact.FirstName,
+			LastName:       contact.LastName,
+			Email:          contact.Email,
+			PhoneNumber:    contact.PhoneNumber,
+			Company:        contact.Company,
+			JobTitle:       contact.JobTitle,
+			SocialProfiles: profiles[contact.ID],
+		}
+	}
+	return result, nil
+}
+
+// hashEmbedToken is the key an embed token is stored under, so a leak of the database does not leak usable tokens
+func hashEmbedToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func toEmbedTokenDto(token models.EmbedToken, now time.Time) dtos.EmbedTokenDto {
+	return dtos.EmbedTokenDto{
+		ID:          token.ID,
+		Name:        token.Name,
+		TokenPrefix: token.TokenPrefix,
+		GroupID:     token.GroupID,
+		Query:       token.Query,
+		Tag:         token.Tag,
+		Active:      token.RevokedAt == nil && (token.ExpiresAt == nil || token.ExpiresAt.After(now)),
+		ExpiresAt:   token.ExpiresAt,
+		RevokedAt:   token.RevokedAt,
+		LastUsedAt:  token.LastUsedAt,
+		CreatedAt:   token.CreatedAt,
+	}
+}
@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/enrichment"
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/danizion/contact-app/internal/repository"
+	"github.com/danizion/contact-app/internal/social"
+	"github.com/danizion/contact-app/internal/storage/redis"
+	"github.com/danizion/contact-app/internal/utils"
+)
+
+// EnrichmentService fills missing contact data from a pluggable enrichment provider,
+// results are kept as suggestions with their provenance until the user accepts or rejects them
+type EnrichmentService struct {
+	repo     *repository.Repository
+	redis    *redis.Redis
+	provider enrichment.Provider
+	onCreate bool
+}
+
+// NewEnrichmentService creates a new instance of EnrichmentService, provider may be nil when enrichment is not configured
+func NewEnrichmentService(db *sql.DB, redisClient *redis.Redis, provider enrichment.Provider) *EnrichmentService {
+	return &EnrichmentService{
+		repo:     repository.NewRepository(db),
+		redis:    redisClient,
+		provider: provider,
+		onCreate: utils.GetEnvOrDefault("ENRICH_ON_CREATE", "false") == "true",
+	}
+}
+
+// WithContext returns the service running its queries and cache calls under ctx, the context of a request so they stop with it
+func (s *EnrichmentService) WithContext(ctx context.Context) *EnrichmentService {
+	bound := *s
+	bound.repo = s.repo.WithContext(ctx)
+	bound.redis = s.redis.WithContext(ctx)
+	return &bound
+}
+
+// EnrichContact queries the provider for a contact and stores the result as a pending suggestion
+func (s *EnrichmentService) EnrichContact(userID, contactID int) (*dtos.EnrichmentResponseDto, error) {
+	if s.provider == nil {
+		return nil, newError(ErrUnavailable, constants.ErrEnrichmentNotConfigured)
+	}
+
+	contact, err := s.repo.GetContactByID(userID, contactID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contact: %w", err)
+	}
+	if contact == nil {
+		return nil, newError(ErrNotFound, constants.ErrContactNotFound)
+	}
+
+	query := enrichment.Query{
+		Email:       contact.Email,
+		PhoneNumber: contact.PhoneNumber,
+		FullName:    strings.TrimSpace(contact.FirstName + " " + contact.LastName),
+	}
+	matchedOn := "email"
+	if query.Email == "" {
+		matchedOn = "phone_number"
+		if query.PhoneNumber == "" {
+			return nil, newError(ErrUnprocessable, constants.ErrEnrichmentNoIdentifier)
+		}
+	}
+
+	result, err := s.provider.Enrich(query)
+	if err != nil {
+		if errors.Is(err, enrichment.ErrNoMatch) {
+			return nil, newError(ErrNotFound, constants.ErrEnrichmentNoMatch)
+		}
+		return nil, fmt.Errorf("failed to enrich contact: %w", err)
+	}
+
+	// Only suggest values for fields the contact does not have yet
+	suggestion := models.ContactEnrichment{
+		ContactID: contactID,
+		UserID:    userID,
+		Provider:  s.provider.Name(),
+		MatchedOn: matchedOn,
+		Status:    constants.EnrichmentStatusPending,
+	}
+	if contact.Company == "" {
+		suggestion.Company = result.Company
+	}
+	if contact.JobTitle == "" {
+		suggestion.JobTitle = result.JobTitle
+	}
+	if suggestion.Company == "" && suggestion.JobTitle == "" && len(result.SocialProfiles) == 0 {
+		return nil, newError(ErrNotFound, constants.ErrEnrichmentNoMatch)
+	}
+
+	socialProfiles, err := json.Marshal(result.SocialProfiles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode social profiles: %w", err)
+	}
+	suggestion.SocialProfiles = string(socialProfiles)
+
+	suggestion.ID, err = s.repo.CreateEnrichment(suggestion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store enrichment: %w", err)
+	}
+
+	stored, err := s.repo.GetEnrichment(userID, contactID, suggestion.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stored enrichment: %w", err)
+	}
+	if stored == nil {
+		return nil, newError(ErrNotFound, constants.ErrEnrichmentNotFound)
+	}
+	enriched := toEnrichmentDto(*stored)
+	return &enriched, nil
+}
+
+// EnrichOnCreate enriches a newly created contact in the background when ENRICH_ON_CREATE is enabled
+func (s *EnrichmentService) EnrichOnCreate(userID, contactID int) {
+	if !s.onCreate || s.provider == nil {
+		return
+	}
+
+	go func() {
+		_, err := s.EnrichContact(userID, contactID)
+		if err != nil && !errors.Is(err, ErrNotFound) && !errors.Is(err, ErrUnprocessable) {
+			log.Printf("Error enriching new contact %d: %v", contactID, err)
+		}
+	}()
+}
+
+// ListEnrichments returns all enrichment suggestions of a contact
+func (s *EnrichmentService) ListEnrichments(userID, contactID int) ([]dtos.EnrichmentResponseDto, error) {
+	owned, err := s.repo.IsContactOwnedByUser(userID, contactID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check contact: %w", err)
+	}
+	if !owned {
+		return nil, newError(ErrNotFound, constants.ErrContactNotFound)
+	}
+
+	enrichments, err := s.repo.GetEnrichmentsByContact(userID, contactID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get enrichments: %w", err)
+	}
+
+	result := make([]dtos.EnrichmentResponseDto, len(enrichments))
+	for i, e := range enrichments {
+		result[i] = toEnrichmentDto(e)
+	}
+	return result, nil
+}
+
+// AcceptEnrichment applies a pending suggestion to the contact's empty fields
+func (s *EnrichmentService) AcceptEnrichment(userID, contactID, enrichmentID int) error {
+	suggestion, err := s.getPendingEnrichment(userID, contactID, enrichmentID)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.AcceptEnrichment(*suggestion)
+	if err != nil {
+		if errors.Is(err, repository.ErrEnrichmentResolved) {
+			return newError(ErrConflict, constants.ErrEnrichmentResolved)
+		}
+		return fmt.Errorf("failed to accept enrichment: %w", err)
+	}
+
+	s.applySocialProfiles(*suggestion)
+	recordContactChange(s.repo, userID, constants.AuditActionContactUpdated, contactID,
+		map[string]interface{}{"enrichment_id": enrichmentID})
+
+	return nil
+}
+
+// RejectEnrichment discards a pending suggestion
+func (s *EnrichmentService) RejectEnrichment(userID, contactID, enrichmentID int) error {
+	if _, err := s.getPendingEnrichment(userID, contactID, enrichmentID); err != nil {
+		return err
+	}
+
+	err := s.repo.RejectEnrichment(enrichmentID)
+	if err != nil {
+		if errors.Is(err, repository.ErrEnrichmentResolved) {
+			return newError(ErrConflict, constants.ErrEnrichmentResolved)
+		}
+		return fmt.Errorf("failed to reject enrichment: %w", err)
+	}
+	return nil
+}
+
+// applySocialProfiles adds the suggested social profiles the contact does not have yet, invalid ones are skipped
+func (s *EnrichmentService) applySocialProfiles(suggestion models.ContactEnrichment) {
+	var profiles map[string]string
+	if err := json.Unmarshal([]byte(suggestion.SocialProfiles), &profiles); err != nil {
+		log.Printf("Error decoding social profiles of enrichment %d: %v", suggestion.ID, err)
+		return
+	}
+
+	for network, value := range profiles {
+		handle, url, err := social.Normalize(network, value)
+		if err != nil {
+			log.Printf("Skipping social profile %s of enrichment %d: %v", network, suggestion.ID, err)
+			continue
+		}
+
+		err = s.repo.AddSocialProfileIfMissing(models.SocialProfile{
+			ContactID: suggestion.ContactID,
+			Network:   network,
+			Handle:    handle,
+			URL:       url,
+		})
+		if err != nil {
+			log.Printf("Error adding social profile %s of enrichment %d: %v", network, suggestion.ID, err)
+		}
+	}
+}
+
+func (s *EnrichmentService) getPendingEnrichment(userID, contactID, enrichmentID int) (*models.ContactEnrichment, error) {
+	suggestion, err := s.repo.GetEnrichment(userID, contactID, enrichmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get enrichment: %w", err)
+	}
+	if suggestion == nil {
+		return nil, newError(ErrNotFound, constants.ErrEnrichmentNotFound)
+	}
+	if suggestion.Status != constants.EnrichmentStatusPending {
+		return nil, newError(ErrConflict, constants.ErrEnrichmentResolved)
+	}
+	return suggestion, nil
+}
+
+func toEnrichmentDto(e models.ContactEnrichment) dtos.EnrichmentResponseDto {
+	var socialProfiles map[string]string
+	if err := json.Unmarshal([]byte(e.SocialProfiles), &socialProfiles); err != nil {
+		log.Printf("Error decoding social profiles of enrichment %d: %v", e.ID, err)
+	}
+
+	return dtos.EnrichmentResponseDto{
+		ID:             e.ID,
+		ContactID:      e.ContactID,
+		Provider:       e.Provider,
+		MatchedOn:      e.MatchedOn,
+		Status:         e.Status,
+		Company:        e.Company,
+		JobTitle:       e.JobTitle,
+		SocialProfiles: socialProfiles,
+		FetchedAt:      e.FetchedAt,
+		ResolvedAt:     e.ResolvedAt,
+	}
+}
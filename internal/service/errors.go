@@ -0,0 +1,47 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kinds of the errors services return for requests they refuse. Handlers tell them apart with errors.Is and answer
+// each kind with its HTTP status, errors of no kind are internal failures
+var (
+	ErrInvalidInput     = errors.New("invalid input")
+	ErrUnauthorized     = errors.New("unauthorized")
+	ErrForbidden        = errors.New("forbidden")
+	ErrNotFound         = errors.New("not found")
+	ErrConflict         = errors.New("conflict")
+	ErrDuplicateContact = errors.New("duplicate contact")
+	ErrTooLarge         = errors.New("too large")
+	ErrUnsupportedType  = errors.New("unsupported type")
+	ErrUnprocessable    = errors.New("unprocessable")
+	ErrQuotaExceeded    = errors.New("quota exceeded")
+	ErrUnavailable      = errors.New("unavailable")
+)
+
+// Error is an error of one of the kinds above, its message is meant for the client
+type Error struct {
+	kind error
+	err  error
+}
+
+// newError creates an error of a kind, the message being formatted like fmt.Errorf so causes can be wrapped with %w
+func newError(kind error, format string, args ...interface{}) error {
+	return &Error{kind: kind, err: fmt.Errorf(format, args...)}
+}
+
+func (e *Error) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the cause of the error, if any
+func (e *Error) Unwrap() error {
+	return errors.Unwrap(e.err)
+}
+
+// Is reports whether the error is of the kind target
+func (e *Error) Is(target error) bool {
+	return target == e.kind
+}
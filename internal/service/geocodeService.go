@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"strconv"
+
+	"github.com/danizion/contact-app/internal/geocode"
+	"github.com/danizion/contact-app/internal/repository"
+	"github.com/danizion/contact-app/internal/storage/redis"
+)
+
+// GeocodeService resolves contact addresses to coordinates with a pluggable geocoding provider
+type GeocodeService struct {
+	repo     *repository.Repository
+	redis    *redis.Redis
+	provider geocode.Provider
+}
+
+// NewGeocodeService creates a new instance of GeocodeService, provider may be nil when geocoding is not configured
+func NewGeocodeService(db *sql.DB, redisClient *redis.Redis, provider geocode.Provider) *GeocodeService {
+	return &GeocodeService{
+		repo:     repository.NewRepository(db),
+		redis:    redisClient,
+		provider: provider,
+	}
+}
+
+// WithContext returns the service running its queries and cache calls under ctx, the context of a request so they stop with it
+func (s *GeocodeService) WithContext(ctx context.Context) *GeocodeService {
+	bound := *s
+	bound.repo = s.repo.WithContext(ctx)
+	bound.redis = s.redis.WithContext(ctx)
+	return &bound
+}
+
+// GeocodeInBackground geocodes the address of a contact without coordinates, does nothing when no provider is configured
+func (s *GeocodeService) GeocodeInBackground(userID, contactID int) {
+	if s.provider == nil {
+		return
+	}
+
+	go func() {
+		if err := s.geocodeContact(userID, contactID); err != nil {
+			log.Printf("Error geocoding contact %d: %v", contactID, err)
+		}
+	}()
+}
+
+func (s *GeocodeService) geocodeContact(userID, contactID int) error {
+	contact, err := s.repo.GetContactByID(userID, contactID)
+	if err != nil {
+		return err
+	}
+	// Contacts with explicit coordinates are left untouched
+	if contact == nil || contact.Address == "" || contact.Latitude != nil {
+		return nil
+	}
+
+	location, err := s.provider.Geocode(contact.Address)
+	if err != nil {
+		if errors.Is(err, geocode.ErrNoMatch) {
+			return nil
+		}
+		return err
+	}
+
+	if err := s.repo.SetContactLocation(userID, contactID, location.Latitude, location.Longitude); err != nil {
+		return err
+	}
+
+	if s.redis != nil {
+		return s.redis.InvalidateUserCache(strconv.Itoa(userID))
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/danizion/contact-app/internal/repository"
+	"github.com/danizion/contact-app/internal/storage/redis"
+)
+
+// GroupService handles contact groups and the bulk assignment of contacts to them
+type GroupService struct {
+	repo  *repository.Repository
+	redis *redis.Redis
+}
+
+// NewGroupService creates a new instance of GroupService
+func NewGroupService(db *sql.DB, redisClient *redis.Redis) *GroupService {
+	return &GroupService{
+		repo:  repository.NewRepository(db),
+		redis: redisClient,
+	}
+}
+
+// WithContext returns the service running its queries and cache calls under ctx, the context of a request so they stop with it
+func (s *GroupService) WithContext(ctx context.Context) *GroupService {
+	bound := *s
+	bound.repo = s.repo.WithContext(ctx)
+	bound.redis = s.redis.WithContext(ctx)
+	return &bound
+}
+
+// CreateGroup creates a new group for the user
+func (s *GroupService) CreateGroup(req dtos.CreateGroupRequestDto) (*dtos.GroupResponseDto, error) {
+	name := strings.TrimSpace(req.Name)
+	taken, err := s.repo.IsGroupNameTaken(req.UserID, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check group name: %w", err)
+	}
+	if taken {
+		return nil, newError(ErrConflict, constants.ErrGroupExists)
+	}
+
+	groupID, err := s.repo.CreateGroup(models.Group{UserID: req.UserID, Name: name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create group: %w", err)
+	}
+
+	group, err := s.repo.GetGroup(req.UserID, groupID)
+	if err != nil || group == nil {
+		return nil, fmt.Errorf("failed to get created group: %w", err)
+	}
+	result := toGroupDto(*group)
+	return &result, nil
+}
+
+// ListGroups returns the user's groups with their contact counts
+func (s *GroupService) ListGroups(userID int) ([]dtos.GroupResponseDto, error) {
+	groups, err := s.repo.GetGroupsByUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get groups: %w", err)
+	}
+
+	result := make([]dtos.GroupResponseDto, len(groups))
+	for i, group := range groups {
+		result[i] = toGroupDto(group)
+	}
+	return result, nil
+}
+
+// DeleteGroup deletes a group, its contacts are kept
+func (s *GroupService) DeleteGroup(userID, groupID int) error {
+	err := s.repo.DeleteGroup(userID, groupID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return newError(ErrNotFound, constants.ErrGroupNotFound)
+		}
+		return fmt.Errorf("failed to delete group: %w", err)
+	}
+	return s.invalidateGroupCache(userID, groupID)
+}
+
+// AddContactsToGroup attaches many contacts to a group at once
+func (s *GroupService) AddContactsToGroup(userID, groupID int, req dtos.BulkContactsRequestDto) (*dtos.BulkContactsResponseDto, error) {
+	return s.updateGroupContacts(userID, groupID, req, s.repo.AddContactsToGroup)
+}
+
+// RemoveContactsFromGroup detaches many contacts from a group at once
+func (s *GroupService) RemoveContactsFromGroup(userID, groupID int, req dtos.BulkContactsRequestDto) (*dtos.BulkContactsResponseDto, error) {
+	return s.updateGroupContacts(userID, groupID, req, s.repo.RemoveContactsFromGroup)
+}
+
+func (s *GroupService) updateGroupContacts(userID, groupID int, req dtos.BulkContactsRequestDto,
+	apply func(groupID int, contactIDs []int) (int, error)) (*dtos.BulkContactsResponseDto, error) {
+	group, err := s.repo.GetGroup(userID, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group: %w", err)
+	}
+	if group == nil {
+		return nil, newError(ErrNotFound, constants.ErrGroupNotFound)
+	}
+
+	contactIDs, err := validateBulkContacts(s.repo, userID, req.ContactIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	changed, err := apply(groupID, contactIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update group contacts: %w", err)
+	}
+	if changed > 0 {
+		if err := s.invalidateGroupCache(userID, groupID); err != nil {
+			return nil, err
+		}
+	}
+
+	return &dtos.BulkContactsResponseDto{Requested: len(contactIDs), Changed: changed}, nil
+}
+
+// invalidateGroupCache drops only the cached listings filtered by the group, other listings do not show group membership
+func (s *GroupService) invalidateGroupCache(userID, groupID int) error {
+	if s.redis == nil {
+		return nil
+	}
+	return s.redis.InvalidateUserCacheFilter(strconv.Itoa(userID), "group", strconv.Itoa(groupID))
+}
+
+// validateBulkContacts deduplicates contact IDs and checks in a single query that they all belong to the user
+func validateBulkContacts(repo *repository.Repository, userID int, contactIDs []int) ([]int, error) {
+	seen := make(map[int]bool, len(contactIDs))
+	unique := make([]int, 0, len(contactIDs))
+	for _, id := range contactIDs {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	sort.Ints(unique)
+
+	owned, err := repo.CountOwnedContacts(userID, unique)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check contacts: %w", err)
+	}
+	if owned != len(unique) {
+		return nil, newError(ErrNotFound, constants.ErrContactsNotOwned)
+	}
+	return unique, nil
+}
+
+func toGroupDto(group models.Group) dtos.GroupResponseDto {
+	return dtos.GroupResponseDto{
+		ID:           group.ID,
+		Name:         group.Name,
+		ContactCount: group.ContactCount,
+		CreatedAt:    group.CreatedAt,
+	}
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/models"
+)
+
+// LogInteraction records a call, meeting or message with a contact, the contact's last interaction follows it
+func (s *ContactService) LogInteraction(userID, contactID int, req dtos.LogInteractionRequestDto) (*dtos.InteractionDto, error) {
+	kind := strings.ToLower(strings.TrimSpace(req.Kind))
+	if !isInteractionKind(kind) {
+		return nil, newError(ErrInvalidInput, constants.ErrInvalidInteractionKind)
+	}
+	occurredAt := time.Now()
+	if req.OccurredAt != nil {
+		if req.OccurredAt.After(occurredAt) {
+			return nil, newError(ErrInvalidInput, constants.ErrInteractionInFuture)
+		}
+		occurredAt = *req.OccurredAt
+	}
+	if err := s.checkContactOwnership(userID, contactID); err != nil {
+		return nil, err
+	}
+
+	interaction, err := s.repo.CreateInteraction(models.ContactInteraction{
+		ContactID:  contactID,
+		UserID:     userID,
+		Kind:       kind,
+		Note:       strings.TrimSpace(req.Note),
+		OccurredAt: occurredAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to log interaction: %w", err)
+	}
+	s.invalidateInteractionCache(userID)
+
+	result := toInteractionDto(*interaction)
+	return &result, nil
+}
+
+// GetInteractions returns the interactions logged with a contact, the most recent first
+func (s *ContactService) GetInteractions(userID, contactID int) ([]dtos.InteractionDto, error) {
+	if err := s.checkContactOwnership(userID, contactID); err != nil {
+		return nil, err
+	}
+
+	interactions, err := s.repo.GetContactInteractions(contactID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get interactions: %w", err)
+	}
+
+	result := make([]dtos.InteractionDto, len(interactions))
+	for i, interaction := range interactions {
+		result[i] = toInteractionDto(interaction)
+	}
+	return result, nil
+}
+
+// recordContactView counts viewing a contact as an interaction with it, at most once per ContactViewResolution.
+// Failing to record it never fails the view
+func (s *ContactService) recordContactView(contact *models.Contact) {
+	now := time.Now()
+	updated, err := s.repo.TouchContactInteraction(contact.UserID, contact.ID, now, constants.ContactViewResolution)
+	if err != nil {
+		slog.Error("Failed to record contact view", "error", err, "contactID", contact.ID)
+		return
+	}
+	if updated {
+		contact.LastInteractedAt = &now
+		s.invalidateInteractionCache(contact.UserID)
+	}
+}
+
+// invalidateInteractionCache drops the cached contact pages of a user after a last interaction moved. It is not a
+// change of the contact: no audit log entry, webhook or change feed event
+func (s *ContactService) invalidateInteractionCache(userID int) {
+	if s.redis == nil {
+		return
+	}
+	if err := s.redis.InvalidateUserCache(strconv.Itoa(userID)); err != nil {
+		slog.Error("Failed to invalidate contacts cache", "error", err, "userID", userID)
+	}
+}
+
+// parseInactiveFor parses the inactive_for filter of the contact listings, a number of days ("90d") or a duration
+// ("36h"), 0 when empty
+func parseInactiveFor(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		count, err := strconv.Atoi(days)
+		if err != nil || count < 1 {
+			return 0, newError(ErrInvalidInput, constants.ErrInvalidInactiveFor)
+		}
+		return time.Duration(count) * 24 * time.Hour, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return 0, newError(ErrInvalidInput, constants.ErrInvalidInactiveFor)
+	}
+	return duration, nil
+}
+
+func isInteractionKind(kind string) bool {
+	for _, known := range constants.InteractionKinds {
+		if kind == known {
+			return true
+		}
+	}
+	return false
+}
+
+func toInteractionDto(interaction models.ContactInteraction) dtos.InteractionDto {
+	return dtos.InteractionDto{
+		ID:         interaction.ID,
+		ContactID:  interaction.ContactID,
+		Kind:       interaction.Kind,
+		Note:       interaction.Note,
+		OccurredAt: interaction.OccurredAt,
+		CreatedAt:  interaction.CreatedAt,
+	}
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+)
+
+// validateTimezone checks that timezone is a known IANA name, empty values are always accepted
+func validateTimezone(timezone string) error {
+	if timezone == "" {
+		return nil
+	}
+	if _, err := time.LoadLocation(timezone); err != nil || timezone == "Local" {
+		return newError(ErrInvalidInput, "%s: %q", constants.ErrInvalidTimezone, timezone)
+	}
+	return nil
+}
+
+// applyLocalTime fills the contacts' current local time and whether it is within working hours (weekdays 9-18),
+// it runs on every response since cached pages would otherwise carry a stale clock. Contacts without a timezone but
+// with a location get the estimated local time of their longitude
+func applyLocalTime(contacts []dtos.GetContactsResponseDto, now time.Time) {
+	for i := range contacts {
+		contacts[i].LocalTime = ""
+		contacts[i].WithinWorkingHours = nil
+		contacts[i].LocalTimeEstimated = false
+
+		var location *time.Location
+		switch {
+		case contacts[i].Timezone != "":
+			var err error
+			if location, err = time.LoadLocation(contacts[i].Timezone); err != nil {
+				continue
+			}
+		case contacts[i].Longitude != nil:
+			location = locationFromLongitude(*contacts[i].Longitude)
+			contacts[i].LocalTimeEstimated = true
+		default:
+			continue
+		}
+
+		local := now.In(location)
+		weekday := local.Weekday() != time.Saturday && local.Weekday() != time.Sunday
+		workingHours := weekday && local.Hour() >= constants.WorkingHoursStart && local.Hour() < constants.WorkingHoursEnd
+
+		contacts[i].LocalTime = local.Format(time.RFC3339)
+		contacts[i].WithinWorkingHours = &workingHours
+	}
+}
+
+// locationFromLongitude is the nautical time zone of a longitude, offset from UTC by one hour every 15 degrees. It
+// knows neither borders nor daylight saving time, so the local time it gives can be off by an hour or more
+func locationFromLongitude(longitude float64) *time.Location {
+	hours := int(math.Round(longitude / 15))
+	return time.FixedZone(fmt.Sprintf("UTC%+d", hours), hours*3600)
+}
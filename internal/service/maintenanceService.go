@@ -0,0 +1,293 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/danizion/contact-app/internal/repository"
+	"github.com/danizion/contact-app/internal/storage/redis"
+)
+
+// MaintenanceService rebuilds the data derived from the contacts when it drifted from them: search vectors,
+// normalized phone numbers, per-user totals and the caches of the most active users. Operations run from the command
+// line or as background jobs of the admin API, which are only known to the replica running them
+type MaintenanceService struct {
+	repo     *repository.Repository
+	redis    *redis.Redis
+	contacts *ContactService
+	// phoneRegions give the region phone numbers without a country code are normalized in
+	phoneRegions []phoneRegionSource
+
+	mu   sync.Mutex
+	jobs map[string]*dtos.MaintenanceJobDto
+}
+
+// NewMaintenanceService creates a new instance of MaintenanceService
+func NewMaintenanceService(db *sql.DB, redisClient *redis.Redis) *MaintenanceService {
+	repo := repository.NewRepository(db)
+	return &MaintenanceService{
+		repo:         repo,
+		redis:        redisClient,
+		contacts:     NewContactService(db, redisClient),
+		phoneRegions: defaultPhoneRegions(repo),
+		jobs:         make(map[string]*dtos.MaintenanceJobDto),
+	}
+}
+
+// validateMaintenanceOperation fails for unknown operations and for numbers of users warm-cache cannot warm, top
+// being 0 for the default
+func validateMaintenanceOperation(operation string, top int) error {
+	switch operation {
+	case constants.MaintenanceReindexSearch, constants.MaintenanceNormalizePhones, constants.MaintenanceRecount:
+		return nil
+	case constants.MaintenanceWarmCache:
+		if top < 0 || top > constants.MaxWarmCacheUsers {
+			return newError(ErrInvalidInput, constants.ErrInvalidWarmCacheUsers)
+		}
+		return nil
+	}
+	return newError(ErrInvalidInput, constants.ErrUnknownMaintenanceOperation)
+}
+
+// Run runs a maintenance operation until it is done, top is the number of users warm-cache warms the caches of (the
+// default when 0). Operations work by batches, what a failed operation rebuilt before failing stays rebuilt and is
+// counted in the result
+func (s *MaintenanceService) Run(operation string, top int) (*dtos.MaintenanceResultDto, error) {
+	if err := validateMaintenanceOperation(operation, top); err != nil {
+		return nil, err
+	}
+
+	result := &dtos.MaintenanceResultDto{Operation: operation}
+	var err error
+	switch operation {
+	case constants.MaintenanceReindexSearch:
+		err = s.reindexSearch(result)
+	case constants.MaintenanceNormalizePhones:
+		err = s.normalizePhones(result)
+	case constants.MaintenanceRecount:
+		err = s.recount(result)
+	case constants.MaintenanceWarmCache:
+		if top == 0 {
+			top = constants.DefaultWarmCacheUsers
+		}
+		err = s.warmCache(result, top)
+	}
+	return result, err
+}
+
+// reindexSearch recomputes the search vector of every contact and drops the cached listings of their owners
+func (s *MaintenanceService) reindexSearch(result *dtos.MaintenanceResultDto) error {
+	owners := make(map[int]bool)
+	defer s.invalidateListings(owners, result)
+
+	afterID := 0
+	for {
+		contacts, err := s.repo.RebuildContactSearch(afterID, constants.MaintenanceBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to reindex contacts: %w", err)
+		}
+		for _, contact := range contacts {
+			owners[contact.UserID] = true
+			afterID = max(afterID, contact.ID)
+		}
+		result.Scanned += len(contacts)
+		result.Updated += len(contacts)
+		if len(contacts) < constants.MaintenanceBatchSize {
+			return nil
+		}
+	}
+}
+
+// normalizePhones writes the normalized phone number of the contacts whose number reads differently in the current
+// region of their contact or owner. Normalized numbers are not part of the listings, no cache is dropped
+func (s *MaintenanceService) normalizePhones(result *dtos.MaintenanceResultDto) error {
+	// The region of each owner is resolved once per run
+	regions := make(map[int]string)
+	afterID := 0
+	for {
+		contacts, err := s.repo.GetContactPhones(afterID, constants.MaintenanceBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to get contact phones: %w", err)
+		}
+
+		var stale []models.Contact
+		for _, contact := range contacts {
+			afterID = contact.ID
+			region, resolved := regions[contact.UserID]
+			if !resolved {
+				if region, err = resolvePhoneRegion(s.phoneRegions, contact.UserID, ""); err != nil {
+					return err
+				}
+				regions[contact.UserID] = region
+			}
+			if normalized := storedPhoneNormalized(contact, region); normalized != contact.PhoneNormalized {
+				contact.PhoneNormalized = normalized
+				stale = append(stale, contact)
+			}
+		}
+		result.Scanned += len(contacts)
+
+		if len(stale) > 0 {
+			updated, err := s.repo.SetContactPhonesNormalized(stale)
+			if err != nil {
+				return fmt.Errorf("failed to write normalized phone numbers: %w", err)
+			}
+			result.Updated += updated
+		}
+		if len(contacts) < constants.MaintenanceBatchSize {
+			return nil
+		}
+	}
+}
+
+// recount recomputes the totals of every user and drops their cached contact totals, which are counted again on the
+// next read
+func (s *MaintenanceService) recount(result *dtos.MaintenanceResultDto) error {
+	afterID := 0
+	for {
+		scanned, fixed, err := s.repo.RecountUserCounts(afterID, constants.MaintenanceBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to recount users: %w", err)
+		}
+		result.Scanned += len(scanned)
+		result.Updated += len(fixed)
+
+		if s.redis != nil {
+			for _, userID := range scanned {
+				if err := s.redis.ForgetContactTotal(userID); err != nil {
+					slog.Error("Failed to forget contact total", "error", err, "userID", userID)
+					continue
+				}
+				result.Users++
+			}
+		}
+		if len(scanned) < constants.MaintenanceBatchSize {
+			return nil
+		}
+		afterID = scanned[len(scanned)-1]
+	}
+}
+
+// warmCache fills the cached first contact page and contact total of the top users by changes made over the last
+// days, as their first request after a deploy or a cache flush would
+func (s *MaintenanceService) warmCache(result *dtos.MaintenanceResultDto, top int) error {
+	if s.redis == nil {
+		return newError(ErrUnavailable, constants.ErrCacheUnavailable)
+	}
+	userIDs, err := s.repo.GetMostActiveUsers(time.Now().Add(-constants.WarmCacheActivityWindow), top)
+	if err != nil {
+		return fmt.Errorf("failed to get most active users: %w", err)
+	}
+	result.Scanned = len(userIDs)
+
+	for _, userID := range userIDs {
+		page := dtos.GetContactRequestDto{UserID: userID, Page: 1, PageSize: constants.DefaultPageSize}
+		if _, err := s.contacts.GetContacts(page); err != nil {
+			return fmt.Errorf("failed to warm the contacts of user %d: %w", userID, err)
+		}
+		if _, err := s.contacts.CountContacts(userID, 0); err != nil {
+			return fmt.Errorf("failed to warm the contact count of user %d: %w", userID, err)
+		}
+		result.Users++
+	}
+	return nil
+}
+
+// invalidateListings drops the cached contact listings of users and counts them in result
+func (s *MaintenanceService) invalidateListings(userIDs map[int]bool, result *dtos.MaintenanceResultDto) {
+	if s.redis == nil {
+		return
+	}
+	for userID := range userIDs {
+		if err := s.redis.InvalidateUserCache(strconv.Itoa(userID)); err != nil {
+			slog.Error("Failed to invalidate contacts cache", "error", err, "userID", userID)
+			continue
+		}
+		result.Users++
+	}
+}
+
+// StartJob runs a maintenance operation in the background and returns its job. An operation runs once at a time on
+// a replica, finished jobs are forgotten after MaintenanceJobRetention
+func (s *MaintenanceService) StartJob(req dtos.StartMaintenanceJobRequestDto) (*dtos.MaintenanceJobDto, error) {
+	if err := validateMaintenanceOperation(req.Operation, req.Top); err != nil {
+		return nil, err
+	}
+	id, err := randomToken("", 8)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for jobID, job := range s.jobs {
+		if job.Status == constants.MaintenanceJobRunning && job.Operation == req.Operation {
+			return nil, newError(ErrConflict, constants.ErrMaintenanceJobRunning)
+		}
+		if job.FinishedAt != nil && now.Sub(*job.FinishedAt) > constants.MaintenanceJobRetention {
+			delete(s.jobs, jobID)
+		}
+	}
+
+	job := &dtos.MaintenanceJobDto{ID: id, Operation: req.Operation, Status: constants.MaintenanceJobRunning, StartedAt: now}
+	s.jobs[id] = job
+	go s.runJob(job, req.Top)
+
+	started := *job
+	return &started, nil
+}
+
+// runJob runs the operation of a job and records its outcome
+func (s *MaintenanceService) runJob(job *dtos.MaintenanceJobDto, top int) {
+	slog.Info("Maintenance job started", "job", job.ID, "operation", job.Operation)
+	result, err := s.Run(job.Operation, top)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	finished := time.Now()
+	job.FinishedAt = &finished
+	job.Result = result
+	if err != nil {
+		job.Status, job.Error = constants.MaintenanceJobFailed, err.Error()
+		slog.Error("Maintenance job failed", "job", job.ID, "operation", job.Operation, "error", err)
+		return
+	}
+	job.Status = constants.MaintenanceJobSucceeded
+	slog.Info("Maintenance job finished", "job", job.ID, "operation", job.Operation, "duration", finished.Sub(job.StartedAt),
+		"scanned", result.Scanned, "updated", result.Updated)
+}
+
+// GetJob returns a maintenance job of the replica
+func (s *MaintenanceService) GetJob(id string) (*dtos.MaintenanceJobDto, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, newError(ErrNotFound, constants.ErrMaintenanceJobNotFound)
+	}
+	found := *job
+	return &found, nil
+}
+
+// ListJobs returns the maintenance jobs of the replica, most recent first
+func (s *MaintenanceService) ListJobs() *dtos.MaintenanceJobListResponseDto {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := &dtos.MaintenanceJobListResponseDto{Items: make([]dtos.MaintenanceJobDto, 0, len(s.jobs))}
+	for _, job := range s.jobs {
+		list.Items = append(list.Items, *job)
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].StartedAt.After(list.Items[j].StartedAt)
+	})
+	return list
+}
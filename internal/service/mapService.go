@@ -0,0 +1,117 @@
+package service
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/danizion/contact-app/internal/repository"
+	"github.com/danizion/contact-app/internal/utils"
+)
+
+// GetContactsGeoJSON returns the user's geocoded contacts matching the filters as a GeoJSON FeatureCollection,
+// above the cluster threshold nearby contacts are grouped into one feature per grid cell sized by the zoom level
+func (s *ContactService) GetContactsGeoJSON(req dtos.ContactsGeoJSONRequestDto) (*dtos.GeoJSONFeatureCollectionDto, error) {
+	filter := repository.ContactFilter{
+		FirstName:    req.FirstName,
+		LastName:     req.LastName,
+		PhoneNumber:  req.PhoneNumber,
+		Address:      req.Address,
+		SocialHandle: req.Social,
+		GroupID:      req.GroupID,
+	}
+	contacts, err := s.repo.GetGeocodedContacts(req.UserID, filter, constants.MaxGeoJSONContacts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get geocoded contacts: %w", err)
+	}
+
+	result := &dtos.GeoJSONFeatureCollectionDto{
+		Type:     "FeatureCollection",
+		Features: []dtos.GeoJSONFeatureDto{},
+	}
+
+	threshold := utils.GetEnvIntOrDefault("GEOJSON_CLUSTER_THRESHOLD", constants.DefaultGeoJSONClusterThreshold)
+	if len(contacts) <= threshold {
+		for _, contact := range contacts {
+			result.Features = append(result.Features, contactFeature(contact))
+		}
+		return result, nil
+	}
+
+	result.Clustered = true
+	for _, cluster := range clusterContacts(contacts, req.Zoom) {
+		if len(cluster) == 1 {
+			result.Features = append(result.Features, contactFeature(cluster[0]))
+			continue
+		}
+		result.Features = append(result.Features, clusterFeature(cluster))
+	}
+	return result, nil
+}
+
+// clusterContacts groups contacts by grid cell, cells halve in size with every zoom level (45 degrees at zoom 0)
+func clusterContacts(contacts []models.Contact, zoom int) [][]models.Contact {
+	cellSize := 360 / math.Pow(2, float64(zoom+3))
+
+	type cell struct{ x, y int }
+	var order []cell
+	clusters := make(map[cell][]models.Contact)
+	for _, contact := range contacts {
+		key := cell{
+			x: int(math.Floor(*contact.Longitude / cellSize)),
+			y: int(math.Floor(*contact.Latitude / cellSize)),
+		}
+		if _, ok := clusters[key]; !ok {
+			order = append(order, key)
+		}
+		clusters[key] = append(clusters[key], contact)
+	}
+
+	result := make([][]models.Contact, len(order))
+	for i, key := range order {
+		result[i] = clusters[key]
+	}
+	return result
+}
+
+func contactFeature(contact models.Contact) dtos.GeoJSONFeatureDto {
+	return dtos.GeoJSONFeatureDto{
+		Type:     "Feature",
+		Geometry: geoJSONPoint(*contact.Latitude, *contact.Longitude),
+		Properties: map[string]interface{}{
+			"id":         contact.ID,
+			"first_name": contact.FirstName,
+			"last_name":  contact.LastName,
+			"company":    contact.Company,
+			"address":    contact.Address,
+		},
+	}
+}
+
+// clusterFeature places a cluster at the centroid of its contacts
+func clusterFeature(contacts []models.Contact) dtos.GeoJSONFeatureDto {
+	var latitude, longitude float64
+	for _, contact := range contacts {
+		latitude += *contact.Latitude
+		longitude += *contact.Longitude
+	}
+	count := float64(len(contacts))
+
+	return dtos.GeoJSONFeatureDto{
+		Type:     "Feature",
+		Geometry: geoJSONPoint(latitude/count, longitude/count),
+		Properties: map[string]interface{}{
+			"cluster":     true,
+			"point_count": len(contacts),
+		},
+	}
+}
+
+func geoJSONPoint(latitude, longitude float64) dtos.GeoJSONPointDto {
+	return dtos.GeoJSONPointDto{
+		Type:        "Point",
+		Coordinates: [2]float64{longitude, latitude},
+	}
+}
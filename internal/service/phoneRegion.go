@@ -0,0 +1,107 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/danizion/contact-app/internal/address"
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/danizion/contact-app/internal/phone"
+	"github.com/danizion/contact-app/internal/repository"
+	"github.com/danizion/contact-app/internal/utils"
+)
+
+// phoneRegionSource gives the region the phone numbers a user enters without a country code are read in, empty when
+// it has none
+type phoneRegionSource func(userID int) (string, error)
+
+// defaultPhoneRegions are the sources of the region of phone numbers, most specific first: the region of the
+// preferences of the user, then the one of the deployment
+func defaultPhoneRegions(repo *repository.Repository) []phoneRegionSource {
+	return []phoneRegionSource{preferredPhoneRegion(repo), fixedPhoneRegion(deploymentPhoneRegion())}
+}
+
+// preferredPhoneRegion reads the region of the preferences of the user
+func preferredPhoneRegion(repo *repository.Repository) phoneRegionSource {
+	return func(userID int) (string, error) {
+		prefs, err := repo.GetUserPreferences(userID)
+		if err != nil {
+			return "", fmt.Errorf("failed to get preferences: %w", err)
+		}
+		return prefs.Region, nil
+	}
+}
+
+// fixedPhoneRegion gives the same region for every user
+func fixedPhoneRegion(region string) phoneRegionSource {
+	return func(int) (string, error) {
+		return region, nil
+	}
+}
+
+// deploymentPhoneRegion returns the DEFAULT_PHONE_REGION of the deployment, empty when it is not set or invalid
+func deploymentPhoneRegion() string {
+	region := utils.GetEnvOrDefault("DEFAULT_PHONE_REGION", "")
+	if region == "" {
+		return ""
+	}
+	normalized, err := address.NormalizeCountryCode(region)
+	if err != nil {
+		slog.Warn("Ignoring invalid DEFAULT_PHONE_REGION", "error", err)
+		return ""
+	}
+	return normalized
+}
+
+// resolvePhoneRegion returns the region the phone number of a contact of a user is read in: the country of the
+// contact, else the region of the first source having one
+func resolvePhoneRegion(sources []phoneRegionSource, userID int, countryCode string) (string, error) {
+	if countryCode != "" {
+		return countryCode, nil
+	}
+	for _, source := range sources {
+		region, err := source(userID)
+		if err != nil {
+			return "", err
+		}
+		if region != "" {
+			return region, nil
+		}
+	}
+	return "", nil
+}
+
+// validatePhoneNumber fails when a phone number cannot be normalized, national numbers being read as numbers of
+// region. Without a region, or with one whose numbers are only understood in international form, numbers are kept
+// as entered
+func validatePhoneNumber(number, region string) error {
+	if !phone.KnownRegion(region) {
+		return nil
+	}
+	if _, ok := phone.Normalize(number, region); !ok {
+		return newError(ErrInvalidInput, "%s %q for region %s", constants.ErrInvalidPhoneNumber, number, region)
+	}
+	return nil
+}
+
+// normalizedPhone returns the E.164 form of a phone number, national numbers being read as numbers of region, empty
+// when it cannot be normalized
+func normalizedPhone(number, region string) string {
+	e164, _ := phone.Normalize(number, region)
+	return e164
+}
+
+// storedPhoneNormalized returns the E.164 form of the phone number of a stored contact, read in the country of the
+// contact or else in ownerRegion, the region resolved for its owner
+func storedPhoneNormalized(contact models.Contact, ownerRegion string) string {
+	return normalizedPhone(contact.PhoneNumber, firstNonEmpty(contact.CountryCode, ownerRegion))
+}
+
+// normalizeImportedPhones sets the normalized form of the phone numbers of contacts imported for a user, ownerRegion
+// being the region resolved for the user
+func normalizeImportedPhones(contacts []repository.ArchivedContact, ownerRegion string) {
+	for i := range contacts {
+		contacts[i].Contact.PhoneNormalized = storedPhoneNormalized(contacts[i].Contact, ownerRegion)
+	}
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/danizion/contact-app/internal/repository"
+)
+
+// PicklistService handles the admin-managed allowed values of picklist fields (source, stage)
+type PicklistService struct {
+	repo *repository.Repository
+}
+
+// NewPicklistService creates a new instance of PicklistService
+func NewPicklistService(db *sql.DB) *PicklistService {
+	return &PicklistService{
+		repo: repository.NewRepository(db),
+	}
+}
+
+// WithContext returns the service running its queries under ctx, the context of a request so they stop with it
+func (s *PicklistService) WithContext(ctx context.Context) *PicklistService {
+	bound := *s
+	bound.repo = s.repo.WithContext(ctx)
+	return &bound
+}
+
+// GetPicklist returns the allowed values of a picklist field
+func (s *PicklistService) GetPicklist(field string) (*dtos.PicklistResponseDto, error) {
+	if !constants.IsPicklistField(field) {
+		return nil, newError(ErrNotFound, constants.ErrUnknownPicklistField)
+	}
+
+	values, err := s.repo.GetPicklistValues(field)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get picklist values: %w", err)
+	}
+
+	result := &dtos.PicklistResponseDto{
+		Field:  field,
+		Values: make([]string, len(values)),
+	}
+	for i, value := range values {
+		result.Values[i] = value.Value
+	}
+	return result, nil
+}
+
+// AddPicklistValue adds a new allowed value to a picklist field
+func (s *PicklistService) AddPicklistValue(req dtos.CreatePicklistValueRequestDto) error {
+	if !constants.IsPicklistField(req.Field) {
+		return newError(ErrNotFound, constants.ErrUnknownPicklistField)
+	}
+
+	value := strings.TrimSpace(req.Value)
+	exists, err := s.repo.IsPicklistValueAllowed(req.Field, value)
+	if err != nil {
+		return fmt.Errorf("failed to check picklist value: %w", err)
+	}
+	if exists {
+		return newError(ErrConflict, constants.ErrPicklistValueExists)
+	}
+
+	_, err = s.repo.CreatePicklistValue(models.PicklistValue{
+		Field:    req.Field,
+		Value:    value,
+		Position: req.Position,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add picklist value: %w", err)
+	}
+	return nil
+}
+
+// RemovePicklistValue removes an allowed value from a picklist field, contacts already using it keep their value
+func (s *PicklistService) RemovePicklistValue(field, value string) error {
+	if !constants.IsPicklistField(field) {
+		return newError(ErrNotFound, constants.ErrUnknownPicklistField)
+	}
+
+	err := s.repo.DeletePicklistValue(field, value)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return newError(ErrNotFound, constants.ErrPicklistValueMissing)
+		}
+		return fmt.Errorf("failed to remove picklist value: %w", err)
+	}
+	return nil
+}
+
+// validatePicklistValue checks value against the allowed values of field, empty values are always accepted
+func validatePicklistValue(repo *repository.Repository, field, value string) error {
+	if value == "" {
+		return nil
+	}
+
+	allowed, err := repo.IsPicklistValueAllowed(field, value)
+	if err != nil {
+		return fmt.Errorf("failed to validate %s: %w", field, err)
+	}
+	if !allowed {
+		return newError(ErrInvalidInput, "%s: %s %q", constants.ErrInvalidPicklistValue, field, value)
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/danizion/contact-app/internal/address"
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/danizion/contact-app/internal/repository"
+)
+
+// PreferencesService handles the settings of users
+type PreferencesService struct {
+	repo *repository.Repository
+	// defaultRegion is the region of the deployment, for users who did not choose one
+	defaultRegion string
+}
+
+// NewPreferencesService creates a new instance of PreferencesService
+func NewPreferencesService(db *sql.DB) *PreferencesService {
+	return &PreferencesService{
+		repo:          repository.NewRepository(db),
+		defaultRegion: deploymentPhoneRegion(),
+	}
+}
+
+// WithContext returns the service running its queries under ctx, the context of a request so they stop with it
+func (s *PreferencesService) WithContext(ctx context.Context) *PreferencesService {
+	bound := *s
+	bound.repo = s.repo.WithContext(ctx)
+	return &bound
+}
+
+// DefaultPhoneRegion returns the region phone numbers are read and displayed in for users without one, empty when
+// the deployment has none
+func (s *PreferencesService) DefaultPhoneRegion() string {
+	return s.defaultRegion
+}
+
+// GetPreferences returns the preferences of a user
+func (s *PreferencesService) GetPreferences(userID int) (*dtos.PreferencesResponseDto, error) {
+	prefs, err := s.repo.GetUserPreferences(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get preferences: %w", err)
+	}
+	return toPreferencesDto(prefs), nil
+}
+
+// UpdatePreferences changes the given preferences of a user and returns the result
+func (s *PreferencesService) UpdatePreferences(req dtos.UpdatePreferencesRequestDto) (*dtos.PreferencesResponseDto, error) {
+	prefs, err := s.repo.GetUserPreferences(req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get preferences: %w", err)
+	}
+
+	if req.WeeklyDigest != nil {
+		prefs.WeeklyDigest = *req.WeeklyDigest
+	}
+	if req.Region != nil {
+		if prefs.Region, err = normalizeRegion(*req.Region); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.SearchLanguage != nil {
+		if prefs.SearchLanguage, err = normalizeSearchLanguage(s.repo, *req.SearchLanguage); err != nil {
+			return nil, err
+		}
+	}
+	if req.DuplicateSensitivity != nil {
+		if prefs.DuplicateSensitivity, err = normalizeDuplicateSensitivity(*req.DuplicateSensitivity); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.SaveUserPreferences(*prefs); err != nil {
+		return nil, fmt.Errorf("failed to save preferences: %w", err)
+	}
+	return toPreferencesDto(prefs), nil
+}
+
+// notificationDefaults is the notification preferences matrix of users who changed none of it, by event and channel.
+// Changes to shared contacts are marked in the app only since every user would be emailed of every change
+var notificationDefaults = map[string]map[string]bool{
+	constants.NotificationSharedContactUpdated: {
+		constants.NotificationChannelEmail: false,
+		constants.NotificationChannelInApp: true,
+	},
+	constants.NotificationSharedContactReviewed: {
+		constants.NotificationChannelEmail: true,
+	},
+}
+
+// GetNotificationPreferences returns the notification preferences matrix of a user, defaults included
+func (s *PreferencesService) GetNotificationPreferences(userID int) (*dtos.NotificationPreferencesDto, error) {
+	prefs, err := s.repo.GetNotificationPreferences(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+	return toNotificationPreferencesDto(prefs), nil
+}
+
+// UpdateNotificationPreferences turns the given cells of the notification preferences matrix of a user on or off
+// and returns the result, nothing is changed when a cell is unknown
+func (s *PreferencesService) UpdateNotificationPreferences(req dtos.UpdateNotificationPreferencesRequestDto) (*dtos.NotificationPreferencesDto, error) {
+	var changes []models.NotificationPreference
+	for event, channels := range req.Events {
+		defaults, ok := notificationDefaults[event]
+		if !ok {
+			return nil, newError(ErrInvalidInput, "%s: %s", constants.ErrUnknownNotificationEvent, event)
+		}
+		for channel, enabled := range channels {
+			if _, ok := defaults[channel]; !ok {
+				return nil, newError(ErrInvalidInput, "%s: %s", constants.ErrUnknownNotificationChannel, channel)
+			}
+			changes = append(changes, models.NotificationPreference{UserID: req.UserID, Event: event, Channel: channel, Enabled: enabled})
+		}
+	}
+
+	if len(changes) > 0 {
+		if err := s.repo.SaveNotificationPreferences(changes); err != nil {
+			return nil, fmt.Errorf("failed to save notification preferences: %w", err)
+		}
+	}
+	return s.GetNotificationPreferences(req.UserID)
+}
+
+// notificationEnabled reports whether a user is notified of an event on a channel
+func notificationEnabled(repo *repository.Repository, userID int, event, channel string) (bool, error) {
+	prefs, err := repo.GetNotificationPreferences(userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+	for _, pref := range prefs {
+		if pref.Event == event && pref.Channel == channel {
+			return pref.Enabled, nil
+		}
+	}
+	return notificationDefaults[event][channel], nil
+}
+
+func toNotificationPreferencesDto(prefs []models.NotificationPreference) *dtos.NotificationPreferencesDto {
+	result := &dtos.NotificationPreferencesDto{Events: make(map[string]map[string]bool, len(notificationDefaults))}
+	for event, defaults := range notificationDefaults {
+		result.Events[event] = make(map[string]bool, len(defaults))
+		for channel, enabled := range defaults {
+			result.Events[event][channel] = enabled
+		}
+	}
+	// Cells of events or channels since removed are left out
+	for _, pref := range prefs {
+		if _, ok := result.Events[pref.Event][pref.Channel]; ok {
+			result.Events[pref.Event][pref.Channel] = pref.Enabled
+		}
+	}
+	return result
+}
+
+func toPreferencesDto(prefs *models.UserPreferences) *dtos.PreferencesResponseDto {
+	return &dtos.PreferencesResponseDto{
+		WeeklyDigest:         prefs.WeeklyDigest,
+		Region:               prefs.Region,
+		SearchLanguage:       prefs.SearchLanguage,
+		DuplicateSensitivity: duplicateSensitivity(prefs),
+	}
+}
+
+// normalizeRegion validates the region of a user, an ISO 3166-1 alpha-2 country code or empty to unset it
+func normalizeRegion(region string) (string, error) {
+	if region == "" {
+		return "", nil
+	}
+	region, err := address.NormalizeCountryCode(region)
+	if err != nil {
+		return "", newError(ErrInvalidInput, "%s: %w", constants.ErrInvalidRegion, err)
+	}
+	return region, nil
+}
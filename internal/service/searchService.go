@@ -0,0 +1,99 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/repository"
+	"github.com/danizion/contact-app/internal/storage/redis"
+)
+
+// SearchService keeps the full-text search of contacts in the language of their owner
+type SearchService struct {
+	repo  *repository.Repository
+	redis *redis.Redis
+}
+
+// NewSearchService creates a new instance of SearchService
+func NewSearchService(db *sql.DB, redisClient *redis.Redis) *SearchService {
+	return &SearchService{
+		repo:  repository.NewRepository(db),
+		redis: redisClient,
+	}
+}
+
+// ApplyDefaultLanguage saves the search language of the deployment, used for the users without their own. Contacts
+// indexed in the previous one are reindexed by Reindex
+func (s *SearchService) ApplyDefaultLanguage(language string) error {
+	language, err := normalizeSearchLanguage(s.repo, language)
+	if err != nil {
+		return err
+	}
+	if language == "" {
+		language = constants.DefaultSearchLanguage
+	}
+
+	current, found, err := s.repo.GetInstanceSetting(constants.SettingSearchLanguage)
+	if err != nil {
+		return fmt.Errorf("failed to get search language: %w", err)
+	}
+	if found && current == language {
+		return nil
+	}
+	if err := s.repo.SaveInstanceSetting(constants.SettingSearchLanguage, language); err != nil {
+		return fmt.Errorf("failed to save search language: %w", err)
+	}
+	slog.Info("Search language changed, contacts will be reindexed", "from", current, "to", language)
+	return nil
+}
+
+// Reindex recomputes the search vector of the contacts indexed in another language than their owner's, by batches
+// until none is left. It runs periodically, picking up language changes of users and of the deployment, and
+// drops the cached listings of the users whose contacts were reindexed
+func (s *SearchService) Reindex() error {
+	reindexed := make(map[int]bool)
+	for {
+		userIDs, err := s.repo.ReindexContactSearch(constants.SearchReindexBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to reindex contacts: %w", err)
+		}
+		for _, userID := range userIDs {
+			reindexed[userID] = true
+		}
+		if len(userIDs) < constants.SearchReindexBatchSize {
+			break
+		}
+	}
+
+	if s.redis != nil {
+		for userID := range reindexed {
+			if err := s.redis.InvalidateUserCache(strconv.Itoa(userID)); err != nil {
+				slog.Error("Failed to invalidate contacts cache", "error", err, "userID", userID)
+			}
+		}
+	}
+	return nil
+}
+
+// normalizeSearchLanguage validates a search language, the name of a text search configuration of the database, or
+// empty to use the deployment one
+func normalizeSearchLanguage(repo *repository.Repository, language string) (string, error) {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if language == "" {
+		return "", nil
+	}
+	languages, err := repo.GetSearchLanguages()
+	if err != nil {
+		return "", fmt.Errorf("failed to get search languages: %w", err)
+	}
+	for _, known := range languages {
+		if language == known {
+			return language, nil
+		}
+	}
+	return "", newError(ErrInvalidInput, "%s %q, expected one of %s", constants.ErrInvalidSearchLanguage, language, strings.Join(languages, ", "))
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/danizion/contact-app/internal/accountstate"
+	"github.com/danizion/contact-app/internal/auth"
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/danizion/contact-app/internal/repository"
+	"github.com/danizion/contact-app/internal/storage/redis"
+)
+
+// SessionService issues access and refresh tokens and revokes them, it backs the JWT authentication of the middlewares.
+// Refresh tokens are only kept in Redis as hashes and are single use, each refresh rotates them
+type SessionService struct {
+	repo  *repository.Repository
+	redis *redis.Redis
+}
+
+// NewSessionService creates a new instance of SessionService
+func NewSessionService(db *sql.DB, redisClient *redis.Redis) *SessionService {
+	return &SessionService{
+		repo:  repository.NewRepository(db),
+		redis: redisClient,
+	}
+}
+
+// WithContext returns the service running its queries and cache calls under ctx, the context of a request so they stop with it
+func (s *SessionService) WithContext(ctx context.Context) *SessionService {
+	bound := *s
+	bound.repo = s.repo.WithContext(ctx)
+	bound.redis = s.redis.WithContext(ctx)
+	return &bound
+}
+
+// CreateSession issues the tokens of a user who just logged in
+func (s *SessionService) CreateSession(user *models.User) (*dtos.LoginResponseDto, error) {
+	generation, err := s.redis.SessionGeneration(user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session generation: %w", err)
+	}
+	return s.issueTokens(user, generation)
+}
+
+// Refresh exchanges a refresh token for a new access token and a new refresh token. Unknown, expired, already used
+// and revoked refresh tokens are rejected with ErrInvalidRefreshToken
+func (s *SessionService) Refresh(refreshToken string) (*dtos.LoginResponseDto, error) {
+	token, err := s.redis.TakeRefreshToken(hashRefreshToken(refreshToken))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read refresh token: %w", err)
+	}
+	if token == nil {
+		return nil, newError(ErrUnauthorized, constants.ErrInvalidRefreshToken)
+	}
+
+	generation, err := s.redis.SessionGeneration(token.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session generation: %w", err)
+	}
+	if token.Generation < generation {
+		return nil, newError(ErrUnauthorized, constants.ErrInvalidRefreshToken)
+	}
+
+	// Reload the user so a removed account or a changed admin flag is reflected in the new access token
+	user, err := s.repo.GetUser(token.UserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, newError(ErrUnauthorized, constants.ErrInvalidRefreshToken)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh session: %w", err)
+	}
+	if !accountstate.CanUseAPI(user.State) {
+		return nil, newError(ErrUnauthorized, constants.ErrInvalidRefreshToken)
+	}
+	return s.issueTokens(user, generation)
+}
+
+// Logout revokes the access token of the request, nil for API key requests, and the refresh token of the session when
+// given. A refresh token of another user is left alone
+func (s *SessionService) Logout(userID int, claims *auth.Claims, refreshToken string) error {
+	if claims != nil && claims.ID != "" {
+		if lifetime := claims.RemainingLifetime(); lifetime > 0 {
+			if err := s.redis.DenyToken(claims.ID, lifetime); err != nil {
+				return fmt.Errorf("failed to revoke access token: %w", err)
+			}
+		}
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+	hash := hashRefreshToken(refreshToken)
+	token, err := s.redis.TakeRefreshToken(hash)
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+	if token != nil && token.UserID != userID {
+		// Put it back, only its owner can end that session
+		if err := s.redis.SaveRefreshToken(hash, *token, time.Until(token.IssuedAt.Add(auth.RefreshTokenTTL))); err != nil {
+			return fmt.Errorf("failed to restore refresh token: %w", err)
+		}
+	}
+	return nil
+}
+
+// RevokeSessions revokes every access and refresh token of a user and issues the tokens of a new session,
+// so the client revoking the others stays logged in
+func (s *SessionService) RevokeSessions(user *models.User) (*dtos.LoginResponseDto, error) {
+	generation, err := s.redis.RevokeSessions(user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return s.issueTokens(user, generation)
+}
+
+// IsTokenRevoked reports whether an access token was revoked by a logout or by revoking the sessions of its user
+func (s *SessionService) IsTokenRevoked(claims *auth.Claims) (bool, error) {
+	return s.redis.IsTokenRevoked(claims.ID, claims.UserID, claims.Generation)
+}
+
+func (s *SessionService) issueTokens(user *models.User, generation int) (*dtos.LoginResponseDto, error) {
+	accessToken, err := auth.GenerateJWT(user.ID, user.Username, user.IsAdmin, generation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate authentication token: %w", err)
+	}
+
+	random, err := auth.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken := "rt_" + random
+	err = s.redis.SaveRefreshToken(hashRefreshToken(refreshToken),
+		redis.RefreshToken{UserID: user.ID, Generation: generation, IssuedAt: time.Now()}, auth.RefreshTokenTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save refresh token: %w", err)
+	}
+
+	return &dtos.LoginResponseDto{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(auth.AccessTokenTTL.Seconds()),
+		UserID:       user.ID,
+	}, nil
+}
+
+// hashRefreshToken is the key a refresh token is stored under, so a leak of Redis does not leak usable tokens
+func hashRefreshToken(refreshToken string) string {
+	sum := sha256.Sum256([]byte(refreshToken))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,411 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/events"
+	"github.com/danizion/contact-app/internal/mail"
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/danizion/contact-app/internal/policy"
+	"github.com/danizion/contact-app/internal/repository"
+	"github.com/danizion/contact-app/internal/utils"
+)
+
+// sharedContactReviewData is the data rendered by the review email template
+type sharedContactReviewData struct {
+	Username    string
+	ContactName string
+	Approved    bool
+	Note        string
+	Link        string
+}
+
+// sharedContactUpdatedData is the data rendered by the shared contact update email template
+type sharedContactUpdatedData struct {
+	Username    string
+	ContactName string
+	Owner       string
+	// Fields are the changed fields, joined
+	Fields string
+	Link   string
+}
+
+// SharedBookService handles the address book shared with every user of the deployment. Users submit their own
+// contacts to it; with review enabled the submissions of members wait in a queue until an admin or an editor approves
+// or rejects them, and the submitter is told by email
+type SharedBookService struct {
+	repo   *repository.Repository
+	sender mail.Sender
+	// requireReview holds the submissions of members for approval, they are shared right away otherwise
+	requireReview bool
+	// editors are the emails of the users reviewing submissions besides admins
+	editors []string
+	// publicURL starts the links of the review emails
+	publicURL string
+}
+
+// NewSharedBookService creates a new instance of SharedBookService, submitters are not told of reviews without a
+// sender
+func NewSharedBookService(db *sql.DB, sender mail.Sender) *SharedBookService {
+	var editors []string
+	for _, email := range strings.Split(utils.GetEnvOrDefault("SHARED_BOOK_EDITORS", ""), ",") {
+		if email = strings.TrimSpace(email); email != "" {
+			editors = append(editors, email)
+		}
+	}
+	return &SharedBookService{
+		repo:          repository.NewRepository(db),
+		sender:        sender,
+		requireReview: utils.GetEnvOrDefault("SHARED_BOOK_REVIEW", "false") == "true",
+		editors:       editors,
+		publicURL:     strings.TrimSuffix(utils.GetEnvOrDefault("PUBLIC_URL", constants.DefaultPublicURL), "/"),
+	}
+}
+
+// WithContext returns the service running its queries under ctx, the context of a request so they stop with it
+func (s *SharedBookService) WithContext(ctx context.Context) *SharedBookService {
+	bound := *s
+	bound.repo = s.repo.WithContext(ctx)
+	return &bound
+}
+
+// Submit adds a contact of the subject to the shared address book. It is pending review when review is enabled and
+// the subject does not review submissions, shared right away otherwise. Submitting a rejected contact again queues
+// it again
+func (s *SharedBookService) Submit(subject policy.Subject, contactID int) (*dtos.SharedContactDto, error) {
+	contact, err := s.repo.GetContactByID(subject.UserID, contactID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contact: %w", err)
+	}
+	if contact == nil {
+		return nil, newError(ErrNotFound, constants.ErrContactNotFound)
+	}
+
+	status := constants.SharedContactShared
+	if s.requireReview {
+		reviewer, err := s.isReviewer(subject)
+		if err != nil {
+			return nil, err
+		}
+		if !reviewer {
+			status = constants.SharedContactPending
+		}
+	}
+	if err := s.repo.SubmitSharedContact(contactID, status); err != nil {
+		return nil, fmt.Errorf("failed to submit contact: %w", err)
+	}
+
+	action := constants.AuditActionContactShared
+	if status == constants.SharedContactPending {
+		action = constants.AuditActionContactSubmitted
+	}
+	recordAudit(s.repo, subject.UserID, action, constants.AuditEntityContact, contactID, nil)
+	return s.get(contactID)
+}
+
+// Withdraw takes a contact of a user out of the shared address book, pending or shared
+func (s *SharedBookService) Withdraw(userID, contactID int) error {
+	contact, err := s.repo.GetContactByID(userID, contactID)
+	if err != nil {
+		return fmt.Errorf("failed to get contact: %w", err)
+	}
+	if contact == nil {
+		return newError(ErrNotFound, constants.ErrContactNotFound)
+	}
+	found, err := s.repo.RemoveSharedContact(contactID)
+	if err != nil {
+		return fmt.Errorf("failed to remove shared contact: %w", err)
+	}
+	if !found {
+		return newError(ErrNotFound, constants.ErrSharedContactNotFound)
+	}
+	recordAudit(s.repo, userID, constants.AuditActionContactUnshared, constants.AuditEntityContact, contactID, nil)
+	return nil
+}
+
+// ListShared returns the contacts visible to every user. When the user has the in-app shared_contact_updated
+// notification on, the contacts of others changed since the user last viewed them are marked updated
+func (s *SharedBookService) ListShared(userID int) (*dtos.SharedContactListResponseDto, error) {
+	contacts, err := s.repo.GetSharedContacts(constants.SharedContactShared)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shared contacts: %w", err)
+	}
+	result := toSharedContactList(contacts)
+
+	marked, err := notificationEnabled(s.repo, userID, constants.NotificationSharedContactUpdated, constants.NotificationChannelInApp)
+	if err != nil {
+		return nil, err
+	}
+	if !marked {
+		return result, nil
+	}
+	views, err := s.repo.GetSharedContactViews(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shared contact views: %w", err)
+	}
+	for i, contact := range contacts {
+		// Contacts never viewed are not marked, there is no earlier version the user knows of
+		viewedAt, viewed := views[contact.ID]
+		result.Items[i].UpdatedSinceViewed = viewed && contact.UserID != userID && contact.ChangedAt != nil &&
+			contact.ChangedAt.After(viewedAt)
+	}
+	return result, nil
+}
+
+// Get returns a contact of the shared address book and records that the user viewed it. Submitters see their own
+// submissions whatever their status, other users only the shared contacts
+func (s *SharedBookService) Get(userID, contactID int) (*dtos.SharedContactDto, error) {
+	contact, err := s.repo.GetSharedContact(contactID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shared contact: %w", err)
+	}
+	if contact == nil || (contact.Status != constants.SharedContactShared && contact.UserID != userID) {
+		return nil, newError(ErrNotFound, constants.ErrSharedContactNotFound)
+	}
+	if contact.Status == constants.SharedContactShared {
+		if err := s.repo.RecordSharedContactView(userID, contactID); err != nil {
+			return nil, fmt.Errorf("failed to record shared contact view: %w", err)
+		}
+	}
+	result := toSharedContactDto(*contact)
+	return &result, nil
+}
+
+// ListSubmissions returns the contacts a user submitted, with the outcome of their review
+func (s *SharedBookService) ListSubmissions(userID int) (*dtos.SharedContactListResponseDto, error) {
+	contacts, err := s.repo.GetSubmittedContacts(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get submitted contacts: %w", err)
+	}
+	return toSharedContactList(contacts), nil
+}
+
+// ListPending returns the review queue, the oldest submission first. Only reviewers see it
+func (s *SharedBookService) ListPending(subject policy.Subject) (*dtos.SharedContactListResponseDto, error) {
+	if err := s.authorizeReviewer(subject); err != nil {
+		return nil, err
+	}
+	contacts, err := s.repo.GetSharedContacts(constants.SharedContactPending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending contacts: %w", err)
+	}
+	return toSharedContactList(contacts), nil
+}
+
+// Approve makes a pending submission visible to every user and tells the submitter
+func (s *SharedBookService) Approve(subject policy.Subject, contactID int, req dtos.ReviewSharedContactRequestDto) (*dtos.SharedContactDto, error) {
+	return s.review(subject, contactID, constants.SharedContactShared, req.Note)
+}
+
+// Reject refuses a pending submission and tells the submitter, who may submit the contact again
+func (s *SharedBookService) Reject(subject policy.Subject, contactID int, req dtos.ReviewSharedContactRequestDto) (*dtos.SharedContactDto, error) {
+	return s.review(subject, contactID, constants.SharedContactRejected, req.Note)
+}
+
+// review decides a pending submission with status
+func (s *SharedBookService) review(subject policy.Subject, contactID int, status, note string) (*dtos.SharedContactDto, error) {
+	if err := s.authorizeReviewer(subject); err != nil {
+		return nil, err
+	}
+	contact, err := s.repo.GetSharedContact(contactID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shared contact: %w", err)
+	}
+	if contact == nil {
+		return nil, newError(ErrNotFound, constants.ErrSharedContactNotFound)
+	}
+	note = strings.TrimSpace(note)
+	reviewed, err := s.repo.ReviewSharedContact(contactID, subject.UserID, status, note)
+	if err != nil {
+		return nil, fmt.Errorf("failed to review shared contact: %w", err)
+	}
+	if !reviewed {
+		return nil, newError(ErrConflict, constants.ErrSubmissionNotPending)
+	}
+
+	action := constants.AuditActionContactShared
+	if status == constants.SharedContactRejected {
+		action = constants.AuditActionContactRejected
+	}
+	recordAuditBy(s.repo, subject.UserID, contact.UserID, action, constants.AuditEntityContact, contactID, map[string]interface{}{"note": note})
+	s.notifySubmitter(contact.Contact, status == constants.SharedContactShared, note)
+	return s.get(contactID)
+}
+
+// notifySubmitter emails the outcome of a review to the submitter, failures are logged and never fail the review
+func (s *SharedBookService) notifySubmitter(contact models.Contact, approved bool, note string) {
+	if s.sender == nil {
+		return
+	}
+	user, err := s.repo.GetUser(contact.UserID)
+	if err != nil || user == nil {
+		slog.Error("Failed to get the submitter of a shared contact", "error", err, "userID", contact.UserID)
+		return
+	}
+	notified, err := notificationEnabled(s.repo, user.ID, constants.NotificationSharedContactReviewed, constants.NotificationChannelEmail)
+	if err != nil {
+		slog.Error("Failed to get the notification preferences of a submitter", "error", err, "userID", user.ID)
+		return
+	}
+	if !notified {
+		return
+	}
+	msg, err := mail.Render("shared_contact_review", user.Email, sharedContactReviewData{
+		Username:    user.Username,
+		ContactName: strings.TrimSpace(contact.FirstName + " " + contact.LastName),
+		Approved:    approved,
+		Note:        note,
+		Link:        s.publicURL + "/shared-contacts/submissions",
+	})
+	if err == nil {
+		err = s.sender.Send(msg)
+	}
+	if err != nil {
+		slog.Error("Failed to send shared contact review email", "error", err, "userID", user.ID, "contactID", contact.ID)
+	}
+}
+
+// NotifyContactChange is the event handler marking the shared contacts changed by their owner, so other users see
+// them updated in the shared address book, and emailing the users with the shared_contact_updated email notification
+// on. Repairs made by maintenance (events with a source) are no change of the owner and are left out
+func (s *SharedBookService) NotifyContactChange(event events.Event) {
+	if event.ContactID == 0 || event.Details["source"] != nil {
+		return
+	}
+	shared, err := s.repo.MarkSharedContactChanged(event.ContactID)
+	if err != nil {
+		slog.Error("Failed to mark shared contact changed", "error", err, "contactID", event.ContactID)
+		return
+	}
+	if !shared || s.sender == nil {
+		return
+	}
+	go s.emailContactChange(event.UserID, event.ContactID, changedFields(event.Details))
+}
+
+// emailContactChange emails the change of a shared contact to the users notified of it, failures are logged
+func (s *SharedBookService) emailContactChange(ownerID, contactID int, fields []string) {
+	contact, err := s.repo.GetSharedContact(contactID)
+	if err != nil || contact == nil {
+		slog.Error("Failed to get changed shared contact", "error", err, "contactID", contactID)
+		return
+	}
+	owner, err := s.repo.GetUser(ownerID)
+	if err != nil || owner == nil {
+		slog.Error("Failed to get the owner of a shared contact", "error", err, "userID", ownerID)
+		return
+	}
+	users, err := s.repo.GetNotifiedUsers(constants.NotificationSharedContactUpdated, constants.NotificationChannelEmail,
+		notificationDefaults[constants.NotificationSharedContactUpdated][constants.NotificationChannelEmail], ownerID)
+	if err != nil {
+		slog.Error("Failed to get the users notified of shared contact changes", "error", err, "contactID", contactID)
+		return
+	}
+
+	data := sharedContactUpdatedData{
+		ContactName: strings.TrimSpace(contact.FirstName + " " + contact.LastName),
+		Owner:       owner.Username,
+		Fields:      strings.Join(fields, ", "),
+		Link:        fmt.Sprintf("%s/shared-contacts/%d", s.publicURL, contactID),
+	}
+	for _, user := range users {
+		data.Username = user.Username
+		msg, err := mail.Render("shared_contact_updated", user.Email, data)
+		if err == nil {
+			err = s.sender.Send(msg)
+		}
+		if err != nil {
+			slog.Error("Failed to send shared contact update email", "error", err, "userID", user.ID, "contactID", contactID)
+		}
+	}
+}
+
+// changedFields returns the fields listed in the details of a contact update event
+func changedFields(details map[string]interface{}) []string {
+	switch fields := details["fields"].(type) {
+	case []string:
+		return fields
+	case []interface{}:
+		result := make([]string, 0, len(fields))
+		for _, field := range fields {
+			if name, ok := field.(string); ok {
+				result = append(result, name)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// authorizeReviewer fails unless the subject reviews submissions
+func (s *SharedBookService) authorizeReviewer(subject policy.Subject) error {
+	reviewer, err := s.isReviewer(subject)
+	if err != nil {
+		return err
+	}
+	if !reviewer {
+		return newError(ErrForbidden, constants.ErrNotSharedBookReviewer)
+	}
+	return nil
+}
+
+// isReviewer reports whether the subject reviews submissions: admins through the policy, and the editors
+func (s *SharedBookService) isReviewer(subject policy.Subject) (bool, error) {
+	if policy.Can(subject, policy.ActionManage, policy.Resource{Type: policy.ResourceSharedBook}) {
+		return true, nil
+	}
+	if len(s.editors) == 0 {
+		return false, nil
+	}
+	user, err := s.repo.GetUser(subject.UserID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return false, nil
+	}
+	for _, email := range s.editors {
+		if strings.EqualFold(email, user.Email) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *SharedBookService) get(contactID int) (*dtos.SharedContactDto, error) {
+	contact, err := s.repo.GetSharedContact(contactID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shared contact: %w", err)
+	}
+	if contact == nil {
+		return nil, newError(ErrNotFound, constants.ErrSharedContactNotFound)
+	}
+	result := toSharedContactDto(*contact)
+	return &result, nil
+}
+
+func toSharedContactList(contacts []models.SharedContact) *dtos.SharedContactListResponseDto {
+	result := &dtos.SharedContactListResponseDto{Items: make([]dtos.SharedContactDto, len(contacts))}
+	for i, contact := range contacts {
+		result.Items[i] = toSharedContactDto(contact)
+	}
+	return result
+}
+
+func toSharedContactDto(contact models.SharedContact) dtos.SharedContactDto {
+	return dtos.SharedContactDto{
+		Contact:     toContactDto(contact.Contact),
+		Status:      contact.Status,
+		SubmittedBy: contact.UserID,
+		SubmittedAt: contact.SubmittedAt,
+		ReviewedAt:  contact.ReviewedAt,
+		ReviewNote:  contact.ReviewNote,
+		ChangedAt:   contact.ChangedAt,
+	}
+}
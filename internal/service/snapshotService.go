@@ -0,0 +1,293 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/events"
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/danizion/contact-app/internal/repository"
+	"github.com/danizion/contact-app/internal/snapshot"
+	"github.com/danizion/contact-app/internal/storage/redis"
+)
+
+// SnapshotService takes named copies of a user's address book, diffs them against the current state and restores them
+type SnapshotService struct {
+	repo  *repository.Repository
+	redis *redis.Redis
+	// phoneRegions give the region the restored phone numbers without a country code are normalized in
+	phoneRegions []phoneRegionSource
+}
+
+// NewSnapshotService creates a new instance of SnapshotService
+func NewSnapshotService(db *sql.DB, redisClient *redis.Redis) *SnapshotService {
+	repo := repository.NewRepository(db)
+	return &SnapshotService{
+		repo:         repo,
+		redis:        redisClient,
+		phoneRegions: defaultPhoneRegions(repo),
+	}
+}
+
+// WithContext returns the service running its queries and cache calls under ctx, the context of a request so they stop with it
+func (s *SnapshotService) WithContext(ctx context.Context) *SnapshotService {
+	bound := *s
+	bound.repo = s.repo.WithContext(ctx)
+	bound.redis = s.redis.WithContext(ctx)
+	return &bound
+}
+
+// CreateSnapshot stores a copy of all the user's contacts
+func (s *SnapshotService) CreateSnapshot(req dtos.CreateSnapshotRequestDto) (*dtos.SnapshotResponseDto, error) {
+	count, err := s.repo.CountSnapshotsByUser(req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count snapshots: %w", err)
+	}
+	if count >= constants.MaxSnapshotsPerUser {
+		return nil, newError(ErrConflict, constants.ErrSnapshotLimitReached)
+	}
+
+	contacts, err := s.currentContacts(req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	contactsJSON, err := json.Marshal(contacts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = time.Now().UTC().Format("Snapshot 2006-01-02 15:04")
+	}
+
+	snap := models.ContactSnapshot{
+		UserID:       req.UserID,
+		Name:         name,
+		ContactCount: len(contacts),
+		Contacts:     string(contactsJSON),
+		CreatedAt:    time.Now(),
+	}
+	snap.ID, err = s.repo.CreateSnapshot(snap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snapshot: %w", err)
+	}
+	recordAudit(s.repo, req.UserID, constants.AuditActionSnapshotCreated, constants.AuditEntitySnapshot, snap.ID, nil)
+
+	result := toSnapshotDto(snap)
+	return &result, nil
+}
+
+// ListSnapshots returns the user's snapshots, newest first
+func (s *SnapshotService) ListSnapshots(userID int) ([]dtos.SnapshotResponseDto, error) {
+	snapshots, err := s.repo.GetSnapshotsByUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get snapshots: %w", err)
+	}
+
+	result := make([]dtos.SnapshotResponseDto, len(snapshots))
+	for i, snap := range snapshots {
+		result[i] = toSnapshotDto(snap)
+	}
+	return result, nil
+}
+
+// DeleteSnapshot deletes a snapshot
+func (s *SnapshotService) DeleteSnapshot(userID, snapshotID int) error {
+	err := s.repo.DeleteSnapshot(userID, snapshotID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return newError(ErrNotFound, constants.ErrSnapshotNotFound)
+		}
+		return fmt.Errorf("failed to delete snapshot: %w", err)
+	}
+	return nil
+}
+
+// DiffSnapshot compares a snapshot with the current contacts
+func (s *SnapshotService) DiffSnapshot(userID, snapshotID int) (*dtos.SnapshotDiffResponseDto, error) {
+	stored, err := s.loadSnapshot(userID, snapshotID)
+	if err != nil {
+		return nil, err
+	}
+	diff, err := s.diff(userID, stored)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dtos.SnapshotDiffResponseDto{
+		SnapshotID: snapshotID,
+		Added:      make([]dtos.SnapshotContactDto, len(diff.Added)),
+		Removed:    make([]dtos.SnapshotContactDto, len(diff.Removed)),
+		Changed:    make([]dtos.SnapshotContactChangeDto, len(diff.Changed)),
+	}
+	for i, contact := range diff.Added {
+		result.Added[i] = toSnapshotContactDto(contact)
+	}
+	for i, contact := range diff.Removed {
+		result.Removed[i] = toSnapshotContactDto(contact)
+	}
+	for i, change := range diff.Changed {
+		result.Changed[i] = dtos.SnapshotContactChangeDto{
+			SnapshotContactDto: toSnapshotContactDto(change.Current),
+			Changes:            make([]dtos.SnapshotFieldChangeDto, len(change.Changes)),
+		}
+		for j, field := range change.Changes {
+			result.Changed[i].Changes[j] = dtos.SnapshotFieldChangeDto{
+				Field:    field.Field,
+				Snapshot: field.Snapshot,
+				Current:  field.Current,
+			}
+		}
+	}
+	return result, nil
+}
+
+// RestoreSnapshot brings the address book back to the state of a snapshot: contacts added since are moved to the
+// trash, changed ones reverted and deleted ones re-created, in a single transaction
+func (s *SnapshotService) RestoreSnapshot(userID, snapshotID int) (*dtos.RestoreSnapshotResponseDto, error) {
+	stored, err := s.loadSnapshot(userID, snapshotID)
+	if err != nil {
+		return nil, err
+	}
+	diff, err := s.diff(userID, stored)
+	if err != nil {
+		return nil, err
+	}
+
+	// Snapshots keep the phone numbers as entered, their normalized form is computed again
+	region, err := resolvePhoneRegion(s.phoneRegions, userID, "")
+	if err != nil {
+		return nil, err
+	}
+	contacts := make([]models.Contact, len(stored))
+	for i, contact := range stored {
+		contacts[i] = fromSnapshotContact(contact)
+		contacts[i].PhoneNormalized = storedPhoneNormalized(contacts[i], region)
+	}
+
+	if err := s.repo.ReplaceContacts(userID, contacts); err != nil {
+		return nil, fmt.Errorf("failed to restore snapshot: %w", err)
+	}
+
+	result := &dtos.RestoreSnapshotResponseDto{
+		SnapshotID: snapshotID,
+		Recreated:  len(diff.Removed),
+		Updated:    len(diff.Changed),
+		Deleted:    len(diff.Added),
+	}
+	details := map[string]interface{}{"recreated": result.Recreated, "updated": result.Updated, "deleted": result.Deleted}
+	recordAudit(s.repo, userID, constants.AuditActionSnapshotRestored, constants.AuditEntitySnapshot, snapshotID, details)
+	events.Publish(events.Event{Type: events.SnapshotRestored, UserID: userID, Details: details})
+	return result, nil
+}
+
+func (s *SnapshotService) diff(userID int, stored []snapshot.Contact) (*snapshot.Diff, error) {
+	current, err := s.currentContacts(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := snapshot.Compare(stored, current)
+	return &diff, nil
+}
+
+// loadSnapshot returns the contacts stored in a snapshot of the user
+func (s *SnapshotService) loadSnapshot(userID, snapshotID int) ([]snapshot.Contact, error) {
+	snap, err := s.repo.GetSnapshot(userID, snapshotID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get snapshot: %w", err)
+	}
+	if snap == nil {
+		return nil, newError(ErrNotFound, constants.ErrSnapshotNotFound)
+	}
+
+	var stored []snapshot.Contact
+	if err := json.Unmarshal([]byte(snap.Contacts), &stored); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+	return stored, nil
+}
+
+func (s *SnapshotService) currentContacts(userID int) ([]snapshot.Contact, error) {
+	contacts, err := s.repo.GetContactsByUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contacts: %w", err)
+	}
+
+	result := make([]snapshot.Contact, len(contacts))
+	for i, contact := range contacts {
+		result[i] = toSnapshotContact(contact)
+	}
+	return result, nil
+}
+
+func toSnapshotContact(contact models.Contact) snapshot.Contact {
+	return snapshot.Contact{
+		ID:          contact.ID,
+		FirstName:   contact.FirstName,
+		LastName:    contact.LastName,
+		PhoneNumber: contact.PhoneNumber,
+		Address:     contact.Address,
+		Email:       contact.Email,
+		Company:     contact.Company,
+		JobTitle:    contact.JobTitle,
+		Timezone:    contact.Timezone,
+		Street:      contact.Street,
+		City:        contact.City,
+		Region:      contact.Region,
+		PostalCode:  contact.PostalCode,
+		CountryCode: contact.CountryCode,
+		Latitude:    contact.Latitude,
+		Longitude:   contact.Longitude,
+		Source:      contact.Source,
+		Stage:       contact.Stage,
+	}
+}
+
+func fromSnapshotContact(contact snapshot.Contact) models.Contact {
+	return models.Contact{
+		ID:          contact.ID,
+		FirstName:   contact.FirstName,
+		LastName:    contact.LastName,
+		PhoneNumber: contact.PhoneNumber,
+		Address:     contact.Address,
+		Email:       contact.Email,
+		Company:     contact.Company,
+		JobTitle:    contact.JobTitle,
+		Timezone:    contact.Timezone,
+		Street:      contact.Street,
+		City:        contact.City,
+		Region:      contact.Region,
+		PostalCode:  contact.PostalCode,
+		CountryCode: contact.CountryCode,
+		Latitude:    contact.Latitude,
+		Longitude:   contact.Longitude,
+		Source:      contact.Source,
+		Stage:       contact.Stage,
+	}
+}
+
+func toSnapshotDto(snap models.ContactSnapshot) dtos.SnapshotResponseDto {
+	return dtos.SnapshotResponseDto{
+		ID:           snap.ID,
+		Name:         snap.Name,
+		ContactCount: snap.ContactCount,
+		CreatedAt:    snap.CreatedAt,
+	}
+}
+
+func toSnapshotContactDto(contact snapshot.Contact) dtos.SnapshotContactDto {
+	return dtos.SnapshotContactDto{
+		ID:        contact.ID,
+		FirstName: contact.FirstName,
+		LastName:  contact.LastName,
+	}
+}
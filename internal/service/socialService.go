@@ -0,0 +1,136 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/danizion/contact-app/internal/repository"
+	"github.com/danizion/contact-app/internal/social"
+)
+
+// GetSocialProfiles returns the social profiles of a contact
+func (s *ContactService) GetSocialProfiles(userID, contactID int) ([]dtos.SocialProfileDto, error) {
+	if err := s.checkContactOwnership(userID, contactID); err != nil {
+		return nil, err
+	}
+
+	profiles, err := s.repo.GetSocialProfilesByContacts([]int{contactID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get social profiles: %w", err)
+	}
+
+	result := make([]dtos.SocialProfileDto, len(profiles))
+	for i, profile := range profiles {
+		result[i] = toSocialProfileDto(profile)
+	}
+	return result, nil
+}
+
+// SetSocialProfile validates and normalizes a handle or profile URL and stores it for the contact
+func (s *ContactService) SetSocialProfile(userID, contactID int, network, value string) (*dtos.SocialProfileDto, error) {
+	if !social.IsSupported(network) {
+		return nil, newError(ErrInvalidInput, "%s: %s", constants.ErrUnsupportedSocialNetwork, network)
+	}
+	if err := s.checkContactOwnership(userID, contactID); err != nil {
+		return nil, err
+	}
+
+	handle, url, err := social.Normalize(network, value)
+	if err != nil {
+		return nil, newError(ErrInvalidInput, "%s: %w", constants.ErrInvalidSocialProfile, err)
+	}
+
+	profile := models.SocialProfile{
+		ContactID: contactID,
+		Network:   network,
+		Handle:    handle,
+		URL:       url,
+	}
+	if err := s.repo.UpsertSocialProfile(profile); err != nil {
+		return nil, fmt.Errorf("failed to save social profile: %w", err)
+	}
+	recordContactChange(s.repo, userID, constants.AuditActionContactUpdated, contactID,
+		map[string]interface{}{"fields": []string{"social_profiles"}})
+
+	result := toSocialProfileDto(profile)
+	return &result, nil
+}
+
+// DeleteSocialProfile removes the profile of a contact on a social network
+func (s *ContactService) DeleteSocialProfile(userID, contactID int, network string) error {
+	if err := s.checkContactOwnership(userID, contactID); err != nil {
+		return err
+	}
+
+	err := s.repo.DeleteSocialProfile(contactID, network)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return newError(ErrNotFound, constants.ErrSocialProfileNotFound)
+		}
+		return fmt.Errorf("failed to delete social profile: %w", err)
+	}
+	recordContactChange(s.repo, userID, constants.AuditActionContactUpdated, contactID,
+		map[string]interface{}{"fields": []string{"social_profiles"}})
+
+	return nil
+}
+
+// attachSocialProfiles loads the social profiles of a page of contacts with a single query
+func (s *ContactService) attachSocialProfiles(contacts []dtos.GetContactsResponseDto) error {
+	contactIDs := make([]int, len(contacts))
+	index := make(map[int]int, len(contacts))
+	for i, contact := range contacts {
+		contactIDs[i] = contact.ID
+		index[contact.ID] = i
+	}
+
+	profiles, err := s.repo.GetSocialProfilesByContacts(contactIDs)
+	if err != nil {
+		return fmt.Errorf("failed to get social profiles: %w", err)
+	}
+
+	for _, profile := range profiles {
+		i := index[profile.ContactID]
+		contacts[i].SocialProfiles = append(contacts[i].SocialProfiles, toSocialProfileDto(profile))
+	}
+	return nil
+}
+
+// socialProfilesByContact loads the social profiles of contacts with a single query, keyed by contact ID
+func socialProfilesByContact(repo *repository.Repository, contacts []models.Contact) (map[int][]dtos.SocialProfileDto, error) {
+	contactIDs := make([]int, len(contacts))
+	for i, contact := range contacts {
+		contactIDs[i] = contact.ID
+	}
+	profiles, err := repo.GetSocialProfilesByContacts(contactIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get social profiles: %w", err)
+	}
+	byContact := make(map[int][]dtos.SocialProfileDto)
+	for _, profile := range profiles {
+		byContact[profile.ContactID] = append(byContact[profile.ContactID], toSocialProfileDto(profile))
+	}
+	return byContact, nil
+}
+
+func (s *ContactService) checkContactOwnership(userID, contactID int) error {
+	owned, err := s.repo.IsContactOwnedByUser(userID, contactID)
+	if err != nil {
+		return fmt.Errorf("failed to check contact: %w", err)
+	}
+	if !owned {
+		return newError(ErrNotFound, constants.ErrContactNotFound)
+	}
+	return nil
+}
+
+func toSocialProfileDto(profile models.SocialProfile) dtos.SocialProfileDto {
+	return dtos.SocialProfileDto{
+		Network: profile.Network,
+		Handle:  profile.Handle,
+		URL:     profile.URL,
+	}
+}
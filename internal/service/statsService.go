@@ -0,0 +1,75 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/danizion/contact-app/internal/analytics"
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/metrics"
+	"github.com/danizion/contact-app/internal/repository"
+)
+
+// StatsService computes the instance wide numbers of the admin dashboard
+type StatsService struct {
+	repo *repository.Repository
+
+	mu       sync.Mutex
+	cached   *dtos.AdminStatsResponseDto
+	cachedAt time.Time
+}
+
+// NewStatsService creates a new instance of StatsService
+func NewStatsService(db *sql.DB) *StatsService {
+	return &StatsService{
+		repo: repository.NewRepository(db),
+	}
+}
+
+// GetAdminStats returns the instance stats, computed at most once per AdminStatsCacheTTL since the counting
+// queries scan every account
+func (s *StatsService) GetAdminStats() (*dtos.AdminStatsResponseDto, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if s.cached != nil && now.Sub(s.cachedAt) < constants.AdminStatsCacheTTL {
+		return s.cached, nil
+	}
+
+	counts, err := s.repo.GetInstanceCounts(now.Add(-constants.ActiveUserWindow), now.Add(-constants.DigestPeriod))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count instance stats: %w", err)
+	}
+	usage, err := s.repo.CountAnalyticsEventsByName(now.Add(-constants.UsageWindow))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count analytics events: %w", err)
+	}
+
+	stats := &dtos.AdminStatsResponseDto{
+		Users:            counts.Users,
+		Contacts:         counts.Contacts,
+		DailyActiveUsers: counts.ActiveUsers,
+		ImportsLast24h:   usage[analytics.EventImport],
+		ExportsLast24h:   usage[analytics.EventExport],
+		Cache: dtos.CacheStatsDto{
+			Hits:   metrics.CacheLookups.Value("hit"),
+			Misses: metrics.CacheLookups.Value("miss"),
+		},
+		Jobs: dtos.JobStatsDto{
+			DigestsDue:        counts.DigestsDue,
+			AnalyticsBuffered: analytics.Buffered(),
+		},
+		GeneratedAt: now.UTC(),
+	}
+	if lookups := stats.Cache.Hits + stats.Cache.Misses; lookups > 0 {
+		hitRate := float64(stats.Cache.Hits) / float64(lookups)
+		stats.Cache.HitRate = &hitRate
+	}
+
+	s.cached, s.cachedAt = stats, now
+	return stats, nil
+}
@@ -0,0 +1,646 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/danizion/contact-app/internal/repository"
+	"github.com/danizion/contact-app/internal/storage/redis"
+)
+
+// SyncService serves sync clients editing contacts offline. The version of a contact is the latest entry of its
+// history; changes made at an older version are merged field by field with the changes recorded since
+type SyncService struct {
+	repo     *repository.Repository
+	contacts *ContactService
+}
+
+// NewSyncService creates a new instance of SyncService
+func NewSyncService(db *sql.DB, redisClient *redis.Redis) *SyncService {
+	return &SyncService{
+		repo:     repository.NewRepository(db),
+		contacts: NewContactService(db, redisClient),
+	}
+}
+
+// WithContext returns the service running its queries under ctx, the context of a request so they stop with it
+func (s *SyncService) WithContext(ctx context.Context) *SyncService {
+	bound := *s
+	bound.repo = s.repo.WithContext(ctx)
+	bound.contacts = s.contacts.WithContext(ctx)
+	return &bound
+}
+
+// GetContact returns a contact of a user with its version. Unlike a regular read it is not counted as a view
+func (s *SyncService) GetContact(userID, contactID int) (*dtos.SyncContactDto, error) {
+	contact, err := s.loadContact(userID, contactID)
+	if err != nil {
+		return nil, err
+	}
+	version, err := s.repo.GetContactVersion(userID, contactID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contact version: %w", err)
+	}
+	return &dtos.SyncContactDto{Contact: *contact, Version: version}, nil
+}
+
+// UpdateContact merges the changes a client made to a contact at its base version. A field the client changed is
+// applied unless the history recorded a change of it since the base version, the last writer wins for the fields
+// only one side touched. Fields both sides changed keep the server value and are reported as conflicts, except when
+// both set the same value. The client resolves conflicts by sending the fields again at the returned version. A change
+// written while the update was merged fails it with ErrConflict, the client sends it again
+func (s *SyncService) UpdateContact(req dtos.SyncUpdateContactRequestDto) (*dtos.SyncUpdateContactResponseDto, error) {
+	userID, contactID := req.Changes.UserID, req.Changes.ID
+	if req.BaseVersion < 0 {
+		return nil, newError(ErrInvalidInput, constants.ErrInvalidBaseVersion)
+	}
+	current, err := s.repo.GetContactByID(userID, contactID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contact: %w", err)
+	}
+	if current == nil {
+		return nil, newError(ErrNotFound, constants.ErrContactNotFound)
+	}
+	version, err := s.repo.GetContactVersion(userID, contactID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contact version: %w", err)
+	}
+	if req.BaseVersion > version {
+		return nil, newError(ErrInvalidInput, constants.ErrInvalidBaseVersion)
+	}
+
+	touched, err := s.changedSince(userID, contactID, req.BaseVersion)
+	if err != nil {
+		return nil, err
+	}
+	changes, applied, conflicts := mergeSyncChanges(req.Changes, repository.ContactFieldValues(current), touched)
+	result := &dtos.SyncUpdateContactResponseDto{Applied: applied, Conflicts: conflicts}
+
+	if len(result.Applied) > 0 {
+		repoContact, fields, err := s.contacts.prepareContactUpdate(current, changes)
+		if err != nil {
+			return nil, err
+		}
+		// The merge holds only at the version it was made on, a change written since fails the update
+		mutation := repository.ContactMutation{Op: repository.MutationUpdate, Contact: repoContact, UpdateFields: fields, Version: version}
+		if _, _, err := s.repo.ApplyContactMutations(userID, []repository.ContactMutation{mutation}, "", nil); err != nil {
+			if errors.Is(err, repository.ErrContactVersionChanged) {
+				return nil, newError(ErrConflict, constants.ErrSyncContactConflict)
+			}
+			return nil, fmt.Errorf("failed to update contact: %w", err)
+		}
+		s.contacts.contactUpdated(current, changes, fields)
+	}
+
+	synced, err := s.GetContact(userID, contactID)
+	if err != nil {
+		return nil, err
+	}
+	result.Contact, result.Version = synced.Contact, synced.Version
+	return result, nil
+}
+
+// changedSince returns the fields recorded as changed in the history of a contact after version
+func (s *SyncService) changedSince(userID, contactID int, version int64) (map[string]bool, error) {
+	entries, err := s.repo.GetContactHistoryAfter(userID, contactID, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contact history: %w", err)
+	}
+	touched := make(map[string]bool)
+	for _, entry := range entries {
+		var changes map[string]json.RawMessage
+		if err := json.Unmarshal(entry.Changes, &changes); err != nil {
+			return nil, fmt.Errorf("failed to decode contact history: %w", err)
+		}
+		for field := range changes {
+			touched[field] = true
+		}
+	}
+	return touched, nil
+}
+
+func (s *SyncService) loadContact(userID, contactID int) (*dtos.GetContactsResponseDto, error) {
+	contact, err := s.repo.GetContactByID(userID, contactID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contact: %w", err)
+	}
+	if contact == nil {
+		return nil, newError(ErrNotFound, constants.ErrContactNotFound)
+	}
+	contacts := []dtos.GetContactsResponseDto{toContactDto(*contact)}
+	if err := s.contacts.attachSocialProfiles(contacts); err != nil {
+		return nil, err
+	}
+	applyLocalTime(contacts, time.Now())
+	return &contacts[0], nil
+}
+
+// mergeSyncChanges keeps the changes of an update to the fields not touched on the server since its base version.
+// The fields touched on both sides are dropped, as conflicts unless both set the same value
+func mergeSyncChanges(changes dtos.UpdateContactRequestDto, serverValues map[string]interface{}, touched map[string]bool) (dtos.UpdateContactRequestDto, []string, []dtos.SyncFieldConflictDto) {
+	applied, conflicts := []string{}, []dtos.SyncFieldConflictDto{}
+	clientValues := syncFieldValues(changes)
+	if changes.CustomFields != nil {
+		// The map is shared with the caller
+		fields := make(map[string]string, len(changes.CustomFields))
+		for key, value := range changes.CustomFields {
+			fields[key] = value
+		}
+		changes.CustomFields = fields
+	}
+	for _, field := range sortedKeys(clientValues) {
+		if !touched[field] {
+			applied = append(applied, field)
+			continue
+		}
+		if !sameSyncValue(serverValues[field], clientValues[field]) {
+			conflicts = append(conflicts, dtos.SyncFieldConflictDto{
+				Field:       field,
+				ServerValue: serverValues[field],
+				ClientValue: clientValues[field],
+			})
+		}
+		dropSyncField(&changes, field)
+	}
+	// Coordinates are only set together
+	if (changes.Latitude == nil) != (changes.Longitude == nil) {
+		dropSyncField(&changes, "latitude")
+		dropSyncField(&changes, "longitude")
+		applied = removeString(removeString(applied, "latitude"), "longitude")
+	}
+	return changes, applied, conflicts
+}
+
+// syncFieldValues returns the fields set by an update by their name in the contact history
+func syncFieldValues(req dtos.UpdateContactRequestDto) map[string]interface{} {
+	values := make(map[string]interface{})
+	for field, value := range map[string]string{
+		"first_name":   req.FirstName,
+		"last_name":    req.LastName,
+		"phone_number": req.PhoneNumber,
+		"address":      req.Address,
+		"email":        req.Email,
+		"company":      req.Company,
+		"job_title":    req.JobTitle,
+		"timezone":     req.Timezone,
+		"street":       req.Street,
+		"city":         req.City,
+		"region":       req.Region,
+		"postal_code":  req.PostalCode,
+		"country_code": strings.ToUpper(req.CountryCode),
+		"source":       req.Source,
+		"stage":        req.Stage,
+	} {
+		if value != "" {
+			values[field] = value
+		}
+	}
+	if req.Latitude != nil {
+		values["latitude"] = *req.Latitude
+	}
+	if req.Longitude != nil {
+		values["longitude"] = *req.Longitude
+	}
+	for key, value := range req.CustomFields {
+		values["custom_fields."+key] = value
+	}
+	return values
+}
+
+// dropSyncField removes a field named as in the contact history from an update
+func dropSyncField(req *dtos.UpdateContactRequestDto, field string) {
+	if key, ok := strings.CutPrefix(field, "custom_fields."); ok {
+		delete(req.CustomFields, key)
+		return
+	}
+	switch field {
+	case "first_name":
+		req.FirstName = ""
+	case "last_name":
+		req.LastName = ""
+	case "phone_number":
+		req.PhoneNumber = ""
+	case "address":
+		req.Address = ""
+	case "email":
+		req.Email = ""
+	case "company":
+		req.Company = ""
+	case "job_title":
+		req.JobTitle = ""
+	case "timezone":
+		req.Timezone = ""
+	case "street":
+		req.Street = ""
+	case "city":
+		req.City = ""
+	case "region":
+		req.Region = ""
+	case "postal_code":
+		req.PostalCode = ""
+	case "country_code":
+		req.CountryCode = ""
+	case "source":
+		req.Source = ""
+	case "stage":
+		req.Stage = ""
+	case "latitude":
+		req.Latitude = nil
+	case "longitude":
+		req.Longitude = nil
+	}
+}
+
+// sameSyncValue compares a server and a client value of a field, a missing custom field is empty
+func sameSyncValue(server, client interface{}) bool {
+	if server == nil {
+		server = ""
+	}
+	return server == client
+}
+
+func sortedKeys(values map[string]interface{}) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func removeString(values []string, value string) []string {
+	result := values[:0]
+	for _, v := range values {
+		if v != value {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// batchContact is a contact changed by a batch, as the mutations before the current one left it
+type batchContact struct {
+	contact models.Contact
+	// create is the repository mutation creating the contact, -1 for a stored contact
+	create int
+	// version is the version of a stored contact when the batch first referred to it
+	version int64
+	deleted bool
+}
+
+// batchUpdate is an update of a batch to record once the batch is committed
+type batchUpdate struct {
+	before models.Contact
+	req    dtos.UpdateContactRequestDto
+	fields map[string]bool
+}
+
+// syncBatch is a batch being prepared: the repository mutations applying it and the contacts it changes
+type syncBatch struct {
+	userID    int
+	mutations []repository.ContactMutation
+	// resultMutation is the repository mutation of each result, -1 when it has none of its own
+	resultMutation []int
+	temp           map[string]*batchContact
+	stored         map[int]*batchContact
+	names          map[string]bool
+	clientIDs      map[string]bool
+	// templates and updates are kept by repository mutation to record the creates and updates once committed
+	templates map[int]*contactTemplate
+	updates   map[int]batchUpdate
+}
+
+// ApplyBatch applies the mutations a sync client queued offline, in order and in one transaction. Every mutation is
+// prepared first, a mutation that cannot apply fails the batch and nothing is applied. Updates of stored contacts are
+// merged field by field at their base version like UpdateContact, updates and deletes of a contact created earlier
+// in the batch change the create instead. A deleted contact goes to the trash whatever its version
+func (s *SyncService) ApplyBatch(userID int, req dtos.SyncBatchRequestDto) (*dtos.SyncBatchResponseDto, error) {
+	if req.BatchID != "" {
+		stored, err := s.repo.GetSyncBatchResult(userID, req.BatchID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get sync batch: %w", err)
+		}
+		if stored != nil {
+			var result dtos.SyncBatchResponseDto
+			if err := json.Unmarshal(stored, &result); err != nil {
+				return nil, fmt.Errorf("failed to decode sync batch: %w", err)
+			}
+			result.Replayed = true
+			return &result, nil
+		}
+	}
+
+	batch := &syncBatch{
+		userID:         userID,
+		resultMutation: make([]int, len(req.Mutations)),
+		temp:           make(map[string]*batchContact),
+		stored:         make(map[int]*batchContact),
+		names:          make(map[string]bool),
+		clientIDs:      make(map[string]bool),
+		templates:      make(map[int]*contactTemplate),
+		updates:        make(map[int]batchUpdate),
+	}
+	result := &dtos.SyncBatchResponseDto{IDMap: map[string]int{}, Results: make([]dtos.SyncMutationResultDto, len(req.Mutations))}
+	for i, mutation := range req.Mutations {
+		result.Results[i] = dtos.SyncMutationResultDto{Index: i, Op: mutation.Op, TempID: mutation.TempID, ContactID: mutation.ContactID}
+		batch.resultMutation[i] = -1
+	}
+
+	for i, mutation := range req.Mutations {
+		err := s.prepareMutation(batch, mutation, &result.Results[i])
+		if err == nil {
+			result.Results[i].Status = constants.SyncMutationApplied
+			continue
+		}
+		if !isMutationError(err) {
+			return nil, err
+		}
+		for j := range result.Results {
+			result.Results[j].Status = constants.SyncMutationSkipped
+			result.Results[j].Applied, result.Results[j].Conflicts = nil, nil
+		}
+		result.Results[i].Status, result.Results[i].Error = constants.SyncMutationFailed, err.Error()
+		return result, nil
+	}
+
+	// Creates dropped by a later delete of the batch are not applied
+	var mutations []repository.ContactMutation
+	applied := make([]int, len(batch.mutations))
+	for i, mutation := range batch.mutations {
+		applied[i] = -1
+		if mutation.Op != "" {
+			applied[i] = len(mutations)
+			mutations = append(mutations, mutation)
+		}
+	}
+
+	complete := func(ids []int, versions map[int]int64) {
+		result.Committed = true
+		for i := range result.Results {
+			res := &result.Results[i]
+			target := batch.resultMutation[i]
+			if target < 0 || applied[target] < 0 {
+				// Updates merged to nothing still tell the version of their contact
+				if version, ok := versions[res.ContactID]; ok && res.Op == repository.MutationUpdate {
+					res.Version = version
+				}
+				continue
+			}
+			res.ContactID = ids[applied[target]]
+			if res.Op != repository.MutationDelete {
+				res.Version = versions[res.ContactID]
+			}
+			if res.Op == repository.MutationCreate {
+				result.IDMap[res.TempID] = res.ContactID
+			}
+		}
+	}
+	ids, versions, err := s.repo.ApplyContactMutations(userID, mutations, req.BatchID, func(ids []int, versions map[int]int64) ([]byte, error) {
+		complete(ids, versions)
+		return json.Marshal(result)
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrContactVersionChanged) {
+			return nil, newError(ErrConflict, constants.ErrSyncBatchConflict)
+		}
+		return nil, fmt.Errorf("failed to apply sync batch: %w", err)
+	}
+	complete(ids, versions)
+
+	for i, mutation := range batch.mutations {
+		if applied[i] < 0 {
+			continue
+		}
+		contactID := ids[applied[i]]
+		switch mutation.Op {
+		case repository.MutationCreate:
+			s.contacts.contactCreated(userID, contactID, batch.templates[i])
+		case repository.MutationUpdate:
+			update := batch.updates[i]
+			s.contacts.contactUpdated(&update.before, update.req, update.fields)
+		case repository.MutationDelete:
+			recordContactChange(s.repo, userID, constants.AuditActionContactDeleted, contactID, nil)
+		}
+	}
+	return result, nil
+}
+
+// CleanupBatches forgets the outcome of the batches older than SyncBatchRetention, they cannot be replayed anymore
+func (s *SyncService) CleanupBatches() error {
+	deleted, err := s.repo.DeleteSyncBatchesBefore(time.Now().Add(-constants.SyncBatchRetention))
+	if err != nil {
+		return fmt.Errorf("failed to delete sync batches: %w", err)
+	}
+	if deleted > 0 {
+		slog.Info("Deleted expired sync batches", "count", deleted)
+	}
+	return nil
+}
+
+// prepareMutation validates a mutation of a batch against the contacts as the earlier mutations left them, and adds
+// the repository mutation applying it. Errors the client has to fix are mutationErrors
+func (s *SyncService) prepareMutation(batch *syncBatch, mutation dtos.SyncMutationDto, result *dtos.SyncMutationResultDto) error {
+	if mutation.Op == repository.MutationCreate {
+		if mutation.Create == nil || mutation.TempID == "" {
+			return mutationError(constants.ErrInvalidMutation + ": a create needs a temp_id and a create object")
+		}
+		if batch.temp[mutation.TempID] != nil {
+			return mutationError(constants.ErrDuplicateTempID)
+		}
+		req := *mutation.Create
+		req.UserID = batch.userID
+		contact, template, err := s.contacts.prepareNewContact(req)
+		if err != nil {
+			return asMutationError(err)
+		}
+		name := contact.FirstName + "\x00" + contact.LastName
+		exists, err := s.repo.IsContactExists(batch.userID, contact.FirstName, contact.LastName)
+		if err != nil {
+			return fmt.Errorf("failed to check existing contact: %w", err)
+		}
+		if exists || batch.names[name] {
+			return mutationError(fmt.Sprintf("%s: %s %s", constants.ErrDuplicateContactName, contact.FirstName, contact.LastName))
+		}
+		if contact.ClientID != nil {
+			if batch.clientIDs[*contact.ClientID] {
+				return mutationError(constants.ErrContactClientIDExists)
+			}
+			if err := s.contacts.checkClientID(batch.userID, contact.ClientID); err != nil {
+				if errors.Is(err, ErrDuplicateContact) {
+					return mutationError(err.Error())
+				}
+				return err
+			}
+			batch.clientIDs[*contact.ClientID] = true
+		}
+		batch.names[name] = true
+		batch.temp[mutation.TempID] = &batchContact{contact: contact, create: len(batch.mutations)}
+		batch.templates[len(batch.mutations)] = template
+		batch.resultMutation[result.Index] = len(batch.mutations)
+		batch.mutations = append(batch.mutations, repository.ContactMutation{Op: repository.MutationCreate, Contact: contact})
+		return nil
+	}
+
+	target, err := s.batchTarget(batch, mutation)
+	if err != nil {
+		return err
+	}
+	switch mutation.Op {
+	case repository.MutationUpdate:
+		if mutation.Update == nil {
+			return mutationError(constants.ErrInvalidMutation + ": an update needs an update object")
+		}
+		return s.prepareUpdate(batch, target, mutation, result)
+	case repository.MutationDelete:
+		target.deleted = true
+		if target.create >= 0 {
+			// The contact is not created at all, its create and updates are dropped
+			delete(batch.names, target.contact.FirstName+"\x00"+target.contact.LastName)
+			batch.mutations[target.create].Op = ""
+			return nil
+		}
+		batch.resultMutation[result.Index] = len(batch.mutations)
+		batch.mutations = append(batch.mutations, repository.ContactMutation{Op: repository.MutationDelete, Contact: target.contact, Version: target.version})
+		return nil
+	}
+	return mutationError(constants.ErrInvalidMutation + ": unknown op " + mutation.Op)
+}
+
+// batchTarget returns the contact an update or delete refers to, created earlier in the batch or stored
+func (s *SyncService) batchTarget(batch *syncBatch, mutation dtos.SyncMutationDto) (*batchContact, error) {
+	var target *batchContact
+	switch {
+	case mutation.ContactID == 0 && mutation.TempID == "":
+		return nil, mutationError(constants.ErrInvalidMutation + ": expected a contact_id or a temp_id")
+	case mutation.ContactID == 0:
+		if target = batch.temp[mutation.TempID]; target == nil {
+			return nil, mutationError(constants.ErrUnknownTempID)
+		}
+	default:
+		if target = batch.stored[mutation.ContactID]; target == nil {
+			contact, err := s.repo.GetContactByID(batch.userID, mutation.ContactID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get contact: %w", err)
+			}
+			if contact == nil {
+				return nil, mutationError(constants.ErrContactNotFound)
+			}
+			version, err := s.repo.GetContactVersion(batch.userID, mutation.ContactID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get contact version: %w", err)
+			}
+			target = &batchContact{contact: *contact, create: -1, version: version}
+			batch.stored[mutation.ContactID] = target
+		}
+	}
+	if target.deleted {
+		return nil, mutationError(constants.ErrContactNotFound)
+	}
+	return target, nil
+}
+
+// prepareUpdate merges an update into the contact it targets. A contact created by the batch is changed in its
+// create, a stored one is merged field by field at the base version of the update
+func (s *SyncService) prepareUpdate(batch *syncBatch, target *batchContact, mutation dtos.SyncMutationDto, result *dtos.SyncMutationResultDto) error {
+	req := *mutation.Update
+	req.UserID, req.ID = batch.userID, target.contact.ID
+	if target.create >= 0 {
+		result.Applied = sortedKeys(syncFieldValues(req))
+		contact, fields, err := s.contacts.prepareContactUpdate(&target.contact, req)
+		if err != nil {
+			return asMutationError(err)
+		}
+		applyContactFields(&target.contact, contact, fields)
+		batch.mutations[target.create].Contact = target.contact
+		batch.resultMutation[result.Index] = target.create
+		return nil
+	}
+
+	if mutation.BaseVersion < 0 || mutation.BaseVersion > target.version {
+		return mutationError(constants.ErrInvalidBaseVersion)
+	}
+	touched, err := s.changedSince(batch.userID, target.contact.ID, mutation.BaseVersion)
+	if err != nil {
+		return err
+	}
+	merged, applied, conflicts := mergeSyncChanges(req, repository.ContactFieldValues(&target.contact), touched)
+	result.Applied, result.Conflicts = applied, conflicts
+	batch.resultMutation[result.Index] = -1
+	if len(applied) == 0 {
+		return nil
+	}
+	contact, fields, err := s.contacts.prepareContactUpdate(&target.contact, merged)
+	if err != nil {
+		return asMutationError(err)
+	}
+	batch.updates[len(batch.mutations)] = batchUpdate{before: target.contact, req: merged, fields: fields}
+	batch.resultMutation[result.Index] = len(batch.mutations)
+	batch.mutations = append(batch.mutations, repository.ContactMutation{Op: repository.MutationUpdate, Contact: contact, UpdateFields: fields, Version: target.version})
+	applyContactFields(&target.contact, contact, fields)
+	return nil
+}
+
+// mutationError is an error of a mutation of a batch the client has to fix
+type mutationError string
+
+func (e mutationError) Error() string {
+	return string(e)
+}
+
+// asMutationError makes the validation errors of the contact service mutationErrors
+func asMutationError(err error) error {
+	if errors.Is(err, ErrInvalidInput) {
+		return mutationError(err.Error())
+	}
+	return err
+}
+
+func isMutationError(err error) bool {
+	var target mutationError
+	return errors.As(err, &target)
+}
+
+// applyContactFields copies the updateFields of an update prepared by prepareContactUpdate into contact
+func applyContactFields(contact *models.Contact, update models.Contact, updateFields map[string]bool) {
+	for field := range updateFields {
+		switch field {
+		case "first_name":
+			contact.FirstName = update.FirstName
+		case "last_name":
+			contact.LastName = update.LastName
+		case "phone_number":
+			contact.PhoneNumber, contact.PhoneNormalized = update.PhoneNumber, update.PhoneNormalized
+		case "address":
+			contact.Address = update.Address
+		case "email":
+			contact.Email = update.Email
+		case "company":
+			contact.Company = update.Company
+		case "job_title":
+			contact.JobTitle = update.JobTitle
+		case "timezone":
+			contact.Timezone = update.Timezone
+		case "structured_address":
+			contact.Street, contact.City, contact.Region = update.Street, update.City, update.Region
+			contact.PostalCode, contact.CountryCode = update.PostalCode, update.CountryCode
+		case "location":
+			contact.Latitude, contact.Longitude = update.Latitude, update.Longitude
+		case "source":
+			contact.Source = update.Source
+		case "stage":
+			contact.Stage = update.Stage
+		case "custom_fields":
+			contact.CustomFields = update.CustomFields
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/danizion/contact-app/internal/repository"
+	"github.com/danizion/contact-app/internal/storage/redis"
+)
+
+// TagService handles the free-form tags of contacts
+type TagService struct {
+	repo  *repository.Repository
+	redis *redis.Redis
+}
+
+// NewTagService creates a new instance of TagService
+func NewTagService(db *sql.DB, redisClient *redis.Redis) *TagService {
+	return &TagService{
+		repo:  repository.NewRepository(db),
+		redis: redisClient,
+	}
+}
+
+// WithContext returns the service running its queries and cache calls under ctx, the context of a request so they stop with it
+func (s *TagService) WithContext(ctx context.Context) *TagService {
+	bound := *s
+	bound.repo = s.repo.WithContext(ctx)
+	bound.redis = s.redis.WithContext(ctx)
+	return &bound
+}
+
+// CreateTag creates a new tag for the user
+func (s *TagService) CreateTag(req dtos.CreateTagRequestDto) (*dtos.TagResponseDto, error) {
+	name, err := normalizeTagName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.repo.GetTagByName(req.UserID, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check tag name: %w", err)
+	}
+	if existing != nil {
+		return nil, newError(ErrConflict, constants.ErrTagExists)
+	}
+
+	if _, err := s.repo.CreateTag(req.UserID, name); err != nil {
+		return nil, fmt.Errorf("failed to create tag: %w", err)
+	}
+	tag, err := s.repo.GetTagByName(req.UserID, name)
+	if err != nil || tag == nil {
+		return nil, fmt.Errorf("failed to get created tag: %w", err)
+	}
+	result := toTagDto(*tag)
+	return &result, nil
+}
+
+// ListTags returns the user's tags with the number of contacts tagged
+func (s *TagService) ListTags(userID int) ([]dtos.TagResponseDto, error) {
+	tags, err := s.repo.GetTagsByUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+
+	result := make([]dtos.TagResponseDto, len(tags))
+	for i, tag := range tags {
+		result[i] = toTagDto(tag)
+	}
+	return result, nil
+}
+
+// DeleteTag deletes a tag, its contacts are kept
+func (s *TagService) DeleteTag(userID int, name string) error {
+	name, err := normalizeTagName(name)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteTag(userID, name); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return newError(ErrNotFound, constants.ErrTagNotFound)
+		}
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+	return s.invalidateTagCache(userID, name)
+}
+
+// TagContact attaches a tag to a contact, creating the tag when it does not exist yet, and returns the contact tags
+func (s *TagService) TagContact(userID, contactID int, name string) (*dtos.ContactTagsResponseDto, error) {
+	if _, err := s.AddContactsToTag(userID, name, dtos.BulkContactsRequestDto{ContactIDs: []int{contactID}}); err != nil {
+		return nil, err
+	}
+	return s.contactTags(contactID)
+}
+
+// UntagContact detaches a tag from a contact and returns the contact tags
+func (s *TagService) UntagContact(userID, contactID int, name string) (*dtos.ContactTagsResponseDto, error) {
+	if _, err := s.RemoveContactsFromTag(userID, name, dtos.BulkContactsRequestDto{ContactIDs: []int{contactID}}); err != nil {
+		return nil, err
+	}
+	return s.contactTags(contactID)
+}
+
+func (s *TagService) contactTags(contactID int) (*dtos.ContactTagsResponseDto, error) {
+	memberships, err := s.repo.GetTagsByContacts([]int{contactID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contact tags: %w", err)
+	}
+	result := &dtos.ContactTagsResponseDto{ContactID: contactID, Tags: []dtos.MembershipDto{}}
+	for _, membership := range memberships {
+		result.Tags = append(result.Tags, dtos.MembershipDto{ID: membership.ID, Name: membership.Name})
+	}
+	return result, nil
+}
+
+// AddContactsToTag tags many contacts at once, creating the tag when it does not exist yet
+func (s *TagService) AddContactsToTag(userID int, name string, req dtos.BulkContactsRequestDto) (*dtos.BulkContactsResponseDto, error) {
+	name, err := normalizeTagName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	contactIDs, err := validateBulkContacts(s.repo, userID, req.ContactIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	tagID, err := s.repo.GetOrCreateTag(userID, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tag: %w", err)
+	}
+
+	changed, err := s.repo.AddContactsToTag(tagID, contactIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to tag contacts: %w", err)
+	}
+	if changed > 0 {
+		if err := s.invalidateTagCache(userID, name); err != nil {
+			return nil, err
+		}
+	}
+
+	return &dtos.BulkContactsResponseDto{Requested: len(contactIDs), Changed: changed}, nil
+}
+
+// RemoveContactsFromTag untags many contacts at once
+func (s *TagService) RemoveContactsFromTag(userID int, name string, req dtos.BulkContactsRequestDto) (*dtos.BulkContactsResponseDto, error) {
+	name, err := normalizeTagName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err := s.repo.GetTagByName(userID, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tag: %w", err)
+	}
+	if tag == nil {
+		return nil, newError(ErrNotFound, constants.ErrTagNotFound)
+	}
+
+	contactIDs, err := validateBulkContacts(s.repo, userID, req.ContactIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	changed, err := s.repo.RemoveContactsFromTag(tag.ID, contactIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to untag contacts: %w", err)
+	}
+	if changed > 0 {
+		if err := s.invalidateTagCache(userID, name); err != nil {
+			return nil, err
+		}
+	}
+
+	return &dtos.BulkContactsResponseDto{Requested: len(contactIDs), Changed: changed}, nil
+}
+
+// invalidateTagCache drops only the cached listings filtered by the tag
+func (s *TagService) invalidateTagCache(userID int, name string) error {
+	if s.redis == nil {
+		return nil
+	}
+	return s.redis.InvalidateUserCacheFilter(strconv.Itoa(userID), "tag", name)
+}
+
+func toTagDto(tag models.Tag) dtos.TagResponseDto {
+	return dtos.TagResponseDto{
+		ID:           tag.ID,
+		Name:         tag.Name,
+		ContactCount: tag.ContactCount,
+		CreatedAt:    tag.CreatedAt,
+	}
+}
+
+// normalizeTagName trims and lower-cases a tag name so "Work" and "work " are the same tag
+func normalizeTagName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || len(name) > 50 {
+		return "", newError(ErrInvalidInput, constants.ErrInvalidTagName)
+	}
+	return name, nil
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/repository"
+	"github.com/danizion/contact-app/internal/storage/blob"
+)
+
+// TrashService deletes contacts for good, from the trash or directly, with their history and attachment files.
+// Moving contacts to the trash and restoring them is handled by ContactService
+type TrashService struct {
+	repo      *repository.Repository
+	blobStore blob.Store
+}
+
+// NewTrashService creates a new instance of TrashService
+func NewTrashService(db *sql.DB, blobStore blob.Store) *TrashService {
+	return &TrashService{
+		repo:      repository.NewRepository(db),
+		blobStore: blobStore,
+	}
+}
+
+// WithContext returns the service running its queries under ctx, the context of a request so they stop with it
+func (s *TrashService) WithContext(ctx context.Context) *TrashService {
+	bound := *s
+	bound.repo = s.repo.WithContext(ctx)
+	return &bound
+}
+
+// PurgeContact deletes a contact of a user for good with its history and attachments, whether it is in the trash or
+// not. A contact already in the trash is reported as contact.purged, it was deleted for the user before
+func (s *TrashService) PurgeContact(userID, contactID int) error {
+	trashed, storageKeys, err := s.repo.PurgeContact(userID, contactID)
+	if err != nil {
+		if errors.Is(err, repository.ErrContactNotFound) {
+			return newError(ErrNotFound, constants.ErrContactNotFound)
+		}
+		return fmt.Errorf("failed to delete contact: %w", err)
+	}
+	s.deleteBlobs(storageKeys)
+
+	if trashed {
+		recordContactChange(s.repo, userID, constants.AuditActionContactPurged, contactID, nil)
+	} else {
+		recordContactChange(s.repo, userID, constants.AuditActionContactDeleted, contactID,
+			map[string]interface{}{"permanent": true})
+	}
+	return nil
+}
+
+// PurgeExpiredTrash deletes for good the contacts in the trash for longer than TrashRetention
+func (s *TrashService) PurgeExpiredTrash() error {
+	_, storageKeys, err := s.repo.PurgeTrashBefore(time.Now().Add(-constants.TrashRetention))
+	if err != nil {
+		return fmt.Errorf("failed to purge trash: %w", err)
+	}
+	s.deleteBlobs(storageKeys)
+	return nil
+}
+
+// deleteBlobs deletes the files of the attachments of purged contacts, their rows are already gone so a file left
+// behind only wastes space
+func (s *TrashService) deleteBlobs(storageKeys []string) {
+	for _, key := range storageKeys {
+		if err := s.blobStore.Delete(key); err != nil {
+			log.Printf("Error deleting blob %s: %v", key, err)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/danizion/contact-app/internal/repository"
+	"github.com/danizion/contact-app/internal/storage/redis"
+)
+
+// UsageService counts the API requests of every user by endpoint. Requests are counted in Redis, shared by the
+// replicas, and rolled up to Postgres where reports read them
+type UsageService struct {
+	repo  *repository.Repository
+	redis *redis.Redis
+	now   func() time.Time
+}
+
+// NewUsageService creates a new instance of UsageService, usage is not counted without Redis
+func NewUsageService(db *sql.DB, redisClient *redis.Redis) *UsageService {
+	return &UsageService{
+		repo:  repository.NewRepository(db),
+		redis: redisClient,
+		now:   time.Now,
+	}
+}
+
+// WithContext returns the service running its queries and cache calls under ctx, the context of a request so they stop with it
+func (s *UsageService) WithContext(ctx context.Context) *UsageService {
+	bound := *s
+	bound.repo = s.repo.WithContext(ctx)
+	bound.redis = s.redis.WithContext(ctx)
+	return &bound
+}
+
+// Record counts a request of a user to a route (by route name) answered with status. Counting never fails the
+// request, errors are only logged
+func (s *UsageService) Record(userID int, route string, status int, bytesIn, bytesOut int64) {
+	if s.redis == nil {
+		return
+	}
+	rateLimited := status == http.StatusTooManyRequests
+	if err := s.redis.CountUsage(s.today(), userID, route, rateLimited, bytesIn, bytesOut, constants.APIUsageCounterTTL); err != nil {
+		slog.Error("Failed to count API usage", "error", err, "userID", userID, "route", route)
+	}
+}
+
+// RollUp copies the usage counted in Redis today and yesterday to Postgres. Yesterday is rolled up again so the
+// requests counted after its last rollup are not lost
+func (s *UsageService) RollUp() error {
+	if s.redis == nil {
+		return nil
+	}
+	today := s.today()
+	for _, day := range []time.Time{today.AddDate(0, 0, -1), today} {
+		userIDs, err := s.redis.GetUsageUsers(day)
+		if err != nil {
+			return fmt.Errorf("failed to list API users: %w", err)
+		}
+		for _, userID := range userIDs {
+			usage, err := s.countedUsage(day, userID)
+			if err != nil {
+				return err
+			}
+			if err := s.repo.UpsertAPIUsage(usage); err != nil {
+				return fmt.Errorf("failed to store API usage: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// GetUserUsage reports the usage of a user over the last days, today included. Today is read from Redis so the
+// report is up to date
+func (s *UsageService) GetUserUsage(userID, days int) (*dtos.APIUsageResponseDto, error) {
+	if days < 1 || days > constants.MaxAPIUsageDays {
+		return nil, newError(ErrInvalidInput, constants.ErrInvalidUsageDays)
+	}
+	today := s.today()
+	from := today.AddDate(0, 0, 1-days)
+
+	usage, err := s.repo.GetUserAPIUsage(userID, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API usage: %w", err)
+	}
+	if s.redis != nil {
+		// The counters of today are more recent than its last rollup
+		counted, err := s.countedUsage(today, userID)
+		if err != nil {
+			return nil, err
+		}
+		rolledUp := usage[:0]
+		for _, row := range usage {
+			if !row.Day.Equal(today) {
+				rolledUp = append(rolledUp, row)
+			}
+		}
+		usage = append(rolledUp, counted...)
+	}
+
+	report := newUsageReport(from, today)
+	byRoute := make(map[string]*dtos.APIUsageEndpointDto)
+	for _, row := range usage {
+		counts := usageCounts(row)
+		addUsage(&report.Total, counts)
+		if day := int(row.Day.Sub(from).Hours() / 24); day >= 0 && day < len(report.Days) {
+			addUsage(&report.Days[day].APIUsageCountsDto, counts)
+		}
+		endpoint, ok := byRoute[row.Route]
+		if !ok {
+			endpoint = &dtos.APIUsageEndpointDto{Endpoint: row.Route}
+			byRoute[row.Route] = endpoint
+		}
+		addUsage(&endpoint.APIUsageCountsDto, counts)
+	}
+	for _, endpoint := range byRoute {
+		report.Endpoints = append(report.Endpoints, *endpoint)
+	}
+	sort.Slice(report.Endpoints, func(i, j int) bool {
+		a, b := report.Endpoints[i], report.Endpoints[j]
+		if a.Requests != b.Requests {
+			return a.Requests > b.Requests
+		}
+		return a.Endpoint < b.Endpoint
+	})
+	return report, nil
+}
+
+// GetAdminUsage reports the usage of every user over the last days, today included as of its last rollup
+func (s *UsageService) GetAdminUsage(days int) (*dtos.AdminAPIUsageResponseDto, error) {
+	if days < 1 || days > constants.MaxAPIUsageDays {
+		return nil, newError(ErrInvalidInput, constants.ErrInvalidUsageDays)
+	}
+	today := s.today()
+	from := today.AddDate(0, 0, 1-days)
+
+	byDay, err := s.repo.GetAPIUsageByDay(from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API usage by day: %w", err)
+	}
+	byRoute, err := s.repo.GetAPIUsageByRoute(from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API usage by endpoint: %w", err)
+	}
+	topUsers, err := s.repo.GetTopAPIUsers(from, constants.APIUsageTopUsers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get top API users: %w", err)
+	}
+
+	report := &dtos.AdminAPIUsageResponseDto{APIUsageResponseDto: *newUsageReport(from, today), TopUsers: []dtos.APIUserUsageDto{}}
+	for _, row := range byDay {
+		counts := usageCounts(row)
+		addUsage(&report.Total, counts)
+		if day := int(row.Day.Sub(from).Hours() / 24); day >= 0 && day < len(report.Days) {
+			report.Days[day].APIUsageCountsDto = counts
+		}
+	}
+	for _, row := range byRoute {
+		report.Endpoints = append(report.Endpoints, dtos.APIUsageEndpointDto{Endpoint: row.Route, APIUsageCountsDto: usageCounts(row)})
+	}
+	for _, user := range topUsers {
+		report.TopUsers = append(report.TopUsers, dtos.APIUserUsageDto{
+			UserID:   user.UserID,
+			Username: user.Username,
+			APIUsageCountsDto: dtos.APIUsageCountsDto{
+				Requests:    user.Requests,
+				RateLimited: user.RateLimited,
+				BytesIn:     user.BytesIn,
+				BytesOut:    user.BytesOut,
+			},
+		})
+	}
+	return report, nil
+}
+
+// countedUsage reads the usage of a user counted in Redis during day
+func (s *UsageService) countedUsage(day time.Time, userID int) ([]models.APIUsage, error) {
+	counters, err := s.redis.GetUsage(day, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API usage counters: %w", err)
+	}
+	usage := make([]models.APIUsage, 0, len(counters))
+	for route, counted := range counters {
+		usage = append(usage, models.APIUsage{
+			UserID:      userID,
+			Day:         day,
+			Route:       route,
+			Requests:    counted.Requests,
+			RateLimited: counted.RateLimited,
+			BytesIn:     counted.BytesIn,
+			BytesOut:    counted.BytesOut,
+		})
+	}
+	return usage, nil
+}
+
+// today returns the start of the current UTC day, usage is counted by UTC day
+func (s *UsageService) today() time.Time {
+	return s.now().UTC().Truncate(24 * time.Hour)
+}
+
+// newUsageReport creates an empty report listing every day from from to to
+func newUsageReport(from, to time.Time) *dtos.APIUsageResponseDto {
+	report := &dtos.APIUsageResponseDto{
+		From:      from.Format("2006-01-02"),
+		To:        to.Format("2006-01-02"),
+		Days:      []dtos.APIUsageDayDto{},
+		Endpoints: []dtos.APIUsageEndpointDto{},
+	}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		report.Days = append(report.Days, dtos.APIUsageDayDto{Date: day.Format("2006-01-02")})
+	}
+	return report
+}
+
+func usageCounts(usage models.APIUsage) dtos.APIUsageCountsDto {
+	return dtos.APIUsageCountsDto{
+		Requests:    usage.Requests,
+		RateLimited: usage.RateLimited,
+		BytesIn:     usage.BytesIn,
+		BytesOut:    usage.BytesOut,
+	}
+}
+
+func addUsage(total *dtos.APIUsageCountsDto, counts dtos.APIUsageCountsDto) {
+	total.Requests += counts.Requests
+	total.RateLimited += counts.RateLimited
+	total.BytesIn += counts.BytesIn
+	total.BytesOut += counts.BytesOut
+}
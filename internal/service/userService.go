@@ -1,50 +1,70 @@
 package service
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"github.com/danizion/contact-app/internal/accountstate"
 	"github.com/danizion/contact-app/internal/auth"
 	"github.com/danizion/contact-app/internal/constants"
 	"github.com/danizion/contact-app/internal/dtos"
 	"github.com/danizion/contact-app/internal/models"
 	"github.com/danizion/contact-app/internal/repository"
+	"github.com/danizion/contact-app/internal/signup"
 	"log"
+	"strings"
+	"time"
 )
 
 // UserService handles business logic for users
 type UserService struct {
 	repo *repository.Repository
+	// signup restricts the email domains that can register
+	signup signup.Policy
 }
 
 // NewUserService creates a new instance of UserService
 func NewUserService(db *sql.DB) *UserService {
 	return &UserService{
-		repo: repository.NewRepository(db),
+		repo:   repository.NewRepository(db),
+		signup: signup.Load(),
 	}
 }
 
+// WithContext returns the service running its queries under ctx, the context of a request so they stop with it
+func (s *UserService) WithContext(ctx context.Context) *UserService {
+	bound := *s
+	bound.repo = s.repo.WithContext(ctx)
+	return &bound
+}
+
 // CreateUserRequestDto is the DTO (Data Transfer Object) for user operations
 
 // CreateUser creates a new user
 func (s *UserService) CreateUser(createUserRequestDto dtos.CreateUserRequestDto) (int, error) {
-	// Check if username already exists
-	existingUser, err := s.repo.GetUserByUsername(createUserRequestDto.Username)
+	// Check the email domain may register on this instance
+	if err := s.signup.Check(createUserRequestDto.Email); err != nil {
+		return 0, newError(ErrForbidden, "%w", err)
+	}
+
+	// Check if username already exists or is reserved to the account that gave it up
+	taken, err := usernameTaken(s.repo, createUserRequestDto.Username, 0)
 	if err != nil {
 		log.Printf("Error checking username: %v", err)
 		return 0, fmt.Errorf("failed to create user: %w", err)
 	}
-	if existingUser != nil {
-		return 0, fmt.Errorf(constants.ErrUsernameExists)
+	if taken {
+		return 0, newError(ErrConflict, constants.ErrUsernameExists)
 	}
 
 	// Check if email already exists
-	existingUser, err = s.repo.GetUserByEmail(createUserRequestDto.Email)
+	existingUser, err := s.repo.GetUserByEmail(createUserRequestDto.Email)
 	if err != nil {
 		log.Printf("Error checking email: %v", err)
 		return 0, fmt.Errorf("failed to create user: %w", err)
 	}
 	if existingUser != nil {
-		return 0, fmt.Errorf(constants.ErrEmailExists)
+		return 0, newError(ErrConflict, constants.ErrEmailExists)
 	}
 
 	// Map DTO to repository models
@@ -65,36 +85,119 @@ func (s *UserService) CreateUser(createUserRequestDto dtos.CreateUserRequestDto)
 	if err != nil {
 		return 0, fmt.Errorf("failed to create user: %w", err)
 	}
+	recordAudit(s.repo, userID, constants.AuditActionUserRegistered, constants.AuditEntityUser, userID, clientIPDetails(createUserRequestDto.ClientIP))
 
 	return userID, nil
 }
 
-// AuthenticateUser validates user credentials and returns user data if valid
-func (s *UserService) AuthenticateUser(email, password string) (*models.User, error) {
+// AuthenticateUser validates user credentials and returns user data if valid, attempts on existing accounts are
+// recorded in their audit log with the client IP
+func (s *UserService) AuthenticateUser(email, password, clientIP string) (*models.User, error) {
 	// Get user by email from repository
 	user, err := s.repo.GetUserByEmail(email)
 	if err != nil || user == nil {
 		log.Printf("Failed to find user with email %s: %v", email, err)
-		return nil, fmt.Errorf("invalid credentials")
+		return nil, newError(ErrUnauthorized, "invalid credentials")
 	}
 
 	// Verify password
 	if !auth.CheckPassword(password, user.HashedPassword) {
 		log.Printf("Invalid password for user with email %s", email)
-		return nil, fmt.Errorf("invalid credentials")
+		recordAudit(s.repo, user.ID, constants.AuditActionLoginFailed, constants.AuditEntityUser, user.ID, clientIPDetails(clientIP))
+		return nil, newError(ErrUnauthorized, "invalid credentials")
 	}
+	// Only once the password is verified, so the state of an account is not revealed to whoever knows its email
+	if !accountstate.CanUseAPI(user.State) {
+		return nil, newError(ErrForbidden, constants.ErrAccountDeactivated)
+	}
+	recordAudit(s.repo, user.ID, constants.AuditActionLogin, constants.AuditEntityUser, user.ID, clientIPDetails(clientIP))
 
 	return user, nil
 }
 
-// GenerateToken creates a JWT token for the authenticated user
-func (s *UserService) GenerateToken(userID int, username string) (string, error) {
-	// Use the auth package to generate a JWT
-	token, err := auth.GenerateJWT(userID, username)
+// usernameTaken tells whether a username belongs to a user other than userID, or was given up by one less than
+// UsernameReservation ago
+func usernameTaken(repo *repository.Repository, username string, userID int) (bool, error) {
+	owner, err := repo.GetUserByUsername(username)
+	if err != nil {
+		return false, err
+	}
+	if owner == nil {
+		owner, err = repo.GetFormerUsernameOwner(username, time.Now().Add(-constants.UsernameReservation))
+		if err != nil {
+			return false, err
+		}
+	}
+	return owner != nil && owner.ID != userID, nil
+}
+
+// LookupUsername finds the account a username belongs to, a former username still reserved to an account redirects
+// to it
+func (s *UserService) LookupUsername(username string) (*dtos.UserLookupResponseDto, error) {
+	user, err := s.repo.GetUserByUsername(username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up username: %w", err)
+	}
+	if user != nil {
+		return &dtos.UserLookupResponseDto{ID: user.ID, UserName: user.Username}, nil
+	}
+
+	user, err = s.repo.GetFormerUsernameOwner(username, time.Now().Add(-constants.UsernameReservation))
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up username: %w", err)
+	}
+	if user == nil {
+		return nil, newError(ErrNotFound, constants.ErrUserNotFound)
+	}
+	return &dtos.UserLookupResponseDto{ID: user.ID, UserName: user.Username, RedirectedFrom: username}, nil
+}
+
+// clientIPDetails returns the audit details recording the client IP of a request, nil when there is no request
+func clientIPDetails(clientIP string) map[string]interface{} {
+	if clientIP == "" {
+		return nil
+	}
+	return map[string]interface{}{"ip": clientIP}
+}
+
+// ChangePassword replaces the password of a user once the current one is verified, the change is recorded in the
+// audit log. The caller revokes the sessions of the user
+func (s *UserService) ChangePassword(userID int, req dtos.ChangePasswordRequestDto, clientIP string) (*models.User, error) {
+	user, err := s.repo.GetUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to change password: %w", err)
+	}
+	if !auth.CheckPassword(req.CurrentPassword, user.HashedPassword) {
+		return nil, newError(ErrForbidden, constants.ErrInvalidPassword)
+	}
+
+	hashedPassword, err := auth.HashPassword(req.NewPassword)
 	if err != nil {
-		log.Printf("Failed to generate token: %v", err)
-		return "", fmt.Errorf("failed to generate authentication token: %w", err)
+		log.Printf("Failed to hash password: %v", err)
+		return nil, fmt.Errorf("failed to change password: %w", err)
+	}
+	if err := s.repo.UpdateUserPassword(userID, hashedPassword); err != nil {
+		return nil, fmt.Errorf("failed to change password: %w", err)
 	}
+	user.HashedPassword = hashedPassword
+	recordAudit(s.repo, userID, constants.AuditActionPasswordChanged, constants.AuditEntityUser, userID, clientIPDetails(clientIP))
+	return user, nil
+}
 
-	return token, nil
+// SetAdmin grants or revokes the admin role of the user with an email, users never get it by signing up since
+// their email is not verified. It takes effect on the next refresh of the tokens of the user
+func (s *UserService) SetAdmin(email string, admin bool) (*models.User, error) {
+	user, err := s.repo.SetUserAdmin(strings.TrimSpace(email), admin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set admin role: %w", err)
+	}
+	if user == nil {
+		return nil, newError(ErrNotFound, constants.ErrUserNotFound)
+	}
+	action := constants.AuditActionAdminGranted
+	if !admin {
+		action = constants.AuditActionAdminRevoked
+	}
+	recordAudit(s.repo, user.ID, action, constants.AuditEntityUser, user.ID, nil)
+	return user, nil
 }
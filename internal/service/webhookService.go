@@ -0,0 +1,388 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/danizion/contact-app/internal/accountstate"
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/dtos"
+	"github.com/danizion/contact-app/internal/events"
+	"github.com/danizion/contact-app/internal/models"
+	"github.com/danizion/contact-app/internal/repository"
+	"github.com/danizion/contact-app/pkg/webhook"
+)
+
+// WebhookService manages the webhooks of users and delivers signed events to them
+type WebhookService struct {
+	repo   *repository.Repository
+	client *http.Client
+}
+
+// NewWebhookService creates a new instance of WebhookService
+func NewWebhookService(db *sql.DB) *WebhookService {
+	return &WebhookService{
+		repo:   repository.NewRepository(db),
+		client: newWebhookClient(),
+	}
+}
+
+// WithContext returns the service running its queries under ctx, the context of a request so they stop with it
+func (s *WebhookService) WithContext(ctx context.Context) *WebhookService {
+	bound := *s
+	bound.repo = s.repo.WithContext(ctx)
+	return &bound
+}
+
+// CreateWebhook registers a webhook URL and generates its signing secret, URLs reaching the host or its internal
+// network are refused
+func (s *WebhookService) CreateWebhook(req dtos.CreateWebhookRequestDto) (*dtos.WebhookResponseDto, error) {
+	target, err := parseWebhookURL(context.Background(), req.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.repo.CountWebhooksByUser(req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count webhooks: %w", err)
+	}
+	if count >= constants.MaxWebhooksPerUser {
+		return nil, newError(ErrConflict, constants.ErrWebhookLimitReached)
+	}
+
+	secret, err := randomToken("whsec_", 24)
+	if err != nil {
+		return nil, err
+	}
+	hook := models.Webhook{
+		UserID:    req.UserID,
+		URL:       target.String(),
+		Secret:    secret,
+		CreatedAt: time.Now(),
+	}
+	hook.ID, err = s.repo.CreateWebhook(hook)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	result := toWebhookDto(hook)
+	result.Secret = hook.Secret
+	return &result, nil
+}
+
+// ListWebhooks returns the user's webhooks without their secrets
+func (s *WebhookService) ListWebhooks(userID int) ([]dtos.WebhookResponseDto, error) {
+	hooks, err := s.repo.GetWebhooksByUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhooks: %w", err)
+	}
+
+	result := make([]dtos.WebhookResponseDto, len(hooks))
+	for i, hook := range hooks {
+		result[i] = toWebhookDto(hook)
+	}
+	return result, nil
+}
+
+// DeleteWebhook removes a webhook
+func (s *WebhookService) DeleteWebhook(userID, webhookID int) error {
+	err := s.repo.DeleteWebhook(userID, webhookID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return newError(ErrNotFound, constants.ErrWebhookNotFound)
+		}
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	return nil
+}
+
+// SendTestEvent delivers a sample signed event to a webhook and reports how the endpoint answered,
+// a failed delivery is a result and not an error
+func (s *WebhookService) SendTestEvent(req dtos.TestWebhookRequestDto) (*dtos.TestWebhookResponseDto, error) {
+	hook, err := s.repo.GetWebhook(req.UserID, req.WebhookID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+	if hook == nil {
+		return nil, newError(ErrNotFound, constants.ErrWebhookNotFound)
+	}
+
+	data, err := json.Marshal(map[string]interface{}{
+		"webhook_id": hook.ID,
+		"message":    "This is a test event, your endpoint received and can verify webhook deliveries",
+	})
+	if err != nil {
+		return nil, err
+	}
+	event, err := newWebhookEvent(constants.WebhookEventTest, data)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dtos.TestWebhookResponseDto{WebhookID: hook.ID, EventID: event.ID}
+	started := time.Now()
+	statusCode, err := s.deliver(hook, event)
+	result.DurationMs = time.Since(started).Milliseconds()
+	result.StatusCode = statusCode
+	switch {
+	case err != nil:
+		result.Error = err.Error()
+	case statusCode < 200 || statusCode > 299:
+		result.Error = fmt.Sprintf("endpoint answered with status %d", statusCode)
+	default:
+		result.Delivered = true
+	}
+	return result, nil
+}
+
+// deliver signs and posts an event to a webhook, returning the status code of the endpoint
+func (s *WebhookService) deliver(hook *models.Webhook, event webhook.Event) (int, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return 0, err
+	}
+	return s.post(hook.URL, hook.Secret, event.ID, body)
+}
+
+// post signs and posts the body of an event to a webhook URL, returning the status code of the endpoint
+func (s *WebhookService) post(url, secret, eventID string, body []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	webhook.SetHeaders(req.Header, secret, eventID, time.Now(), body)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		slog.Info("Webhook delivery failed", "error", err, "eventID", eventID)
+		return 0, errors.New(constants.ErrWebhookUnreachable)
+	}
+	defer resp.Body.Close()
+	// Drain a bounded part of the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	return resp.StatusCode, nil
+}
+
+func newWebhookEvent(eventType string, data json.RawMessage) (webhook.Event, error) {
+	id, err := randomToken("evt_", 12)
+	if err != nil {
+		return webhook.Event{}, err
+	}
+	return webhook.Event{
+		ID:        id,
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	}, nil
+}
+
+// randomToken returns prefix followed by size random bytes in hex
+func randomToken(prefix string, size int) (string, error) {
+	random := make([]byte, size)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("failed to generate random token: %w", err)
+	}
+	return prefix + hex.EncodeToString(random), nil
+}
+
+func toWebhookDto(hook models.Webhook) dtos.WebhookResponseDto {
+	return dtos.WebhookResponseDto{
+		ID:        hook.ID,
+		URL:       hook.URL,
+		CreatedAt: hook.CreatedAt,
+	}
+}
+
+// DeliverEvent is the event subscriber delivering contact events to the webhooks of their user. Each delivery is
+// stored before its first attempt, so failed ones are retried by RetryDueDeliveries. Deliveries run in a goroutine
+// of their own so a slow endpoint never holds up the request publishing the event
+func (s *WebhookService) DeliverEvent(event events.Event) {
+	go func() {
+		// Webhooks of deactivated accounts are paused, their events are not delivered
+		state, err := s.repo.GetAccountState(event.UserID)
+		if err != nil {
+			slog.Error("Failed to get account state", "error", err, "userID", event.UserID)
+			return
+		}
+		if !accountstate.CanUseAPI(state) {
+			return
+		}
+		hooks, err := s.repo.GetWebhooksByUser(event.UserID)
+		if err != nil {
+			slog.Error("Failed to get webhooks", "error", err, "userID", event.UserID)
+			return
+		}
+		if len(hooks) == 0 {
+			return
+		}
+
+		data, err := json.Marshal(map[string]interface{}{
+			"contact_id": event.ContactID,
+			"details":    event.Details,
+		})
+		if err != nil {
+			slog.Error("Failed to encode webhook event", "error", err, "event", event.Type)
+			return
+		}
+		for _, hook := range hooks {
+			delivery, err := s.queueDelivery(hook, event.Type, data)
+			if err != nil {
+				slog.Error("Failed to queue webhook delivery", "error", err, "webhookID", hook.ID, "event", event.Type)
+				continue
+			}
+			s.attempt(hook.URL, hook.Secret, *delivery)
+		}
+	}()
+}
+
+// queueDelivery stores a pending delivery of an event to a webhook, claimed by the caller for its first attempt
+func (s *WebhookService) queueDelivery(hook models.Webhook, eventType string, data json.RawMessage) (*models.WebhookDelivery, error) {
+	event, err := newWebhookEvent(eventType, data)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery := models.WebhookDelivery{
+		WebhookID: hook.ID,
+		EventID:   event.ID,
+		EventType: event.Type,
+		Payload:   string(body),
+		Status:    constants.WebhookDeliveryPending,
+		CreatedAt: time.Now(),
+	}
+	delivery.ID, err = s.repo.CreateWebhookDelivery(delivery, time.Now().Add(constants.WebhookDeliveryLease))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+	return &delivery, nil
+}
+
+// attempt posts a delivery and records the outcome: delivered on a 2xx answer, otherwise retried after a backoff
+// until WebhookMaxAttempts attempts failed
+func (s *WebhookService) attempt(url, secret string, delivery models.WebhookDelivery) {
+	statusCode, err := s.post(url, secret, delivery.EventID, []byte(delivery.Payload))
+	if err == nil && (statusCode < 200 || statusCode > 299) {
+		err = fmt.Errorf("endpoint answered with status %d", statusCode)
+	}
+
+	delivery.Attempts++
+	delivery.StatusCode, delivery.LastError, delivery.NextAttemptAt, delivery.DeliveredAt = nil, "", nil, nil
+	if statusCode != 0 {
+		delivery.StatusCode = &statusCode
+	}
+	now := time.Now()
+	switch {
+	case err == nil:
+		delivery.Status = constants.WebhookDeliveryDelivered
+		delivery.DeliveredAt = &now
+	case delivery.Attempts >= constants.WebhookMaxAttempts:
+		delivery.Status = constants.WebhookDeliveryFailed
+		delivery.LastError = err.Error()
+		slog.Warn("Webhook delivery failed, giving up", "error", err, "webhookID", delivery.WebhookID,
+			"eventID", delivery.EventID, "attempts", delivery.Attempts)
+	default:
+		next := now.Add(webhookRetryDelay(delivery.Attempts))
+		delivery.Status = constants.WebhookDeliveryPending
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = &next
+		slog.Info("Webhook delivery failed, will retry", "error", err, "webhookID", delivery.WebhookID,
+			"eventID", delivery.EventID, "attempts", delivery.Attempts, "nextAttemptAt", next)
+	}
+
+	if err := s.repo.RecordWebhookAttempt(delivery); err != nil {
+		slog.Error("Failed to record webhook attempt", "error", err, "deliveryID", delivery.ID)
+	}
+}
+
+// webhookRetryDelay is the wait before the next attempt once attempts attempts failed
+func webhookRetryDelay(attempts int) time.Duration {
+	delay := constants.WebhookRetryBaseDelay
+	for i := 1; i < attempts && delay < constants.WebhookRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > constants.WebhookRetryMaxDelay {
+		delay = constants.WebhookRetryMaxDelay
+	}
+	return delay
+}
+
+// RetryDueDeliveries attempts again the failed deliveries whose backoff elapsed, concurrently so one slow endpoint
+// does not delay the others. Replicas claim deliveries first so each attempt is made once
+func (s *WebhookService) RetryDueDeliveries() error {
+	deliveries, err := s.repo.ClaimDueWebhookDeliveries(constants.WebhookRetryBatchSize,
+		time.Now().Add(constants.WebhookDeliveryLease))
+	if err != nil {
+		return fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		go func(delivery repository.DueWebhookDelivery) {
+			defer wg.Done()
+			s.attempt(delivery.URL, delivery.Secret, delivery.WebhookDelivery)
+		}(delivery)
+	}
+	wg.Wait()
+	return nil
+}
+
+// CleanupDeliveries removes the finished deliveries older than WebhookDeliveryRetention
+func (s *WebhookService) CleanupDeliveries() error {
+	if _, err := s.repo.DeleteWebhookDeliveriesBefore(time.Now().Add(-constants.WebhookDeliveryRetention)); err != nil {
+		return fmt.Errorf("failed to delete webhook deliveries: %w", err)
+	}
+	return nil
+}
+
+// ListDeliveries returns the latest deliveries of a webhook of the user with their status, newest first
+func (s *WebhookService) ListDeliveries(userID, webhookID int) ([]dtos.WebhookDeliveryDto, error) {
+	hook, err := s.repo.GetWebhook(userID, webhookID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+	if hook == nil {
+		return nil, newError(ErrNotFound, constants.ErrWebhookNotFound)
+	}
+
+	deliveries, err := s.repo.GetWebhookDeliveries(hook.ID, constants.WebhookDeliveriesListLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook deliveries: %w", err)
+	}
+	result := make([]dtos.WebhookDeliveryDto, len(deliveries))
+	for i, delivery := range deliveries {
+		result[i] = dtos.WebhookDeliveryDto{
+			ID:          delivery.ID,
+			EventID:     delivery.EventID,
+			EventType:   delivery.EventType,
+			Status:      delivery.Status,
+			Attempts:    delivery.Attempts,
+			Error:       delivery.LastError,
+			CreatedAt:   delivery.CreatedAt,
+			DeliveredAt: delivery.DeliveredAt,
+		}
+		if delivery.StatusCode != nil {
+			result[i].StatusCode = *delivery.StatusCode
+		}
+		if delivery.Status == constants.WebhookDeliveryPending {
+			result[i].NextAttemptAt = delivery.NextAttemptAt
+		}
+	}
+	return result, nil
+}
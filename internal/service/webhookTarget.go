@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+
+	"github.com/danizion/contact-app/internal/constants"
+)
+
+// errWebhookAddressBlocked is returned by the dialer of webhook deliveries for addresses of the host or its networks
+var errWebhookAddressBlocked = errors.New("webhook address is not public")
+
+// publicWebhookAddress reports whether webhooks may be delivered to ip: loopback, private, link-local, multicast
+// and unspecified addresses reach the host or its internal network and are refused
+func publicWebhookAddress(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified())
+}
+
+// parseWebhookURL validates the URL of a webhook: an absolute http or https URL whose host is not, nor resolves to,
+// an address refused by publicWebhookAddress. Hosts not resolving yet are accepted, deliveries check the address
+// they connect to anyway
+func parseWebhookURL(ctx context.Context, raw string) (*url.URL, error) {
+	target, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Hostname() == "" {
+		return nil, newError(ErrInvalidInput, constants.ErrInvalidWebhookURL)
+	}
+
+	host := target.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		if !publicWebhookAddress(ip) {
+			return nil, newError(ErrInvalidInput, constants.ErrWebhookURLNotPublic)
+		}
+		return target, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, constants.WebhookResolveTimeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return target, nil
+	}
+	for _, addr := range addrs {
+		if !publicWebhookAddress(addr.IP) {
+			return nil, newError(ErrInvalidInput, constants.ErrWebhookURLNotPublic)
+		}
+	}
+	return target, nil
+}
+
+// newWebhookClient creates the HTTP client of webhook deliveries. Its dialer checks the address each connection is
+// made to, after resolution, so a host resolving to a public address when registered and to an internal one later
+// is still refused. Redirects are not followed and no proxy is used, both would reach another host than the checked
+// one
+func newWebhookClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: constants.WebhookTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !publicWebhookAddress(ip) {
+				return errWebhookAddressBlocked
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   constants.WebhookTimeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,90 @@
+// Package signup decides which email addresses may register, so private instances can restrict registration to
+// their company domain and public ones keep out throwaway accounts
+package signup
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/utils"
+)
+
+// disposableList holds the domains of well-known disposable email services, one per line with # comments
+//
+//go:embed disposable_domains.txt
+var disposableList string
+
+var disposableDomains = parseDomains(strings.Split(disposableList, "\n"))
+
+// Policy of the email domains allowed to register, read from the environment
+type Policy struct {
+	// Allowed restricts registration to these domains and their subdomains when not empty
+	Allowed []string
+	// Blocked domains and their subdomains cannot register, even when allowed
+	Blocked []string
+	// BlockDisposable refuses the domains of disposable email services, unless explicitly allowed
+	BlockDisposable bool
+}
+
+// Load reads the signup policy: SIGNUP_ALLOWED_DOMAINS and SIGNUP_BLOCKED_DOMAINS are comma separated domains and
+// SIGNUP_BLOCK_DISPOSABLE=false lets disposable addresses register
+func Load() Policy {
+	return Policy{
+		Allowed:         parseDomains(strings.Split(utils.GetEnvOrDefault("SIGNUP_ALLOWED_DOMAINS", ""), ",")),
+		Blocked:         parseDomains(strings.Split(utils.GetEnvOrDefault("SIGNUP_BLOCKED_DOMAINS", ""), ",")),
+		BlockDisposable: utils.GetEnvOrDefault("SIGNUP_BLOCK_DISPOSABLE", "true") != "false",
+	}
+}
+
+// Check returns an error naming why an email may not register, nil when it may
+func (p Policy) Check(email string) error {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return fmt.Errorf(constants.ErrEmailDomainNotAllowed)
+	}
+	domain := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(email[at+1:]), "."))
+
+	if matches(domain, p.Blocked) {
+		return fmt.Errorf(constants.ErrEmailDomainBlocked)
+	}
+	allowed := matches(domain, p.Allowed)
+	if len(p.Allowed) > 0 && !allowed {
+		return fmt.Errorf(constants.ErrEmailDomainNotAllowed)
+	}
+	if p.BlockDisposable && !allowed && IsDisposable(domain) {
+		return fmt.Errorf(constants.ErrDisposableEmail)
+	}
+	return nil
+}
+
+// IsDisposable reports whether a domain belongs to a well-known disposable email service
+func IsDisposable(domain string) bool {
+	return matches(strings.ToLower(domain), disposableDomains)
+}
+
+// matches reports whether domain is one of domains or a subdomain of one
+func matches(domain string, domains []string) bool {
+	for _, candidate := range domains {
+		if domain == candidate || strings.HasSuffix(domain, "."+candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseDomains lower cases the domains of a list, skipping blanks and # comments. A leading @ or *. is dropped so
+// "@example.com" and "*.example.com" name example.com
+func parseDomains(values []string) []string {
+	var domains []string
+	for _, value := range values {
+		value = strings.ToLower(strings.TrimSpace(value))
+		value = strings.TrimPrefix(strings.TrimPrefix(value, "@"), "*.")
+		if value == "" || strings.HasPrefix(value, "#") {
+			continue
+		}
+		domains = append(domains, value)
+	}
+	return domains
+}
@@ -0,0 +1,130 @@
+package snapshot
+
+import (
+	"sort"
+	"strconv"
+)
+
+// Contact is the stored form of a contact inside a snapshot
+type Contact struct {
+	ID          int      `json:"id"`
+	FirstName   string   `json:"first_name"`
+	LastName    string   `json:"last_name"`
+	PhoneNumber string   `json:"phone_number"`
+	Address     string   `json:"address"`
+	Email       string   `json:"email"`
+	Company     string   `json:"company"`
+	JobTitle    string   `json:"job_title"`
+	Timezone    string   `json:"timezone"`
+	Street      string   `json:"street"`
+	City        string   `json:"city"`
+	Region      string   `json:"region"`
+	PostalCode  string   `json:"postal_code"`
+	CountryCode string   `json:"country_code"`
+	Latitude    *float64 `json:"latitude"`
+	Longitude   *float64 `json:"longitude"`
+	Source      string   `json:"source"`
+	Stage       string   `json:"stage"`
+}
+
+// FieldChange is a field whose value differs between a snapshot and the current address book
+type FieldChange struct {
+	Field    string
+	Snapshot string
+	Current  string
+}
+
+// ContactChange lists the changed fields of a contact present in both the snapshot and the current address book
+type ContactChange struct {
+	Current Contact
+	Changes []FieldChange
+}
+
+// Diff describes how the current address book differs from a snapshot
+type Diff struct {
+	// Added contacts exist now but not in the snapshot
+	Added []Contact
+	// Removed contacts exist in the snapshot but not anymore
+	Removed []Contact
+	Changed []ContactChange
+}
+
+// Compare diffs a snapshot against the current contacts, matching contacts by ID, results are ordered by ID
+func Compare(snapshot, current []Contact) Diff {
+	byID := make(map[int]Contact, len(snapshot))
+	for _, contact := range snapshot {
+		byID[contact.ID] = contact
+	}
+
+	var diff Diff
+	seen := make(map[int]bool, len(current))
+	for _, contact := range current {
+		seen[contact.ID] = true
+		old, ok := byID[contact.ID]
+		if !ok {
+			diff.Added = append(diff.Added, contact)
+			continue
+		}
+		if changes := compareFields(old, contact); len(changes) > 0 {
+			diff.Changed = append(diff.Changed, ContactChange{Current: contact, Changes: changes})
+		}
+	}
+	for _, contact := range snapshot {
+		if !seen[contact.ID] {
+			diff.Removed = append(diff.Removed, contact)
+		}
+	}
+
+	sort.Slice(diff.Added, func(i, j int) bool { return diff.Added[i].ID < diff.Added[j].ID })
+	sort.Slice(diff.Removed, func(i, j int) bool { return diff.Removed[i].ID < diff.Removed[j].ID })
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].Current.ID < diff.Changed[j].Current.ID })
+	return diff
+}
+
+func compareFields(snapshot, current Contact) []FieldChange {
+	oldFields := snapshot.fields()
+	newFields := current.fields()
+
+	var changes []FieldChange
+	for i, field := range oldFields {
+		if field.value != newFields[i].value {
+			changes = append(changes, FieldChange{Field: field.name, Snapshot: field.value, Current: newFields[i].value})
+		}
+	}
+	return changes
+}
+
+type namedValue struct {
+	name  string
+	value string
+}
+
+// fields lists the compared fields of a contact in a stable order
+func (c Contact) fields() []namedValue {
+	return []namedValue{
+		{"first_name", c.FirstName},
+		{"last_name", c.LastName},
+		{"phone_number", c.PhoneNumber},
+		{"address", c.Address},
+		{"email", c.Email},
+		{"company", c.Company},
+		{"job_title", c.JobTitle},
+		{"timezone", c.Timezone},
+		{"street", c.Street},
+		{"city", c.City},
+		{"region", c.Region},
+		{"postal_code", c.PostalCode},
+		{"country_code", c.CountryCode},
+		{"latitude", formatCoordinate(c.Latitude)},
+		{"longitude", formatCoordinate(c.Longitude)},
+		{"source", c.Source},
+		{"stage", c.Stage},
+	}
+}
+
+func formatCoordinate(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'f', -1, 64)
+}
@@ -0,0 +1,117 @@
+package social
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Supported social networks
+const (
+	LinkedIn  = "linkedin"
+	X         = "x"
+	Instagram = "instagram"
+	GitHub    = "github"
+)
+
+type network struct {
+	hosts      []string
+	pathPrefix string
+	handle     *regexp.Regexp
+	urlFormat  string
+}
+
+var networks = map[string]network{
+	LinkedIn: {
+		hosts:      []string{"linkedin.com"},
+		pathPrefix: "/in/",
+		handle:     regexp.MustCompile(`^[A-Za-z0-9\-_%]{3,100}$`),
+		urlFormat:  "https://www.linkedin.com/in/%s",
+	},
+	X: {
+		hosts:     []string{"x.com", "twitter.com"},
+		handle:    regexp.MustCompile(`^[A-Za-z0-9_]{1,15}$`),
+		urlFormat: "https://x.com/%s",
+	},
+	Instagram: {
+		hosts:     []string{"instagram.com"},
+		handle:    regexp.MustCompile(`^[A-Za-z0-9_.]{1,30}$`),
+		urlFormat: "https://www.instagram.com/%s",
+	},
+	GitHub: {
+		hosts:     []string{"github.com"},
+		handle:    regexp.MustCompile(`^[A-Za-z0-9](?:[A-Za-z0-9\-]{0,37}[A-Za-z0-9])?$`),
+		urlFormat: "https://github.com/%s",
+	},
+}
+
+// Networks returns the names of the supported social networks
+func Networks() []string {
+	return []string{LinkedIn, X, Instagram, GitHub}
+}
+
+// IsSupported reports whether name is a supported social network
+func IsSupported(name string) bool {
+	_, ok := networks[name]
+	return ok
+}
+
+// Normalize accepts a handle ("@jane", "jane") or a profile URL for a network and returns the bare handle and canonical profile URL
+func Normalize(networkName, input string) (string, string, error) {
+	n, ok := networks[networkName]
+	if !ok {
+		return "", "", fmt.Errorf("unsupported social network %q", networkName)
+	}
+
+	handle := strings.TrimSpace(input)
+	if strings.Contains(handle, "/") || strings.Contains(handle, ".com") {
+		parsed, err := parseProfileURL(handle)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid %s profile URL: %w", networkName, err)
+		}
+		if !hostMatches(parsed.Host, n.hosts) {
+			return "", "", fmt.Errorf("%s profile URL must be on %s", networkName, strings.Join(n.hosts, " or "))
+		}
+
+		path := parsed.Path
+		if n.pathPrefix != "" {
+			if !strings.HasPrefix(path, n.pathPrefix) {
+				return "", "", fmt.Errorf("%s profile URL must start with %s%s", networkName, n.hosts[0], n.pathPrefix)
+			}
+			path = strings.TrimPrefix(path, n.pathPrefix)
+		}
+		handle = strings.Trim(path, "/")
+	}
+	handle = strings.TrimPrefix(handle, "@")
+
+	if !n.handle.MatchString(handle) {
+		return "", "", fmt.Errorf("invalid %s handle %q", networkName, handle)
+	}
+	return handle, fmt.Sprintf(n.urlFormat, handle), nil
+}
+
+func parseProfileURL(raw string) (*url.URL, error) {
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported scheme %q", parsed.Scheme)
+	}
+	return parsed, nil
+}
+
+func hostMatches(host string, hosts []string) bool {
+	host = strings.TrimPrefix(strings.ToLower(host), "www.")
+	host = strings.TrimPrefix(host, "mobile.")
+	for _, h := range hosts {
+		if host == h {
+			return true
+		}
+	}
+	return false
+}
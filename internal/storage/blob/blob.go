@@ -0,0 +1,115 @@
+package blob
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/danizion/contact-app/internal/utils"
+)
+
+// ErrNotFound is returned when a blob does not exist in the store
+var ErrNotFound = errors.New("blob not found")
+
+// Store is the interface for storing binary objects (attachments, exports) by key
+type Store interface {
+	Put(key string, r io.Reader) (int64, error)
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+// LocalStore is a Store keeping blobs as files under a base directory
+type LocalStore struct {
+	baseDir string
+}
+
+// Init creates the blob store in the blobs directory of dataDir, the BLOB_DIR environment variable overrides it
+func Init(dataDir string) Store {
+	baseDir := utils.GetEnvOrDefault("BLOB_DIR", filepath.Join(dataDir, "blobs"))
+
+	store, err := NewLocalStore(baseDir)
+	if err != nil {
+		log.Fatalf("Failed to initialize blob store: %v", err)
+	}
+	return store
+}
+
+// NewLocalStore creates a LocalStore rooted at baseDir, creating the directory if needed
+func NewLocalStore(baseDir string) (*LocalStore, error) {
+	if err := os.MkdirAll(baseDir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create blob directory: %w", err)
+	}
+	return &LocalStore{baseDir: baseDir}, nil
+}
+
+// Put writes the content of r under key and returns the number of bytes written
+func (s *LocalStore) Put(key string, r io.Reader) (int64, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return 0, err
+	}
+
+	// Write to a temporary file first so readers never see a partial blob
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	written, err := io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, err
+	}
+	return written, nil
+}
+
+// Get opens the blob stored under key, the caller must close it
+func (s *LocalStore) Get(key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return file, err
+}
+
+// Delete removes the blob stored under key, deleting a missing blob is not an error
+func (s *LocalStore) Delete(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// path maps a key to a file inside the base directory, rejecting keys escaping it
+func (s *LocalStore) path(key string) (string, error) {
+	cleaned := filepath.Clean("/" + key)
+	if cleaned == "/" || strings.Contains(key, "..") {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(s.baseDir, cleaned), nil
+}
@@ -1,25 +1,25 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	_ "embed"
 	"fmt"
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/metrics"
 	"github.com/danizion/contact-app/internal/utils"
 	_ "github.com/lib/pq"
 	"log"
 )
 
-func Init() *sql.DB {
-	host := utils.GetEnvOrDefault("POSTGRES_HOST", "localhost")
-	port := utils.GetEnvOrDefault("POSTGRES_PORT", "5433")
-	user := utils.GetEnvOrDefault("POSTGRES_USER", "myuser")
-	password := utils.GetEnvOrDefault("POSTGRES_PASSWORD", "mypassword")
-	dbname := utils.GetEnvOrDefault("POSTGRES_DB", "mydb")
-
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		host, port, user, password, dbname)
+// schema creates or upgrades the tables, it is embedded so the binary carries its own migrations
+//
+//go:embed schema.sql
+var schema string
 
+func Init() *sql.DB {
 	// Establish a connection to the database
-	db, err := sql.Open("postgres", dsn)
+	db, err := sql.Open("postgres", dataSourceName())
 	if err != nil {
 		log.Fatalf("Failed to connect to the database: %v", err)
 	}
@@ -28,40 +28,58 @@ func Init() *sql.DB {
 	if err != nil {
 		log.Fatalf("Error initializing the database schema: %v", err)
 	}
+	// connections in use that never go back to idle are leaked by queries not closed, or not canceled with their
+	// request
+	metrics.Publish("db_pool", func() interface{} { return db.Stats() })
 	return db
 }
 
-func initializeSchemaFromSQL(db *sql.DB) error {
-	// Read the contents of the schema.sql file
-	const schema = `
-	CREATE TABLE IF NOT EXISTS users
-(
-                       id SERIAL PRIMARY KEY,
-                       username VARCHAR(50) NOT NULL UNIQUE,
-                       email VARCHAR(100) NOT NULL UNIQUE,
-                       hashed_password VARCHAR(255) NOT NULL,
-                       created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-                       updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
-);
+// Ping checks that the configured database accepts connections, without touching the schema
+func Ping() error {
+	db, err := sql.Open("postgres", dataSourceName())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.Ping()
+}
 
-CREATE TABLE IF NOT EXISTS contacts (
-                          id SERIAL PRIMARY KEY,
-                          user_id INTEGER NOT NULL,
-                          first_name VARCHAR(100) NOT NULL,
-                          last_name VARCHAR(100) NOT NULL,
-                          phone_number VARCHAR(20) NOT NULL,
-                          address TEXT,
-                          created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-                          updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-                          FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
-);
-	`
+// dataSourceName builds the connection string from the POSTGRES_* environment variables, the sessions it opens
+// cancel the statements running longer than DB_STATEMENT_TIMEOUT
+func dataSourceName() string {
+	host := utils.GetEnvOrDefault("POSTGRES_HOST", "localhost")
+	port := utils.GetEnvOrDefault("POSTGRES_PORT", "5433")
+	user := utils.GetEnvOrDefault("POSTGRES_USER", "myuser")
+	password := utils.GetEnvOrDefault("POSTGRES_PASSWORD", "mypassword")
+	dbname := utils.GetEnvOrDefault("POSTGRES_DB", "mydb")
+	statementTimeout := utils.GetEnvDurationOrDefault("DB_STATEMENT_TIMEOUT", constants.DefaultDBStatementTimeout)
+
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable statement_timeout=%d",
+		host, port, user, password, dbname, statementTimeout.Milliseconds())
+}
 
+// initializeSchemaFromSQL runs the schema without the statement timeout of the sessions, so a long migration is not
+// canceled
+func initializeSchemaFromSQL(db *sql.DB) error {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to open schema connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SET statement_timeout = 0`); err != nil {
+		return fmt.Errorf("failed to disable statement timeout: %w", err)
+	}
 	// Execute the SQL commands in the schema file
-	_, err := db.Exec(string(schema))
+	_, err = conn.ExecContext(ctx, schema)
 	if err != nil {
 		return fmt.Errorf("failed to execute schema script: %w", err)
 	}
+	// The connection goes back to the pool, with the timeout of the connection string again
+	if _, err := conn.ExecContext(ctx, `RESET statement_timeout`); err != nil {
+		return fmt.Errorf("failed to restore statement timeout: %w", err)
+	}
 
 	return nil
 }
@@ -0,0 +1,505 @@
+CREATE TABLE IF NOT EXISTS users
+(
+                       id SERIAL PRIMARY KEY,
+                       username VARCHAR(50) NOT NULL UNIQUE,
+                       email VARCHAR(100) NOT NULL UNIQUE,
+                       hashed_password VARCHAR(255) NOT NULL,
+                       created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                       updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS contacts (
+                          id SERIAL PRIMARY KEY,
+                          user_id INTEGER NOT NULL,
+                          first_name VARCHAR(100) NOT NULL,
+                          last_name VARCHAR(100) NOT NULL,
+                          phone_number VARCHAR(20) NOT NULL,
+                          address TEXT,
+                          created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                          updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                          FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_admin BOOLEAN NOT NULL DEFAULT FALSE;
+-- state of the account (see the accountstate package): deactivated accounts keep their data but cannot be used until
+-- reactivated from a link sent to their email
+ALTER TABLE users ADD COLUMN IF NOT EXISTS state VARCHAR(20) NOT NULL DEFAULT 'active';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deactivated_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS source VARCHAR(50) NOT NULL DEFAULT '';
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS stage VARCHAR(50) NOT NULL DEFAULT '';
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS email VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS company VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS job_title VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS street VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS city VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS region VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS postal_code VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS country_code CHAR(2) NOT NULL DEFAULT '';
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS board_position INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION;
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION;
+-- deleted contacts stay in the trash until restored or purged
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+CREATE INDEX IF NOT EXISTS idx_contacts_trash ON contacts (user_id, deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_contacts_user_stage_position ON contacts (user_id, stage, board_position, id);
+-- keyset pagination of the contact listings, one index per sort order
+CREATE INDEX IF NOT EXISTS idx_contacts_user_id ON contacts (user_id, id);
+CREATE INDEX IF NOT EXISTS idx_contacts_user_name ON contacts (user_id, last_name, first_name, id);
+CREATE INDEX IF NOT EXISTS idx_contacts_user_created ON contacts (user_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_contacts_user_updated ON contacts (user_id, updated_at, id);
+-- the last interaction logged or view of a contact, NULL when never; contacts never interacted with count from their
+-- creation, the expression must match the last interaction key of the repository
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS last_interacted_at TIMESTAMP WITH TIME ZONE;
+CREATE INDEX IF NOT EXISTS idx_contacts_user_interacted ON contacts (user_id, (COALESCE(last_interacted_at, created_at)), id);
+-- fuzzy search of the contact listings, the expression must match the search query of the repository
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX IF NOT EXISTS idx_contacts_search_trgm ON contacts
+    USING GIN ((first_name || ' ' || last_name || ' ' || phone_number || ' ' || COALESCE(address, '')) gin_trgm_ops);
+
+CREATE TABLE IF NOT EXISTS picklist_values (
+                          id SERIAL PRIMARY KEY,
+                          field VARCHAR(50) NOT NULL,
+                          value VARCHAR(50) NOT NULL,
+                          position INTEGER NOT NULL DEFAULT 0,
+                          created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                          UNIQUE (field, value)
+);
+
+-- seed default picklist values only on first start so admin deletions stick
+INSERT INTO picklist_values (field, value, position)
+SELECT v.field, v.value, v.position FROM (VALUES
+    ('source', 'website', 1),
+    ('source', 'referral', 2),
+    ('source', 'event', 3),
+    ('source', 'cold_call', 4),
+    ('source', 'other', 5),
+    ('stage', 'lead', 1),
+    ('stage', 'prospect', 2),
+    ('stage', 'customer', 3),
+    ('stage', 'churned', 4)
+) AS v(field, value, position)
+WHERE NOT EXISTS (SELECT 1 FROM picklist_values);
+
+CREATE TABLE IF NOT EXISTS attachments (
+                          id SERIAL PRIMARY KEY,
+                          contact_id INTEGER NOT NULL REFERENCES contacts (id) ON DELETE CASCADE,
+                          user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+                          file_name VARCHAR(255) NOT NULL,
+                          content_type VARCHAR(100) NOT NULL,
+                          size_bytes BIGINT NOT NULL,
+                          storage_key VARCHAR(255) NOT NULL UNIQUE,
+                          created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_attachments_contact ON attachments (contact_id);
+CREATE INDEX IF NOT EXISTS idx_attachments_user ON attachments (user_id);
+
+CREATE TABLE IF NOT EXISTS contact_enrichments (
+                          id SERIAL PRIMARY KEY,
+                          contact_id INTEGER NOT NULL REFERENCES contacts (id) ON DELETE CASCADE,
+                          user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+                          provider VARCHAR(50) NOT NULL,
+                          matched_on VARCHAR(50) NOT NULL,
+                          status VARCHAR(20) NOT NULL DEFAULT 'pending',
+                          company VARCHAR(100) NOT NULL DEFAULT '',
+                          job_title VARCHAR(100) NOT NULL DEFAULT '',
+                          social_profiles JSONB NOT NULL DEFAULT '{}',
+                          fetched_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                          resolved_at TIMESTAMP WITH TIME ZONE
+);
+CREATE INDEX IF NOT EXISTS idx_contact_enrichments_contact ON contact_enrichments (contact_id);
+
+CREATE TABLE IF NOT EXISTS contact_social_profiles (
+                          id SERIAL PRIMARY KEY,
+                          contact_id INTEGER NOT NULL REFERENCES contacts (id) ON DELETE CASCADE,
+                          network VARCHAR(20) NOT NULL,
+                          handle VARCHAR(100) NOT NULL,
+                          url VARCHAR(255) NOT NULL,
+                          created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                          UNIQUE (contact_id, network)
+);
+
+CREATE TABLE IF NOT EXISTS audit_log (
+                          id BIGSERIAL PRIMARY KEY,
+                          user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+                          actor_id INTEGER NOT NULL,
+                          action VARCHAR(50) NOT NULL,
+                          entity_type VARCHAR(20) NOT NULL,
+                          entity_id INTEGER NOT NULL,
+                          details JSONB NOT NULL DEFAULT '{}',
+                          created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_audit_log_user_created ON audit_log (user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created ON audit_log (created_at);
+
+CREATE TABLE IF NOT EXISTS groups (
+                          id SERIAL PRIMARY KEY,
+                          user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+                          name VARCHAR(50) NOT NULL,
+                          created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                          UNIQUE (user_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS contact_groups (
+                          contact_id INTEGER NOT NULL REFERENCES contacts (id) ON DELETE CASCADE,
+                          group_id INTEGER NOT NULL REFERENCES groups (id) ON DELETE CASCADE,
+                          PRIMARY KEY (group_id, contact_id)
+);
+CREATE INDEX IF NOT EXISTS idx_contact_groups_contact ON contact_groups (contact_id);
+
+CREATE TABLE IF NOT EXISTS tags (
+                          id SERIAL PRIMARY KEY,
+                          user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+                          name VARCHAR(50) NOT NULL,
+                          created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                          UNIQUE (user_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS contact_tags (
+                          contact_id INTEGER NOT NULL REFERENCES contacts (id) ON DELETE CASCADE,
+                          tag_id INTEGER NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+                          PRIMARY KEY (tag_id, contact_id)
+);
+CREATE INDEX IF NOT EXISTS idx_contact_tags_contact ON contact_tags (contact_id);
+
+CREATE TABLE IF NOT EXISTS contact_snapshots (
+                          id SERIAL PRIMARY KEY,
+                          user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+                          name VARCHAR(100) NOT NULL,
+                          contact_count INTEGER NOT NULL,
+                          contacts JSONB NOT NULL,
+                          created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_contact_snapshots_user ON contact_snapshots (user_id, created_at);
+
+CREATE TABLE IF NOT EXISTS user_preferences (
+                          user_id INTEGER PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+                          weekly_digest BOOLEAN NOT NULL DEFAULT FALSE,
+                          digest_sent_at TIMESTAMP WITH TIME ZONE,
+                          updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+-- country of the user, phone numbers of the same country are displayed in national format
+ALTER TABLE user_preferences ADD COLUMN IF NOT EXISTS region VARCHAR(2) NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS webhooks (
+                          id SERIAL PRIMARY KEY,
+                          user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+                          url VARCHAR(2048) NOT NULL,
+                          secret VARCHAR(100) NOT NULL,
+                          created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_webhooks_user ON webhooks (user_id);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+                          id BIGSERIAL PRIMARY KEY,
+                          webhook_id INTEGER NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+                          event_id VARCHAR(40) NOT NULL,
+                          event_type VARCHAR(50) NOT NULL,
+                          payload TEXT NOT NULL,
+                          status VARCHAR(20) NOT NULL DEFAULT 'pending',
+                          attempts INTEGER NOT NULL DEFAULT 0,
+                          status_code INTEGER,
+                          last_error TEXT NOT NULL DEFAULT '',
+                          next_attempt_at TIMESTAMP WITH TIME ZONE,
+                          created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                          delivered_at TIMESTAMP WITH TIME ZONE
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook ON webhook_deliveries (webhook_id, id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_created ON webhook_deliveries (created_at);
+
+CREATE TABLE IF NOT EXISTS contact_audit (
+                          id BIGSERIAL PRIMARY KEY,
+                          contact_id INTEGER NOT NULL REFERENCES contacts (id) ON DELETE CASCADE,
+                          user_id INTEGER NOT NULL,
+                          action VARCHAR(20) NOT NULL,
+                          changes JSONB NOT NULL DEFAULT '{}',
+                          created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_contact_audit_contact ON contact_audit (contact_id, id);
+
+CREATE TABLE IF NOT EXISTS api_keys (
+                          id SERIAL PRIMARY KEY,
+                          user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+                          name VARCHAR(50) NOT NULL,
+                          key_id VARCHAR(40) NOT NULL UNIQUE,
+                          secret VARCHAR(100) NOT NULL,
+                          created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_api_keys_user ON api_keys (user_id);
+
+CREATE TABLE IF NOT EXISTS analytics_events (
+                          id BIGSERIAL PRIMARY KEY,
+                          name VARCHAR(50) NOT NULL,
+                          actor VARCHAR(64) NOT NULL,
+                          properties JSONB NOT NULL DEFAULT '{}',
+                          occurred_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_analytics_events_name_time ON analytics_events (name, occurred_at);
+
+-- a pending email change per user, effective once both addresses confirmed it
+CREATE TABLE IF NOT EXISTS email_changes (
+                          user_id INTEGER PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+                          new_email VARCHAR(100) NOT NULL,
+                          old_token_hash VARCHAR(64) NOT NULL UNIQUE,
+                          new_token_hash VARCHAR(64) NOT NULL UNIQUE,
+                          old_confirmed_at TIMESTAMP WITH TIME ZONE,
+                          new_confirmed_at TIMESTAMP WITH TIME ZONE,
+                          created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                          expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS username_history (
+                          id SERIAL PRIMARY KEY,
+                          user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+                          old_username VARCHAR(50) NOT NULL,
+                          new_username VARCHAR(50) NOT NULL,
+                          changed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_username_history_user ON username_history (user_id, changed_at);
+CREATE INDEX IF NOT EXISTS idx_username_history_old ON username_history (old_username, changed_at);
+
+CREATE TABLE IF NOT EXISTS instance_settings (
+                          key VARCHAR(50) PRIMARY KEY,
+                          value TEXT NOT NULL,
+                          updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- per-user totals kept by triggers so stats and quotas read one row instead of aggregating the user's rows.
+-- Rows are created with the user and only ever updated, a trigger fired while the user is being deleted finds no row
+CREATE TABLE IF NOT EXISTS user_counts (
+                          user_id INTEGER PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+                          contacts INTEGER NOT NULL DEFAULT 0,
+                          trashed_contacts INTEGER NOT NULL DEFAULT 0,
+                          attachment_bytes BIGINT NOT NULL DEFAULT 0,
+                          updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE OR REPLACE FUNCTION user_counts_create() RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO user_counts (user_id) VALUES (NEW.id) ON CONFLICT (user_id) DO NOTHING;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION user_counts_contacts() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE user_counts SET contacts = contacts - (OLD.deleted_at IS NULL)::int,
+                               trashed_contacts = trashed_contacts - (OLD.deleted_at IS NOT NULL)::int, updated_at = NOW()
+        WHERE user_id = OLD.user_id;
+    END IF;
+    IF TG_OP IN ('UPDATE', 'INSERT') THEN
+        UPDATE user_counts SET contacts = contacts + (NEW.deleted_at IS NULL)::int,
+                               trashed_contacts = trashed_contacts + (NEW.deleted_at IS NOT NULL)::int, updated_at = NOW()
+        WHERE user_id = NEW.user_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION user_counts_attachments() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE user_counts SET attachment_bytes = attachment_bytes - OLD.size_bytes, updated_at = NOW() WHERE user_id = OLD.user_id;
+    END IF;
+    IF TG_OP IN ('UPDATE', 'INSERT') THEN
+        UPDATE user_counts SET attachment_bytes = attachment_bytes + NEW.size_bytes, updated_at = NOW() WHERE user_id = NEW.user_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS user_counts_create ON users;
+CREATE TRIGGER user_counts_create AFTER INSERT ON users FOR EACH ROW EXECUTE FUNCTION user_counts_create();
+DROP TRIGGER IF EXISTS user_counts_contacts ON contacts;
+CREATE TRIGGER user_counts_contacts AFTER INSERT OR DELETE ON contacts FOR EACH ROW EXECUTE FUNCTION user_counts_contacts();
+DROP TRIGGER IF EXISTS user_counts_contacts_update ON contacts;
+CREATE TRIGGER user_counts_contacts_update AFTER UPDATE OF user_id, deleted_at ON contacts FOR EACH ROW
+    WHEN (OLD.user_id IS DISTINCT FROM NEW.user_id OR (OLD.deleted_at IS NULL) IS DISTINCT FROM (NEW.deleted_at IS NULL))
+    EXECUTE FUNCTION user_counts_contacts();
+DROP TRIGGER IF EXISTS user_counts_attachments ON attachments;
+CREATE TRIGGER user_counts_attachments AFTER INSERT OR DELETE OR UPDATE OF user_id, size_bytes ON attachments FOR EACH ROW
+    EXECUTE FUNCTION user_counts_attachments();
+
+-- the schema runs in one transaction and the triggers lock out writes until it commits, so users existing before
+-- the triggers are counted once without missing a write
+INSERT INTO user_counts (user_id, contacts, trashed_contacts, attachment_bytes)
+SELECT u.id,
+       (SELECT COUNT(*) FROM contacts c WHERE c.user_id = u.id AND c.deleted_at IS NULL),
+       (SELECT COUNT(*) FROM contacts c WHERE c.user_id = u.id AND c.deleted_at IS NOT NULL),
+       (SELECT COALESCE(SUM(a.size_bytes), 0) FROM attachments a WHERE a.user_id = u.id)
+FROM users u WHERE NOT EXISTS (SELECT 1 FROM user_counts uc WHERE uc.user_id = u.id);
+
+-- API requests per user, endpoint (route name) and UTC day, rolled up from the Redis counters of the day
+CREATE TABLE IF NOT EXISTS api_usage (
+                          user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+                          day DATE NOT NULL,
+                          route VARCHAR(100) NOT NULL,
+                          requests BIGINT NOT NULL DEFAULT 0,
+                          rate_limited BIGINT NOT NULL DEFAULT 0,
+                          bytes_in BIGINT NOT NULL DEFAULT 0,
+                          bytes_out BIGINT NOT NULL DEFAULT 0,
+                          PRIMARY KEY (user_id, day, route)
+);
+CREATE INDEX IF NOT EXISTS idx_api_usage_day ON api_usage (day);
+
+-- calls, meetings and messages logged with a contact, the latest sets contacts.last_interacted_at
+CREATE TABLE IF NOT EXISTS contact_interactions (
+                          id SERIAL PRIMARY KEY,
+                          contact_id INTEGER NOT NULL REFERENCES contacts (id) ON DELETE CASCADE,
+                          user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+                          kind VARCHAR(20) NOT NULL,
+                          note TEXT NOT NULL DEFAULT '',
+                          occurred_at TIMESTAMP WITH TIME ZONE NOT NULL,
+                          created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_contact_interactions_contact ON contact_interactions (contact_id, occurred_at);
+
+-- full-text search of the contacts in the language of their owner: the user's search language, the deployment one
+-- (the search_language instance setting) for users without one, simple when neither is set. search_language is the
+-- language search_vector was computed with, the reindex job catches up the contacts of users whose language changed
+ALTER TABLE user_preferences ADD COLUMN IF NOT EXISTS search_language VARCHAR(63) NOT NULL DEFAULT '';
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS search_language REGCONFIG;
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS search_vector TSVECTOR;
+CREATE INDEX IF NOT EXISTS idx_contacts_search_fts ON contacts USING GIN (search_vector);
+
+CREATE OR REPLACE FUNCTION user_search_language(owner INTEGER) RETURNS REGCONFIG AS $$
+    SELECT COALESCE(NULLIF((SELECT search_language FROM user_preferences WHERE user_id = owner), ''),
+                    (SELECT value FROM instance_settings WHERE key = 'search_language'), 'simple')::regconfig;
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION contacts_search_vector() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' OR NEW.user_id IS DISTINCT FROM OLD.user_id THEN
+        NEW.search_language := user_search_language(NEW.user_id);
+    END IF;
+    NEW.search_vector := setweight(to_tsvector(NEW.search_language, concat_ws(' ', NEW.first_name, NEW.last_name)), 'A') ||
+                         setweight(to_tsvector(NEW.search_language, concat_ws(' ', NEW.company, NEW.job_title, NEW.email)), 'B') ||
+                         setweight(to_tsvector(NEW.search_language, concat_ws(' ', NEW.address, NEW.city)), 'C');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS contacts_search_vector ON contacts;
+CREATE TRIGGER contacts_search_vector BEFORE INSERT OR UPDATE OF user_id, first_name, last_name, company, job_title, email, address, city, search_language
+    ON contacts FOR EACH ROW EXECUTE FUNCTION contacts_search_vector();
+
+-- deployment wide announcements managed by admins, shown to users from starts_at until ends_at (NULL for no end)
+-- until each user dismisses them
+CREATE TABLE IF NOT EXISTS announcements (
+                          id SERIAL PRIMARY KEY,
+                          message TEXT NOT NULL,
+                          level VARCHAR(20) NOT NULL DEFAULT 'info',
+                          starts_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                          ends_at TIMESTAMP WITH TIME ZONE,
+                          created_by INTEGER REFERENCES users (id) ON DELETE SET NULL,
+                          created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE IF NOT EXISTS announcement_dismissals (
+                          announcement_id INTEGER NOT NULL REFERENCES announcements (id) ON DELETE CASCADE,
+                          user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+                          dismissed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                          PRIMARY KEY (user_id, announcement_id)
+);
+
+-- pending reactivation of a deactivated account, the token of the emailed link is stored hashed
+CREATE TABLE IF NOT EXISTS account_reactivations (
+                          user_id INTEGER PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+                          token_hash VARCHAR(64) NOT NULL UNIQUE,
+                          created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                          expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- custom fields of the contact template, defined by admins for every contact of the deployment. Each change bumps
+-- the contact_template_version instance setting and contacts are migrated to it in the background
+CREATE TABLE IF NOT EXISTS custom_field_definitions (
+                          key VARCHAR(50) PRIMARY KEY,
+                          label VARCHAR(100) NOT NULL,
+                          type VARCHAR(20) NOT NULL,
+                          options TEXT[] NOT NULL DEFAULT '{}',
+                          required BOOLEAN NOT NULL DEFAULT FALSE,
+                          default_value VARCHAR(255) NOT NULL DEFAULT '',
+                          position INTEGER NOT NULL DEFAULT 0,
+                          created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                          updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS custom_fields JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS template_version INTEGER NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_contacts_template_version ON contacts (template_version);
+
+-- contacts their owner shared with every user of the deployment. With SHARED_BOOK_REVIEW set a submission stays
+-- pending until an admin or editor approves it, only shared ones are visible to everyone
+CREATE TABLE IF NOT EXISTS shared_contacts (
+                          contact_id INTEGER PRIMARY KEY REFERENCES contacts (id) ON DELETE CASCADE,
+                          status VARCHAR(20) NOT NULL,
+                          submitted_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                          reviewed_by INTEGER REFERENCES users (id) ON DELETE SET NULL,
+                          reviewed_at TIMESTAMP WITH TIME ZONE,
+                          review_note VARCHAR(500) NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS idx_shared_contacts_status ON shared_contacts (status, submitted_at);
+
+-- outcome of the sync batches applied with a client batch ID, a replayed batch returns it instead of applying again
+CREATE TABLE IF NOT EXISTS sync_batches (
+                          user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+                          batch_id VARCHAR(64) NOT NULL,
+                          result JSONB NOT NULL,
+                          created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                          PRIMARY KEY (user_id, batch_id)
+);
+CREATE INDEX IF NOT EXISTS idx_sync_batches_created ON sync_batches (created_at);
+
+-- UUID given by an offline client to a contact it created, so it can refer to it before knowing its ID
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS client_id UUID;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_contacts_client_id ON contacts (user_id, client_id) WHERE client_id IS NOT NULL;
+
+-- read-only tokens embedding a group or a saved search of a user in another page, only the hash of the token is kept
+CREATE TABLE IF NOT EXISTS embed_tokens (
+                          id SERIAL PRIMARY KEY,
+                          user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+                          name VARCHAR(50) NOT NULL,
+                          token_hash CHAR(64) NOT NULL UNIQUE,
+                          token_prefix VARCHAR(16) NOT NULL,
+                          group_id INTEGER REFERENCES groups (id) ON DELETE CASCADE,
+                          query VARCHAR(200) NOT NULL DEFAULT '',
+                          tag VARCHAR(50) NOT NULL DEFAULT '',
+                          expires_at TIMESTAMP WITH TIME ZONE,
+                          revoked_at TIMESTAMP WITH TIME ZONE,
+                          last_used_at TIMESTAMP WITH TIME ZONE,
+                          created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_embed_tokens_user ON embed_tokens (user_id);
+
+-- contact standing for a user in the team directory listing the members, the user is not listed without one
+ALTER TABLE users ADD COLUMN IF NOT EXISTS directory_card_id INTEGER REFERENCES contacts (id) ON DELETE SET NULL;
+
+-- how alike two contacts must be to be listed as duplicates: low, medium or high, empty for the default (medium)
+ALTER TABLE user_preferences ADD COLUMN IF NOT EXISTS duplicate_sensitivity VARCHAR(10) NOT NULL DEFAULT '';
+
+-- phone number of the contact in E.164, read in the country of the contact or the region of its owner, empty when it
+-- cannot be normalized. The normalize-phones admin operation writes it again when the regions change
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS phone_normalized VARCHAR(20) NOT NULL DEFAULT '';
+
+-- the notification preferences matrix: whether a user is notified of an event on a channel. Users only have rows for
+-- the cells they changed, the others are on or off by the default of the event and channel
+CREATE TABLE IF NOT EXISTS notification_preferences (
+                          user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+                          event VARCHAR(50) NOT NULL,
+                          channel VARCHAR(20) NOT NULL,
+                          enabled BOOLEAN NOT NULL,
+                          updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+                          PRIMARY KEY (user_id, event, channel)
+);
+
+-- when the owner of a shared contact last changed it, and when each user last viewed it: a shared contact changed
+-- since a user viewed it is marked updated in the shared address book of that user
+ALTER TABLE shared_contacts ADD COLUMN IF NOT EXISTS changed_at TIMESTAMP WITH TIME ZONE;
+CREATE TABLE IF NOT EXISTS shared_contact_views (
+                          user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+                          contact_id INTEGER NOT NULL REFERENCES contacts (id) ON DELETE CASCADE,
+                          viewed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                          PRIMARY KEY (user_id, contact_id)
+);
@@ -0,0 +1,78 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+func contactTotalKey(userID int) string {
+	return fmt.Sprintf("counts:user:%d:contacts", userID)
+}
+
+func contactChangesKey(userID int) string {
+	return fmt.Sprintf("counts:user:%d:changes", userID)
+}
+
+// countContactChange counts a change and moves the total by ARGV[1], only when the total is known: a total
+// counted from zero would be wrong until it expires
+var countContactChange = redis.NewScript(`
+redis.call('INCR', KEYS[2])
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	redis.call('INCRBY', KEYS[1], ARGV[1])
+end
+return 1`)
+
+// GetContactCounts returns the number of contacts of a user, with ok false when it is not known, and the number of
+// changes counted for the user so far
+func (r *Redis) GetContactCounts(userID int) (total int64, ok bool, changes int64, err error) {
+	values, err := r.client.MGet(r.ctx, contactTotalKey(userID), contactChangesKey(userID)).Result()
+	if err != nil {
+		return 0, false, 0, err
+	}
+	if values[0] != nil {
+		if total, err = parseCounter(values[0]); err != nil {
+			return 0, false, 0, err
+		}
+		ok = true
+	}
+	if values[1] != nil {
+		if changes, err = parseCounter(values[1]); err != nil {
+			return 0, false, 0, err
+		}
+	}
+	return total, ok, changes, nil
+}
+
+// SetContactTotal stores the number of contacts of a user counted from the database, unless a total is already
+// known. It expires after ttl so any drift from the database is corrected
+func (r *Redis) SetContactTotal(userID int, total int64, ttl time.Duration) error {
+	return r.client.SetNX(r.ctx, contactTotalKey(userID), total, ttl).Err()
+}
+
+// CountContactChange counts a change to the contacts of a user, moving their number by delta
+func (r *Redis) CountContactChange(userID int, delta int64) error {
+	err := countContactChange.Run(context.Background(), r.client, []string{contactTotalKey(userID), contactChangesKey(userID)}, delta).Err()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+	return nil
+}
+
+// ForgetContactTotal drops the number of contacts of a user after changes to many contacts at once, it is counted
+// again from the database on next read
+func (r *Redis) ForgetContactTotal(userID int) error {
+	return r.client.Del(context.Background(), contactTotalKey(userID)).Err()
+}
+
+func parseCounter(value interface{}) (int64, error) {
+	text, ok := value.(string)
+	if !ok {
+		return 0, fmt.Errorf("unexpected counter %v", value)
+	}
+	return strconv.ParseInt(text, 10, 64)
+}
@@ -0,0 +1,34 @@
+package redis
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+func rateLimitKey(key string, windowStart time.Time) string {
+	return fmt.Sprintf("ratelimit:%s:%d", key, windowStart.Unix())
+}
+
+// CountRequest counts a request for key in the window starting at windowStart and returns the requests counted in
+// that window and in the previous one. Counters expire once they can no longer be the previous window
+func (r *Redis) CountRequest(key string, windowStart time.Time, window time.Duration) (current, previous int64, err error) {
+	ctx := r.ctx
+	currentKey := rateLimitKey(key, windowStart)
+
+	pipe := r.client.TxPipeline()
+	incr := pipe.Incr(ctx, currentKey)
+	pipe.Expire(ctx, currentKey, 2*window)
+	prev := pipe.Get(ctx, rateLimitKey(key, windowStart.Add(-window)))
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return 0, 0, err
+	}
+
+	previous, err = prev.Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return 0, 0, err
+	}
+	return incr.Val(), previous, nil
+}
@@ -7,43 +7,91 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/danizion/contact-app/internal/constants"
+	"github.com/danizion/contact-app/internal/metrics"
 	"github.com/danizion/contact-app/internal/models"
+	"github.com/danizion/contact-app/internal/utils"
 	"github.com/go-redis/redis/v8"
 )
 
+// Redis is the client of the cache, counters, sessions and rate limits. Its calls that keep the cache and counters
+// consistent (invalidations, publishes) always run to completion, the others run under its context
 type Redis struct {
 	client *redis.Client
+	ctx    context.Context
 }
 
 func InitRedis() *Redis {
-	host := getEnvOrDefault("REDIS_HOST", "localhost")
-	port := getEnvOrDefault("REDIS_PORT", "6379")
-	password := getEnvOrDefault("REDIS_PASSWORD", "")
-
-	client := redis.NewClient(&redis.Options{
-		Addr:     fmt.Sprintf("%s:%s", host, port),
-		Password: password,
-		DB:       0,
-	})
+	client := newClient()
 
 	_, err := client.Ping(context.Background()).Result()
 	if err != nil {
 		log.Fatal(err)
 	}
+	// a growing number of connections or of pool timeouts is a leak, calls not released or not canceled with their
+	// request
+	metrics.Publish("redis_pool", func() interface{} { return client.PoolStats() })
 	return &Redis{
 		client: client,
+		ctx:    context.Background(),
+	}
+}
+
+// WithContext returns a client running its calls under ctx, usually the context of a request: the calls waiting for a
+// connection are aborted when it is canceled, the ones sent to the server at its deadline
+func (r *Redis) WithContext(ctx context.Context) *Redis {
+	if r == nil {
+		return nil
 	}
+	return &Redis{client: r.client, ctx: ctx}
+}
+
+// Ping checks that the configured Redis server answers
+func Ping() error {
+	client := newClient()
+	defer client.Close()
+	return client.Ping(context.Background()).Err()
+}
+
+// newClient creates a client for the server set by the REDIS_* environment variables. A call waiting longer than
+// REDIS_TIMEOUT for the server fails
+func newClient() *redis.Client {
+	host := getEnvOrDefault("REDIS_HOST", "localhost")
+	port := getEnvOrDefault("REDIS_PORT", "6379")
+	password := getEnvOrDefault("REDIS_PASSWORD", "")
+	timeout := utils.GetEnvDurationOrDefault("REDIS_TIMEOUT", constants.DefaultRedisTimeout)
+
+	return redis.NewClient(&redis.Options{
+		Addr:         fmt.Sprintf("%s:%s", host, port),
+		Password:     password,
+		DB:           0,
+		ReadTimeout:  timeout,
+		WriteTimeout: timeout,
+	})
 }
 
+// cacheKeyEscaper escapes the separators of the cache keys in filter names and values, so that values holding ":" or
+// "=" cannot make two listings share a key
+var cacheKeyEscaper = strings.NewReplacer("%", "%25", ":", "%3A", "=", "%3D")
+
+// buildCacheKey names a cached page of contacts, filters are sorted by name so the same listing always has the same key
 func buildCacheKey(userID string, filters map[string]string, page, limit int) string {
-	key := fmt.Sprintf("contacts:user:%s", userID)
+	names := make([]string, 0, len(filters))
 	for k, v := range filters {
 		if v != "" {
-			key += fmt.Sprintf(":%s=%s", k, v)
+			names = append(names, k)
 		}
 	}
+	sort.Strings(names)
+
+	key := fmt.Sprintf("contacts:user:%s", userID)
+	for _, k := range names {
+		key += fmt.Sprintf(":%s=%s", cacheKeyEscaper.Replace(k), cacheKeyEscaper.Replace(filters[k]))
+	}
 	key += fmt.Sprintf(":page:%d:limit:%d", page, limit)
 	return key
 }
@@ -55,12 +103,12 @@ func (r *Redis) CacheContacts(userID string, filters map[string]string, page, li
 		return err
 	}
 	// Set the cache with a TTL of 5 minutes.
-	return r.client.Set(context.Background(), cacheKey, contactsJSON, 5*time.Minute).Err()
+	return r.client.Set(r.ctx, cacheKey, contactsJSON, 5*time.Minute).Err()
 }
 
 func (r *Redis) GetCachedContacts(userID string, filters map[string]string, page, limit int) ([]models.Contact, error) {
 	cacheKey := buildCacheKey(userID, filters, page, limit)
-	contactsJSON, err := r.client.Get(context.Background(), cacheKey).Result()
+	contactsJSON, err := r.client.Get(r.ctx, cacheKey).Result()
 	if errors.Is(err, redis.Nil) {
 		// Cache miss.
 		return nil, nil
@@ -83,14 +131,14 @@ func (r *Redis) CachePaginationResult(userID string, filters map[string]string,
 		return err
 	}
 	// Set the cache with a TTL of 5 minutes.
-	return r.client.Set(context.Background(), cacheKey, resultJSON, 5*time.Minute).Err()
+	return r.client.Set(r.ctx, cacheKey, resultJSON, 5*time.Minute).Err()
 }
 
 // GetCachedPaginationResult retrieves the entire pagination result from cache
 // Returns (found, error) where found indicates if the key was found in cache
 func (r *Redis) GetCachedPaginationResult(userID string, filters map[string]string, page, limit int, result interface{}) (bool, error) {
 	cacheKey := buildCacheKey(userID, filters, page, limit)
-	resultJSON, err := r.client.Get(context.Background(), cacheKey).Result()
+	resultJSON, err := r.client.Get(r.ctx, cacheKey).Result()
 	if errors.Is(err, redis.Nil) {
 		// Cache miss.
 		return false, nil
@@ -132,9 +180,88 @@ func (r *Redis) InvalidateUserCache(userID string) error {
 	return nil
 }
 
+// InvalidateUserCacheFilter removes only the cached contact entries of a user filtered by filter=value,
+// used when a change can only affect listings using that filter
+func (r *Redis) InvalidateUserCacheFilter(userID, filter, value string) error {
+	pattern := fmt.Sprintf("contacts:user:%s:*%s=%s:*", userID, escapePattern(cacheKeyEscaper.Replace(filter)),
+		escapePattern(cacheKeyEscaper.Replace(value)))
+
+	ctx := context.Background()
+	iter := r.client.Scan(ctx, 0, pattern, 0).Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+		err := r.client.Del(ctx, key).Err()
+		if err != nil {
+			log.Printf("Error deleting key %s: %v", key, err)
+		}
+	}
+
+	if err := iter.Err(); err != nil {
+		log.Printf("Error scanning Redis keys: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// escapePattern escapes the glob special characters of a value used in a SCAN pattern
+func escapePattern(value string) string {
+	var escaped strings.Builder
+	for _, ch := range value {
+		switch ch {
+		case '*', '?', '[', ']', '\\':
+			escaped.WriteRune('\\')
+		}
+		escaped.WriteRune(ch)
+	}
+	return escaped.String()
+}
+
 func getEnvOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
 	}
 	return defaultValue
 }
+
+// ClaimNonce records a nonce of scope for ttl, it returns false when the nonce was already claimed
+func (r *Redis) ClaimNonce(scope, nonce string, ttl time.Duration) (bool, error) {
+	key := fmt.Sprintf("nonce:%s:%s", scope, nonce)
+	return r.client.SetNX(r.ctx, key, 1, ttl).Result()
+}
+
+// Publish sends a message to the subscribers of a channel on every replica
+func (r *Redis) Publish(channel string, message []byte) error {
+	return r.client.Publish(context.Background(), channel, message).Err()
+}
+
+// Subscribe receives the messages of a channel until ctx is done. The subscription is active when it returns, so a
+// message published after that is never missed. Messages are dropped when the reader falls behind by more than buffer
+func (r *Redis) Subscribe(ctx context.Context, channel string, buffer int) (<-chan string, error) {
+	pubsub := r.client.Subscribe(ctx, channel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+
+	received := make(chan string, buffer)
+	go func() {
+		defer pubsub.Close()
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case message, ok := <-messages:
+				if !ok {
+					return
+				}
+				select {
+				case received <- message.Payload:
+				default:
+				}
+			}
+		}
+	}()
+	return received, nil
+}
@@ -0,0 +1,96 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// TestCallCanceledWithContext blocks a call waiting for the only connection of the pool, held by a blocking pop,
+// then cancels its context: it must fail with context.Canceled right away and leave neither a connection nor a
+// goroutine behind. It needs the Redis server set by the REDIS_* environment variables and is skipped without one
+func TestCallCanceledWithContext(t *testing.T) {
+	if err := Ping(); err != nil {
+		t.Skipf("no redis: %v", err)
+	}
+	options := newClient().Options()
+	options.PoolSize = 1
+	r := &Redis{client: redis.NewClient(options), ctx: context.Background()}
+	defer r.client.Close()
+	if err := r.client.Ping(r.ctx).Err(); err != nil {
+		t.Fatal(err)
+	}
+	conns, goroutines := r.client.PoolStats().TotalConns, runtime.NumGoroutine()
+
+	blocked := make(chan error, 1)
+	go func() {
+		blocked <- r.client.BLPop(r.ctx, time.Second, "redis_test:empty").Err()
+	}()
+	waitFor(t, "the blocking pop to hold the connection", func() bool { return r.client.PoolStats().IdleConns == 0 })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := r.WithContext(ctx).GetCachedContacts("0", nil, 1, 10)
+		done <- err
+	}()
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("got %v, want context.Canceled", err)
+		}
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("the call was not aborted by its context")
+	}
+	if err := <-blocked; !errors.Is(err, redis.Nil) {
+		t.Fatalf("blocking pop: %v", err)
+	}
+
+	waitFor(t, "the connection to go back to the pool", func() bool {
+		stats := r.client.PoolStats()
+		return stats.TotalConns <= conns && stats.IdleConns == stats.TotalConns
+	})
+	waitFor(t, "the goroutines to end", func() bool { return runtime.NumGoroutine() <= goroutines })
+}
+
+// TestSubscribeCanceledWithContext cancels the context of a subscription: its connection and goroutine must be
+// released
+func TestSubscribeCanceledWithContext(t *testing.T) {
+	if err := Ping(); err != nil {
+		t.Skipf("no redis: %v", err)
+	}
+	r := &Redis{client: newClient(), ctx: context.Background()}
+	defer r.client.Close()
+	goroutines := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if _, err := r.Subscribe(ctx, "redis_test:channel", 1); err != nil {
+		t.Fatal(err)
+	}
+	if r.client.PoolStats().TotalConns == 0 {
+		t.Fatal("the subscription holds no connection")
+	}
+	cancel()
+
+	waitFor(t, "the subscription to close its connection", func() bool { return r.client.PoolStats().TotalConns == 0 })
+	waitFor(t, "the goroutines to end", func() bool { return runtime.NumGoroutine() <= goroutines })
+}
+
+// waitFor polls condition for up to 5 seconds, failing the test with what it waited for when it never holds
+func waitFor(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
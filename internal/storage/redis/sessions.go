@@ -0,0 +1,107 @@
+package redis
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// RefreshToken is what a refresh token stands for: a user and the session generation it was issued in
+type RefreshToken struct {
+	UserID     int       `json:"user_id"`
+	Generation int       `json:"generation"`
+	IssuedAt   time.Time `json:"issued_at"`
+}
+
+func refreshTokenKey(hash string) string {
+	return fmt.Sprintf("refresh:%s", hash)
+}
+
+func deniedTokenKey(tokenID string) string {
+	return fmt.Sprintf("denied:token:%s", tokenID)
+}
+
+func sessionGenerationKey(userID int) string {
+	return fmt.Sprintf("sessions:user:%d:generation", userID)
+}
+
+// SaveRefreshToken stores a refresh token by its hash for ttl
+func (r *Redis) SaveRefreshToken(hash string, token RefreshToken, ttl time.Duration) error {
+	tokenJSON, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(r.ctx, refreshTokenKey(hash), tokenJSON, ttl).Err()
+}
+
+// TakeRefreshToken removes and returns the refresh token with this hash, nil when it does not exist or expired.
+// Taking is atomic so a refresh token can only ever be exchanged once
+func (r *Redis) TakeRefreshToken(hash string) (*RefreshToken, error) {
+	tokenJSON, err := r.client.GetDel(r.ctx, refreshTokenKey(hash)).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var token RefreshToken
+	if err := json.Unmarshal([]byte(tokenJSON), &token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// DenyToken revokes the access token with this ID for ttl, which should cover what is left of its lifetime
+func (r *Redis) DenyToken(tokenID string, ttl time.Duration) error {
+	return r.client.Set(r.ctx, deniedTokenKey(tokenID), 1, ttl).Err()
+}
+
+// IsTokenRevoked reports whether the access token with this ID was denied or was issued in an older session
+// generation of its user, both are read in one round trip
+func (r *Redis) IsTokenRevoked(tokenID string, userID, generation int) (bool, error) {
+	values, err := r.client.MGet(r.ctx, deniedTokenKey(tokenID), sessionGenerationKey(userID)).Result()
+	if err != nil {
+		return false, err
+	}
+	if values[0] != nil {
+		return true, nil
+	}
+	current, err := parseGeneration(values[1])
+	if err != nil {
+		return false, err
+	}
+	return generation < current, nil
+}
+
+// SessionGeneration returns the current session generation of a user, 0 until its sessions were first revoked
+func (r *Redis) SessionGeneration(userID int) (int, error) {
+	value, err := r.client.Get(r.ctx, sessionGenerationKey(userID)).Result()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return parseGeneration(value)
+}
+
+// RevokeSessions raises the session generation of a user, revoking every access and refresh token issued before.
+// The new generation is returned for the tokens issued next
+func (r *Redis) RevokeSessions(userID int) (int, error) {
+	generation, err := r.client.Incr(r.ctx, sessionGenerationKey(userID)).Result()
+	return int(generation), err
+}
+
+func parseGeneration(value interface{}) (int, error) {
+	if value == nil {
+		return 0, nil
+	}
+	text, ok := value.(string)
+	if !ok {
+		return 0, fmt.Errorf("unexpected session generation %v", value)
+	}
+	return strconv.Atoi(text)
+}
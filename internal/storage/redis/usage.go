@@ -0,0 +1,102 @@
+package redis
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UsageCounters count the requests of a user to a route during a day
+type UsageCounters struct {
+	Requests    int64
+	RateLimited int64
+	BytesIn     int64
+	BytesOut    int64
+}
+
+func usageKey(day time.Time, userID int) string {
+	return fmt.Sprintf("usage:day:%s:user:%d", day.Format("2006-01-02"), userID)
+}
+
+// usageUsersKey names the set of the users with usage counted during a day, the rollup only reads their counters
+func usageUsersKey(day time.Time) string {
+	return fmt.Sprintf("usage:day:%s:users", day.Format("2006-01-02"))
+}
+
+// CountUsage counts a request of a user to route during day (UTC). The counters of a day expire after ttl, once
+// rolled up to the database
+func (r *Redis) CountUsage(day time.Time, userID int, route string, rateLimited bool, bytesIn, bytesOut int64, ttl time.Duration) error {
+	ctx := r.ctx
+	key := usageKey(day, userID)
+	usersKey := usageUsersKey(day)
+
+	pipe := r.client.TxPipeline()
+	pipe.HIncrBy(ctx, key, route+":requests", 1)
+	if rateLimited {
+		pipe.HIncrBy(ctx, key, route+":rate_limited", 1)
+	}
+	if bytesIn > 0 {
+		pipe.HIncrBy(ctx, key, route+":bytes_in", bytesIn)
+	}
+	if bytesOut > 0 {
+		pipe.HIncrBy(ctx, key, route+":bytes_out", bytesOut)
+	}
+	pipe.Expire(ctx, key, ttl)
+	pipe.SAdd(ctx, usersKey, userID)
+	pipe.Expire(ctx, usersKey, ttl)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// GetUsage returns the counters of a user during day by route
+func (r *Redis) GetUsage(day time.Time, userID int) (map[string]UsageCounters, error) {
+	fields, err := r.client.HGetAll(r.ctx, usageKey(day, userID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make(map[string]UsageCounters)
+	for field, value := range fields {
+		route, counter, ok := strings.Cut(field, ":")
+		if !ok {
+			continue
+		}
+		count, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected usage counter %s: %w", field, err)
+		}
+
+		counters := usage[route]
+		switch counter {
+		case "requests":
+			counters.Requests = count
+		case "rate_limited":
+			counters.RateLimited = count
+		case "bytes_in":
+			counters.BytesIn = count
+		case "bytes_out":
+			counters.BytesOut = count
+		}
+		usage[route] = counters
+	}
+	return usage, nil
+}
+
+// GetUsageUsers returns the users with usage counted during day
+func (r *Redis) GetUsageUsers(day time.Time) ([]int, error) {
+	members, err := r.client.SMembers(r.ctx, usageUsersKey(day)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]int, 0, len(members))
+	for _, member := range members {
+		userID, err := strconv.Atoi(member)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected usage user %q: %w", member, err)
+		}
+		userIDs = append(userIDs, userID)
+	}
+	return userIDs, nil
+}
@@ -2,6 +2,9 @@ package utils
 
 import (
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // GetEnvOrDefault retrieves an environment variable's value or returns a default value if not set
@@ -11,3 +14,31 @@ func GetEnvOrDefault(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// GetEnvIntOrDefault retrieves an environment variable as an integer or returns a default value if not set or invalid
+func GetEnvIntOrDefault(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// GetEnvDurationOrDefault retrieves an environment variable as a positive duration (30s, 1m) or returns a default
+// value if not set or invalid
+func GetEnvDurationOrDefault(key string, defaultValue time.Duration) time.Duration {
+	if value, err := time.ParseDuration(os.Getenv(key)); err == nil && value > 0 {
+		return value
+	}
+	return defaultValue
+}
+
+// GetEnvList retrieves a comma separated environment variable as a list, blank entries are left out
+func GetEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
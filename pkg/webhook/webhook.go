@@ -0,0 +1,106 @@
+// Package webhook implements the signing scheme of the contact app webhooks. Consumers use Verify or VerifyRequest
+// to check that a delivery was sent by the contact app and has not been replayed long after it was signed.
+//
+// Every delivery is a POST with a JSON Event body and three headers:
+//
+//	X-Webhook-Id:        unique id of the event, the same on retries
+//	X-Webhook-Timestamp: unix time in seconds at which the delivery was signed
+//	X-Webhook-Signature: v1=<hex HMAC-SHA256 of "<timestamp>.<body>" keyed with the webhook secret>
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers of a webhook delivery
+const (
+	HeaderID        = "X-Webhook-Id"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// SignatureVersion prefixes the signatures of the current scheme
+const SignatureVersion = "v1"
+
+// DefaultTolerance is how far the delivery timestamp may be from the receiver's clock
+const DefaultTolerance = 5 * time.Minute
+
+// Errors returned by Verify
+var (
+	ErrMissingHeaders   = errors.New("webhook: missing signature headers")
+	ErrInvalidTimestamp = errors.New("webhook: invalid timestamp")
+	ErrExpired          = errors.New("webhook: timestamp outside the tolerance")
+	ErrInvalidSignature = errors.New("webhook: invalid signature")
+)
+
+// Event is the body of a delivery
+type Event struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Sign returns the X-Webhook-Signature value of body signed at timestamp
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return SignatureVersion + "=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// SetHeaders signs body and sets the delivery headers on header
+func SetHeaders(header http.Header, secret, eventID string, timestamp time.Time, body []byte) {
+	header.Set(HeaderID, eventID)
+	header.Set(HeaderTimestamp, strconv.FormatInt(timestamp.Unix(), 10))
+	header.Set(HeaderSignature, Sign(secret, timestamp, body))
+}
+
+// Verify checks the signature headers of a delivery against its raw body. The signature header may hold several
+// comma separated signatures (while a secret is rotated), the delivery is valid if any of them matches.
+func Verify(secret string, header http.Header, body []byte, tolerance time.Duration) error {
+	timestampValue := header.Get(HeaderTimestamp)
+	signatures := header.Get(HeaderSignature)
+	if timestampValue == "" || signatures == "" {
+		return ErrMissingHeaders
+	}
+
+	unix, err := strconv.ParseInt(timestampValue, 10, 64)
+	if err != nil {
+		return ErrInvalidTimestamp
+	}
+	timestamp := time.Unix(unix, 0)
+	if age := time.Since(timestamp); age > tolerance || age < -tolerance {
+		return ErrExpired
+	}
+
+	expected := []byte(Sign(secret, timestamp, body))
+	for _, signature := range strings.Split(signatures, ",") {
+		if hmac.Equal(expected, []byte(strings.TrimSpace(signature))) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// VerifyRequest reads the body of an incoming delivery and verifies it, returning the body when it is valid
+func VerifyRequest(r *http.Request, secret string, tolerance time.Duration) ([]byte, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := Verify(secret, r.Header, body, tolerance); err != nil {
+		return nil, err
+	}
+	return body, nil
+}